@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Certificate attributes granting contract roles. Each is issued by the
+// organisation's CA with the value "true".
+const (
+	AttrAdmin     = "kyc.admin"
+	AttrVerifier  = "kyc.verifier"
+	AttrSenior    = "kyc.senior"
+	AttrCustodian = "kyc.custodian" // records custodian, trusted with applicant photographs
+	AttrRegulator = "kyc.regulator"
+	AttrGateway   = "kyc.gateway" // application acting for operators, see operator.go
+)
+
+// requireAttribute fails unless the caller's certificate carries attr=true
+// and is not a certificate rotated out of a verifier's binding
+func requireAttribute(ctx contractapi.TransactionContextInterface, attr string) error {
+	held, err := hasAttribute(ctx, attr)
+	if err != nil {
+		return err
+	}
+	if !held {
+		return fmt.Errorf("caller is not authorized: %s attribute required", attr)
+	}
+	return requireCurrentCertificate(ctx)
+}
+
+// requireAnyAttribute fails unless the caller's certificate carries one of
+// attrs=true and is current, and returns the first one it carries
+func requireAnyAttribute(ctx contractapi.TransactionContextInterface, attrs ...string) (string, error) {
+	for _, attr := range attrs {
+		held, err := hasAttribute(ctx, attr)
+		if err != nil {
+			return "", err
+		}
+		if held {
+			return attr, requireCurrentCertificate(ctx)
+		}
+	}
+	return "", fmt.Errorf("caller is not authorized: one of the %s attributes required", strings.Join(attrs, ", "))
+}
+
+// hasAttribute reports whether the caller's certificate carries attr=true.
+// The regulator attribute only counts for organisations on the regulator
+// allowlist, see allowlist.go.
+func hasAttribute(ctx contractapi.TransactionContextInterface, attr string) (bool, error) {
+	if ctx.GetClientIdentity().AssertAttributeValue(attr, "true") != nil {
+		return false, nil
+	}
+	if attr == AttrRegulator {
+		return mspAllowed(ctx, AllowlistRegulator)
+	}
+	return true, nil
+}
+
+// requireAdmin fails unless the caller is a contract administrator
+func requireAdmin(ctx contractapi.TransactionContextInterface) error {
+	return requireAttribute(ctx, AttrAdmin)
+}
+
+// requireCurrentCertificate fails when the caller's certificate was rotated
+// out of a verifier's binding, see verifier.go
+func requireCurrentCertificate(ctx contractapi.TransactionContextInterface) error {
+	_, err := identityActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("caller is not authorized: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	pincodeIndex   = "pincode~kycid"
+	stateCityIndex = "state~city~kycid"
+)
+
+// GetKYCByPincode returns a page of KYC records registered at the given pincode
+func (s *SmartContract) GetKYCByPincode(ctx contractapi.TransactionContextInterface, pincode string, pageSize int32, bookmark string, purposeCode string) (*PaginatedQueryResult, error) {
+	purpose, err := newReadPurpose(ctx, "GetKYCByPincode", purposeCode)
+	if err != nil {
+		return nil, err
+	}
+	return s.getRecordsByIndex(ctx, purpose, pincodeIndex, []string{indexValue(pincode)}, pageSize, bookmark)
+}
+
+// GetKYCByState returns a page of KYC records whose address is in the given state
+func (s *SmartContract) GetKYCByState(ctx contractapi.TransactionContextInterface, state string, pageSize int32, bookmark string, purposeCode string) (*PaginatedQueryResult, error) {
+	purpose, err := newReadPurpose(ctx, "GetKYCByState", purposeCode)
+	if err != nil {
+		return nil, err
+	}
+	return s.getRecordsByIndex(ctx, purpose, stateCityIndex, []string{indexValue(state)}, pageSize, bookmark)
+}
+
+// GetKYCByCity returns a page of KYC records whose address is in the given city of a state
+func (s *SmartContract) GetKYCByCity(ctx contractapi.TransactionContextInterface, state string, city string, pageSize int32, bookmark string, purposeCode string) (*PaginatedQueryResult, error) {
+	purpose, err := newReadPurpose(ctx, "GetKYCByCity", purposeCode)
+	if err != nil {
+		return nil, err
+	}
+	return s.getRecordsByIndex(ctx, purpose, stateCityIndex, []string{indexValue(state), indexValue(city)}, pageSize, bookmark)
+}
+
+// putAddressIndexes writes the regional index entries for a record's address
+func putAddressIndexes(ctx contractapi.TransactionContextInterface, kyc *KYCRecord) error {
+	if kyc.Address.Pincode != "" {
+		err := putIndexEntry(ctx, pincodeIndex, kyc.ID, indexValue(kyc.Address.Pincode))
+		if err != nil {
+			return err
+		}
+	}
+	if kyc.Address.State != "" {
+		err := putIndexEntry(ctx, stateCityIndex, kyc.ID, indexValue(kyc.Address.State), indexValue(kyc.Address.City))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteAddressIndexes removes the regional index entries for a record's address
+func deleteAddressIndexes(ctx contractapi.TransactionContextInterface, kyc *KYCRecord) error {
+	if kyc.Address.Pincode != "" {
+		err := deleteIndexEntry(ctx, pincodeIndex, kyc.ID, indexValue(kyc.Address.Pincode))
+		if err != nil {
+			return err
+		}
+	}
+	if kyc.Address.State != "" {
+		err := deleteIndexEntry(ctx, stateCityIndex, kyc.ID, indexValue(kyc.Address.State), indexValue(kyc.Address.City))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexValue canonicalises a free-text value for use in an index key
+func indexValue(value string) string {
+	return strings.ToUpper(strings.TrimSpace(value))
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+var sha256HexPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// adverseMediaRiskTier is the risk tier a finding of each severity escalates a record to
+var adverseMediaRiskTier = map[string]string{
+	"LOW":    RiskLow,
+	"MEDIUM": RiskMedium,
+	"HIGH":   RiskHigh,
+}
+
+// AdverseMedia attests a negative-news finding against a customer. Only
+// hashes of the source and of the analyst's summary are kept on the ledger.
+type AdverseMedia struct {
+	SourceHash  string `json:"sourceHash"`
+	Severity    string `json:"severity"` // LOW, MEDIUM, HIGH
+	SummaryHash string `json:"summaryHash"`
+	Analyst     string `json:"analyst"`
+	AttestedAt  string `json:"attestedAt"`
+	TxID        string `json:"txId"`
+}
+
+// SetAdverseMediaFlag attaches an adverse media attestation to a record and
+// escalates the record's risk tier according to the finding's severity
+func (s *SmartContract) SetAdverseMediaFlag(ctx contractapi.TransactionContextInterface, kycID string, sourceHash string, severity string, summaryHash string) error {
+	err := requireAttribute(ctx, AttrVerifier)
+	if err != nil {
+		return err
+	}
+
+	sourceHash = strings.ToLower(sourceHash)
+	summaryHash = strings.ToLower(summaryHash)
+	if !sha256HexPattern.MatchString(sourceHash) || !sha256HexPattern.MatchString(summaryHash) {
+		return fmt.Errorf("source and summary hashes must be hex SHA-256 digests")
+	}
+	severity = strings.ToUpper(severity)
+	tier, ok := adverseMediaRiskTier[severity]
+	if !ok {
+		return fmt.Errorf("severity must be LOW, MEDIUM or HIGH")
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	for _, finding := range kyc.AdverseMedia {
+		if finding.SourceHash == sourceHash {
+			return fmt.Errorf("KYC record %s already has an adverse media finding for this source", kycID)
+		}
+	}
+
+	analyst, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	before := *kyc
+	kyc.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+	kyc.AdverseMedia = append(kyc.AdverseMedia, AdverseMedia{
+		SourceHash:  sourceHash,
+		Severity:    severity,
+		SummaryHash: summaryHash,
+		Analyst:     analyst,
+		AttestedAt:  kyc.UpdatedAt,
+		TxID:        ctx.GetStub().GetTxID(),
+	})
+	addFlag(kyc, FlagAdverseMedia, "adverse media finding attested", kyc.UpdatedAt)
+	oldTier := kyc.RiskTier
+	escalated := escalateRiskTier(kyc, tier)
+
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return err
+	}
+	err = putRecordState(ctx, kycID, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update KYC record: %v", err)
+	}
+	err = updateRecordCounters(ctx, &before, kyc)
+	if err != nil {
+		return fmt.Errorf("failed to update counters: %v", err)
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-ADVERSE_MEDIA_FLAGGED-%d", kycID, s.txTime(ctx).Unix()),
+		KYCID:       kycID,
+		Action:      "ADVERSE_MEDIA_FLAGGED",
+		PerformedBy: analyst,
+		PerformedAt: kyc.UpdatedAt,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"severity":   severity,
+			"sourceHash": sourceHash,
+			"oldTier":    oldTier,
+			"newTier":    kyc.RiskTier,
+			"escalated":  escalated,
+		},
+	}
+
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return nil
+}
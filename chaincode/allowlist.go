@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// MSP allowlists. The configuration holds the organisations allowed to
+// submit records with CreateKYC, to decide them with UpdateKYCStatus and to
+// act as regulators, so admitting a consortium member is a data change made
+// with AllowMSP rather than a chaincode upgrade. A list left empty allows
+// every organisation, as before allowlists existed; the regulator attribute
+// of a certificate from an organisation not on a non-empty regulator list is
+// ignored.
+const (
+	AllowlistSubmitter = "SUBMITTER"
+	AllowlistVerifier  = "VERIFIER"
+	AllowlistRegulator = "REGULATOR"
+)
+
+// MSPAllowlists are the organisations allowed each role, by MSP ID
+type MSPAllowlists struct {
+	SubmitterMSPs []string `json:"submitterMsps"`
+	VerifierMSPs  []string `json:"verifierMsps"`
+	RegulatorMSPs []string `json:"regulatorMsps"`
+}
+
+// list returns the allowlist of a role
+func (a *MSPAllowlists) list(role string) (*[]string, error) {
+	switch role {
+	case AllowlistSubmitter:
+		return &a.SubmitterMSPs, nil
+	case AllowlistVerifier:
+		return &a.VerifierMSPs, nil
+	case AllowlistRegulator:
+		return &a.RegulatorMSPs, nil
+	}
+	return nil, fmt.Errorf("allowlist must be %s, %s or %s", AllowlistSubmitter, AllowlistVerifier, AllowlistRegulator)
+}
+
+// AllowMSP adds an organisation to the allowlist of a role: SUBMITTER,
+// VERIFIER or REGULATOR. Only administrators can change allowlists.
+func (s *SmartContract) AllowMSP(ctx contractapi.TransactionContextInterface, role string, mspID string) (*MSPAllowlists, error) {
+	return s.updateAllowlist(ctx, role, mspID, true)
+}
+
+// DisallowMSP removes an organisation from the allowlist of a role. Removing
+// the last organisation leaves the list empty, which allows every
+// organisation.
+func (s *SmartContract) DisallowMSP(ctx contractapi.TransactionContextInterface, role string, mspID string) (*MSPAllowlists, error) {
+	return s.updateAllowlist(ctx, role, mspID, false)
+}
+
+func (s *SmartContract) updateAllowlist(ctx contractapi.TransactionContextInterface, role string, mspID string, allow bool) (*MSPAllowlists, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mspID = strings.TrimSpace(mspID)
+	if mspID == "" {
+		return nil, fmt.Errorf("MSP ID is required")
+	}
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list, err := config.Allowlists.list(role)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := []string{}
+	for _, allowed := range *list {
+		if allowed == mspID {
+			if allow {
+				return nil, fmt.Errorf("%s is already on the %s allowlist", mspID, role)
+			}
+			continue
+		}
+		kept = append(kept, allowed)
+	}
+	if allow {
+		kept = append(kept, mspID)
+	} else if len(kept) == len(*list) {
+		return nil, fmt.Errorf("%s is not on the %s allowlist", mspID, role)
+	}
+	*list = kept
+
+	config.Version++
+	config.UpdatedBy, err = clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+	config.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(configKey, configJSON)
+	if err != nil {
+		return nil, err
+	}
+	return &config.Allowlists, nil
+}
+
+// requireAllowedMSP fails unless the caller's organisation is on the
+// allowlist of role, or the list is empty
+func requireAllowedMSP(ctx contractapi.TransactionContextInterface, role string) error {
+	allowed, err := mspAllowed(ctx, role)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		mspID, _ := ctx.GetClientIdentity().GetMSPID()
+		return fmt.Errorf("caller is not authorized: %s is not on the %s allowlist", mspID, role)
+	}
+	return nil
+}
+
+// mspAllowed reports whether the caller's organisation is on the allowlist
+// of role, or the list is empty
+func mspAllowed(ctx contractapi.TransactionContextInterface, role string) (bool, error) {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return false, err
+	}
+	list, err := config.Allowlists.list(role)
+	if err != nil {
+		return false, err
+	}
+	if len(*list) == 0 {
+		return true, nil
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return false, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	for _, allowed := range *list {
+		if allowed == mspID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// listedRole returns the role of the first allowlist naming an organisation,
+// or an empty string when none does
+func listedRole(ctx contractapi.TransactionContextInterface, mspID string) (string, error) {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, role := range []string{AllowlistSubmitter, AllowlistVerifier, AllowlistRegulator} {
+		list, _ := config.Allowlists.list(role)
+		for _, allowed := range *list {
+			if allowed == mspID {
+				return role, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// validateAllowlists checks that no allowlist names an organisation twice,
+// and stores an omitted list as an empty one
+func validateAllowlists(allowlists *MSPAllowlists) error {
+	for _, role := range []string{AllowlistSubmitter, AllowlistVerifier, AllowlistRegulator} {
+		list, _ := allowlists.list(role)
+		if *list == nil {
+			*list = []string{}
+		}
+		seen := map[string]bool{}
+		for _, mspID := range *list {
+			if strings.TrimSpace(mspID) == "" || seen[mspID] {
+				return fmt.Errorf("the %s allowlist must name distinct, non-empty MSP IDs", role)
+			}
+			seen[mspID] = true
+		}
+	}
+	return nil
+}
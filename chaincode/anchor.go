@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Anchors. An anchoring worker periodically hashes the ledger state, writes
+// the digest to a public chain or an external timestamping service, and
+// records the proof it gets back with RecordAnchor under
+// "ANCHOR~<recorded at>~<tx ID>", so anchors list in the order they were
+// recorded. The proof is kept as the target returned it; checking it is left
+// to the target's own tools, since the chaincode cannot reach the target.
+const anchorObjectType = "ANCHOR"
+
+// Anchor is a digest of the ledger state written outside the network, with
+// the proof the target returned
+type Anchor struct {
+	ID         string `json:"id"`         // the recording transaction's ID
+	Digest     string `json:"digest"`     // hex SHA-256
+	Documents  int    `json:"documents"`  // documents the digest covers
+	Target     string `json:"target"`     // where the digest was written, such as opentimestamps or ethereum
+	Proof      string `json:"proof"`      // as the target returned it: a transaction hash, or a base64 timestamp
+	AnchoredAt string `json:"anchoredAt"` // when the target accepted the digest
+	RecordedAt string `json:"recordedAt"`
+	RecordedBy string `json:"recordedBy"`
+}
+
+// AnchorPage holds one page of anchors
+type AnchorPage struct {
+	Items               []*Anchor `json:"items"`
+	FetchedRecordsCount int32     `json:"fetchedRecordsCount"`
+	Bookmark            string    `json:"bookmark"`
+	Truncated           bool      `json:"truncated"` // the page was cut short by maxResponseBytes
+}
+
+// RecordAnchor records the proof that a state digest was written to a public
+// chain or external timestamping service. anchoredAt is an RFC 3339
+// timestamp; empty records the anchor as accepted now.
+func (s *SmartContract) RecordAnchor(ctx contractapi.TransactionContextInterface, digest string, documents int, target string, proof string, anchoredAt string) (*Anchor, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	digest = strings.ToLower(digest)
+	decoded, err := hex.DecodeString(digest)
+	if err != nil || len(decoded) != 32 {
+		return nil, fmt.Errorf("digest must be a hex SHA-256 digest")
+	}
+	if documents < 0 {
+		return nil, fmt.Errorf("documents cannot be negative")
+	}
+	if target == "" || proof == "" {
+		return nil, fmt.Errorf("target and proof are required")
+	}
+	now := s.txTime(ctx).Format(time.RFC3339)
+	if anchoredAt == "" {
+		anchoredAt = now
+	}
+	parsed, err := time.Parse(time.RFC3339, anchoredAt)
+	if err != nil {
+		return nil, fmt.Errorf("anchoredAt must be an RFC 3339 timestamp")
+	}
+
+	recordedBy, err := clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+	anchor := &Anchor{
+		ID:         ctx.GetStub().GetTxID(),
+		Digest:     digest,
+		Documents:  documents,
+		Target:     target,
+		Proof:      proof,
+		AnchoredAt: parsed.UTC().Format(time.RFC3339),
+		RecordedAt: now,
+		RecordedBy: recordedBy,
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(anchorObjectType, []string{anchor.RecordedAt, anchor.ID})
+	if err != nil {
+		return nil, err
+	}
+	anchorJSON, err := json.Marshal(anchor)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(key, anchorJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record anchor: %v", err)
+	}
+	return anchor, nil
+}
+
+// GetAnchors returns one page of anchors, oldest first
+func (s *SmartContract) GetAnchors(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*AnchorPage, error) {
+	err := checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	resultsIterator, responseMetadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(anchorObjectType, []string{}, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	budget, err := newResponseBudget(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []*Anchor{}
+	bookmark, truncated := responseMetadata.Bookmark, false
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var anchor Anchor
+		err = json.Unmarshal(queryResponse.Value, &anchor)
+		if err != nil {
+			return nil, err
+		}
+		fits, err := budget.fits(&anchor)
+		if err != nil {
+			return nil, err
+		}
+		if !fits {
+			bookmark, truncated = queryResponse.Key, true
+			break
+		}
+		items = append(items, &anchor)
+	}
+
+	return &AnchorPage{
+		Items:               items,
+		FetchedRecordsCount: int32(len(items)),
+		Bookmark:            bookmark,
+		Truncated:           truncated,
+	}, nil
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// Access anomaly patterns
+const (
+	AnomalyHighVolume = "HIGH_VOLUME"            // one identity read many records
+	AnomalySequential = "SEQUENTIAL_ENUMERATION" // one identity read a run of consecutively numbered records
+	AnomalyNoFollowUp = "NO_FOLLOW_UP"           // one identity read records and never acted on them
+)
+
+// Thresholds at which an access pattern is reported
+const (
+	anomalyHighVolumeReads = 100 // reads by one identity in the window
+	anomalySequentialRun   = 5   // consecutively numbered records read by one identity
+	anomalyNoFollowUpReads = 20  // records read by one identity without a later action on them
+	anomalyNoFollowUpShare = 0.9 // share of the identity's records left without an action
+)
+
+// recordNumberPattern splits a record ID into a prefix and its trailing number
+var recordNumberPattern = regexp.MustCompile(`^(.*?)([0-9]+)$`)
+
+// AccessAnomaly is one suspicious pattern of reads by one identity
+type AccessAnomaly struct {
+	Pattern     string   `json:"pattern"`
+	Score       int      `json:"score"` // 0-100, higher is more suspicious
+	Accessor    string   `json:"accessor"`
+	AccessorMSP string   `json:"accessorMsp"`
+	Reads       int      `json:"reads"`   // reads by the identity in the window
+	Records     int      `json:"records"` // distinct records among them
+	Detail      string   `json:"detail"`
+	KYCIDs      []string `json:"kycIds"` // the records the pattern involves, at most 20
+}
+
+// accessorReads are the reads of one identity in the window
+type accessorReads struct {
+	msp       string
+	reads     int
+	firstRead map[string]string // kycID → time of the identity's first read of it
+}
+
+// GetAccessAnomalies analyses the access log between from and to inclusive
+// for sequential enumeration of record IDs, high-volume reads by one
+// identity and reads never followed by an action on the record, and returns
+// what it finds most suspicious first. Bounds are RFC 3339 timestamps and
+// either may be empty. Only reads of other organisations' records are
+// logged, so only those are analysed. The window is read from the
+// time-ordered copy of the access log, so its cost grows with the reads in
+// it rather than the whole log, and a window holding more than
+// maxIteratorResults reads fails; narrow it instead. Reads logged before the
+// copy existed are analysed once BackfillAccessTimes has copied them.
+func (s *SmartContract) GetAccessAnomalies(ctx contractapi.TransactionContextInterface, from string, to string) ([]*AccessAnomaly, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, bound := range []string{from, to} {
+		if bound == "" {
+			continue
+		}
+		_, err := time.Parse(time.RFC3339, bound)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q: expected RFC 3339", bound)
+		}
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByRange(accessTimePrefix+from, accessTimePrefix+to+"\xff")
+	if err != nil {
+		return nil, err
+	}
+	accessors := map[string]*accessorReads{}
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		var entry AccessLogEntry
+		err := json.Unmarshal(queryResponse.Value, &entry)
+		if err != nil {
+			return err
+		}
+		reads := accessors[entry.Accessor]
+		if reads == nil {
+			reads = &accessorReads{msp: entry.AccessorMSP, firstRead: map[string]string{}}
+			accessors[entry.Accessor] = reads
+		}
+		reads.reads++
+		if first, ok := reads.firstRead[entry.KYCID]; !ok || entry.AccessedAt < first {
+			reads.firstRead[entry.KYCID] = entry.AccessedAt
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	anomalies := []*AccessAnomaly{}
+	histories := map[string][]*HistoryEntry{}
+	for accessor, reads := range accessors {
+		kycIDs := make([]string, 0, len(reads.firstRead))
+		for kycID := range reads.firstRead {
+			kycIDs = append(kycIDs, kycID)
+		}
+		sort.Strings(kycIDs)
+		anomaly := func(pattern string, score int, detail string, involved []string) {
+			if len(involved) > 20 {
+				involved = involved[:20]
+			}
+			anomalies = append(anomalies, &AccessAnomaly{
+				Pattern:     pattern,
+				Score:       score,
+				Accessor:    accessor,
+				AccessorMSP: reads.msp,
+				Reads:       reads.reads,
+				Records:     len(kycIDs),
+				Detail:      detail,
+				KYCIDs:      involved,
+			})
+		}
+
+		if reads.reads >= anomalyHighVolumeReads {
+			score := 50 * reads.reads / anomalyHighVolumeReads
+			anomaly(AnomalyHighVolume, min(score, 100), fmt.Sprintf("%d reads of %d records", reads.reads, len(kycIDs)), kycIDs)
+		}
+
+		run := longestNumberedRun(kycIDs)
+		if len(run) >= anomalySequentialRun {
+			score := 60 + 5*(len(run)-anomalySequentialRun)
+			anomaly(AnomalySequential, min(score, 100), fmt.Sprintf("read %d consecutively numbered records, %s to %s", len(run), run[0], run[len(run)-1]), run)
+		}
+
+		if len(kycIDs) >= anomalyNoFollowUpReads {
+			unactioned := []string{}
+			for _, kycID := range kycIDs {
+				history, ok := histories[kycID]
+				if !ok {
+					history, err = s.GetKYCHistory(ctx, kycID)
+					if err != nil {
+						return nil, err
+					}
+					histories[kycID] = history
+				}
+				acted := false
+				for _, entry := range history {
+					if entry.PerformedBy == accessor && entry.PerformedAt >= reads.firstRead[kycID] {
+						acted = true
+						break
+					}
+				}
+				if !acted {
+					unactioned = append(unactioned, kycID)
+				}
+			}
+			if len(unactioned) >= anomalyNoFollowUpReads && float64(len(unactioned)) >= anomalyNoFollowUpShare*float64(len(kycIDs)) {
+				score := 40 + (len(unactioned)-anomalyNoFollowUpReads)/5
+				anomaly(AnomalyNoFollowUp, min(score, 100), fmt.Sprintf("no action followed the reads of %d of %d records", len(unactioned), len(kycIDs)), unactioned)
+			}
+		}
+	}
+
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		if anomalies[i].Score != anomalies[j].Score {
+			return anomalies[i].Score > anomalies[j].Score
+		}
+		if anomalies[i].Accessor != anomalies[j].Accessor {
+			return anomalies[i].Accessor < anomalies[j].Accessor
+		}
+		return anomalies[i].Pattern < anomalies[j].Pattern
+	})
+	return anomalies, nil
+}
+
+// longestNumberedRun returns the longest run of record IDs sharing a prefix
+// with consecutive trailing numbers, in order
+func longestNumberedRun(kycIDs []string) []string {
+	type numbered struct {
+		id     string
+		prefix string
+		number uint64
+	}
+	ids := []numbered{}
+	for _, kycID := range kycIDs {
+		match := recordNumberPattern.FindStringSubmatch(kycID)
+		if match == nil {
+			continue
+		}
+		number, err := strconv.ParseUint(match[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, numbered{id: kycID, prefix: match[1], number: number})
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if ids[i].prefix != ids[j].prefix {
+			return ids[i].prefix < ids[j].prefix
+		}
+		return ids[i].number < ids[j].number
+	})
+
+	longest, current := []string{}, []string{}
+	for i, id := range ids {
+		if i > 0 && id.prefix == ids[i-1].prefix && id.number == ids[i-1].number+1 {
+			current = append(current, id.id)
+		} else {
+			current = []string{id.id}
+		}
+		if len(current) > len(longest) {
+			longest = append([]string{}, current...)
+		}
+	}
+	return longest
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// archivePrefix keys archived records, "ARCHIVE_<kyc ID>"
+const archivePrefix = "ARCHIVE_"
+
+// archivableStatuses are the statuses of closed records, which can be
+// archived once they have not changed for the configured period
+var archivableStatuses = map[string]bool{
+	"REJECTED": true,
+	"EXPIRED":  true,
+}
+
+// ArchivedRecord is a record moved out of the live key space. The record is
+// nested rather than stored as is, so the rich queries that select records
+// by their top-level fields do not match it.
+type ArchivedRecord struct {
+	Record     *KYCRecord `json:"record"`
+	ArchivedAt string     `json:"archivedAt"`
+	ArchivedBy string     `json:"archivedBy"`
+}
+
+func archiveKey(kycID string) string {
+	return archivePrefix + kycID
+}
+
+// ArchiveKYC moves a closed record into the archive: the record leaves its
+// indexes, counters and every query over live records, while the record, its
+// history and its screening runs are kept. Only REJECTED and EXPIRED records
+// unchanged for the consortium policy's retention period can be archived,
+// and not while a screening alert on them is still open. RestoreKYC brings a
+// record back.
+func (s *SmartContract) ArchiveKYC(ctx contractapi.TransactionContextInterface, kycID string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	if !archivableStatuses[kyc.Status] {
+		return fmt.Errorf("KYC record %s is %s; only REJECTED and EXPIRED records can be archived", kycID, kyc.Status)
+	}
+	policy, err := currentPolicy(ctx)
+	if err != nil {
+		return err
+	}
+	now := s.txTime(ctx)
+	updatedAt, err := time.Parse(time.RFC3339, kyc.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("KYC record %s has an invalid updatedAt %q", kycID, kyc.UpdatedAt)
+	}
+	retentionDays := policy.Retention.days(kyc.Status)
+	if eligibleAt := updatedAt.AddDate(0, 0, retentionDays); now.Before(eligibleAt) {
+		return fmt.Errorf("KYC record %s can be archived from %s, %d days after its last change", kycID, eligibleAt.Format(time.RFC3339), retentionDays)
+	}
+	openAlerts, err := ctx.GetStub().GetStateByPartialCompositeKey(openAlertIndex, []string{kycID})
+	if err != nil {
+		return err
+	}
+	hasOpenAlert := openAlerts.HasNext()
+	openAlerts.Close()
+	if hasOpenAlert {
+		return fmt.Errorf("KYC record %s has an open screening alert; record a disposition before archiving", kycID)
+	}
+
+	err = deleteRecordIndexes(ctx, kyc)
+	if err != nil {
+		return fmt.Errorf("failed to delete KYC indexes: %v", err)
+	}
+	err = clearRecordStaleScreenings(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	err = updateRecordCounters(ctx, kyc, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update counters: %v", err)
+	}
+	err = deleteRecordState(ctx, kycID)
+	if err != nil {
+		return fmt.Errorf("failed to delete KYC record: %v", err)
+	}
+
+	archivedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	archived := ArchivedRecord{
+		Record:     kyc,
+		ArchivedAt: now.Format(time.RFC3339),
+		ArchivedBy: archivedBy,
+	}
+	archivedJSON, err := json.Marshal(archived)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(archiveKey(kycID), archivedJSON)
+	if err != nil {
+		return fmt.Errorf("failed to archive KYC record: %v", err)
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-ARCHIVED-%d", kycID, now.Unix()),
+		KYCID:       kycID,
+		Action:      "ARCHIVED",
+		PerformedBy: archivedBy,
+		PerformedAt: archived.ArchivedAt,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"status":    kyc.Status,
+			"updatedAt": kyc.UpdatedAt,
+		},
+	}
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+
+	return nil
+}
+
+// RestoreKYC brings an archived record back for a returning customer. The
+// record is revalidated as on submission: against the current validation
+// rules, email domain blocklist, blacklist and risk rules, with its
+// duplicate phone check rerun. Unless it is VERIFIED and not yet expired it
+// returns as PENDING with a new SLA, its old verification, screening result,
+// assignment and overrides cleared so it is reviewed afresh; a record that now
+// matches the blacklist returns BLOCKED.
+func (s *SmartContract) RestoreKYC(ctx contractapi.TransactionContextInterface, kycID string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	archived, err := readArchivedRecord(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	if archived == nil {
+		return fmt.Errorf("KYC record %s is not archived", kycID)
+	}
+	exists, err := s.KYCExists(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("KYC record %s already exists", kycID)
+	}
+	kyc := archived.Record
+	err = validateKYCRecord(kyc)
+	if err != nil {
+		return fmt.Errorf("KYC record %s no longer passes validation: %v", kycID, err)
+	}
+
+	now := s.txTime(ctx).Format(time.RFC3339)
+	archivedStatus := kyc.Status
+	kyc.UpdatedAt = now
+	// timestamps are all UTC RFC 3339, so they compare as strings
+	if kyc.Status != "VERIFIED" || kyc.ExpiresAt <= now {
+		policy, err := currentPolicy(ctx)
+		if err != nil {
+			return err
+		}
+		kyc.Status = "PENDING"
+		kyc.VerificationLevel = "L1"
+		kyc.ExpiresAt = ""
+		kyc.SLADueAt = slaDueAt(now, policy.SLAHours)
+		kyc.VerificationApproval = nil
+		kyc.Hold = nil
+		kyc.Screening = nil
+		kyc.AssignedTo = ""
+		kyc.Escalation = nil
+		kyc.RiskOverride = nil
+	}
+
+	checkPincodeState(kyc, now)
+	err = screenEmailDomain(ctx, kyc, now)
+	if err != nil {
+		return err
+	}
+	kyc.Blacklist = nil
+	err = screenBlacklist(ctx, kyc, now)
+	if err != nil {
+		return err
+	}
+	if kyc.Phone != "" {
+		samePhone, err := getIndexedIDs(ctx, phoneIndex, phoneHash(kyc.Phone))
+		if err != nil {
+			return err
+		}
+		if len(samePhone) > 0 {
+			addFlag(kyc, FlagDuplicatePhone, fmt.Sprintf("phone number already registered on %d other record(s)", len(samePhone)), now)
+		}
+	}
+	riskRules, err := loadRiskRules(ctx)
+	if err != nil {
+		return err
+	}
+	assessRisk(kyc, riskRules)
+
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return err
+	}
+	err = putRecordState(ctx, kycID, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put KYC record: %v", err)
+	}
+	err = ctx.GetStub().DelState(archiveKey(kycID))
+	if err != nil {
+		return fmt.Errorf("failed to delete archived KYC record: %v", err)
+	}
+	err = putRecordIndexes(ctx, kyc)
+	if err != nil {
+		return fmt.Errorf("failed to index KYC record: %v", err)
+	}
+	err = updateRecordCounters(ctx, nil, kyc)
+	if err != nil {
+		return fmt.Errorf("failed to update counters: %v", err)
+	}
+
+	performedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-RESTORED-%d", kycID, s.txTime(ctx).Unix()),
+		KYCID:       kycID,
+		Action:      "RESTORED",
+		PerformedBy: performedBy,
+		PerformedAt: now,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"archivedStatus": archivedStatus,
+			"archivedAt":     archived.ArchivedAt,
+			"status":         kyc.Status,
+			"riskTier":       kyc.RiskTier,
+			"flagCount":      len(kyc.Flags),
+		},
+	}
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+
+	return nil
+}
+
+// GetArchivedKYC returns an archived record with when and by whom it was
+// archived
+func (s *SmartContract) GetArchivedKYC(ctx contractapi.TransactionContextInterface, kycID string) (*ArchivedRecord, error) {
+	archived, err := readArchivedRecord(ctx, kycID)
+	if err != nil {
+		return nil, err
+	}
+	if archived == nil {
+		return nil, fmt.Errorf("KYC record %s is not archived", kycID)
+	}
+	return archived, nil
+}
+
+// readArchivedRecord returns an archived record, or nil if kycID is not archived
+func readArchivedRecord(ctx contractapi.TransactionContextInterface, kycID string) (*ArchivedRecord, error) {
+	archivedJSON, err := ctx.GetStub().GetState(archiveKey(kycID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if archivedJSON == nil {
+		return nil, nil
+	}
+
+	var archived ArchivedRecord
+	err = json.Unmarshal(archivedJSON, &archived)
+	if err != nil {
+		return nil, err
+	}
+	return &archived, nil
+}
+
+// clearRecordStaleScreenings removes a record's stale markers on every
+// reference list, so GetStaleScreenings no longer offers it for rescreening
+func clearRecordStaleScreenings(ctx contractapi.TransactionContextInterface, kycID string) error {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(listObjectType, []string{})
+	if err != nil {
+		return err
+	}
+
+	cleared := 0
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		_, keyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return err
+		}
+		staleKey, err := ctx.GetStub().CreateCompositeKey(staleScreeningIndex, []string{keyParts[0], kycID})
+		if err != nil {
+			return err
+		}
+		existing, err := ctx.GetStub().GetState(staleKey)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return nil
+		}
+		err = ctx.GetStub().DelState(staleKey)
+		if err != nil {
+			return fmt.Errorf("failed to clear stale screening: %v", err)
+		}
+		cleared++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if cleared == 0 {
+		return nil
+	}
+	return incrementCounter(ctx, counterStaleScreens, -cleared)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// AssignKYC assigns a pending record to a verifier's review queue. An empty
+// assignee returns the record to the unassigned pool.
+func (s *SmartContract) AssignKYC(ctx contractapi.TransactionContextInterface, kycID string, assignee string) error {
+	err := requireAttribute(ctx, AttrSenior)
+	if err != nil {
+		return err
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	if kyc.Status != "PENDING" {
+		return fmt.Errorf("KYC record %s is %s; only PENDING records can be assigned", kycID, kyc.Status)
+	}
+
+	assignedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	previous := kyc.AssignedTo
+	kyc.AssignedTo = strings.TrimSpace(assignee)
+	kyc.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return err
+	}
+	err = putRecordState(ctx, kyc.ID, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update KYC record: %v", err)
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-ASSIGNED-%d", kyc.ID, s.txTime(ctx).Unix()),
+		KYCID:       kyc.ID,
+		Action:      "ASSIGNED",
+		PerformedBy: assignedBy,
+		PerformedAt: kyc.UpdatedAt,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"previousAssignee": previous,
+			"assignee":         kyc.AssignedTo,
+		},
+	}
+
+	return s.createHistoryEntry(ctx, historyEntry)
+}
+
+// Escalation records that a verifier referred a pending record to a senior
+// verifier instead of deciding it
+type Escalation struct {
+	Reason           string `json:"reason"`
+	EscalatedBy      string `json:"escalatedBy"`
+	EscalatedAt      string `json:"escalatedAt"`
+	PreviousAssignee string `json:"previousAssignee,omitempty" metadata:",optional"`
+}
+
+// EscalateKYC refers a pending record to senior review. The record leaves
+// the verifier's queue for the unassigned pool, where a senior verifier picks
+// it up with AssignKYC. The escalation is recorded as the caller's;
+// escalatedBy is the caller's own reference for whoever raised it and is
+// kept in the history entry.
+func (s *SmartContract) EscalateKYC(ctx contractapi.TransactionContextInterface, kycID string, reason string, escalatedBy string) error {
+	err := requireAttribute(ctx, AttrVerifier)
+	if err != nil {
+		return err
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return fmt.Errorf("an escalation reason is required")
+	}
+	performedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	if kyc.Status != "PENDING" {
+		return fmt.Errorf("KYC record %s is %s; only PENDING records can be escalated", kycID, kyc.Status)
+	}
+	if kyc.Escalation != nil {
+		return fmt.Errorf("KYC record %s is already escalated", kycID)
+	}
+
+	kyc.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+	kyc.Escalation = &Escalation{
+		Reason:           reason,
+		EscalatedBy:      performedBy,
+		EscalatedAt:      kyc.UpdatedAt,
+		PreviousAssignee: kyc.AssignedTo,
+	}
+	kyc.AssignedTo = ""
+
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return err
+	}
+	err = putRecordState(ctx, kyc.ID, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update KYC record: %v", err)
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-ESCALATED-%d", kyc.ID, s.txTime(ctx).Unix()),
+		KYCID:       kyc.ID,
+		Action:      "ESCALATED",
+		PerformedBy: performedBy,
+		PerformedAt: kyc.UpdatedAt,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"previousAssignee": kyc.Escalation.PreviousAssignee,
+		},
+		Remarks: reason,
+	}
+	if escalatedBy != "" {
+		historyEntry.Details["operatorReference"] = escalatedBy
+	}
+
+	return s.createHistoryEntry(ctx, historyEntry)
+}
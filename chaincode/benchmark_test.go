@@ -0,0 +1,370 @@
+package main
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// benchIndexedFields stand in for the CouchDB indexes a production peer
+// would use for the contract's rich queries
+var benchIndexedFields = []string{"status", "kycId"}
+
+// benchStub is an in-memory world state implementing the stub calls the
+// contract makes. Composite keys are kept sorted per object type and JSON
+// documents are decoded and indexed once on write, so the stub's own lookups
+// do not dominate the measurements as the ledger grows.
+type benchStub struct {
+	shim.ChaincodeStubInterface
+	state     map[string][]byte
+	docs      map[string]map[string]interface{}
+	indexes   map[string]map[string]map[string]bool
+	composite map[string][]string
+	txID      string
+	txCount   int
+}
+
+func newBenchStub() *benchStub {
+	stub := &benchStub{
+		state:     map[string][]byte{},
+		docs:      map[string]map[string]interface{}{},
+		indexes:   map[string]map[string]map[string]bool{},
+		composite: map[string][]string{},
+	}
+	for _, field := range benchIndexedFields {
+		stub.indexes[field] = map[string]map[string]bool{}
+	}
+	return stub
+}
+
+// nextTx starts a new transaction with a fresh ID
+func (s *benchStub) nextTx() {
+	s.txCount++
+	s.txID = fmt.Sprintf("tx%08d", s.txCount)
+}
+
+func (s *benchStub) GetTxID() string { return s.txID }
+
+// GetTxTimestamp gives each transaction a timestamp a second after the last
+func (s *benchStub) GetTxTimestamp() (*timestamp.Timestamp, error) {
+	return &timestamp.Timestamp{Seconds: 1700000000 + int64(s.txCount)}, nil
+}
+
+func (s *benchStub) GetState(key string) ([]byte, error) { return s.state[key], nil }
+
+func (s *benchStub) PutState(key string, value []byte) error {
+	if _, exists := s.state[key]; !exists {
+		if objectType, ok := compositeObjectType(key); ok {
+			keys := s.composite[objectType]
+			i := sort.SearchStrings(keys, key)
+			keys = append(keys, "")
+			copy(keys[i+1:], keys[i:])
+			keys[i] = key
+			s.composite[objectType] = keys
+		}
+	}
+	s.state[key] = value
+
+	s.unindex(key)
+	if len(value) > 0 && value[0] == '{' {
+		var doc map[string]interface{}
+		if json.Unmarshal(value, &doc) == nil {
+			s.docs[key] = doc
+			for field, index := range s.indexes {
+				if value, ok := doc[field].(string); ok {
+					if index[value] == nil {
+						index[value] = map[string]bool{}
+					}
+					index[value][key] = true
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// unindex drops a key's decoded document and index entries
+func (s *benchStub) unindex(key string) {
+	for field, index := range s.indexes {
+		if value, ok := s.docs[key][field].(string); ok {
+			delete(index[value], key)
+		}
+	}
+	delete(s.docs, key)
+}
+
+func (s *benchStub) DelState(key string) error {
+	if _, exists := s.state[key]; exists {
+		if objectType, ok := compositeObjectType(key); ok {
+			keys := s.composite[objectType]
+			i := sort.SearchStrings(keys, key)
+			s.composite[objectType] = append(keys[:i], keys[i+1:]...)
+		}
+	}
+	s.unindex(key)
+	delete(s.state, key)
+	return nil
+}
+
+func (s *benchStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	return "\x00" + objectType + "\x00" + strings.Join(append(attributes, ""), "\x00"), nil
+}
+
+func (s *benchStub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(compositeKey, "\x00"), "\x00"), "\x00")
+	return parts[0], parts[1:], nil
+}
+
+func (s *benchStub) GetStateByPartialCompositeKey(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+	prefix, _ := s.CreateCompositeKey(objectType, attributes)
+	keys := s.composite[objectType]
+	i := sort.SearchStrings(keys, prefix)
+	j := i
+	for j < len(keys) && strings.HasPrefix(keys[j], prefix) {
+		j++
+	}
+	return s.iterator(keys[i:j]), nil
+}
+
+func (s *benchStub) GetStateByRange(startKey string, endKey string) (shim.StateQueryIteratorInterface, error) {
+	keys := []string{}
+	for key := range s.state {
+		if strings.HasPrefix(key, "\x00") || key < startKey || (endKey != "" && key >= endKey) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return s.iterator(keys), nil
+}
+
+// GetQueryResult supports selectors of top-level fields matched by value or
+// by {"$exists": bool}, which covers the queries the contract issues
+func (s *benchStub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	var parsed struct {
+		Selector map[string]interface{} `json:"selector"`
+	}
+	err := json.Unmarshal([]byte(query), &parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates map[string]bool
+	for field, index := range s.indexes {
+		if value, ok := parsed.Selector[field].(string); ok {
+			candidates = index[value]
+			break
+		}
+	}
+	if candidates == nil {
+		candidates = map[string]bool{}
+		for key := range s.docs {
+			candidates[key] = true
+		}
+	}
+
+	keys := []string{}
+	for key := range candidates {
+		if matchesSelector(s.docs[key], parsed.Selector) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return s.iterator(keys), nil
+}
+
+func (s *benchStub) iterator(keys []string) *benchIterator {
+	return &benchIterator{stub: s, keys: keys}
+}
+
+func matchesSelector(doc map[string]interface{}, selector map[string]interface{}) bool {
+	for field, want := range selector {
+		value, present := doc[field]
+		if condition, ok := want.(map[string]interface{}); ok {
+			if exists, ok := condition["$exists"].(bool); ok && exists != present {
+				return false
+			}
+			continue
+		}
+		if !present || value != want {
+			return false
+		}
+	}
+	return true
+}
+
+func compositeObjectType(key string) (string, bool) {
+	if !strings.HasPrefix(key, "\x00") {
+		return "", false
+	}
+	end := strings.IndexByte(key[1:], 0)
+	if end < 0 {
+		return "", false
+	}
+	return key[1 : end+1], true
+}
+
+type benchIterator struct {
+	stub *benchStub
+	keys []string
+	next int
+}
+
+func (it *benchIterator) HasNext() bool { return it.next < len(it.keys) }
+
+func (it *benchIterator) Next() (*queryresult.KV, error) {
+	key := it.keys[it.next]
+	it.next++
+	return &queryresult.KV{Key: key, Value: it.stub.state[key]}, nil
+}
+
+func (it *benchIterator) Close() error { return nil }
+
+// benchIdentity is a client identity without roles
+type benchIdentity struct{}
+
+func (benchIdentity) GetID() (string, error)    { return "x509::CN=bench,O=Org1MSP::CN=ca,O=Org1MSP", nil }
+func (benchIdentity) GetMSPID() (string, error) { return "Org1MSP", nil }
+func (benchIdentity) GetAttributeValue(attrName string) (string, bool, error) {
+	return "", false, nil
+}
+func (benchIdentity) AssertAttributeValue(attrName, attrValue string) error {
+	return fmt.Errorf("attribute %s was not found", attrName)
+}
+func (benchIdentity) GetX509Certificate() (*x509.Certificate, error) { return nil, nil }
+
+func newBenchContext(stub *benchStub) *contractapi.TransactionContext {
+	ctx := new(contractapi.TransactionContext)
+	ctx.SetStub(stub)
+	ctx.SetClientIdentity(benchIdentity{})
+	return ctx
+}
+
+// benchKYCData returns the submission for the i'th benchmark record
+func benchKYCData(i int) string {
+	return fmt.Sprintf(`{"id":"KYC%08d","userId":"user%d","name":"Bench Customer %d","email":"customer%d@example.com","phone":"98%08d","pan":"ABCPD%04dK","dateOfBirth":"1990-01-01","address":{"street":"12 MG Rd","city":"Pune","state":"MH","pincode":"411001","country":"IN"}}`, i, i, i, i, i, i%10000)
+}
+
+// seedRecords writes count records and their lookup index entries directly
+// to the world state, one in every verifiedEvery of them VERIFIED and the
+// rest PENDING
+func seedRecords(b *testing.B, stub *benchStub, count int, verifiedEvery int) {
+	for i := 0; i < count; i++ {
+		status := "PENDING"
+		if i%verifiedEvery == 0 {
+			status = "VERIFIED"
+		}
+		kyc := KYCRecord{
+			ID:                fmt.Sprintf("KYC%08d", i),
+			UserID:            fmt.Sprintf("user%d", i),
+			OwnerMSP:          "Org1MSP",
+			EntityType:        "INDIVIDUAL",
+			Name:              fmt.Sprintf("Bench Customer %d", i),
+			Email:             fmt.Sprintf("customer%d@example.com", i),
+			Phone:             fmt.Sprintf("+9198%08d", i),
+			PAN:               fmt.Sprintf("ABCPD%04dK", i%10000),
+			DateOfBirth:       "1990-01-01",
+			Address:           Address{Street: "12 MG Road", City: "Pune", State: "Maharashtra", Pincode: "411001", Country: "IN"},
+			DocumentHashes:    []DocumentHash{},
+			Status:            status,
+			VerificationLevel: "L1",
+			CreatedAt:         "2026-01-10T09:00:00Z",
+			UpdatedAt:         "2026-01-10T09:00:00Z",
+			RiskTier:          RiskLow,
+		}
+		kycJSON, err := json.Marshal(kyc)
+		if err != nil {
+			b.Fatal(err)
+		}
+		key, _ := stub.CreateCompositeKey(recordObjectType, []string{kyc.ID})
+		stub.PutState(key, kycJSON)
+		for entry := range lookupEntries(&kyc) {
+			indexKey, _ := stub.CreateCompositeKey(entry.index, []string{entry.attribute, kyc.ID})
+			stub.PutState(indexKey, []byte{0x00})
+		}
+	}
+}
+
+func BenchmarkCreateKYC(b *testing.B) {
+	contract := new(SmartContract)
+	stub := newBenchStub()
+	ctx := newBenchContext(stub)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stub.nextTx()
+		err := contract.CreateKYC(ctx, benchKYCData(i))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetKYCByStatus(b *testing.B) {
+	contract := new(SmartContract)
+	stub := newBenchStub()
+	ctx := newBenchContext(stub)
+	seedRecords(b, stub, 100000, 20)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stub.nextTx()
+		records, err := contract.GetKYCByStatus(ctx, "VERIFIED", "")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(records) != 5000 {
+			b.Fatalf("expected 5000 records, got %d", len(records))
+		}
+	}
+}
+
+func BenchmarkGetKYCHistory(b *testing.B) {
+	contract := new(SmartContract)
+	stub := newBenchStub()
+	ctx := newBenchContext(stub)
+	seedRecords(b, stub, 1000, 2)
+	for i := 0; i < 1000; i++ {
+		kycID := fmt.Sprintf("KYC%08d", i)
+		for j := 0; j < 20; j++ {
+			entry := HistoryEntry{
+				ID:          fmt.Sprintf("%s-UPDATED-%d", kycID, j),
+				KYCID:       kycID,
+				Action:      "UPDATED",
+				PerformedBy: "x509::CN=bench,O=Org1MSP::CN=ca,O=Org1MSP",
+				PerformedAt: "2026-01-10T09:00:00Z",
+				TxID:        fmt.Sprintf("seed%d-%d", i, j),
+				Details:     map[string]interface{}{"oldStatus": "PENDING", "newStatus": "PENDING"},
+			}
+			entryJSON, err := json.Marshal(entry)
+			if err != nil {
+				b.Fatal(err)
+			}
+			key, _ := stub.CreateCompositeKey(historyObjectType, []string{kycID, entry.TxID, "000000"})
+			stub.PutState(key, entryJSON)
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stub.nextTx()
+		entries, err := contract.GetKYCHistory(ctx, fmt.Sprintf("KYC%08d", i%1000))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(entries) != 20 {
+			b.Fatalf("expected 20 history entries, got %d", len(entries))
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// internalBlacklist is the reference list of customers barred from
+// onboarding. Entries are keyed "pan:<panHash>" for a PAN match or
+// "namedob:<normalized name>|<dobHash>" for a name and date of birth match,
+// where hashes are hex SHA-256 digests of the upper-cased PAN and the
+// YYYY-MM-DD date of birth.
+const internalBlacklist = "INTERNAL_BLACKLIST"
+
+// Blacklist outcomes and override decisions
+const (
+	BlacklistBlocked    = "BLOCKED"
+	BlacklistOverridden = "OVERRIDDEN"
+
+	OverrideRequested = "REQUESTED"
+	OverrideApproved  = "APPROVED"
+	OverrideDenied    = "DENIED"
+)
+
+// BlacklistMatch is one blacklist entry matched by a check
+type BlacklistMatch struct {
+	List        string `json:"list"`
+	EntryKey    string `json:"entryKey"`
+	ListVersion int    `json:"listVersion"` // list version in which the entry was written
+	MatchedOn   string `json:"matchedOn"`   // PAN or NAME_DOB
+	Reason      string `json:"reason,omitempty" metadata:",optional"`
+}
+
+// BlacklistCheckResult reports the outcome of a blacklist check
+type BlacklistCheckResult struct {
+	Hit     bool             `json:"hit"`
+	Matches []BlacklistMatch `json:"matches"`
+}
+
+// BlacklistOutcome records a blacklist hit against a KYC record and any override of it
+type BlacklistOutcome struct {
+	Outcome   string             `json:"outcome"` // BLOCKED, OVERRIDDEN
+	Matches   []BlacklistMatch   `json:"matches"`
+	CheckedAt string             `json:"checkedAt"`
+	Override  *BlacklistOverride `json:"override,omitempty" metadata:",optional"`
+}
+
+// BlacklistOverride is a request to onboard a blacklisted customer and its decision
+type BlacklistOverride struct {
+	Status        string `json:"status"` // REQUESTED, APPROVED, DENIED
+	Justification string `json:"justification"`
+	RequestedBy   string `json:"requestedBy"`
+	RequestedAt   string `json:"requestedAt"`
+	DecidedBy     string `json:"decidedBy,omitempty" metadata:",optional"`
+	DecidedAt     string `json:"decidedAt,omitempty" metadata:",optional"`
+	Remarks       string `json:"remarks,omitempty" metadata:",optional"`
+}
+
+// CheckBlacklist looks up a customer on the internal blacklist by PAN hash
+// and by normalized name with date of birth hash. Only verifiers and
+// administrators of organisations on the verifier allowlist can check it.
+func (s *SmartContract) CheckBlacklist(ctx contractapi.TransactionContextInterface, panHash string, nameNormalized string, dobHash string) (*BlacklistCheckResult, error) {
+	err := requireAllowedMSP(ctx, AllowlistVerifier)
+	if err != nil {
+		return nil, err
+	}
+	_, err = requireAnyAttribute(ctx, AttrVerifier, AttrAdmin)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := checkBlacklist(ctx, panHash, nameNormalized, dobHash)
+	if err != nil {
+		return nil, err
+	}
+	return &BlacklistCheckResult{Hit: len(matches) > 0, Matches: matches}, nil
+}
+
+// RequestBlacklistOverride asks for a blocked record to be released for verification
+func (s *SmartContract) RequestBlacklistOverride(ctx contractapi.TransactionContextInterface, kycID string, justification string) error {
+	err := requireAttribute(ctx, AttrVerifier)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(justification) == "" {
+		return fmt.Errorf("justification is required")
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	if kyc.Status != "BLOCKED" || kyc.Blacklist == nil {
+		return fmt.Errorf("KYC record %s is not blocked", kycID)
+	}
+	if kyc.Blacklist.Override != nil && kyc.Blacklist.Override.Status == OverrideRequested {
+		return fmt.Errorf("an override of KYC record %s is already awaiting a decision", kycID)
+	}
+
+	requestedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	kyc.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+	kyc.Blacklist.Override = &BlacklistOverride{
+		Status:        OverrideRequested,
+		Justification: justification,
+		RequestedBy:   requestedBy,
+		RequestedAt:   kyc.UpdatedAt,
+	}
+
+	return s.saveBlacklistChange(ctx, kyc, "BLACKLIST_OVERRIDE_REQUESTED", requestedBy, justification)
+}
+
+// DecideBlacklistOverride approves or denies a pending override request. An
+// approved override returns the record to PENDING so verification can
+// proceed; the decision must be taken by an administrator other than the
+// requester.
+func (s *SmartContract) DecideBlacklistOverride(ctx contractapi.TransactionContextInterface, kycID string, approve bool, remarks string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	if kyc.Blacklist == nil || kyc.Blacklist.Override == nil || kyc.Blacklist.Override.Status != OverrideRequested {
+		return fmt.Errorf("KYC record %s has no pending blacklist override", kycID)
+	}
+
+	decidedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	if decidedBy == kyc.Blacklist.Override.RequestedBy {
+		return fmt.Errorf("an override cannot be decided by its requester")
+	}
+
+	before := *kyc
+	kyc.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+	override := kyc.Blacklist.Override
+	override.DecidedBy = decidedBy
+	override.DecidedAt = kyc.UpdatedAt
+	override.Remarks = remarks
+
+	action := "BLACKLIST_OVERRIDE_DENIED"
+	override.Status = OverrideDenied
+	if approve {
+		action = "BLACKLIST_OVERRIDE_APPROVED"
+		override.Status = OverrideApproved
+		kyc.Blacklist.Outcome = BlacklistOverridden
+		kyc.Status = "PENDING"
+	}
+
+	err = s.saveBlacklistChange(ctx, kyc, action, decidedBy, remarks)
+	if err != nil || !approve {
+		return err
+	}
+	err = updateRecordCounters(ctx, &before, kyc)
+	if err != nil {
+		return fmt.Errorf("failed to update counters: %v", err)
+	}
+
+	return recordException(ctx, ExceptionEntry{
+		Type:          ExceptionBlacklistOverride,
+		KYCID:         kyc.ID,
+		RaisedAt:      kyc.UpdatedAt,
+		RequestedBy:   override.RequestedBy,
+		ApprovedBy:    decidedBy,
+		Justification: override.Justification,
+		Details: map[string]interface{}{
+			"matchCount": len(kyc.Blacklist.Matches),
+		},
+	})
+}
+
+// checkBlacklist returns the internal blacklist entries matching a customer
+func checkBlacklist(ctx contractapi.TransactionContextInterface, panHash string, nameNormalized string, dobHash string) ([]BlacklistMatch, error) {
+	lookups := []struct{ matchedOn, key string }{}
+	if panHash != "" {
+		lookups = append(lookups, struct{ matchedOn, key string }{"PAN", "pan:" + panHash})
+	}
+	if nameNormalized != "" && dobHash != "" {
+		lookups = append(lookups, struct{ matchedOn, key string }{"NAME_DOB", "namedob:" + nameNormalized + "|" + dobHash})
+	}
+
+	matches := []BlacklistMatch{}
+	for _, lookup := range lookups {
+		entry, err := getListEntry(ctx, internalBlacklist, lookup.key)
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			continue
+		}
+		matches = append(matches, BlacklistMatch{
+			List:        entry.List,
+			EntryKey:    entry.Key,
+			ListVersion: entry.Version,
+			MatchedOn:   lookup.matchedOn,
+			Reason:      entry.Reason,
+		})
+	}
+	return matches, nil
+}
+
+// screenBlacklist blocks a new record that matches the internal blacklist
+func screenBlacklist(ctx contractapi.TransactionContextInterface, kyc *KYCRecord, checkedAt string) error {
+	dobHash := ""
+	if kyc.DateOfBirth != "" {
+		dobHash = identifierHash(kyc.DateOfBirth)
+	}
+
+	matches, err := checkBlacklist(ctx, identifierHash(strings.ToUpper(kyc.PAN)), normalizePersonName(kyc.Name), dobHash)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	kyc.Status = "BLOCKED"
+	kyc.Blacklist = &BlacklistOutcome{
+		Outcome:   BlacklistBlocked,
+		Matches:   matches,
+		CheckedAt: checkedAt,
+	}
+	return nil
+}
+
+// saveBlacklistChange stores a record after an override step and writes its history entry
+func (s *SmartContract) saveBlacklistChange(ctx contractapi.TransactionContextInterface, kyc *KYCRecord, action string, performedBy string, remarks string) error {
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return err
+	}
+
+	err = putRecordState(ctx, kyc.ID, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update KYC record: %v", err)
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-%s-%d", kyc.ID, action, s.txTime(ctx).Unix()),
+		KYCID:       kyc.ID,
+		Action:      action,
+		PerformedBy: performedBy,
+		PerformedAt: kyc.UpdatedAt,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"status":         kyc.Status,
+			"overrideStatus": kyc.Blacklist.Override.Status,
+		},
+		Remarks: remarks,
+	}
+
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return nil
+}
+
+// identifierHash returns the hex SHA-256 digest used to match identifiers without storing them
+func identifierHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizePersonName lower-cases a name, drops punctuation and collapses
+// whitespace so that spelling variants such as "R. K. Sharma" and
+// "r k sharma" match
+func normalizePersonName(name string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsSpace(r) {
+			return unicode.ToLower(r)
+		}
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, name)
+	return collapseSpaces(cleaned)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Capability names. Each optional feature is reported as enabled or not,
+// with where it is switched: by the consortium policy, the contract
+// configuration, the ledger's contents, or the contract itself for features
+// a chaincode version does or does not implement.
+const (
+	CapabilityPrivateData       = "PRIVATE_DATA"
+	CapabilityCredentials       = "CREDENTIALS"
+	CapabilityDualApproval      = "DUAL_APPROVAL"
+	CapabilityConsent           = "CONSENT_ENFORCEMENT"
+	CapabilityFaceMatch         = "FACE_MATCH_REQUIRED"
+	CapabilityDocumentQuality   = "DOCUMENT_QUALITY_REQUIRED"
+	CapabilityFieldEncryption   = "FIELD_ENCRYPTION"
+	CapabilityAllowlists        = "MSP_ALLOWLISTS"
+	CapabilityRateLimiting      = "RATE_LIMITING"
+	CapabilityTimestamping      = "TIMESTAMPING"
+	CapabilityHistorySignatures = "HISTORY_SIGNATURES"
+	CapabilityValidationHooks   = "VALIDATION_HOOKS"
+	CapabilitySubmissionQuotas  = "SUBMISSION_QUOTAS"
+	CapabilityTelemetry         = "TRANSACTION_TELEMETRY"
+	CapabilityMasking           = "PII_MASKING"
+)
+
+// Where a capability is switched
+const (
+	CapabilitySourceContract = "contract"
+	CapabilitySourcePolicy   = "policy"
+	CapabilitySourceConfig   = "config"
+	CapabilitySourceLedger   = "ledger"
+)
+
+// Capabilities describes the contract a network runs and the optional
+// features enabled on it
+type Capabilities struct {
+	Contract VersionInfo  `json:"contract"`
+	Features []Capability `json:"features"`
+}
+
+// Capability is one optional feature
+type Capability struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Source  string `json:"source"` // contract, policy, config or ledger
+}
+
+// GetCapabilities reports the chaincode's build and which optional features
+// are enabled in this deployment, so generic clients can adapt their flows.
+// Any identity can read it.
+func (s *SmartContract) GetCapabilities(ctx contractapi.TransactionContextInterface) (*Capabilities, error) {
+	version, err := s.GetVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := currentPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fieldKeys, err := ctx.GetStub().GetStateByPartialCompositeKey(fieldKeyObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	encryption := fieldKeys.HasNext()
+	fieldKeys.Close()
+	allowlists := config.Allowlists
+	hooks, err := loadHookConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Capabilities{
+		Contract: *version,
+		Features: []Capability{
+			// records submitted with CreateKYCPrivate keep their personal
+			// details in their organisation's implicit collection
+			{Name: CapabilityPrivateData, Enabled: true, Source: CapabilitySourceContract},
+			{Name: CapabilityCredentials, Enabled: false, Source: CapabilitySourceContract},
+			{Name: CapabilityDualApproval, Enabled: policy.DualApproval, Source: CapabilitySourcePolicy},
+			{Name: CapabilityConsent, Enabled: policy.RequireConsent, Source: CapabilitySourcePolicy},
+			{Name: CapabilityFaceMatch, Enabled: config.RequireFaceMatch, Source: CapabilitySourceConfig},
+			{Name: CapabilityDocumentQuality, Enabled: config.RequireDocumentQuality, Source: CapabilitySourceConfig},
+			{Name: CapabilityFieldEncryption, Enabled: encryption, Source: CapabilitySourceLedger},
+			{Name: CapabilityAllowlists, Enabled: len(allowlists.SubmitterMSPs)+len(allowlists.VerifierMSPs)+len(allowlists.RegulatorMSPs) > 0, Source: CapabilitySourceConfig},
+			{Name: CapabilityRateLimiting, Enabled: config.RateLimit.Capacity > 0, Source: CapabilitySourceConfig},
+			{Name: CapabilityTimestamping, Enabled: len(config.TrustedTSARoots) > 0, Source: CapabilitySourceConfig},
+			{Name: CapabilityHistorySignatures, Enabled: true, Source: CapabilitySourceContract},
+			{Name: CapabilityValidationHooks, Enabled: len(hooks.Hooks) > 0, Source: CapabilitySourceConfig},
+			{Name: CapabilitySubmissionQuotas, Enabled: config.SubmissionQuotas.enabled(), Source: CapabilitySourceConfig},
+			{Name: CapabilityTelemetry, Enabled: config.TelemetrySampleEvery > 0, Source: CapabilitySourceConfig},
+			{Name: CapabilityMasking, Enabled: len(config.Masking.Rules) > 0, Source: CapabilitySourceConfig},
+		},
+	}, nil
+}
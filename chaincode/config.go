@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const configKey = "CONFIG"
+
+// Actions taken when a screening check matches
+const (
+	ScreeningReject = "REJECT"
+	ScreeningFlag   = "FLAG"
+)
+
+// ContractConfig holds contract-wide settings maintained by administrators
+type ContractConfig struct {
+	EmailBlocklistAction    string       `json:"emailBlocklistAction"`    // REJECT or FLAG
+	ScreeningAlertThreshold int          `json:"screeningAlertThreshold"` // match score at which a screening run opens an alert
+	VerificationSLAHours    int          `json:"verificationSlaHours"`    // hours from submission within which a record must be decided, until a consortium policy sets them
+	RekycYears              RekycPeriods `json:"rekycYears"`              // years a verification stays valid, by risk tier
+	MaxResponseBytes        int          `json:"maxResponseBytes"`        // encoded size at which paginated queries stop and return a partial page
+	ArchiveAfterDays        int          `json:"archiveAfterDays"`        // days a REJECTED or EXPIRED record must go unchanged before it can be archived, until a consortium policy sets them
+	DualWriteRecords        bool         `json:"dualWriteRecords"`        // also write records under the schema 2 key, during a migration to it
+	FaceMatchThreshold      int          `json:"faceMatchThreshold"`      // face match score at which a selfie passes
+	RequireFaceMatch        bool         `json:"requireFaceMatch"`        // verification needs a passing face match
+	MaxBlurScore            int          `json:"maxBlurScore"`            // highest document blur score that passes
+	MaxGlareScore           int          `json:"maxGlareScore"`           // highest document glare score that passes
+	RequireDocumentQuality  bool         `json:"requireDocumentQuality"`  // verification needs a passing quality assessment of every document
+	RateLimit               RateLimit    `json:"rateLimit"`               // per-caller limits on expensive queries
+	MaxPageSize             int          `json:"maxPageSize"`             // largest page a paginated query may ask for
+	MaxIteratorResults      int          `json:"maxIteratorResults"`      // results an unpaginated query may read before it fails
+	TelemetrySampleEvery    int          `json:"telemetrySampleEvery"`    // sample one in this many transactions' read and write sets, see telemetry.go; 0 turns sampling off
+	// per-organisation caps on CreateKYC by licence tier, see quota.go
+	SubmissionQuotas SubmissionQuotas `json:"submissionQuotas"`
+	// masking of personal details in query responses for lower roles, see masking.go
+	Masking MaskingProfile `json:"masking"`
+	// organisations allowed to submit, verify and regulate, see allowlist.go
+	Allowlists MSPAllowlists `json:"allowlists"`
+	// PEM root certificates of the timestamp authorities whose tokens StoreTimestampToken accepts
+	TrustedTSARoots []string `json:"trustedTsaRoots,omitempty" metadata:",optional"`
+	Version         int      `json:"version,omitempty" metadata:",optional"` // changes made to the configuration, 0 until the first
+	UpdatedAt       string   `json:"updatedAt,omitempty" metadata:",optional"`
+	UpdatedBy       string   `json:"updatedBy,omitempty" metadata:",optional"`
+}
+
+// RekycPeriods are the re-KYC intervals in years for each risk tier
+type RekycPeriods struct {
+	Low    int `json:"low"`
+	Medium int `json:"medium"`
+	High   int `json:"high"`
+}
+
+// years returns the re-KYC interval for a risk tier; unknown tiers get the shortest
+func (p RekycPeriods) years(tier string) int {
+	switch tier {
+	case RiskLow:
+		return p.Low
+	case RiskMedium:
+		return p.Medium
+	default:
+		return p.High
+	}
+}
+
+// defaultConfig returns the settings used until an administrator changes them
+func defaultConfig() *ContractConfig {
+	return &ContractConfig{
+		EmailBlocklistAction:    ScreeningReject,
+		ScreeningAlertThreshold: 85,
+		VerificationSLAHours:    72,
+		RekycYears:              RekycPeriods{Low: 10, Medium: 8, High: 2},
+		MaxResponseBytes:        4 << 20,
+		ArchiveAfterDays:        365,
+		FaceMatchThreshold:      80,
+		MaxBlurScore:            40,
+		MaxGlareScore:           40,
+		MaxPageSize:             1000,
+		MaxIteratorResults:      10000,
+		Allowlists:              MSPAllowlists{SubmitterMSPs: []string{}, VerifierMSPs: []string{}, RegulatorMSPs: []string{}},
+		SubmissionQuotas:        SubmissionQuotas{Tiers: []QuotaTier{}},
+		Masking:                 MaskingProfile{UnmaskedRole: MaskRoleSenior, Rules: []MaskRule{}},
+	}
+}
+
+// GetConfig returns the current contract configuration
+func (s *SmartContract) GetConfig(ctx contractapi.TransactionContextInterface) (*ContractConfig, error) {
+	return loadConfig(ctx)
+}
+
+// SetConfig updates the contract configuration. Fields omitted from
+// configData keep their current values.
+func (s *SmartContract) SetConfig(ctx contractapi.TransactionContextInterface, configData string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	version := config.Version
+	err = json.Unmarshal([]byte(configData), config)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal config: %v", err)
+	}
+	err = validateConfig(config)
+	if err != nil {
+		return err
+	}
+	config.Version = version + 1
+
+	config.UpdatedBy, err = clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	config.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(configKey, configJSON)
+}
+
+// loadConfig reads the stored configuration over the defaults
+func loadConfig(ctx contractapi.TransactionContextInterface) (*ContractConfig, error) {
+	config := defaultConfig()
+	configJSON, err := ctx.GetStub().GetState(configKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %v", err)
+	}
+	if configJSON != nil {
+		err = json.Unmarshal(configJSON, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+func validateConfig(config *ContractConfig) error {
+	if config.EmailBlocklistAction != ScreeningReject && config.EmailBlocklistAction != ScreeningFlag {
+		return fmt.Errorf("emailBlocklistAction must be %s or %s", ScreeningReject, ScreeningFlag)
+	}
+	if config.ScreeningAlertThreshold < 0 || config.ScreeningAlertThreshold > 100 {
+		return fmt.Errorf("screeningAlertThreshold must be between 0 and 100")
+	}
+	if config.VerificationSLAHours <= 0 {
+		return fmt.Errorf("verificationSlaHours must be positive")
+	}
+	if config.RekycYears.Low <= 0 || config.RekycYears.Medium <= 0 || config.RekycYears.High <= 0 {
+		return fmt.Errorf("rekycYears must be positive for every risk tier")
+	}
+	if config.MaxResponseBytes <= 0 {
+		return fmt.Errorf("maxResponseBytes must be positive")
+	}
+	if config.ArchiveAfterDays < 0 {
+		return fmt.Errorf("archiveAfterDays must not be negative")
+	}
+	if config.FaceMatchThreshold < 0 || config.FaceMatchThreshold > 100 {
+		return fmt.Errorf("faceMatchThreshold must be between 0 and 100")
+	}
+	if config.MaxBlurScore < 0 || config.MaxBlurScore > 100 || config.MaxGlareScore < 0 || config.MaxGlareScore > 100 {
+		return fmt.Errorf("maxBlurScore and maxGlareScore must be between 0 and 100")
+	}
+	if config.RateLimit.Capacity < 0 {
+		return fmt.Errorf("rateLimit.capacity must not be negative")
+	}
+	if config.RateLimit.Capacity > 0 && config.RateLimit.RefillPerMinute <= 0 {
+		return fmt.Errorf("rateLimit.refillPerMinute must be positive when rate limiting is on")
+	}
+	if config.MaxPageSize <= 0 {
+		return fmt.Errorf("maxPageSize must be positive")
+	}
+	if config.MaxIteratorResults < config.MaxPageSize {
+		return fmt.Errorf("maxIteratorResults must be at least maxPageSize")
+	}
+	if config.TelemetrySampleEvery < 0 {
+		return fmt.Errorf("telemetrySampleEvery must not be negative")
+	}
+	err := validateAllowlists(&config.Allowlists)
+	if err != nil {
+		return err
+	}
+	err = validateSubmissionQuotas(&config.SubmissionQuotas)
+	if err != nil {
+		return err
+	}
+	err = validateMaskingProfile(&config.Masking)
+	if err != nil {
+		return err
+	}
+	return validateTSARoots(config.TrustedTSARoots)
+}
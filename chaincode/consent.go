@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// Consent receipts. A record owner's consent to the use of their data is
+// stored as a receipt modelled on the Kantara Initiative Consent Receipt
+// Specification, under "CONSENT~<kycID>~<receipt ID>". The receipt is
+// returned to the organisation recording the consent to hand to the
+// customer, and can be fetched again with GetConsentReceipt as proof of
+// what was agreed. It is enforced through the access grants it issues (see
+// grant.go).
+const consentObjectType = "CONSENT"
+
+// consentReceiptVersion is the Kantara specification the receipts follow
+const consentReceiptVersion = "KI-CR-v1.1.0"
+
+// purposeCodePattern matches a purpose code, such as ACCOUNT_OPENING
+var purposeCodePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]{1,63}$`)
+
+// consentDataCategories are the categories of record data a consent can
+// cover, with the record fields each one holds
+var consentDataCategories = map[string][]string{
+	"IDENTITY":  {"name", "dateOfBirth", "pan", "entityType", "entityDetails"},
+	"CONTACT":   {"email", "phone"},
+	"ADDRESS":   {"address", "rawAddress"},
+	"DOCUMENTS": {"documentHashes"},
+	"BIOMETRIC": {"faceMatch", "faceHash"},
+}
+
+// ConsentReceipt records what a record owner agreed to: which categories of
+// their data may be used, for which purposes, by which parties, and until
+// when
+type ConsentReceipt struct {
+	Version          string   `json:"version"`
+	ReceiptID        string   `json:"consentReceiptId"`
+	KYCID            string   `json:"kycId"`
+	PrincipalID      string   `json:"piiPrincipalId"` // the record's user ID
+	Controller       string   `json:"piiController"`  // MSP of the organisation holding the record
+	Recipients       []string `json:"recipients"`     // MSPs of the third parties the data may be disclosed to
+	Purposes         []string `json:"purposes"`
+	DataCategories   []string `json:"piiCategories"`
+	CollectionMethod string   `json:"collectionMethod,omitempty" metadata:",optional"` // how consent was obtained, such as ONLINE_FORM
+	Jurisdiction     string   `json:"jurisdiction,omitempty" metadata:",optional"`
+	ConsentRef       string   `json:"consentRef,omitempty" metadata:",optional"` // reference to the owner's signed declaration
+	ConsentTimestamp string   `json:"consentTimestamp"`
+	ExpiresAt        string   `json:"expiresAt"`
+	RecordedBy       string   `json:"recordedBy"`
+	TxID             string   `json:"txId"`
+	RevokedAt        string   `json:"revokedAt,omitempty" metadata:",optional"`
+	RevokedBy        string   `json:"revokedBy,omitempty" metadata:",optional"`
+	RevocationID     string   `json:"revocationId,omitempty" metadata:",optional"` // the transaction that revoked the consent
+}
+
+// consentInput is the payload accepted by GrantConsent
+type consentInput struct {
+	Recipients       []string `json:"recipients"`
+	Purposes         []string `json:"purposes"`
+	DataCategories   []string `json:"piiCategories"`
+	CollectionMethod string   `json:"collectionMethod"`
+	Jurisdiction     string   `json:"jurisdiction"`
+	ConsentRef       string   `json:"consentRef"`
+	ExpiresAt        string   `json:"expiresAt"`
+}
+
+// GrantConsent records a record owner's consent and returns its receipt.
+// Only the organisation that submitted the record can record consent to it.
+func (s *SmartContract) GrantConsent(ctx contractapi.TransactionContextInterface, kycID string, consentData string) (*ConsentReceipt, error) {
+	var input consentInput
+	err := json.Unmarshal([]byte(consentData), &input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal consent: %v", err)
+	}
+
+	if len(input.Purposes) == 0 {
+		return nil, fmt.Errorf("consent must name at least one purpose")
+	}
+	for _, purpose := range input.Purposes {
+		if !purposeCodePattern.MatchString(purpose) {
+			return nil, fmt.Errorf("invalid purpose code %q", purpose)
+		}
+	}
+	if len(input.DataCategories) == 0 {
+		return nil, fmt.Errorf("consent must cover at least one data category")
+	}
+	for _, category := range input.DataCategories {
+		if _, ok := consentDataCategories[category]; !ok {
+			return nil, fmt.Errorf("unknown data category %q", category)
+		}
+	}
+	for _, recipient := range input.Recipients {
+		if strings.TrimSpace(recipient) == "" {
+			return nil, fmt.Errorf("recipients must be MSP IDs")
+		}
+		delegate, err := isDelegate(ctx, recipient)
+		if err != nil {
+			return nil, err
+		}
+		if delegate {
+			return nil, fmt.Errorf("recipient %s is a delegate; grant it access through its sponsor with GrantAccess", recipient)
+		}
+	}
+	now := s.txTime(ctx)
+	expiresAt, err := time.Parse(time.RFC3339, input.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("expiresAt must be an RFC 3339 timestamp")
+	}
+	if !expiresAt.After(now) {
+		return nil, fmt.Errorf("expiresAt must be in the future")
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return nil, err
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	if kyc.OwnerMSP != "" && kyc.OwnerMSP != mspID {
+		return nil, fmt.Errorf("KYC record %s is owned by %s", kycID, kyc.OwnerMSP)
+	}
+	recordedBy, err := clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	receipt := &ConsentReceipt{
+		Version:          consentReceiptVersion,
+		ReceiptID:        ctx.GetStub().GetTxID(),
+		KYCID:            kycID,
+		PrincipalID:      kyc.UserID,
+		Controller:       mspID,
+		Recipients:       sortedUnique(input.Recipients),
+		Purposes:         sortedUnique(input.Purposes),
+		DataCategories:   sortedUnique(input.DataCategories),
+		CollectionMethod: input.CollectionMethod,
+		Jurisdiction:     input.Jurisdiction,
+		ConsentRef:       input.ConsentRef,
+		ConsentTimestamp: now.Format(time.RFC3339),
+		ExpiresAt:        expiresAt.UTC().Format(time.RFC3339),
+		RecordedBy:       recordedBy,
+		TxID:             ctx.GetStub().GetTxID(),
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(consentObjectType, []string{kycID, receipt.ReceiptID})
+	if err != nil {
+		return nil, err
+	}
+	receiptJSON, err := json.Marshal(receipt)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(key, receiptJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put consent receipt: %v", err)
+	}
+	err = issueConsentGrants(ctx, receipt)
+	if err != nil {
+		return nil, err
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-CONSENT_GRANTED-%d", kycID, s.txTime(ctx).Unix()),
+		KYCID:       kycID,
+		Action:      "CONSENT_GRANTED",
+		PerformedBy: recordedBy,
+		PerformedAt: receipt.ConsentTimestamp,
+		TxID:        receipt.TxID,
+		Details: map[string]interface{}{
+			"consentReceiptId": receipt.ReceiptID,
+			"purposes":         receipt.Purposes,
+			"piiCategories":    receipt.DataCategories,
+			"recipients":       receipt.Recipients,
+			"expiresAt":        receipt.ExpiresAt,
+		},
+	}
+
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return receipt, nil
+}
+
+// GetConsentReceipt returns a consent receipt of a record
+func (s *SmartContract) GetConsentReceipt(ctx contractapi.TransactionContextInterface, kycID string, receiptID string) (*ConsentReceipt, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(consentObjectType, []string{kycID, receiptID})
+	if err != nil {
+		return nil, err
+	}
+	receiptJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if receiptJSON == nil {
+		return nil, fmt.Errorf("KYC record %s has no consent receipt %s", kycID, receiptID)
+	}
+
+	var receipt ConsentReceipt
+	err = json.Unmarshal(receiptJSON, &receipt)
+	if err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+// hasCurrentConsent reports whether a record has a consent receipt that is
+// neither revoked nor expired at the RFC 3339 time now
+func hasCurrentConsent(ctx contractapi.TransactionContextInterface, kycID string, now string) (bool, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(consentObjectType, []string{kycID})
+	if err != nil {
+		return false, err
+	}
+
+	current := false
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		var receipt ConsentReceipt
+		err := json.Unmarshal(queryResponse.Value, &receipt)
+		if err != nil {
+			return err
+		}
+		// timestamps are all UTC RFC 3339, so they compare as strings
+		if receipt.RevokedAt == "" && receipt.ExpiresAt > now {
+			current = true
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return current, nil
+}
+
+// sortedUnique returns values sorted with duplicates removed
+func sortedUnique(values []string) []string {
+	unique := []string{}
+	seen := map[string]bool{}
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
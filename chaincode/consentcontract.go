@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Consent management. The ConsentContract is the interface a bank uses to
+// record a customer's consent to one other organisation reading their
+// record: GrantConsent records a consent receipt naming that organisation
+// and one scope, RevokeConsent withdraws it, and CheckConsent tells whether
+// a consent covering a scope is in force. A scope is a purpose code, such as
+// ACCOUNT_OPENING, optionally followed by a colon and the data categories
+// consented to, such as ACCOUNT_OPENING:IDENTITY,ADDRESS; a scope naming
+// no categories covers them all.
+//
+// Consents recorded here are receipts like any other (see consent.go), and
+// are enforced by the grants they issue: ReadKYC refuses callers from other
+// organisations without an active grant for the purpose they give, and logs
+// each read it allows in the access log with the consent receipt its grant
+// was issued for.
+
+// ConsentContract manages consent to organisations reading records. Its
+// functions are called as ConsentContract:<function>.
+type ConsentContract struct {
+	contractapi.Contract
+	records SmartContract
+}
+
+// ConsentCheck is whether a consent covering a scope lets an organisation
+// read a record
+type ConsentCheck struct {
+	KYCID          string   `json:"kycId"`
+	Grantee        string   `json:"grantee"`
+	Purpose        string   `json:"purpose"`
+	DataCategories []string `json:"piiCategories"`
+	Active         bool     `json:"active"`
+	ReceiptID      string   `json:"consentReceiptId,omitempty" metadata:",optional"` // the consent in force, when one is
+	GrantID        string   `json:"grantId,omitempty" metadata:",optional"`          // the grant enforcing it
+	ExpiresAt      string   `json:"expiresAt,omitempty" metadata:",optional"`
+}
+
+// GetEvaluateTransactions lists the ConsentContract functions that only read
+// the ledger
+func (c *ConsentContract) GetEvaluateTransactions() []string {
+	return []string{
+		"CheckConsent",
+	}
+}
+
+// GetTransactionContextHandler runs the contract's transactions in a
+// txContext, as the SmartContract's are
+func (c *ConsentContract) GetTransactionContextHandler() contractapi.SettableTransactionContextInterface {
+	return c.records.GetTransactionContextHandler()
+}
+
+// GetBeforeTransaction runs the beforeTransaction steps
+func (c *ConsentContract) GetBeforeTransaction() interface{} {
+	return c.records.GetBeforeTransaction()
+}
+
+// GetAfterTransaction runs the afterTransaction steps
+func (c *ConsentContract) GetAfterTransaction() interface{} {
+	return c.records.GetAfterTransaction()
+}
+
+// GrantConsent records the record owner's consent to granteeMSP reading
+// their record within scope until expiry, an RFC 3339 time, and returns the
+// receipt. Only the organisation that submitted the record can record
+// consent to it.
+func (c *ConsentContract) GrantConsent(ctx contractapi.TransactionContextInterface, kycID string, granteeMSP string, scope string, expiry string) (*ConsentReceipt, error) {
+	if strings.TrimSpace(granteeMSP) == "" {
+		return nil, fmt.Errorf("grantee MSP ID is required")
+	}
+	purpose, categories, err := parseConsentScope(scope)
+	if err != nil {
+		return nil, err
+	}
+	consentJSON, err := json.Marshal(consentInput{
+		Recipients:     []string{granteeMSP},
+		Purposes:       []string{purpose},
+		DataCategories: categories,
+		ExpiresAt:      expiry,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.records.GrantConsent(ctx, kycID, string(consentJSON))
+}
+
+// RevokeConsent revokes a consent and the grant issued for it. Only the
+// organisation that submitted the record can revoke consent to it.
+func (c *ConsentContract) RevokeConsent(ctx contractapi.TransactionContextInterface, kycID string, receiptID string, reason string) (*Revocation, error) {
+	return c.records.RevokeConsent(ctx, kycID, receiptID, reason)
+}
+
+// CheckConsent reports whether a consent in force lets granteeMSP read a
+// record within scope. The organisation that owns the record, the grantee,
+// regulators and administrators can check.
+func (c *ConsentContract) CheckConsent(ctx contractapi.TransactionContextInterface, kycID string, granteeMSP string, scope string) (*ConsentCheck, error) {
+	purpose, categories, err := parseConsentScope(scope)
+	if err != nil {
+		return nil, err
+	}
+	kyc, err := c.records.readKYC(ctx, kycID)
+	if err != nil {
+		return nil, err
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	if kyc.OwnerMSP != "" && kyc.OwnerMSP != mspID && granteeMSP != mspID {
+		_, err = requireAnyAttribute(ctx, AttrRegulator, AttrAdmin)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fields := []string{}
+	for _, category := range categories {
+		fields = append(fields, consentDataCategories[category]...)
+	}
+	grants, err := recordGrants(ctx, kycID)
+	if err != nil {
+		return nil, err
+	}
+	check := &ConsentCheck{KYCID: kycID, Grantee: granteeMSP, Purpose: purpose, DataCategories: categories}
+	now := c.records.txTime(ctx).Format(time.RFC3339)
+	for _, grant := range grants {
+		if grant.ConsentReceiptID == "" || grant.Grantee != granteeMSP || grant.Purpose != purpose || !coversFields(grant.Fields, fields) {
+			continue
+		}
+		usable, err := grantUsable(ctx, grant, now)
+		if err != nil {
+			return nil, err
+		}
+		// of several consents in force, report the one lasting longest
+		if usable && grant.ExpiresAt > check.ExpiresAt {
+			check.Active = true
+			check.ReceiptID, check.GrantID, check.ExpiresAt = grant.ConsentReceiptID, grant.GrantID, grant.ExpiresAt
+		}
+	}
+	return check, nil
+}
+
+// parseConsentScope splits a scope into its purpose code and data
+// categories, every category when it names none
+func parseConsentScope(scope string) (string, []string, error) {
+	purpose, list, _ := strings.Cut(strings.TrimSpace(scope), ":")
+	if !purposeCodePattern.MatchString(purpose) {
+		return "", nil, fmt.Errorf("consent scope must start with a purpose code, got %q", scope)
+	}
+	categories := []string{}
+	if list == "" {
+		for category := range consentDataCategories {
+			categories = append(categories, category)
+		}
+	} else {
+		for _, category := range strings.Split(list, ",") {
+			category = strings.ToUpper(strings.TrimSpace(category))
+			if _, ok := consentDataCategories[category]; !ok {
+				return "", nil, fmt.Errorf("unknown data category %q", category)
+			}
+			categories = append(categories, category)
+		}
+	}
+	return purpose, sortedUnique(categories), nil
+}
+
+// coversFields reports whether granted holds every one of fields
+func coversFields(granted []string, fields []string) bool {
+	held := map[string]bool{}
+	for _, field := range granted {
+		held[field] = true
+	}
+	for _, field := range fields {
+		if !held[field] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// txContext is the transaction context the contract runs every transaction
+// in. A transaction cannot read back its own writes, so it remembers here
+// what it has written that later writes in the same transaction depend on.
+type txContext struct {
+	contractapi.TransactionContext
+	historySequences map[string]int           // next history sequence per record and transaction
+	historyHeads     map[string]*historyHead  // history chain heads written, per record
+	historySignature *pendingSignature        // the client's history signature, once read
+	trace            *txTrace                 // what the transaction has read and written, see telemetry.go
+	timestamp        time.Time                // the transaction's timestamp, once read
+	recordChanges    map[string]*recordChange // records written, see events.go
+	recordOrder      []string                 // IDs of the records written, in the order first written
+	eventSet         bool                     // whether the transaction has set an event of its own
+}
+
+// GetTransactionContextHandler runs the contract's transactions in a txContext
+func (s *SmartContract) GetTransactionContextHandler() contractapi.SettableTransactionContextInterface {
+	return new(txContext)
+}
+
+// SetStub gives the transaction a stub that meters its reads and writes
+func (tx *txContext) SetStub(stub shim.ChaincodeStubInterface) {
+	tx.trace = &txTrace{startedAt: time.Now(), reads: map[string]int{}, writes: map[string]int{}}
+	tx.TransactionContext.SetStub(&meteredStub{ChaincodeStubInterface: stub, trace: tx.trace})
+}
+
+// beforeTransaction and afterTransaction are the steps run around every
+// transaction, in order. A failing step fails the transaction; the steps
+// after a transaction only run once it has succeeded.
+var (
+	beforeTransaction = []func(contractapi.TransactionContextInterface) error{
+		readTxTimestamp,
+		startTrace,
+		rateLimit,
+	}
+	afterTransaction = []func(contractapi.TransactionContextInterface) error{
+		requireHistorySignatureUsed,
+		emitRecordEvent,
+		recordTrace,
+	}
+)
+
+// GetBeforeTransaction runs the beforeTransaction steps
+func (s *SmartContract) GetBeforeTransaction() interface{} {
+	return func(ctx contractapi.TransactionContextInterface) error {
+		return runTransactionSteps(ctx, beforeTransaction)
+	}
+}
+
+// GetAfterTransaction runs the afterTransaction steps
+func (s *SmartContract) GetAfterTransaction() interface{} {
+	return func(ctx contractapi.TransactionContextInterface) error {
+		return runTransactionSteps(ctx, afterTransaction)
+	}
+}
+
+// readTxTimestamp fails a transaction whose proposal carries no timestamp,
+// and keeps the timestamp for txTime
+func readTxTimestamp(ctx contractapi.TransactionContextInterface) error {
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil || timestamp == nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	if tx, ok := ctx.(*txContext); ok {
+		tx.timestamp = time.Unix(timestamp.Seconds, int64(timestamp.Nanos)).UTC()
+	}
+	return nil
+}
+
+// txTime returns the time of the transaction ctx runs: the timestamp its
+// client put in the proposal. Every endorsing peer sees the same timestamp,
+// where their clocks differ and would give them different read and write
+// sets, so anything a transaction records or decides on uses it rather than
+// time.Now. Peers do not check it against their clocks; it is only as
+// accurate as the client's.
+func txTime(ctx contractapi.TransactionContextInterface) time.Time {
+	if tx, ok := ctx.(*txContext); ok && !tx.timestamp.IsZero() {
+		return tx.timestamp
+	}
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil || timestamp == nil {
+		// readTxTimestamp fails contract transactions without one
+		return time.Time{}
+	}
+	return time.Unix(timestamp.Seconds, int64(timestamp.Nanos)).UTC()
+}
+
+// txTime returns the time of the transaction ctx runs, see the txTime function
+func (s *SmartContract) txTime(ctx contractapi.TransactionContextInterface) time.Time {
+	return txTime(ctx)
+}
+
+func runTransactionSteps(ctx contractapi.TransactionContextInterface, steps []func(contractapi.TransactionContextInterface) error) error {
+	for _, step := range steps {
+		err := step(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextHistorySequence returns the sequence of the next history entry a
+// transaction writes for a record: the number already written in this
+// transaction, given a txContext, or else 0
+func nextHistorySequence(ctx contractapi.TransactionContextInterface, kycID, txID string) int {
+	tx, ok := ctx.(*txContext)
+	if !ok {
+		return 0
+	}
+	if tx.historySequences == nil {
+		tx.historySequences = map[string]int{}
+	}
+	key := kycID + "\x00" + txID
+	sequence := tx.historySequences[key]
+	tx.historySequences[key] = sequence + 1
+	return sequence
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// counterPrefix keys the maintained record counters. Each counter is split
+// over counterBuckets keys, "COUNTER_<name>#<bucket>", and a transaction only
+// touches the bucket its ID hashes to, so concurrent transactions updating
+// the same counter rarely collide on an MVCC read conflict. A counter's value
+// is the sum of its buckets.
+const (
+	counterPrefix  = "COUNTER_"
+	counterBuckets = 16
+)
+
+// Counter names. Time-bucketed counters append an hour (SLA due), a day
+// (re-KYC due) or a month (onboarding and decisions) so that overdue and
+// expiring records, and a month's activity, can be summed per bucket.
+// Decoys (see decoy.go) count like other records, and are counted again
+// under the decoy prefix, so figures that leave them out can subtract them.
+const (
+	counterTotal          = "total"
+	counterStatusPrefix   = "status:"
+	counterOrgPrefix      = "org:"
+	counterFlagged        = "flagged"
+	counterSLADueHour     = "sladue:"
+	counterExpiryDay      = "expiry:"
+	counterNomineePrefix  = "nominee:"   // by nominee completeness, see nominee.go
+	counterOnboardedMonth = "onboarded:" // by creation month and entity type
+	counterDecisionMonth  = "decisions:" // by decision month, see reports.go
+	counterDecoyPrefix    = "decoy:"
+	counterOpenAlerts     = "alerts:open"
+	counterStaleScreens   = "screenings:stale"
+)
+
+// recordCounterPrefixes are the counters derived from records, which RebuildCounters recomputes
+var recordCounterPrefixes = []string{counterTotal, counterStatusPrefix, counterOrgPrefix, counterFlagged, counterSLADueHour, counterExpiryDay, counterNomineePrefix, counterOnboardedMonth, counterDecoyPrefix}
+
+// CounterRebuildResult summarises one page of a counter rebuild
+type CounterRebuildResult struct {
+	Scanned  int    `json:"scanned"`
+	Bookmark string `json:"bookmark"`
+}
+
+// GetRecordCount returns the number of records with a status, optionally
+// restricted to an owning organisation. Empty arguments count every record.
+func (s *SmartContract) GetRecordCount(ctx contractapi.TransactionContextInterface, mspID string, status string) (int, error) {
+	name := counterTotal
+	if status != "" {
+		name = counterStatusPrefix + strings.ToUpper(status)
+	}
+	if mspID != "" {
+		name = counterOrgPrefix + mspID + ":" + name
+	}
+	return readCounter(ctx, name)
+}
+
+// RebuildCounters recomputes the record counters from the records themselves,
+// one page at a time, for ledgers that hold records written before counters
+// were maintained, or before a counter was added, such as the nominee,
+// onboarding and decoy counters. The first page (empty bookmark) clears the existing
+// counters; run it while no other transactions are changing records.
+func (s *SmartContract) RebuildCounters(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*CounterRebuildResult, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if bookmark == "" {
+		for _, prefix := range recordCounterPrefixes {
+			err = clearCounters(ctx, prefix)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	err = checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	// the counter writes rule out paginated queries
+	result := &CounterRebuildResult{}
+	deltas := map[string]int{}
+	next, done, err := scanCompositeKeys(ctx, recordObjectType, bookmark, int(pageSize), func(key string, value []byte) (bool, error) {
+		var kyc KYCRecord
+		err := unmarshalRecord(value, &kyc)
+		if err != nil {
+			return false, err
+		}
+		decoy, err := isDecoy(ctx, kyc.ID)
+		if err != nil {
+			return false, err
+		}
+		for _, name := range counterMemberships(&kyc) {
+			deltas[name]++
+			if decoy {
+				deltas[counterDecoyPrefix+name]++
+			}
+		}
+		result.Scanned++
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !done {
+		result.Bookmark = next
+	}
+
+	if bookmark == "" {
+		// the cleared buckets still read as their committed values, so the
+		// first page's counts are written rather than added
+		for name, delta := range deltas {
+			err = ctx.GetStub().PutState(counterBucketKey(name, counterBucket(ctx.GetStub().GetTxID())), []byte(strconv.Itoa(delta)))
+			if err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	return result, applyCounterDeltas(ctx, deltas)
+}
+
+// counterMemberships returns the counters a record contributes one to
+func counterMemberships(kyc *KYCRecord) []string {
+	if kyc == nil {
+		return nil
+	}
+
+	memberships := []string{counterTotal, counterStatusPrefix + kyc.Status, counterNomineePrefix + nomineeCompleteness(kyc)}
+	if kyc.OwnerMSP != "" {
+		memberships = append(memberships,
+			counterOrgPrefix+kyc.OwnerMSP+":"+counterTotal,
+			counterOrgPrefix+kyc.OwnerMSP+":"+counterStatusPrefix+kyc.Status)
+	}
+	if len(kyc.Flags) > 0 {
+		memberships = append(memberships, counterFlagged)
+	}
+	if kyc.Status == "PENDING" && len(kyc.SLADueAt) >= 13 {
+		memberships = append(memberships, counterSLADueHour+kyc.SLADueAt[:13])
+	}
+	if kyc.Status == "VERIFIED" && len(kyc.ExpiresAt) >= 10 {
+		memberships = append(memberships, counterExpiryDay+kyc.ExpiresAt[:10])
+	}
+	if len(kyc.CreatedAt) >= 7 {
+		memberships = append(memberships, counterOnboardedMonth+kyc.CreatedAt[:7]+":"+kyc.EntityType)
+	}
+	return memberships
+}
+
+// updateRecordCounters moves a record between counters after a write. before
+// is nil for a new record and after is nil for a deleted one. A decoy moves
+// between the decoy counters too.
+func updateRecordCounters(ctx contractapi.TransactionContextInterface, before *KYCRecord, after *KYCRecord) error {
+	deltas := map[string]int{}
+	for _, name := range counterMemberships(before) {
+		deltas[name]--
+	}
+	for _, name := range counterMemberships(after) {
+		deltas[name]++
+	}
+
+	kyc := after
+	if kyc == nil {
+		kyc = before
+	}
+	if kyc != nil {
+		decoy, err := isDecoy(ctx, kyc.ID)
+		if err != nil {
+			return err
+		}
+		if decoy {
+			deltas = withDecoyDeltas(deltas)
+		}
+	}
+	return applyCounterDeltas(ctx, deltas)
+}
+
+// withDecoyDeltas returns deltas with each delta repeated on its decoy counter
+func withDecoyDeltas(deltas map[string]int) map[string]int {
+	all := map[string]int{}
+	for name, delta := range deltas {
+		all[name] += delta
+		all[counterDecoyPrefix+name] += delta
+	}
+	return all
+}
+
+// applyCounterDeltas adds each delta to its counter
+func applyCounterDeltas(ctx contractapi.TransactionContextInterface, deltas map[string]int) error {
+	for name, delta := range deltas {
+		if delta == 0 {
+			continue
+		}
+		err := incrementCounter(ctx, name, delta)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// incrementCounter adds delta to a counter. Reads in a transaction do not see
+// its own writes, so a transaction must increment each counter at most once;
+// callers with several changes aggregate them first.
+func incrementCounter(ctx contractapi.TransactionContextInterface, name string, delta int) error {
+	key := counterBucketKey(name, counterBucket(ctx.GetStub().GetTxID()))
+	valueBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read counter %s: %v", name, err)
+	}
+
+	value := 0
+	if valueBytes != nil {
+		value, err = strconv.Atoi(string(valueBytes))
+		if err != nil {
+			return err
+		}
+	}
+	return ctx.GetStub().PutState(key, []byte(strconv.Itoa(value+delta)))
+}
+
+// readCounter returns the current value of a counter
+func readCounter(ctx contractapi.TransactionContextInterface, name string) (int, error) {
+	total := 0
+	for bucket := 0; bucket < counterBuckets; bucket++ {
+		valueBytes, err := ctx.GetStub().GetState(counterBucketKey(name, bucket))
+		if err != nil {
+			return 0, fmt.Errorf("failed to read counter %s: %v", name, err)
+		}
+		if valueBytes == nil {
+			continue
+		}
+		value, err := strconv.Atoi(string(valueBytes))
+		if err != nil {
+			return 0, err
+		}
+		total += value
+	}
+	return total, nil
+}
+
+// sumCounters adds up every bucket of the counters named in [from, to)
+func sumCounters(ctx contractapi.TransactionContextInterface, from string, to string) (int, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByRange(counterPrefix+from, counterPrefix+to)
+	if err != nil {
+		return 0, err
+	}
+	defer resultsIterator.Close()
+
+	total := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+		value, err := strconv.Atoi(string(queryResponse.Value))
+		if err != nil {
+			return 0, err
+		}
+		total += value
+	}
+	return total, nil
+}
+
+// readCounters returns the value of each counter named in [from, to), in
+// one pass over their buckets in key order
+func readCounters(ctx contractapi.TransactionContextInterface, from string, to string) (map[string]int, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByRange(counterPrefix+from, counterPrefix+to)
+	if err != nil {
+		return nil, err
+	}
+
+	counters := map[string]int{}
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		value, err := strconv.Atoi(string(queryResponse.Value))
+		if err != nil {
+			return err
+		}
+		name := strings.TrimPrefix(queryResponse.Key, counterPrefix)
+		counters[name[:strings.LastIndex(name, "#")]] += value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counters, nil
+}
+
+// clearCounters deletes every bucket of the counters whose names start with prefix
+func clearCounters(ctx contractapi.TransactionContextInterface, prefix string) error {
+	resultsIterator, err := ctx.GetStub().GetStateByRange(counterPrefix+prefix, counterPrefix+prefix+"\xff")
+	if err != nil {
+		return err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return err
+		}
+		err = ctx.GetStub().DelState(queryResponse.Key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// counterBucket picks the bucket a transaction writes to
+func counterBucket(txID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(txID))
+	return int(h.Sum32() % counterBuckets)
+}
+
+func counterBucketKey(name string, bucket int) string {
+	return fmt.Sprintf("%s%s#%02d", counterPrefix, name, bucket)
+}
@@ -0,0 +1,703 @@
+package main
+
+// countryNames maps ISO 3166-1 alpha-2 codes to English display names
+var countryNames = map[string]string{
+	"AD": "Andorra",
+	"AE": "United Arab Emirates",
+	"AF": "Afghanistan",
+	"AG": "Antigua and Barbuda",
+	"AI": "Anguilla",
+	"AL": "Albania",
+	"AM": "Armenia",
+	"AO": "Angola",
+	"AQ": "Antarctica",
+	"AR": "Argentina",
+	"AS": "American Samoa",
+	"AT": "Austria",
+	"AU": "Australia",
+	"AW": "Aruba",
+	"AX": "Åland Islands",
+	"AZ": "Azerbaijan",
+	"BA": "Bosnia and Herzegovina",
+	"BB": "Barbados",
+	"BD": "Bangladesh",
+	"BE": "Belgium",
+	"BF": "Burkina Faso",
+	"BG": "Bulgaria",
+	"BH": "Bahrain",
+	"BI": "Burundi",
+	"BJ": "Benin",
+	"BL": "Saint Barthélemy",
+	"BM": "Bermuda",
+	"BN": "Brunei Darussalam",
+	"BO": "Bolivia",
+	"BQ": "Bonaire, Sint Eustatius and Saba",
+	"BR": "Brazil",
+	"BS": "Bahamas",
+	"BT": "Bhutan",
+	"BV": "Bouvet Island",
+	"BW": "Botswana",
+	"BY": "Belarus",
+	"BZ": "Belize",
+	"CA": "Canada",
+	"CC": "Cocos (Keeling) Islands",
+	"CD": "Congo, The Democratic Republic of the",
+	"CF": "Central African Republic",
+	"CG": "Congo",
+	"CH": "Switzerland",
+	"CI": "Côte d'Ivoire",
+	"CK": "Cook Islands",
+	"CL": "Chile",
+	"CM": "Cameroon",
+	"CN": "China",
+	"CO": "Colombia",
+	"CR": "Costa Rica",
+	"CU": "Cuba",
+	"CV": "Cabo Verde",
+	"CW": "Curaçao",
+	"CX": "Christmas Island",
+	"CY": "Cyprus",
+	"CZ": "Czechia",
+	"DE": "Germany",
+	"DJ": "Djibouti",
+	"DK": "Denmark",
+	"DM": "Dominica",
+	"DO": "Dominican Republic",
+	"DZ": "Algeria",
+	"EC": "Ecuador",
+	"EE": "Estonia",
+	"EG": "Egypt",
+	"EH": "Western Sahara",
+	"ER": "Eritrea",
+	"ES": "Spain",
+	"ET": "Ethiopia",
+	"FI": "Finland",
+	"FJ": "Fiji",
+	"FK": "Falkland Islands (Malvinas)",
+	"FM": "Micronesia, Federated States of",
+	"FO": "Faroe Islands",
+	"FR": "France",
+	"GA": "Gabon",
+	"GB": "United Kingdom",
+	"GD": "Grenada",
+	"GE": "Georgia",
+	"GF": "French Guiana",
+	"GG": "Guernsey",
+	"GH": "Ghana",
+	"GI": "Gibraltar",
+	"GL": "Greenland",
+	"GM": "Gambia",
+	"GN": "Guinea",
+	"GP": "Guadeloupe",
+	"GQ": "Equatorial Guinea",
+	"GR": "Greece",
+	"GS": "South Georgia and the South Sandwich Islands",
+	"GT": "Guatemala",
+	"GU": "Guam",
+	"GW": "Guinea-Bissau",
+	"GY": "Guyana",
+	"HK": "Hong Kong",
+	"HM": "Heard Island and McDonald Islands",
+	"HN": "Honduras",
+	"HR": "Croatia",
+	"HT": "Haiti",
+	"HU": "Hungary",
+	"ID": "Indonesia",
+	"IE": "Ireland",
+	"IL": "Israel",
+	"IM": "Isle of Man",
+	"IN": "India",
+	"IO": "British Indian Ocean Territory",
+	"IQ": "Iraq",
+	"IR": "Iran",
+	"IS": "Iceland",
+	"IT": "Italy",
+	"JE": "Jersey",
+	"JM": "Jamaica",
+	"JO": "Jordan",
+	"JP": "Japan",
+	"KE": "Kenya",
+	"KG": "Kyrgyzstan",
+	"KH": "Cambodia",
+	"KI": "Kiribati",
+	"KM": "Comoros",
+	"KN": "Saint Kitts and Nevis",
+	"KP": "North Korea",
+	"KR": "South Korea",
+	"KW": "Kuwait",
+	"KY": "Cayman Islands",
+	"KZ": "Kazakhstan",
+	"LA": "Laos",
+	"LB": "Lebanon",
+	"LC": "Saint Lucia",
+	"LI": "Liechtenstein",
+	"LK": "Sri Lanka",
+	"LR": "Liberia",
+	"LS": "Lesotho",
+	"LT": "Lithuania",
+	"LU": "Luxembourg",
+	"LV": "Latvia",
+	"LY": "Libya",
+	"MA": "Morocco",
+	"MC": "Monaco",
+	"MD": "Moldova",
+	"ME": "Montenegro",
+	"MF": "Saint Martin (French part)",
+	"MG": "Madagascar",
+	"MH": "Marshall Islands",
+	"MK": "North Macedonia",
+	"ML": "Mali",
+	"MM": "Myanmar",
+	"MN": "Mongolia",
+	"MO": "Macao",
+	"MP": "Northern Mariana Islands",
+	"MQ": "Martinique",
+	"MR": "Mauritania",
+	"MS": "Montserrat",
+	"MT": "Malta",
+	"MU": "Mauritius",
+	"MV": "Maldives",
+	"MW": "Malawi",
+	"MX": "Mexico",
+	"MY": "Malaysia",
+	"MZ": "Mozambique",
+	"NA": "Namibia",
+	"NC": "New Caledonia",
+	"NE": "Niger",
+	"NF": "Norfolk Island",
+	"NG": "Nigeria",
+	"NI": "Nicaragua",
+	"NL": "Netherlands",
+	"NO": "Norway",
+	"NP": "Nepal",
+	"NR": "Nauru",
+	"NU": "Niue",
+	"NZ": "New Zealand",
+	"OM": "Oman",
+	"PA": "Panama",
+	"PE": "Peru",
+	"PF": "French Polynesia",
+	"PG": "Papua New Guinea",
+	"PH": "Philippines",
+	"PK": "Pakistan",
+	"PL": "Poland",
+	"PM": "Saint Pierre and Miquelon",
+	"PN": "Pitcairn",
+	"PR": "Puerto Rico",
+	"PS": "Palestine, State of",
+	"PT": "Portugal",
+	"PW": "Palau",
+	"PY": "Paraguay",
+	"QA": "Qatar",
+	"RE": "Réunion",
+	"RO": "Romania",
+	"RS": "Serbia",
+	"RU": "Russian Federation",
+	"RW": "Rwanda",
+	"SA": "Saudi Arabia",
+	"SB": "Solomon Islands",
+	"SC": "Seychelles",
+	"SD": "Sudan",
+	"SE": "Sweden",
+	"SG": "Singapore",
+	"SH": "Saint Helena, Ascension and Tristan da Cunha",
+	"SI": "Slovenia",
+	"SJ": "Svalbard and Jan Mayen",
+	"SK": "Slovakia",
+	"SL": "Sierra Leone",
+	"SM": "San Marino",
+	"SN": "Senegal",
+	"SO": "Somalia",
+	"SR": "Suriname",
+	"SS": "South Sudan",
+	"ST": "Sao Tome and Principe",
+	"SV": "El Salvador",
+	"SX": "Sint Maarten (Dutch part)",
+	"SY": "Syria",
+	"SZ": "Eswatini",
+	"TC": "Turks and Caicos Islands",
+	"TD": "Chad",
+	"TF": "French Southern Territories",
+	"TG": "Togo",
+	"TH": "Thailand",
+	"TJ": "Tajikistan",
+	"TK": "Tokelau",
+	"TL": "Timor-Leste",
+	"TM": "Turkmenistan",
+	"TN": "Tunisia",
+	"TO": "Tonga",
+	"TR": "Türkiye",
+	"TT": "Trinidad and Tobago",
+	"TV": "Tuvalu",
+	"TW": "Taiwan",
+	"TZ": "Tanzania",
+	"UA": "Ukraine",
+	"UG": "Uganda",
+	"UM": "United States Minor Outlying Islands",
+	"US": "United States",
+	"UY": "Uruguay",
+	"UZ": "Uzbekistan",
+	"VA": "Holy See (Vatican City State)",
+	"VC": "Saint Vincent and the Grenadines",
+	"VE": "Venezuela",
+	"VG": "Virgin Islands, British",
+	"VI": "Virgin Islands, U.S.",
+	"VN": "Vietnam",
+	"VU": "Vanuatu",
+	"WF": "Wallis and Futuna",
+	"WS": "Samoa",
+	"YE": "Yemen",
+	"YT": "Mayotte",
+	"ZA": "South Africa",
+	"ZM": "Zambia",
+	"ZW": "Zimbabwe",
+}
+
+// countryAlpha3 maps ISO 3166-1 alpha-3 codes to their alpha-2 equivalents
+var countryAlpha3 = map[string]string{
+	"AND": "AD",
+	"ARE": "AE",
+	"AFG": "AF",
+	"ATG": "AG",
+	"AIA": "AI",
+	"ALB": "AL",
+	"ARM": "AM",
+	"AGO": "AO",
+	"ATA": "AQ",
+	"ARG": "AR",
+	"ASM": "AS",
+	"AUT": "AT",
+	"AUS": "AU",
+	"ABW": "AW",
+	"ALA": "AX",
+	"AZE": "AZ",
+	"BIH": "BA",
+	"BRB": "BB",
+	"BGD": "BD",
+	"BEL": "BE",
+	"BFA": "BF",
+	"BGR": "BG",
+	"BHR": "BH",
+	"BDI": "BI",
+	"BEN": "BJ",
+	"BLM": "BL",
+	"BMU": "BM",
+	"BRN": "BN",
+	"BOL": "BO",
+	"BES": "BQ",
+	"BRA": "BR",
+	"BHS": "BS",
+	"BTN": "BT",
+	"BVT": "BV",
+	"BWA": "BW",
+	"BLR": "BY",
+	"BLZ": "BZ",
+	"CAN": "CA",
+	"CCK": "CC",
+	"COD": "CD",
+	"CAF": "CF",
+	"COG": "CG",
+	"CHE": "CH",
+	"CIV": "CI",
+	"COK": "CK",
+	"CHL": "CL",
+	"CMR": "CM",
+	"CHN": "CN",
+	"COL": "CO",
+	"CRI": "CR",
+	"CUB": "CU",
+	"CPV": "CV",
+	"CUW": "CW",
+	"CXR": "CX",
+	"CYP": "CY",
+	"CZE": "CZ",
+	"DEU": "DE",
+	"DJI": "DJ",
+	"DNK": "DK",
+	"DMA": "DM",
+	"DOM": "DO",
+	"DZA": "DZ",
+	"ECU": "EC",
+	"EST": "EE",
+	"EGY": "EG",
+	"ESH": "EH",
+	"ERI": "ER",
+	"ESP": "ES",
+	"ETH": "ET",
+	"FIN": "FI",
+	"FJI": "FJ",
+	"FLK": "FK",
+	"FSM": "FM",
+	"FRO": "FO",
+	"FRA": "FR",
+	"GAB": "GA",
+	"GBR": "GB",
+	"GRD": "GD",
+	"GEO": "GE",
+	"GUF": "GF",
+	"GGY": "GG",
+	"GHA": "GH",
+	"GIB": "GI",
+	"GRL": "GL",
+	"GMB": "GM",
+	"GIN": "GN",
+	"GLP": "GP",
+	"GNQ": "GQ",
+	"GRC": "GR",
+	"SGS": "GS",
+	"GTM": "GT",
+	"GUM": "GU",
+	"GNB": "GW",
+	"GUY": "GY",
+	"HKG": "HK",
+	"HMD": "HM",
+	"HND": "HN",
+	"HRV": "HR",
+	"HTI": "HT",
+	"HUN": "HU",
+	"IDN": "ID",
+	"IRL": "IE",
+	"ISR": "IL",
+	"IMN": "IM",
+	"IND": "IN",
+	"IOT": "IO",
+	"IRQ": "IQ",
+	"IRN": "IR",
+	"ISL": "IS",
+	"ITA": "IT",
+	"JEY": "JE",
+	"JAM": "JM",
+	"JOR": "JO",
+	"JPN": "JP",
+	"KEN": "KE",
+	"KGZ": "KG",
+	"KHM": "KH",
+	"KIR": "KI",
+	"COM": "KM",
+	"KNA": "KN",
+	"PRK": "KP",
+	"KOR": "KR",
+	"KWT": "KW",
+	"CYM": "KY",
+	"KAZ": "KZ",
+	"LAO": "LA",
+	"LBN": "LB",
+	"LCA": "LC",
+	"LIE": "LI",
+	"LKA": "LK",
+	"LBR": "LR",
+	"LSO": "LS",
+	"LTU": "LT",
+	"LUX": "LU",
+	"LVA": "LV",
+	"LBY": "LY",
+	"MAR": "MA",
+	"MCO": "MC",
+	"MDA": "MD",
+	"MNE": "ME",
+	"MAF": "MF",
+	"MDG": "MG",
+	"MHL": "MH",
+	"MKD": "MK",
+	"MLI": "ML",
+	"MMR": "MM",
+	"MNG": "MN",
+	"MAC": "MO",
+	"MNP": "MP",
+	"MTQ": "MQ",
+	"MRT": "MR",
+	"MSR": "MS",
+	"MLT": "MT",
+	"MUS": "MU",
+	"MDV": "MV",
+	"MWI": "MW",
+	"MEX": "MX",
+	"MYS": "MY",
+	"MOZ": "MZ",
+	"NAM": "NA",
+	"NCL": "NC",
+	"NER": "NE",
+	"NFK": "NF",
+	"NGA": "NG",
+	"NIC": "NI",
+	"NLD": "NL",
+	"NOR": "NO",
+	"NPL": "NP",
+	"NRU": "NR",
+	"NIU": "NU",
+	"NZL": "NZ",
+	"OMN": "OM",
+	"PAN": "PA",
+	"PER": "PE",
+	"PYF": "PF",
+	"PNG": "PG",
+	"PHL": "PH",
+	"PAK": "PK",
+	"POL": "PL",
+	"SPM": "PM",
+	"PCN": "PN",
+	"PRI": "PR",
+	"PSE": "PS",
+	"PRT": "PT",
+	"PLW": "PW",
+	"PRY": "PY",
+	"QAT": "QA",
+	"REU": "RE",
+	"ROU": "RO",
+	"SRB": "RS",
+	"RUS": "RU",
+	"RWA": "RW",
+	"SAU": "SA",
+	"SLB": "SB",
+	"SYC": "SC",
+	"SDN": "SD",
+	"SWE": "SE",
+	"SGP": "SG",
+	"SHN": "SH",
+	"SVN": "SI",
+	"SJM": "SJ",
+	"SVK": "SK",
+	"SLE": "SL",
+	"SMR": "SM",
+	"SEN": "SN",
+	"SOM": "SO",
+	"SUR": "SR",
+	"SSD": "SS",
+	"STP": "ST",
+	"SLV": "SV",
+	"SXM": "SX",
+	"SYR": "SY",
+	"SWZ": "SZ",
+	"TCA": "TC",
+	"TCD": "TD",
+	"ATF": "TF",
+	"TGO": "TG",
+	"THA": "TH",
+	"TJK": "TJ",
+	"TKL": "TK",
+	"TLS": "TL",
+	"TKM": "TM",
+	"TUN": "TN",
+	"TON": "TO",
+	"TUR": "TR",
+	"TTO": "TT",
+	"TUV": "TV",
+	"TWN": "TW",
+	"TZA": "TZ",
+	"UKR": "UA",
+	"UGA": "UG",
+	"UMI": "UM",
+	"USA": "US",
+	"URY": "UY",
+	"UZB": "UZ",
+	"VAT": "VA",
+	"VCT": "VC",
+	"VEN": "VE",
+	"VGB": "VG",
+	"VIR": "VI",
+	"VNM": "VN",
+	"VUT": "VU",
+	"WLF": "WF",
+	"WSM": "WS",
+	"YEM": "YE",
+	"MYT": "YT",
+	"ZAF": "ZA",
+	"ZMB": "ZM",
+	"ZWE": "ZW",
+}
+
+// countryNameAliases maps upper-cased official, formal and colloquial country
+// names that differ from the display name to their alpha-2 code
+var countryNameAliases = map[string]string{
+	"ARAB REPUBLIC OF EGYPT":                               "EG",
+	"ARGENTINE REPUBLIC":                                   "AR",
+	"BHARAT":                                               "IN",
+	"BOLIVARIAN REPUBLIC OF VENEZUELA":                     "VE",
+	"BOLIVIA, PLURINATIONAL STATE OF":                      "BO",
+	"BRITISH VIRGIN ISLANDS":                               "VG",
+	"BURMA":                                                "MM",
+	"COMMONWEALTH OF DOMINICA":                             "DM",
+	"COMMONWEALTH OF THE BAHAMAS":                          "BS",
+	"COMMONWEALTH OF THE NORTHERN MARIANA ISLANDS":         "MP",
+	"CZECH REPUBLIC":                                       "CZ",
+	"CZECHIA":                                              "CZ",
+	"DEMOCRATIC PEOPLE'S REPUBLIC OF KOREA":                "KP",
+	"DEMOCRATIC REPUBLIC OF SAO TOME AND PRINCIPE":         "ST",
+	"DEMOCRATIC REPUBLIC OF TIMOR-LESTE":                   "TL",
+	"DEMOCRATIC SOCIALIST REPUBLIC OF SRI LANKA":           "LK",
+	"EASTERN REPUBLIC OF URUGUAY":                          "UY",
+	"ENGLAND":                                              "GB",
+	"FEDERAL DEMOCRATIC REPUBLIC OF ETHIOPIA":              "ET",
+	"FEDERAL DEMOCRATIC REPUBLIC OF NEPAL":                 "NP",
+	"FEDERAL REPUBLIC OF GERMANY":                          "DE",
+	"FEDERAL REPUBLIC OF NIGERIA":                          "NG",
+	"FEDERAL REPUBLIC OF SOMALIA":                          "SO",
+	"FEDERATED STATES OF MICRONESIA":                       "FM",
+	"FEDERATIVE REPUBLIC OF BRAZIL":                        "BR",
+	"FRENCH REPUBLIC":                                      "FR",
+	"GABONESE REPUBLIC":                                    "GA",
+	"GRAND DUCHY OF LUXEMBOURG":                            "LU",
+	"GREAT BRITAIN":                                        "GB",
+	"HASHEMITE KINGDOM OF JORDAN":                          "JO",
+	"HELLENIC REPUBLIC":                                    "GR",
+	"HOLLAND":                                              "NL",
+	"HONG KONG SPECIAL ADMINISTRATIVE REGION OF CHINA":     "HK",
+	"INDEPENDENT STATE OF PAPUA NEW GUINEA":                "PG",
+	"INDEPENDENT STATE OF SAMOA":                           "WS",
+	"IRAN, ISLAMIC REPUBLIC OF":                            "IR",
+	"ISLAMIC REPUBLIC OF AFGHANISTAN":                      "AF",
+	"ISLAMIC REPUBLIC OF IRAN":                             "IR",
+	"ISLAMIC REPUBLIC OF MAURITANIA":                       "MR",
+	"ISLAMIC REPUBLIC OF PAKISTAN":                         "PK",
+	"ITALIAN REPUBLIC":                                     "IT",
+	"IVORY COAST":                                          "CI",
+	"KINGDOM OF BAHRAIN":                                   "BH",
+	"KINGDOM OF BELGIUM":                                   "BE",
+	"KINGDOM OF BHUTAN":                                    "BT",
+	"KINGDOM OF CAMBODIA":                                  "KH",
+	"KINGDOM OF DENMARK":                                   "DK",
+	"KINGDOM OF ESWATINI":                                  "SZ",
+	"KINGDOM OF LESOTHO":                                   "LS",
+	"KINGDOM OF MOROCCO":                                   "MA",
+	"KINGDOM OF NORWAY":                                    "NO",
+	"KINGDOM OF SAUDI ARABIA":                              "SA",
+	"KINGDOM OF SPAIN":                                     "ES",
+	"KINGDOM OF SWEDEN":                                    "SE",
+	"KINGDOM OF THAILAND":                                  "TH",
+	"KINGDOM OF THE NETHERLANDS":                           "NL",
+	"KINGDOM OF TONGA":                                     "TO",
+	"KOREA":                                                "KR",
+	"KOREA, DEMOCRATIC PEOPLE'S REPUBLIC OF":               "KP",
+	"KOREA, REPUBLIC OF":                                   "KR",
+	"KYRGYZ REPUBLIC":                                      "KG",
+	"LAO PEOPLE'S DEMOCRATIC REPUBLIC":                     "LA",
+	"LEBANESE REPUBLIC":                                    "LB",
+	"MACAO SPECIAL ADMINISTRATIVE REGION OF CHINA":         "MO",
+	"MACEDONIA":                                            "MK",
+	"MOLDOVA, REPUBLIC OF":                                 "MD",
+	"PEOPLE'S DEMOCRATIC REPUBLIC OF ALGERIA":              "DZ",
+	"PEOPLE'S REPUBLIC OF BANGLADESH":                      "BD",
+	"PEOPLE'S REPUBLIC OF CHINA":                           "CN",
+	"PLURINATIONAL STATE OF BOLIVIA":                       "BO",
+	"PORTUGUESE REPUBLIC":                                  "PT",
+	"PRINCIPALITY OF ANDORRA":                              "AD",
+	"PRINCIPALITY OF LIECHTENSTEIN":                        "LI",
+	"PRINCIPALITY OF MONACO":                               "MC",
+	"REPUBLIC OF ALBANIA":                                  "AL",
+	"REPUBLIC OF ANGOLA":                                   "AO",
+	"REPUBLIC OF ARMENIA":                                  "AM",
+	"REPUBLIC OF AUSTRIA":                                  "AT",
+	"REPUBLIC OF AZERBAIJAN":                               "AZ",
+	"REPUBLIC OF BELARUS":                                  "BY",
+	"REPUBLIC OF BENIN":                                    "BJ",
+	"REPUBLIC OF BOSNIA AND HERZEGOVINA":                   "BA",
+	"REPUBLIC OF BOTSWANA":                                 "BW",
+	"REPUBLIC OF BULGARIA":                                 "BG",
+	"REPUBLIC OF BURUNDI":                                  "BI",
+	"REPUBLIC OF CABO VERDE":                               "CV",
+	"REPUBLIC OF CAMEROON":                                 "CM",
+	"REPUBLIC OF CHAD":                                     "TD",
+	"REPUBLIC OF CHILE":                                    "CL",
+	"REPUBLIC OF COLOMBIA":                                 "CO",
+	"REPUBLIC OF COSTA RICA":                               "CR",
+	"REPUBLIC OF CROATIA":                                  "HR",
+	"REPUBLIC OF CUBA":                                     "CU",
+	"REPUBLIC OF CYPRUS":                                   "CY",
+	"REPUBLIC OF CÔTE D'IVOIRE":                            "CI",
+	"REPUBLIC OF DJIBOUTI":                                 "DJ",
+	"REPUBLIC OF ECUADOR":                                  "EC",
+	"REPUBLIC OF EL SALVADOR":                              "SV",
+	"REPUBLIC OF EQUATORIAL GUINEA":                        "GQ",
+	"REPUBLIC OF ESTONIA":                                  "EE",
+	"REPUBLIC OF FIJI":                                     "FJ",
+	"REPUBLIC OF FINLAND":                                  "FI",
+	"REPUBLIC OF GHANA":                                    "GH",
+	"REPUBLIC OF GUATEMALA":                                "GT",
+	"REPUBLIC OF GUINEA":                                   "GN",
+	"REPUBLIC OF GUINEA-BISSAU":                            "GW",
+	"REPUBLIC OF GUYANA":                                   "GY",
+	"REPUBLIC OF HAITI":                                    "HT",
+	"REPUBLIC OF HONDURAS":                                 "HN",
+	"REPUBLIC OF ICELAND":                                  "IS",
+	"REPUBLIC OF INDIA":                                    "IN",
+	"REPUBLIC OF INDONESIA":                                "ID",
+	"REPUBLIC OF IRAQ":                                     "IQ",
+	"REPUBLIC OF KAZAKHSTAN":                               "KZ",
+	"REPUBLIC OF KENYA":                                    "KE",
+	"REPUBLIC OF KIRIBATI":                                 "KI",
+	"REPUBLIC OF LATVIA":                                   "LV",
+	"REPUBLIC OF LIBERIA":                                  "LR",
+	"REPUBLIC OF LITHUANIA":                                "LT",
+	"REPUBLIC OF MADAGASCAR":                               "MG",
+	"REPUBLIC OF MALAWI":                                   "MW",
+	"REPUBLIC OF MALDIVES":                                 "MV",
+	"REPUBLIC OF MALI":                                     "ML",
+	"REPUBLIC OF MALTA":                                    "MT",
+	"REPUBLIC OF MAURITIUS":                                "MU",
+	"REPUBLIC OF MOLDOVA":                                  "MD",
+	"REPUBLIC OF MOZAMBIQUE":                               "MZ",
+	"REPUBLIC OF MYANMAR":                                  "MM",
+	"REPUBLIC OF NAMIBIA":                                  "NA",
+	"REPUBLIC OF NAURU":                                    "NR",
+	"REPUBLIC OF NICARAGUA":                                "NI",
+	"REPUBLIC OF NORTH MACEDONIA":                          "MK",
+	"REPUBLIC OF PALAU":                                    "PW",
+	"REPUBLIC OF PANAMA":                                   "PA",
+	"REPUBLIC OF PARAGUAY":                                 "PY",
+	"REPUBLIC OF PERU":                                     "PE",
+	"REPUBLIC OF POLAND":                                   "PL",
+	"REPUBLIC OF SAN MARINO":                               "SM",
+	"REPUBLIC OF SENEGAL":                                  "SN",
+	"REPUBLIC OF SERBIA":                                   "RS",
+	"REPUBLIC OF SEYCHELLES":                               "SC",
+	"REPUBLIC OF SIERRA LEONE":                             "SL",
+	"REPUBLIC OF SINGAPORE":                                "SG",
+	"REPUBLIC OF SLOVENIA":                                 "SI",
+	"REPUBLIC OF SOUTH AFRICA":                             "ZA",
+	"REPUBLIC OF SOUTH SUDAN":                              "SS",
+	"REPUBLIC OF SURINAME":                                 "SR",
+	"REPUBLIC OF TAJIKISTAN":                               "TJ",
+	"REPUBLIC OF THE CONGO":                                "CG",
+	"REPUBLIC OF THE GAMBIA":                               "GM",
+	"REPUBLIC OF THE MARSHALL ISLANDS":                     "MH",
+	"REPUBLIC OF THE NIGER":                                "NE",
+	"REPUBLIC OF THE PHILIPPINES":                          "PH",
+	"REPUBLIC OF THE SUDAN":                                "SD",
+	"REPUBLIC OF TRINIDAD AND TOBAGO":                      "TT",
+	"REPUBLIC OF TUNISIA":                                  "TN",
+	"REPUBLIC OF TÜRKIYE":                                  "TR",
+	"REPUBLIC OF UGANDA":                                   "UG",
+	"REPUBLIC OF UZBEKISTAN":                               "UZ",
+	"REPUBLIC OF VANUATU":                                  "VU",
+	"REPUBLIC OF YEMEN":                                    "YE",
+	"REPUBLIC OF ZAMBIA":                                   "ZM",
+	"REPUBLIC OF ZIMBABWE":                                 "ZW",
+	"RUSSIA":                                               "RU",
+	"RWANDESE REPUBLIC":                                    "RW",
+	"SLOVAK REPUBLIC":                                      "SK",
+	"SOCIALIST REPUBLIC OF VIET NAM":                       "VN",
+	"STATE OF ISRAEL":                                      "IL",
+	"STATE OF KUWAIT":                                      "KW",
+	"STATE OF QATAR":                                       "QA",
+	"SULTANATE OF OMAN":                                    "OM",
+	"SWAZILAND":                                            "SZ",
+	"SWISS CONFEDERATION":                                  "CH",
+	"SYRIAN ARAB REPUBLIC":                                 "SY",
+	"TAIWAN, PROVINCE OF CHINA":                            "TW",
+	"TANZANIA, UNITED REPUBLIC OF":                         "TZ",
+	"THE STATE OF ERITREA":                                 "ER",
+	"THE STATE OF PALESTINE":                               "PS",
+	"TOGOLESE REPUBLIC":                                    "TG",
+	"TURKEY":                                               "TR",
+	"UAE":                                                  "AE",
+	"UK":                                                   "GB",
+	"UNION OF THE COMOROS":                                 "KM",
+	"UNITED KINGDOM OF GREAT BRITAIN AND NORTHERN IRELAND": "GB",
+	"UNITED MEXICAN STATES":                                "MX",
+	"UNITED REPUBLIC OF TANZANIA":                          "TZ",
+	"UNITED STATES OF AMERICA":                             "US",
+	"USA":                                                  "US",
+	"VENEZUELA, BOLIVARIAN REPUBLIC OF":                    "VE",
+	"VIET NAM":                                             "VN",
+	"VIRGIN ISLANDS OF THE UNITED STATES":                  "VI",
+}
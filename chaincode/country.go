@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// defaultCountry is assumed when an address does not name a country
+const defaultCountry = "IN"
+
+// countryCodesByName maps upper-cased display names to alpha-2 codes
+var countryCodesByName = map[string]string{}
+
+func init() {
+	for code, name := range countryNames {
+		countryCodesByName[strings.ToUpper(name)] = code
+	}
+}
+
+// CountryMigrationResult summarises one page of a country code migration
+type CountryMigrationResult struct {
+	Scanned    int      `json:"scanned"`
+	Migrated   int      `json:"migrated"`
+	Unresolved []string `json:"unresolved"` // IDs of records whose country could not be resolved
+	Bookmark   string   `json:"bookmark"`
+}
+
+// normalizeCountry resolves a country given as an ISO 3166-1 alpha-2 or alpha-3
+// code or an English name to its alpha-2 code. ok is false when the value is
+// not recognised, in which case the cleaned input is returned unchanged.
+func normalizeCountry(value string) (string, bool) {
+	cleaned := strings.ToUpper(collapseSpaces(strings.Trim(value, " .")))
+	if cleaned == "" {
+		return defaultCountry, true
+	}
+	if _, ok := countryNames[cleaned]; ok {
+		return cleaned, true
+	}
+	if code, ok := countryAlpha3[cleaned]; ok {
+		return code, true
+	}
+	if code, ok := countryCodesByName[cleaned]; ok {
+		return code, true
+	}
+	if code, ok := countryNameAliases[cleaned]; ok {
+		return code, true
+	}
+	return cleaned, false
+}
+
+// countryName returns the display name for an alpha-2 country code
+func countryName(code string) (string, bool) {
+	name, ok := countryNames[strings.ToUpper(code)]
+	return name, ok
+}
+
+// GetCountryName returns the English display name of an ISO 3166-1 alpha-2 code
+func (s *SmartContract) GetCountryName(ctx contractapi.TransactionContextInterface, code string) (string, error) {
+	name, ok := countryName(code)
+	if !ok {
+		return "", fmt.Errorf("%q is not an ISO 3166-1 alpha-2 country code", code)
+	}
+	return name, nil
+}
+
+// MigrateCountryCodes rewrites free-text country values on existing records to
+// ISO 3166-1 alpha-2 codes, one page at a time. Records whose country cannot be
+// resolved are left untouched and reported for manual correction.
+func (s *SmartContract) MigrateCountryCodes(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*CountryMigrationResult, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	performedBy, err := clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	result := &CountryMigrationResult{Unresolved: []string{}}
+	now := s.txTime(ctx).Format(time.RFC3339)
+	// the rewrites rule out paginated queries
+	next, done, err := scanCompositeKeys(ctx, recordObjectType, bookmark, int(pageSize), func(key string, value []byte) (bool, error) {
+		var kyc KYCRecord
+		err := unmarshalRecord(value, &kyc)
+		if err != nil {
+			return false, err
+		}
+		result.Scanned++
+
+		oldCountry := kyc.Address.Country
+		code, ok := normalizeCountry(oldCountry)
+		if !ok {
+			result.Unresolved = append(result.Unresolved, kyc.ID)
+			return true, nil
+		}
+		if code == oldCountry {
+			return true, nil
+		}
+
+		if kyc.RawAddress == nil {
+			rawAddress := kyc.Address
+			kyc.RawAddress = &rawAddress
+		}
+		kyc.Address.Country = code
+		kyc.UpdatedAt = now
+
+		kycJSON, err := json.Marshal(kyc)
+		if err != nil {
+			return false, err
+		}
+
+		err = putRecordState(ctx, kyc.ID, kycJSON)
+		if err != nil {
+			return false, fmt.Errorf("failed to update KYC record: %v", err)
+		}
+
+		historyEntry := HistoryEntry{
+			ID:          fmt.Sprintf("%s-COUNTRY_MIGRATED-%d", kyc.ID, s.txTime(ctx).Unix()),
+			KYCID:       kyc.ID,
+			Action:      "COUNTRY_MIGRATED",
+			PerformedBy: performedBy,
+			PerformedAt: now,
+			TxID:        ctx.GetStub().GetTxID(),
+			Details: map[string]interface{}{
+				"oldCountry": oldCountry,
+				"newCountry": code,
+			},
+		}
+
+		err = s.createHistoryEntry(ctx, historyEntry)
+		if err != nil {
+			return false, fmt.Errorf("failed to create history entry: %v", err)
+		}
+		result.Migrated++
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !done {
+		result.Bookmark = next
+	}
+
+	return result, nil
+}
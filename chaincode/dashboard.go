@@ -0,0 +1,67 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// expiringSoonDays is the horizon of the dashboard's expiring-soon count
+const expiringSoonDays = 30
+
+// ComplianceDashboard is a point-in-time summary of the compliance workload
+type ComplianceDashboard struct {
+	ByStatus         map[string]int `json:"byStatus"`
+	Pending          int            `json:"pending"`
+	Overdue          int            `json:"overdue"` // pending past their SLA due hour
+	Flagged          int            `json:"flagged"`
+	ExpiringSoon     int            `json:"expiringSoon"` // verified records due for re-KYC within expiringSoonDays
+	OpenAlerts       int            `json:"openAlerts"`
+	StaleScreenings  int            `json:"staleScreenings"`
+	ScreeningBacklog int            `json:"screeningBacklog"` // open alerts plus stale screenings
+	GeneratedAt      string         `json:"generatedAt"`
+}
+
+// GetComplianceDashboard returns the compliance workload summary. It is read
+// from maintained counters, so its cost does not grow with the number of records.
+func (s *SmartContract) GetComplianceDashboard(ctx contractapi.TransactionContextInterface) (*ComplianceDashboard, error) {
+	now := s.txTime(ctx)
+	dashboard := &ComplianceDashboard{
+		ByStatus:    map[string]int{},
+		GeneratedAt: now.Format(time.RFC3339),
+	}
+
+	for _, status := range []string{"PENDING", "ON_HOLD", "VERIFIED", "REJECTED", "EXPIRED", "BLOCKED"} {
+		count, err := readCounter(ctx, counterStatusPrefix+status)
+		if err != nil {
+			return nil, err
+		}
+		dashboard.ByStatus[status] = count
+	}
+	dashboard.Pending = dashboard.ByStatus["PENDING"]
+
+	var err error
+	dashboard.Overdue, err = sumCounters(ctx, counterSLADueHour, counterSLADueHour+now.Format("2006-01-02T15"))
+	if err != nil {
+		return nil, err
+	}
+	dashboard.ExpiringSoon, err = sumCounters(ctx, counterExpiryDay+now.Format("2006-01-02"), counterExpiryDay+now.AddDate(0, 0, expiringSoonDays+1).Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	dashboard.Flagged, err = readCounter(ctx, counterFlagged)
+	if err != nil {
+		return nil, err
+	}
+	dashboard.OpenAlerts, err = readCounter(ctx, counterOpenAlerts)
+	if err != nil {
+		return nil, err
+	}
+	dashboard.StaleScreenings, err = readCounter(ctx, counterStaleScreens)
+	if err != nil {
+		return nil, err
+	}
+	dashboard.ScreeningBacklog = dashboard.OpenAlerts + dashboard.StaleScreenings
+
+	return dashboard, nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// Honeytoken decoys. An administrator plants a decoy with PlantDecoy: an
+// ordinary record that no customer stands behind, flagged under
+// "DECOY~<kycID>" rather than on the record, so it reads, queries and
+// screens like any other. Nobody has a reason to read one, so any read of
+// a decoy by a non-administrator, even from the organisation that planted
+// it, is logged to its access log and emits a DecoyAccessed event, an early
+// warning of stolen credentials or bulk scraping. Decoys count in the record
+// counters and the dashboard like other records, but are also counted under
+// the decoy counters, which GetComplianceStats subtracts. As with the access
+// log, only submitted reads commit the log entry and the event.
+const (
+	decoyObjectType    = "DECOY"
+	decoyAccessedEvent = "DecoyAccessed"
+)
+
+// Decoy flags a planted decoy record
+type Decoy struct {
+	KYCID     string `json:"kycId"`
+	Note      string `json:"note,omitempty" metadata:",optional"` // where the decoy was seeded, for the security team
+	PlantedBy string `json:"plantedBy"`
+	PlantedAt string `json:"plantedAt"`
+	TxID      string `json:"txId"`
+}
+
+// DecoyAccess is the DecoyAccessed event payload, naming the decoys one
+// transaction read
+type DecoyAccess struct {
+	KYCIDs      []string `json:"kycIds"`
+	OwnerMSP    string   `json:"ownerMsp"` // the organisation that planted the first decoy read
+	Severity    string   `json:"severity"` // always HIGH
+	Function    string   `json:"function"`
+	PurposeCode string   `json:"purposeCode,omitempty" metadata:",optional"`
+	Accessor    string   `json:"accessor"`
+	AccessorMSP string   `json:"accessorMsp"`
+	AccessedAt  string   `json:"accessedAt"`
+	TxID        string   `json:"txId"`
+}
+
+// PlantDecoy creates a decoy record from kycData exactly as CreateKYC
+// creates a record, and flags it as a decoy. Only administrators can plant
+// decoys.
+func (s *SmartContract) PlantDecoy(ctx contractapi.TransactionContextInterface, kycData string, note string) (*Decoy, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = requireAllowedMSP(ctx, AllowlistSubmitter)
+	if err != nil {
+		return nil, err
+	}
+	var kyc KYCRecord
+	err = json.Unmarshal([]byte(kycData), &kyc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal KYC data: %v", err)
+	}
+	kyc.PrivateData = nil
+	err = s.createKYC(ctx, &kyc, nil)
+	if err != nil {
+		return nil, err
+	}
+	// createKYC counted the record before it was flagged
+	deltas := map[string]int{}
+	for _, name := range counterMemberships(&kyc) {
+		deltas[counterDecoyPrefix+name]++
+	}
+	err = applyCounterDeltas(ctx, deltas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update counters: %v", err)
+	}
+	plantedBy, err := clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	decoy := &Decoy{
+		KYCID:     kyc.ID,
+		Note:      note,
+		PlantedBy: plantedBy,
+		PlantedAt: s.txTime(ctx).Format(time.RFC3339),
+		TxID:      ctx.GetStub().GetTxID(),
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(decoyObjectType, []string{decoy.KYCID})
+	if err != nil {
+		return nil, err
+	}
+	decoyJSON, err := json.Marshal(decoy)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(key, decoyJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put decoy: %v", err)
+	}
+	return decoy, nil
+}
+
+// GetDecoys returns the planted decoys. Only administrators can list them.
+func (s *SmartContract) GetDecoys(ctx contractapi.TransactionContextInterface) ([]*Decoy, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(decoyObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+
+	decoys := []*Decoy{}
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		var decoy Decoy
+		err := json.Unmarshal(queryResponse.Value, &decoy)
+		if err != nil {
+			return err
+		}
+		decoys = append(decoys, &decoy)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decoys, nil
+}
+
+// isDecoy reports whether a record is a planted decoy
+func isDecoy(ctx contractapi.TransactionContextInterface, kycID string) (bool, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(decoyObjectType, []string{kycID})
+	if err != nil {
+		return false, err
+	}
+	decoyJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	return decoyJSON != nil, nil
+}
+
+// decoyRead raises the alarm for a read of a decoy, emitting a
+// DecoyAccessed event naming every decoy the transaction has read so far.
+// A transaction keeps only the last event it sets, so the final one names
+// them all.
+func (p *readPurpose) decoyRead(ctx contractapi.TransactionContextInterface, kyc *KYCRecord, entry *AccessLogEntry) error {
+	if p.decoys == nil {
+		p.decoys = &DecoyAccess{
+			OwnerMSP:    kyc.OwnerMSP,
+			Severity:    "HIGH",
+			Function:    entry.Function,
+			PurposeCode: entry.PurposeCode,
+			Accessor:    entry.Accessor,
+			AccessorMSP: entry.AccessorMSP,
+			AccessedAt:  entry.AccessedAt,
+			TxID:        entry.TxID,
+		}
+	}
+	p.decoys.KYCIDs = append(p.decoys.KYCIDs, kyc.ID)
+
+	accessJSON, err := json.Marshal(p.decoys)
+	if err != nil {
+		return err
+	}
+	return setEvent(ctx, decoyAccessedEvent, accessJSON)
+}
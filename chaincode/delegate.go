@@ -0,0 +1,335 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// Delegated access. A third party such as a fintech holds the DELEGATE org
+// role while a bank sponsors it with SponsorDelegate: only organisations on
+// the submitter allowlist sponsor, and only organisations on no allowlist
+// can be sponsored, so no consortium member can be made a delegate. A
+// delegate's access is always mediated by a
+// sponsor: it can only be granted access with GrantAccess naming an active
+// sponsor, not through consent or a plain grant, and its reads under a
+// grant need the grant's sponsorship to still be active. The sponsor can
+// withdraw all of a delegate's access it mediates with RevokeDelegate.
+//
+// Sponsorships are stored under "SPONSORSHIP~<delegate MSP>~<sponsor MSP>",
+// and the records a sponsor has mediated grants to are indexed under
+// "sponsor~delegate~kycid".
+const (
+	sponsorshipObjectType = "SPONSORSHIP"
+	sponsoredGrantIndex   = "sponsor~delegate~kycid"
+)
+
+// Org roles
+const (
+	OrgRoleMember   = "MEMBER"
+	OrgRoleDelegate = "DELEGATE"
+)
+
+// Sponsorship records a bank's sponsorship of a delegate
+type Sponsorship struct {
+	SponsorMSP   string `json:"sponsorMsp"`
+	DelegateMSP  string `json:"delegateMsp"`
+	SponsoredBy  string `json:"sponsoredBy"`
+	SponsoredAt  string `json:"sponsoredAt"`
+	TxID         string `json:"txId"`
+	RevokedAt    string `json:"revokedAt,omitempty" metadata:",optional"`
+	RevokedBy    string `json:"revokedBy,omitempty" metadata:",optional"`
+	RevocationID string `json:"revocationId,omitempty" metadata:",optional"` // the RevokeDelegate transaction
+}
+
+// DelegateRevocation records a sponsor's withdrawal of a delegate's access.
+// It is the AccessRevoked event payload of a RevokeDelegate transaction,
+// and each record it names also holds a Revocation with the same ID.
+type DelegateRevocation struct {
+	RevocationID string   `json:"revocationId"`
+	SponsorMSP   string   `json:"sponsorMsp"`
+	DelegateMSP  string   `json:"delegateMsp"`
+	Grantees     []string `json:"grantees"` // the delegate, as in a record's Revocation
+	KYCIDs       []string `json:"kycIds"`   // records whose grants were revoked
+	Reason       string   `json:"reason,omitempty" metadata:",optional"`
+	RevokedBy    string   `json:"revokedBy"`
+	RevokedAt    string   `json:"revokedAt"`
+}
+
+// SponsorDelegate makes the caller's organisation the sponsor of a
+// delegate, giving the delegate the DELEGATE role. Only an administrator
+// of an organisation on the submitter allowlist that is not itself a
+// delegate can sponsor one, and the delegate must be on no allowlist.
+func (s *SmartContract) SponsorDelegate(ctx contractapi.TransactionContextInterface, delegateMSP string) (*Sponsorship, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = requireAllowedMSP(ctx, AllowlistSubmitter)
+	if err != nil {
+		return nil, err
+	}
+	sponsorMSP, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	delegateMSP = strings.TrimSpace(delegateMSP)
+	if delegateMSP == "" || delegateMSP == sponsorMSP {
+		return nil, fmt.Errorf("delegate must be another organisation's MSP ID")
+	}
+	delegate, err := isDelegate(ctx, sponsorMSP)
+	if err != nil {
+		return nil, err
+	}
+	if delegate {
+		return nil, fmt.Errorf("%s is a delegate and cannot sponsor one", sponsorMSP)
+	}
+	role, err := listedRole(ctx, delegateMSP)
+	if err != nil {
+		return nil, err
+	}
+	if role != "" {
+		return nil, fmt.Errorf("%s is on the %s allowlist and cannot be sponsored as a delegate", delegateMSP, role)
+	}
+
+	existing, err := getSponsorship(ctx, sponsorMSP, delegateMSP)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.RevokedAt == "" {
+		return nil, fmt.Errorf("%s already sponsors %s", sponsorMSP, delegateMSP)
+	}
+	sponsoredBy, err := clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	sponsorship := &Sponsorship{
+		SponsorMSP:  sponsorMSP,
+		DelegateMSP: delegateMSP,
+		SponsoredBy: sponsoredBy,
+		SponsoredAt: s.txTime(ctx).Format(time.RFC3339),
+		TxID:        ctx.GetStub().GetTxID(),
+	}
+	return sponsorship, putSponsorship(ctx, sponsorship)
+}
+
+// RevokeDelegate ends the caller's organisation's sponsorship of a delegate
+// and revokes every grant to the delegate it mediates, writing a Revocation
+// to each record and emitting one AccessRevoked event naming them all. The
+// delegate's reads stop with the sponsorship, whatever the grants say.
+func (s *SmartContract) RevokeDelegate(ctx contractapi.TransactionContextInterface, delegateMSP string, reason string) (*DelegateRevocation, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sponsorMSP, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	sponsorship, err := getSponsorship(ctx, sponsorMSP, delegateMSP)
+	if err != nil {
+		return nil, err
+	}
+	if sponsorship == nil || sponsorship.RevokedAt != "" {
+		return nil, fmt.Errorf("%s does not sponsor %s", sponsorMSP, delegateMSP)
+	}
+	revokedBy, err := clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	bulk := &DelegateRevocation{
+		RevocationID: ctx.GetStub().GetTxID(),
+		SponsorMSP:   sponsorMSP,
+		DelegateMSP:  delegateMSP,
+		Grantees:     []string{delegateMSP},
+		KYCIDs:       []string{},
+		Reason:       reason,
+		RevokedBy:    revokedBy,
+		RevokedAt:    s.txTime(ctx).Format(time.RFC3339),
+	}
+	sponsorship.RevokedAt = bulk.RevokedAt
+	sponsorship.RevokedBy = revokedBy
+	sponsorship.RevocationID = bulk.RevocationID
+	err = putSponsorship(ctx, sponsorship)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(sponsoredGrantIndex, []string{sponsorMSP, delegateMSP})
+	if err != nil {
+		return nil, err
+	}
+	kycIDs := []string{}
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		_, keyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return err
+		}
+		kycIDs = append(kycIDs, keyParts[len(keyParts)-1])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, kycID := range kycIDs {
+		err = deleteIndexEntry(ctx, sponsoredGrantIndex, kycID, sponsorMSP, delegateMSP)
+		if err != nil {
+			return nil, err
+		}
+		exists, err := s.KYCExists(ctx, kycID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue // deleted since the grant; nothing left to read
+		}
+		kyc, err := s.readKYC(ctx, kycID)
+		if err != nil {
+			return nil, err
+		}
+
+		grants, err := recordGrants(ctx, kycID)
+		if err != nil {
+			return nil, err
+		}
+		revoked := []*AccessGrant{}
+		for _, grant := range grants {
+			if grant.Sponsor == sponsorMSP && grant.Grantee == delegateMSP && grant.RevokedAt == "" {
+				revoked = append(revoked, grant)
+			}
+		}
+		if len(revoked) == 0 {
+			continue
+		}
+		_, err = s.revoke(ctx, kyc, sponsorMSP, "", revoked, reason)
+		if err != nil {
+			return nil, err
+		}
+		bulk.KYCIDs = append(bulk.KYCIDs, kycID)
+	}
+
+	bulkJSON, err := json.Marshal(bulk)
+	if err != nil {
+		return nil, err
+	}
+	err = setEvent(ctx, accessRevokedEvent, bulkJSON)
+	if err != nil {
+		return nil, err
+	}
+	return bulk, nil
+}
+
+// GetSponsorships returns the sponsorships of a delegate, past and present
+func (s *SmartContract) GetSponsorships(ctx contractapi.TransactionContextInterface, delegateMSP string) ([]*Sponsorship, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(sponsorshipObjectType, []string{delegateMSP})
+	if err != nil {
+		return nil, err
+	}
+
+	sponsorships := []*Sponsorship{}
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		var sponsorship Sponsorship
+		err := json.Unmarshal(queryResponse.Value, &sponsorship)
+		if err != nil {
+			return err
+		}
+		sponsorships = append(sponsorships, &sponsorship)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sponsorships, nil
+}
+
+// GetOrgRole returns an organisation's role, DELEGATE or MEMBER
+func (s *SmartContract) GetOrgRole(ctx contractapi.TransactionContextInterface, mspID string) (string, error) {
+	delegate, err := isDelegate(ctx, mspID)
+	if err != nil {
+		return "", err
+	}
+	if delegate {
+		return OrgRoleDelegate, nil
+	}
+	return OrgRoleMember, nil
+}
+
+// isDelegate reports whether an organisation has the DELEGATE role, that
+// is whether a bank currently sponsors it
+func isDelegate(ctx contractapi.TransactionContextInterface, mspID string) (bool, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(sponsorshipObjectType, []string{mspID})
+	if err != nil {
+		return false, err
+	}
+	active := false
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		var sponsorship Sponsorship
+		err := json.Unmarshal(queryResponse.Value, &sponsorship)
+		if err != nil {
+			return err
+		}
+		if sponsorship.RevokedAt == "" {
+			active = true
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return active, nil
+}
+
+// sponsorshipActive reports whether sponsorMSP currently sponsors delegateMSP
+func sponsorshipActive(ctx contractapi.TransactionContextInterface, sponsorMSP string, delegateMSP string) (bool, error) {
+	sponsorship, err := getSponsorship(ctx, sponsorMSP, delegateMSP)
+	if err != nil {
+		return false, err
+	}
+	return sponsorship != nil && sponsorship.RevokedAt == "", nil
+}
+
+// getSponsorship loads a sponsorship, returning nil when there is none
+func getSponsorship(ctx contractapi.TransactionContextInterface, sponsorMSP string, delegateMSP string) (*Sponsorship, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(sponsorshipObjectType, []string{delegateMSP, sponsorMSP})
+	if err != nil {
+		return nil, err
+	}
+	sponsorshipJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if sponsorshipJSON == nil {
+		return nil, nil
+	}
+
+	var sponsorship Sponsorship
+	err = json.Unmarshal(sponsorshipJSON, &sponsorship)
+	if err != nil {
+		return nil, err
+	}
+	return &sponsorship, nil
+}
+
+// putSponsorship stores a sponsorship under its key
+func putSponsorship(ctx contractapi.TransactionContextInterface, sponsorship *Sponsorship) error {
+	key, err := ctx.GetStub().CreateCompositeKey(sponsorshipObjectType, []string{sponsorship.DelegateMSP, sponsorship.SponsorMSP})
+	if err != nil {
+		return err
+	}
+	sponsorshipJSON, err := json.Marshal(sponsorship)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, sponsorshipJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put sponsorship: %v", err)
+	}
+	return nil
+}
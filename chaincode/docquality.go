@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Document quality. The capture pipeline scores each uploaded document for
+// blur and glare, from 0 for none to 100, and judges whether its text can be
+// read, recording the result with RecordDocumentQuality. Like a scan, a
+// quality assessment covers the document hash it was made of. With
+// requireDocumentQuality set, a record cannot be verified while any of its
+// documents lacks a readable assessment of its current hash within the
+// configured maxBlurScore and maxGlareScore, so unreadable uploads are sent
+// back for recapture before a reviewer opens them.
+
+// DocumentQuality is the capture pipeline's assessment of one document
+type DocumentQuality struct {
+	DocumentID   string `json:"documentId"`
+	DocumentHash string `json:"documentHash"` // the hash of the document as assessed
+	BlurScore    int    `json:"blurScore"`    // 0-100, higher is blurrier
+	GlareScore   int    `json:"glareScore"`   // 0-100, higher has more glare
+	Readable     bool   `json:"readable"`
+	Passed       bool   `json:"passed"` // within the configured limits when recorded
+	RecordedBy   string `json:"recordedBy"`
+	RecordedAt   string `json:"recordedAt"`
+	TxID         string `json:"txId"`
+}
+
+// RecordDocumentQuality stores the capture pipeline's quality assessment of
+// one of a record's documents, replacing any earlier one. It covers the
+// document's current version.
+func (s *SmartContract) RecordDocumentQuality(ctx contractapi.TransactionContextInterface, kycID string, docID string, blurScore int, glareScore int, readable bool) error {
+	err := requireAttribute(ctx, AttrVerifier)
+	if err != nil {
+		return err
+	}
+	if blurScore < 0 || blurScore > 100 || glareScore < 0 || glareScore > 100 {
+		return fmt.Errorf("blur and glare scores must be between 0 and 100")
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	var document *DocumentHash
+	for i := range kyc.DocumentHashes {
+		if kyc.DocumentHashes[i].ID == docID {
+			document = &kyc.DocumentHashes[i]
+		}
+	}
+	if document == nil {
+		return fmt.Errorf("KYC record %s has no document %s", kycID, docID)
+	}
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	recordedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	kyc.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+	quality := DocumentQuality{
+		DocumentID:   docID,
+		DocumentHash: document.Hash,
+		BlurScore:    blurScore,
+		GlareScore:   glareScore,
+		Readable:     readable,
+		RecordedBy:   recordedBy,
+		RecordedAt:   kyc.UpdatedAt,
+		TxID:         ctx.GetStub().GetTxID(),
+	}
+	quality.Passed = qualityFailure(config, quality) == ""
+	replaced := false
+	assessments := []DocumentQuality{}
+	for _, existing := range kyc.DocumentQuality {
+		if existing.DocumentID == docID {
+			existing, replaced = quality, true
+		}
+		assessments = append(assessments, existing)
+	}
+	if !replaced {
+		assessments = append(assessments, quality)
+	}
+	kyc.DocumentQuality = assessments
+
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return err
+	}
+	err = putRecordState(ctx, kycID, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update KYC record: %v", err)
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-DOCUMENT_QUALITY_RECORDED-%d", kycID, s.txTime(ctx).Unix()),
+		KYCID:       kycID,
+		Action:      "DOCUMENT_QUALITY_RECORDED",
+		PerformedBy: recordedBy,
+		PerformedAt: kyc.UpdatedAt,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"documentId": docID,
+			"blurScore":  blurScore,
+			"glareScore": glareScore,
+			"readable":   readable,
+			"passed":     quality.Passed,
+			"replaced":   replaced,
+		},
+	}
+
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return nil
+}
+
+// qualityFailure returns why an assessment falls short of the configured
+// limits, or an empty string when it meets them
+func qualityFailure(config *ContractConfig, quality DocumentQuality) string {
+	switch {
+	case !quality.Readable:
+		return "is unreadable"
+	case quality.BlurScore > config.MaxBlurScore:
+		return fmt.Sprintf("has a blur score of %d, above the limit of %d", quality.BlurScore, config.MaxBlurScore)
+	case quality.GlareScore > config.MaxGlareScore:
+		return fmt.Sprintf("has a glare score of %d, above the limit of %d", quality.GlareScore, config.MaxGlareScore)
+	}
+	return ""
+}
+
+// checkDocumentQuality returns an error when the configuration requires
+// document quality for verification and any of a record's documents lacks an
+// assessment of its current hash meeting it. Assessments are judged against
+// the current limits, so tightening them also holds back records assessed
+// under the old ones.
+func checkDocumentQuality(config *ContractConfig, kyc *KYCRecord) error {
+	if !config.RequireDocumentQuality {
+		return nil
+	}
+	assessed := map[string]DocumentQuality{}
+	for _, quality := range kyc.DocumentQuality {
+		assessed[quality.DocumentID] = quality
+	}
+	unassessed := []string{}
+	for _, document := range kyc.DocumentHashes {
+		quality, ok := assessed[document.ID]
+		if !ok || quality.DocumentHash != document.Hash {
+			unassessed = append(unassessed, document.ID)
+			continue
+		}
+		failure := qualityFailure(config, quality)
+		if failure != "" {
+			return fmt.Errorf("KYC record %s cannot be verified: document %s %s; recapture it", kyc.ID, document.ID, failure)
+		}
+	}
+	if len(unassessed) > 0 {
+		return fmt.Errorf("KYC record %s cannot be verified until documents %v have a quality assessment", kyc.ID, unassessed)
+	}
+	return nil
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Document review. A verification covers the documents a record held when
+// it was verified, so once a record has been verified, a document replaced
+// by a new one (a renewed passport, say) or added to it is marked
+// PENDING_REVIEW, as is the OCR comparison made of the document it
+// replaced. The record keeps its status, and is indexed under
+// documentReviewIndex until a verifier reviews each such document with
+// ReviewDocument or verifies the record again.
+
+const documentReviewIndex = "docreview~kycid"
+
+// DocumentPendingReview marks a document, or the OCR result of one, that no
+// verifier has looked at since the document changed
+const DocumentPendingReview = "PENDING_REVIEW"
+
+// ReplaceDocument replaces one of a record's documents with a new version
+// of it. documentData is a document hash with the ID of the document it
+// replaces and the same type. Only the organisation that submitted the
+// record can replace its documents.
+func (s *SmartContract) ReplaceDocument(ctx contractapi.TransactionContextInterface, kycID string, documentData string) error {
+	err := requireAllowedMSP(ctx, AllowlistSubmitter)
+	if err != nil {
+		return err
+	}
+	var document DocumentHash
+	err = json.Unmarshal([]byte(documentData), &document)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal document: %v", err)
+	}
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	if kyc.OwnerMSP != "" && kyc.OwnerMSP != mspID {
+		return fmt.Errorf("KYC record %s is owned by %s", kycID, kyc.OwnerMSP)
+	}
+
+	index := -1
+	for i, existing := range kyc.DocumentHashes {
+		if existing.ID == document.ID {
+			index = i
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("KYC record %s has no document %q", kycID, document.ID)
+	}
+	existing := kyc.DocumentHashes[index]
+	if document.Type != existing.Type {
+		return fmt.Errorf("document %s is a %s and cannot be replaced with a %s", document.ID, existing.Type, document.Type)
+	}
+	if document.Hash == "" {
+		return fmt.Errorf("document hash is required")
+	}
+	if document.Hash == existing.Hash {
+		return fmt.Errorf("document %s already has this hash", document.ID)
+	}
+	replacedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	before := *kyc
+	now := s.txTime(ctx).Format(time.RFC3339)
+	if document.UploadedAt == "" {
+		document.UploadedAt = now
+	}
+	documents := append([]DocumentHash{}, kyc.DocumentHashes...)
+	documents[index] = document
+	kyc.DocumentHashes = documents
+	marked := reviewChangedDocuments(before.DocumentHashes, kyc, now)
+	kyc.UpdatedAt = now
+
+	err = saveDocumentChange(ctx, &before, kyc)
+	if err != nil {
+		return err
+	}
+	err = s.createHistoryEntry(ctx, HistoryEntry{
+		ID:          fmt.Sprintf("%s-DOCUMENT_REPLACED-%d", kycID, s.txTime(ctx).Unix()),
+		KYCID:       kycID,
+		Action:      "DOCUMENT_REPLACED",
+		PerformedBy: replacedBy,
+		PerformedAt: now,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"documentId":     document.ID,
+			"type":           document.Type,
+			"previousHash":   existing.Hash,
+			"awaitingReview": len(marked) > 0,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return nil
+}
+
+// AddDocumentHash adds a document to a record. documentData is a document
+// hash with an ID the record does not already hold. Only the organisation
+// that submitted the record can add documents to it.
+func (s *SmartContract) AddDocumentHash(ctx contractapi.TransactionContextInterface, kycID string, documentData string) error {
+	err := requireAllowedMSP(ctx, AllowlistSubmitter)
+	if err != nil {
+		return err
+	}
+	var document DocumentHash
+	err = json.Unmarshal([]byte(documentData), &document)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal document: %v", err)
+	}
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	if kyc.OwnerMSP != "" && kyc.OwnerMSP != mspID {
+		return fmt.Errorf("KYC record %s is owned by %s", kycID, kyc.OwnerMSP)
+	}
+
+	switch {
+	case document.ID == "":
+		return fmt.Errorf("document ID is required")
+	case document.Type == "":
+		return fmt.Errorf("document type is required")
+	case document.Hash == "":
+		return fmt.Errorf("document hash is required")
+	}
+	for _, existing := range kyc.DocumentHashes {
+		if existing.ID == document.ID {
+			return fmt.Errorf("KYC record %s already has a document %q; replace it with ReplaceDocument", kycID, document.ID)
+		}
+	}
+	addedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	before := *kyc
+	now := s.txTime(ctx).Format(time.RFC3339)
+	if document.UploadedAt == "" {
+		document.UploadedAt = now
+	}
+	kyc.DocumentHashes = append(append([]DocumentHash{}, kyc.DocumentHashes...), document)
+	marked := reviewChangedDocuments(before.DocumentHashes, kyc, now)
+	kyc.UpdatedAt = now
+
+	err = saveDocumentChange(ctx, &before, kyc)
+	if err != nil {
+		return err
+	}
+	err = s.createHistoryEntry(ctx, HistoryEntry{
+		ID:          fmt.Sprintf("%s-DOCUMENT_ADDED-%d", kycID, s.txTime(ctx).Unix()),
+		KYCID:       kycID,
+		Action:      "DOCUMENT_ADDED",
+		PerformedBy: addedBy,
+		PerformedAt: now,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"documentId":     document.ID,
+			"type":           document.Type,
+			"awaitingReview": len(marked) > 0,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return nil
+}
+
+// ReviewDocument records that a verifier has checked a document awaiting
+// review, clearing its PENDING_REVIEW mark. It leaves the document's OCR
+// result marked until a new comparison is recorded with RecordOCRResult.
+func (s *SmartContract) ReviewDocument(ctx contractapi.TransactionContextInterface, kycID string, docID string, remarks string) error {
+	err := requireAllowedMSP(ctx, AllowlistVerifier)
+	if err != nil {
+		return err
+	}
+	err = requireAttribute(ctx, AttrVerifier)
+	if err != nil {
+		return err
+	}
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	reviewedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	before := *kyc
+	documents := append([]DocumentHash{}, kyc.DocumentHashes...)
+	found := false
+	for i := range documents {
+		if documents[i].ID == docID && documents[i].Review == DocumentPendingReview {
+			documents[i].Review, documents[i].PreviousHash, documents[i].ReplacedAt = "", "", ""
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("document %q of KYC record %s is not awaiting review", docID, kycID)
+	}
+	kyc.DocumentHashes = documents
+	kyc.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+
+	err = saveDocumentChange(ctx, &before, kyc)
+	if err != nil {
+		return err
+	}
+	err = s.createHistoryEntry(ctx, HistoryEntry{
+		ID:          fmt.Sprintf("%s-DOCUMENT_REVIEWED-%d", kycID, s.txTime(ctx).Unix()),
+		KYCID:       kycID,
+		Action:      "DOCUMENT_REVIEWED",
+		PerformedBy: reviewedBy,
+		PerformedAt: kyc.UpdatedAt,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"documentId":     docID,
+			"awaitingReview": awaitingDocumentReview(kyc),
+		},
+		Remarks: remarks,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return nil
+}
+
+// GetRecordsAwaitingDocumentReview returns a page of the records holding a
+// document replaced or added since they were verified that no verifier has
+// reviewed yet
+func (s *SmartContract) GetRecordsAwaitingDocumentReview(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string, purposeCode string) (*PaginatedQueryResult, error) {
+	purpose, err := newReadPurpose(ctx, "GetRecordsAwaitingDocumentReview", purposeCode)
+	if err != nil {
+		return nil, err
+	}
+	return s.getRecordsByIndex(ctx, purpose, documentReviewIndex, []string{}, pageSize, bookmark)
+}
+
+// reviewChangedDocuments compares a record's documents with those it held
+// before a change. The review marks of documents are carried over from
+// before, since clients cannot set them, and if the record has been
+// verified, every document whose hash changed or that is new is marked
+// PENDING_REVIEW, along with the OCR results of the changed ones. It returns
+// the IDs of the documents it marked.
+func reviewChangedDocuments(before []DocumentHash, kyc *KYCRecord, now string) []string {
+	previous := map[string]DocumentHash{}
+	for _, document := range before {
+		previous[document.ID] = document
+	}
+
+	marked := []string{}
+	replaced := map[string]bool{}
+	documents := make([]DocumentHash, len(kyc.DocumentHashes))
+	for i, document := range kyc.DocumentHashes {
+		old, existed := previous[document.ID]
+		document.Review, document.PreviousHash, document.ReplacedAt = old.Review, old.PreviousHash, old.ReplacedAt
+		if kyc.VerifiedAt != "" && (!existed || old.Hash != document.Hash) {
+			document.Review, document.ReplacedAt = DocumentPendingReview, now
+			document.PreviousHash = ""
+			if existed {
+				document.PreviousHash = old.Hash
+				replaced[document.ID] = true
+			}
+			marked = append(marked, document.ID)
+		}
+		documents[i] = document
+	}
+	kyc.DocumentHashes = documents
+
+	if len(replaced) > 0 && len(kyc.OCRResults) > 0 {
+		results := append([]OCRResult{}, kyc.OCRResults...)
+		for i := range results {
+			if replaced[results[i].DocumentID] {
+				results[i].Review = DocumentPendingReview
+			}
+		}
+		kyc.OCRResults = results
+	}
+	return marked
+}
+
+// clearDocumentReviews clears the review marks of a record's documents
+func clearDocumentReviews(kyc *KYCRecord) {
+	documents := make([]DocumentHash, len(kyc.DocumentHashes))
+	for i, document := range kyc.DocumentHashes {
+		document.Review, document.PreviousHash, document.ReplacedAt = "", "", ""
+		documents[i] = document
+	}
+	kyc.DocumentHashes = documents
+}
+
+// awaitingDocumentReview reports whether a record has a document awaiting review
+func awaitingDocumentReview(kyc *KYCRecord) bool {
+	for _, document := range kyc.DocumentHashes {
+		if document.Review == DocumentPendingReview {
+			return true
+		}
+	}
+	return false
+}
+
+// updateDocumentReviewIndex adds a record to the document review index or
+// removes it, as a change to its documents requires
+func updateDocumentReviewIndex(ctx contractapi.TransactionContextInterface, before *KYCRecord, after *KYCRecord) error {
+	was, is := awaitingDocumentReview(before), awaitingDocumentReview(after)
+	switch {
+	case is && !was:
+		return putIndexEntry(ctx, documentReviewIndex, after.ID)
+	case was && !is:
+		return deleteIndexEntry(ctx, documentReviewIndex, after.ID)
+	}
+	return nil
+}
+
+// saveDocumentChange stores a record after a change to its documents and
+// updates the document review index
+func saveDocumentChange(ctx contractapi.TransactionContextInterface, before *KYCRecord, kyc *KYCRecord) error {
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return err
+	}
+	err = putRecordState(ctx, kyc.ID, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update KYC record: %v", err)
+	}
+	err = updateDocumentReviewIndex(ctx, before, kyc)
+	if err != nil {
+		return fmt.Errorf("failed to index KYC record: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Document scans. Uploaded files pass through a malware scanner off-chain,
+// which records its verdict on each document with RecordDocumentScan. A scan
+// covers the document hash it was made of, so a document replaced since its
+// scan needs a new one. A record cannot be verified while any of its
+// documents lacks a clean scan of its current hash, so only scanned
+// documents count toward the documents its entity type requires.
+
+// Verdicts of a document scan
+const (
+	ScanClean       = "CLEAN"
+	ScanInfected    = "INFECTED"
+	ScanUnscannable = "UNSCANNABLE" // the engine could not read the file, such as an encrypted archive
+)
+
+var scanVerdicts = map[string]bool{ScanClean: true, ScanInfected: true, ScanUnscannable: true}
+
+const maxScanField = 128
+
+// DocumentScan is a malware scanner's verdict on one document
+type DocumentScan struct {
+	DocumentID       string `json:"documentId"`
+	DocumentHash     string `json:"documentHash"` // the hash of the document as scanned
+	Engine           string `json:"engine"`       // such as ClamAV
+	SignatureVersion string `json:"signatureVersion"`
+	Verdict          string `json:"verdict"`                               // CLEAN, INFECTED or UNSCANNABLE
+	Threat           string `json:"threat,omitempty" metadata:",optional"` // the engine's name for what it found in an infected document
+	RecordedBy       string `json:"recordedBy"`
+	RecordedAt       string `json:"recordedAt"`
+	TxID             string `json:"txId"`
+}
+
+// RecordDocumentScan stores a scanner's verdict on one of a record's
+// documents, replacing any earlier scan of it. documentHash is the hash of
+// the file scanned, which must still be the document's.
+func (s *SmartContract) RecordDocumentScan(ctx contractapi.TransactionContextInterface, kycID string, docID string, documentHash string, engine string, signatureVersion string, verdict string, threat string) error {
+	err := requireAttribute(ctx, AttrVerifier)
+	if err != nil {
+		return err
+	}
+
+	verdict = strings.ToUpper(verdict)
+	if !scanVerdicts[verdict] {
+		return fmt.Errorf("scan verdict must be %s, %s or %s", ScanClean, ScanInfected, ScanUnscannable)
+	}
+	if engine == "" || len(engine) > maxScanField || signatureVersion == "" || len(signatureVersion) > maxScanField {
+		return fmt.Errorf("scan engine and signature version must be between 1 and %d characters", maxScanField)
+	}
+	if len(threat) > maxScanField {
+		return fmt.Errorf("threat name must be at most %d characters", maxScanField)
+	}
+	if verdict != ScanInfected {
+		threat = ""
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	var document *DocumentHash
+	for i := range kyc.DocumentHashes {
+		if kyc.DocumentHashes[i].ID == docID {
+			document = &kyc.DocumentHashes[i]
+		}
+	}
+	if document == nil {
+		return fmt.Errorf("KYC record %s has no document %s", kycID, docID)
+	}
+	if !strings.EqualFold(documentHash, document.Hash) {
+		return fmt.Errorf("document %s has changed since it was scanned; scan its current version", docID)
+	}
+
+	recordedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	kyc.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+	scan := DocumentScan{
+		DocumentID:       docID,
+		DocumentHash:     document.Hash,
+		Engine:           engine,
+		SignatureVersion: signatureVersion,
+		Verdict:          verdict,
+		Threat:           threat,
+		RecordedBy:       recordedBy,
+		RecordedAt:       kyc.UpdatedAt,
+		TxID:             ctx.GetStub().GetTxID(),
+	}
+	replaced := false
+	scans := []DocumentScan{}
+	for _, existing := range kyc.DocumentScans {
+		if existing.DocumentID == docID {
+			existing, replaced = scan, true
+		}
+		scans = append(scans, existing)
+	}
+	if !replaced {
+		scans = append(scans, scan)
+	}
+	kyc.DocumentScans = scans
+
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return err
+	}
+	err = putRecordState(ctx, kycID, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update KYC record: %v", err)
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-DOCUMENT_SCANNED-%d", kycID, s.txTime(ctx).Unix()),
+		KYCID:       kycID,
+		Action:      "DOCUMENT_SCANNED",
+		PerformedBy: recordedBy,
+		PerformedAt: kyc.UpdatedAt,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"documentId":       docID,
+			"engine":           engine,
+			"signatureVersion": signatureVersion,
+			"verdict":          verdict,
+			"replaced":         replaced,
+		},
+	}
+	if threat != "" {
+		historyEntry.Details["threat"] = threat
+	}
+
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return nil
+}
+
+// unscannedDocuments returns the IDs of a record's documents without a
+// clean scan of their current hash
+func unscannedDocuments(kyc *KYCRecord) []string {
+	clean := map[string]string{}
+	for _, scan := range kyc.DocumentScans {
+		if scan.Verdict == ScanClean {
+			clean[scan.DocumentID] = scan.DocumentHash
+		}
+	}
+	unscanned := []string{}
+	for _, document := range kyc.DocumentHashes {
+		if hash, ok := clean[document.ID]; !ok || hash != document.Hash {
+			unscanned = append(unscanned, document.ID)
+		}
+	}
+	return unscanned
+}
+
+// checkDocumentScans returns an error when any of a record's documents
+// lacks a clean scan of its current hash
+func checkDocumentScans(kyc *KYCRecord) error {
+	unscanned := unscannedDocuments(kyc)
+	if len(unscanned) > 0 {
+		return fmt.Errorf("KYC record %s cannot be verified until documents %v have a clean malware scan", kyc.ID, unscanned)
+	}
+	return nil
+}
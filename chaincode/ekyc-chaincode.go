@@ -11,9 +11,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
 )
 
 // SmartContract provides functions for managing KYC records
@@ -21,24 +24,121 @@ type SmartContract struct {
 	contractapi.Contract
 }
 
+// GetEvaluateTransactions lists the functions that only read the ledger, so
+// the contract metadata tags them for evaluation rather than submission.
+// Add new query functions here, but not reads that log or count their
+// access (see purpose.go): those must be submitted for the log and the
+// grant's read count to be committed.
+func (s *SmartContract) GetEvaluateTransactions() []string {
+	return []string{
+		"CheckBlacklist",
+		"ComputeStateDigest",
+		"GetAccessAnomalies",
+		"GetAccessLog",
+		"GetAnchors",
+		"GetArchivedKYC",
+		"GetAvailableValidationHooks",
+		"GetBulkAccessLog",
+		"GetCapabilities",
+		"GetComplianceDashboard",
+		"GetComplianceStats",
+		"GetConfig",
+		"GetConsentReceipt",
+		"GetCountryName",
+		"GetDecoys",
+		"GetDuplicatePhoneReport",
+		"GetExceptions",
+		"GetExtensionSchema",
+		"GetFieldKeys",
+		"GetKYCHistory",
+		"GetKYCLite",
+		"GetKeyEscrow",
+		"GetKeyRecovery",
+		"GetList",
+		"GetMonthlySummary",
+		"GetMyKYCStatus",
+		"GetOpenScreeningAlerts",
+		"GetOrgRole",
+		"GetRecordCount",
+		"GetRelianceHistory",
+		"GetRevocation",
+		"GetReportESignAttestations",
+		"GetReportSignatures",
+		"GetRiskOverrides",
+		"GetRiskRecalculationRun",
+		"GetRiskRules",
+		"GetScreeningRuns",
+		"GetSponsorships",
+		"GetStaleScreenings",
+		"GetSubmissionQuotaUsage",
+		"GetTelemetryReport",
+		"GetTimestampDigest",
+		"GetValidationHooks",
+		"GetVerifier",
+		"GetVerifierByCertificate",
+		"GetVersion",
+		"KYCExists",
+		"ListActiveGrants",
+		"Ping",
+		"SearchHistory",
+		"ValidateKYC",
+		"VerifyDocumentHash",
+		"VerifyHistoryChain",
+		"VerifyHistorySignature",
+		"VerifyKYCStatusProof",
+		"VerifyTimestampToken",
+	}
+}
+
 // KYCRecord represents a KYC record stored on the blockchain
 type KYCRecord struct {
-	ID                string            `json:"id"`
-	UserID            string            `json:"userId"`
-	Name              string            `json:"name"`
-	Email             string            `json:"email"`
-	Phone             string            `json:"phone"`
-	PAN               string            `json:"pan"`
-	DateOfBirth       string            `json:"dateOfBirth"`
-	Address           Address           `json:"address"`
-	DocumentHashes    []DocumentHash    `json:"documentHashes"`
-	Status            string            `json:"status"` // PENDING, VERIFIED, REJECTED, EXPIRED
-	VerificationLevel string            `json:"verificationLevel"` // L1, L2, L3
-	CreatedAt         string            `json:"createdAt"`
-	UpdatedAt         string            `json:"updatedAt"`
-	VerifiedAt        string            `json:"verifiedAt,omitempty"`
-	VerifiedBy        string            `json:"verifiedBy,omitempty"`
-	Remarks           string            `json:"remarks,omitempty"`
+	SchemaVersion        int                       `json:"schemaVersion,omitempty" metadata:",optional"` // record schema the record was written in; absent in schema 1
+	ID                   string                    `json:"id"`
+	UserID               string                    `json:"userId"`
+	OwnerMSP             string                    `json:"ownerMsp,omitempty" metadata:",optional"` // MSP of the organisation that submitted the record
+	EntityType           string                    `json:"entityType"`                              // INDIVIDUAL, COMPANY, TRUST, PARTNERSHIP, HUF
+	Name                 string                    `json:"name"`
+	Email                string                    `json:"email"`
+	Phone                string                    `json:"phone"`
+	PAN                  string                    `json:"pan"`
+	DateOfBirth          string                    `json:"dateOfBirth"`
+	Address              Address                   `json:"address"`
+	RawAddress           *Address                  `json:"rawAddress,omitempty" metadata:",optional"` // address as submitted, when normalization changed it
+	DocumentHashes       []DocumentHash            `json:"documentHashes"`
+	Status               string                    `json:"status"`            // PENDING, ON_HOLD, VERIFIED, REJECTED, EXPIRED, BLOCKED
+	VerificationLevel    string                    `json:"verificationLevel"` // L1, L2, L3
+	CreatedAt            string                    `json:"createdAt"`
+	UpdatedAt            string                    `json:"updatedAt"`
+	VerifiedAt           string                    `json:"verifiedAt,omitempty" metadata:",optional"`
+	VerifiedBy           string                    `json:"verifiedBy,omitempty" metadata:",optional"`
+	Remarks              string                    `json:"remarks,omitempty" metadata:",optional"`
+	Nominee              *Nominee                  `json:"nominee,omitempty" metadata:",optional"`
+	EntityDetails        *EntityDetails            `json:"entityDetails,omitempty" metadata:",optional"`
+	Extensions           map[string]interface{}    `json:"extensions,omitempty" metadata:",optional"` // keyed by registered extension namespace
+	Tags                 []string                  `json:"tags,omitempty" metadata:",optional"`
+	Flags                []RecordFlag              `json:"flags,omitempty" metadata:",optional"`
+	Blacklist            *BlacklistOutcome         `json:"blacklist,omitempty" metadata:",optional"`
+	Screening            *ScreeningMatch           `json:"screening,omitempty" metadata:",optional"` // best match from the latest watchlist screening
+	RiskTier             string                    `json:"riskTier,omitempty" metadata:",optional"`  // LOW, MEDIUM, HIGH
+	RiskScore            int                       `json:"riskScore,omitempty" metadata:",optional"`
+	RiskRuleVersion      int                       `json:"riskRuleVersion,omitempty" metadata:",optional"` // risk rule set the score was computed with
+	RiskOverride         *RiskOverride             `json:"riskOverride,omitempty" metadata:",optional"`
+	SLADueAt             string                    `json:"slaDueAt,omitempty" metadata:",optional"`             // verification decision due by
+	AssignedTo           string                    `json:"assignedTo,omitempty" metadata:",optional"`           // verifier whose review queue holds the record
+	Escalation           *Escalation               `json:"escalation,omitempty" metadata:",optional"`           // the referral to senior review, if a verifier made one
+	VerificationApproval *VerificationApproval     `json:"verificationApproval,omitempty" metadata:",optional"` // the first of two approvals, while dual approval awaits the second
+	Hold                 *RecordHold               `json:"hold,omitempty" metadata:",optional"`                 // why the record is ON_HOLD, while it is
+	ExpiresAt            string                    `json:"expiresAt,omitempty" metadata:",optional"`            // re-KYC due by, set on verification
+	AdverseMedia         []AdverseMedia            `json:"adverseMedia,omitempty" metadata:",optional"`
+	ESignAttestations    []ESignAttestation        `json:"esignAttestations,omitempty" metadata:",optional"` // signed customer declarations
+	OCRResults           []OCRResult               `json:"ocrResults,omitempty" metadata:",optional"`        // latest OCR comparison by document
+	DocumentScans        []DocumentScan            `json:"documentScans,omitempty" metadata:",optional"`     // latest malware scan by document
+	DocumentQuality      []DocumentQuality         `json:"documentQuality,omitempty" metadata:",optional"`   // latest quality assessment by document
+	FaceMatch            *FaceMatch                `json:"faceMatch,omitempty" metadata:",optional"`         // latest selfie-to-document face match
+	FaceHash             string                    `json:"faceHash,omitempty" metadata:",optional"`          // provider's perceptual hash of the selfie, indexed for duplicate faces
+	Notifications        *NotificationPreferences  `json:"notifications,omitempty" metadata:",optional"`     // nil notifies by email only
+	EncryptedFields      map[string]*FieldEnvelope `json:"encryptedFields,omitempty" metadata:",optional"`   // fields held encrypted, by field name (see encryption.go)
+	PrivateData          *PrivateRecordData        `json:"privateData,omitempty" metadata:",optional"`       // where personal details are held, for records submitted with CreateKYCPrivate (see private.go)
 }
 
 // Address represents the address information
@@ -47,7 +147,7 @@ type Address struct {
 	City    string `json:"city"`
 	State   string `json:"state"`
 	Pincode string `json:"pincode"`
-	Country string `json:"country"`
+	Country string `json:"country"` // ISO 3166-1 alpha-2 code
 }
 
 // DocumentHash represents a document hash stored on blockchain
@@ -55,20 +155,26 @@ type DocumentHash struct {
 	ID           string `json:"id"`
 	Type         string `json:"type"` // PAN, AADHAAR, PASSPORT, etc.
 	Hash         string `json:"hash"`
-	IPFSHash     string `json:"ipfsHash,omitempty"`
+	IPFSHash     string `json:"ipfsHash,omitempty" metadata:",optional"`
 	UploadedAt   string `json:"uploadedAt"`
+	Review       string `json:"review,omitempty" metadata:",optional"`       // PENDING_REVIEW once replaced or added after verification
+	PreviousHash string `json:"previousHash,omitempty" metadata:",optional"` // the hash it replaced, while awaiting review
+	ReplacedAt   string `json:"replacedAt,omitempty" metadata:",optional"`
 }
 
 // HistoryEntry represents an audit trail entry
 type HistoryEntry struct {
-	ID               string                 `json:"id"`
-	KYCID            string                 `json:"kycId"`
-	Action           string                 `json:"action"` // CREATED, UPDATED, VERIFIED, REJECTED, RESUBMITTED
-	PerformedBy      string                 `json:"performedBy"`
-	PerformedAt      string                 `json:"performedAt"`
-	TxID             string                 `json:"txId"`
-	Details          map[string]interface{} `json:"details"`
-	Remarks          string                 `json:"remarks,omitempty"`
+	ID            string                 `json:"id"`
+	KYCID         string                 `json:"kycId"`
+	Action        string                 `json:"action"` // CREATED, UPDATED, VERIFIED, REJECTED, RESUBMITTED
+	PerformedBy   string                 `json:"performedBy"`
+	PerformedAt   string                 `json:"performedAt"`
+	TxID          string                 `json:"txId"`
+	Sequence      int                    `json:"sequence,omitempty" metadata:",optional"`      // orders the entries one transaction wrote for the record
+	PrevEntryHash string                 `json:"prevEntryHash,omitempty" metadata:",optional"` // hash of the record's previous entry; absent before history was chained
+	Signature     *HistorySignature      `json:"signature,omitempty" metadata:",optional"`     // the performer's own signature, given in the historySignature transient field
+	Details       map[string]interface{} `json:"details"`
+	Remarks       string                 `json:"remarks,omitempty" metadata:",optional"`
 }
 
 // QueryResult structure used for handling result of query
@@ -85,11 +191,36 @@ func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface)
 
 // CreateKYC creates a new KYC record
 func (s *SmartContract) CreateKYC(ctx contractapi.TransactionContextInterface, kycData string) error {
+	err := requireAllowedMSP(ctx, AllowlistSubmitter)
+	if err != nil {
+		return err
+	}
 	var kyc KYCRecord
-	err := json.Unmarshal([]byte(kycData), &kyc)
+	err = json.Unmarshal([]byte(kycData), &kyc)
 	if err != nil {
 		return fmt.Errorf("failed to unmarshal KYC data: %v", err)
 	}
+	kyc.PrivateData = nil
+	return s.createKYC(ctx, &kyc, nil)
+}
+
+// createKYC checks, screens and stores a new record. With a salt, the
+// record's personal details are moved into its organisation's private data
+// collection once it has been checked (see private.go).
+func (s *SmartContract) createKYC(ctx contractapi.TransactionContextInterface, kyc *KYCRecord, privateSalt []byte) error {
+	err := normalizeSubmission(kyc)
+	if err != nil {
+		return err
+	}
+
+	err = validateKYCRecord(kyc)
+	if err != nil {
+		return err
+	}
+	err = s.validateExtensions(ctx, kyc.Extensions)
+	if err != nil {
+		return err
+	}
 
 	// Check if KYC already exists
 	exists, err := s.KYCExists(ctx, kyc.ID)
@@ -99,15 +230,94 @@ func (s *SmartContract) CreateKYC(ctx contractapi.TransactionContextInterface, k
 	if exists {
 		return fmt.Errorf("KYC record %s already exists", kyc.ID)
 	}
+	archived, err := readArchivedRecord(ctx, kyc.ID)
+	if err != nil {
+		return err
+	}
+	if archived != nil {
+		return fmt.Errorf("KYC record %s is archived; restore it with RestoreKYC", kyc.ID)
+	}
 
 	// Set creation timestamp
-	kyc.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	kyc.SchemaVersion = recordSchema
+	kyc.CreatedAt = s.txTime(ctx).Format(time.RFC3339)
 	kyc.UpdatedAt = kyc.CreatedAt
 	kyc.Status = "PENDING"
-	
+	kyc.OwnerMSP, err = ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	err = chargeSubmissionQuota(ctx, kyc.OwnerMSP, kyc.CreatedAt)
+	if err != nil {
+		return err
+	}
+	err = checkEncryptedFields(ctx, kyc)
+	if err != nil {
+		return err
+	}
+	policy, err := currentPolicy(ctx)
+	if err != nil {
+		return err
+	}
+	kyc.SLADueAt = slaDueAt(kyc.CreatedAt, policy.SLAHours)
+	kyc.Flags = nil
+	checkPincodeState(kyc, kyc.CreatedAt)
+	err = screenEmailDomain(ctx, kyc, kyc.CreatedAt)
+	if err != nil {
+		return err
+	}
+	kyc.Blacklist = nil
+	kyc.Screening = nil
+	kyc.AdverseMedia = nil
+	kyc.ESignAttestations = nil
+	kyc.OCRResults = nil
+	kyc.DocumentScans = nil
+	kyc.DocumentQuality = nil
+	kyc.FaceMatch = nil
+	kyc.FaceHash = ""
+	kyc.RiskOverride = nil
+	kyc.AssignedTo = ""
+	kyc.Escalation = nil
+	kyc.VerificationApproval = nil
+	kyc.Hold = nil
+	err = screenBlacklist(ctx, kyc, kyc.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if kyc.Phone != "" {
+		samePhone, err := getIndexedIDs(ctx, phoneIndex, phoneHash(kyc.Phone))
+		if err != nil {
+			return err
+		}
+		if len(samePhone) > 0 {
+			addFlag(kyc, FlagDuplicatePhone, fmt.Sprintf("phone number already registered on %d other record(s)", len(samePhone)), kyc.CreatedAt)
+		}
+	}
+	// Nominees are only accepted through SetNominee so owner consent is always captured
+	kyc.Nominee = nil
+	riskRules, err := loadRiskRules(ctx)
+	if err != nil {
+		return err
+	}
+	assessRisk(kyc, riskRules)
+
 	if kyc.VerificationLevel == "" {
 		kyc.VerificationLevel = "L1"
 	}
+	if kyc.DocumentHashes == nil {
+		kyc.DocumentHashes = []DocumentHash{}
+	}
+	hookResults, err := runValidationHooks(ctx, kyc, HookOperationCreate)
+	if err != nil {
+		return err
+	}
+
+	if privateSalt != nil {
+		err = sealPrivateDetails(ctx, kyc, privateSalt)
+		if err != nil {
+			return err
+		}
+	}
 
 	kycJSON, err := json.Marshal(kyc)
 	if err != nil {
@@ -115,15 +325,30 @@ func (s *SmartContract) CreateKYC(ctx contractapi.TransactionContextInterface, k
 	}
 
 	// Store KYC record
-	err = ctx.GetStub().PutState(kyc.ID, kycJSON)
+	err = putRecordState(ctx, kyc.ID, kycJSON)
 	if err != nil {
 		return fmt.Errorf("failed to put KYC record: %v", err)
 	}
 
+	err = putAddressIndexes(ctx, kyc)
+	if err != nil {
+		return fmt.Errorf("failed to index KYC record: %v", err)
+	}
+	err = updateRecordCounters(ctx, nil, kyc)
+	if err != nil {
+		return fmt.Errorf("failed to update counters: %v", err)
+	}
+	if kyc.Phone != "" {
+		err = putIndexEntry(ctx, phoneIndex, kyc.ID, phoneHash(kyc.Phone))
+		if err != nil {
+			return fmt.Errorf("failed to index KYC record: %v", err)
+		}
+	}
+
 	// Create history entry
 	txID := ctx.GetStub().GetTxID()
 	historyEntry := HistoryEntry{
-		ID:          fmt.Sprintf("%s-CREATED-%d", kyc.ID, time.Now().Unix()),
+		ID:          fmt.Sprintf("%s-CREATED-%d", kyc.ID, s.txTime(ctx).Unix()),
 		KYCID:       kyc.ID,
 		Action:      "CREATED",
 		PerformedBy: kyc.UserID,
@@ -132,9 +357,18 @@ func (s *SmartContract) CreateKYC(ctx contractapi.TransactionContextInterface, k
 		Details: map[string]interface{}{
 			"initialSubmission": true,
 			"documentCount":     len(kyc.DocumentHashes),
+			"entityType":        kyc.EntityType,
+			"flagCount":         len(kyc.Flags),
+			"status":            kyc.Status,
+			"riskTier":          kyc.RiskTier,
+			"riskScore":         kyc.RiskScore,
+			"riskRuleVersion":   kyc.RiskRuleVersion,
 		},
 		Remarks: "Initial KYC submission",
 	}
+	if len(hookResults) > 0 {
+		historyEntry.Details["validationHooks"] = hookResults
+	}
 
 	err = s.createHistoryEntry(ctx, historyEntry)
 	if err != nil {
@@ -144,18 +378,57 @@ func (s *SmartContract) CreateKYC(ctx contractapi.TransactionContextInterface, k
 	return nil
 }
 
-// ReadKYC returns the KYC record stored in the world state with given id
-func (s *SmartContract) ReadKYC(ctx contractapi.TransactionContextInterface, id string) (*KYCRecord, error) {
-	kycJSON, err := ctx.GetStub().GetState(id)
+// normalizeSubmission defaults the entity type of a new record and puts its
+// address and phone number into canonical form
+func normalizeSubmission(kyc *KYCRecord) error {
+	if kyc.EntityType == "" {
+		kyc.EntityType = EntityIndividual
+	}
+
+	normalizeRecordAddress(kyc)
+	clearDocumentReviews(kyc)
+
+	if kyc.Phone != "" {
+		phone, err := normalizePhone(kyc.Phone, kyc.Address.Country)
+		if err != nil {
+			return err
+		}
+		kyc.Phone = phone
+	}
+	return nil
+}
+
+// normalizeRecordAddress puts a record's address into canonical form, keeping
+// the address as given in RawAddress when normalization changes it
+func normalizeRecordAddress(kyc *KYCRecord) {
+	rawAddress := kyc.Address
+	kyc.Address = normalizeAddress(rawAddress)
+	kyc.RawAddress = nil
+	if kyc.Address != rawAddress {
+		kyc.RawAddress = &rawAddress
+	}
+}
+
+// readKYC returns the KYC record stored in the world state with given id,
+// without the purpose checks ReadKYC applies to its callers
+func (s *SmartContract) readKYC(ctx contractapi.TransactionContextInterface, id string) (*KYCRecord, error) {
+	kycJSON, err := getRecordState(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from world state: %v", err)
 	}
 	if kycJSON == nil {
+		archived, err := readArchivedRecord(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if archived != nil {
+			return nil, fmt.Errorf("KYC record %s is archived; restore it with RestoreKYC", id)
+		}
 		return nil, fmt.Errorf("KYC record %s does not exist", id)
 	}
 
 	var kyc KYCRecord
-	err = json.Unmarshal(kycJSON, &kyc)
+	err = unmarshalRecord(kycJSON, &kyc)
 	if err != nil {
 		return nil, err
 	}
@@ -163,22 +436,114 @@ func (s *SmartContract) ReadKYC(ctx contractapi.TransactionContextInterface, id
 	return &kyc, nil
 }
 
-// UpdateKYCStatus updates the status of an existing KYC record
+// UpdateKYCStatus updates the status of an existing KYC record to PENDING,
+// VERIFIED, REJECTED or EXPIRED. Holds are placed with PutOnHold, which
+// records their reason, and records are only blocked by a blacklist match
+// at creation, which records the match an override is decided on. Only
+// verifiers of organisations on the verifier allowlist can decide records.
+// The decision is recorded as the caller's; verifiedBy is the caller's own
+// reference for whoever made it, such as an operator of an application
+// that submits as one identity, and is kept in the history entry.
 func (s *SmartContract) UpdateKYCStatus(ctx contractapi.TransactionContextInterface, id string, status string, verifiedBy string, remarks string) error {
-	kyc, err := s.ReadKYC(ctx, id)
+	switch status {
+	case "PENDING", "VERIFIED", "REJECTED", "EXPIRED":
+	case "ON_HOLD":
+		return fmt.Errorf("records are put on hold with PutOnHold, which records the hold")
+	case "BLOCKED":
+		return fmt.Errorf("records are only blocked by a blacklist match when they are created")
+	default:
+		return fmt.Errorf("invalid status %q: must be PENDING, VERIFIED, REJECTED or EXPIRED", status)
+	}
+	err := requireAllowedMSP(ctx, AllowlistVerifier)
+	if err != nil {
+		return err
+	}
+	err = requireAttribute(ctx, AttrVerifier)
 	if err != nil {
 		return err
 	}
+	decidedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	kyc, err := s.readKYC(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if kyc.Status == "BLOCKED" {
+		return fmt.Errorf("KYC record %s is blocked by a blacklist match and needs an approved override", id)
+	}
+	if kyc.Status == "ON_HOLD" {
+		return fmt.Errorf("KYC record %s is on hold; release the hold before deciding it", id)
+	}
+
+	if status == "VERIFIED" && kyc.RiskTier == RiskHigh && (kyc.RiskOverride == nil || kyc.RiskOverride.Status != OverrideApproved) {
+		return fmt.Errorf("KYC record %s is HIGH risk and needs an approved risk override before verification", id)
+	}
 
+	policy, err := currentPolicy(ctx)
+	if err != nil {
+		return err
+	}
+	var firstApproval *VerificationApproval
+	if status == "VERIFIED" {
+		if policy.RequireConsent {
+			consented, err := hasCurrentConsent(ctx, id, s.txTime(ctx).Format(time.RFC3339))
+			if err != nil {
+				return err
+			}
+			if !consented {
+				return fmt.Errorf("KYC record %s needs a current consent receipt from its owner before verification", id)
+			}
+		}
+		if policy.DualApproval {
+			if kyc.VerificationApproval == nil {
+				return s.approveVerification(ctx, kyc, policy, remarks)
+			}
+			if decidedBy == kyc.VerificationApproval.ApprovedBy {
+				return fmt.Errorf("KYC record %s needs its second approval from a different verifier", id)
+			}
+			firstApproval = kyc.VerificationApproval
+		}
+	}
+
+	before := *kyc
 	oldStatus := kyc.Status
 	kyc.Status = status
-	kyc.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	kyc.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
 	kyc.Remarks = remarks
+	kyc.VerificationApproval = nil
 
 	if status == "VERIFIED" {
 		kyc.VerifiedAt = kyc.UpdatedAt
-		kyc.VerifiedBy = verifiedBy
+		kyc.VerifiedBy = decidedBy
 		kyc.VerificationLevel = "L2" // Upgrade verification level
+
+		config, err := loadConfig(ctx)
+		if err != nil {
+			return err
+		}
+		err = checkFaceMatch(config, kyc)
+		if err != nil {
+			return err
+		}
+		err = checkDocumentScans(kyc)
+		if err != nil {
+			return err
+		}
+		err = checkDocumentQuality(config, kyc)
+		if err != nil {
+			return err
+		}
+		verifiedAt, _ := time.Parse(time.RFC3339, kyc.VerifiedAt)
+		kyc.ExpiresAt = verifiedAt.AddDate(config.RekycYears.years(kyc.RiskTier), 0, 0).Format(time.RFC3339)
+		// the verification covers the documents now on file
+		clearDocumentReviews(kyc)
+	}
+	hookResults, err := runValidationHooks(ctx, kyc, HookOperationStatusUpdate)
+	if err != nil {
+		return err
 	}
 
 	kycJSON, err := json.Marshal(kyc)
@@ -186,10 +551,18 @@ func (s *SmartContract) UpdateKYCStatus(ctx contractapi.TransactionContextInterf
 		return err
 	}
 
-	err = ctx.GetStub().PutState(id, kycJSON)
+	err = putRecordState(ctx, id, kycJSON)
 	if err != nil {
 		return fmt.Errorf("failed to update KYC record: %v", err)
 	}
+	err = updateRecordCounters(ctx, &before, kyc)
+	if err != nil {
+		return fmt.Errorf("failed to update counters: %v", err)
+	}
+	err = updateDocumentReviewIndex(ctx, &before, kyc)
+	if err != nil {
+		return fmt.Errorf("failed to index KYC record: %v", err)
+	}
 
 	// Create history entry
 	txID := ctx.GetStub().GetTxID()
@@ -201,44 +574,86 @@ func (s *SmartContract) UpdateKYCStatus(ctx contractapi.TransactionContextInterf
 	}
 
 	historyEntry := HistoryEntry{
-		ID:          fmt.Sprintf("%s-%s-%d", id, action, time.Now().Unix()),
+		ID:          fmt.Sprintf("%s-%s-%d", id, action, s.txTime(ctx).Unix()),
 		KYCID:       id,
 		Action:      action,
-		PerformedBy: verifiedBy,
+		PerformedBy: decidedBy,
 		PerformedAt: kyc.UpdatedAt,
 		TxID:        txID,
 		Details: map[string]interface{}{
 			"oldStatus":         oldStatus,
 			"newStatus":         status,
 			"verificationLevel": kyc.VerificationLevel,
+			"expiresAt":         kyc.ExpiresAt,
+			"policyVersion":     policy.Version,
 		},
 		Remarks: remarks,
 	}
+	if verifiedBy != "" {
+		historyEntry.Details["operatorReference"] = verifiedBy
+	}
+	if firstApproval != nil {
+		historyEntry.Details["firstApprovedBy"] = firstApproval.ApprovedBy
+		historyEntry.Details["firstApprovedAt"] = firstApproval.ApprovedAt
+	}
+	if len(hookResults) > 0 {
+		historyEntry.Details["validationHooks"] = hookResults
+	}
 
 	err = s.createHistoryEntry(ctx, historyEntry)
 	if err != nil {
 		return fmt.Errorf("failed to create history entry: %v", err)
 	}
+	err = applyCounterDeltas(ctx, decisionCounterDeltas(&historyEntry, kyc.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to update counters: %v", err)
+	}
 
+	if status == "VERIFIED" || status == "REJECTED" {
+		return recordSLABreach(ctx, kyc, decidedBy)
+	}
 	return nil
 }
 
-// DeleteKYC deletes a KYC record from the world state
+// DeleteKYC deletes a KYC record from the world state. Only administrators
+// can delete records.
 func (s *SmartContract) DeleteKYC(ctx contractapi.TransactionContextInterface, id string) error {
-	exists, err := s.KYCExists(ctx, id)
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	kyc, err := s.readKYC(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = deleteRecordIndexes(ctx, kyc)
+	if err != nil {
+		return fmt.Errorf("failed to delete KYC indexes: %v", err)
+	}
+	err = updateRecordCounters(ctx, kyc, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update counters: %v", err)
+	}
+	photoKey, err := ctx.GetStub().CreateCompositeKey(photoObjectType, []string{id})
 	if err != nil {
 		return err
 	}
-	if !exists {
-		return fmt.Errorf("KYC record %s does not exist", id)
+	err = ctx.GetStub().DelState(photoKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete photo: %v", err)
+	}
+	err = deletePrivateDetails(ctx, kyc)
+	if err != nil {
+		return err
 	}
 
-	return ctx.GetStub().DelState(id)
+	return deleteRecordState(ctx, id)
 }
 
 // KYCExists returns true when KYC with given ID exists in world state
 func (s *SmartContract) KYCExists(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
-	kycJSON, err := ctx.GetStub().GetState(id)
+	kycJSON, err := getRecordState(ctx, id)
 	if err != nil {
 		return false, fmt.Errorf("failed to read from world state: %v", err)
 	}
@@ -246,83 +661,176 @@ func (s *SmartContract) KYCExists(ctx contractapi.TransactionContextInterface, i
 	return kycJSON != nil, nil
 }
 
-// GetKYCByPAN queries for KYC records by PAN number
-func (s *SmartContract) GetKYCByPAN(ctx contractapi.TransactionContextInterface, pan string) ([]*KYCRecord, error) {
-	queryString := fmt.Sprintf(`{"selector":{"pan":"%s"}}`, pan)
-	return s.getQueryResultForQueryString(ctx, queryString)
+// GetKYCByPAN returns the KYC records with a PAN, case aside, through the
+// PAN lookup index
+func (s *SmartContract) GetKYCByPAN(ctx contractapi.TransactionContextInterface, pan string, purposeCode string) ([]*KYCRecord, error) {
+	purpose, err := newReadPurpose(ctx, "GetKYCByPAN", purposeCode)
+	if err != nil {
+		return nil, err
+	}
+	return s.getRecordsByLookup(ctx, purpose, panIndex, panHash(pan))
 }
 
-// GetKYCByEmail queries for KYC records by email
-func (s *SmartContract) GetKYCByEmail(ctx contractapi.TransactionContextInterface, email string) ([]*KYCRecord, error) {
-	queryString := fmt.Sprintf(`{"selector":{"email":"%s"}}`, email)
-	return s.getQueryResultForQueryString(ctx, queryString)
+// GetKYCByEmail returns the KYC records with an email address, case aside,
+// through the email lookup index
+func (s *SmartContract) GetKYCByEmail(ctx contractapi.TransactionContextInterface, email string, purposeCode string) ([]*KYCRecord, error) {
+	purpose, err := newReadPurpose(ctx, "GetKYCByEmail", purposeCode)
+	if err != nil {
+		return nil, err
+	}
+	return s.getRecordsByLookup(ctx, purpose, emailIndex, emailHash(email))
 }
 
-// GetKYCByStatus queries for KYC records by status
-func (s *SmartContract) GetKYCByStatus(ctx contractapi.TransactionContextInterface, status string) ([]*KYCRecord, error) {
-	queryString := fmt.Sprintf(`{"selector":{"status":"%s"}}`, status)
-	return s.getQueryResultForQueryString(ctx, queryString)
+// GetKYCByStatus returns every KYC record with a status, through the status
+// lookup index
+func (s *SmartContract) GetKYCByStatus(ctx contractapi.TransactionContextInterface, status string, purposeCode string) ([]*KYCRecord, error) {
+	purpose, err := newReadPurpose(ctx, "GetKYCByStatus", purposeCode)
+	if err != nil {
+		return nil, err
+	}
+	return s.getRecordsByLookup(ctx, purpose, statusIndex, status)
 }
 
-// GetKYCHistory returns the history of a specific KYC record
-func (s *SmartContract) GetKYCHistory(ctx contractapi.TransactionContextInterface, kycID string) ([]*HistoryEntry, error) {
-	queryString := fmt.Sprintf(`{"selector":{"kycId":"%s"}}`, kycID)
-	
-	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+// GetKYCByStatusWithPagination returns one page of the KYC records with a
+// status, through the status lookup index
+func (s *SmartContract) GetKYCByStatusWithPagination(ctx contractapi.TransactionContextInterface, status string, pageSize int32, bookmark string, purposeCode string) (*PaginatedQueryResult, error) {
+	purpose, err := newReadPurpose(ctx, "GetKYCByStatusWithPagination", purposeCode)
 	if err != nil {
 		return nil, err
 	}
-	defer resultsIterator.Close()
+	return s.getRecordsByIndex(ctx, purpose, statusIndex, []string{status}, pageSize, bookmark)
+}
 
-	var history []*HistoryEntry
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, err
-		}
+// GetKYCHistory returns the history of a specific KYC record, oldest first.
+// Entries still under their legacy keys are not returned until MigrateKeys
+// has moved them.
+func (s *SmartContract) GetKYCHistory(ctx contractapi.TransactionContextInterface, kycID string) ([]*HistoryEntry, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(historyObjectType, []string{kycID})
+	if err != nil {
+		return nil, err
+	}
 
-		var historyEntry HistoryEntry
-		err = json.Unmarshal(queryResponse.Value, &historyEntry)
+	entries := []*HistoryEntry{}
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		var entry HistoryEntry
+		err := json.Unmarshal(queryResponse.Value, &entry)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		history = append(history, &historyEntry)
+		entries = append(entries, &entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return history, nil
+	// keys order a transaction's entries but not the transactions
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].PerformedAt < entries[j].PerformedAt })
+	return entries, nil
 }
 
-// GetAllKYC returns all KYC records found in world state
-func (s *SmartContract) GetAllKYC(ctx contractapi.TransactionContextInterface) ([]*KYCRecord, error) {
-	// range query with empty string for startKey and endKey does an
-	// open-ended query of all KYC records in the chaincode namespace.
-	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+// GetAllKYC returns one page of every KYC record in world state: records
+// under their typed keys first, then those still under legacy keys, so a
+// page may be empty; keep paging until the bookmark comes back empty. Only
+// administrators and regulators can list every record, a page at a time,
+// and each page is logged with the number of records it returned (see
+// GetBulkAccessLog).
+func (s *SmartContract) GetAllKYC(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string, purposeCode string) (*PaginatedQueryResult, error) {
+	_, err := requireAnyAttribute(ctx, AttrAdmin, AttrRegulator)
+	if err != nil {
+		return nil, err
+	}
+	err = checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	purpose, err := newReadPurpose(ctx, "GetAllKYC", purposeCode)
 	if err != nil {
 		return nil, err
 	}
-	defer resultsIterator.Close()
 
-	var kycRecords []*KYCRecord
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, err
+	// the bookmark is "<key space>:<bookmark within it>", 0 for the typed
+	// record keys and 1 for the simple key space holding legacy records
+	legacy, inner := false, ""
+	if bookmark != "" {
+		parts := strings.SplitN(bookmark, ":", 2)
+		if len(parts) != 2 || (parts[0] != "0" && parts[0] != "1") {
+			return nil, fmt.Errorf("invalid bookmark %q", bookmark)
+		}
+		legacy, inner = parts[0] == "1", parts[1]
+	}
+	budget, err := newResponseBudget(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PaginatedQueryResult{Records: []*KYCRecord{}}
+	page := func(key string, value []byte) (bool, error) {
+		if legacy && (strings.HasPrefix(key, legacyHistoryPrefix) || len(value) == 0 || value[0] != '{') {
+			return true, nil
 		}
 
 		var kyc KYCRecord
-		err = json.Unmarshal(queryResponse.Value, &kyc)
+		err := unmarshalRecord(value, &kyc)
 		if err != nil {
-			return nil, err
+			return false, err
+		}
+		if kyc.Status == "" {
+			return true, nil
+		}
+		visible, grant, err := purpose.permits(ctx, &kyc)
+		if err != nil {
+			return false, err
 		}
-		kycRecords = append(kycRecords, &kyc)
+		if visible == nil {
+			return true, nil
+		}
+		fits, err := budget.fits(visible)
+		if err != nil {
+			return false, err
+		}
+		if !fits {
+			result.Truncated = true
+			return false, nil
+		}
+		err = purpose.record(ctx, &kyc, grant)
+		if err != nil {
+			return false, err
+		}
+		result.Records = append(result.Records, visible)
+		return true, nil
+	}
+	// the log's write rules out paginated queries, so the page is read with
+	// a plain iterator
+	done := false
+	if legacy {
+		inner, done, err = scanSimpleKeys(ctx, inner, int(pageSize), page)
+	} else {
+		inner, done, err = scanCompositeKeys(ctx, recordObjectType, inner, int(pageSize), page)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return kycRecords, nil
+	switch {
+	case !done && legacy:
+		result.Bookmark = "1:" + inner
+	case !done:
+		result.Bookmark = "0:" + inner
+	case !legacy:
+		result.Bookmark = "1:"
+	}
+	result.FetchedRecordsCount = int32(len(result.Records))
+	err = purpose.recordBulk(ctx, bookmark, len(result.Records))
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // VerifyDocumentHash verifies if a document hash exists in a KYC record
 func (s *SmartContract) VerifyDocumentHash(ctx contractapi.TransactionContextInterface, kycID string, documentHash string) (bool, error) {
-	kyc, err := s.ReadKYC(ctx, kycID)
+	kyc, err := s.readKYC(ctx, kycID)
 	if err != nil {
 		return false, err
 	}
@@ -338,43 +846,39 @@ func (s *SmartContract) VerifyDocumentHash(ctx contractapi.TransactionContextInt
 
 // Helper function to create history entries
 func (s *SmartContract) createHistoryEntry(ctx contractapi.TransactionContextInterface, entry HistoryEntry) error {
+	entry.Sequence = nextHistorySequence(ctx, entry.KYCID, entry.TxID)
+	head, err := readHistoryHead(ctx, entry.KYCID)
+	if err != nil {
+		return err
+	}
+	entry.PrevEntryHash = head.Hash
+	err = signHistoryEntry(ctx, &entry)
+	if err != nil {
+		return err
+	}
 	historyJSON, err := json.Marshal(entry)
 	if err != nil {
 		return err
 	}
 
-	historyKey := fmt.Sprintf("HISTORY_%s", entry.ID)
-	return ctx.GetStub().PutState(historyKey, historyJSON)
-}
-
-// Helper function for queries
-func (s *SmartContract) getQueryResultForQueryString(ctx contractapi.TransactionContextInterface, queryString string) ([]*KYCRecord, error) {
-	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	historyKey, err := historyStateKey(ctx, &entry)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer resultsIterator.Close()
-
-	var kycRecords []*KYCRecord
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, err
-		}
-
-		var kyc KYCRecord
-		err = json.Unmarshal(queryResponse.Value, &kyc)
-		if err != nil {
-			return nil, err
-		}
-		kycRecords = append(kycRecords, &kyc)
+	err = ctx.GetStub().PutState(historyKey, historyJSON)
+	if err != nil {
+		return err
 	}
-
-	return kycRecords, nil
+	hash, err := historyEntryHash(historyJSON)
+	if err != nil {
+		return err
+	}
+	noteHistoryAction(ctx, entry.KYCID, entry.Action)
+	return writeHistoryHead(ctx, entry.KYCID, &historyHead{KYCID: entry.KYCID, Hash: hash, Entries: head.Entries + 1})
 }
 
 func main() {
-	kycChaincode, err := contractapi.NewChaincode(&SmartContract{})
+	kycChaincode, err := contractapi.NewChaincode(&SmartContract{}, &PolicyConfig{}, &ConsentContract{})
 	if err != nil {
 		log.Panicf("Error creating eKYC chaincode: %v", err)
 	}
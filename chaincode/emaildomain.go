@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// emailDomainBlocklist is the reference list of blocked email domains, keyed
+// by domain. Subdomains of a listed domain are blocked as well.
+const emailDomainBlocklist = "EMAIL_DOMAIN_BLOCKLIST"
+
+// findBlockedEmailDomain returns the blocklist entry matching an email
+// address's domain or one of its parent domains, or nil if none matches
+func findBlockedEmailDomain(ctx contractapi.TransactionContextInterface, email string) (*ListEntry, error) {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return nil, nil
+	}
+
+	domain := strings.ToLower(email[at+1:])
+	for {
+		entry, err := getListEntry(ctx, emailDomainBlocklist, domain)
+		if err != nil || entry != nil {
+			return entry, err
+		}
+
+		dot := strings.Index(domain, ".")
+		if dot < 0 || !strings.Contains(domain[dot+1:], ".") {
+			return nil, nil
+		}
+		domain = domain[dot+1:]
+	}
+}
+
+// screenEmailDomain rejects or flags a record whose email domain is blocked,
+// according to the configured emailBlocklistAction
+func screenEmailDomain(ctx contractapi.TransactionContextInterface, kyc *KYCRecord, raisedAt string) error {
+	if kyc.Email == "" {
+		return nil
+	}
+
+	blocked, err := findBlockedEmailDomain(ctx, kyc.Email)
+	if err != nil || blocked == nil {
+		return err
+	}
+
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	reason := fmt.Sprintf("email domain %s is blocked", blocked.Key)
+	if blocked.Reason != "" {
+		reason += fmt.Sprintf(" (%s)", blocked.Reason)
+	}
+	if config.EmailBlocklistAction == ScreeningReject {
+		return fmt.Errorf("%s", reason)
+	}
+
+	addFlag(kyc, FlagBlockedEmailDomain, reason, raisedAt)
+	return nil
+}
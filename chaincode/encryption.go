@@ -0,0 +1,343 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// Field encryption. The submitting organisation may hold some personal
+// fields of a record encrypted: it leaves the field empty and puts an
+// envelope in the record's encryptedFields under the field's name, with
+// the ciphertext and the key it was encrypted under. The ledger only holds
+// key references, registered with RegisterFieldKey under
+// "FIELDKEY~<key ID>"; the keys themselves stay in the organisation's HSM
+// or key manager, so only clients holding them can read the fields.
+// Encrypted fields are not validated, indexed or screened on-chain, so only
+// encryptableFields, which nothing on-chain depends on, can be encrypted.
+// Keys are rotated with ReencryptRecord, which replaces a record's
+// envelopes with ones under a new key.
+const (
+	fieldKeyObjectType = "FIELDKEY"
+	fieldKeyActive     = "ACTIVE"
+	fieldKeyRetired    = "RETIRED"
+)
+
+// encryptableFields are the record fields that may be held encrypted
+var encryptableFields = map[string]bool{"email": true, "phone": true, "dateOfBirth": true}
+
+// fieldEncryptionAlgorithms are the algorithms a field key may be for
+var fieldEncryptionAlgorithms = map[string]bool{"AES-256-GCM": true, "XCHACHA20-POLY1305": true}
+
+// FieldEnvelope holds one encrypted record field
+type FieldEnvelope struct {
+	KeyID      string `json:"keyId"`
+	Algorithm  string `json:"algorithm"`
+	Ciphertext string `json:"ciphertext"` // base64, with the nonce and tag as the algorithm lays them out
+}
+
+// FieldKey references a field-encryption key held off-chain
+type FieldKey struct {
+	KeyID        string `json:"keyId"`
+	OwnerMSP     string `json:"ownerMsp"`
+	Algorithm    string `json:"algorithm"`
+	KeyRef       string `json:"keyRef"` // where the organisation's HSM or key manager holds the key
+	Status       string `json:"status"` // ACTIVE or RETIRED
+	RegisteredBy string `json:"registeredBy"`
+	RegisteredAt string `json:"registeredAt"`
+	TxID         string `json:"txId"`
+	RetiredBy    string `json:"retiredBy,omitempty" metadata:",optional"`
+	RetiredAt    string `json:"retiredAt,omitempty" metadata:",optional"`
+}
+
+// RegisterFieldKey registers a reference to a field-encryption key of the
+// caller's organisation. Only administrators can register keys.
+func (s *SmartContract) RegisterFieldKey(ctx contractapi.TransactionContextInterface, keyID string, algorithm string, keyRef string) (*FieldKey, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keyID = strings.TrimSpace(keyID)
+	if keyID == "" {
+		return nil, fmt.Errorf("key ID is required")
+	}
+	if !fieldEncryptionAlgorithms[algorithm] {
+		return nil, fmt.Errorf("unsupported field encryption algorithm %q", algorithm)
+	}
+	if strings.TrimSpace(keyRef) == "" {
+		return nil, fmt.Errorf("key reference is required")
+	}
+	existing, err := getFieldKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("field key %s is already registered", keyID)
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	registeredBy, err := clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	key := &FieldKey{
+		KeyID:        keyID,
+		OwnerMSP:     mspID,
+		Algorithm:    algorithm,
+		KeyRef:       keyRef,
+		Status:       fieldKeyActive,
+		RegisteredBy: registeredBy,
+		RegisteredAt: s.txTime(ctx).Format(time.RFC3339),
+		TxID:         ctx.GetStub().GetTxID(),
+	}
+	return key, putFieldKey(ctx, key)
+}
+
+// RetireFieldKey stops a key being used for new envelopes. Records already
+// encrypted under it keep their envelopes until they are re-encrypted. Only
+// an administrator of the key's organisation can retire it.
+func (s *SmartContract) RetireFieldKey(ctx contractapi.TransactionContextInterface, keyID string) (*FieldKey, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ownFieldKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key.Status == fieldKeyRetired {
+		return nil, fmt.Errorf("field key %s is already retired", keyID)
+	}
+	key.RetiredBy, err = clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+	key.Status = fieldKeyRetired
+	key.RetiredAt = s.txTime(ctx).Format(time.RFC3339)
+	return key, putFieldKey(ctx, key)
+}
+
+// GetFieldKeys returns the field keys an organisation has registered
+func (s *SmartContract) GetFieldKeys(ctx contractapi.TransactionContextInterface, mspID string) ([]*FieldKey, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(fieldKeyObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []*FieldKey{}
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		var key FieldKey
+		err := json.Unmarshal(queryResponse.Value, &key)
+		if err != nil {
+			return err
+		}
+		if key.OwnerMSP == mspID {
+			keys = append(keys, &key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// ReencryptRecord replaces the envelopes of a record's encrypted fields
+// with ones under newKeyID, to rotate the key they are encrypted under.
+// The caller decrypts the fields off-chain and submits envelopesData, a
+// JSON object of the new envelopes keyed by field name, which must cover
+// exactly the fields that are encrypted now, so no field is lost. Only the
+// organisation that submitted the record can re-encrypt it.
+func (s *SmartContract) ReencryptRecord(ctx contractapi.TransactionContextInterface, kycID string, newKeyID string, envelopesData string) error {
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	if kyc.OwnerMSP != "" && kyc.OwnerMSP != mspID {
+		return fmt.Errorf("KYC record %s is owned by %s", kycID, kyc.OwnerMSP)
+	}
+	if len(kyc.EncryptedFields) == 0 {
+		return fmt.Errorf("KYC record %s has no encrypted fields", kycID)
+	}
+
+	var envelopes map[string]*FieldEnvelope
+	err = json.Unmarshal([]byte(envelopesData), &envelopes)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal envelopes: %v", err)
+	}
+	fields, previousKeys, seen := []string{}, []string{}, map[string]bool{}
+	for field, previous := range kyc.EncryptedFields {
+		envelope := envelopes[field]
+		if envelope == nil {
+			return fmt.Errorf("no envelope for encrypted field %s; every encrypted field must be re-encrypted", field)
+		}
+		if envelope.KeyID != newKeyID {
+			return fmt.Errorf("envelope for %s is under key %s, not %s", field, envelope.KeyID, newKeyID)
+		}
+		fields = append(fields, field)
+		if !seen[previous.KeyID] {
+			seen[previous.KeyID] = true
+			previousKeys = append(previousKeys, previous.KeyID)
+		}
+	}
+	for field := range envelopes {
+		if kyc.EncryptedFields[field] == nil {
+			return fmt.Errorf("field %s is not encrypted; only encrypted fields can be re-encrypted", field)
+		}
+	}
+	err = validateEnvelopes(ctx, envelopes, mspID)
+	if err != nil {
+		return err
+	}
+	sort.Strings(fields)
+	sort.Strings(previousKeys)
+
+	kyc.EncryptedFields = envelopes
+	kyc.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return err
+	}
+	err = putRecordState(ctx, kycID, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update KYC record: %v", err)
+	}
+
+	performedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	return s.createHistoryEntry(ctx, HistoryEntry{
+		ID:          fmt.Sprintf("%s-REENCRYPTED-%d", kycID, s.txTime(ctx).Unix()),
+		KYCID:       kycID,
+		Action:      "REENCRYPTED",
+		PerformedBy: performedBy,
+		PerformedAt: kyc.UpdatedAt,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"fields":         fields,
+			"newKeyId":       newKeyID,
+			"previousKeyIds": previousKeys,
+		},
+	})
+}
+
+// validateEnvelopes checks that each envelope holds an encryptable field
+// under an active key of the organisation ownerMSP, in the key's algorithm
+func validateEnvelopes(ctx contractapi.TransactionContextInterface, envelopes map[string]*FieldEnvelope, ownerMSP string) error {
+	keys := map[string]*FieldKey{}
+	for field, envelope := range envelopes {
+		if !encryptableFields[field] {
+			return fmt.Errorf("field %s cannot be encrypted", field)
+		}
+		if envelope == nil {
+			return fmt.Errorf("envelope for %s is empty", field)
+		}
+		key, ok := keys[envelope.KeyID]
+		if !ok {
+			var err error
+			key, err = getFieldKey(ctx, envelope.KeyID)
+			if err != nil {
+				return err
+			}
+			keys[envelope.KeyID] = key
+		}
+		if key == nil || key.OwnerMSP != ownerMSP {
+			return fmt.Errorf("field key %s is not registered to %s", envelope.KeyID, ownerMSP)
+		}
+		if key.Status != fieldKeyActive {
+			return fmt.Errorf("field key %s is retired", envelope.KeyID)
+		}
+		if envelope.Algorithm != key.Algorithm {
+			return fmt.Errorf("envelope for %s uses %s but key %s is for %s", field, envelope.Algorithm, key.KeyID, key.Algorithm)
+		}
+		ciphertext, err := base64.StdEncoding.DecodeString(envelope.Ciphertext)
+		if err != nil || len(ciphertext) == 0 {
+			return fmt.Errorf("envelope for %s must hold base64 ciphertext", field)
+		}
+	}
+	return nil
+}
+
+// checkEncryptedFields checks a new record's envelopes and that the fields
+// they hold are not also given in the clear
+func checkEncryptedFields(ctx contractapi.TransactionContextInterface, kyc *KYCRecord) error {
+	plaintext := map[string]string{"email": kyc.Email, "phone": kyc.Phone, "dateOfBirth": kyc.DateOfBirth}
+	for field := range kyc.EncryptedFields {
+		if plaintext[field] != "" {
+			return fmt.Errorf("field %s is encrypted and must be left empty", field)
+		}
+	}
+	return validateEnvelopes(ctx, kyc.EncryptedFields, kyc.OwnerMSP)
+}
+
+// ownFieldKey loads a field key of the caller's organisation
+func ownFieldKey(ctx contractapi.TransactionContextInterface, keyID string) (*FieldKey, error) {
+	key, err := getFieldKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, fmt.Errorf("field key %s does not exist", keyID)
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	if key.OwnerMSP != mspID {
+		return nil, fmt.Errorf("field key %s is registered to %s", keyID, key.OwnerMSP)
+	}
+	return key, nil
+}
+
+// getFieldKey loads a field key, returning nil when there is none
+func getFieldKey(ctx contractapi.TransactionContextInterface, keyID string) (*FieldKey, error) {
+	stateKey, err := ctx.GetStub().CreateCompositeKey(fieldKeyObjectType, []string{keyID})
+	if err != nil {
+		return nil, err
+	}
+	keyJSON, err := ctx.GetStub().GetState(stateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if keyJSON == nil {
+		return nil, nil
+	}
+
+	var key FieldKey
+	err = json.Unmarshal(keyJSON, &key)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// putFieldKey stores a field key under its key
+func putFieldKey(ctx contractapi.TransactionContextInterface, key *FieldKey) error {
+	stateKey, err := ctx.GetStub().CreateCompositeKey(fieldKeyObjectType, []string{key.KeyID})
+	if err != nil {
+		return err
+	}
+	keyJSON, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(stateKey, keyJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put field key: %v", err)
+	}
+	return nil
+}
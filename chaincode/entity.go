@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+)
+
+// Entity types supported by KYC records
+const (
+	EntityIndividual  = "INDIVIDUAL"
+	EntityCompany     = "COMPANY"
+	EntityTrust       = "TRUST"
+	EntityPartnership = "PARTNERSHIP"
+	EntityHUF         = "HUF"
+)
+
+// EntityDetails holds the legal-entity specific information for non-individual customers
+type EntityDetails struct {
+	LegalName          string         `json:"legalName"`
+	RegistrationNumber string         `json:"registrationNumber"` // CIN, trust/firm registration number
+	DateOfFormation    string         `json:"dateOfFormation,omitempty" metadata:",optional"`
+	Directors          []RelatedParty `json:"directors,omitempty" metadata:",optional"`   // COMPANY
+	Trustees           []RelatedParty `json:"trustees,omitempty" metadata:",optional"`    // TRUST
+	Settlor            *RelatedParty  `json:"settlor,omitempty" metadata:",optional"`     // TRUST
+	Partners           []RelatedParty `json:"partners,omitempty" metadata:",optional"`    // PARTNERSHIP
+	Karta              *RelatedParty  `json:"karta,omitempty" metadata:",optional"`       // HUF
+	Coparceners        []RelatedParty `json:"coparceners,omitempty" metadata:",optional"` // HUF
+}
+
+// RelatedParty represents an individual acting for, or holding an interest in, a legal entity
+type RelatedParty struct {
+	Name        string `json:"name"`
+	PAN         string `json:"pan"`
+	Designation string `json:"designation,omitempty" metadata:",optional"`
+	KYCID       string `json:"kycId,omitempty" metadata:",optional"` // the party's own KYC record, if available
+}
+
+// validateIndividualStructure rejects entity details on individual records
+func validateIndividualStructure(kyc *KYCRecord) error {
+	if kyc.EntityDetails != nil {
+		return fmt.Errorf("entity details are not allowed for %s records", EntityIndividual)
+	}
+	return nil
+}
+
+// validateCompanyStructure requires at least one director
+func validateCompanyStructure(kyc *KYCRecord) error {
+	details, err := requireEntityDetails(kyc)
+	if err != nil {
+		return err
+	}
+	if len(details.Directors) == 0 {
+		return fmt.Errorf("at least one director is required for %s records", EntityCompany)
+	}
+	return validateRelatedParties("director", details.Directors)
+}
+
+// validateTrustStructure requires at least one trustee
+func validateTrustStructure(kyc *KYCRecord) error {
+	details, err := requireEntityDetails(kyc)
+	if err != nil {
+		return err
+	}
+	if len(details.Trustees) == 0 {
+		return fmt.Errorf("at least one trustee is required for %s records", EntityTrust)
+	}
+	err = validateRelatedParties("trustee", details.Trustees)
+	if err != nil {
+		return err
+	}
+	if details.Settlor != nil {
+		return validateRelatedParties("settlor", []RelatedParty{*details.Settlor})
+	}
+	return nil
+}
+
+// validatePartnershipStructure requires at least two partners
+func validatePartnershipStructure(kyc *KYCRecord) error {
+	details, err := requireEntityDetails(kyc)
+	if err != nil {
+		return err
+	}
+	if len(details.Partners) < 2 {
+		return fmt.Errorf("at least two partners are required for %s records", EntityPartnership)
+	}
+	return validateRelatedParties("partner", details.Partners)
+}
+
+// validateHUFStructure requires the karta acting for the family
+func validateHUFStructure(kyc *KYCRecord) error {
+	details, err := requireEntityDetails(kyc)
+	if err != nil {
+		return err
+	}
+	if details.Karta == nil {
+		return fmt.Errorf("karta details are required for %s records", EntityHUF)
+	}
+	err = validateRelatedParties("karta", []RelatedParty{*details.Karta})
+	if err != nil {
+		return err
+	}
+	return validateRelatedParties("coparcener", details.Coparceners)
+}
+
+// requireEntityDetails returns the entity details of a non-individual record
+func requireEntityDetails(kyc *KYCRecord) (*EntityDetails, error) {
+	details := kyc.EntityDetails
+	if details == nil {
+		return nil, fmt.Errorf("entity details are required for %s records", kyc.EntityType)
+	}
+	if details.LegalName == "" || details.RegistrationNumber == "" {
+		return nil, fmt.Errorf("legal name and registration number are required for %s records", kyc.EntityType)
+	}
+	return details, nil
+}
+
+// validateRelatedParties checks that every related party carries a name and a valid PAN
+func validateRelatedParties(role string, parties []RelatedParty) error {
+	for i, party := range parties {
+		if party.Name == "" {
+			return fmt.Errorf("%s %d must have a name", role, i+1)
+		}
+		if !panPattern.MatchString(party.PAN) {
+			return fmt.Errorf("%s %d has an invalid PAN", role, i+1)
+		}
+	}
+	return nil
+}
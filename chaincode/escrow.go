@@ -0,0 +1,353 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Key escrow. An organisation can escrow a field-encryption key (see
+// encryption.go) with EscrowFieldKey: it splits the key off-chain into
+// Shamir shares, any threshold of which rebuild it, and hands one share to
+// each custodian organisation it designates, recording only the hash of
+// each share, under "ESCROW~<key ID>". If it loses the key it opens a
+// recovery with RequestKeyRecovery, naming a public key to receive the
+// shares; each custodian approves with ApproveKeyRecovery, putting its
+// share on the ledger encrypted to that public key, and once threshold
+// custodians have approved the requester combines the shares off-chain,
+// checking each against its recorded hash. A recovery records the escrow
+// and custodians it was opened against, so only those custodians can
+// approve it, and none once the escrow has been replaced or the recovery
+// is APPROVED. Recoveries are stored under
+// "RECOVERY~<key ID>~<tx ID>". The gateway's escrow package splits and
+// combines shares.
+const (
+	escrowObjectType   = "ESCROW"
+	recoveryObjectType = "RECOVERY"
+)
+
+// Key recovery statuses
+const (
+	RecoveryRequested = "REQUESTED"
+	RecoveryApproved  = "APPROVED"
+)
+
+// KeyEscrow records how a field key is split among custodians
+type KeyEscrow struct {
+	KeyID      string        `json:"keyId"`
+	OwnerMSP   string        `json:"ownerMsp"`
+	Threshold  int           `json:"threshold"` // shares needed to rebuild the key
+	Shares     []EscrowShare `json:"shares"`
+	EscrowedBy string        `json:"escrowedBy"`
+	EscrowedAt string        `json:"escrowedAt"`
+	TxID       string        `json:"txId"`
+}
+
+// EscrowShare is the share of a key one custodian holds
+type EscrowShare struct {
+	CustodianMSP string `json:"custodianMsp"`
+	ShareHash    string `json:"shareHash"` // hex SHA-256 of the share
+}
+
+// KeyRecovery is a request to rebuild an escrowed key from its shares
+type KeyRecovery struct {
+	RecoveryID   string             `json:"recoveryId"` // the requesting transaction
+	KeyID        string             `json:"keyId"`
+	OwnerMSP     string             `json:"ownerMsp"`
+	RecipientKey string             `json:"recipientKey"` // PEM public key the shares are released to
+	Reason       string             `json:"reason"`
+	Status       string             `json:"status"` // REQUESTED, APPROVED
+	Threshold    int                `json:"threshold"`
+	EscrowTxID   string             `json:"escrowTxId"` // the escrow the recovery was opened against
+	Custodians   []string           `json:"custodians"` // the custodians of that escrow
+	Approvals    []RecoveryApproval `json:"approvals"`
+	RequestedBy  string             `json:"requestedBy"`
+	RequestedAt  string             `json:"requestedAt"`
+	ApprovedAt   string             `json:"approvedAt,omitempty" metadata:",optional"` // when the threshold was reached
+}
+
+// RecoveryApproval is a custodian's release of its share for a recovery
+type RecoveryApproval struct {
+	CustodianMSP   string `json:"custodianMsp"`
+	EncryptedShare string `json:"encryptedShare"` // base64, encrypted to the recovery's recipient key
+	ApprovedBy     string `json:"approvedBy"`
+	ApprovedAt     string `json:"approvedAt"`
+}
+
+// escrowInput is the payload accepted by EscrowFieldKey
+type escrowInput struct {
+	Threshold int           `json:"threshold"`
+	Shares    []EscrowShare `json:"shares"`
+}
+
+// EscrowFieldKey records the escrow of one of the caller's organisation's
+// field keys. escrowData holds the threshold and the hash of the share
+// each custodian organisation was given; at least two custodians are needed
+// and the threshold must be at least two. Escrowing a key again replaces
+// its escrow, as after re-splitting it with new custodians.
+func (s *SmartContract) EscrowFieldKey(ctx contractapi.TransactionContextInterface, keyID string, escrowData string) (*KeyEscrow, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ownFieldKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var input escrowInput
+	err = json.Unmarshal([]byte(escrowData), &input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal escrow: %v", err)
+	}
+	if len(input.Shares) < 2 {
+		return nil, fmt.Errorf("a key must be escrowed with at least two custodians")
+	}
+	if input.Threshold < 2 || input.Threshold > len(input.Shares) {
+		return nil, fmt.Errorf("threshold must be between 2 and the number of custodians, %d", len(input.Shares))
+	}
+	custodians := map[string]bool{}
+	for i := range input.Shares {
+		share := &input.Shares[i]
+		share.CustodianMSP = strings.TrimSpace(share.CustodianMSP)
+		share.ShareHash = strings.ToLower(share.ShareHash)
+		if share.CustodianMSP == "" || share.CustodianMSP == key.OwnerMSP {
+			return nil, fmt.Errorf("custodians must be other organisations' MSP IDs")
+		}
+		if custodians[share.CustodianMSP] {
+			return nil, fmt.Errorf("custodian %s is named twice", share.CustodianMSP)
+		}
+		custodians[share.CustodianMSP] = true
+		if !sha256HexPattern.MatchString(share.ShareHash) {
+			return nil, fmt.Errorf("share hash for %s must be a hex SHA-256 digest", share.CustodianMSP)
+		}
+	}
+	escrowedBy, err := clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	escrow := &KeyEscrow{
+		KeyID:      key.KeyID,
+		OwnerMSP:   key.OwnerMSP,
+		Threshold:  input.Threshold,
+		Shares:     input.Shares,
+		EscrowedBy: escrowedBy,
+		EscrowedAt: s.txTime(ctx).Format(time.RFC3339),
+		TxID:       ctx.GetStub().GetTxID(),
+	}
+	return escrow, putKeyEscrow(ctx, escrow)
+}
+
+// GetKeyEscrow returns the escrow of a field key
+func (s *SmartContract) GetKeyEscrow(ctx contractapi.TransactionContextInterface, keyID string) (*KeyEscrow, error) {
+	escrow, err := getKeyEscrow(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if escrow == nil {
+		return nil, fmt.Errorf("field key %s is not escrowed", keyID)
+	}
+	return escrow, nil
+}
+
+// RequestKeyRecovery opens the recovery of one of the caller's
+// organisation's escrowed keys. Custodians release their shares encrypted
+// to recipientKey, a PEM public key whose private half only the requester
+// holds.
+func (s *SmartContract) RequestKeyRecovery(ctx contractapi.TransactionContextInterface, keyID string, recipientKey string, reason string) (*KeyRecovery, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ownFieldKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	escrow, err := s.GetKeyEscrow(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(recipientKey, "PUBLIC KEY-----") {
+		return nil, fmt.Errorf("recipient key must be a PEM public key")
+	}
+	if strings.TrimSpace(reason) == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+	requestedBy, err := clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+	custodians := make([]string, 0, len(escrow.Shares))
+	for _, share := range escrow.Shares {
+		custodians = append(custodians, share.CustodianMSP)
+	}
+
+	recovery := &KeyRecovery{
+		RecoveryID:   ctx.GetStub().GetTxID(),
+		KeyID:        key.KeyID,
+		OwnerMSP:     key.OwnerMSP,
+		RecipientKey: recipientKey,
+		Reason:       reason,
+		Status:       RecoveryRequested,
+		Threshold:    escrow.Threshold,
+		EscrowTxID:   escrow.TxID,
+		Custodians:   custodians,
+		Approvals:    []RecoveryApproval{},
+		RequestedBy:  requestedBy,
+		RequestedAt:  s.txTime(ctx).Format(time.RFC3339),
+	}
+	return recovery, putKeyRecovery(ctx, recovery)
+}
+
+// ApproveKeyRecovery releases the caller's organisation's share of a key
+// to a recovery, encrypted to the recovery's recipient key. Only an
+// administrator of a custodian of the escrow the recovery was opened
+// against can approve, once per custodian, while that escrow is still the
+// key's and the recovery is not yet APPROVED, which it is when threshold
+// custodians have approved.
+func (s *SmartContract) ApproveKeyRecovery(ctx contractapi.TransactionContextInterface, keyID string, recoveryID string, encryptedShare string) (*KeyRecovery, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	recovery, err := s.GetKeyRecovery(ctx, keyID, recoveryID)
+	if err != nil {
+		return nil, err
+	}
+	if recovery.Status == RecoveryApproved {
+		return nil, fmt.Errorf("recovery %s is already approved", recoveryID)
+	}
+	escrow, err := s.GetKeyEscrow(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if recovery.EscrowTxID == "" {
+		return nil, fmt.Errorf("recovery %s does not record the escrow it was opened against; open a new recovery", recoveryID)
+	}
+	if escrow.TxID != recovery.EscrowTxID {
+		return nil, fmt.Errorf("field key %s has been escrowed again since recovery %s was opened; open a new recovery", keyID, recoveryID)
+	}
+	custodian := false
+	for _, custodianMSP := range recovery.Custodians {
+		if custodianMSP == mspID {
+			custodian = true
+		}
+	}
+	if !custodian {
+		return nil, fmt.Errorf("%s is not a custodian of recovery %s", mspID, recoveryID)
+	}
+	for _, approval := range recovery.Approvals {
+		if approval.CustodianMSP == mspID {
+			return nil, fmt.Errorf("%s has already approved recovery %s", mspID, recoveryID)
+		}
+	}
+	share, err := base64.StdEncoding.DecodeString(encryptedShare)
+	if err != nil || len(share) == 0 {
+		return nil, fmt.Errorf("encrypted share must be base64")
+	}
+	approvedBy, err := clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	now := s.txTime(ctx).Format(time.RFC3339)
+	recovery.Approvals = append(recovery.Approvals, RecoveryApproval{
+		CustodianMSP:   mspID,
+		EncryptedShare: encryptedShare,
+		ApprovedBy:     approvedBy,
+		ApprovedAt:     now,
+	})
+	if len(recovery.Approvals) >= recovery.Threshold {
+		recovery.Status = RecoveryApproved
+		recovery.ApprovedAt = now
+	}
+	return recovery, putKeyRecovery(ctx, recovery)
+}
+
+// GetKeyRecovery returns a key recovery with the shares released to it
+func (s *SmartContract) GetKeyRecovery(ctx contractapi.TransactionContextInterface, keyID string, recoveryID string) (*KeyRecovery, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(recoveryObjectType, []string{keyID, recoveryID})
+	if err != nil {
+		return nil, err
+	}
+	recoveryJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if recoveryJSON == nil {
+		return nil, fmt.Errorf("key recovery %s of field key %s does not exist", recoveryID, keyID)
+	}
+
+	var recovery KeyRecovery
+	err = json.Unmarshal(recoveryJSON, &recovery)
+	if err != nil {
+		return nil, err
+	}
+	return &recovery, nil
+}
+
+// getKeyEscrow loads the escrow of a field key, returning nil when it is
+// not escrowed
+func getKeyEscrow(ctx contractapi.TransactionContextInterface, keyID string) (*KeyEscrow, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(escrowObjectType, []string{keyID})
+	if err != nil {
+		return nil, err
+	}
+	escrowJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if escrowJSON == nil {
+		return nil, nil
+	}
+
+	var escrow KeyEscrow
+	err = json.Unmarshal(escrowJSON, &escrow)
+	if err != nil {
+		return nil, err
+	}
+	return &escrow, nil
+}
+
+// putKeyEscrow stores a key escrow under its key
+func putKeyEscrow(ctx contractapi.TransactionContextInterface, escrow *KeyEscrow) error {
+	key, err := ctx.GetStub().CreateCompositeKey(escrowObjectType, []string{escrow.KeyID})
+	if err != nil {
+		return err
+	}
+	escrowJSON, err := json.Marshal(escrow)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, escrowJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put key escrow: %v", err)
+	}
+	return nil
+}
+
+// putKeyRecovery stores a key recovery under its key
+func putKeyRecovery(ctx contractapi.TransactionContextInterface, recovery *KeyRecovery) error {
+	key, err := ctx.GetStub().CreateCompositeKey(recoveryObjectType, []string{recovery.KeyID, recovery.RecoveryID})
+	if err != nil {
+		return err
+	}
+	recoveryJSON, err := json.Marshal(recovery)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, recoveryJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put key recovery: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// reportESignObjectType keys the eSign attestations of monthly summaries,
+// "reportESign~<month>~<eSign transaction ID>". Summaries are immutable once
+// generated, so their attestations are kept beside them rather than on them.
+const reportESignObjectType = "reportESign"
+
+// aadhaarNumberPattern matches a bare Aadhaar number, which must never reach
+// the ledger
+var aadhaarNumberPattern = regexp.MustCompile(`^[0-9]{12}$`)
+
+// ESignAttestation attests a document signed through an eSign service
+// provider, such as Aadhaar eSign. Only the hash of the signed document is
+// kept on the ledger; the signed document itself stays with the submitter.
+type ESignAttestation struct {
+	SignerRef    string `json:"signerRef"`    // the provider's reference for the signer, never an Aadhaar number
+	DocumentHash string `json:"documentHash"` // hex SHA-256 of the signed document
+	ESignTxnID   string `json:"esignTxnId"`   // the provider's transaction ID for the signature
+	Provider     string `json:"provider,omitempty" metadata:",optional"`
+	SignedAt     string `json:"signedAt"`
+	ValidUntil   string `json:"validUntil,omitempty" metadata:",optional"` // end of the signing certificate's validity
+	Valid        bool   `json:"valid"`                                     // the provider's response verified when it was received
+	RecordedBy   string `json:"recordedBy"`
+	RecordedAt   string `json:"recordedAt"`
+	TxID         string `json:"txId"`
+}
+
+// reportAttestation is an eSign attestation of a monthly summary as stored
+type reportAttestation struct {
+	Month string `json:"month"`
+	ESignAttestation
+}
+
+// esignInput is the payload accepted by AttachESignAttestation and
+// AttachReportESignAttestation
+type esignInput struct {
+	SignerRef    string `json:"signerRef"`
+	DocumentHash string `json:"documentHash"`
+	ESignTxnID   string `json:"esignTxnId"`
+	Provider     string `json:"provider"`
+	SignedAt     string `json:"signedAt"`
+	ValidUntil   string `json:"validUntil"`
+	Valid        bool   `json:"valid"`
+}
+
+// AttachESignAttestation attaches an eSign attestation of a customer
+// declaration to a record. Only the organisation that submitted the record
+// can attach one.
+func (s *SmartContract) AttachESignAttestation(ctx contractapi.TransactionContextInterface, kycID string, attestationData string) error {
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	if kyc.OwnerMSP != "" && kyc.OwnerMSP != mspID {
+		return fmt.Errorf("KYC record %s is owned by %s", kycID, kyc.OwnerMSP)
+	}
+
+	attestation, err := newESignAttestation(ctx, attestationData)
+	if err != nil {
+		return err
+	}
+	for _, existing := range kyc.ESignAttestations {
+		if existing.ESignTxnID == attestation.ESignTxnID {
+			return fmt.Errorf("KYC record %s already has an eSign attestation for transaction %s", kycID, attestation.ESignTxnID)
+		}
+	}
+
+	kyc.UpdatedAt = attestation.RecordedAt
+	kyc.ESignAttestations = append(kyc.ESignAttestations, *attestation)
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return err
+	}
+	err = putRecordState(ctx, kycID, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update KYC record: %v", err)
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-ESIGN_ATTESTED-%d", kycID, s.txTime(ctx).Unix()),
+		KYCID:       kycID,
+		Action:      "ESIGN_ATTESTED",
+		PerformedBy: attestation.RecordedBy,
+		PerformedAt: attestation.RecordedAt,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"documentHash": attestation.DocumentHash,
+			"esignTxnId":   attestation.ESignTxnID,
+			"valid":        attestation.Valid,
+		},
+	}
+
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return nil
+}
+
+// AttachReportESignAttestation attaches an eSign attestation, such as a
+// compliance officer's signed sign-off, to a generated monthly summary
+func (s *SmartContract) AttachReportESignAttestation(ctx contractapi.TransactionContextInterface, month string, attestationData string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = s.GetMonthlySummary(ctx, month)
+	if err != nil {
+		return err
+	}
+
+	attestation, err := newESignAttestation(ctx, attestationData)
+	if err != nil {
+		return err
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(reportESignObjectType, []string{month, attestation.ESignTxnID})
+	if err != nil {
+		return err
+	}
+	existing, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("summary for %s already has an eSign attestation for transaction %s", month, attestation.ESignTxnID)
+	}
+
+	attestationJSON, err := json.Marshal(reportAttestation{Month: month, ESignAttestation: *attestation})
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(key, attestationJSON)
+}
+
+// GetReportESignAttestations returns the eSign attestations of a monthly summary
+func (s *SmartContract) GetReportESignAttestations(ctx contractapi.TransactionContextInterface, month string) ([]*ESignAttestation, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(reportESignObjectType, []string{month})
+	if err != nil {
+		return nil, err
+	}
+
+	attestations := []*ESignAttestation{}
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		var attestation reportAttestation
+		err := json.Unmarshal(queryResponse.Value, &attestation)
+		if err != nil {
+			return err
+		}
+		attestations = append(attestations, &attestation.ESignAttestation)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attestations, nil
+}
+
+// newESignAttestation validates an attestation payload and stamps it with
+// the recording identity and transaction
+func newESignAttestation(ctx contractapi.TransactionContextInterface, attestationData string) (*ESignAttestation, error) {
+	var input esignInput
+	err := json.Unmarshal([]byte(attestationData), &input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal eSign attestation: %v", err)
+	}
+
+	if input.SignerRef == "" {
+		return nil, fmt.Errorf("signer reference is required")
+	}
+	if aadhaarNumberPattern.MatchString(strings.ReplaceAll(input.SignerRef, " ", "")) {
+		return nil, fmt.Errorf("signer reference must not be an Aadhaar number; use the provider's reference for the signer")
+	}
+	input.DocumentHash = strings.ToLower(input.DocumentHash)
+	if !sha256HexPattern.MatchString(input.DocumentHash) {
+		return nil, fmt.Errorf("document hash must be a hex SHA-256 digest")
+	}
+	if input.ESignTxnID == "" {
+		return nil, fmt.Errorf("eSign transaction ID is required")
+	}
+	signedAt, err := time.Parse(time.RFC3339, input.SignedAt)
+	if err != nil {
+		return nil, fmt.Errorf("signedAt must be an RFC 3339 timestamp")
+	}
+	validUntil := ""
+	if input.ValidUntil != "" {
+		until, err := time.Parse(time.RFC3339, input.ValidUntil)
+		if err != nil {
+			return nil, fmt.Errorf("validUntil must be an RFC 3339 timestamp")
+		}
+		if until.Before(signedAt) {
+			return nil, fmt.Errorf("validUntil cannot be before signedAt")
+		}
+		validUntil = until.UTC().Format(time.RFC3339)
+	}
+
+	recordedBy, err := clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+	return &ESignAttestation{
+		SignerRef:    input.SignerRef,
+		DocumentHash: input.DocumentHash,
+		ESignTxnID:   input.ESignTxnID,
+		Provider:     input.Provider,
+		SignedAt:     signedAt.UTC().Format(time.RFC3339),
+		ValidUntil:   validUntil,
+		Valid:        input.Valid,
+		RecordedBy:   recordedBy,
+		RecordedAt:   txTime(ctx).Format(time.RFC3339),
+		TxID:         ctx.GetStub().GetTxID(),
+	}, nil
+}
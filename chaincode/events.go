@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Record events. Every transaction that changes a record emits one chaincode
+// event for it once it has succeeded, so gateway listeners can notify and
+// sync without polling. putRecordState and deleteRecordState note each
+// record a transaction writes, with its state before the transaction, and
+// emitRecordEvent names the change by comparing the two:
+//
+//   - KYCCreated, or KYCRestored when the record came back from the archive
+//   - KYCDeleted, or KYCArchived when it went to the archive
+//   - KYCVerified, KYCRejected or KYCStatusChanged when its status changed
+//   - DocumentAdded when it gained a document, or a document's hash changed
+//   - KYCUpdated for any other change
+//
+// Fabric keeps one event per transaction, so a transaction changing several
+// records emits KYCBatchChanged listing each change instead, and a
+// transaction that sets its own event, such as AccessRevoked, emits that
+// rather than a record event.
+const (
+	kycCreatedEvent       = "KYCCreated"
+	kycRestoredEvent      = "KYCRestored"
+	kycDeletedEvent       = "KYCDeleted"
+	kycArchivedEvent      = "KYCArchived"
+	kycVerifiedEvent      = "KYCVerified"
+	kycRejectedEvent      = "KYCRejected"
+	kycStatusChangedEvent = "KYCStatusChanged"
+	documentAddedEvent    = "DocumentAdded"
+	kycUpdatedEvent       = "KYCUpdated"
+	kycBatchChangedEvent  = "KYCBatchChanged"
+)
+
+// RecordEvent is the payload of a record event
+type RecordEvent struct {
+	Event          string   `json:"event"`
+	KYCID          string   `json:"kycId"`
+	Status         string   `json:"status,omitempty" metadata:",optional"`         // the status after the change; absent once deleted
+	PreviousStatus string   `json:"previousStatus,omitempty" metadata:",optional"` // absent for a new record
+	OwnerMSP       string   `json:"ownerMsp,omitempty" metadata:",optional"`
+	Action         string   `json:"action,omitempty" metadata:",optional"`      // the action of the change's last history entry
+	DocumentIDs    []string `json:"documentIds,omitempty" metadata:",optional"` // for DocumentAdded, the documents added or replaced
+	TxID           string   `json:"txId"`
+	Timestamp      string   `json:"timestamp"`
+}
+
+// RecordBatchEvent is the payload of a KYCBatchChanged event
+type RecordBatchEvent struct {
+	KYCIDs    []string      `json:"kycIds"`
+	Records   []RecordEvent `json:"records"` // in the order the transaction first wrote them
+	TxID      string        `json:"txId"`
+	Timestamp string        `json:"timestamp"`
+}
+
+// recordChange is what a transaction has done to one record
+type recordChange struct {
+	before []byte // the record's state before the transaction, nil if it had none
+	after  []byte // the record last written, nil once deleted
+	action string // the action of the record's last history entry
+}
+
+// noteRecordChange remembers that a transaction is writing or, given nil,
+// deleting a record, and returns the record as it stood before the write:
+// as the transaction last wrote it, or else as committed. Call it before the
+// write, so the state before the transaction can still be read.
+func noteRecordChange(ctx contractapi.TransactionContextInterface, kycID string, kycJSON []byte) ([]byte, error) {
+	tx, ok := ctx.(*txContext)
+	if !ok {
+		return getRecordState(ctx, kycID)
+	}
+	change, ok := tx.recordChanges[kycID]
+	if !ok {
+		before, err := getRecordState(ctx, kycID)
+		if err != nil {
+			return nil, err
+		}
+		if tx.recordChanges == nil {
+			tx.recordChanges = map[string]*recordChange{}
+		}
+		change = &recordChange{before: before, after: before}
+		tx.recordChanges[kycID] = change
+		tx.recordOrder = append(tx.recordOrder, kycID)
+	}
+	previous := change.after
+	change.after = kycJSON
+	return previous, nil
+}
+
+// noteHistoryAction remembers the action of a history entry a transaction
+// writes for a changed record
+func noteHistoryAction(ctx contractapi.TransactionContextInterface, kycID string, action string) {
+	if tx, ok := ctx.(*txContext); ok {
+		if change, ok := tx.recordChanges[kycID]; ok {
+			change.action = action
+		}
+	}
+}
+
+// setEvent sets a transaction's event, in place of its record event
+func setEvent(ctx contractapi.TransactionContextInterface, name string, payload []byte) error {
+	err := ctx.GetStub().SetEvent(name, payload)
+	if err != nil {
+		return fmt.Errorf("failed to set %s event: %v", name, err)
+	}
+	if tx, ok := ctx.(*txContext); ok {
+		tx.eventSet = true
+	}
+	return nil
+}
+
+// emitRecordEvent emits the event of the records a transaction changed,
+// unless it set an event of its own
+func emitRecordEvent(ctx contractapi.TransactionContextInterface) error {
+	tx, ok := ctx.(*txContext)
+	if !ok || tx.eventSet || len(tx.recordOrder) == 0 {
+		return nil
+	}
+	txID := ctx.GetStub().GetTxID()
+	timestamp := txTime(ctx).Format(time.RFC3339)
+
+	events := []RecordEvent{}
+	for _, kycID := range tx.recordOrder {
+		event, err := describeRecordChange(kycID, tx.recordChanges[kycID])
+		if err != nil {
+			return err
+		}
+		if event == nil {
+			continue
+		}
+		event.TxID, event.Timestamp = txID, timestamp
+		events = append(events, *event)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	name, payload := events[0].Event, interface{}(events[0])
+	if len(events) > 1 {
+		batch := RecordBatchEvent{Records: events, TxID: txID, Timestamp: timestamp}
+		for _, event := range events {
+			batch.KYCIDs = append(batch.KYCIDs, event.KYCID)
+		}
+		name, payload = kycBatchChangedEvent, batch
+	}
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return setEvent(ctx, name, payloadJSON)
+}
+
+// describeRecordChange names what a transaction did to a record, or returns
+// nil if it left the record as it was
+func describeRecordChange(kycID string, change *recordChange) (*RecordEvent, error) {
+	before, err := unmarshalRecordState(kycID, change.before)
+	if err != nil {
+		return nil, err
+	}
+	after, err := unmarshalRecordState(kycID, change.after)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &RecordEvent{KYCID: kycID, Action: change.action}
+	if before != nil {
+		event.PreviousStatus = before.Status
+		event.OwnerMSP = before.OwnerMSP
+	}
+	if after != nil {
+		event.Status = after.Status
+		event.OwnerMSP = after.OwnerMSP
+	}
+	switch {
+	case before == nil && after == nil:
+		return nil, nil
+	case before == nil && change.action == "RESTORED":
+		event.Event = kycRestoredEvent
+	case before == nil:
+		event.Event = kycCreatedEvent
+	case after == nil && change.action == "ARCHIVED":
+		event.Event = kycArchivedEvent
+	case after == nil:
+		event.Event = kycDeletedEvent
+	case after.Status != before.Status && after.Status == "VERIFIED":
+		event.Event = kycVerifiedEvent
+	case after.Status != before.Status && after.Status == "REJECTED":
+		event.Event = kycRejectedEvent
+	case after.Status != before.Status:
+		event.Event = kycStatusChangedEvent
+	case len(addedDocuments(before.DocumentHashes, after.DocumentHashes)) > 0:
+		event.Event = documentAddedEvent
+		event.DocumentIDs = addedDocuments(before.DocumentHashes, after.DocumentHashes)
+	case string(change.before) == string(change.after):
+		return nil, nil
+	default:
+		event.Event = kycUpdatedEvent
+	}
+	return event, nil
+}
+
+// unmarshalRecordState decodes a record's state, nil if it had none
+func unmarshalRecordState(kycID string, kycJSON []byte) (*KYCRecord, error) {
+	if kycJSON == nil {
+		return nil, nil
+	}
+	var kyc KYCRecord
+	err := unmarshalRecord(kycJSON, &kyc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal KYC record %s: %v", kycID, err)
+	}
+	return &kyc, nil
+}
+
+// addedDocuments returns the IDs of the documents after a change that were
+// not there before it, or had a different hash
+func addedDocuments(before []DocumentHash, after []DocumentHash) []string {
+	hashes := map[string]string{}
+	for _, document := range before {
+		hashes[document.ID] = document.Hash
+	}
+	added := []string{}
+	for _, document := range after {
+		if hash, ok := hashes[document.ID]; !ok || hash != document.Hash {
+			added = append(added, document.ID)
+		}
+	}
+	return added
+}
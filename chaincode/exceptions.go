@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Exception register keys. Every exception is written in time order under
+// exceptionPrefix and again under exceptionTypePrefix+type, so the register
+// can be read as one feed or per exception type.
+const (
+	exceptionPrefix     = "EXCEPTION_"
+	exceptionTypePrefix = "EXCEPTIONTYPE_"
+)
+
+// Exception types
+const (
+	ExceptionRiskOverride      = "RISK_OVERRIDE"
+	ExceptionBlacklistOverride = "BLACKLIST_OVERRIDE"
+	ExceptionSLABreach         = "SLA_BREACH"
+)
+
+// ExceptionEntry is one approved deviation from normal onboarding policy
+type ExceptionEntry struct {
+	ID            string                 `json:"id"` // transaction ID that raised the exception
+	Type          string                 `json:"type"`
+	KYCID         string                 `json:"kycId"`
+	RaisedAt      string                 `json:"raisedAt"`
+	RequestedBy   string                 `json:"requestedBy,omitempty" metadata:",optional"`
+	ApprovedBy    string                 `json:"approvedBy,omitempty" metadata:",optional"`
+	Justification string                 `json:"justification,omitempty" metadata:",optional"`
+	Details       map[string]interface{} `json:"details,omitempty" metadata:",optional"`
+}
+
+// ExceptionPage holds one page of the exception register
+type ExceptionPage struct {
+	Exceptions          []*ExceptionEntry `json:"exceptions"`
+	FetchedRecordsCount int32             `json:"fetchedRecordsCount"`
+	Bookmark            string            `json:"bookmark"`
+	Truncated           bool              `json:"truncated"` // the page was cut short by maxResponseBytes
+}
+
+// GetExceptions returns one page of the exception register between from and
+// to inclusive, oldest first. Bounds are dates (YYYY-MM-DD) or RFC 3339
+// timestamps and either may be empty; an empty exceptionType returns every type.
+func (s *SmartContract) GetExceptions(ctx contractapi.TransactionContextInterface, from string, to string, exceptionType string, pageSize int32, bookmark string) (*ExceptionPage, error) {
+	for _, bound := range []string{from, to} {
+		if bound == "" {
+			continue
+		}
+		_, dateErr := time.Parse("2006-01-02", bound)
+		_, timeErr := time.Parse(time.RFC3339, bound)
+		if dateErr != nil && timeErr != nil {
+			return nil, fmt.Errorf("invalid date %q: expected YYYY-MM-DD or RFC 3339", bound)
+		}
+	}
+
+	prefix := exceptionPrefix
+	if exceptionType != "" {
+		prefix = exceptionTypePrefix + strings.ToUpper(exceptionType) + "_"
+	}
+	return getExceptionsByRange(ctx, prefix+from, prefix+to+"\xff", pageSize, bookmark)
+}
+
+// recordException adds an entry to the exception register
+func recordException(ctx contractapi.TransactionContextInterface, entry ExceptionEntry) error {
+	entry.ID = ctx.GetStub().GetTxID()
+	entryJSON, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	suffix := fmt.Sprintf("%s_%s_%s", entry.RaisedAt, entry.ID, entry.KYCID)
+	err = ctx.GetStub().PutState(exceptionPrefix+suffix, entryJSON)
+	if err != nil {
+		return fmt.Errorf("failed to record exception: %v", err)
+	}
+	err = ctx.GetStub().PutState(exceptionTypePrefix+entry.Type+"_"+suffix, entryJSON)
+	if err != nil {
+		return fmt.Errorf("failed to record exception: %v", err)
+	}
+	return nil
+}
+
+// getExceptionsByRange pages through exception register keys in [startKey, endKey)
+func getExceptionsByRange(ctx contractapi.TransactionContextInterface, startKey string, endKey string, pageSize int32, bookmark string) (*ExceptionPage, error) {
+	err := checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	resultsIterator, responseMetadata, err := ctx.GetStub().GetStateByRangeWithPagination(startKey, endKey, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	budget, err := newResponseBudget(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	exceptions := []*ExceptionEntry{}
+	bookmark, truncated := responseMetadata.Bookmark, false
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var entry ExceptionEntry
+		err = json.Unmarshal(queryResponse.Value, &entry)
+		if err != nil {
+			return nil, err
+		}
+		fits, err := budget.fits(&entry)
+		if err != nil {
+			return nil, err
+		}
+		if !fits {
+			bookmark, truncated = queryResponse.Key, true
+			break
+		}
+		exceptions = append(exceptions, &entry)
+	}
+
+	return &ExceptionPage{
+		Exceptions:          exceptions,
+		FetchedRecordsCount: int32(len(exceptions)),
+		Bookmark:            bookmark,
+		Truncated:           truncated,
+	}, nil
+}
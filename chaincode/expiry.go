@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Expiry reminders. SendExpiryReminders sweeps the records a page at a time
+// and emits one KYCExpiringSoon event naming the verified records whose
+// re-KYC falls due within the window, so the gateway's notification worker
+// can tell their customers. Each reminder is remembered under
+// "EXPIRYREMINDER~<kycID>" with the expiry it was sent for, so a record is
+// reminded once per verification however often the sweep runs.
+const (
+	expiryReminderObjectType = "EXPIRYREMINDER"
+
+	// kycExpiringSoonEvent is the chaincode event a sweep emits, with the
+	// ExpiryReminders as its payload
+	kycExpiringSoonEvent = "KYCExpiringSoon"
+)
+
+// ExpiryReminders lists the records one sweep reminded
+type ExpiryReminders struct {
+	KYCIDs     []string `json:"kycIds"`
+	WithinDays int      `json:"withinDays"`
+	TxID       string   `json:"txId"`
+	Timestamp  string   `json:"timestamp"`
+}
+
+// ExpiryReminderResult summarises one page of an expiry sweep
+type ExpiryReminderResult struct {
+	Scanned  int      `json:"scanned"`
+	Reminded []string `json:"reminded"`
+	Bookmark string   `json:"bookmark"`
+}
+
+// SendExpiryReminders reminds the customers of VERIFIED records due for
+// re-KYC within withinDays, one page of records at a time; records already
+// past due are included, and those already reminded of their current expiry
+// are skipped. Run it daily, following the bookmark to the end.
+func (s *SmartContract) SendExpiryReminders(ctx contractapi.TransactionContextInterface, withinDays int, pageSize int32, bookmark string) (*ExpiryReminderResult, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if withinDays <= 0 {
+		return nil, fmt.Errorf("withinDays must be positive")
+	}
+	err = checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.txTime(ctx)
+	horizon := now.AddDate(0, 0, withinDays).Format(time.RFC3339)
+	result := &ExpiryReminderResult{Reminded: []string{}}
+	// the reminders' writes rule out paginated queries
+	next, done, err := scanCompositeKeys(ctx, recordObjectType, bookmark, int(pageSize), func(key string, value []byte) (bool, error) {
+		var kyc KYCRecord
+		err := unmarshalRecord(value, &kyc)
+		if err != nil {
+			return false, err
+		}
+		result.Scanned++
+
+		if kyc.Status != "VERIFIED" || kyc.ExpiresAt == "" || kyc.ExpiresAt > horizon {
+			return true, nil
+		}
+		reminderKey, err := ctx.GetStub().CreateCompositeKey(expiryReminderObjectType, []string{kyc.ID})
+		if err != nil {
+			return false, err
+		}
+		remindedFor, err := ctx.GetStub().GetState(reminderKey)
+		if err != nil {
+			return false, err
+		}
+		if string(remindedFor) == kyc.ExpiresAt {
+			return true, nil
+		}
+		err = ctx.GetStub().PutState(reminderKey, []byte(kyc.ExpiresAt))
+		if err != nil {
+			return false, err
+		}
+		result.Reminded = append(result.Reminded, kyc.ID)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !done {
+		result.Bookmark = next
+	}
+
+	if len(result.Reminded) == 0 {
+		return result, nil
+	}
+	reminders := ExpiryReminders{
+		KYCIDs:     result.Reminded,
+		WithinDays: withinDays,
+		TxID:       ctx.GetStub().GetTxID(),
+		Timestamp:  now.Format(time.RFC3339),
+	}
+	remindersJSON, err := json.Marshal(reminders)
+	if err != nil {
+		return nil, err
+	}
+	err = setEvent(ctx, kycExpiringSoonEvent, remindersJSON)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
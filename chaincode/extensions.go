@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+var extensionNamespacePattern = regexp.MustCompile(`^[a-z][a-z0-9_.-]{1,63}$`)
+
+// ExtensionSchema is the JSON schema an organization registered for an extension namespace
+type ExtensionSchema struct {
+	Namespace    string `json:"namespace"`
+	Schema       string `json:"schema"`
+	Version      int    `json:"version"`
+	OwnerMSP     string `json:"ownerMsp"`
+	RegisteredAt string `json:"registeredAt"`
+	UpdatedAt    string `json:"updatedAt"`
+}
+
+// RegisterExtensionSchema registers or replaces the JSON schema for an extension
+// namespace. The first organization to register a namespace owns it. Only
+// administrators of organizations on the submitter allowlist can register.
+func (s *SmartContract) RegisterExtensionSchema(ctx contractapi.TransactionContextInterface, namespace string, jsonSchema string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	err = requireAllowedMSP(ctx, AllowlistSubmitter)
+	if err != nil {
+		return err
+	}
+	if !extensionNamespacePattern.MatchString(namespace) {
+		return fmt.Errorf("invalid extension namespace %q", namespace)
+	}
+
+	_, err = compileExtensionSchema(jsonSchema)
+	if err != nil {
+		return err
+	}
+
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+
+	now := s.txTime(ctx).Format(time.RFC3339)
+	schema, err := s.GetExtensionSchema(ctx, namespace)
+	if err != nil {
+		schema = &ExtensionSchema{
+			Namespace:    namespace,
+			OwnerMSP:     mspID,
+			RegisteredAt: now,
+		}
+	} else if schema.OwnerMSP != mspID {
+		return fmt.Errorf("extension namespace %s is owned by %s", namespace, schema.OwnerMSP)
+	}
+
+	schema.Schema = jsonSchema
+	schema.Version++
+	schema.UpdatedAt = now
+
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(extensionSchemaKey(namespace), schemaJSON)
+}
+
+// GetExtensionSchema returns the schema registered for an extension namespace
+func (s *SmartContract) GetExtensionSchema(ctx contractapi.TransactionContextInterface, namespace string) (*ExtensionSchema, error) {
+	schemaJSON, err := ctx.GetStub().GetState(extensionSchemaKey(namespace))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if schemaJSON == nil {
+		return nil, fmt.Errorf("extension namespace %s is not registered", namespace)
+	}
+
+	var schema ExtensionSchema
+	err = json.Unmarshal(schemaJSON, &schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema, nil
+}
+
+// SetExtension attaches or replaces the data for one extension namespace on a
+// KYC record. Only the organization owning the namespace may write it, and
+// only on records it owns.
+func (s *SmartContract) SetExtension(ctx contractapi.TransactionContextInterface, kycID string, namespace string, extensionData string) error {
+	schema, err := s.GetExtensionSchema(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	if schema.OwnerMSP != mspID {
+		return fmt.Errorf("extension namespace %s is owned by %s", namespace, schema.OwnerMSP)
+	}
+
+	var value interface{}
+	err = json.Unmarshal([]byte(extensionData), &value)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal extension data: %v", err)
+	}
+
+	err = validateExtensionValue(schema, value)
+	if err != nil {
+		return err
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	if kyc.OwnerMSP != "" && kyc.OwnerMSP != mspID {
+		return fmt.Errorf("KYC record %s is owned by %s", kycID, kyc.OwnerMSP)
+	}
+
+	if kyc.Extensions == nil {
+		kyc.Extensions = map[string]interface{}{}
+	}
+	kyc.Extensions[namespace] = value
+	kyc.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return err
+	}
+
+	err = putRecordState(ctx, kycID, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update KYC record: %v", err)
+	}
+
+	performedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-EXTENSION_UPDATED-%d", kycID, s.txTime(ctx).Unix()),
+		KYCID:       kycID,
+		Action:      "EXTENSION_UPDATED",
+		PerformedBy: performedBy,
+		PerformedAt: kyc.UpdatedAt,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"namespace":     namespace,
+			"schemaVersion": schema.Version,
+		},
+	}
+
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+
+	return nil
+}
+
+// validateExtensions checks every extension on a record against its registered schema
+func (s *SmartContract) validateExtensions(ctx contractapi.TransactionContextInterface, extensions map[string]interface{}) error {
+	for namespace, value := range extensions {
+		schema, err := s.GetExtensionSchema(ctx, namespace)
+		if err != nil {
+			return err
+		}
+
+		err = validateExtensionValue(schema, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateExtensionValue validates a single extension value against its schema
+func validateExtensionValue(schema *ExtensionSchema, value interface{}) error {
+	compiled, err := compileExtensionSchema(schema.Schema)
+	if err != nil {
+		return err
+	}
+
+	result, err := compiled.Validate(gojsonschema.NewGoLoader(value))
+	if err != nil {
+		return fmt.Errorf("failed to validate extension %s: %v", schema.Namespace, err)
+	}
+	if !result.Valid() {
+		var problems []string
+		for _, resultErr := range result.Errors() {
+			problems = append(problems, resultErr.String())
+		}
+		return fmt.Errorf("extension %s does not match schema version %d: %s", schema.Namespace, schema.Version, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// compileExtensionSchema parses a JSON schema, refusing references to external
+// documents since resolving them would make endorsement depend on the network
+func compileExtensionSchema(jsonSchema string) (*gojsonschema.Schema, error) {
+	var parsed interface{}
+	err := json.Unmarshal([]byte(jsonSchema), &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal extension schema: %v", err)
+	}
+	if hasExternalRef(parsed) {
+		return nil, fmt.Errorf("extension schemas must not reference external documents")
+	}
+
+	compiled, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(parsed))
+	if err != nil {
+		return nil, fmt.Errorf("invalid extension schema: %v", err)
+	}
+
+	return compiled, nil
+}
+
+// hasExternalRef reports whether a parsed schema contains a $ref outside the document
+func hasExternalRef(node interface{}) bool {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if ref, ok := child.(string); ok && key == "$ref" && !strings.HasPrefix(ref, "#") {
+				return true
+			}
+			if hasExternalRef(child) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if hasExternalRef(child) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func extensionSchemaKey(namespace string) string {
+	return fmt.Sprintf("EXTSCHEMA_%s", namespace)
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const faceHashIndex = "faceHash~kycid"
+
+// faceHashPattern matches a provider-supplied face hash in hex
+var faceHashPattern = regexp.MustCompile(`^[0-9a-f]{16,128}$`)
+
+// FaceMatch is a provider's comparison of an applicant's selfie with the
+// photograph on their identity document. Only hashes of the two images are
+// kept on the ledger.
+type FaceMatch struct {
+	SelfieHash   string `json:"selfieHash"`   // hex SHA-256 of the selfie
+	DocPhotoHash string `json:"docPhotoHash"` // hex SHA-256 of the document photograph
+	Score        int    `json:"score"`        // 0-100
+	Provider     string `json:"provider"`
+	Threshold    int    `json:"threshold"` // faceMatchThreshold when the match was recorded
+	Passed       bool   `json:"passed"`
+	RecordedBy   string `json:"recordedBy"`
+	RecordedAt   string `json:"recordedAt"`
+	TxID         string `json:"txId"`
+}
+
+// RecordFaceMatch stores a face-match result on a record, replacing any
+// earlier one. Whether it passes is judged against the configured
+// faceMatchThreshold; with requireFaceMatch set, a record cannot be
+// verified until its face match passes.
+func (s *SmartContract) RecordFaceMatch(ctx contractapi.TransactionContextInterface, kycID string, selfieHash string, docPhotoHash string, score int, provider string) error {
+	err := requireAttribute(ctx, AttrVerifier)
+	if err != nil {
+		return err
+	}
+
+	selfieHash, docPhotoHash = strings.ToLower(selfieHash), strings.ToLower(docPhotoHash)
+	if !sha256HexPattern.MatchString(selfieHash) || !sha256HexPattern.MatchString(docPhotoHash) {
+		return fmt.Errorf("selfie and document photo hashes must be hex SHA-256 digests")
+	}
+	if score < 0 || score > 100 {
+		return fmt.Errorf("face match score must be between 0 and 100")
+	}
+	if provider == "" {
+		return fmt.Errorf("face match provider is required")
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	recordedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	kyc.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+	kyc.FaceMatch = &FaceMatch{
+		SelfieHash:   selfieHash,
+		DocPhotoHash: docPhotoHash,
+		Score:        score,
+		Provider:     provider,
+		Threshold:    config.FaceMatchThreshold,
+		Passed:       score >= config.FaceMatchThreshold,
+		RecordedBy:   recordedBy,
+		RecordedAt:   kyc.UpdatedAt,
+		TxID:         ctx.GetStub().GetTxID(),
+	}
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return err
+	}
+	err = putRecordState(ctx, kycID, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update KYC record: %v", err)
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-FACE_MATCH_RECORDED-%d", kycID, s.txTime(ctx).Unix()),
+		KYCID:       kycID,
+		Action:      "FACE_MATCH_RECORDED",
+		PerformedBy: recordedBy,
+		PerformedAt: kyc.UpdatedAt,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"score":     score,
+			"provider":  provider,
+			"threshold": config.FaceMatchThreshold,
+			"passed":    kyc.FaceMatch.Passed,
+		},
+	}
+
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return nil
+}
+
+// checkFaceMatch returns an error when the configuration requires a passing
+// face match for verification and the record lacks one. The score is judged
+// against the current threshold, so raising it also holds back records
+// matched under the old one.
+func checkFaceMatch(config *ContractConfig, kyc *KYCRecord) error {
+	if !config.RequireFaceMatch {
+		return nil
+	}
+	if kyc.FaceMatch == nil {
+		return fmt.Errorf("KYC record %s needs a face match before verification", kyc.ID)
+	}
+	if kyc.FaceMatch.Score < config.FaceMatchThreshold {
+		return fmt.Errorf("KYC record %s face match scored %d, below the threshold of %d", kyc.ID, kyc.FaceMatch.Score, config.FaceMatchThreshold)
+	}
+	return nil
+}
+
+// RecordFaceHash stores the perceptual or embedding hash a face-match
+// provider derived from a record's selfie and indexes the record under it.
+// The provider's hash is the same for photographs of the same face, so a
+// hash already indexed under another record flags this one with
+// DUPLICATE_FACE: one person applying under several identities.
+func (s *SmartContract) RecordFaceHash(ctx contractapi.TransactionContextInterface, kycID string, faceHash string) error {
+	err := requireAttribute(ctx, AttrVerifier)
+	if err != nil {
+		return err
+	}
+	faceHash = strings.ToLower(faceHash)
+	if !faceHashPattern.MatchString(faceHash) {
+		return fmt.Errorf("face hash must be 16 to 128 hex digits")
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	recordedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	if kyc.FaceHash != "" {
+		err = deleteIndexEntry(ctx, faceHashIndex, kycID, kyc.FaceHash)
+		if err != nil {
+			return err
+		}
+	}
+	sameFace, err := getIndexedIDs(ctx, faceHashIndex, faceHash)
+	if err != nil {
+		return err
+	}
+
+	before := *kyc
+	kyc.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+	kyc.FaceHash = faceHash
+	if len(sameFace) > 0 {
+		addFlag(kyc, FlagDuplicateFace, fmt.Sprintf("face already registered on %d other record(s)", len(sameFace)), kyc.UpdatedAt)
+	}
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return err
+	}
+	err = putRecordState(ctx, kycID, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update KYC record: %v", err)
+	}
+	err = updateRecordCounters(ctx, &before, kyc)
+	if err != nil {
+		return fmt.Errorf("failed to update counters: %v", err)
+	}
+	err = putIndexEntry(ctx, faceHashIndex, kycID, faceHash)
+	if err != nil {
+		return fmt.Errorf("failed to index KYC record: %v", err)
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-FACE_HASH_RECORDED-%d", kycID, s.txTime(ctx).Unix()),
+		KYCID:       kycID,
+		Action:      "FACE_HASH_RECORDED",
+		PerformedBy: recordedBy,
+		PerformedAt: kyc.UpdatedAt,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"faceHash": faceHash,
+			"kycIds":   sameFace,
+		},
+	}
+
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return nil
+}
+
+// FindFaceCollisions returns the records indexed under a face hash. More
+// than one means the same face appears under several identities.
+func (s *SmartContract) FindFaceCollisions(ctx contractapi.TransactionContextInterface, faceHash string, purposeCode string) ([]*KYCRecord, error) {
+	purpose, err := newReadPurpose(ctx, "FindFaceCollisions", purposeCode)
+	if err != nil {
+		return nil, err
+	}
+	kycIDs, err := getIndexedIDs(ctx, faceHashIndex, strings.ToLower(faceHash))
+	if err != nil {
+		return nil, err
+	}
+
+	kycRecords := []*KYCRecord{}
+	for _, kycID := range kycIDs {
+		kyc, err := s.readKYC(ctx, kycID)
+		if err != nil {
+			return nil, err
+		}
+		kycRecords = append(kycRecords, kyc)
+	}
+	return purpose.filter(ctx, kycRecords)
+}
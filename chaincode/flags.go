@@ -0,0 +1,52 @@
+package main
+
+// Flag codes raised against KYC records
+const (
+	FlagPincodeStateMismatch = "PINCODE_STATE_MISMATCH"
+	FlagDuplicatePhone       = "DUPLICATE_PHONE"
+	FlagBlockedEmailDomain   = "BLOCKED_EMAIL_DOMAIN"
+	FlagAdverseMedia         = "ADVERSE_MEDIA"
+	FlagOCRMismatch          = "OCR_MISMATCH"
+	FlagDuplicateFace        = "DUPLICATE_FACE"
+)
+
+// RecordFlag marks a data-quality or compliance concern raised against a record
+type RecordFlag struct {
+	Code     string `json:"code"`
+	Reason   string `json:"reason"`
+	RaisedAt string `json:"raisedAt"`
+}
+
+// addFlag raises a flag on a record unless one with the same code is already present
+func addFlag(kyc *KYCRecord, code string, reason string, raisedAt string) {
+	for _, flag := range kyc.Flags {
+		if flag.Code == code {
+			return
+		}
+	}
+	kyc.Flags = append(kyc.Flags, RecordFlag{Code: code, Reason: reason, RaisedAt: raisedAt})
+}
+
+// removeFlag clears the flag with the given code from a record, if it has one
+func removeFlag(kyc *KYCRecord, code string) {
+	flags := kyc.Flags[:0]
+	for _, flag := range kyc.Flags {
+		if flag.Code != code {
+			flags = append(flags, flag)
+		}
+	}
+	kyc.Flags = flags
+	if len(kyc.Flags) == 0 {
+		kyc.Flags = nil
+	}
+}
+
+// hasFlag reports whether a record carries a flag with the given code
+func hasFlag(kyc *KYCRecord, code string) bool {
+	for _, flag := range kyc.Flags {
+		if flag.Code == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// Access grants. A grant lets one organisation read the named fields of a
+// record for one purpose, until it expires or has been used for maxReads
+// reads, and is stored under "GRANT~<kycID>~<grant ID>". Consent receipts
+// are enforced through the grants GrantConsent issues for them, one per
+// recipient and purpose; GrantAccess issues a grant directly, and
+// RevokeGrant and RevokeConsent withdraw grants (see revocation.go). Peers
+// answer evaluated transactions without committing what they write, so
+// read counts, like the access log, only advance for submitted reads.
+const grantObjectType = "GRANT"
+
+// grantBaseFields are returned with every read under a grant, whatever its
+// scope: what a relying party needs to know the record's standing
+var grantBaseFields = []string{"schemaVersion", "id", "ownerMsp", "entityType", "status", "verificationLevel", "riskTier", "verifiedAt", "expiresAt", "createdAt", "updatedAt"}
+
+// AccessGrant lets an organisation read part of a record for a purpose
+type AccessGrant struct {
+	GrantID          string   `json:"grantId"`
+	KYCID            string   `json:"kycId"`
+	Grantee          string   `json:"grantee"` // MSP of the organisation granted access
+	Fields           []string `json:"fields"`  // record fields the grantee may read, beyond grantBaseFields
+	Purpose          string   `json:"purpose"`
+	ExpiresAt        string   `json:"expiresAt"`
+	MaxReads         int      `json:"maxReads"` // 0 for no limit
+	Reads            int      `json:"reads"`
+	ConsentReceiptID string   `json:"consentReceiptId,omitempty" metadata:",optional"` // the consent the grant was issued for
+	Sponsor          string   `json:"sponsor,omitempty" metadata:",optional"`          // MSP of the bank mediating a delegate grantee's access
+	GrantedBy        string   `json:"grantedBy"`
+	GrantedAt        string   `json:"grantedAt"`
+	TxID             string   `json:"txId"`
+	RevokedAt        string   `json:"revokedAt,omitempty" metadata:",optional"`
+	RevokedBy        string   `json:"revokedBy,omitempty" metadata:",optional"`
+	RevocationID     string   `json:"revocationId,omitempty" metadata:",optional"` // the transaction that revoked the grant
+}
+
+// grantInput is the payload accepted by GrantAccess
+type grantInput struct {
+	Grantee   string   `json:"grantee"`
+	Fields    []string `json:"fields"`
+	Purpose   string   `json:"purpose"`
+	ExpiresAt string   `json:"expiresAt"`
+	MaxReads  int      `json:"maxReads"`
+	Sponsor   string   `json:"sponsor"` // required when the grantee is a delegate
+}
+
+// active reports whether the grant still allows a read at now
+func (g *AccessGrant) active(now string) bool {
+	return g.RevokedAt == "" && g.ExpiresAt > now && (g.MaxReads == 0 || g.Reads < g.MaxReads)
+}
+
+// GrantAccess grants an organisation scoped, expiring access to a record.
+// Only the organisation that submitted the record can grant access to it.
+func (s *SmartContract) GrantAccess(ctx contractapi.TransactionContextInterface, kycID string, grantData string) (*AccessGrant, error) {
+	var input grantInput
+	err := json.Unmarshal([]byte(grantData), &input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal grant: %v", err)
+	}
+	if strings.TrimSpace(input.Grantee) == "" {
+		return nil, fmt.Errorf("grantee MSP ID is required")
+	}
+	if !purposeCodePattern.MatchString(input.Purpose) {
+		return nil, fmt.Errorf("invalid purpose code %q", input.Purpose)
+	}
+	if len(input.Fields) == 0 {
+		return nil, fmt.Errorf("grant must cover at least one field")
+	}
+	fields := recordFieldNames()
+	for _, field := range input.Fields {
+		if !fields[field] {
+			return nil, fmt.Errorf("unknown record field %q", field)
+		}
+	}
+	if input.MaxReads < 0 {
+		return nil, fmt.Errorf("maxReads cannot be negative")
+	}
+	delegate, err := isDelegate(ctx, input.Grantee)
+	if err != nil {
+		return nil, err
+	}
+	if delegate {
+		sponsored, err := sponsorshipActive(ctx, input.Sponsor, input.Grantee)
+		if err != nil {
+			return nil, err
+		}
+		if !sponsored {
+			return nil, fmt.Errorf("%s is a delegate; name a bank actively sponsoring it", input.Grantee)
+		}
+	} else if input.Sponsor != "" {
+		return nil, fmt.Errorf("%s is not a delegate and cannot be granted access through a sponsor", input.Grantee)
+	}
+	expiresAt, err := time.Parse(time.RFC3339, input.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("expiresAt must be an RFC 3339 timestamp")
+	}
+	if !expiresAt.After(s.txTime(ctx)) {
+		return nil, fmt.Errorf("expiresAt must be in the future")
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return nil, err
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	if kyc.OwnerMSP != "" && kyc.OwnerMSP != mspID {
+		return nil, fmt.Errorf("KYC record %s is owned by %s", kycID, kyc.OwnerMSP)
+	}
+	grantedBy, err := clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	grant := &AccessGrant{
+		GrantID:   ctx.GetStub().GetTxID(),
+		KYCID:     kycID,
+		Grantee:   input.Grantee,
+		Fields:    sortedUnique(input.Fields),
+		Purpose:   input.Purpose,
+		ExpiresAt: expiresAt.UTC().Format(time.RFC3339),
+		MaxReads:  input.MaxReads,
+		Sponsor:   input.Sponsor,
+		GrantedBy: grantedBy,
+		GrantedAt: s.txTime(ctx).Format(time.RFC3339),
+		TxID:      ctx.GetStub().GetTxID(),
+	}
+	err = putGrant(ctx, grant)
+	if err != nil {
+		return nil, err
+	}
+	if grant.Sponsor != "" {
+		err = putIndexEntry(ctx, sponsoredGrantIndex, kycID, grant.Sponsor, grant.Grantee)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-ACCESS_GRANTED-%d", kycID, s.txTime(ctx).Unix()),
+		KYCID:       kycID,
+		Action:      "ACCESS_GRANTED",
+		PerformedBy: grantedBy,
+		PerformedAt: grant.GrantedAt,
+		TxID:        grant.TxID,
+		Details: map[string]interface{}{
+			"grantId":   grant.GrantID,
+			"grantee":   grant.Grantee,
+			"fields":    grant.Fields,
+			"purpose":   grant.Purpose,
+			"expiresAt": grant.ExpiresAt,
+			"maxReads":  grant.MaxReads,
+			"sponsor":   grant.Sponsor,
+		},
+	}
+
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return grant, nil
+}
+
+// ListActiveGrants returns the grants of a record that still allow reads
+func (s *SmartContract) ListActiveGrants(ctx contractapi.TransactionContextInterface, kycID string) ([]*AccessGrant, error) {
+	grants, err := recordGrants(ctx, kycID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.txTime(ctx).Format(time.RFC3339)
+	active := []*AccessGrant{}
+	for _, grant := range grants {
+		usable, err := grantUsable(ctx, grant, now)
+		if err != nil {
+			return nil, err
+		}
+		if usable {
+			active = append(active, grant)
+		}
+	}
+	return active, nil
+}
+
+// issueConsentGrants issues the grants enforcing a consent receipt, one for
+// each recipient and purpose, covering the fields of its data categories
+func issueConsentGrants(ctx contractapi.TransactionContextInterface, receipt *ConsentReceipt) error {
+	fields := []string{}
+	for _, category := range receipt.DataCategories {
+		fields = append(fields, consentDataCategories[category]...)
+	}
+	fields = sortedUnique(fields)
+
+	n := 0
+	for _, recipient := range receipt.Recipients {
+		for _, purpose := range receipt.Purposes {
+			n++
+			err := putGrant(ctx, &AccessGrant{
+				GrantID:          fmt.Sprintf("%s.%d", receipt.ReceiptID, n),
+				KYCID:            receipt.KYCID,
+				Grantee:          recipient,
+				Fields:           fields,
+				Purpose:          purpose,
+				ExpiresAt:        receipt.ExpiresAt,
+				ConsentReceiptID: receipt.ReceiptID,
+				GrantedBy:        receipt.RecordedBy,
+				GrantedAt:        receipt.ConsentTimestamp,
+				TxID:             receipt.TxID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// activeGrant returns an active grant letting mspID read a record for
+// purposeCode, or nil when there is none
+func activeGrant(ctx contractapi.TransactionContextInterface, kycID string, mspID string, purposeCode string) (*AccessGrant, error) {
+	grants, err := recordGrants(ctx, kycID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := txTime(ctx).Format(time.RFC3339)
+	for _, grant := range grants {
+		if grant.Grantee != mspID || grant.Purpose != purposeCode {
+			continue
+		}
+		usable, err := grantUsable(ctx, grant, now)
+		if err != nil {
+			return nil, err
+		}
+		if usable {
+			return grant, nil
+		}
+	}
+	return nil, nil
+}
+
+// grantUsable reports whether a grant allows a read at now: it must be
+// active and, when its grantee is a delegate, mediated by a sponsorship
+// that is still active
+func grantUsable(ctx contractapi.TransactionContextInterface, grant *AccessGrant, now string) (bool, error) {
+	if !grant.active(now) {
+		return false, nil
+	}
+	if grant.Sponsor != "" {
+		return sponsorshipActive(ctx, grant.Sponsor, grant.Grantee)
+	}
+	delegate, err := isDelegate(ctx, grant.Grantee)
+	if err != nil {
+		return false, err
+	}
+	return !delegate, nil
+}
+
+// recordGrants returns every grant of a record, in grant ID order
+func recordGrants(ctx contractapi.TransactionContextInterface, kycID string) ([]*AccessGrant, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(grantObjectType, []string{kycID})
+	if err != nil {
+		return nil, err
+	}
+
+	grants := []*AccessGrant{}
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		var grant AccessGrant
+		err := json.Unmarshal(queryResponse.Value, &grant)
+		if err != nil {
+			return err
+		}
+		grants = append(grants, &grant)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+// putGrant stores a grant under its key
+func putGrant(ctx contractapi.TransactionContextInterface, grant *AccessGrant) error {
+	key, err := ctx.GetStub().CreateCompositeKey(grantObjectType, []string{grant.KYCID, grant.GrantID})
+	if err != nil {
+		return err
+	}
+	grantJSON, err := json.Marshal(grant)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, grantJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put access grant: %v", err)
+	}
+	return nil
+}
+
+// scopeRecord returns a copy of a record holding only grantBaseFields and
+// the given fields
+func scopeRecord(kyc *KYCRecord, fields []string) (*KYCRecord, error) {
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]json.RawMessage
+	err = json.Unmarshal(kycJSON, &values)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := map[string]json.RawMessage{}
+	for _, field := range append(append([]string{}, grantBaseFields...), fields...) {
+		if value, ok := values[field]; ok {
+			scoped[field] = value
+		}
+	}
+	scopedJSON, err := json.Marshal(scoped)
+	if err != nil {
+		return nil, err
+	}
+	var out KYCRecord
+	err = json.Unmarshal(scopedJSON, &out)
+	if err != nil {
+		return nil, err
+	}
+	if out.DocumentHashes == nil {
+		// the field is required, so a grant without it reads an empty list
+		out.DocumentHashes = []DocumentHash{}
+	}
+	// an encrypted field in scope is read as its envelope
+	for _, field := range fields {
+		if envelope := kyc.EncryptedFields[field]; envelope != nil {
+			if out.EncryptedFields == nil {
+				out.EncryptedFields = map[string]*FieldEnvelope{}
+			}
+			out.EncryptedFields[field] = envelope
+		}
+	}
+	return &out, nil
+}
+
+// recordFieldNames returns the JSON names of the KYCRecord fields
+func recordFieldNames() map[string]bool {
+	names := map[string]bool{}
+	recordType := reflect.TypeOf(KYCRecord{})
+	for i := 0; i < recordType.NumField(); i++ {
+		name := strings.Split(recordType.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// Hash-chained history. Each entry a record's history gains carries the hash
+// of the entry before it, the first the genesis hash, and the record's
+// history head under "HISTHEAD~<kyc ID>" holds the hash and count of the
+// chain so far. An entry deleted, inserted or altered out of band breaks the
+// chain, which VerifyHistoryChain detects. Entries written before history was
+// chained carry no previous hash and are reported as unchained.
+const historyHeadObjectType = "HISTHEAD"
+
+// genesisHash is the previous hash of the first entry in a chain
+var genesisHash = strings.Repeat("0", sha256.Size*2)
+
+// historyHead is the end of a record's history chain
+type historyHead struct {
+	KYCID   string `json:"kycId"`
+	Hash    string `json:"hash"`
+	Entries int    `json:"entries"`
+}
+
+// HistoryChainVerification reports whether a record's history chain is intact
+type HistoryChainVerification struct {
+	KYCID     string   `json:"kycId"`
+	Valid     bool     `json:"valid"`
+	Entries   int      `json:"entries"`   // entries verified on the chain
+	Unchained int      `json:"unchained"` // entries written before history was chained
+	Problems  []string `json:"problems,omitempty" metadata:",optional"`
+}
+
+// HistoryPage holds one page of history entries
+type HistoryPage struct {
+	Entries             []*HistoryEntry `json:"entries"`
+	FetchedRecordsCount int32           `json:"fetchedRecordsCount"`
+	Bookmark            string          `json:"bookmark"`
+}
+
+// SearchHistory returns one page of the history entries of every record
+// matching all the given filters, each of which may be empty: the record,
+// any of actions, the identity that performed the entry, and performedAt
+// between from and to inclusive. Bounds are dates (YYYY-MM-DD) or RFC 3339
+// timestamps. Pages come from a rich query, so like the other paginated
+// queries they are not cut short by maxResponseBytes. Administrators and
+// regulators can search every record's history; the organisation that owns
+// a record, and those it has granted access to it, can search its own.
+func (s *SmartContract) SearchHistory(ctx contractapi.TransactionContextInterface, kycID string, actions []string, performedBy string, from string, to string, pageSize int32, bookmark string) (*HistoryPage, error) {
+	err := s.requireHistoryReader(ctx, kycID)
+	if err != nil {
+		return nil, err
+	}
+
+	// other documents such as screening runs also carry a kycId, so every
+	// selector matches on action
+	selector := map[string]interface{}{"action": map[string]interface{}{"$exists": true}}
+	if len(actions) > 0 {
+		normalized := make([]string, len(actions))
+		for i, action := range actions {
+			normalized[i] = strings.ToUpper(action)
+		}
+		selector["action"] = map[string]interface{}{"$in": normalized}
+	}
+	if kycID != "" {
+		selector["kycId"] = kycID
+	}
+	if performedBy != "" {
+		selector["performedBy"] = performedBy
+	}
+
+	performedAt := map[string]interface{}{}
+	if from != "" {
+		start, err := parseHistoryBound(from)
+		if err != nil {
+			return nil, err
+		}
+		performedAt["$gte"] = start.Format(time.RFC3339)
+	}
+	if to != "" {
+		end, err := parseHistoryBound(to)
+		if err != nil {
+			return nil, err
+		}
+		if len(to) == len("2006-01-02") {
+			// a date bound takes in the whole day
+			performedAt["$lt"] = end.AddDate(0, 0, 1).Format(time.RFC3339)
+		} else {
+			performedAt["$lte"] = end.Format(time.RFC3339)
+		}
+	}
+	if len(performedAt) > 0 {
+		selector["performedAt"] = performedAt
+	}
+
+	queryJSON, err := json.Marshal(map[string]interface{}{"selector": selector})
+	if err != nil {
+		return nil, err
+	}
+	err = checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	resultsIterator, responseMetadata, err := ctx.GetStub().GetQueryResultWithPagination(string(queryJSON), pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []*HistoryEntry{}
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		var entry HistoryEntry
+		err := json.Unmarshal(queryResponse.Value, &entry)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, &entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &HistoryPage{
+		Entries:             entries,
+		FetchedRecordsCount: responseMetadata.FetchedRecordsCount,
+		Bookmark:            responseMetadata.Bookmark,
+	}, nil
+}
+
+// requireHistoryReader fails unless the caller may search the history of
+// the record kycID, or of every record when it is empty
+func (s *SmartContract) requireHistoryReader(ctx contractapi.TransactionContextInterface, kycID string) error {
+	for _, attr := range []string{AttrAdmin, AttrRegulator} {
+		held, err := hasAttribute(ctx, attr)
+		if err != nil {
+			return err
+		}
+		if held {
+			return requireCurrentCertificate(ctx)
+		}
+	}
+	if kycID == "" {
+		return fmt.Errorf("caller is not authorized: searching every record's history needs the %s or %s attribute", AttrAdmin, AttrRegulator)
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	if kyc.OwnerMSP == "" || kyc.OwnerMSP == mspID {
+		return nil
+	}
+	grants, err := recordGrants(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	now := s.txTime(ctx).Format(time.RFC3339)
+	for _, grant := range grants {
+		if grant.Grantee != mspID {
+			continue
+		}
+		usable, err := grantUsable(ctx, grant, now)
+		if err != nil {
+			return err
+		}
+		if usable {
+			return nil
+		}
+	}
+	return fmt.Errorf("KYC record %s is owned by %s; no active grant lets %s search its history", kycID, kyc.OwnerMSP, mspID)
+}
+
+// parseHistoryBound parses a date (YYYY-MM-DD) or RFC 3339 timestamp into UTC,
+// the zone history timestamps are written in
+func parseHistoryBound(bound string) (time.Time, error) {
+	parsed, err := time.Parse("2006-01-02", bound)
+	if err != nil {
+		parsed, err = time.Parse(time.RFC3339, bound)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: expected YYYY-MM-DD or RFC 3339", bound)
+	}
+	return parsed.UTC(), nil
+}
+
+// VerifyHistoryChain walks a record's history chain from the genesis hash to
+// its head, reporting any entry that is not on the chain, any break in it,
+// and a chain that does not end at the recorded head
+func (s *SmartContract) VerifyHistoryChain(ctx contractapi.TransactionContextInterface, kycID string) (*HistoryChainVerification, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(historyObjectType, []string{kycID})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &HistoryChainVerification{KYCID: kycID}
+	next := map[string][]byte{} // entry JSON by the hash it follows
+	chained := 0
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		var entry HistoryEntry
+		err := json.Unmarshal(queryResponse.Value, &entry)
+		if err != nil {
+			return err
+		}
+		if entry.PrevEntryHash == "" {
+			result.Unchained++
+			return nil
+		}
+		chained++
+		if next[entry.PrevEntryHash] != nil {
+			result.Problems = append(result.Problems, fmt.Sprintf("more than one entry follows %s; entry %s was inserted or altered", entry.PrevEntryHash, entry.ID))
+			return nil
+		}
+		next[entry.PrevEntryHash] = queryResponse.Value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hash := genesisHash
+	for next[hash] != nil {
+		hash, err = historyEntryHash(next[hash])
+		if err != nil {
+			return nil, err
+		}
+		result.Entries++
+	}
+	if result.Entries < chained {
+		result.Problems = append(result.Problems, fmt.Sprintf("%d entries are not on the chain; the chain breaks after %s, where an entry was deleted or altered", chained-result.Entries, hash))
+	}
+
+	head, err := readHistoryHead(ctx, kycID)
+	if err != nil {
+		return nil, err
+	}
+	if head.Hash != hash || head.Entries != result.Entries {
+		result.Problems = append(result.Problems, fmt.Sprintf("chain ends at %s after %d entries but its head records %s after %d", hash, result.Entries, head.Hash, head.Entries))
+	}
+	result.Valid = len(result.Problems) == 0
+	return result, nil
+}
+
+// historyEntryHash hashes a history entry's canonical JSON: the entry decoded
+// and encoded again with numbers kept as written, so every key is sorted
+func historyEntryHash(entryJSON []byte) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(entryJSON))
+	decoder.UseNumber()
+	var entry interface{}
+	err := decoder.Decode(&entry)
+	if err != nil {
+		return "", err
+	}
+	canonical, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(canonical)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// readHistoryHead returns the head of a record's history chain, as this
+// transaction last wrote it if it has, and the genesis head if the record has
+// no chained history yet
+func readHistoryHead(ctx contractapi.TransactionContextInterface, kycID string) (*historyHead, error) {
+	if tx, ok := ctx.(*txContext); ok && tx.historyHeads[kycID] != nil {
+		return tx.historyHeads[kycID], nil
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(historyHeadObjectType, []string{kycID})
+	if err != nil {
+		return nil, err
+	}
+	headJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if headJSON == nil {
+		return &historyHead{KYCID: kycID, Hash: genesisHash}, nil
+	}
+	var head historyHead
+	err = json.Unmarshal(headJSON, &head)
+	if err != nil {
+		return nil, err
+	}
+	return &head, nil
+}
+
+func writeHistoryHead(ctx contractapi.TransactionContextInterface, kycID string, head *historyHead) error {
+	key, err := ctx.GetStub().CreateCompositeKey(historyHeadObjectType, []string{kycID})
+	if err != nil {
+		return err
+	}
+	headJSON, err := json.Marshal(head)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, headJSON)
+	if err != nil {
+		return fmt.Errorf("failed to write history head: %v", err)
+	}
+	if tx, ok := ctx.(*txContext); ok {
+		if tx.historyHeads == nil {
+			tx.historyHeads = map[string]*historyHead{}
+		}
+		tx.historyHeads[kycID] = head
+	}
+	return nil
+}
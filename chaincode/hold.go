@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// holdReasonList is the reference list of reasons a record can be put on
+// hold for, keyed by reason code, with the reason's description as each
+// entry's reason. Administrators manage it with UpsertListEntries as an
+// ALLOWLIST.
+const holdReasonList = "HOLD_REASONS"
+
+// RecordHold is why a record is ON_HOLD. While it is held its SLA timer is
+// stopped: the record's slaDueAt moves onto the hold, and on release the
+// record is given back the time it had left when it was held.
+type RecordHold struct {
+	ReasonCode string `json:"reasonCode"`
+	Reason     string `json:"reason,omitempty" metadata:",optional"` // the reason's description when the hold was placed
+	Remarks    string `json:"remarks,omitempty" metadata:",optional"`
+	PlacedBy   string `json:"placedBy"`
+	PlacedAt   string `json:"placedAt"`
+	SLADueAt   string `json:"slaDueAt,omitempty" metadata:",optional"` // the record's SLA due date when it was held
+}
+
+// PutOnHold pauses the verification of a PENDING record while it waits on
+// external input, such as a callback from the customer. reasonCode must be
+// on the HOLD_REASONS list. Decisions cannot be made on a held record until
+// ReleaseHold is called.
+func (s *SmartContract) PutOnHold(ctx contractapi.TransactionContextInterface, id string, reasonCode string, remarks string) error {
+	err := requireAllowedMSP(ctx, AllowlistVerifier)
+	if err != nil {
+		return err
+	}
+	err = requireAttribute(ctx, AttrVerifier)
+	if err != nil {
+		return err
+	}
+	kyc, err := s.readKYC(ctx, id)
+	if err != nil {
+		return err
+	}
+	if kyc.Status != "PENDING" {
+		return fmt.Errorf("KYC record %s is %s; only PENDING records can be put on hold", id, kyc.Status)
+	}
+	reasonCode = strings.ToUpper(strings.TrimSpace(reasonCode))
+	reason, err := getListEntry(ctx, holdReasonList, reasonCode)
+	if err != nil {
+		return err
+	}
+	if reason == nil {
+		return fmt.Errorf("hold reason %q is not on the %s list", reasonCode, holdReasonList)
+	}
+	placedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	before := *kyc
+	now := s.txTime(ctx)
+	hold := &RecordHold{
+		ReasonCode: reasonCode,
+		Reason:     reason.Reason,
+		Remarks:    remarks,
+		PlacedBy:   placedBy,
+		PlacedAt:   now.Format(time.RFC3339),
+		SLADueAt:   kyc.SLADueAt,
+	}
+	kyc.Status = "ON_HOLD"
+	kyc.Hold = hold
+	kyc.SLADueAt = ""
+	kyc.UpdatedAt = hold.PlacedAt
+
+	details := map[string]interface{}{
+		"reasonCode": reasonCode,
+		"slaDueAt":   before.SLADueAt,
+	}
+	return s.saveHoldChange(ctx, &before, kyc, "PUT_ON_HOLD", placedBy, remarks, details)
+}
+
+// ReleaseHold returns a held record to PENDING and restarts its SLA timer
+// with the time that was left on it when the hold was placed
+func (s *SmartContract) ReleaseHold(ctx contractapi.TransactionContextInterface, id string, remarks string) error {
+	err := requireAllowedMSP(ctx, AllowlistVerifier)
+	if err != nil {
+		return err
+	}
+	err = requireAttribute(ctx, AttrVerifier)
+	if err != nil {
+		return err
+	}
+	kyc, err := s.readKYC(ctx, id)
+	if err != nil {
+		return err
+	}
+	if kyc.Status != "ON_HOLD" || kyc.Hold == nil {
+		return fmt.Errorf("KYC record %s is not on hold", id)
+	}
+	releasedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	before := *kyc
+	now := s.txTime(ctx)
+	hold := kyc.Hold
+	kyc.Status = "PENDING"
+	kyc.Hold = nil
+	kyc.UpdatedAt = now.Format(time.RFC3339)
+	placedAt, placedErr := time.Parse(time.RFC3339, hold.PlacedAt)
+	due, dueErr := time.Parse(time.RFC3339, hold.SLADueAt)
+	if placedErr == nil && dueErr == nil {
+		// a record already overdue when held is still overdue by as much
+		kyc.SLADueAt = now.Add(due.Sub(placedAt)).Format(time.RFC3339)
+	}
+
+	details := map[string]interface{}{
+		"reasonCode": hold.ReasonCode,
+		"placedAt":   hold.PlacedAt,
+		"slaDueAt":   kyc.SLADueAt,
+	}
+	if placedErr == nil {
+		details["heldSeconds"] = int64(now.Sub(placedAt) / time.Second)
+	}
+	return s.saveHoldChange(ctx, &before, kyc, "HOLD_RELEASED", releasedBy, remarks, details)
+}
+
+// saveHoldChange stores a record after a hold is placed or released, moves
+// it between counters and writes its history entry
+func (s *SmartContract) saveHoldChange(ctx contractapi.TransactionContextInterface, before *KYCRecord, kyc *KYCRecord, action string, performedBy string, remarks string, details map[string]interface{}) error {
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return err
+	}
+	err = putRecordState(ctx, kyc.ID, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update KYC record: %v", err)
+	}
+	err = updateRecordCounters(ctx, before, kyc)
+	if err != nil {
+		return fmt.Errorf("failed to update counters: %v", err)
+	}
+
+	err = s.createHistoryEntry(ctx, HistoryEntry{
+		ID:          fmt.Sprintf("%s-%s-%d", kyc.ID, action, s.txTime(ctx).Unix()),
+		KYCID:       kyc.ID,
+		Action:      action,
+		PerformedBy: performedBy,
+		PerformedAt: kyc.UpdatedAt,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details:     details,
+		Remarks:     remarks,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return nil
+}
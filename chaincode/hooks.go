@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Validation hooks. An organisation's own checks, such as the format of its
+// internal customer IDs, are written as a ValidationHook in a file of their
+// own that registers the hook from init, and are compiled into the
+// chaincode. Compiled-in hooks do nothing until an administrator lists them
+// in the hook configuration with SetValidationHooks, which sets the order
+// they run in, the operations they run on and their parameters. Each hook's
+// result is recorded in the history entry of the change it checked.
+//
+// Hooks run during endorsement, so like the rest of the contract they must
+// be deterministic: no clocks but txTime, no randomness and no calls off the
+// peer. Every peer endorsing for a deployment needs the same hooks compiled
+// in.
+
+const validationHooksKey = "VALIDATIONHOOKS"
+
+// Operations a validation hook can run on
+const (
+	HookOperationCreate       = "CREATE"
+	HookOperationStatusUpdate = "STATUS_UPDATE"
+	HookOperationPatch        = "PATCH"
+)
+
+// ValidationHook is an organisation-specific check on KYC records
+type ValidationHook interface {
+	// CheckParams rejects parameters the hook cannot run with, when a hook
+	// configuration naming the hook is set
+	CheckParams(params map[string]string) error
+	// Validate returns an error describing why the record is not acceptable.
+	// The record is the one about to be written, and must not be modified.
+	Validate(ctx contractapi.TransactionContextInterface, kyc *KYCRecord, params map[string]string) error
+}
+
+// validationHooks are the hooks compiled into the chaincode, by name
+var validationHooks = map[string]ValidationHook{}
+
+// registerValidationHook compiles a hook in under name. Call it from init.
+func registerValidationHook(name string, hook ValidationHook) {
+	if _, ok := validationHooks[name]; ok {
+		panic(fmt.Sprintf("validation hook %s is registered twice", name))
+	}
+	validationHooks[name] = hook
+}
+
+// ValidationHookConfig lists the hooks a deployment runs, in order
+type ValidationHookConfig struct {
+	Version   int                     `json:"version"`
+	Hooks     []ValidationHookSetting `json:"hooks"`
+	UpdatedAt string                  `json:"updatedAt,omitempty" metadata:",optional"`
+	UpdatedBy string                  `json:"updatedBy,omitempty" metadata:",optional"`
+}
+
+// ValidationHookSetting enables one compiled-in hook
+type ValidationHookSetting struct {
+	Name       string            `json:"name"`
+	Operations []string          `json:"operations,omitempty" metadata:",optional"` // CREATE, STATUS_UPDATE and PATCH; empty runs on all
+	Params     map[string]string `json:"params,omitempty" metadata:",optional"`
+	Advisory   bool              `json:"advisory,omitempty" metadata:",optional"` // failures are recorded but do not reject the change
+}
+
+// ValidationHookResult is the outcome of one hook on one change
+type ValidationHookResult struct {
+	Name     string `json:"name"`
+	Passed   bool   `json:"passed"`
+	Advisory bool   `json:"advisory,omitempty" metadata:",optional"`
+	Message  string `json:"message,omitempty" metadata:",optional"`
+}
+
+// SetValidationHooks replaces the hook configuration. Listing no hooks turns
+// them all off. Only administrators can configure hooks.
+func (s *SmartContract) SetValidationHooks(ctx contractapi.TransactionContextInterface, hooksJSON string) (*ValidationHookConfig, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var config ValidationHookConfig
+	err = json.Unmarshal([]byte(hooksJSON), &config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal validation hooks: %v", err)
+	}
+	if config.Hooks == nil {
+		config.Hooks = []ValidationHookSetting{}
+	}
+	err = validateHookConfig(&config)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := loadHookConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	config.Version = current.Version + 1
+	config.UpdatedBy, err = clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+	config.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	return &config, ctx.GetStub().PutState(validationHooksKey, configJSON)
+}
+
+// GetValidationHooks returns the hook configuration in force
+func (s *SmartContract) GetValidationHooks(ctx contractapi.TransactionContextInterface) (*ValidationHookConfig, error) {
+	return loadHookConfig(ctx)
+}
+
+// GetAvailableValidationHooks returns the names of the hooks compiled into
+// this chaincode, which SetValidationHooks can enable
+func (s *SmartContract) GetAvailableValidationHooks(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	names := make([]string, 0, len(validationHooks))
+	for name := range validationHooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// loadHookConfig returns the hook configuration, which lists no hooks until
+// SetValidationHooks is first called
+func loadHookConfig(ctx contractapi.TransactionContextInterface) (*ValidationHookConfig, error) {
+	configJSON, err := ctx.GetStub().GetState(validationHooksKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read validation hooks: %v", err)
+	}
+	if configJSON == nil {
+		return &ValidationHookConfig{Hooks: []ValidationHookSetting{}}, nil
+	}
+
+	var config ValidationHookConfig
+	err = json.Unmarshal(configJSON, &config)
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func validateHookConfig(config *ValidationHookConfig) error {
+	seen := map[string]bool{}
+	for _, setting := range config.Hooks {
+		hook, ok := validationHooks[setting.Name]
+		if !ok {
+			return fmt.Errorf("validation hook %q is not compiled into this chaincode", setting.Name)
+		}
+		if seen[setting.Name] {
+			return fmt.Errorf("validation hook %s is listed more than once", setting.Name)
+		}
+		seen[setting.Name] = true
+		for _, operation := range setting.Operations {
+			if operation != HookOperationCreate && operation != HookOperationStatusUpdate && operation != HookOperationPatch {
+				return fmt.Errorf("validation hook %s lists unknown operation %q", setting.Name, operation)
+			}
+		}
+		err := hook.CheckParams(setting.Params)
+		if err != nil {
+			return fmt.Errorf("invalid parameters for validation hook %s: %v", setting.Name, err)
+		}
+	}
+	return nil
+}
+
+// runsOn reports whether a hook setting applies to operation
+func (setting ValidationHookSetting) runsOn(operation string) bool {
+	if len(setting.Operations) == 0 {
+		return true
+	}
+	for _, op := range setting.Operations {
+		if op == operation {
+			return true
+		}
+	}
+	return false
+}
+
+// runValidationHooks runs the configured hooks for operation on a record,
+// failing if any hook that is not advisory rejects it, and otherwise returns
+// each hook's result
+func runValidationHooks(ctx contractapi.TransactionContextInterface, kyc *KYCRecord, operation string) ([]ValidationHookResult, error) {
+	results, err := evaluateValidationHooks(ctx, kyc, operation)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		if !result.Passed && !result.Advisory {
+			return nil, fmt.Errorf("validation hook %s rejected KYC record %s: %s", result.Name, kyc.ID, result.Message)
+		}
+	}
+	return results, nil
+}
+
+// evaluateValidationHooks runs every configured hook for operation on a
+// record in order and returns their results
+func evaluateValidationHooks(ctx contractapi.TransactionContextInterface, kyc *KYCRecord, operation string) ([]ValidationHookResult, error) {
+	config, err := loadHookConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []ValidationHookResult{}
+	for _, setting := range config.Hooks {
+		if !setting.runsOn(operation) {
+			continue
+		}
+		hook, ok := validationHooks[setting.Name]
+		if !ok {
+			return nil, fmt.Errorf("validation hook %s is configured but not compiled into this chaincode", setting.Name)
+		}
+		result := ValidationHookResult{Name: setting.Name, Passed: true, Advisory: setting.Advisory}
+		err = hook.Validate(ctx, kyc, setting.Params)
+		if err != nil {
+			result.Passed = false
+			result.Message = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// userIDFormatHook checks a record's userId, the submitting organisation's
+// own customer ID, against a regular expression given as the "pattern"
+// parameter. The pattern must match the whole ID.
+type userIDFormatHook struct{}
+
+func init() {
+	registerValidationHook("USER_ID_FORMAT", userIDFormatHook{})
+}
+
+func (userIDFormatHook) CheckParams(params map[string]string) error {
+	_, err := userIDPattern(params)
+	return err
+}
+
+func (userIDFormatHook) Validate(ctx contractapi.TransactionContextInterface, kyc *KYCRecord, params map[string]string) error {
+	pattern, err := userIDPattern(params)
+	if err != nil {
+		return err
+	}
+	if !pattern.MatchString(kyc.UserID) {
+		return fmt.Errorf("user ID %q does not match %s", kyc.UserID, params["pattern"])
+	}
+	return nil
+}
+
+// userIDPattern compiles the hook's pattern, anchored at both ends
+func userIDPattern(params map[string]string) (*regexp.Regexp, error) {
+	if params["pattern"] == "" {
+		return nil, fmt.Errorf("a pattern parameter is required")
+	}
+	pattern, err := regexp.Compile(`^(?:` + params["pattern"] + `)$`)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %v", err)
+	}
+	return pattern, nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// PaginatedQueryResult holds one page of KYC records and the bookmark for the next page
+type PaginatedQueryResult struct {
+	Records             []*KYCRecord `json:"records"`
+	FetchedRecordsCount int32        `json:"fetchedRecordsCount"`
+	Bookmark            string       `json:"bookmark"`
+	Truncated           bool         `json:"truncated"` // the page was cut short by maxResponseBytes
+}
+
+// putIndexEntry writes a composite-key index entry pointing at kycID. The key is
+// made of the index attributes followed by the record ID; the value is a marker.
+func putIndexEntry(ctx contractapi.TransactionContextInterface, indexName string, kycID string, attributes ...string) error {
+	indexKey, err := ctx.GetStub().CreateCompositeKey(indexName, append(attributes, kycID))
+	if err != nil {
+		return fmt.Errorf("failed to create %s index key: %v", indexName, err)
+	}
+	return ctx.GetStub().PutState(indexKey, []byte{0x00})
+}
+
+// deleteIndexEntry removes a composite-key index entry written by putIndexEntry
+func deleteIndexEntry(ctx contractapi.TransactionContextInterface, indexName string, kycID string, attributes ...string) error {
+	indexKey, err := ctx.GetStub().CreateCompositeKey(indexName, append(attributes, kycID))
+	if err != nil {
+		return fmt.Errorf("failed to create %s index key: %v", indexName, err)
+	}
+	return ctx.GetStub().DelState(indexKey)
+}
+
+// putRecordIndexes writes every index entry for a record, the reverse of
+// deleteRecordIndexes
+func putRecordIndexes(ctx contractapi.TransactionContextInterface, kyc *KYCRecord) error {
+	for _, tag := range kyc.Tags {
+		err := putIndexEntry(ctx, tagIndex, kyc.ID, tag)
+		if err != nil {
+			return err
+		}
+	}
+	if kyc.Phone != "" {
+		err := putIndexEntry(ctx, phoneIndex, kyc.ID, phoneHash(kyc.Phone))
+		if err != nil {
+			return err
+		}
+	}
+	if kyc.FaceHash != "" {
+		err := putIndexEntry(ctx, faceHashIndex, kyc.ID, kyc.FaceHash)
+		if err != nil {
+			return err
+		}
+	}
+	if kyc.Screening != nil {
+		err := ctx.GetStub().PutState(matchScoreKey(kyc.Screening.Score, kyc.ID), []byte{0x00})
+		if err != nil {
+			return err
+		}
+	}
+	if awaitingDocumentReview(kyc) {
+		err := putIndexEntry(ctx, documentReviewIndex, kyc.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return putAddressIndexes(ctx, kyc)
+}
+
+// deleteRecordIndexes removes every index entry pointing at a record
+func deleteRecordIndexes(ctx contractapi.TransactionContextInterface, kyc *KYCRecord) error {
+	for _, tag := range kyc.Tags {
+		err := deleteIndexEntry(ctx, tagIndex, kyc.ID, tag)
+		if err != nil {
+			return err
+		}
+	}
+	if kyc.Phone != "" {
+		err := deleteIndexEntry(ctx, phoneIndex, kyc.ID, phoneHash(kyc.Phone))
+		if err != nil {
+			return err
+		}
+	}
+	if kyc.FaceHash != "" {
+		err := deleteIndexEntry(ctx, faceHashIndex, kyc.ID, kyc.FaceHash)
+		if err != nil {
+			return err
+		}
+	}
+	if awaitingDocumentReview(kyc) {
+		err := deleteIndexEntry(ctx, documentReviewIndex, kyc.ID)
+		if err != nil {
+			return err
+		}
+	}
+	err := deleteMatchScoreIndex(ctx, kyc)
+	if err != nil {
+		return err
+	}
+	return deleteAddressIndexes(ctx, kyc)
+}
+
+// getIndexedIDs returns the IDs of every record indexed under the given attributes
+func getIndexedIDs(ctx contractapi.TransactionContextInterface, indexName string, attributes ...string) ([]string, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(indexName, attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	var kycIDs []string
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		_, keyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return err
+		}
+		kycIDs = append(kycIDs, keyParts[len(keyParts)-1])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return kycIDs, nil
+}
+
+// getRecordsByIndex pages through an index by partial key and loads the
+// referenced records. The rate limit's bucket and the purpose's access logs
+// are writes, which rule out paginated queries.
+func (s *SmartContract) getRecordsByIndex(ctx contractapi.TransactionContextInterface, purpose *readPurpose, indexName string, attributes []string, pageSize int32, bookmark string) (*PaginatedQueryResult, error) {
+	err := checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	budget, err := newResponseBudget(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PaginatedQueryResult{Records: []*KYCRecord{}}
+	next, done, err := scanPartialCompositeKeys(ctx, indexName, attributes, bookmark, int(pageSize), func(key string, value []byte) (bool, error) {
+		_, keyParts, err := ctx.GetStub().SplitCompositeKey(key)
+		if err != nil {
+			return false, err
+		}
+
+		kyc, err := s.readKYC(ctx, keyParts[len(keyParts)-1])
+		if err != nil {
+			return false, err
+		}
+		visible, grant, err := purpose.permits(ctx, kyc)
+		if err != nil {
+			return false, err
+		}
+		if visible == nil {
+			return true, nil
+		}
+		fits, err := budget.fits(visible)
+		if err != nil {
+			return false, err
+		}
+		if !fits {
+			result.Truncated = true
+			return false, nil
+		}
+		err = purpose.record(ctx, kyc, grant)
+		if err != nil {
+			return false, err
+		}
+		result.Records = append(result.Records, visible)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !done {
+		result.Bookmark = next
+	}
+
+	result.FetchedRecordsCount = int32(len(result.Records))
+	return result, nil
+}
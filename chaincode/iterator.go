@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// Query cost limits. Every paginated query checks its page size against
+// the configured maxPageSize with checkPageSize, and every query that reads
+// an iterator through forEachResult fails once it has seen more than the
+// configured maxIteratorResults, so no one call can make a peer walk an
+// unbounded result set. Larger result sets must be fetched a page at a time.
+//
+// Fabric refuses writes in a transaction that has run a paginated query, and
+// paginated queries in one that has written, so a function that writes as it
+// goes, or logs the reads it makes, pages with scanCompositeKeys or
+// scanSimpleKeys instead: a plain iterator read up to a count, resuming
+// after the key the previous page stopped at. Composite key ranges cannot
+// start at an arbitrary key, so a page of composite keys also reads past the
+// keys before it; every key read counts toward maxIteratorResults, and a run
+// over more documents than that needs it raised.
+
+// checkPageSize fails unless pageSize is between 1 and the configured
+// maxPageSize
+func checkPageSize(ctx contractapi.TransactionContextInterface, pageSize int32) error {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if pageSize <= 0 || int(pageSize) > config.MaxPageSize {
+		return fmt.Errorf("page size must be between 1 and %d; fetch further pages with the bookmark each page returns", config.MaxPageSize)
+	}
+	return nil
+}
+
+// forEachResult calls fn with each result of an iterator, one at a time, and
+// closes the iterator. It fails the query once more than the configured
+// maxIteratorResults results have been seen.
+func forEachResult(ctx contractapi.TransactionContextInterface, resultsIterator shim.StateQueryIteratorInterface, fn func(*queryresult.KV) error) error {
+	defer resultsIterator.Close()
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	seen := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return err
+		}
+
+		seen++
+		if seen > config.MaxIteratorResults {
+			return fmt.Errorf("query matched more than %d results; use a paginated query and follow its bookmark", config.MaxIteratorResults)
+		}
+		err = fn(queryResponse)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanCompositeKeys calls fn with up to limit documents under composite keys
+// of objectType, in key order, starting after the key after, or at the first
+// when it is empty. fn returns false to end the page before the document it
+// was given. scanCompositeKeys returns the key to resume after, and whether
+// the scan has passed the last document.
+func scanCompositeKeys(ctx contractapi.TransactionContextInterface, objectType string, after string, limit int, fn func(key string, value []byte) (bool, error)) (string, bool, error) {
+	return scanPartialCompositeKeys(ctx, objectType, []string{}, after, limit, fn)
+}
+
+// scanPartialCompositeKeys is scanCompositeKeys over the keys of objectType
+// that start with attributes
+func scanPartialCompositeKeys(ctx contractapi.TransactionContextInterface, objectType string, attributes []string, after string, limit int, fn func(key string, value []byte) (bool, error)) (string, bool, error) {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(objectType, attributes)
+	if err != nil {
+		return "", false, err
+	}
+	defer resultsIterator.Close()
+
+	read, taken, last := 0, 0, after
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return "", false, err
+		}
+		read++
+		if read > config.MaxIteratorResults {
+			return "", false, fmt.Errorf("the page lies more than %d keys into the scan; raise maxIteratorResults to continue", config.MaxIteratorResults)
+		}
+		if after != "" && queryResponse.Key <= after {
+			continue
+		}
+		if taken == limit {
+			return last, false, nil
+		}
+		more, err := fn(queryResponse.Key, queryResponse.Value)
+		if err != nil {
+			return "", false, err
+		}
+		if !more {
+			return last, false, nil
+		}
+		taken++
+		last = queryResponse.Key
+	}
+	return last, true, nil
+}
+
+// scanSimpleKeys calls fn with up to limit documents under simple keys, in
+// key order, starting after the key after, or at the first when it is
+// empty. fn returns false to end the page before the document it was given.
+// scanSimpleKeys returns the key to resume after, and whether the scan has
+// passed the last document.
+func scanSimpleKeys(ctx contractapi.TransactionContextInterface, after string, limit int, fn func(key string, value []byte) (bool, error)) (string, bool, error) {
+	return scanKeyRange(ctx, "", "", after, limit, fn)
+}
+
+// scanKeyRange is scanSimpleKeys over the simple keys in [start, end), an
+// empty end leaving the range open
+func scanKeyRange(ctx contractapi.TransactionContextInterface, start string, end string, after string, limit int, fn func(key string, value []byte) (bool, error)) (string, bool, error) {
+	if after != "" && after >= start {
+		// the first key greater than after
+		start = after + "\x00"
+	}
+	resultsIterator, err := ctx.GetStub().GetStateByRange(start, end)
+	if err != nil {
+		return "", false, err
+	}
+	defer resultsIterator.Close()
+
+	taken, last := 0, after
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return "", false, err
+		}
+		if taken == limit {
+			return last, false, nil
+		}
+		more, err := fn(queryResponse.Key, queryResponse.Value)
+		if err != nil {
+			return "", false, err
+		}
+		if !more {
+			return last, false, nil
+		}
+		taken++
+		last = queryResponse.Key
+	}
+	return last, true, nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Typed keys. Records and history entries are stored under composite keys of
+// their own object type, "KYC~<kyc ID>" and "HIST~<kyc ID>~<tx ID>~<sequence>",
+// so a scan over one kind of document never meets another and a record's
+// history is one partial-key read. The sequence tells apart the entries one
+// transaction writes for a record, which the entry IDs, built from the time
+// in seconds, do not. Earlier versions stored records under their bare ID and
+// history entries under HISTORY_<entry ID>, sharing the simple key space
+// with every other document. MigrateKeys moves them; until it has run over
+// every page, reads fall back to the legacy record keys and writes move the
+// record they touch, while legacy history entries are not read at all.
+const (
+	recordObjectType    = "KYC"
+	historyObjectType   = "HIST"
+	legacyHistoryPrefix = "HISTORY_"
+)
+
+// KeyMigrationResult summarises one page of a key migration
+type KeyMigrationResult struct {
+	Scanned  int    `json:"scanned"`
+	Records  int    `json:"records"` // records moved to their typed key
+	History  int    `json:"history"` // history entries moved to their typed key
+	Bookmark string `json:"bookmark"`
+}
+
+func recordStateKey(ctx contractapi.TransactionContextInterface, kycID string) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(recordObjectType, []string{kycID})
+}
+
+func historyStateKey(ctx contractapi.TransactionContextInterface, entry *HistoryEntry) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(historyObjectType, []string{entry.KYCID, entry.TxID, fmt.Sprintf("%06d", entry.Sequence)})
+}
+
+// getRecordState returns a record's JSON from its typed key or, if it has
+// not been migrated yet, its legacy key; nil if there is no such record
+func getRecordState(ctx contractapi.TransactionContextInterface, kycID string) ([]byte, error) {
+	key, err := recordStateKey(ctx, kycID)
+	if err != nil {
+		return nil, err
+	}
+	kycJSON, err := ctx.GetStub().GetState(key)
+	if err != nil || kycJSON != nil {
+		return kycJSON, err
+	}
+	return ctx.GetStub().GetState(kycID)
+}
+
+// deleteLegacyRecord deletes a record under its legacy key, if it is still
+// there
+func deleteLegacyRecord(ctx contractapi.TransactionContextInterface, kycID string) error {
+	legacy, err := ctx.GetStub().GetState(kycID)
+	if err != nil || legacy == nil {
+		return err
+	}
+	return ctx.GetStub().DelState(kycID)
+}
+
+// countHistoryEntries counts the committed history entries one transaction
+// wrote for a record
+func countHistoryEntries(ctx contractapi.TransactionContextInterface, kycID, txID string) (int, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(historyObjectType, []string{kycID, txID})
+	if err != nil {
+		return 0, err
+	}
+	defer resultsIterator.Close()
+
+	count := 0
+	for resultsIterator.HasNext() {
+		_, err := resultsIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// MigrateKeys moves one page of the records and history entries still under
+// their legacy keys to their typed keys, unchanged but for the sequence a
+// history entry is given. Run it over every page, until the bookmark comes
+// back empty; documents of every other kind are left where they are.
+func (s *SmartContract) MigrateKeys(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*KeyMigrationResult, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	// the moves are writes, which rule out paginated queries
+	result := &KeyMigrationResult{}
+	next, done, err := scanSimpleKeys(ctx, bookmark, int(pageSize), func(key string, value []byte) (bool, error) {
+		result.Scanned++
+
+		if strings.HasPrefix(key, legacyHistoryPrefix) {
+			var entry HistoryEntry
+			err := json.Unmarshal(value, &entry)
+			if err != nil {
+				return false, fmt.Errorf("invalid history entry %s: %v", key, err)
+			}
+			// entries of one transaction moved by earlier pages are
+			// committed, those moved by this page are not
+			moved, err := countHistoryEntries(ctx, entry.KYCID, entry.TxID)
+			if err != nil {
+				return false, err
+			}
+			entry.Sequence = moved + nextHistorySequence(ctx, entry.KYCID, entry.TxID)
+			entryJSON, err := json.Marshal(entry)
+			if err != nil {
+				return false, err
+			}
+			historyKey, err := historyStateKey(ctx, &entry)
+			if err != nil {
+				return false, err
+			}
+			err = ctx.GetStub().PutState(historyKey, entryJSON)
+			if err != nil {
+				return false, fmt.Errorf("failed to move history entry %s: %v", entry.ID, err)
+			}
+			err = ctx.GetStub().DelState(key)
+			if err != nil {
+				return false, fmt.Errorf("failed to move history entry %s: %v", entry.ID, err)
+			}
+			result.History++
+			return true, nil
+		}
+
+		lineType, err := exportKeyType(key, value)
+		if err != nil {
+			return false, err
+		}
+		if lineType != ExportRecord {
+			return true, nil
+		}
+		var kyc KYCRecord
+		err = unmarshalRecord(value, &kyc)
+		if err != nil {
+			return false, fmt.Errorf("invalid KYC record %s: %v", key, err)
+		}
+		if kyc.Status == "" || kyc.ID != key {
+			return false, fmt.Errorf("key %q holds a document that is not a KYC record", key)
+		}
+		err = putRecordState(ctx, kyc.ID, value)
+		if err != nil {
+			return false, fmt.Errorf("failed to move KYC record %s: %v", kyc.ID, err)
+		}
+		result.Records++
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !done {
+		result.Bookmark = next
+	}
+	return result, nil
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	listObjectType      = "refList"
+	listEntryObjectType = "refListEntry"
+
+	// maxListBatch bounds the entries written by one UpsertListEntries call
+	maxListBatch = 1000
+)
+
+// Reference list types
+const (
+	ListBlocklist = "BLOCKLIST"
+	ListWatchlist = "WATCHLIST"
+	ListAllowlist = "ALLOWLIST"
+)
+
+var listNamePattern = regexp.MustCompile(`^[A-Z0-9_-]{1,64}$`)
+
+// ReferenceList describes a named screening list. Version increases with
+// every change to the list's entries.
+type ReferenceList struct {
+	Name          string `json:"name"`
+	Type          string `json:"type"` // BLOCKLIST, WATCHLIST, ALLOWLIST
+	Version       int    `json:"version"`
+	EntryCount    int    `json:"entryCount"`
+	CreatedAt     string `json:"createdAt"`
+	UpdatedAt     string `json:"updatedAt"`
+	UpdatedBy     string `json:"updatedBy"`
+	SourceVersion string `json:"sourceVersion,omitempty" metadata:",optional"` // publisher's version as of the last SyncWatchlist
+	ContentHash   string `json:"contentHash,omitempty" metadata:",optional"`   // cleared when entries are edited directly
+}
+
+// ListEntry is one entry of a reference list, identified by its key within the list
+type ListEntry struct {
+	List       string            `json:"list"`
+	Key        string            `json:"key"`
+	Reason     string            `json:"reason,omitempty" metadata:",optional"`
+	Attributes map[string]string `json:"attributes,omitempty" metadata:",optional"`
+	Version    int               `json:"version"` // list version in which the entry was last written
+	UpdatedAt  string            `json:"updatedAt"`
+	UpdatedBy  string            `json:"updatedBy"`
+}
+
+// ListPage holds one page of a reference list's entries
+type ListPage struct {
+	List                *ReferenceList `json:"list"`
+	Entries             []*ListEntry   `json:"entries"`
+	FetchedRecordsCount int32          `json:"fetchedRecordsCount"`
+	Bookmark            string         `json:"bookmark"`
+	Truncated           bool           `json:"truncated"` // the page was cut short by maxResponseBytes
+}
+
+// UpsertListEntries adds or replaces entries of a named list, creating the
+// list with the given type if it does not exist yet. entriesData is a JSON
+// array of entries; only key, reason and attributes are read from each.
+func (s *SmartContract) UpsertListEntries(ctx contractapi.TransactionContextInterface, listName string, listType string, entriesData string) (*ReferenceList, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ListEntry
+	err = json.Unmarshal([]byte(entriesData), &entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal list entries: %v", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no list entries supplied")
+	}
+	if len(entries) > maxListBatch {
+		return nil, fmt.Errorf("at most %d list entries can be written per transaction", maxListBatch)
+	}
+
+	list, err := loadOrCreateList(ctx, listName, listType)
+	if err != nil {
+		return nil, err
+	}
+	err = touchList(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+	list.ContentHash = ""
+
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		// writes are not visible to reads within a transaction, so a repeated
+		// key would otherwise be counted twice
+		if seen[listEntryKey(entry.Key)] {
+			return nil, fmt.Errorf("duplicate list entry key %q", entry.Key)
+		}
+		seen[listEntryKey(entry.Key)] = true
+
+		created, err := putListEntry(ctx, list, entry.Key, entry.Reason, entry.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			list.EntryCount++
+		}
+	}
+
+	return list, saveList(ctx, list)
+}
+
+// RemoveListEntry deletes one entry from a named list
+func (s *SmartContract) RemoveListEntry(ctx contractapi.TransactionContextInterface, listName string, key string) (*ReferenceList, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := loadList(ctx, listName)
+	if err != nil {
+		return nil, err
+	}
+
+	key = listEntryKey(key)
+	entryKey, err := ctx.GetStub().CreateCompositeKey(listEntryObjectType, []string{list.Name, key})
+	if err != nil {
+		return nil, err
+	}
+	existing, err := ctx.GetStub().GetState(entryKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if existing == nil {
+		return nil, fmt.Errorf("list %s has no entry %s", list.Name, key)
+	}
+
+	err = ctx.GetStub().DelState(entryKey)
+	if err != nil {
+		return nil, err
+	}
+
+	err = touchList(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+	list.ContentHash = ""
+	list.EntryCount--
+	return list, saveList(ctx, list)
+}
+
+// GetList returns a named list and one page of its entries
+func (s *SmartContract) GetList(ctx contractapi.TransactionContextInterface, listName string, pageSize int32, bookmark string) (*ListPage, error) {
+	list, err := loadList(ctx, listName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	resultsIterator, responseMetadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(listEntryObjectType, []string{list.Name}, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	budget, err := newResponseBudget(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []*ListEntry{}
+	bookmark, truncated := responseMetadata.Bookmark, false
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var entry ListEntry
+		err = json.Unmarshal(queryResponse.Value, &entry)
+		if err != nil {
+			return nil, err
+		}
+		fits, err := budget.fits(&entry)
+		if err != nil {
+			return nil, err
+		}
+		if !fits {
+			bookmark, truncated = queryResponse.Key, true
+			break
+		}
+		entries = append(entries, &entry)
+	}
+
+	return &ListPage{
+		List:                list,
+		Entries:             entries,
+		FetchedRecordsCount: int32(len(entries)),
+		Bookmark:            bookmark,
+		Truncated:           truncated,
+	}, nil
+}
+
+// getListEntry returns an entry of a named list, or nil if the list has no such entry
+func getListEntry(ctx contractapi.TransactionContextInterface, listName string, key string) (*ListEntry, error) {
+	entryKey, err := ctx.GetStub().CreateCompositeKey(listEntryObjectType, []string{strings.ToUpper(listName), listEntryKey(key)})
+	if err != nil {
+		return nil, err
+	}
+	entryJSON, err := ctx.GetStub().GetState(entryKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if entryJSON == nil {
+		return nil, nil
+	}
+
+	var entry ListEntry
+	err = json.Unmarshal(entryJSON, &entry)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// putListEntry writes an entry at the list's current version and reports
+// whether the key was new to the list
+func putListEntry(ctx contractapi.TransactionContextInterface, list *ReferenceList, key string, reason string, attributes map[string]string) (bool, error) {
+	key = listEntryKey(key)
+	if key == "" {
+		return false, fmt.Errorf("list entry key is required")
+	}
+
+	entryKey, err := ctx.GetStub().CreateCompositeKey(listEntryObjectType, []string{list.Name, key})
+	if err != nil {
+		return false, err
+	}
+	existing, err := ctx.GetStub().GetState(entryKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	entry := ListEntry{
+		List:       list.Name,
+		Key:        key,
+		Reason:     strings.TrimSpace(reason),
+		Attributes: attributes,
+		Version:    list.Version,
+		UpdatedAt:  list.UpdatedAt,
+		UpdatedBy:  list.UpdatedBy,
+	}
+	entryJSON, err := json.Marshal(entry)
+	if err != nil {
+		return false, err
+	}
+	return existing == nil, ctx.GetStub().PutState(entryKey, entryJSON)
+}
+
+// listEntryKey canonicalises an entry key; keys are matched case-insensitively
+func listEntryKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+func loadList(ctx contractapi.TransactionContextInterface, listName string) (*ReferenceList, error) {
+	list, err := readList(ctx, listName)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		return nil, fmt.Errorf("list %s does not exist", strings.ToUpper(listName))
+	}
+	return list, nil
+}
+
+func loadOrCreateList(ctx contractapi.TransactionContextInterface, listName string, listType string) (*ReferenceList, error) {
+	list, err := readList(ctx, listName)
+	if err != nil || list != nil {
+		return list, err
+	}
+
+	name := strings.ToUpper(strings.TrimSpace(listName))
+	if !listNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid list name %q", listName)
+	}
+	listType = strings.ToUpper(strings.TrimSpace(listType))
+	if listType != ListBlocklist && listType != ListWatchlist && listType != ListAllowlist {
+		return nil, fmt.Errorf("list type must be %s, %s or %s", ListBlocklist, ListWatchlist, ListAllowlist)
+	}
+
+	return &ReferenceList{
+		Name:      name,
+		Type:      listType,
+		CreatedAt: txTime(ctx).Format(time.RFC3339),
+	}, nil
+}
+
+func readList(ctx contractapi.TransactionContextInterface, listName string) (*ReferenceList, error) {
+	listKey, err := ctx.GetStub().CreateCompositeKey(listObjectType, []string{strings.ToUpper(strings.TrimSpace(listName))})
+	if err != nil {
+		return nil, err
+	}
+	listJSON, err := ctx.GetStub().GetState(listKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if listJSON == nil {
+		return nil, nil
+	}
+
+	var list ReferenceList
+	err = json.Unmarshal(listJSON, &list)
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// touchList starts a new list version attributed to the caller
+func touchList(ctx contractapi.TransactionContextInterface, list *ReferenceList) error {
+	updatedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	list.Version++
+	list.UpdatedAt = txTime(ctx).Format(time.RFC3339)
+	list.UpdatedBy = updatedBy
+	return nil
+}
+
+func saveList(ctx contractapi.TransactionContextInterface, list *ReferenceList) error {
+	listKey, err := ctx.GetStub().CreateCompositeKey(listObjectType, []string{list.Name})
+	if err != nil {
+		return err
+	}
+	listJSON, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(listKey, listJSON)
+}
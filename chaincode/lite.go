@@ -0,0 +1,37 @@
+package main
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// KYCLite is the view of a KYC record a relying party needs to decide whether
+// to rely on it, without the customer's personal details
+type KYCLite struct {
+	ID                string `json:"id"`
+	EntityType        string `json:"entityType"`
+	Status            string `json:"status"`
+	VerificationLevel string `json:"verificationLevel"`
+	RiskTier          string `json:"riskTier,omitempty" metadata:",optional"`
+	VerifiedAt        string `json:"verifiedAt,omitempty" metadata:",optional"`
+	ExpiresAt         string `json:"expiresAt,omitempty" metadata:",optional"`
+	UpdatedAt         string `json:"updatedAt"`
+}
+
+// GetKYCLite returns the status summary of a KYC record
+func (s *SmartContract) GetKYCLite(ctx contractapi.TransactionContextInterface, id string) (*KYCLite, error) {
+	kyc, err := s.readKYC(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KYCLite{
+		ID:                kyc.ID,
+		EntityType:        kyc.EntityType,
+		Status:            kyc.Status,
+		VerificationLevel: kyc.VerificationLevel,
+		RiskTier:          kyc.RiskTier,
+		VerifiedAt:        kyc.VerifiedAt,
+		ExpiresAt:         kyc.ExpiresAt,
+		UpdatedAt:         kyc.UpdatedAt,
+	}, nil
+}
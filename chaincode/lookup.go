@@ -0,0 +1,156 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Lookup indexes. GetKYCByPAN, GetKYCByEmail and the status queries find
+// records through composite-key indexes rather than CouchDB rich queries, so
+// they work on any state database and their reads are checked at commit
+// like any other key read. putRecordState and deleteRecordState keep the
+// indexes in step with every record write. The PAN and email indexes hold
+// the identifierHash of the upper-cased PAN and lower-cased email, so their
+// keys carry no personal details; the status index holds the status.
+// Fields held in private data or encrypted are empty in world state and
+// not indexed. Records written before the indexes were maintained are
+// indexed with BackfillLookupIndexes.
+const (
+	panIndex    = "pan~kycid"
+	emailIndex  = "email~kycid"
+	statusIndex = "status~kycid"
+)
+
+// LookupBackfillResult summarises one page of a lookup index backfill
+type LookupBackfillResult struct {
+	Scanned  int    `json:"scanned"`
+	Bookmark string `json:"bookmark"`
+}
+
+// panHash returns the index hash of a PAN
+func panHash(pan string) string {
+	return identifierHash(strings.ToUpper(strings.TrimSpace(pan)))
+}
+
+// emailHash returns the index hash of an email address
+func emailHash(email string) string {
+	return identifierHash(strings.ToLower(strings.TrimSpace(email)))
+}
+
+// lookupEntry is one lookup index entry of a record
+type lookupEntry struct {
+	index     string
+	attribute string
+}
+
+// lookupEntries returns the lookup index entries of a record, none for a nil
+// record
+func lookupEntries(kyc *KYCRecord) map[lookupEntry]bool {
+	entries := map[lookupEntry]bool{}
+	if kyc == nil {
+		return entries
+	}
+	if kyc.PAN != "" {
+		entries[lookupEntry{panIndex, panHash(kyc.PAN)}] = true
+	}
+	if kyc.Email != "" {
+		entries[lookupEntry{emailIndex, emailHash(kyc.Email)}] = true
+	}
+	if kyc.Status != "" {
+		entries[lookupEntry{statusIndex, kyc.Status}] = true
+	}
+	return entries
+}
+
+// updateLookupIndexes moves a record's lookup index entries from its JSON
+// before a write to its JSON after it, either nil when there is none
+func updateLookupIndexes(ctx contractapi.TransactionContextInterface, kycID string, beforeJSON []byte, afterJSON []byte) error {
+	before, err := unmarshalRecordState(kycID, beforeJSON)
+	if err != nil {
+		return err
+	}
+	after, err := unmarshalRecordState(kycID, afterJSON)
+	if err != nil {
+		return err
+	}
+	was, is := lookupEntries(before), lookupEntries(after)
+	for entry := range was {
+		if !is[entry] {
+			err = deleteIndexEntry(ctx, entry.index, kycID, entry.attribute)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for entry := range is {
+		if !was[entry] {
+			err = putIndexEntry(ctx, entry.index, kycID, entry.attribute)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// getRecordsByLookup returns what the caller may read of the records a
+// lookup index holds under an attribute
+func (s *SmartContract) getRecordsByLookup(ctx contractapi.TransactionContextInterface, purpose *readPurpose, indexName string, attribute string) ([]*KYCRecord, error) {
+	kycIDs, err := getIndexedIDs(ctx, indexName, attribute)
+	if err != nil {
+		return nil, err
+	}
+
+	kycRecords := []*KYCRecord{}
+	for _, kycID := range kycIDs {
+		kyc, err := s.readKYC(ctx, kycID)
+		if err != nil {
+			return nil, err
+		}
+		kycRecords = append(kycRecords, kyc)
+	}
+	return purpose.filter(ctx, kycRecords)
+}
+
+// BackfillLookupIndexes writes the lookup index entries of one page of the
+// records under their typed keys. Run it over every page, until the
+// bookmark comes back empty, once after upgrading a ledger holding records
+// written before the indexes were maintained, and after MigrateKeys has
+// moved any records still under legacy keys. Entries already there are
+// rewritten unchanged.
+func (s *SmartContract) BackfillLookupIndexes(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*LookupBackfillResult, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	// the index writes rule out paginated queries
+	result := &LookupBackfillResult{}
+	next, done, err := scanCompositeKeys(ctx, recordObjectType, bookmark, int(pageSize), func(key string, value []byte) (bool, error) {
+		var kyc KYCRecord
+		err := unmarshalRecord(value, &kyc)
+		if err != nil {
+			return false, err
+		}
+		for entry := range lookupEntries(&kyc) {
+			err = putIndexEntry(ctx, entry.index, kyc.ID, entry.attribute)
+			if err != nil {
+				return false, err
+			}
+		}
+		result.Scanned++
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !done {
+		result.Bookmark = next
+	}
+	return result, nil
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// PII masking. The configuration's masking profile lists rules that mask
+// personal details in the records queries return, such as a PAN read as
+// XXXXX1234F or a phone number as ******7890, for every caller whose role
+// ranks below the profile's unmasked role. Callers at or above it read the
+// details unmasked, and each record they read that way is logged in the
+// record's access log, their own organisation's records included. The
+// rules for name and pan also mask the related parties of entity records.
+// Masking applies to what a read returns, on top of any grant's field
+// scope; the records themselves are stored unmasked. A profile with no
+// rules turns masking off.
+
+// Roles callers rank by for masking, lowest first. A caller ranks by the
+// highest role their certificate carries; regulators rank with senior
+// verifiers.
+const (
+	MaskRoleUser     = "USER"
+	MaskRoleVerifier = "VERIFIER"
+	MaskRoleSenior   = "SENIOR"
+	MaskRoleAdmin    = "ADMIN"
+)
+
+var maskRoleRanks = map[string]int{
+	MaskRoleUser:     0,
+	MaskRoleVerifier: 1,
+	MaskRoleSenior:   2,
+	MaskRoleAdmin:    3,
+}
+
+// maskableFields are the record fields a masking rule can name
+var maskableFields = map[string]bool{
+	"userId":      true,
+	"name":        true,
+	"email":       true,
+	"phone":       true,
+	"pan":         true,
+	"dateOfBirth": true,
+	"street":      true, // the address's, and the as-given address's
+}
+
+// MaskingProfile configures the masking of personal details in query responses
+type MaskingProfile struct {
+	UnmaskedRole string     `json:"unmaskedRole"` // lowest role reading unmasked: USER, VERIFIER, SENIOR or ADMIN
+	Rules        []MaskRule `json:"rules"`
+}
+
+// MaskRule masks one field, leaving its last characters visible
+type MaskRule struct {
+	Field    string `json:"field"`                                   // userId, name, email, phone, pan, dateOfBirth or street
+	KeepLast int    `json:"keepLast"`                                // trailing characters left visible; values no longer than this are masked whole
+	MaskChar string `json:"maskChar,omitempty" metadata:",optional"` // a single character, * by default
+}
+
+func validateMaskingProfile(profile *MaskingProfile) error {
+	if len(profile.Rules) == 0 {
+		return nil
+	}
+	if _, ok := maskRoleRanks[profile.UnmaskedRole]; !ok {
+		return fmt.Errorf("masking.unmaskedRole must be %s, %s, %s or %s", MaskRoleUser, MaskRoleVerifier, MaskRoleSenior, MaskRoleAdmin)
+	}
+	seen := map[string]bool{}
+	for _, rule := range profile.Rules {
+		if !maskableFields[rule.Field] {
+			return fmt.Errorf("masking rule field %q cannot be masked", rule.Field)
+		}
+		if seen[rule.Field] {
+			return fmt.Errorf("masking rule for %s is listed more than once", rule.Field)
+		}
+		seen[rule.Field] = true
+		if rule.KeepLast < 0 {
+			return fmt.Errorf("masking rule for %s must not keep a negative number of characters", rule.Field)
+		}
+		if rule.MaskChar != "" && utf8.RuneCountInString(rule.MaskChar) != 1 {
+			return fmt.Errorf("masking rule for %s must mask with a single character", rule.Field)
+		}
+	}
+	return nil
+}
+
+// readsUnmasked reports whether the caller's role ranks at or above a
+// profile's unmasked role
+func readsUnmasked(ctx contractapi.TransactionContextInterface, profile *MaskingProfile) (bool, error) {
+	rank := maskRoleRanks[MaskRoleUser]
+	for _, role := range []struct {
+		attr string
+		rank int
+	}{
+		{AttrVerifier, maskRoleRanks[MaskRoleVerifier]},
+		{AttrSenior, maskRoleRanks[MaskRoleSenior]},
+		{AttrRegulator, maskRoleRanks[MaskRoleSenior]},
+		{AttrAdmin, maskRoleRanks[MaskRoleAdmin]},
+	} {
+		held, err := hasAttribute(ctx, role.attr)
+		if err != nil {
+			return false, err
+		}
+		if held && role.rank > rank {
+			rank = role.rank
+		}
+	}
+	return rank >= maskRoleRanks[profile.UnmaskedRole], nil
+}
+
+// maskRecord returns a copy of a record with a profile's rules applied
+func maskRecord(kyc *KYCRecord, profile *MaskingProfile) *KYCRecord {
+	masked := *kyc
+	for _, rule := range profile.Rules {
+		switch rule.Field {
+		case "userId":
+			masked.UserID = rule.mask(masked.UserID)
+		case "name":
+			masked.Name = rule.mask(masked.Name)
+		case "email":
+			masked.Email = rule.mask(masked.Email)
+		case "phone":
+			masked.Phone = rule.mask(masked.Phone)
+		case "pan":
+			masked.PAN = rule.mask(masked.PAN)
+		case "dateOfBirth":
+			masked.DateOfBirth = rule.mask(masked.DateOfBirth)
+		case "street":
+			masked.Address.Street = rule.mask(masked.Address.Street)
+			if masked.RawAddress != nil {
+				raw := *masked.RawAddress
+				raw.Street = rule.mask(raw.Street)
+				masked.RawAddress = &raw
+			}
+		}
+	}
+	if masked.EntityDetails != nil {
+		details := *masked.EntityDetails
+		details.Directors = maskParties(details.Directors, profile)
+		details.Trustees = maskParties(details.Trustees, profile)
+		details.Partners = maskParties(details.Partners, profile)
+		details.Coparceners = maskParties(details.Coparceners, profile)
+		for _, party := range []**RelatedParty{&details.Settlor, &details.Karta} {
+			if *party != nil {
+				*party = &maskParties([]RelatedParty{**party}, profile)[0]
+			}
+		}
+		masked.EntityDetails = &details
+	}
+	return &masked
+}
+
+// maskParties returns a copy of related parties with a profile's name and
+// pan rules applied
+func maskParties(parties []RelatedParty, profile *MaskingProfile) []RelatedParty {
+	if parties == nil {
+		return nil
+	}
+	masked := make([]RelatedParty, len(parties))
+	for i, party := range parties {
+		for _, rule := range profile.Rules {
+			switch rule.Field {
+			case "name":
+				party.Name = rule.mask(party.Name)
+			case "pan":
+				party.PAN = rule.mask(party.PAN)
+			}
+		}
+		masked[i] = party
+	}
+	return masked
+}
+
+// mask masks a value by the rule
+func (rule MaskRule) mask(value string) string {
+	if value == "" {
+		return ""
+	}
+	char := rule.MaskChar
+	if char == "" {
+		char = "*"
+	}
+	runes := []rune(value)
+	keep := rule.KeepLast
+	if keep >= len(runes) {
+		keep = 0
+	}
+	return strings.Repeat(char, len(runes)-keep) + string(runes[len(runes)-keep:])
+}
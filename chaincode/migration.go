@@ -0,0 +1,738 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Export type markers, one per kind of document ExportAll writes
+const (
+	ExportRecord            = "record"
+	ExportArchivedRecord    = "archivedRecord"
+	ExportHistory           = "history"
+	ExportException         = "exception"
+	ExportConfig            = "config"
+	ExportRiskRules         = "riskRules"
+	ExportRiskRuleSet       = "riskRuleSet"
+	ExportExtensionSchema   = "extensionSchema"
+	ExportList              = "list"
+	ExportListEntry         = "listEntry"
+	ExportScreeningRun      = "screeningRun"
+	ExportStaleScreening    = "staleScreening"
+	ExportMonthlySummary    = "monthlySummary"
+	ExportRiskRecalculation = "riskRecalculation"
+	ExportHistoryHead       = "historyHead"
+	ExportAnchor            = "anchor"
+	ExportTimestampToken    = "timestampToken"
+	ExportReportESign       = "reportESign"
+	ExportPhoto             = "photo"
+	ExportConsentReceipt    = "consentReceipt"
+	ExportAccessLog         = "accessLog"
+	ExportAccessGrant       = "accessGrant"
+	ExportRevocation        = "revocation"
+	ExportRevocationAck     = "revocationAck"
+	ExportSponsorship       = "sponsorship"
+	ExportDecoy             = "decoy"
+	ExportBulkAccessLog     = "bulkAccessLog"
+	ExportFieldKey          = "fieldKey"
+	ExportKeyEscrow         = "keyEscrow"
+	ExportKeyRecovery       = "keyRecovery"
+	ExportReportSignature   = "reportSignature"
+	ExportVerifier          = "verifier"
+	ExportVerifierCert      = "verifierCertificate"
+	ExportPolicy            = "policy"
+	ExportValidationHooks   = "validationHooks"
+)
+
+// maxImportBatch bounds the lines loaded by one ImportRecords call
+const maxImportBatch = 1000
+
+// exportSections are the key spaces ExportAll pages through in turn: the
+// simple keys first, then each composite-key object type holding documents.
+// Indexes, counters, the per-type copy of the exception register, the
+// time-ordered copy of the access log and the schema 2 copies of records are
+// derived from the exported documents and are rebuilt by ImportRecords
+// rather than exported; rate limit buckets and expiry reminders are not
+// carried over at all.
+var exportSections = []struct {
+	objectType string
+	exportType string
+}{
+	{"", ""},
+	{recordObjectType, ExportRecord},
+	{historyObjectType, ExportHistory},
+	{listObjectType, ExportList},
+	{listEntryObjectType, ExportListEntry},
+	{screeningRunObjectType, ExportScreeningRun},
+	{staleScreeningIndex, ExportStaleScreening},
+	{monthlySummaryObjectType, ExportMonthlySummary},
+	{riskRecalculationObjectType, ExportRiskRecalculation},
+	{historyHeadObjectType, ExportHistoryHead},
+	{riskRuleSetObjectType, ExportRiskRuleSet},
+	{anchorObjectType, ExportAnchor},
+	{timestampTokenObjectType, ExportTimestampToken},
+	{reportESignObjectType, ExportReportESign},
+	{photoObjectType, ExportPhoto},
+	{consentObjectType, ExportConsentReceipt},
+	{accessLogObjectType, ExportAccessLog},
+	{grantObjectType, ExportAccessGrant},
+	{revocationObjectType, ExportRevocation},
+	{revocationAckObjectType, ExportRevocationAck},
+	{sponsorshipObjectType, ExportSponsorship},
+	{decoyObjectType, ExportDecoy},
+	{bulkAccessLogObjectType, ExportBulkAccessLog},
+	{fieldKeyObjectType, ExportFieldKey},
+	{escrowObjectType, ExportKeyEscrow},
+	{recoveryObjectType, ExportKeyRecovery},
+	{reportSignatureObjectType, ExportReportSignature},
+	{verifierObjectType, ExportVerifier},
+	{verifierCertObjectType, ExportVerifierCert},
+	{policyObjectType, ExportPolicy},
+}
+
+// recordStatuses are the statuses a record can hold
+var recordStatuses = map[string]bool{
+	"PENDING":  true,
+	"ON_HOLD":  true,
+	"VERIFIED": true,
+	"REJECTED": true,
+	"EXPIRED":  true,
+	"BLOCKED":  true,
+}
+
+// ExportLine is one line of an export: a document, the key it is stored
+// under and the type marker telling ImportRecords how to validate it
+type ExportLine struct {
+	Type  string          `json:"type"`
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ExportPage holds one page of an export
+type ExportPage struct {
+	Data                string `json:"data"` // NDJSON, one ExportLine per line
+	FetchedRecordsCount int32  `json:"fetchedRecordsCount"`
+	Bookmark            string `json:"bookmark"`  // empty once every key space has been exported
+	Truncated           bool   `json:"truncated"` // the page was cut short by maxResponseBytes
+}
+
+// ImportResult summarises one ImportRecords call
+type ImportResult struct {
+	Imported int            `json:"imported"`
+	Skipped  int            `json:"skipped"` // lines already present with the same value, from a retried import
+	ByType   map[string]int `json:"byType"`  // imported lines per type marker
+}
+
+// ExportAll returns one page of every document in the namespace as NDJSON,
+// for loading into a new channel or network with ImportRecords. Pages are
+// taken from one key space at a time, so a page may be empty; keep paging
+// until the bookmark comes back empty. Only administrators and regulators
+// can export, and each page is logged with the number of documents on it
+// (see GetBulkAccessLog).
+func (s *SmartContract) ExportAll(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*ExportPage, error) {
+	_, err := requireAnyAttribute(ctx, AttrAdmin, AttrRegulator)
+	if err != nil {
+		return nil, err
+	}
+	err = checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	purpose, err := newReadPurpose(ctx, "ExportAll", "")
+	if err != nil {
+		return nil, err
+	}
+
+	section, inner := 0, ""
+	if bookmark != "" {
+		parts := strings.SplitN(bookmark, ":", 2)
+		section, err = strconv.Atoi(parts[0])
+		if len(parts) != 2 || err != nil || section < 0 || section >= len(exportSections) {
+			return nil, fmt.Errorf("invalid export bookmark %q", bookmark)
+		}
+		inner = parts[1]
+	}
+
+	objectType, exportType := exportSections[section].objectType, exportSections[section].exportType
+	budget, err := newResponseBudget(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var data bytes.Buffer
+	count, truncated := int32(0), false
+	page := func(key string, value []byte) (bool, error) {
+		lineType := exportType
+		if objectType == "" {
+			var err error
+			lineType, err = exportKeyType(key, value)
+			if err != nil {
+				return false, err
+			}
+			if lineType == "" {
+				return true, nil
+			}
+		}
+		line := ExportLine{Type: lineType, Key: key, Value: value}
+		fits, err := budget.fits(&line)
+		if err != nil {
+			return false, err
+		}
+		if !fits {
+			truncated = true
+			return false, nil
+		}
+		lineJSON, err := json.Marshal(&line)
+		if err != nil {
+			return false, err
+		}
+		data.Write(lineJSON)
+		data.WriteByte('\n')
+		count++
+		return true, nil
+	}
+	// the log's write rules out paginated queries, so the page is read with
+	// a plain iterator
+	done := false
+	if objectType == "" {
+		inner, done, err = scanSimpleKeys(ctx, inner, int(pageSize), page)
+	} else {
+		inner, done, err = scanCompositeKeys(ctx, objectType, inner, int(pageSize), page)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = purpose.recordBulk(ctx, bookmark, int(count))
+	if err != nil {
+		return nil, err
+	}
+	bookmark = fmt.Sprintf("%d:%s", section, inner)
+	if done {
+		bookmark = ""
+		if section+1 < len(exportSections) {
+			bookmark = fmt.Sprintf("%d:", section+1)
+		}
+	}
+	return &ExportPage{
+		Data:                data.String(),
+		FetchedRecordsCount: count,
+		Bookmark:            bookmark,
+		Truncated:           truncated,
+	}, nil
+}
+
+// exportKeyType returns the type marker of a simple key, or "" for the
+// derived entries ExportAll leaves out
+func exportKeyType(key string, value []byte) (string, error) {
+	switch {
+	case strings.HasPrefix(key, counterPrefix), strings.HasPrefix(key, matchScorePrefix), strings.HasPrefix(key, exceptionTypePrefix), strings.HasPrefix(key, recordPrefix), strings.HasPrefix(key, accessTimePrefix):
+		return "", nil
+	case strings.HasPrefix(key, archivePrefix):
+		return ExportArchivedRecord, nil
+	case strings.HasPrefix(key, exceptionPrefix):
+		return ExportException, nil
+	case strings.HasPrefix(key, legacyHistoryPrefix):
+		return ExportHistory, nil
+	case strings.HasPrefix(key, "EXTSCHEMA_"):
+		return ExportExtensionSchema, nil
+	case key == configKey:
+		return ExportConfig, nil
+	case key == riskRulesKey:
+		return ExportRiskRules, nil
+	case key == validationHooksKey:
+		return ExportValidationHooks, nil
+	}
+	if len(value) == 0 || value[0] != '{' {
+		return "", fmt.Errorf("key %q holds a value of unknown type", key)
+	}
+	return ExportRecord, nil
+}
+
+// ImportRecords loads lines written by ExportAll into an empty namespace. Every
+// line is validated before anything is written: its type must be known, its
+// document must decode without unknown fields and pass the checks the
+// contract applies when writing it, and its key must be the one the contract
+// stores the document under; records and history entries exported before
+// typed keys are accepted under their legacy keys and imported under their
+// typed ones. Legacy history entries are given sequences within their
+// batch, so run MigrateKeys before exporting a ledger that has them. Records are indexed and counted as they are
+// loaded. A line whose key already holds the same value is skipped, so a
+// batch can be retried; any other existing value fails the import.
+func (s *SmartContract) ImportRecords(ctx contractapi.TransactionContextInterface, data string) (*ImportResult, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type importLine struct {
+		ExportLine
+		doc       interface{}
+		valueJSON []byte
+	}
+	var lines []importLine
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if len(lines) == maxImportBatch {
+			return nil, fmt.Errorf("at most %d lines can be imported at once", maxImportBatch)
+		}
+
+		var line importLine
+		decoder := json.NewDecoder(strings.NewReader(text))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&line.ExportLine)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid export line: %v", n, err)
+		}
+		line.doc, err = decodeExportValue(ctx, &line.ExportLine)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		if seen[line.Key] {
+			return nil, fmt.Errorf("line %d: key %q appears more than once", n, line.Key)
+		}
+		seen[line.Key] = true
+		line.valueJSON, err = json.Marshal(line.doc)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	err = scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	stub := ctx.GetStub()
+	// a record and its archived copy must not both be live
+	for _, line := range lines {
+		var kycID, otherKey string
+		switch doc := line.doc.(type) {
+		case *KYCRecord:
+			kycID, otherKey = doc.ID, archiveKey(doc.ID)
+		case *ArchivedRecord:
+			kycID = doc.Record.ID
+			otherKey, err = recordStateKey(ctx, kycID)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			continue
+		}
+		other, err := stub.GetState(otherKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if other != nil || seen[otherKey] {
+			return nil, fmt.Errorf("KYC record %s cannot be imported both live and archived", kycID)
+		}
+	}
+
+	// a decoy's counters are added with whichever of the record and its
+	// decoy flag is imported last
+	records := map[string]*KYCRecord{}
+	for _, line := range lines {
+		if kyc, ok := line.doc.(*KYCRecord); ok {
+			records[kyc.ID] = kyc
+		}
+	}
+
+	result := &ImportResult{ByType: map[string]int{}}
+	deltas := map[string]int{}
+	for _, line := range lines {
+		existing, err := stub.GetState(line.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if existing != nil {
+			if !bytes.Equal(existing, line.valueJSON) {
+				return nil, fmt.Errorf("key %q already holds a different value", line.Key)
+			}
+			result.Skipped++
+			continue
+		}
+
+		if kyc, ok := line.doc.(*KYCRecord); ok {
+			err = putRecordState(ctx, kyc.ID, line.valueJSON)
+		} else {
+			err = stub.PutState(line.Key, line.valueJSON)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to import %s: %v", line.Key, err)
+		}
+		switch doc := line.doc.(type) {
+		case *KYCRecord:
+			err = putRecordIndexes(ctx, doc)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create KYC indexes: %v", err)
+			}
+			decoy, err := isDecoy(ctx, doc.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, name := range counterMemberships(doc) {
+				deltas[name]++
+				if decoy {
+					deltas[counterDecoyPrefix+name]++
+				}
+			}
+		case *Decoy:
+			kyc := records[doc.KYCID]
+			if kyc == nil {
+				exists, err := s.KYCExists(ctx, doc.KYCID)
+				if err != nil {
+					return nil, err
+				}
+				if !exists {
+					break // archived or not imported yet; counted when it is
+				}
+				kyc, err = s.readKYC(ctx, doc.KYCID)
+				if err != nil {
+					return nil, err
+				}
+			}
+			for _, name := range counterMemberships(kyc) {
+				deltas[counterDecoyPrefix+name]++
+			}
+		case *ExceptionEntry:
+			err = stub.PutState(exceptionTypePrefix+doc.Type+"_"+strings.TrimPrefix(line.Key, exceptionPrefix), line.valueJSON)
+			if err != nil {
+				return nil, fmt.Errorf("failed to record exception: %v", err)
+			}
+		case *ScreeningRun:
+			if doc.Disposition == DispositionPendingReview {
+				openAlertKey, err := stub.CreateCompositeKey(openAlertIndex, []string{doc.KYCID, doc.RunID})
+				if err != nil {
+					return nil, err
+				}
+				err = stub.PutState(openAlertKey, []byte{0x00})
+				if err != nil {
+					return nil, err
+				}
+				deltas[counterOpenAlerts]++
+			}
+		case *StaleScreening:
+			deltas[counterStaleScreens]++
+		case *HistoryEntry:
+			createdAt := ""
+			if kyc := records[doc.KYCID]; kyc != nil {
+				createdAt = kyc.CreatedAt
+			} else {
+				createdAt, err = recordCreatedAt(ctx, doc.KYCID)
+				if err != nil {
+					return nil, err
+				}
+			}
+			for name, delta := range decisionCounterDeltas(doc, createdAt) {
+				deltas[name] += delta
+			}
+		case *AccessLogEntry:
+			err = stub.PutState(accessTimeKey(doc), line.valueJSON)
+			if err != nil {
+				return nil, fmt.Errorf("failed to log access: %v", err)
+			}
+		case *AccessGrant:
+			if doc.Sponsor != "" && doc.RevokedAt == "" {
+				err = putIndexEntry(ctx, sponsoredGrantIndex, doc.KYCID, doc.Sponsor, doc.Grantee)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		result.Imported++
+		result.ByType[line.Type]++
+	}
+
+	return result, applyCounterDeltas(ctx, deltas)
+}
+
+// decodeExportValue decodes and validates the document of an export line and
+// checks its key is the one the contract stores the document under
+func decodeExportValue(ctx contractapi.TransactionContextInterface, line *ExportLine) (interface{}, error) {
+	var doc interface{}
+	var key func() (string, error)
+	var legacyKey func() string // the key an export from before typed keys holds it under
+	var fromLegacyKey func()    // completes a document exported under its legacy key
+	var validate func() error
+	switch line.Type {
+	case ExportRecord:
+		kyc := &KYCRecord{}
+		doc, key = kyc, func() (string, error) { return recordStateKey(ctx, kyc.ID) }
+		legacyKey = func() string { return kyc.ID }
+		validate = func() error { return validateImportedRecord(kyc) }
+	case ExportArchivedRecord:
+		archived := &ArchivedRecord{}
+		doc = archived
+		key = func() (string, error) { return archiveKey(archived.Record.ID), nil }
+		validate = func() error {
+			if archived.Record == nil {
+				return fmt.Errorf("archived record has no record")
+			}
+			return validateImportedRecord(archived.Record)
+		}
+	case ExportHistory:
+		entry := &HistoryEntry{}
+		doc, key = entry, func() (string, error) { return historyStateKey(ctx, entry) }
+		legacyKey = func() string { return legacyHistoryPrefix + entry.ID }
+		fromLegacyKey = func() { entry.Sequence = nextHistorySequence(ctx, entry.KYCID, entry.TxID) }
+	case ExportException:
+		entry := &ExceptionEntry{}
+		doc = entry
+		key = func() (string, error) {
+			return fmt.Sprintf("%s%s_%s_%s", exceptionPrefix, entry.RaisedAt, entry.ID, entry.KYCID), nil
+		}
+	case ExportConfig:
+		config := &ContractConfig{}
+		doc, key = config, func() (string, error) { return configKey, nil }
+		validate = func() error { return validateConfig(config) }
+	case ExportRiskRules:
+		rules := &RiskRuleSet{}
+		doc, key = rules, func() (string, error) { return riskRulesKey, nil }
+		validate = func() error { return validateRiskRules(rules) }
+	case ExportRiskRuleSet:
+		rules := &RiskRuleSet{}
+		doc = rules
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(riskRuleSetObjectType, []string{fmt.Sprintf("%06d", rules.Version)})
+		}
+		validate = func() error { return validateRiskRules(rules) }
+	case ExportValidationHooks:
+		config := &ValidationHookConfig{}
+		doc, key = config, func() (string, error) { return validationHooksKey, nil }
+		validate = func() error { return validateHookConfig(config) }
+	case ExportExtensionSchema:
+		schema := &ExtensionSchema{}
+		doc, key = schema, func() (string, error) { return extensionSchemaKey(schema.Namespace), nil }
+		validate = func() error {
+			_, err := compileExtensionSchema(schema.Schema)
+			return err
+		}
+	case ExportList:
+		list := &ReferenceList{}
+		doc = list
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(listObjectType, []string{list.Name})
+		}
+		validate = func() error {
+			if !listNamePattern.MatchString(list.Name) {
+				return fmt.Errorf("invalid list name %q", list.Name)
+			}
+			return nil
+		}
+	case ExportListEntry:
+		entry := &ListEntry{}
+		doc = entry
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(listEntryObjectType, []string{entry.List, entry.Key})
+		}
+	case ExportScreeningRun:
+		run := &ScreeningRun{}
+		doc = run
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(screeningRunObjectType, []string{run.KYCID, run.RunID})
+		}
+	case ExportStaleScreening:
+		stale := &StaleScreening{}
+		doc = stale
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(staleScreeningIndex, []string{stale.List, stale.KYCID})
+		}
+	case ExportMonthlySummary:
+		summary := &MonthlySummary{}
+		doc = summary
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(monthlySummaryObjectType, []string{summary.Month})
+		}
+	case ExportRiskRecalculation:
+		run := &RiskRecalculationRun{}
+		doc = run
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(riskRecalculationObjectType, []string{run.RunID})
+		}
+	case ExportHistoryHead:
+		head := &historyHead{}
+		doc = head
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(historyHeadObjectType, []string{head.KYCID})
+		}
+	case ExportAnchor:
+		anchor := &Anchor{}
+		doc = anchor
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(anchorObjectType, []string{anchor.RecordedAt, anchor.ID})
+		}
+	case ExportTimestampToken:
+		token := &TimestampToken{}
+		doc = token
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(timestampTokenObjectType, []string{token.Event, token.Reference})
+		}
+	case ExportReportESign:
+		attestation := &reportAttestation{}
+		doc = attestation
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(reportESignObjectType, []string{attestation.Month, attestation.ESignTxnID})
+		}
+	case ExportPhoto:
+		photo := &Photo{}
+		doc = photo
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(photoObjectType, []string{photo.KYCID})
+		}
+	case ExportConsentReceipt:
+		receipt := &ConsentReceipt{}
+		doc = receipt
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(consentObjectType, []string{receipt.KYCID, receipt.ReceiptID})
+		}
+	case ExportAccessLog:
+		entry := &AccessLogEntry{}
+		doc = entry
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(accessLogObjectType, []string{entry.KYCID, entry.TxID})
+		}
+	case ExportAccessGrant:
+		grant := &AccessGrant{}
+		doc = grant
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(grantObjectType, []string{grant.KYCID, grant.GrantID})
+		}
+	case ExportRevocation:
+		revocation := &Revocation{}
+		doc = revocation
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(revocationObjectType, []string{revocation.KYCID, revocation.RevocationID})
+		}
+	case ExportRevocationAck:
+		ack := &RevocationAck{}
+		doc = ack
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(revocationAckObjectType, []string{ack.KYCID, ack.RevocationID, ack.MSPID})
+		}
+	case ExportSponsorship:
+		sponsorship := &Sponsorship{}
+		doc = sponsorship
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(sponsorshipObjectType, []string{sponsorship.DelegateMSP, sponsorship.SponsorMSP})
+		}
+	case ExportDecoy:
+		decoy := &Decoy{}
+		doc = decoy
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(decoyObjectType, []string{decoy.KYCID})
+		}
+	case ExportBulkAccessLog:
+		entry := &BulkAccessLogEntry{}
+		doc = entry
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(bulkAccessLogObjectType, []string{entry.TxID})
+		}
+	case ExportFieldKey:
+		fieldKey := &FieldKey{}
+		doc = fieldKey
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(fieldKeyObjectType, []string{fieldKey.KeyID})
+		}
+	case ExportKeyEscrow:
+		escrow := &KeyEscrow{}
+		doc = escrow
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(escrowObjectType, []string{escrow.KeyID})
+		}
+	case ExportKeyRecovery:
+		recovery := &KeyRecovery{}
+		doc = recovery
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(recoveryObjectType, []string{recovery.KeyID, recovery.RecoveryID})
+		}
+	case ExportReportSignature:
+		signature := &ReportSignature{}
+		doc = signature
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(reportSignatureObjectType, []string{signature.Month, signature.TxID})
+		}
+	case ExportVerifier:
+		verifier := &Verifier{}
+		doc = verifier
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(verifierObjectType, []string{verifier.VerifierID})
+		}
+	case ExportVerifierCert:
+		cert := &VerifierCertificate{}
+		doc = cert
+		key = func() (string, error) {
+			return ctx.GetStub().CreateCompositeKey(verifierCertObjectType, []string{cert.Fingerprint})
+		}
+	case ExportPolicy:
+		policy := &ConsortiumPolicy{}
+		doc = policy
+		key = func() (string, error) {
+			return policyKey(ctx, policy.Version)
+		}
+	default:
+		return nil, fmt.Errorf("unknown export type %q", line.Type)
+	}
+
+	value := []byte(line.Value)
+	if line.Type == ExportRecord {
+		// exports from a ledger with schema 1 records import in the current shape
+		var stamped struct {
+			SchemaVersion int `json:"schemaVersion"`
+		}
+		if json.Unmarshal(value, &stamped) == nil && stamped.SchemaVersion < recordSchema {
+			upgraded, err := upgradeLegacyRecord(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %v", line.Type, err)
+			}
+			value = upgraded
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", line.Type, err)
+	}
+	if validate != nil {
+		err = validate()
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %s: %v", line.Type, line.Key, err)
+		}
+	}
+	legacy := legacyKey != nil && line.Key == legacyKey()
+	if legacy && fromLegacyKey != nil {
+		fromLegacyKey()
+	}
+	expected, err := key()
+	if err != nil {
+		return nil, err
+	}
+	if legacy {
+		line.Key = expected
+	}
+	if line.Key != expected {
+		return nil, fmt.Errorf("%s is stored under %q, not %q", line.Type, expected, line.Key)
+	}
+	return doc, nil
+}
+
+// validateImportedRecord checks a record as CreateKYC would, and that it
+// holds a known status
+func validateImportedRecord(kyc *KYCRecord) error {
+	if !recordStatuses[kyc.Status] {
+		return fmt.Errorf("unknown status %q", kyc.Status)
+	}
+	return validateKYCRecord(kyc)
+}
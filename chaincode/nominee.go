@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Nominee represents the nominee registered against a KYC record
+type Nominee struct {
+	NameHash     string         `json:"nameHash"`
+	Relationship string         `json:"relationship"` // SPOUSE, CHILD, PARENT, SIBLING, OTHER
+	NomineeKYCID string         `json:"nomineeKycId,omitempty" metadata:",optional"`
+	Consent      NomineeConsent `json:"consent"`
+	UpdatedAt    string         `json:"updatedAt"`
+}
+
+// NomineeConsent records the record owner's consent to the nominee details
+type NomineeConsent struct {
+	ConsentRef  string `json:"consentRef"` // reference to the owner's signed nominee declaration
+	ConsentedAt string `json:"consentedAt"`
+	RecordedBy  string `json:"recordedBy"`
+}
+
+// nomineeInput is the payload accepted by SetNominee
+type nomineeInput struct {
+	NameHash     string `json:"nameHash"`
+	Relationship string `json:"relationship"`
+	NomineeKYCID string `json:"nomineeKycId"`
+	ConsentRef   string `json:"consentRef"`
+}
+
+var validNomineeRelationships = map[string]bool{
+	"SPOUSE":  true,
+	"CHILD":   true,
+	"PARENT":  true,
+	"SIBLING": true,
+	"OTHER":   true,
+}
+
+// SetNominee adds or replaces the nominee on a KYC record. The owner's consent
+// reference is mandatory and is stored alongside the nominee details. Only
+// the organisation that submitted the record can set its nominee.
+func (s *SmartContract) SetNominee(ctx contractapi.TransactionContextInterface, kycID string, nomineeData string) error {
+	err := requireAllowedMSP(ctx, AllowlistSubmitter)
+	if err != nil {
+		return err
+	}
+	var input nomineeInput
+	err = json.Unmarshal([]byte(nomineeData), &input)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal nominee data: %v", err)
+	}
+
+	if input.NameHash == "" {
+		return fmt.Errorf("nominee name hash is required")
+	}
+	if !validNomineeRelationships[input.Relationship] {
+		return fmt.Errorf("invalid nominee relationship %q", input.Relationship)
+	}
+	if input.ConsentRef == "" {
+		return fmt.Errorf("owner consent reference is required to set a nominee")
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	if kyc.OwnerMSP != "" && kyc.OwnerMSP != mspID {
+		return fmt.Errorf("KYC record %s is owned by %s", kycID, kyc.OwnerMSP)
+	}
+
+	if input.NomineeKYCID != "" {
+		if input.NomineeKYCID == kycID {
+			return fmt.Errorf("a KYC record cannot be its own nominee")
+		}
+		exists, err := s.KYCExists(ctx, input.NomineeKYCID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("nominee KYC record %s does not exist", input.NomineeKYCID)
+		}
+	}
+
+	recordedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	before := *kyc
+	now := s.txTime(ctx).Format(time.RFC3339)
+	replaced := kyc.Nominee != nil
+	kyc.Nominee = &Nominee{
+		NameHash:     input.NameHash,
+		Relationship: input.Relationship,
+		NomineeKYCID: input.NomineeKYCID,
+		Consent: NomineeConsent{
+			ConsentRef:  input.ConsentRef,
+			ConsentedAt: now,
+			RecordedBy:  recordedBy,
+		},
+		UpdatedAt: now,
+	}
+	kyc.UpdatedAt = now
+
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return err
+	}
+
+	err = putRecordState(ctx, kycID, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update KYC record: %v", err)
+	}
+	err = updateRecordCounters(ctx, &before, kyc)
+	if err != nil {
+		return fmt.Errorf("failed to update counters: %v", err)
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-NOMINEE_UPDATED-%d", kycID, s.txTime(ctx).Unix()),
+		KYCID:       kycID,
+		Action:      "NOMINEE_UPDATED",
+		PerformedBy: recordedBy,
+		PerformedAt: now,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"relationship": input.Relationship,
+			"linked":       input.NomineeKYCID != "",
+			"replaced":     replaced,
+			"consentRef":   input.ConsentRef,
+		},
+		Remarks: "Nominee details updated with owner consent",
+	}
+
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+
+	return nil
+}
+
+// nomineeCompleteness classifies the nominee on a record as COMPLETE (consented
+// and linked to the nominee's own KYC), PARTIAL (consented but unlinked) or MISSING
+func nomineeCompleteness(kyc *KYCRecord) string {
+	if kyc.Nominee == nil || kyc.Nominee.Consent.ConsentRef == "" {
+		return "MISSING"
+	}
+	if kyc.Nominee.NomineeKYCID == "" {
+		return "PARTIAL"
+	}
+	return "COMPLETE"
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// streetAbbreviations expands common abbreviations found in street lines
+var streetAbbreviations = map[string]string{
+	"APT":  "Apartment",
+	"APTS": "Apartments",
+	"AVE":  "Avenue",
+	"BLDG": "Building",
+	"BLK":  "Block",
+	"CHS":  "Co-operative Housing Society",
+	"CIR":  "Circle",
+	"COL":  "Colony",
+	"CRS":  "Cross",
+	"FLR":  "Floor",
+	"HSG":  "Housing",
+	"LN":   "Lane",
+	"MKT":  "Market",
+	"NGR":  "Nagar",
+	"NR":   "Near",
+	"OPP":  "Opposite",
+	"PO":   "Post Office",
+	"RD":   "Road",
+	"SEC":  "Sector",
+	"SOC":  "Society",
+	"ST":   "Street",
+}
+
+// cityAliases maps historical or colloquial city names to their current names
+var cityAliases = map[string]string{
+	"BOMBAY":     "Mumbai",
+	"MADRAS":     "Chennai",
+	"CALCUTTA":   "Kolkata",
+	"BANGALORE":  "Bengaluru",
+	"GURGAON":    "Gurugram",
+	"POONA":      "Pune",
+	"BARODA":     "Vadodara",
+	"TRIVANDRUM": "Thiruvananthapuram",
+}
+
+// stateNames maps upper-cased state spellings and codes to the canonical state name
+var stateNames = map[string]string{}
+
+func init() {
+	canonical := map[string][]string{
+		"Andaman and Nicobar Islands": {"AN", "ANDAMAN & NICOBAR ISLANDS", "ANDAMAN AND NICOBAR"},
+		"Andhra Pradesh":              {"AP"},
+		"Arunachal Pradesh":           {"AR"},
+		"Assam":                       {"AS"},
+		"Bihar":                       {"BR"},
+		"Chandigarh":                  {"CH"},
+		"Chhattisgarh":                {"CG", "CT", "CHATTISGARH"},
+		"Dadra and Nagar Haveli and Daman and Diu": {"DH", "DN", "DD", "DAMAN AND DIU", "DADRA AND NAGAR HAVELI"},
+		"Delhi":             {"DL", "NEW DELHI", "NCT OF DELHI"},
+		"Goa":               {"GA"},
+		"Gujarat":           {"GJ"},
+		"Haryana":           {"HR"},
+		"Himachal Pradesh":  {"HP"},
+		"Jammu and Kashmir": {"JK", "J&K", "JAMMU & KASHMIR"},
+		"Jharkhand":         {"JH"},
+		"Karnataka":         {"KA"},
+		"Kerala":            {"KL"},
+		"Ladakh":            {"LA"},
+		"Lakshadweep":       {"LD"},
+		"Madhya Pradesh":    {"MP"},
+		"Maharashtra":       {"MH"},
+		"Manipur":           {"MN"},
+		"Meghalaya":         {"ML"},
+		"Mizoram":           {"MZ"},
+		"Nagaland":          {"NL"},
+		"Odisha":            {"OD", "OR", "ORISSA"},
+		"Puducherry":        {"PY", "PONDICHERRY"},
+		"Punjab":            {"PB"},
+		"Rajasthan":         {"RJ"},
+		"Sikkim":            {"SK"},
+		"Tamil Nadu":        {"TN"},
+		"Telangana":         {"TG", "TS"},
+		"Tripura":           {"TR"},
+		"Uttar Pradesh":     {"UP"},
+		"Uttarakhand":       {"UK", "UT", "UTTARANCHAL"},
+		"West Bengal":       {"WB"},
+	}
+	for name, aliases := range canonical {
+		stateNames[strings.ToUpper(name)] = name
+		for _, alias := range aliases {
+			stateNames[alias] = name
+		}
+	}
+}
+
+// normalizeAddress returns a standardised copy of an address: whitespace is
+// collapsed, street abbreviations expanded, names case-folded to title case,
+// known state spellings canonicalised and a missing state filled from the
+// pincode when the pincode identifies a single state. Countries are resolved
+// to ISO 3166-1 alpha-2 codes.
+func normalizeAddress(raw Address) Address {
+	addr := Address{
+		Street:  normalizeStreet(raw.Street),
+		City:    normalizeCity(raw.City),
+		State:   normalizeState(raw.State),
+		Pincode: strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(raw.Pincode)),
+	}
+	addr.Country, _ = normalizeCountry(raw.Country)
+
+	if addr.State == "" && isIndianAddress(addr) {
+		states := statesForPincode(addr.Pincode)
+		if len(states) == 1 {
+			addr.State = states[0]
+		}
+	}
+
+	return addr
+}
+
+// isIndianAddress reports whether a normalized address is in India
+func isIndianAddress(addr Address) bool {
+	return addr.Country == "IN"
+}
+
+func normalizeStreet(street string) string {
+	words := strings.Fields(strings.Trim(street, " ,"))
+	for i, word := range words {
+		trailing := ""
+		if strings.HasSuffix(word, ",") {
+			trailing = ","
+			word = strings.TrimSuffix(word, ",")
+		}
+		if expanded, ok := streetAbbreviations[strings.ToUpper(strings.TrimSuffix(word, "."))]; ok {
+			words[i] = expanded + trailing
+			continue
+		}
+		words[i] = titleWord(word) + trailing
+	}
+	return strings.Join(words, " ")
+}
+
+func normalizeCity(city string) string {
+	city = collapseSpaces(city)
+	if alias, ok := cityAliases[strings.ToUpper(city)]; ok {
+		return alias
+	}
+	return titleCase(city)
+}
+
+func normalizeState(state string) string {
+	state = collapseSpaces(strings.TrimSuffix(strings.TrimSpace(state), "."))
+	if name, ok := stateNames[strings.ToUpper(state)]; ok {
+		return name
+	}
+	return titleCase(state)
+}
+
+func collapseSpaces(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+func titleCase(value string) string {
+	words := strings.Fields(value)
+	for i, word := range words {
+		words[i] = titleWord(word)
+	}
+	return strings.Join(words, " ")
+}
+
+// titleWord capitalises a word, upper-casing it entirely when it contains
+// digits so that house numbers such as "12b" become "12B"
+func titleWord(word string) string {
+	if strings.IndexFunc(word, unicode.IsDigit) >= 0 {
+		return strings.ToUpper(word)
+	}
+	runes := []rune(strings.ToLower(word))
+	if len(runes) > 0 {
+		runes[0] = unicode.ToUpper(runes[0])
+	}
+	return string(runes)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// notificationEvents are the events a customer can be notified of
+var notificationEvents = map[string]bool{"KYCVerified": true, "KYCRejected": true, "KYCExpiringSoon": true}
+
+// NotificationPreferences records how the customer of a KYC record has asked
+// to be told about its progress. Records without preferences are notified by
+// email only.
+type NotificationPreferences struct {
+	Email     bool     `json:"email"`
+	SMS       bool     `json:"sms"`
+	Muted     []string `json:"muted,omitempty" metadata:",optional"`    // events the customer does not want to hear about
+	Language  string   `json:"language,omitempty" metadata:",optional"` // BCP 47 tag of the preferred template language
+	UpdatedAt string   `json:"updatedAt"`
+	UpdatedBy string   `json:"updatedBy"`
+}
+
+// SetNotificationPreferences replaces a record's notification preferences.
+// Only the organisation that submitted the record may change them, since it
+// holds the customer relationship the preferences were captured through.
+func (s *SmartContract) SetNotificationPreferences(ctx contractapi.TransactionContextInterface, kycID string, preferencesData string) error {
+	var preferences NotificationPreferences
+	err := json.Unmarshal([]byte(preferencesData), &preferences)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal notification preferences: %v", err)
+	}
+	for _, event := range preferences.Muted {
+		if !notificationEvents[event] {
+			return fmt.Errorf("invalid notification event %q; expected KYCVerified, KYCRejected or KYCExpiringSoon", event)
+		}
+	}
+	if len(preferences.Language) > 35 {
+		return fmt.Errorf("invalid notification language %q", preferences.Language)
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	if kyc.OwnerMSP != "" && kyc.OwnerMSP != mspID {
+		return fmt.Errorf("KYC record %s is owned by %s", kycID, kyc.OwnerMSP)
+	}
+
+	performedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	kyc.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+	preferences.UpdatedAt = kyc.UpdatedAt
+	preferences.UpdatedBy = performedBy
+	kyc.Notifications = &preferences
+
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return err
+	}
+	err = putRecordState(ctx, kyc.ID, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update KYC record: %v", err)
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-NOTIFICATIONS_UPDATED-%d", kyc.ID, s.txTime(ctx).Unix()),
+		KYCID:       kyc.ID,
+		Action:      "NOTIFICATIONS_UPDATED",
+		PerformedBy: performedBy,
+		PerformedAt: kyc.UpdatedAt,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"email": preferences.Email,
+			"sms":   preferences.SMS,
+			"muted": preferences.Muted,
+		},
+	}
+
+	return s.createHistoryEntry(ctx, historyEntry)
+}
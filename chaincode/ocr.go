@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Outcomes of comparing an OCR-extracted field with the declared value
+const (
+	OCRMatch      = "MATCH"
+	OCRMismatch   = "MISMATCH"
+	OCRUnreadable = "UNREADABLE"
+)
+
+var ocrOutcomes = map[string]bool{OCRMatch: true, OCRMismatch: true, OCRUnreadable: true}
+
+// ocrFlaggedFields are the declared fields an OCR mismatch on flags the record for
+var ocrFlaggedFields = []string{"name", "dateOfBirth"}
+
+var ocrFieldPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.]{0,63}$`)
+
+// OCRResult is the outcome of an off-chain comparison of the fields OCR
+// extracted from a document with the values declared on the record. Only a
+// hash of the extracted fields is kept on the ledger.
+type OCRResult struct {
+	DocumentID          string            `json:"documentId"`
+	ExtractedFieldsHash string            `json:"extractedFieldsHash"` // hex SHA-256 of the extracted fields as the OCR service serialised them
+	MatchReport         map[string]string `json:"matchReport"`         // MATCH, MISMATCH or UNREADABLE by declared field, such as name or dateOfBirth
+	RecordedBy          string            `json:"recordedBy"`
+	RecordedAt          string            `json:"recordedAt"`
+	TxID                string            `json:"txId"`
+	Review              string            `json:"review,omitempty" metadata:",optional"` // PENDING_REVIEW once its document is replaced
+}
+
+// RecordOCRResult stores the OCR comparison result of one of a record's
+// documents, replacing any earlier result for the document, and flags the
+// record when the extracted name or date of birth disagrees with the
+// declared one. The flag is weighted by the OCR_MISMATCH risk factor from
+// the next risk recalculation.
+func (s *SmartContract) RecordOCRResult(ctx contractapi.TransactionContextInterface, kycID string, docID string, extractedFieldsHash string, matchReport string) error {
+	err := requireAttribute(ctx, AttrVerifier)
+	if err != nil {
+		return err
+	}
+
+	extractedFieldsHash = strings.ToLower(extractedFieldsHash)
+	if !sha256HexPattern.MatchString(extractedFieldsHash) {
+		return fmt.Errorf("extracted fields hash must be a hex SHA-256 digest")
+	}
+	var report map[string]string
+	err = json.Unmarshal([]byte(matchReport), &report)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal match report: %v", err)
+	}
+	if len(report) == 0 {
+		return fmt.Errorf("match report must cover at least one field")
+	}
+	for field, outcome := range report {
+		if !ocrFieldPattern.MatchString(field) {
+			return fmt.Errorf("invalid match report field %q", field)
+		}
+		report[field] = strings.ToUpper(outcome)
+		if !ocrOutcomes[report[field]] {
+			return fmt.Errorf("match report outcome for %s must be %s, %s or %s", field, OCRMatch, OCRMismatch, OCRUnreadable)
+		}
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, document := range kyc.DocumentHashes {
+		if document.ID == docID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("KYC record %s has no document %s", kycID, docID)
+	}
+
+	recordedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	before := *kyc
+	kyc.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+	result := OCRResult{
+		DocumentID:          docID,
+		ExtractedFieldsHash: extractedFieldsHash,
+		MatchReport:         report,
+		RecordedBy:          recordedBy,
+		RecordedAt:          kyc.UpdatedAt,
+		TxID:                ctx.GetStub().GetTxID(),
+	}
+	replaced := false
+	results := []OCRResult{}
+	for _, existing := range kyc.OCRResults {
+		if existing.DocumentID == docID {
+			existing, replaced = result, true
+		}
+		results = append(results, existing)
+	}
+	if !replaced {
+		results = append(results, result)
+	}
+	kyc.OCRResults = results
+
+	mismatched := []string{}
+	for _, field := range ocrFlaggedFields {
+		if report[field] == OCRMismatch {
+			mismatched = append(mismatched, field)
+		}
+	}
+	if len(mismatched) > 0 {
+		addFlag(kyc, FlagOCRMismatch, fmt.Sprintf("OCR of document %s disagrees with the declared %s", docID, strings.Join(mismatched, " and ")), kyc.UpdatedAt)
+	}
+
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return err
+	}
+	err = putRecordState(ctx, kycID, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update KYC record: %v", err)
+	}
+	err = updateRecordCounters(ctx, &before, kyc)
+	if err != nil {
+		return fmt.Errorf("failed to update counters: %v", err)
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-OCR_RECORDED-%d", kycID, s.txTime(ctx).Unix()),
+		KYCID:       kycID,
+		Action:      "OCR_RECORDED",
+		PerformedBy: recordedBy,
+		PerformedAt: kyc.UpdatedAt,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"documentId":          docID,
+			"extractedFieldsHash": extractedFieldsHash,
+			"mismatched":          mismatched,
+			"replaced":            replaced,
+		},
+	}
+
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return nil
+}
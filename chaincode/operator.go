@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Operators. An application such as the back-office gateway submits for
+// many people under one Fabric identity. A caller whose certificate carries
+// kyc.gateway=true names the person it acts for in the operator transient
+// field, and the contract records the caller as "<identity>#<operator>", so
+// history names who acted and dual approval needs two operators rather
+// than two identities. The application is trusted to have authenticated
+// the operator; the field is ignored from callers without the attribute,
+// who are recorded as themselves.
+const (
+	operatorTransientKey = "operator"
+	operatorSeparator    = "#"
+)
+
+var operatorPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._@+-]{0,127}$`)
+
+// transientOperator returns the operator a gateway caller acts for, or an
+// empty string when the caller is not a gateway or names no operator
+func transientOperator(ctx contractapi.TransactionContextInterface) (string, error) {
+	gateway, err := hasAttribute(ctx, AttrGateway)
+	if err != nil || !gateway {
+		return "", err
+	}
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return "", fmt.Errorf("failed to read transient data: %v", err)
+	}
+	operator := string(transient[operatorTransientKey])
+	if operator != "" && !operatorPattern.MatchString(operator) {
+		return "", fmt.Errorf("invalid operator %q", operator)
+	}
+	return operator, nil
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// patchableFields are the record fields PatchKYC can change: the details the
+// submitter supplies. Status, verification, screening results, risk and
+// timestamps are only changed by the transactions that own them, and
+// extensions, tags, nominees and notification preferences by their own
+// setters.
+var patchableFields = map[string]bool{
+	"userId":         true,
+	"name":           true,
+	"email":          true,
+	"phone":          true,
+	"pan":            true,
+	"dateOfBirth":    true,
+	"address":        true,
+	"entityDetails":  true,
+	"documentHashes": true,
+}
+
+// patchableStatuses are the statuses in which a record's details can be
+// patched; a decided record keeps the details it was decided on
+var patchableStatuses = map[string]bool{
+	"PENDING": true,
+	"ON_HOLD": true,
+}
+
+// PatchKYC applies a JSON merge patch (RFC 7386) to the details of a record:
+// members of patchJSON replace the record's, objects such as the address are
+// merged member by member, and null removes a member. Only the fields in
+// patchableFields can be patched. The patched record is validated as
+// CreateKYC validates a new one, and screened again for the identifiers the
+// patch changes, which can block it. Documents it replaces or adds on a
+// record that has been verified are marked for review. The history entry
+// lists the fields that changed but not their values, which stay in the
+// record's ledger history. Only the organisation that submitted the record
+// can patch it.
+func (s *SmartContract) PatchKYC(ctx contractapi.TransactionContextInterface, id string, patchJSON string) error {
+	err := requireAllowedMSP(ctx, AllowlistSubmitter)
+	if err != nil {
+		return err
+	}
+	kyc, err := s.readKYC(ctx, id)
+	if err != nil {
+		return err
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	if kyc.OwnerMSP != "" && kyc.OwnerMSP != mspID {
+		return fmt.Errorf("KYC record %s is owned by %s", id, kyc.OwnerMSP)
+	}
+	if !patchableStatuses[kyc.Status] {
+		return fmt.Errorf("KYC record %s is %s and its details can no longer be patched", id, kyc.Status)
+	}
+
+	var patch map[string]interface{}
+	err = decodeJSONNumbers([]byte(patchJSON), &patch)
+	if err != nil || patch == nil {
+		return fmt.Errorf("patch must be a JSON object")
+	}
+	for field := range patch {
+		if !patchableFields[field] {
+			return fmt.Errorf("field %s cannot be patched", field)
+		}
+		if patch[field] != nil && kyc.EncryptedFields[field] != nil {
+			return fmt.Errorf("field %s is encrypted and must be left empty", field)
+		}
+		if kyc.PrivateData != nil && isPrivateField(field) {
+			return fmt.Errorf("field %s of KYC record %s is held in private data and cannot be patched", field, id)
+		}
+	}
+
+	before, err := recordFields(kyc)
+	if err != nil {
+		return err
+	}
+	merged := mergePatch(before, patch).(map[string]interface{})
+	mergedJSON, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	var patched KYCRecord
+	decoder := json.NewDecoder(bytes.NewReader(mergedJSON))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&patched)
+	if err != nil {
+		return fmt.Errorf("failed to apply patch: %v", err)
+	}
+
+	if _, ok := patch["address"]; ok {
+		normalizeRecordAddress(&patched)
+	}
+	if patched.Phone != "" && patched.Phone != kyc.Phone {
+		patched.Phone, err = normalizePhone(patched.Phone, patched.Address.Country)
+		if err != nil {
+			return err
+		}
+	}
+	if patched.DocumentHashes == nil {
+		patched.DocumentHashes = []DocumentHash{}
+	}
+	err = validateKYCRecord(&patched)
+	if err != nil {
+		return err
+	}
+	now := s.txTime(ctx).Format(time.RFC3339)
+	marked := reviewChangedDocuments(kyc.DocumentHashes, &patched, now)
+
+	after, err := recordFields(&patched)
+	if err != nil {
+		return err
+	}
+	changed := changedFields("", before, after)
+	if len(changed) == 0 {
+		return nil
+	}
+	changes := map[string]bool{}
+	for _, path := range changed {
+		changes[strings.SplitN(path, ".", 2)[0]] = true
+	}
+
+	patched.UpdatedAt = now
+	if changes["address"] {
+		removeFlag(&patched, FlagPincodeStateMismatch)
+		checkPincodeState(&patched, now)
+	}
+	if changes["email"] {
+		removeFlag(&patched, FlagBlockedEmailDomain)
+		err = screenEmailDomain(ctx, &patched, now)
+		if err != nil {
+			return err
+		}
+	}
+	if changes["phone"] {
+		removeFlag(&patched, FlagDuplicatePhone)
+		if patched.Phone != "" {
+			samePhone, err := getIndexedIDs(ctx, phoneIndex, phoneHash(patched.Phone))
+			if err != nil {
+				return err
+			}
+			if len(samePhone) > 0 {
+				addFlag(&patched, FlagDuplicatePhone, fmt.Sprintf("phone number already registered on %d other record(s)", len(samePhone)), now)
+			}
+		}
+	}
+	if changes["pan"] || changes["name"] || changes["dateOfBirth"] {
+		err = screenBlacklist(ctx, &patched, now)
+		if err != nil {
+			return err
+		}
+		if patched.Status == "BLOCKED" {
+			patched.Hold = nil
+		}
+	}
+	riskRules, err := loadRiskRules(ctx)
+	if err != nil {
+		return err
+	}
+	assessRisk(&patched, riskRules)
+	hookResults, err := runValidationHooks(ctx, &patched, HookOperationPatch)
+	if err != nil {
+		return err
+	}
+
+	kycJSON, err := json.Marshal(patched)
+	if err != nil {
+		return err
+	}
+	err = putRecordState(ctx, id, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update KYC record: %v", err)
+	}
+	if patched.Address != kyc.Address {
+		err = deleteAddressIndexes(ctx, kyc)
+		if err == nil {
+			err = putAddressIndexes(ctx, &patched)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to index KYC record: %v", err)
+		}
+	}
+	if patched.Phone != kyc.Phone {
+		if kyc.Phone != "" {
+			err = deleteIndexEntry(ctx, phoneIndex, id, phoneHash(kyc.Phone))
+			if err != nil {
+				return fmt.Errorf("failed to index KYC record: %v", err)
+			}
+		}
+		if patched.Phone != "" {
+			err = putIndexEntry(ctx, phoneIndex, id, phoneHash(patched.Phone))
+			if err != nil {
+				return fmt.Errorf("failed to index KYC record: %v", err)
+			}
+		}
+	}
+	err = updateDocumentReviewIndex(ctx, kyc, &patched)
+	if err != nil {
+		return fmt.Errorf("failed to index KYC record: %v", err)
+	}
+	err = updateRecordCounters(ctx, kyc, &patched)
+	if err != nil {
+		return fmt.Errorf("failed to update counters: %v", err)
+	}
+
+	patchedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-PATCHED-%d", id, s.txTime(ctx).Unix()),
+		KYCID:       id,
+		Action:      "PATCHED",
+		PerformedBy: patchedBy,
+		PerformedAt: now,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"changedFields": changed,
+			"status":        patched.Status,
+			"riskTier":      patched.RiskTier,
+			"riskScore":     patched.RiskScore,
+		},
+	}
+	if len(marked) > 0 {
+		historyEntry.Details["documentsAwaitingReview"] = marked
+	}
+	if len(hookResults) > 0 {
+		historyEntry.Details["validationHooks"] = hookResults
+	}
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return nil
+}
+
+// decodeJSONNumbers decodes JSON keeping numbers as json.Number, so values
+// pass through a merge unchanged
+func decodeJSONNumbers(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// recordFields returns a record as a generic JSON object
+func recordFields(kyc *KYCRecord) (map[string]interface{}, error) {
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	err = decodeJSONNumbers(kycJSON, &fields)
+	if err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// mergePatch applies an RFC 7386 merge patch to target
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	merged := make(map[string]interface{}, len(targetObject))
+	for name, value := range targetObject {
+		merged[name] = value
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(merged, name)
+			continue
+		}
+		merged[name] = mergePatch(merged[name], value)
+	}
+	return merged
+}
+
+// changedFields returns the sorted dotted paths of the patchable fields that
+// differ between two records' JSON objects, descending into objects and
+// treating arrays as single values
+func changedFields(prefix string, before map[string]interface{}, after map[string]interface{}) []string {
+	names := map[string]bool{}
+	for name := range before {
+		names[name] = true
+	}
+	for name := range after {
+		names[name] = true
+	}
+
+	changed := []string{}
+	for name := range names {
+		if prefix == "" && !patchableFields[name] {
+			continue
+		}
+		beforeObject, beforeIsObject := before[name].(map[string]interface{})
+		afterObject, afterIsObject := after[name].(map[string]interface{})
+		switch {
+		case beforeIsObject && afterIsObject:
+			changed = append(changed, changedFields(prefix+name+".", beforeObject, afterObject)...)
+		case !reflect.DeepEqual(before[name], after[name]):
+			changed = append(changed, prefix+name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const phoneIndex = "phone~kycid"
+
+var (
+	e164Pattern         = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+	indianMobilePattern = regexp.MustCompile(`^[6-9][0-9]{9}$`)
+)
+
+// DuplicatePhoneGroup lists the records sharing one phone number
+type DuplicatePhoneGroup struct {
+	PhoneHash string   `json:"phoneHash"`
+	Count     int      `json:"count"`
+	KYCIDs    []string `json:"kycIds"`
+}
+
+// DuplicatePhonePage is one page of the duplicate phone report
+type DuplicatePhonePage struct {
+	Groups   []*DuplicatePhoneGroup `json:"groups"`
+	Bookmark string                 `json:"bookmark"` // empty once the whole index has been scanned
+}
+
+// normalizePhone converts a phone number to E.164. Numbers without an
+// international prefix are only accepted for Indian addresses, where a
+// 10-digit mobile number (optionally with a trunk 0 or 91 prefix) is assumed.
+func normalizePhone(phone string, country string) (string, error) {
+	cleaned := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "").Replace(strings.TrimSpace(phone))
+	if strings.HasPrefix(cleaned, "00") {
+		cleaned = "+" + strings.TrimPrefix(cleaned, "00")
+	}
+
+	if !strings.HasPrefix(cleaned, "+") && country == "IN" {
+		cleaned = strings.TrimPrefix(cleaned, "0")
+		if len(cleaned) == 12 && strings.HasPrefix(cleaned, "91") {
+			cleaned = cleaned[2:]
+		}
+		if !indianMobilePattern.MatchString(cleaned) {
+			return "", fmt.Errorf("invalid Indian mobile number %q", phone)
+		}
+		cleaned = "+91" + cleaned
+	}
+
+	if !e164Pattern.MatchString(cleaned) {
+		return "", fmt.Errorf("phone number %q must be in international format with a country calling code", phone)
+	}
+	return cleaned, nil
+}
+
+// phoneHash returns the index hash of an E.164 phone number
+func phoneHash(e164 string) string {
+	return identifierHash(e164)
+}
+
+// GetKYCByPhone returns the KYC records registered with a phone number
+func (s *SmartContract) GetKYCByPhone(ctx contractapi.TransactionContextInterface, phone string, purposeCode string) ([]*KYCRecord, error) {
+	purpose, err := newReadPurpose(ctx, "GetKYCByPhone", purposeCode)
+	if err != nil {
+		return nil, err
+	}
+	e164, err := normalizePhone(phone, defaultCountry)
+	if err != nil {
+		return nil, err
+	}
+
+	kycIDs, err := getIndexedIDs(ctx, phoneIndex, phoneHash(e164))
+	if err != nil {
+		return nil, err
+	}
+
+	kycRecords := []*KYCRecord{}
+	for _, kycID := range kycIDs {
+		kyc, err := s.readKYC(ctx, kycID)
+		if err != nil {
+			return nil, err
+		}
+		kycRecords = append(kycRecords, kyc)
+	}
+
+	return purpose.filter(ctx, kycRecords)
+}
+
+// GetDuplicatePhoneReport returns one page of the phone numbers shared by at
+// least minRecords KYC records, a common indicator of application fraud.
+// Only administrators and regulators may run it. A page scans about pageSize
+// phone index entries and never splits a number's records across pages.
+func (s *SmartContract) GetDuplicatePhoneReport(ctx contractapi.TransactionContextInterface, minRecords int, pageSize int32, bookmark string) (*DuplicatePhonePage, error) {
+	_, err := requireAnyAttribute(ctx, AttrAdmin, AttrRegulator)
+	if err != nil {
+		return nil, err
+	}
+	err = checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	if minRecords < 2 {
+		minRecords = 2
+	}
+
+	// index keys are ordered by hash, so records sharing a number are
+	// adjacent; the rate limit's bucket is a write, which rules out
+	// paginated queries
+	page := &DuplicatePhonePage{Groups: []*DuplicatePhoneGroup{}}
+	var current *DuplicatePhoneGroup
+	_, done, err := scanCompositeKeys(ctx, phoneIndex, bookmark, int(pageSize), func(key string, value []byte) (bool, error) {
+		_, keyParts, err := ctx.GetStub().SplitCompositeKey(key)
+		if err != nil {
+			return false, err
+		}
+
+		if current == nil || current.PhoneHash != keyParts[0] {
+			if current != nil && current.Count >= minRecords {
+				page.Groups = append(page.Groups, current)
+			}
+			current = &DuplicatePhoneGroup{PhoneHash: keyParts[0]}
+		}
+		current.Count++
+		current.KYCIDs = append(current.KYCIDs, keyParts[1])
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return page, nil
+	}
+
+	if !done {
+		// the page may have ended inside the last number's records, so read
+		// the rest of them and resume after its last index entry
+		current.KYCIDs, err = getIndexedIDs(ctx, phoneIndex, current.PhoneHash)
+		if err != nil {
+			return nil, err
+		}
+		current.Count = len(current.KYCIDs)
+		page.Bookmark, err = ctx.GetStub().CreateCompositeKey(phoneIndex, []string{current.PhoneHash, current.KYCIDs[current.Count-1]})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if current.Count >= minRecords {
+		page.Groups = append(page.Groups, current)
+	}
+	return page, nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// photoObjectType keys the applicant photograph of a record,
+// "PHOTO~<kycID>". It is kept apart from the record so that only custodians
+// and regulators can read it.
+const photoObjectType = "PHOTO"
+
+// Photo references the applicant photograph of a record, stored off-chain
+type Photo struct {
+	KYCID        string `json:"kycId"`
+	PhotoHash    string `json:"photoHash"`    // hex SHA-256 of the photograph
+	PhotoIPFSRef string `json:"photoIpfsRef"` // IPFS CID of the encrypted photograph
+	StoredBy     string `json:"storedBy"`
+	StoredAt     string `json:"storedAt"`
+	TxID         string `json:"txId"`
+}
+
+// SetPhoto stores the hash and IPFS reference of a record's applicant
+// photograph, replacing any earlier one. Only the organisation that
+// submitted the record can set it.
+func (s *SmartContract) SetPhoto(ctx contractapi.TransactionContextInterface, kycID string, photoHash string, photoIPFSRef string) error {
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	if kyc.OwnerMSP != "" && kyc.OwnerMSP != mspID {
+		return fmt.Errorf("KYC record %s is owned by %s", kycID, kyc.OwnerMSP)
+	}
+
+	photoHash = strings.ToLower(photoHash)
+	if !sha256HexPattern.MatchString(photoHash) {
+		return fmt.Errorf("photo hash must be a hex SHA-256 digest")
+	}
+	if photoIPFSRef == "" {
+		return fmt.Errorf("photo IPFS reference is required")
+	}
+	storedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(photoObjectType, []string{kycID})
+	if err != nil {
+		return err
+	}
+	existing, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	photo := Photo{
+		KYCID:        kycID,
+		PhotoHash:    photoHash,
+		PhotoIPFSRef: photoIPFSRef,
+		StoredBy:     storedBy,
+		StoredAt:     s.txTime(ctx).Format(time.RFC3339),
+		TxID:         ctx.GetStub().GetTxID(),
+	}
+	photoJSON, err := json.Marshal(photo)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, photoJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put photo: %v", err)
+	}
+
+	// the history is readable by anyone who can read the record, so it
+	// notes the change without the photograph's hash or reference
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-PHOTO_STORED-%d", kycID, s.txTime(ctx).Unix()),
+		KYCID:       kycID,
+		Action:      "PHOTO_STORED",
+		PerformedBy: storedBy,
+		PerformedAt: photo.StoredAt,
+		TxID:        photo.TxID,
+		Details: map[string]interface{}{
+			"replaced": existing != nil,
+		},
+	}
+
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return nil
+}
+
+// GetPhoto returns the applicant photograph reference of a record to a
+// custodian or regulator and logs the retrieval in the record's history.
+// It writes the log, so it must be submitted: an evaluated call is answered
+// without the log ever being committed, and is why GetPhoto is not tagged
+// for evaluation.
+func (s *SmartContract) GetPhoto(ctx contractapi.TransactionContextInterface, kycID string) (*Photo, error) {
+	role, err := requireAnyAttribute(ctx, AttrCustodian, AttrRegulator)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(photoObjectType, []string{kycID})
+	if err != nil {
+		return nil, err
+	}
+	photoJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if photoJSON == nil {
+		return nil, fmt.Errorf("KYC record %s has no photo", kycID)
+	}
+	var photo Photo
+	err = json.Unmarshal(photoJSON, &photo)
+	if err != nil {
+		return nil, err
+	}
+
+	accessedBy, err := clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-PHOTO_ACCESSED-%d", kycID, s.txTime(ctx).Unix()),
+		KYCID:       kycID,
+		Action:      "PHOTO_ACCESSED",
+		PerformedBy: accessedBy,
+		PerformedAt: s.txTime(ctx).Format(time.RFC3339),
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"role":  role,
+			"msp":   mspID,
+			"photo": photo.TxID, // the transaction that stored the photograph retrieved
+		},
+	}
+
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return &photo, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var pincodePattern = regexp.MustCompile(`^[1-9][0-9]{5}$`)
+
+// pincodeStates maps the first two digits of an Indian PIN code to the states
+// and union territories served by that postal region. Some regions straddle
+// more than one state, so a prefix can map to several names.
+var pincodeStates = map[string][]string{
+	"11": {"Delhi"},
+	"12": {"Haryana"},
+	"13": {"Haryana"},
+	"14": {"Punjab"},
+	"15": {"Punjab"},
+	"16": {"Punjab", "Chandigarh"},
+	"17": {"Himachal Pradesh"},
+	"18": {"Jammu and Kashmir"},
+	"19": {"Jammu and Kashmir", "Ladakh"},
+	"20": {"Uttar Pradesh"},
+	"21": {"Uttar Pradesh"},
+	"22": {"Uttar Pradesh"},
+	"23": {"Uttar Pradesh"},
+	"24": {"Uttar Pradesh", "Uttarakhand"},
+	"25": {"Uttar Pradesh"},
+	"26": {"Uttar Pradesh", "Uttarakhand"},
+	"27": {"Uttar Pradesh"},
+	"28": {"Uttar Pradesh"},
+	"30": {"Rajasthan"},
+	"31": {"Rajasthan"},
+	"32": {"Rajasthan"},
+	"33": {"Rajasthan"},
+	"34": {"Rajasthan"},
+	"36": {"Gujarat", "Dadra and Nagar Haveli and Daman and Diu"},
+	"37": {"Gujarat"},
+	"38": {"Gujarat"},
+	"39": {"Gujarat", "Dadra and Nagar Haveli and Daman and Diu"},
+	"40": {"Maharashtra", "Goa"},
+	"41": {"Maharashtra"},
+	"42": {"Maharashtra"},
+	"43": {"Maharashtra"},
+	"44": {"Maharashtra"},
+	"45": {"Madhya Pradesh"},
+	"46": {"Madhya Pradesh"},
+	"47": {"Madhya Pradesh"},
+	"48": {"Madhya Pradesh"},
+	"49": {"Chhattisgarh"},
+	"50": {"Telangana", "Andhra Pradesh"},
+	"51": {"Andhra Pradesh", "Telangana"},
+	"52": {"Andhra Pradesh"},
+	"53": {"Andhra Pradesh"},
+	"56": {"Karnataka"},
+	"57": {"Karnataka"},
+	"58": {"Karnataka"},
+	"59": {"Karnataka"},
+	"60": {"Tamil Nadu", "Puducherry"},
+	"61": {"Tamil Nadu"},
+	"62": {"Tamil Nadu"},
+	"63": {"Tamil Nadu"},
+	"64": {"Tamil Nadu"},
+	"67": {"Kerala", "Puducherry"},
+	"68": {"Kerala", "Lakshadweep"},
+	"69": {"Kerala"},
+	"70": {"West Bengal"},
+	"71": {"West Bengal"},
+	"72": {"West Bengal"},
+	"73": {"West Bengal", "Sikkim"},
+	"74": {"West Bengal", "Andaman and Nicobar Islands"},
+	"75": {"Odisha"},
+	"76": {"Odisha"},
+	"77": {"Odisha"},
+	"78": {"Assam"},
+	"79": {"Arunachal Pradesh", "Assam", "Manipur", "Meghalaya", "Mizoram", "Nagaland", "Tripura"},
+	"80": {"Bihar", "Jharkhand"},
+	"81": {"Bihar", "Jharkhand"},
+	"82": {"Bihar", "Jharkhand"},
+	"83": {"Jharkhand"},
+	"84": {"Bihar"},
+	"85": {"Bihar"},
+}
+
+// statesForPincode returns the states a valid Indian PIN code can belong to
+func statesForPincode(pincode string) []string {
+	if !pincodePattern.MatchString(pincode) {
+		return nil
+	}
+	return pincodeStates[pincode[:2]]
+}
+
+// checkPincodeState flags an Indian address whose state is not served by its
+// pincode's postal region. Unknown regions are left unflagged.
+func checkPincodeState(kyc *KYCRecord, raisedAt string) {
+	addr := kyc.Address
+	if addr.Pincode == "" || addr.State == "" || !isIndianAddress(addr) {
+		return
+	}
+
+	states := statesForPincode(addr.Pincode)
+	if len(states) == 0 {
+		return
+	}
+	for _, state := range states {
+		if state == addr.State {
+			return
+		}
+	}
+
+	addFlag(kyc, FlagPincodeStateMismatch, fmt.Sprintf("pincode %s belongs to %s, not %s", addr.Pincode, strings.Join(states, "/"), addr.State), raisedAt)
+}
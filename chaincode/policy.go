@@ -0,0 +1,303 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// Consortium policy. The business policies every member organisation must
+// apply alike - whether verification needs a second verifier, whether it
+// needs the owner's consent, how long decided records are retained and the
+// verification SLA - are kept by the PolicyConfig contract as numbered
+// versions, each taking effect from a date fixed when it is published. A
+// version published ahead of its effective date gives every organisation
+// notice of the change, and superseded versions stay on the ledger, so the
+// policy a decision was made under can be told afterwards. Until the first
+// version takes effect the equivalent settings of the contract configuration
+// apply, as version 0.
+//
+// Versions are stored under "POLICY~<version>", the version zero-padded so
+// they iterate in order.
+const policyObjectType = "POLICY"
+
+// PolicyConfig is the contract holding the consortium policy. Its functions
+// are called as PolicyConfig:<function>.
+type PolicyConfig struct {
+	contractapi.Contract
+}
+
+// GetEvaluateTransactions lists the PolicyConfig functions that only read
+// the ledger
+func (p *PolicyConfig) GetEvaluateTransactions() []string {
+	return []string{
+		"GetPolicy",
+		"GetPolicyAt",
+		"GetPolicyVersions",
+	}
+}
+
+// ConsortiumPolicy is one version of the consortium policy
+type ConsortiumPolicy struct {
+	Version        int              `json:"version"`
+	EffectiveFrom  string           `json:"effectiveFrom"`
+	DualApproval   bool             `json:"dualApproval"`   // verification needs the approval of two different verifiers
+	RequireConsent bool             `json:"requireConsent"` // verification needs a current consent receipt from the owner
+	Retention      RetentionPeriods `json:"retention"`      // days decided records are kept unchanged before they can be archived
+	SLAHours       int              `json:"slaHours"`       // hours from submission within which a record must be decided
+	Description    string           `json:"description,omitempty" metadata:",optional"`
+	PublishedBy    string           `json:"publishedBy,omitempty" metadata:",optional"`
+	PublisherMSP   string           `json:"publisherMsp,omitempty" metadata:",optional"`
+	PublishedAt    string           `json:"publishedAt,omitempty" metadata:",optional"`
+	TxID           string           `json:"txId,omitempty" metadata:",optional"`
+}
+
+// RetentionPeriods are the days a record must go unchanged in each
+// archivable status before it can be archived
+type RetentionPeriods struct {
+	Rejected int `json:"rejected"`
+	Expired  int `json:"expired"`
+}
+
+// days returns the retention period of an archivable status
+func (p RetentionPeriods) days(status string) int {
+	if status == "EXPIRED" {
+		return p.Expired
+	}
+	return p.Rejected
+}
+
+// VerificationApproval is the first approval of a record awaiting
+// verification under dual approval; a different verifier's VERIFIED decision
+// completes it
+type VerificationApproval struct {
+	ApprovedBy    string `json:"approvedBy"`
+	ApprovedAt    string `json:"approvedAt"`
+	PolicyVersion int    `json:"policyVersion"`
+	Remarks       string `json:"remarks,omitempty" metadata:",optional"`
+}
+
+// policyInput is the payload accepted by PublishPolicy
+type policyInput struct {
+	DualApproval   *bool             `json:"dualApproval"`
+	RequireConsent *bool             `json:"requireConsent"`
+	Retention      *RetentionPeriods `json:"retention"`
+	SLAHours       *int              `json:"slaHours"`
+	Description    string            `json:"description"`
+}
+
+// PublishPolicy publishes a new version of the consortium policy, effective
+// from effectiveFrom, an RFC 3339 time no earlier than now or than the
+// latest version's, or at once when it is empty. Settings omitted from
+// policyData keep the latest version's values. Only administrators can
+// publish policy.
+func (p *PolicyConfig) PublishPolicy(ctx contractapi.TransactionContextInterface, policyData string, effectiveFrom string) (*ConsortiumPolicy, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var input policyInput
+	err = json.Unmarshal([]byte(policyData), &input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal policy: %v", err)
+	}
+	now := txTime(ctx)
+	from := now
+	if effectiveFrom != "" {
+		from, err = time.Parse(time.RFC3339, effectiveFrom)
+		if err != nil {
+			return nil, fmt.Errorf("effectiveFrom must be an RFC 3339 timestamp")
+		}
+		if from.Before(now.Truncate(time.Second)) {
+			return nil, fmt.Errorf("effectiveFrom must not be in the past")
+		}
+	}
+
+	versions, err := policyVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := basePolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) > 0 {
+		latest = versions[len(versions)-1]
+	}
+	// timestamps are all UTC RFC 3339, so they compare as strings
+	policy := *latest
+	policy.EffectiveFrom = from.UTC().Format(time.RFC3339)
+	if policy.EffectiveFrom < latest.EffectiveFrom {
+		return nil, fmt.Errorf("effectiveFrom must not be before %s, when version %d takes effect", latest.EffectiveFrom, latest.Version)
+	}
+	if input.DualApproval != nil {
+		policy.DualApproval = *input.DualApproval
+	}
+	if input.RequireConsent != nil {
+		policy.RequireConsent = *input.RequireConsent
+	}
+	if input.Retention != nil {
+		policy.Retention = *input.Retention
+	}
+	if input.SLAHours != nil {
+		policy.SLAHours = *input.SLAHours
+	}
+	if policy.Retention.Rejected < 0 || policy.Retention.Expired < 0 {
+		return nil, fmt.Errorf("retention periods must not be negative")
+	}
+	if policy.SLAHours <= 0 {
+		return nil, fmt.Errorf("slaHours must be positive")
+	}
+
+	policy.Version = latest.Version + 1
+	policy.Description = input.Description
+	policy.PublishedBy, err = clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+	policy.PublisherMSP, err = ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	policy.PublishedAt = now.Format(time.RFC3339)
+	policy.TxID = ctx.GetStub().GetTxID()
+
+	key, err := policyKey(ctx, policy.Version)
+	if err != nil {
+		return nil, err
+	}
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(key, policyJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put policy: %v", err)
+	}
+	return &policy, nil
+}
+
+// GetPolicy returns the consortium policy in effect now
+func (p *PolicyConfig) GetPolicy(ctx contractapi.TransactionContextInterface) (*ConsortiumPolicy, error) {
+	return currentPolicy(ctx)
+}
+
+// GetPolicyAt returns the consortium policy in effect at an RFC 3339 time
+func (p *PolicyConfig) GetPolicyAt(ctx contractapi.TransactionContextInterface, at string) (*ConsortiumPolicy, error) {
+	when, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		return nil, fmt.Errorf("time must be an RFC 3339 timestamp")
+	}
+	return effectivePolicy(ctx, when.UTC().Format(time.RFC3339))
+}
+
+// GetPolicyVersions returns every published version of the consortium
+// policy, oldest first, including those not yet in effect
+func (p *PolicyConfig) GetPolicyVersions(ctx contractapi.TransactionContextInterface) ([]*ConsortiumPolicy, error) {
+	return policyVersions(ctx)
+}
+
+// approveVerification records the first of the two approvals dual approval
+// needs, leaving the record PENDING for the second
+func (s *SmartContract) approveVerification(ctx contractapi.TransactionContextInterface, kyc *KYCRecord, policy *ConsortiumPolicy, remarks string) error {
+	approvedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	kyc.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+	kyc.VerificationApproval = &VerificationApproval{
+		ApprovedBy:    approvedBy,
+		ApprovedAt:    kyc.UpdatedAt,
+		PolicyVersion: policy.Version,
+		Remarks:       remarks,
+	}
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return err
+	}
+	err = putRecordState(ctx, kyc.ID, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update KYC record: %v", err)
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-VERIFICATION_APPROVED-%d", kyc.ID, s.txTime(ctx).Unix()),
+		KYCID:       kyc.ID,
+		Action:      "VERIFICATION_APPROVED",
+		PerformedBy: approvedBy,
+		PerformedAt: kyc.UpdatedAt,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"policyVersion": policy.Version,
+		},
+		Remarks: remarks,
+	}
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return nil
+}
+
+// effectivePolicy returns the latest policy version effective at the RFC
+// 3339 time at, or version 0 from the configuration when none is
+func effectivePolicy(ctx contractapi.TransactionContextInterface, at string) (*ConsortiumPolicy, error) {
+	versions, err := policyVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].EffectiveFrom <= at {
+			return versions[i], nil
+		}
+	}
+	return basePolicy(ctx)
+}
+
+// currentPolicy returns the policy in effect now
+func currentPolicy(ctx contractapi.TransactionContextInterface) (*ConsortiumPolicy, error) {
+	return effectivePolicy(ctx, txTime(ctx).Format(time.RFC3339))
+}
+
+// basePolicy returns version 0, the policy the configuration implies
+func basePolicy(ctx contractapi.TransactionContextInterface) (*ConsortiumPolicy, error) {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ConsortiumPolicy{
+		Version:   0,
+		Retention: RetentionPeriods{Rejected: config.ArchiveAfterDays, Expired: config.ArchiveAfterDays},
+		SLAHours:  config.VerificationSLAHours,
+	}, nil
+}
+
+// policyVersions loads every published policy version, oldest first
+func policyVersions(ctx contractapi.TransactionContextInterface) ([]*ConsortiumPolicy, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(policyObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []*ConsortiumPolicy{}
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		var policy ConsortiumPolicy
+		err := json.Unmarshal(queryResponse.Value, &policy)
+		if err != nil {
+			return err
+		}
+		versions = append(versions, &policy)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func policyKey(ctx contractapi.TransactionContextInterface, version int) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(policyObjectType, []string{fmt.Sprintf("%010d", version)})
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Private records. CreateKYCPrivate submits a record whose personal details
+// (privateFields) are passed in the transaction's transient data rather
+// than its arguments, so they are never in a block. The chaincode checks
+// and screens the record with them as CreateKYC would, then stores them in
+// the submitting organisation's implicit private data collection under the
+// record's ID, so only that organisation's peers hold them. The record in
+// world state keeps the fields empty, and its privateData holds a salted
+// hash of each, which the submitter can hand a relying party with the
+// salt and the value for it to check. The salt is client-chosen and only
+// kept in the collection, so the hashes cannot be reversed by trying likely
+// values. GetKYCPrivate returns a record with its details filled in from
+// the collection, and only works on the owner's peers.
+//
+// Private records cannot be found by PAN or email, since the queries match
+// world state, are not indexed or checked for duplicates by phone number,
+// and are not screened against watchlists after submission. PatchKYC
+// cannot change their personal details.
+const (
+	privateDetailsTransientKey = "kycPrivate"
+	minPrivateSaltBytes        = 16
+)
+
+// privateFields are the record fields a private record holds in its
+// organisation's collection
+var privateFields = []string{"name", "email", "phone", "pan", "dateOfBirth"}
+
+// isPrivateField reports whether a private record holds a field in its
+// organisation's collection
+func isPrivateField(field string) bool {
+	for _, private := range privateFields {
+		if field == private {
+			return true
+		}
+	}
+	return false
+}
+
+// PrivateRecordData is where a private record's personal details are held
+type PrivateRecordData struct {
+	Collection string            `json:"collection"`
+	Hashes     map[string]string `json:"hashes"` // hex SHA-256 of the salt, the field name, a zero byte and the value, by field
+}
+
+// PrivateDetails are the personal details of a private record, as passed to
+// CreateKYCPrivate and held in its organisation's collection
+type PrivateDetails struct {
+	Name        string `json:"name"`
+	Email       string `json:"email,omitempty" metadata:",optional"`
+	Phone       string `json:"phone,omitempty" metadata:",optional"`
+	PAN         string `json:"pan"`
+	DateOfBirth string `json:"dateOfBirth,omitempty" metadata:",optional"`
+	Salt        string `json:"salt"` // base64, at least minPrivateSaltBytes bytes
+}
+
+// CreateKYCPrivate creates a KYC record keeping its personal details in the
+// caller's organisation's implicit private data collection. kycData is the
+// record without them; they are passed as PrivateDetails JSON in the
+// kycPrivate transient field.
+func (s *SmartContract) CreateKYCPrivate(ctx contractapi.TransactionContextInterface, kycData string) error {
+	err := requireAllowedMSP(ctx, AllowlistSubmitter)
+	if err != nil {
+		return err
+	}
+	var kyc KYCRecord
+	err = json.Unmarshal([]byte(kycData), &kyc)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal KYC data: %v", err)
+	}
+	values := recordPrivateDetails(&kyc).values()
+	for _, field := range privateFields {
+		if values[field] != "" {
+			return fmt.Errorf("field %s must be passed in the %s transient field", field, privateDetailsTransientKey)
+		}
+	}
+
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("failed to get transient data: %v", err)
+	}
+	detailsJSON, ok := transient[privateDetailsTransientKey]
+	if !ok {
+		return fmt.Errorf("the %s transient field is required", privateDetailsTransientKey)
+	}
+	var details PrivateDetails
+	err = json.Unmarshal(detailsJSON, &details)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal private details: %v", err)
+	}
+	salt, err := base64.StdEncoding.DecodeString(details.Salt)
+	if err != nil || len(salt) < minPrivateSaltBytes {
+		return fmt.Errorf("private details salt must be at least %d bytes, base64 encoded", minPrivateSaltBytes)
+	}
+	details.fill(&kyc)
+	kyc.PrivateData = nil
+	return s.createKYC(ctx, &kyc, salt)
+}
+
+// GetKYCPrivate returns a private record with its personal details, for the
+// purpose given as ReadKYC does. The details are only held on the peers of
+// the organisation that submitted the record.
+func (s *SmartContract) GetKYCPrivate(ctx contractapi.TransactionContextInterface, id string, purposeCode string) (*KYCRecord, error) {
+	purpose, err := newReadPurpose(ctx, "GetKYCPrivate", purposeCode)
+	if err != nil {
+		return nil, err
+	}
+	kyc, err := s.readKYC(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if kyc.PrivateData == nil {
+		return nil, fmt.Errorf("KYC record %s does not keep its details in private data", id)
+	}
+	details, err := readPrivateDetails(ctx, kyc)
+	if err != nil {
+		return nil, err
+	}
+	details.fill(kyc)
+	return purpose.check(ctx, kyc)
+}
+
+// privateCollection is the implicit private data collection of an organisation
+func privateCollection(mspID string) string {
+	return "_implicit_org_" + mspID
+}
+
+// recordPrivateDetails returns the personal details of a record
+func recordPrivateDetails(kyc *KYCRecord) *PrivateDetails {
+	return &PrivateDetails{Name: kyc.Name, Email: kyc.Email, Phone: kyc.Phone, PAN: kyc.PAN, DateOfBirth: kyc.DateOfBirth}
+}
+
+// values returns the details by field name
+func (d *PrivateDetails) values() map[string]string {
+	return map[string]string{
+		"name":        d.Name,
+		"email":       d.Email,
+		"phone":       d.Phone,
+		"pan":         d.PAN,
+		"dateOfBirth": d.DateOfBirth,
+	}
+}
+
+// fill sets a record's personal details to the details
+func (d *PrivateDetails) fill(kyc *KYCRecord) {
+	kyc.Name, kyc.Email, kyc.Phone, kyc.PAN, kyc.DateOfBirth = d.Name, d.Email, d.Phone, d.PAN, d.DateOfBirth
+}
+
+// privateFieldHash is the salted hash of one personal detail
+func privateFieldHash(salt []byte, field string, value string) string {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(field))
+	h.Write([]byte{0})
+	h.Write([]byte(value))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// sealPrivateDetails moves a new record's personal details into its
+// organisation's collection, leaving their salted hashes on the record
+func sealPrivateDetails(ctx contractapi.TransactionContextInterface, kyc *KYCRecord, salt []byte) error {
+	details := recordPrivateDetails(kyc)
+	details.Salt = base64.StdEncoding.EncodeToString(salt)
+	data := &PrivateRecordData{Collection: privateCollection(kyc.OwnerMSP), Hashes: map[string]string{}}
+	for field, value := range details.values() {
+		if value != "" {
+			data.Hashes[field] = privateFieldHash(salt, field, value)
+		}
+	}
+
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutPrivateData(data.Collection, kyc.ID, detailsJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put private details: %v", err)
+	}
+	(&PrivateDetails{}).fill(kyc)
+	kyc.PrivateData = data
+	return nil
+}
+
+// readPrivateDetails loads a private record's personal details from its
+// collection, checking them against the record's hashes
+func readPrivateDetails(ctx contractapi.TransactionContextInterface, kyc *KYCRecord) (*PrivateDetails, error) {
+	detailsJSON, err := ctx.GetStub().GetPrivateData(kyc.PrivateData.Collection, kyc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private details of KYC record %s, which are held by %s: %v", kyc.ID, kyc.OwnerMSP, err)
+	}
+	if detailsJSON == nil {
+		return nil, fmt.Errorf("private details of KYC record %s are not held on this peer", kyc.ID)
+	}
+	var details PrivateDetails
+	err = json.Unmarshal(detailsJSON, &details)
+	if err != nil {
+		return nil, err
+	}
+	salt, err := base64.StdEncoding.DecodeString(details.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("private details of KYC record %s have an invalid salt", kyc.ID)
+	}
+	values := details.values()
+	for _, field := range privateFields {
+		hash := ""
+		if values[field] != "" {
+			hash = privateFieldHash(salt, field, values[field])
+		}
+		if hash != kyc.PrivateData.Hashes[field] {
+			return nil, fmt.Errorf("private %s of KYC record %s does not match its hash", field, kyc.ID)
+		}
+	}
+	return &details, nil
+}
+
+// deletePrivateDetails removes a private record's personal details from its
+// collection
+func deletePrivateDetails(ctx contractapi.TransactionContextInterface, kyc *KYCRecord) error {
+	if kyc.PrivateData == nil {
+		return nil
+	}
+	err := ctx.GetStub().DelPrivateData(kyc.PrivateData.Collection, kyc.ID)
+	if err != nil {
+		return fmt.Errorf("failed to delete private details: %v", err)
+	}
+	return nil
+}
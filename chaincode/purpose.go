@@ -0,0 +1,471 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// Purpose limitation. ReadKYC and the record queries take a purpose code.
+// Callers from the organisation that owns a record read it freely; anyone
+// else must name a purpose an active access grant of their organisation is
+// for (see grant.go), and reads the fields the grant covers. Each record
+// they read is logged under "ACCESS~<kycID>~<tx ID>", and again in time
+// order under "ACCESSAT_<accessed at>_<tx ID>_<kycID>", as are reads of
+// decoys (see decoy.go). Regulators read whole records without a grant,
+// but still name a purpose and are logged. Reads are only logged, and only
+// counted against their grant's maxReads, when the transaction is
+// submitted, so ReadKYC and the record queries are submitted transactions
+// rather than evaluated ones. When the configuration
+// masks personal details (see masking.go), callers whose role is high enough
+// to read them unmasked are logged for every record they read, their own
+// organisation's included.
+//
+// Full listings and exports, GetAllKYC and ExportAll, also log each page
+// they return under "BULKACCESS~<tx ID>", with the number of records or
+// documents on it, whoever the caller.
+//
+// Fabric refuses writes in a transaction that has run a paginated query, so
+// the queries that log page with the key scans of iterator.go, and logging
+// after a paginated query fails rather than losing the log on a peer.
+const (
+	accessLogObjectType     = "ACCESS"
+	accessTimePrefix        = "ACCESSAT_"
+	bulkAccessLogObjectType = "BULKACCESS"
+)
+
+// AccessLogEntry records one read of a record by another organisation, of a
+// decoy, or of personal details that are masked for lower roles
+type AccessLogEntry struct {
+	KYCID       string `json:"kycId"`
+	Function    string `json:"function"`
+	PurposeCode string `json:"purposeCode"`
+	Accessor    string `json:"accessor"`
+	AccessorMSP string `json:"accessorMsp"`
+	GrantID     string `json:"grantId,omitempty" metadata:",optional"` // empty for a regulator's read
+	// the consent the read's grant was issued for, see consentcontract.go
+	ConsentReceiptID string `json:"consentReceiptId,omitempty" metadata:",optional"`
+	AccessedAt       string `json:"accessedAt"`
+	TxID             string `json:"txId"`
+	Unmasked         bool   `json:"unmasked,omitempty" metadata:",optional"` // the read was of details masked for lower roles
+}
+
+// BulkAccessLogEntry records one page of a full listing or export
+type BulkAccessLogEntry struct {
+	Function    string `json:"function"`
+	PurposeCode string `json:"purposeCode,omitempty" metadata:",optional"`
+	Accessor    string `json:"accessor"`
+	AccessorMSP string `json:"accessorMsp"`
+	Bookmark    string `json:"bookmark,omitempty" metadata:",optional"` // where the page started
+	Returned    int    `json:"returned"`                                // records or documents on the page
+	AccessedAt  string `json:"accessedAt"`
+	TxID        string `json:"txId"`
+}
+
+// readPurpose is the caller of a read and the purpose they gave for it
+type readPurpose struct {
+	function  string
+	code      string
+	accessor  string
+	mspID     string
+	regulator bool
+	admin     bool
+	mask      *MaskingProfile // the masking applied to what the caller reads, nil for none
+	unmasked  bool            // the caller reads details masked for lower roles
+	decoys    *DecoyAccess    // the decoys read so far, see decoy.go
+}
+
+// newReadPurpose identifies the caller of function reading for purposeCode
+func newReadPurpose(ctx contractapi.TransactionContextInterface, function string, purposeCode string) (*readPurpose, error) {
+	if purposeCode != "" && !purposeCodePattern.MatchString(purposeCode) {
+		return nil, fmt.Errorf("invalid purpose code %q", purposeCode)
+	}
+	accessor, err := clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	regulator, err := hasAttribute(ctx, AttrRegulator)
+	if err != nil {
+		return nil, err
+	}
+	purpose := &readPurpose{
+		function:  function,
+		code:      purposeCode,
+		accessor:  accessor,
+		mspID:     mspID,
+		regulator: regulator,
+		admin:     ctx.GetClientIdentity().AssertAttributeValue(AttrAdmin, "true") == nil,
+	}
+
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.Masking.Rules) > 0 {
+		purpose.unmasked, err = readsUnmasked(ctx, &config.Masking)
+		if err != nil {
+			return nil, err
+		}
+		if !purpose.unmasked {
+			purpose.mask = &config.Masking
+		}
+	}
+	return purpose, nil
+}
+
+// permits returns what of a record the caller may read for their purpose,
+// or nil when they may not read it, with the grant the read is made under.
+// The caller's own organisation's records are returned whole, as they are
+// to regulators; anyone else sees the fields their grant covers. Either way
+// personal details are masked if the caller's role is below the masking
+// profile's unmasked role.
+func (p *readPurpose) permits(ctx contractapi.TransactionContextInterface, kyc *KYCRecord) (*KYCRecord, *AccessGrant, error) {
+	if kyc.OwnerMSP == "" || kyc.OwnerMSP == p.mspID {
+		return p.masked(kyc), nil, nil
+	}
+	if p.code == "" {
+		return nil, nil, nil
+	}
+	if p.regulator {
+		return p.masked(kyc), nil, nil
+	}
+	grant, err := activeGrant(ctx, kyc.ID, p.mspID, p.code)
+	if err != nil {
+		return nil, nil, err
+	}
+	if grant == nil {
+		return nil, nil, nil
+	}
+	scoped, err := scopeRecord(kyc, grant.Fields)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p.masked(scoped), grant, nil
+}
+
+// masked returns a record as the caller may see it under the masking profile
+func (p *readPurpose) masked(kyc *KYCRecord) *KYCRecord {
+	if p.mask == nil {
+		return kyc
+	}
+	return maskRecord(kyc, p.mask)
+}
+
+// record logs a permitted read of another organisation's record, of a decoy
+// by anyone but an administrator, or of any record by a caller reading it
+// unmasked, and counts it against the grant it was made under
+func (p *readPurpose) record(ctx contractapi.TransactionContextInterface, kyc *KYCRecord, grant *AccessGrant) error {
+	decoy := false
+	if !p.admin {
+		var err error
+		decoy, err = isDecoy(ctx, kyc.ID)
+		if err != nil {
+			return err
+		}
+	}
+	if !decoy && !p.unmasked && (kyc.OwnerMSP == "" || kyc.OwnerMSP == p.mspID) {
+		return nil
+	}
+
+	err := requireUnpaginated(ctx, p.function)
+	if err != nil {
+		return err
+	}
+	entry := AccessLogEntry{
+		KYCID:       kyc.ID,
+		Function:    p.function,
+		PurposeCode: p.code,
+		Accessor:    p.accessor,
+		AccessorMSP: p.mspID,
+		AccessedAt:  txTime(ctx).Format(time.RFC3339),
+		TxID:        ctx.GetStub().GetTxID(),
+		Unmasked:    p.unmasked,
+	}
+	if grant != nil {
+		grant.Reads++
+		err := putGrant(ctx, grant)
+		if err != nil {
+			return err
+		}
+		entry.GrantID, entry.ConsentReceiptID = grant.GrantID, grant.ConsentReceiptID
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(accessLogObjectType, []string{kyc.ID, entry.TxID})
+	if err != nil {
+		return err
+	}
+	entryJSON, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, entryJSON)
+	if err != nil {
+		return fmt.Errorf("failed to log access: %v", err)
+	}
+	err = ctx.GetStub().PutState(accessTimeKey(&entry), entryJSON)
+	if err != nil {
+		return fmt.Errorf("failed to log access: %v", err)
+	}
+	if decoy {
+		return p.decoyRead(ctx, kyc, &entry)
+	}
+	return nil
+}
+
+// accessTimeKey returns the key of the time-ordered copy of an access log entry
+func accessTimeKey(entry *AccessLogEntry) string {
+	return fmt.Sprintf("%s%s_%s_%s", accessTimePrefix, entry.AccessedAt, entry.TxID, entry.KYCID)
+}
+
+// AccessTimeBackfillResult summarises one page of BackfillAccessTimes
+type AccessTimeBackfillResult struct {
+	Scanned  int    `json:"scanned"`
+	Written  int    `json:"written"` // entries whose time-ordered copy was missing
+	Bookmark string `json:"bookmark"`
+}
+
+// BackfillAccessTimes copies one page of the access log entries written
+// before the log was kept in time order as well, so GetAccessAnomalies sees
+// them. Run it over every page, until the bookmark comes back empty.
+func (s *SmartContract) BackfillAccessTimes(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*AccessTimeBackfillResult, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// the copies are writes, which rule out paginated queries
+	result := &AccessTimeBackfillResult{}
+	next, done, err := scanCompositeKeys(ctx, accessLogObjectType, bookmark, int(pageSize), func(key string, value []byte) (bool, error) {
+		result.Scanned++
+		var entry AccessLogEntry
+		err := json.Unmarshal(value, &entry)
+		if err != nil {
+			return false, err
+		}
+		timeKey := accessTimeKey(&entry)
+		existing, err := ctx.GetStub().GetState(timeKey)
+		if err != nil {
+			return false, err
+		}
+		if existing != nil {
+			return true, nil
+		}
+		err = ctx.GetStub().PutState(timeKey, value)
+		if err != nil {
+			return false, fmt.Errorf("failed to copy access log entry: %v", err)
+		}
+		result.Written++
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !done {
+		result.Bookmark = next
+	}
+	return result, nil
+}
+
+// recordBulk logs a page of a full listing or export that started at
+// bookmark and returned count records or documents
+func (p *readPurpose) recordBulk(ctx contractapi.TransactionContextInterface, bookmark string, count int) error {
+	err := requireUnpaginated(ctx, p.function)
+	if err != nil {
+		return err
+	}
+	entry := BulkAccessLogEntry{
+		Function:    p.function,
+		PurposeCode: p.code,
+		Accessor:    p.accessor,
+		AccessorMSP: p.mspID,
+		Bookmark:    bookmark,
+		Returned:    count,
+		AccessedAt:  txTime(ctx).Format(time.RFC3339),
+		TxID:        ctx.GetStub().GetTxID(),
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(bulkAccessLogObjectType, []string{entry.TxID})
+	if err != nil {
+		return err
+	}
+	entryJSON, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, entryJSON)
+	if err != nil {
+		return fmt.Errorf("failed to log access: %v", err)
+	}
+	return nil
+}
+
+// requireUnpaginated fails once a transaction has run a paginated query,
+// for function to log its reads
+func requireUnpaginated(ctx contractapi.TransactionContextInterface, function string) error {
+	if tx, ok := ctx.(*txContext); ok && tx.trace != nil && tx.trace.paginated {
+		return fmt.Errorf("%s cannot log its reads after a paginated query", function)
+	}
+	return nil
+}
+
+// check is permits and record for a read of a single record, failing when
+// it is denied
+func (p *readPurpose) check(ctx contractapi.TransactionContextInterface, kyc *KYCRecord) (*KYCRecord, error) {
+	visible, grant, err := p.permits(ctx, kyc)
+	if err != nil {
+		return nil, err
+	}
+	if visible == nil {
+		if p.code == "" {
+			return nil, fmt.Errorf("KYC record %s is owned by %s; a purpose code is required to read it", kyc.ID, kyc.OwnerMSP)
+		}
+		return nil, fmt.Errorf("no active grant lets %s read KYC record %s for %s", p.mspID, kyc.ID, p.code)
+	}
+	err = p.record(ctx, kyc, grant)
+	if err != nil {
+		return nil, err
+	}
+	return visible, nil
+}
+
+// filter returns what of each record the caller may read, leaving out the
+// records they may not, and records each read of another organisation's
+// record
+func (p *readPurpose) filter(ctx contractapi.TransactionContextInterface, records []*KYCRecord) ([]*KYCRecord, error) {
+	permitted := []*KYCRecord{}
+	for _, kyc := range records {
+		visible, grant, err := p.permits(ctx, kyc)
+		if err != nil {
+			return nil, err
+		}
+		if visible == nil {
+			continue
+		}
+		err = p.record(ctx, kyc, grant)
+		if err != nil {
+			return nil, err
+		}
+		permitted = append(permitted, visible)
+	}
+	return permitted, nil
+}
+
+// ReadKYC returns the KYC record stored in the world state with given id.
+// Callers outside the organisation that owns the record must give the
+// purposeCode of an access grant, and get the fields it covers (see
+// purpose.go).
+func (s *SmartContract) ReadKYC(ctx contractapi.TransactionContextInterface, id string, purposeCode string) (*KYCRecord, error) {
+	purpose, err := newReadPurpose(ctx, "ReadKYC", purposeCode)
+	if err != nil {
+		return nil, err
+	}
+	kyc, err := s.readKYC(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return purpose.check(ctx, kyc)
+}
+
+// GetAccessLog returns the reads of a record by other organisations, and
+// its unmasked reads when personal details are masked. Only the
+// organisation that owns the record, administrators and regulators can
+// read it.
+func (s *SmartContract) GetAccessLog(ctx contractapi.TransactionContextInterface, kycID string) ([]*AccessLogEntry, error) {
+	privileged := false
+	for _, attr := range []string{AttrAdmin, AttrRegulator} {
+		held, err := hasAttribute(ctx, attr)
+		if err != nil {
+			return nil, err
+		}
+		privileged = privileged || held
+	}
+	if !privileged {
+		kyc, err := s.readKYC(ctx, kycID)
+		if err != nil {
+			return nil, err
+		}
+		mspID, err := ctx.GetClientIdentity().GetMSPID()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+		}
+		if kyc.OwnerMSP != "" && kyc.OwnerMSP != mspID {
+			return nil, fmt.Errorf("KYC record %s is owned by %s", kycID, kyc.OwnerMSP)
+		}
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(accessLogObjectType, []string{kycID})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []*AccessLogEntry{}
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		var entry AccessLogEntry
+		err := json.Unmarshal(queryResponse.Value, &entry)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, &entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].AccessedAt < entries[j].AccessedAt })
+	return entries, nil
+}
+
+// GetBulkAccessLog returns the logged pages of full listings and exports
+// between from and to inclusive, oldest first. Bounds are RFC 3339
+// timestamps and either may be empty. Only administrators and regulators
+// can read it.
+func (s *SmartContract) GetBulkAccessLog(ctx contractapi.TransactionContextInterface, from string, to string) ([]*BulkAccessLogEntry, error) {
+	_, err := requireAnyAttribute(ctx, AttrAdmin, AttrRegulator)
+	if err != nil {
+		return nil, err
+	}
+	for _, bound := range []string{from, to} {
+		if bound == "" {
+			continue
+		}
+		_, err := time.Parse(time.RFC3339, bound)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q: expected RFC 3339", bound)
+		}
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(bulkAccessLogObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	entries := []*BulkAccessLogEntry{}
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		var entry BulkAccessLogEntry
+		err := json.Unmarshal(queryResponse.Value, &entry)
+		if err != nil {
+			return err
+		}
+		if (from != "" && entry.AccessedAt < from) || (to != "" && entry.AccessedAt > to) {
+			return nil
+		}
+		entries = append(entries, &entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].AccessedAt < entries[j].AccessedAt })
+	return entries, nil
+}
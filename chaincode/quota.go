@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Submission quotas. A consortium can cap the records each member submits
+// with CreateKYC per UTC day and per UTC month according to its licence
+// tier. Tiers are part of the contract configuration and list the
+// organisations holding them; an organisation no tier lists holds the
+// default tier, and is not limited when there is none. Each submission
+// counts against its organisation's day and month counters, which span
+// counterBuckets keys like the record counters, so a capped organisation's
+// concurrent submissions can collide on an MVCC read conflict while other
+// organisations' submissions are unaffected. Usage is not exported.
+const counterQuotaPrefix = "quota:"
+
+// SubmissionQuotas configures the submission quotas of the licence tiers
+type SubmissionQuotas struct {
+	DefaultTier string      `json:"defaultTier,omitempty" metadata:",optional"` // tier of organisations no tier lists; empty leaves them unlimited
+	Tiers       []QuotaTier `json:"tiers"`
+}
+
+// QuotaTier is a licence tier and the organisations holding it
+type QuotaTier struct {
+	Name    string   `json:"name"`
+	Daily   int      `json:"daily"`   // submissions per UTC day; 0 is unlimited
+	Monthly int      `json:"monthly"` // submissions per UTC month; 0 is unlimited
+	MSPs    []string `json:"msps"`
+}
+
+// QuotaUsage is an organisation's submissions against its quota in the
+// current day and month
+type QuotaUsage struct {
+	MSPID        string `json:"mspId"`
+	Tier         string `json:"tier,omitempty" metadata:",optional"` // empty when the organisation is not limited
+	Day          string `json:"day"`
+	DailyCount   int    `json:"dailyCount"`
+	DailyLimit   int    `json:"dailyLimit"` // 0 is unlimited
+	Month        string `json:"month"`
+	MonthlyCount int    `json:"monthlyCount"`
+	MonthlyLimit int    `json:"monthlyLimit"` // 0 is unlimited
+}
+
+// GetSubmissionQuotaUsage returns an organisation's quota and its use so far
+// this day and month. An empty mspID means the caller's organisation; only
+// administrators can see other organisations' usage.
+func (s *SmartContract) GetSubmissionQuotaUsage(ctx contractapi.TransactionContextInterface, mspID string) (*QuotaUsage, error) {
+	callerMSP, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	mspID = strings.TrimSpace(mspID)
+	if mspID == "" {
+		mspID = callerMSP
+	}
+	if mspID != callerMSP {
+		err = requireAdmin(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return readQuotaUsage(ctx, &config.SubmissionQuotas, mspID, s.txTime(ctx).Format(time.RFC3339))
+}
+
+// tier returns the tier an organisation holds, or nil when it is not limited
+func (q *SubmissionQuotas) tier(mspID string) *QuotaTier {
+	var fallback *QuotaTier
+	for i := range q.Tiers {
+		for _, member := range q.Tiers[i].MSPs {
+			if member == mspID {
+				return &q.Tiers[i]
+			}
+		}
+		if q.Tiers[i].Name == q.DefaultTier {
+			fallback = &q.Tiers[i]
+		}
+	}
+	return fallback
+}
+
+// enabled reports whether any tier limits submissions
+func (q *SubmissionQuotas) enabled() bool {
+	for _, tier := range q.Tiers {
+		if tier.Daily > 0 || tier.Monthly > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func validateSubmissionQuotas(q *SubmissionQuotas) error {
+	names := map[string]bool{}
+	holders := map[string]string{}
+	for _, tier := range q.Tiers {
+		if tier.Name == "" {
+			return fmt.Errorf("submissionQuotas tiers must be named")
+		}
+		if names[tier.Name] {
+			return fmt.Errorf("submissionQuotas tier %s is listed more than once", tier.Name)
+		}
+		names[tier.Name] = true
+		if tier.Daily < 0 || tier.Monthly < 0 {
+			return fmt.Errorf("submissionQuotas tier %s must not have a negative quota", tier.Name)
+		}
+		for _, mspID := range tier.MSPs {
+			if held, ok := holders[mspID]; ok {
+				return fmt.Errorf("%s holds both the %s and %s submission quota tiers", mspID, held, tier.Name)
+			}
+			holders[mspID] = tier.Name
+		}
+	}
+	if q.DefaultTier != "" && !names[q.DefaultTier] {
+		return fmt.Errorf("submissionQuotas defaultTier %s is not a tier", q.DefaultTier)
+	}
+	return nil
+}
+
+// quotaCounters returns the names of an organisation's day and month
+// submission counters at an RFC 3339 time
+func quotaCounters(mspID string, at string) (string, string) {
+	prefix := counterQuotaPrefix + mspID + ":"
+	return prefix + "day:" + at[:10], prefix + "month:" + at[:7]
+}
+
+// readQuotaUsage returns an organisation's quota usage at an RFC 3339 time
+func readQuotaUsage(ctx contractapi.TransactionContextInterface, quotas *SubmissionQuotas, mspID string, at string) (*QuotaUsage, error) {
+	usage := &QuotaUsage{MSPID: mspID, Day: at[:10], Month: at[:7]}
+	if tier := quotas.tier(mspID); tier != nil {
+		usage.Tier, usage.DailyLimit, usage.MonthlyLimit = tier.Name, tier.Daily, tier.Monthly
+	}
+	day, month := quotaCounters(mspID, at)
+	var err error
+	usage.DailyCount, err = readCounter(ctx, day)
+	if err != nil {
+		return nil, err
+	}
+	usage.MonthlyCount, err = readCounter(ctx, month)
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// checkSubmissionQuota fails when an organisation has used up its daily or
+// monthly quota at an RFC 3339 time. Organisations that are not limited are
+// not checked, so their submissions only read the counter buckets they add to.
+func checkSubmissionQuota(ctx contractapi.TransactionContextInterface, mspID string, at string) error {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	tier := config.SubmissionQuotas.tier(mspID)
+	if tier == nil || (tier.Daily == 0 && tier.Monthly == 0) {
+		return nil
+	}
+	usage, err := readQuotaUsage(ctx, &config.SubmissionQuotas, mspID, at)
+	if err != nil {
+		return err
+	}
+	if usage.DailyLimit > 0 && usage.DailyCount >= usage.DailyLimit {
+		return fmt.Errorf("submission quota exceeded: %s has used its quota of %d submissions for %s under the %s tier", mspID, usage.DailyLimit, usage.Day, usage.Tier)
+	}
+	if usage.MonthlyLimit > 0 && usage.MonthlyCount >= usage.MonthlyLimit {
+		return fmt.Errorf("submission quota exceeded: %s has used its quota of %d submissions for %s under the %s tier", mspID, usage.MonthlyLimit, usage.Month, usage.Tier)
+	}
+	return nil
+}
+
+// chargeSubmissionQuota counts a submission against its organisation's
+// quota, failing when the quota is used up
+func chargeSubmissionQuota(ctx contractapi.TransactionContextInterface, mspID string, at string) error {
+	err := checkSubmissionQuota(ctx, mspID, at)
+	if err != nil {
+		return err
+	}
+	day, month := quotaCounters(mspID, at)
+	return applyCounterDeltas(ctx, map[string]int{day: 1, month: 1})
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Rate limiting. Each expensive query draws tokens from a bucket held for
+// its caller, keyed by MSP and enrolment ID under "RATE~<MSP>~<client ID>".
+// A bucket holds up to RateLimit.Capacity tokens and refills at
+// RateLimit.RefillPerMinute, so a caller can burst up to the capacity and is
+// then held to the refill rate; a query is refused while its caller's bucket
+// holds fewer tokens than it costs. Peers answer evaluated transactions
+// without committing what they write, so buckets only drain for submitted
+// queries, but an evaluated query is refused too while the bucket is empty.
+// Limiting is off until an administrator sets a capacity, and callers that
+// page through records in bulk, such as the read model projector, need one
+// that leaves them headroom. Charging a caller writes their bucket, and
+// Fabric refuses paginated queries in a transaction that has written, so
+// the rate-limited functions page with the key scans of iterator.go.
+const rateBucketObjectType = "RATE"
+
+// rateLimitCosts are the tokens each rate-limited function costs, roughly
+// in proportion to the work a call can make a peer do
+var rateLimitCosts = map[string]int{
+	"GetAllKYC":                        20,
+	"GetKYCByStatus":                   10,
+	"GetDuplicatePhoneReport":          10,
+	"GetAccessAnomalies":               10,
+	"GetKYCByCity":                     1,
+	"GetKYCByEmail":                    1,
+	"GetKYCByPAN":                      1,
+	"GetKYCByPhone":                    1,
+	"GetKYCByPincode":                  1,
+	"GetKYCByState":                    1,
+	"GetKYCByStatusWithPagination":     1,
+	"GetKYCByTag":                      1,
+	"GetRecordsAboveMatchScore":        1,
+	"GetRecordsAwaitingDocumentReview": 1,
+	"FindFaceCollisions":               1,
+}
+
+// RateLimit configures the per-caller token buckets of the expensive queries
+type RateLimit struct {
+	Capacity        int `json:"capacity"`        // tokens a bucket holds; 0 turns limiting off
+	RefillPerMinute int `json:"refillPerMinute"` // tokens a bucket regains each minute
+}
+
+// rateBucket is a caller's token bucket
+type rateBucket struct {
+	Tokens    float64 `json:"tokens"`
+	UpdatedAt string  `json:"updatedAt"`
+}
+
+// rateLimit charges the caller of a rate-limited function its cost, failing
+// when their bucket cannot cover it
+func rateLimit(ctx contractapi.TransactionContextInterface) error {
+	function, _ := ctx.GetStub().GetFunctionAndParameters()
+	function = function[strings.LastIndex(function, ":")+1:]
+	cost, ok := rateLimitCosts[function]
+	if !ok {
+		return nil
+	}
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	limit := config.RateLimit
+	if limit.Capacity == 0 {
+		return nil
+	}
+
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	clientID, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(rateBucketObjectType, []string{mspID, clientID})
+	if err != nil {
+		return err
+	}
+	bucketJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	now := txTime(ctx)
+	bucket := rateBucket{Tokens: float64(limit.Capacity)}
+	if bucketJSON != nil {
+		err = json.Unmarshal(bucketJSON, &bucket)
+		if err != nil {
+			return err
+		}
+		updatedAt, err := time.Parse(time.RFC3339, bucket.UpdatedAt)
+		if err == nil && now.After(updatedAt) {
+			bucket.Tokens += now.Sub(updatedAt).Minutes() * float64(limit.RefillPerMinute)
+		}
+		bucket.Tokens = math.Min(bucket.Tokens, float64(limit.Capacity))
+	}
+	if bucket.Tokens < float64(cost) {
+		wait := math.Ceil((float64(cost) - bucket.Tokens) * 60 / float64(limit.RefillPerMinute))
+		return fmt.Errorf("rate limit exceeded: %s costs %d tokens and the caller has %d; retry in %.0fs", function, cost, int(bucket.Tokens), wait)
+	}
+
+	bucket.Tokens -= float64(cost)
+	bucket.UpdatedAt = now.Format(time.RFC3339)
+	bucketJSON, err = json.Marshal(bucket)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(key, bucketJSON)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// Reliance receipts. KYC-sharing regimes expect the organisation that
+// verified a customer to know who has relied on that verification.
+// RecordVerificationCheck records that a relying party checked a record for
+// a purpose and relied on it, under "RELIANCE~<kycID>~<tx ID>". The check
+// is a read for that purpose, so callers from other organisations need an
+// active grant for it and are logged in the access log as for ReadKYC, and
+// only a record that is verified and unexpired can be relied on. The
+// receipt keeps the status relied on and the hash of the record's state at
+// the time, so a later dispute can be settled against the record's
+// history. GetRelianceHistory lists the receipts of a record.
+const (
+	relianceObjectType = "RELIANCE"
+	maxRelyingParty    = 256
+)
+
+// RelianceReceipt records one relying party's reliance on a verified record
+type RelianceReceipt struct {
+	ReceiptID         string `json:"receiptId"` // the recording transaction's ID
+	KYCID             string `json:"kycId"`
+	RelyingParty      string `json:"relyingParty"` // the party relying, as the caller names it
+	PurposeCode       string `json:"purposeCode"`
+	RecordedBy        string `json:"recordedBy"`
+	RecordedByMSP     string `json:"recordedByMsp"`
+	GrantID           string `json:"grantId,omitempty" metadata:",optional"` // the grant the check was made under; empty for the owner or a regulator
+	Status            string `json:"status"`
+	VerificationLevel string `json:"verificationLevel"`
+	VerifiedAt        string `json:"verifiedAt,omitempty" metadata:",optional"`
+	ExpiresAt         string `json:"expiresAt,omitempty" metadata:",optional"`
+	StateHash         string `json:"stateHash"` // hex SHA-256 of the record's world state value when relied on
+	ReliedAt          string `json:"reliedAt"`
+}
+
+// RecordVerificationCheck records that relyingParty has relied on a verified
+// record for purposeCode, and returns the receipt
+func (s *SmartContract) RecordVerificationCheck(ctx contractapi.TransactionContextInterface, kycID string, relyingParty string, purposeCode string) (*RelianceReceipt, error) {
+	if relyingParty == "" || len(relyingParty) > maxRelyingParty {
+		return nil, fmt.Errorf("relying party must be between 1 and %d characters", maxRelyingParty)
+	}
+	if purposeCode == "" {
+		return nil, fmt.Errorf("a purpose code is required to rely on a KYC record")
+	}
+	purpose, err := newReadPurpose(ctx, "RecordVerificationCheck", purposeCode)
+	if err != nil {
+		return nil, err
+	}
+	kyc, kycJSON, err := s.readKYCWithState(ctx, kycID)
+	if err != nil {
+		return nil, err
+	}
+	visible, grant, err := purpose.permits(ctx, kyc)
+	if err != nil {
+		return nil, err
+	}
+	if visible == nil {
+		return nil, fmt.Errorf("no active grant lets %s read KYC record %s for %s", purpose.mspID, kycID, purposeCode)
+	}
+
+	now := s.txTime(ctx).Format(time.RFC3339)
+	if kyc.Status != "VERIFIED" {
+		return nil, fmt.Errorf("KYC record %s is %s and cannot be relied on", kycID, kyc.Status)
+	}
+	if kyc.ExpiresAt != "" && kyc.ExpiresAt <= now {
+		return nil, fmt.Errorf("KYC record %s expired at %s and cannot be relied on", kycID, kyc.ExpiresAt)
+	}
+	err = purpose.record(ctx, kyc, grant)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := &RelianceReceipt{
+		ReceiptID:         ctx.GetStub().GetTxID(),
+		KYCID:             kycID,
+		RelyingParty:      relyingParty,
+		PurposeCode:       purposeCode,
+		RecordedBy:        purpose.accessor,
+		RecordedByMSP:     purpose.mspID,
+		Status:            kyc.Status,
+		VerificationLevel: kyc.VerificationLevel,
+		VerifiedAt:        kyc.VerifiedAt,
+		ExpiresAt:         kyc.ExpiresAt,
+		StateHash:         recordStateHash(kycJSON),
+		ReliedAt:          now,
+	}
+	if grant != nil {
+		receipt.GrantID = grant.GrantID
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(relianceObjectType, []string{kycID, receipt.ReceiptID})
+	if err != nil {
+		return nil, err
+	}
+	receiptJSON, err := json.Marshal(receipt)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(key, receiptJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put reliance receipt: %v", err)
+	}
+	return receipt, nil
+}
+
+// GetRelianceHistory returns the reliance receipts of a record, oldest
+// first. The organisation that owns the record, regulators and
+// administrators see every receipt; other organisations see the receipts
+// they recorded.
+func (s *SmartContract) GetRelianceHistory(ctx contractapi.TransactionContextInterface, kycID string) ([]*RelianceReceipt, error) {
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return nil, err
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	all := kyc.OwnerMSP == "" || kyc.OwnerMSP == mspID
+	if !all {
+		all, err = hasAttribute(ctx, AttrRegulator)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !all {
+		all, err = hasAttribute(ctx, AttrAdmin)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(relianceObjectType, []string{kycID})
+	if err != nil {
+		return nil, err
+	}
+	receipts := []*RelianceReceipt{}
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		var receipt RelianceReceipt
+		err := json.Unmarshal(queryResponse.Value, &receipt)
+		if err != nil {
+			return err
+		}
+		if all || receipt.RecordedByMSP == mspID {
+			receipts = append(receipts, &receipt)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(receipts, func(i, j int) bool { return receipts[i].ReliedAt < receipts[j].ReliedAt })
+	return receipts, nil
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const monthlySummaryObjectType = "monthlySummary"
+
+// MonthlySummary is the compliance summary of one calendar month (UTC). The
+// report is immutable once generated; ContentHash covers every other field
+// except the generator's identity, and the generating transaction carries
+// the endorsing peers' signatures over the stored report.
+type MonthlySummary struct {
+	Month               string         `json:"month"` // YYYY-MM
+	Onboarded           int            `json:"onboarded"`
+	OnboardedByEntity   map[string]int `json:"onboardedByEntity"`
+	Approved            int            `json:"approved"`
+	Rejected            int            `json:"rejected"`
+	ApprovalRate        float64        `json:"approvalRate"`  // share of decisions that approved, 0-1
+	RejectionRate       float64        `json:"rejectionRate"` // share of decisions that rejected, 0-1
+	AvgTurnaroundHours  float64        `json:"avgTurnaroundHours"`
+	RekycDue            int            `json:"rekycDue"`       // verified records whose re-KYC fell due in the month and is still outstanding
+	RekycCompleted      int            `json:"rekycCompleted"` // re-verifications of previously verified or expired records
+	RekycCompletionRate float64        `json:"rekycCompletionRate"`
+	ContentHash         string         `json:"contentHash"`
+	GeneratedAt         string         `json:"generatedAt"`
+	GeneratedBy         string         `json:"generatedBy"`
+	GeneratorMSP        string         `json:"generatorMsp"`
+	TxID                string         `json:"txId"`
+}
+
+// GenerateMonthlySummary computes and stores the compliance summary of a
+// completed month, given as YYYY-MM. Its figures are read from the
+// onboarding, decision and expiry counters of the month, so its cost does
+// not grow with the ledger; ledgers holding records or decisions from
+// before those counters were maintained need RebuildCounters and
+// RebuildDecisionCounters run first.
+func (s *SmartContract) GenerateMonthlySummary(ctx contractapi.TransactionContextInterface, month string) (*MonthlySummary, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		return nil, fmt.Errorf("month must be in YYYY-MM format")
+	}
+	end := start.AddDate(0, 1, 0)
+	now := s.txTime(ctx)
+	if end.After(now) {
+		return nil, fmt.Errorf("month %s has not ended yet", month)
+	}
+
+	summaryKey, err := ctx.GetStub().CreateCompositeKey(monthlySummaryObjectType, []string{month})
+	if err != nil {
+		return nil, err
+	}
+	existing, err := ctx.GetStub().GetState(summaryKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("summary for %s has already been generated", month)
+	}
+
+	summary := &MonthlySummary{Month: month, OnboardedByEntity: map[string]int{}}
+	onboarded, err := readCounters(ctx, counterOnboardedMonth+month+":", counterOnboardedMonth+month+";")
+	if err != nil {
+		return nil, err
+	}
+	for name, count := range onboarded {
+		if count > 0 {
+			summary.Onboarded += count
+			summary.OnboardedByEntity[strings.TrimPrefix(name, counterOnboardedMonth+month+":")] = count
+		}
+	}
+
+	decisions, err := readCounters(ctx, counterDecisionMonth+month+":", counterDecisionMonth+month+";")
+	if err != nil {
+		return nil, err
+	}
+	prefix := counterDecisionMonth + month + ":"
+	summary.Approved = decisions[prefix+"VERIFIED"]
+	summary.Rejected = decisions[prefix+"REJECTED"]
+	summary.RekycCompleted = decisions[prefix+decisionRekyc]
+	timed, turnaround := decisions[prefix+decisionTimed], decisions[prefix+decisionTurnaround]
+
+	summary.RekycDue, err = sumCounters(ctx, counterExpiryDay+start.Format("2006-01-02"), counterExpiryDay+end.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	if decided := summary.Approved + summary.Rejected; decided > 0 {
+		summary.ApprovalRate = roundRate(float64(summary.Approved) / float64(decided))
+		summary.RejectionRate = roundRate(float64(summary.Rejected) / float64(decided))
+	}
+	if timed > 0 {
+		summary.AvgTurnaroundHours = roundRate(float64(turnaround) / 3600 / float64(timed))
+	}
+	if due := summary.RekycDue + summary.RekycCompleted; due > 0 {
+		summary.RekycCompletionRate = roundRate(float64(summary.RekycCompleted) / float64(due))
+	}
+
+	contentJSON, err := json.Marshal(summary)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(contentJSON)
+	summary.ContentHash = hex.EncodeToString(digest[:])
+
+	summary.GeneratedBy, err = clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+	summary.GeneratorMSP, err = ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	summary.GeneratedAt = now.Format(time.RFC3339)
+	summary.TxID = ctx.GetStub().GetTxID()
+
+	summaryJSON, err := json.Marshal(summary)
+	if err != nil {
+		return nil, err
+	}
+	return summary, ctx.GetStub().PutState(summaryKey, summaryJSON)
+}
+
+// GetMonthlySummary returns a previously generated monthly summary
+func (s *SmartContract) GetMonthlySummary(ctx contractapi.TransactionContextInterface, month string) (*MonthlySummary, error) {
+	summaryKey, err := ctx.GetStub().CreateCompositeKey(monthlySummaryObjectType, []string{month})
+	if err != nil {
+		return nil, err
+	}
+	summaryJSON, err := ctx.GetStub().GetState(summaryKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if summaryJSON == nil {
+		return nil, fmt.Errorf("no summary has been generated for %s", month)
+	}
+
+	var summary MonthlySummary
+	err = json.Unmarshal(summaryJSON, &summary)
+	if err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// Decision counters. Each VERIFIED or REJECTED decision a history entry
+// records counts under "decisions:<YYYY-MM>:<action>" for the month it was
+// made in. A verification of a record that was already VERIFIED or EXPIRED
+// also counts as a re-KYC; any other decision is a first decision, whose
+// count and seconds from submission are kept for the average turnaround.
+const (
+	decisionRekyc      = "REKYC"
+	decisionTimed      = "TIMED"
+	decisionTurnaround = "TURNAROUND_SECONDS"
+)
+
+// DecisionCounterRebuildResult summarises one page of a decision counter rebuild
+type DecisionCounterRebuildResult struct {
+	Scanned  int    `json:"scanned"` // history entries
+	Bookmark string `json:"bookmark"`
+}
+
+// decisionCounterDeltas returns the decision counters a history entry adds
+// one or its turnaround to. createdAt is when the decided record was
+// submitted, empty when it is not known; the turnaround is then left out.
+func decisionCounterDeltas(entry *HistoryEntry, createdAt string) map[string]int {
+	if (entry.Action != "VERIFIED" && entry.Action != "REJECTED") || len(entry.PerformedAt) < 7 {
+		return nil
+	}
+	prefix := counterDecisionMonth + entry.PerformedAt[:7] + ":"
+	deltas := map[string]int{prefix + entry.Action: 1}
+	if oldStatus := entry.Details["oldStatus"]; entry.Action == "VERIFIED" && (oldStatus == "VERIFIED" || oldStatus == "EXPIRED") {
+		deltas[prefix+decisionRekyc] = 1
+		return deltas
+	}
+
+	created, createErr := time.Parse(time.RFC3339, createdAt)
+	decided, decideErr := time.Parse(time.RFC3339, entry.PerformedAt)
+	if createErr == nil && decideErr == nil {
+		deltas[prefix+decisionTimed] = 1
+		deltas[prefix+decisionTurnaround] = int(decided.Sub(created).Seconds())
+	}
+	return deltas
+}
+
+// recordCreatedAt returns when a record was submitted, or "" if there is no
+// such record
+func recordCreatedAt(ctx contractapi.TransactionContextInterface, kycID string) (string, error) {
+	kycJSON, err := getRecordState(ctx, kycID)
+	if err != nil {
+		return "", err
+	}
+	kyc, err := unmarshalRecordState(kycID, kycJSON)
+	if err != nil || kyc == nil {
+		return "", err
+	}
+	return kyc.CreatedAt, nil
+}
+
+// RebuildDecisionCounters recomputes the decision counters from the
+// history entries, one page at a time, for ledgers holding decisions made
+// before the counters were maintained. Run MigrateKeys first, so every
+// history entry is under its typed key. The first page (empty bookmark)
+// clears the existing counters; run it while no records are being decided.
+func (s *SmartContract) RebuildDecisionCounters(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*DecisionCounterRebuildResult, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if bookmark == "" {
+		err = clearCounters(ctx, counterDecisionMonth)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	// the counter writes rule out paginated queries
+	result := &DecisionCounterRebuildResult{}
+	deltas := map[string]int{}
+	next, done, err := scanCompositeKeys(ctx, historyObjectType, bookmark, int(pageSize), func(key string, value []byte) (bool, error) {
+		result.Scanned++
+		var entry HistoryEntry
+		err := json.Unmarshal(value, &entry)
+		if err != nil {
+			return false, err
+		}
+		if entry.Action != "VERIFIED" && entry.Action != "REJECTED" {
+			return true, nil
+		}
+		createdAt, err := recordCreatedAt(ctx, entry.KYCID)
+		if err != nil {
+			return false, err
+		}
+		for name, delta := range decisionCounterDeltas(&entry, createdAt) {
+			deltas[name] += delta
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !done {
+		result.Bookmark = next
+	}
+
+	if bookmark == "" {
+		// the cleared buckets still read as their committed values, so the
+		// first page's counts are written rather than added
+		for name, delta := range deltas {
+			err = ctx.GetStub().PutState(counterBucketKey(name, counterBucket(ctx.GetStub().GetTxID())), []byte(strconv.Itoa(delta)))
+			if err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	return result, applyCounterDeltas(ctx, deltas)
+}
+
+// roundRate rounds a ratio or average to two decimal places
+func roundRate(value float64) float64 {
+	return math.Round(value*100) / 100
+}
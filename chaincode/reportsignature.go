@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// reportSignatureObjectType keys the signatures verifiers put on monthly
+// summaries, "REPORTSIG~<month>~<tx ID>". Like eSign attestations they are
+// kept beside the immutable summary rather than on it.
+const reportSignatureObjectType = "REPORTSIG"
+
+// ReportSignaturePayload is what a report signature covers, as JSON with
+// keys sorted and without whitespace: the summary's month and content hash
+type ReportSignaturePayload struct {
+	ContentHash string `json:"contentHash"`
+	Month       string `json:"month"`
+}
+
+// ReportSignature is a verifier's detached signature over a monthly
+// summary, made with the key of the certificate it was submitted with
+type ReportSignature struct {
+	Month       string `json:"month"`
+	ContentHash string `json:"contentHash"`
+	Signature   string `json:"signature"`                            // base64
+	Certificate string `json:"certificate"`                          // PEM
+	KeyID       string `json:"keyId,omitempty" metadata:",optional"` // the signing key's identifier in the signer's key store
+	SignedBy    string `json:"signedBy"`
+	SignerMSP   string `json:"signerMsp"`
+	SignedAt    string `json:"signedAt"`
+	TxID        string `json:"txId"`
+}
+
+// SignMonthlySummary records a verifier's signature over a generated
+// monthly summary. signature is base64, verified as history signatures are
+// against the caller's certificate over the summary's
+// ReportSignaturePayload; keyID optionally names the key it was made with,
+// such as a PKCS #11 URI.
+func (s *SmartContract) SignMonthlySummary(ctx contractapi.TransactionContextInterface, month string, signature string, keyID string) (*ReportSignature, error) {
+	_, err := requireAnyAttribute(ctx, AttrVerifier, AttrAdmin)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := s.GetMonthlySummary(ctx, month)
+	if err != nil {
+		return nil, err
+	}
+	signatureBytes, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || len(signatureBytes) == 0 {
+		return nil, fmt.Errorf("signature must be base64")
+	}
+	cert, err := ctx.GetClientIdentity().GetX509Certificate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client certificate: %v", err)
+	}
+	payload, err := json.Marshal(ReportSignaturePayload{ContentHash: summary.ContentHash, Month: summary.Month})
+	if err != nil {
+		return nil, err
+	}
+	err = verifySignature(cert, payload, signatureBytes)
+	if err != nil {
+		return nil, fmt.Errorf("signature over the summary for %s: %v", month, err)
+	}
+	signedBy, err := clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+
+	reportSignature := &ReportSignature{
+		Month:       summary.Month,
+		ContentHash: summary.ContentHash,
+		Signature:   signature,
+		Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})),
+		KeyID:       keyID,
+		SignedBy:    signedBy,
+		SignerMSP:   mspID,
+		SignedAt:    s.txTime(ctx).Format(time.RFC3339),
+		TxID:        ctx.GetStub().GetTxID(),
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(reportSignatureObjectType, []string{reportSignature.Month, reportSignature.TxID})
+	if err != nil {
+		return nil, err
+	}
+	signatureJSON, err := json.Marshal(reportSignature)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(key, signatureJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put report signature: %v", err)
+	}
+	return reportSignature, nil
+}
+
+// GetReportSignatures returns the verifier signatures of a monthly summary
+func (s *SmartContract) GetReportSignatures(ctx contractapi.TransactionContextInterface, month string) ([]*ReportSignature, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(reportSignatureObjectType, []string{month})
+	if err != nil {
+		return nil, err
+	}
+
+	signatures := []*ReportSignature{}
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		var signature ReportSignature
+		err := json.Unmarshal(queryResponse.Value, &signature)
+		if err != nil {
+			return err
+		}
+		signatures = append(signatures, &signature)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return signatures, nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const staleScreeningIndex = "stalescreening~list~kycid"
+
+// StaleScreening marks a record as needing screening against a newer list version
+type StaleScreening struct {
+	KYCID       string `json:"kycId"`
+	List        string `json:"list"`
+	ListVersion int    `json:"listVersion"`
+	MarkedAt    string `json:"markedAt"`
+}
+
+// RescreeningResult summarises one page of a rescreening trigger
+type RescreeningResult struct {
+	Scanned  int    `json:"scanned"`
+	Marked   int    `json:"marked"`
+	Bookmark string `json:"bookmark"`
+}
+
+// StaleScreeningPage holds one page of records awaiting rescreening
+type StaleScreeningPage struct {
+	Items               []*StaleScreening `json:"items"`
+	FetchedRecordsCount int32             `json:"fetchedRecordsCount"`
+	Bookmark            string            `json:"bookmark"`
+	Truncated           bool              `json:"truncated"` // the page was cut short by maxResponseBytes
+}
+
+// TriggerRescreening marks VERIFIED records as screening-stale after a new
+// version of a watchlist lands, one page of records at a time; records in
+// other statuses count toward the page but are not marked. listVersion
+// must be the list's current version so a delayed trigger cannot mark records
+// against a list that has since moved on. Records already screened against
+// that version are skipped.
+func (s *SmartContract) TriggerRescreening(ctx contractapi.TransactionContextInterface, listName string, listVersion int, pageSize int32, bookmark string) (*RescreeningResult, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := loadList(ctx, listName)
+	if err != nil {
+		return nil, err
+	}
+	if list.Version != listVersion {
+		return nil, fmt.Errorf("list %s is at version %d, not %d", list.Name, list.Version, listVersion)
+	}
+
+	err = checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	result := &RescreeningResult{}
+	markedAt := s.txTime(ctx).Format(time.RFC3339)
+	newlyStale := 0
+	// the markers' writes rule out paginated queries
+	next, done, err := scanCompositeKeys(ctx, recordObjectType, bookmark, int(pageSize), func(key string, value []byte) (bool, error) {
+		var kyc KYCRecord
+		err := unmarshalRecord(value, &kyc)
+		if err != nil {
+			return false, err
+		}
+		result.Scanned++
+
+		if kyc.Status != "VERIFIED" {
+			return true, nil
+		}
+		if kyc.Screening != nil && kyc.Screening.List == list.Name && kyc.Screening.ListVersion >= list.Version {
+			return true, nil
+		}
+
+		stale := StaleScreening{KYCID: kyc.ID, List: list.Name, ListVersion: list.Version, MarkedAt: markedAt}
+		staleJSON, err := json.Marshal(stale)
+		if err != nil {
+			return false, err
+		}
+		staleKey, err := ctx.GetStub().CreateCompositeKey(staleScreeningIndex, []string{list.Name, kyc.ID})
+		if err != nil {
+			return false, err
+		}
+		existing, err := ctx.GetStub().GetState(staleKey)
+		if err != nil {
+			return false, err
+		}
+		err = ctx.GetStub().PutState(staleKey, staleJSON)
+		if err != nil {
+			return false, err
+		}
+		if existing == nil {
+			newlyStale++
+		}
+		result.Marked++
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !done {
+		result.Bookmark = next
+	}
+
+	if newlyStale > 0 {
+		err = incrementCounter(ctx, counterStaleScreens, newlyStale)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// GetStaleScreenings returns one page of records awaiting rescreening against a list
+func (s *SmartContract) GetStaleScreenings(ctx contractapi.TransactionContextInterface, listName string, pageSize int32, bookmark string) (*StaleScreeningPage, error) {
+	list, err := loadList(ctx, listName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	resultsIterator, responseMetadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(staleScreeningIndex, []string{list.Name}, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	budget, err := newResponseBudget(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []*StaleScreening{}
+	bookmark, truncated := responseMetadata.Bookmark, false
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var stale StaleScreening
+		err = json.Unmarshal(queryResponse.Value, &stale)
+		if err != nil {
+			return nil, err
+		}
+		fits, err := budget.fits(&stale)
+		if err != nil {
+			return nil, err
+		}
+		if !fits {
+			bookmark, truncated = queryResponse.Key, true
+			break
+		}
+		items = append(items, &stale)
+	}
+
+	return &StaleScreeningPage{
+		Items:               items,
+		FetchedRecordsCount: int32(len(items)),
+		Bookmark:            bookmark,
+		Truncated:           truncated,
+	}, nil
+}
+
+// clearStaleScreening removes a record's stale marker for a list once it has been rescreened
+func clearStaleScreening(ctx contractapi.TransactionContextInterface, listName string, kycID string) error {
+	staleKey, err := ctx.GetStub().CreateCompositeKey(staleScreeningIndex, []string{listName, kycID})
+	if err != nil {
+		return err
+	}
+	existing, err := ctx.GetStub().GetState(staleKey)
+	if err != nil || existing == nil {
+		return err
+	}
+	err = ctx.GetStub().DelState(staleKey)
+	if err != nil {
+		return err
+	}
+	return incrementCounter(ctx, counterStaleScreens, -1)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// responseBudget tracks how much of the configured maxResponseBytes a page
+// of results has used. Key-ranged pages that run out of budget stop early and
+// return the key of the first result left out as their bookmark, which the
+// peer uses as the start key of the next page.
+type responseBudget struct {
+	remaining int
+	items     int
+}
+
+// newResponseBudget starts a budget for one response
+func newResponseBudget(ctx contractapi.TransactionContextInterface) (*responseBudget, error) {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &responseBudget{remaining: config.MaxResponseBytes}, nil
+}
+
+// fits reports whether item can be added to the response and, if so, charges
+// its encoded size to the budget. The first item always fits so that paging
+// makes progress however large a single result is.
+func (b *responseBudget) fits(item interface{}) (bool, error) {
+	itemJSON, err := json.Marshal(item)
+	if err != nil {
+		return false, err
+	}
+	if b.items > 0 && len(itemJSON) > b.remaining {
+		return false, nil
+	}
+	b.remaining -= len(itemJSON)
+	b.items++
+	return true, nil
+}
@@ -0,0 +1,384 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// Access revocation. RevokeGrant and RevokeConsent withdraw access grants
+// and emit an AccessRevoked event naming the grantees and the fields and
+// purposes withdrawn, so that gateway caches, read models and the
+// grantees' own systems can evict what they hold. RevokeDelegate emits one
+// for every record it revokes a delegate's grants to (see delegate.go).
+// The revocation is kept under "REVOCATION~<kycID>~<revocation ID>", and
+// each grantee records that it has evicted the data with
+// AcknowledgeRevocation, under
+// "REVOCATIONACK~<kycID>~<revocation ID>~<grantee MSP>".
+const (
+	revocationObjectType    = "REVOCATION"
+	revocationAckObjectType = "REVOCATIONACK"
+
+	// accessRevokedEvent is the chaincode event a revocation emits, with the
+	// Revocation as its payload
+	accessRevokedEvent = "AccessRevoked"
+)
+
+// Revocation records the withdrawal of one or more access grants to a record
+type Revocation struct {
+	RevocationID     string          `json:"revocationId"` // the revoking transaction's ID
+	KYCID            string          `json:"kycId"`
+	OwnerMSP         string          `json:"ownerMsp"`
+	ConsentReceiptID string          `json:"consentReceiptId,omitempty" metadata:",optional"` // set when a consent was revoked
+	Grantees         []string        `json:"grantees"`
+	Scopes           []RevokedScope  `json:"scopes"`
+	Reason           string          `json:"reason,omitempty" metadata:",optional"`
+	RevokedBy        string          `json:"revokedBy"`
+	RevokedAt        string          `json:"revokedAt"`
+	Acknowledgements []RevocationAck `json:"acknowledgements,omitempty" metadata:",optional"` // filled in by GetRevocation
+	Pending          []string        `json:"pending,omitempty" metadata:",optional"`          // grantees yet to acknowledge, filled in by GetRevocation
+}
+
+// RevokedScope is what one revoked grant gave its grantee
+type RevokedScope struct {
+	GrantID string   `json:"grantId"`
+	Grantee string   `json:"grantee"`
+	Sponsor string   `json:"sponsor,omitempty" metadata:",optional"`
+	Fields  []string `json:"fields"`
+	Purpose string   `json:"purpose"`
+}
+
+// RevocationAck records a grantee's confirmation that it has evicted the
+// data a revocation withdrew
+type RevocationAck struct {
+	RevocationID   string `json:"revocationId"`
+	KYCID          string `json:"kycId"`
+	MSPID          string `json:"mspId"`
+	AcknowledgedBy string `json:"acknowledgedBy"`
+	AcknowledgedAt string `json:"acknowledgedAt"`
+	TxID           string `json:"txId"`
+}
+
+// RevokeGrant revokes an access grant of a record. Only the organisation
+// that submitted the record can revoke grants to it; a grant issued for a
+// consent is revoked with the consent.
+func (s *SmartContract) RevokeGrant(ctx contractapi.TransactionContextInterface, kycID string, grantID string, reason string) (*Revocation, error) {
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return nil, err
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	if kyc.OwnerMSP != "" && kyc.OwnerMSP != mspID {
+		return nil, fmt.Errorf("KYC record %s is owned by %s", kycID, kyc.OwnerMSP)
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(grantObjectType, []string{kycID, grantID})
+	if err != nil {
+		return nil, err
+	}
+	grantJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if grantJSON == nil {
+		return nil, fmt.Errorf("KYC record %s has no access grant %s", kycID, grantID)
+	}
+	var grant AccessGrant
+	err = json.Unmarshal(grantJSON, &grant)
+	if err != nil {
+		return nil, err
+	}
+	if grant.RevokedAt != "" {
+		return nil, fmt.Errorf("access grant %s of KYC record %s is already revoked", grantID, kycID)
+	}
+	if grant.ConsentReceiptID != "" {
+		return nil, fmt.Errorf("access grant %s was issued for consent %s; revoke the consent instead", grantID, grant.ConsentReceiptID)
+	}
+
+	revocation, err := s.revoke(ctx, kyc, mspID, "", []*AccessGrant{&grant}, reason)
+	if err != nil {
+		return nil, err
+	}
+	return revocation, emitRevocation(ctx, revocation)
+}
+
+// RevokeConsent revokes a consent and every grant issued for it. Only the
+// organisation that submitted the record can revoke consent to it.
+func (s *SmartContract) RevokeConsent(ctx contractapi.TransactionContextInterface, kycID string, receiptID string, reason string) (*Revocation, error) {
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return nil, err
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	if kyc.OwnerMSP != "" && kyc.OwnerMSP != mspID {
+		return nil, fmt.Errorf("KYC record %s is owned by %s", kycID, kyc.OwnerMSP)
+	}
+
+	receipt, err := s.GetConsentReceipt(ctx, kycID, receiptID)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.RevokedAt != "" {
+		return nil, fmt.Errorf("consent %s to KYC record %s is already revoked", receiptID, kycID)
+	}
+
+	grants, err := recordGrants(ctx, kycID)
+	if err != nil {
+		return nil, err
+	}
+	revoked := []*AccessGrant{}
+	for _, grant := range grants {
+		if grant.ConsentReceiptID == receiptID && grant.RevokedAt == "" {
+			revoked = append(revoked, grant)
+		}
+	}
+
+	revocation, err := s.revoke(ctx, kyc, mspID, receiptID, revoked, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt.RevokedAt = revocation.RevokedAt
+	receipt.RevokedBy = revocation.RevokedBy
+	receipt.RevocationID = revocation.RevocationID
+	key, err := ctx.GetStub().CreateCompositeKey(consentObjectType, []string{kycID, receiptID})
+	if err != nil {
+		return nil, err
+	}
+	receiptJSON, err := json.Marshal(receipt)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(key, receiptJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put consent receipt: %v", err)
+	}
+	return revocation, emitRevocation(ctx, revocation)
+}
+
+// emitRevocation emits a record's revocation as the AccessRevoked event
+func emitRevocation(ctx contractapi.TransactionContextInterface, revocation *Revocation) error {
+	revocationJSON, err := json.Marshal(revocation)
+	if err != nil {
+		return err
+	}
+	return setEvent(ctx, accessRevokedEvent, revocationJSON)
+}
+
+// revoke marks grants revoked, stores the revocation and writes the history
+// entry. The caller emits the AccessRevoked event, since a transaction has
+// only one.
+func (s *SmartContract) revoke(ctx contractapi.TransactionContextInterface, kyc *KYCRecord, mspID string, receiptID string, grants []*AccessGrant, reason string) (*Revocation, error) {
+	revokedBy, err := clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	revocation := &Revocation{
+		RevocationID:     ctx.GetStub().GetTxID(),
+		KYCID:            kyc.ID,
+		OwnerMSP:         kyc.OwnerMSP,
+		ConsentReceiptID: receiptID,
+		Grantees:         []string{},
+		Scopes:           []RevokedScope{},
+		Reason:           reason,
+		RevokedBy:        revokedBy,
+		RevokedAt:        s.txTime(ctx).Format(time.RFC3339),
+	}
+	if revocation.OwnerMSP == "" {
+		revocation.OwnerMSP = mspID
+	}
+	for _, grant := range grants {
+		grant.RevokedAt = revocation.RevokedAt
+		grant.RevokedBy = revokedBy
+		grant.RevocationID = revocation.RevocationID
+		err = putGrant(ctx, grant)
+		if err != nil {
+			return nil, err
+		}
+		revocation.Grantees = append(revocation.Grantees, grant.Grantee)
+		revocation.Scopes = append(revocation.Scopes, RevokedScope{
+			GrantID: grant.GrantID,
+			Grantee: grant.Grantee,
+			Sponsor: grant.Sponsor,
+			Fields:  grant.Fields,
+			Purpose: grant.Purpose,
+		})
+	}
+	revocation.Grantees = sortedUnique(revocation.Grantees)
+
+	key, err := ctx.GetStub().CreateCompositeKey(revocationObjectType, []string{kyc.ID, revocation.RevocationID})
+	if err != nil {
+		return nil, err
+	}
+	revocationJSON, err := json.Marshal(revocation)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(key, revocationJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put revocation: %v", err)
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-ACCESS_REVOKED-%d", kyc.ID, s.txTime(ctx).Unix()),
+		KYCID:       kyc.ID,
+		Action:      "ACCESS_REVOKED",
+		PerformedBy: revokedBy,
+		PerformedAt: revocation.RevokedAt,
+		TxID:        revocation.RevocationID,
+		Details: map[string]interface{}{
+			"consentReceiptId": receiptID,
+			"grantees":         revocation.Grantees,
+			"grants":           len(revocation.Scopes),
+			"reason":           reason,
+		},
+	}
+
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return revocation, nil
+}
+
+// AcknowledgeRevocation records that the caller's organisation, a grantee
+// of the revocation, has evicted the data it withdrew. Acknowledging again
+// keeps the first acknowledgement.
+func (s *SmartContract) AcknowledgeRevocation(ctx contractapi.TransactionContextInterface, kycID string, revocationID string) error {
+	revocation, err := getRevocation(ctx, kycID, revocationID)
+	if err != nil {
+		return err
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	granted := false
+	for _, grantee := range revocation.Grantees {
+		if grantee == mspID {
+			granted = true
+		}
+	}
+	if !granted {
+		return fmt.Errorf("%s is not a grantee of revocation %s", mspID, revocationID)
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(revocationAckObjectType, []string{kycID, revocationID, mspID})
+	if err != nil {
+		return err
+	}
+	existing, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if existing != nil {
+		return nil
+	}
+	acknowledgedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	ack := RevocationAck{
+		RevocationID:   revocationID,
+		KYCID:          kycID,
+		MSPID:          mspID,
+		AcknowledgedBy: acknowledgedBy,
+		AcknowledgedAt: s.txTime(ctx).Format(time.RFC3339),
+		TxID:           ctx.GetStub().GetTxID(),
+	}
+	ackJSON, err := json.Marshal(ack)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, ackJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put revocation acknowledgement: %v", err)
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-REVOCATION_ACKNOWLEDGED-%d", kycID, s.txTime(ctx).Unix()),
+		KYCID:       kycID,
+		Action:      "REVOCATION_ACKNOWLEDGED",
+		PerformedBy: acknowledgedBy,
+		PerformedAt: ack.AcknowledgedAt,
+		TxID:        ack.TxID,
+		Details: map[string]interface{}{
+			"revocationId": revocationID,
+			"msp":          mspID,
+		},
+	}
+
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return nil
+}
+
+// GetRevocation returns a revocation of a record, with the grantees'
+// acknowledgements and the grantees yet to acknowledge
+func (s *SmartContract) GetRevocation(ctx contractapi.TransactionContextInterface, kycID string, revocationID string) (*Revocation, error) {
+	revocation, err := getRevocation(ctx, kycID, revocationID)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(revocationAckObjectType, []string{kycID, revocationID})
+	if err != nil {
+		return nil, err
+	}
+	acknowledged := map[string]bool{}
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		var ack RevocationAck
+		err := json.Unmarshal(queryResponse.Value, &ack)
+		if err != nil {
+			return err
+		}
+		revocation.Acknowledgements = append(revocation.Acknowledgements, ack)
+		acknowledged[ack.MSPID] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, grantee := range revocation.Grantees {
+		if !acknowledged[grantee] {
+			revocation.Pending = append(revocation.Pending, grantee)
+		}
+	}
+	return revocation, nil
+}
+
+// getRevocation loads a stored revocation
+func getRevocation(ctx contractapi.TransactionContextInterface, kycID string, revocationID string) (*Revocation, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(revocationObjectType, []string{kycID, revocationID})
+	if err != nil {
+		return nil, err
+	}
+	revocationJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if revocationJSON == nil {
+		return nil, fmt.Errorf("KYC record %s has no revocation %s", kycID, revocationID)
+	}
+
+	var revocation Revocation
+	err = json.Unmarshal(revocationJSON, &revocation)
+	if err != nil {
+		return nil, err
+	}
+	return &revocation, nil
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Risk tiers, from lowest to highest
+const (
+	RiskLow    = "LOW"
+	RiskMedium = "MEDIUM"
+	RiskHigh   = "HIGH"
+)
+
+const riskRecalculationObjectType = "riskRecalculation"
+
+// riskTierRank orders the risk tiers so a tier can only be escalated upwards
+var riskTierRank = map[string]int{
+	RiskLow:    1,
+	RiskMedium: 2,
+	RiskHigh:   3,
+}
+
+// RiskTierChange records one record whose tier a recalculation changed
+type RiskTierChange struct {
+	KYCID   string `json:"kycId"`
+	OldTier string `json:"oldTier"`
+	NewTier string `json:"newTier"`
+	Score   int    `json:"score"`
+}
+
+// RiskRecalculationRun summarises one page of a risk tier recalculation
+type RiskRecalculationRun struct {
+	RunID       string           `json:"runId"`
+	RuleVersion int              `json:"ruleVersion"`
+	Scanned     int              `json:"scanned"`
+	Changed     int              `json:"changed"`
+	Changes     []RiskTierChange `json:"changes"`
+	Bookmark    string           `json:"bookmark"`
+	RunBy       string           `json:"runBy"`
+	RunAt       string           `json:"runAt"`
+}
+
+// RecalculateRiskBatch re-applies the risk rules in force to one page of
+// records, taken in key order from the bookmark. Only records whose tier
+// changes are rewritten; a summary of the run is stored under its
+// transaction ID.
+func (s *SmartContract) RecalculateRiskBatch(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*RiskRecalculationRun, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	runBy, err := clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+	rules, err := loadRiskRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &RiskRecalculationRun{
+		RunID:       ctx.GetStub().GetTxID(),
+		RuleVersion: rules.Version,
+		Changes:     []RiskTierChange{},
+		RunBy:       runBy,
+		RunAt:       s.txTime(ctx).Format(time.RFC3339),
+	}
+	// the rewrites rule out paginated queries
+	next, done, err := scanCompositeKeys(ctx, recordObjectType, bookmark, int(pageSize), func(key string, value []byte) (bool, error) {
+		var kyc KYCRecord
+		err := unmarshalRecord(value, &kyc)
+		if err != nil {
+			return false, err
+		}
+		run.Scanned++
+
+		oldTier := kyc.RiskTier
+		score, newTier := scoreRisk(&kyc, rules)
+		if newTier == oldTier {
+			return true, nil
+		}
+		assessRisk(&kyc, rules)
+		kyc.UpdatedAt = run.RunAt
+
+		kycJSON, err := json.Marshal(kyc)
+		if err != nil {
+			return false, err
+		}
+		err = putRecordState(ctx, kyc.ID, kycJSON)
+		if err != nil {
+			return false, fmt.Errorf("failed to update KYC record: %v", err)
+		}
+
+		historyEntry := HistoryEntry{
+			ID:          fmt.Sprintf("%s-RISK_RECALCULATED-%d", kyc.ID, s.txTime(ctx).Unix()),
+			KYCID:       kyc.ID,
+			Action:      "RISK_RECALCULATED",
+			PerformedBy: runBy,
+			PerformedAt: run.RunAt,
+			TxID:        run.RunID,
+			Details: map[string]interface{}{
+				"oldTier":     oldTier,
+				"newTier":     newTier,
+				"score":       score,
+				"ruleVersion": rules.Version,
+			},
+		}
+		err = s.createHistoryEntry(ctx, historyEntry)
+		if err != nil {
+			return false, fmt.Errorf("failed to create history entry: %v", err)
+		}
+
+		run.Changes = append(run.Changes, RiskTierChange{KYCID: kyc.ID, OldTier: oldTier, NewTier: newTier, Score: score})
+		run.Changed++
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !done {
+		run.Bookmark = next
+	}
+
+	runKey, err := ctx.GetStub().CreateCompositeKey(riskRecalculationObjectType, []string{run.RunID})
+	if err != nil {
+		return nil, err
+	}
+	runJSON, err := json.Marshal(run)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(runKey, runJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store recalculation run: %v", err)
+	}
+
+	return run, nil
+}
+
+// GetRiskRecalculationRun returns the summary of a past recalculation run
+func (s *SmartContract) GetRiskRecalculationRun(ctx contractapi.TransactionContextInterface, runID string) (*RiskRecalculationRun, error) {
+	runKey, err := ctx.GetStub().CreateCompositeKey(riskRecalculationObjectType, []string{runID})
+	if err != nil {
+		return nil, err
+	}
+	runJSON, err := ctx.GetStub().GetState(runKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if runJSON == nil {
+		return nil, fmt.Errorf("recalculation run %s does not exist", runID)
+	}
+
+	var run RiskRecalculationRun
+	err = json.Unmarshal(runJSON, &run)
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// escalateRiskTier raises a record's risk tier to at least tier and reports
+// whether it changed. A record is never lowered by escalation.
+func escalateRiskTier(kyc *KYCRecord, tier string) bool {
+	if riskTierRank[tier] <= riskTierRank[kyc.RiskTier] {
+		return false
+	}
+	kyc.RiskTier = tier
+	return true
+}
+
+// hasAdverseMedia reports whether a record has an adverse media finding of the given severity
+func hasAdverseMedia(kyc *KYCRecord, severity string) bool {
+	for _, finding := range kyc.AdverseMedia {
+		if finding.Severity == severity {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// RiskOverride is a dual-approved exception allowing a HIGH risk customer to be verified
+type RiskOverride struct {
+	Status        string `json:"status"` // REQUESTED, APPROVED
+	Justification string `json:"justification"`
+	RiskTier      string `json:"riskTier"` // tier at the time of the request
+	RiskScore     int    `json:"riskScore"`
+	RequestedBy   string `json:"requestedBy"`
+	RequestedAt   string `json:"requestedAt"`
+	ApprovedBy    string `json:"approvedBy,omitempty" metadata:",optional"`
+	ApprovedAt    string `json:"approvedAt,omitempty" metadata:",optional"`
+	Remarks       string `json:"remarks,omitempty" metadata:",optional"`
+}
+
+// RequestRiskOverride asks for a HIGH risk record to be allowed through verification
+func (s *SmartContract) RequestRiskOverride(ctx contractapi.TransactionContextInterface, kycID string, justification string) error {
+	err := requireAttribute(ctx, AttrVerifier)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(justification) == "" {
+		return fmt.Errorf("justification is required")
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	if kyc.RiskTier != RiskHigh {
+		return fmt.Errorf("KYC record %s is not HIGH risk", kycID)
+	}
+	if kyc.RiskOverride != nil {
+		return fmt.Errorf("KYC record %s already has a %s risk override", kycID, strings.ToLower(kyc.RiskOverride.Status))
+	}
+
+	requestedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	kyc.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+	kyc.RiskOverride = &RiskOverride{
+		Status:        OverrideRequested,
+		Justification: justification,
+		RiskTier:      kyc.RiskTier,
+		RiskScore:     kyc.RiskScore,
+		RequestedBy:   requestedBy,
+		RequestedAt:   kyc.UpdatedAt,
+	}
+
+	return s.saveRiskOverride(ctx, kyc, "RISK_OVERRIDE_REQUESTED", requestedBy, justification)
+}
+
+// ApproveRiskOverride approves a pending risk override. Approval needs the
+// senior role and must come from a different identity than the request.
+func (s *SmartContract) ApproveRiskOverride(ctx contractapi.TransactionContextInterface, kycID string, remarks string) error {
+	err := requireAttribute(ctx, AttrSenior)
+	if err != nil {
+		return err
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	if kyc.RiskOverride == nil || kyc.RiskOverride.Status != OverrideRequested {
+		return fmt.Errorf("KYC record %s has no pending risk override", kycID)
+	}
+
+	approvedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	if approvedBy == kyc.RiskOverride.RequestedBy {
+		return fmt.Errorf("a risk override cannot be approved by its requester")
+	}
+
+	kyc.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+	override := kyc.RiskOverride
+	override.Status = OverrideApproved
+	override.ApprovedBy = approvedBy
+	override.ApprovedAt = kyc.UpdatedAt
+	override.Remarks = remarks
+
+	err = s.saveRiskOverride(ctx, kyc, "RISK_OVERRIDE_APPROVED", approvedBy, remarks)
+	if err != nil {
+		return err
+	}
+
+	return recordException(ctx, ExceptionEntry{
+		Type:          ExceptionRiskOverride,
+		KYCID:         kyc.ID,
+		RaisedAt:      kyc.UpdatedAt,
+		RequestedBy:   override.RequestedBy,
+		ApprovedBy:    approvedBy,
+		Justification: override.Justification,
+		Details: map[string]interface{}{
+			"riskTier":  override.RiskTier,
+			"riskScore": override.RiskScore,
+		},
+	})
+}
+
+// GetRiskOverrides returns one page of approved risk overrides from the exception register, oldest first
+func (s *SmartContract) GetRiskOverrides(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*ExceptionPage, error) {
+	return s.GetExceptions(ctx, "", "", ExceptionRiskOverride, pageSize, bookmark)
+}
+
+// saveRiskOverride stores a record after a risk override step and writes its history entry
+func (s *SmartContract) saveRiskOverride(ctx contractapi.TransactionContextInterface, kyc *KYCRecord, action string, performedBy string, remarks string) error {
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return err
+	}
+
+	err = putRecordState(ctx, kyc.ID, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update KYC record: %v", err)
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-%s-%d", kyc.ID, action, s.txTime(ctx).Unix()),
+		KYCID:       kyc.ID,
+		Action:      action,
+		PerformedBy: performedBy,
+		PerformedAt: kyc.UpdatedAt,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"riskTier":       kyc.RiskOverride.RiskTier,
+			"riskScore":      kyc.RiskOverride.RiskScore,
+			"overrideStatus": kyc.RiskOverride.Status,
+		},
+		Remarks: remarks,
+	}
+
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	riskRulesKey          = "RISKRULES"
+	riskRuleSetObjectType = "riskRuleSet"
+	maxRiskFactorWeight   = 1000
+)
+
+// riskFactors are the record conditions a rule set can weight
+var riskFactors = map[string]func(kyc *KYCRecord) bool{
+	"NON_INDIVIDUAL":       func(kyc *KYCRecord) bool { return kyc.EntityType != EntityIndividual },
+	"FOREIGN_ADDRESS":      func(kyc *KYCRecord) bool { return !isIndianAddress(kyc.Address) },
+	"DUPLICATE_PHONE":      func(kyc *KYCRecord) bool { return hasFlag(kyc, FlagDuplicatePhone) },
+	"DUPLICATE_FACE":       func(kyc *KYCRecord) bool { return hasFlag(kyc, FlagDuplicateFace) },
+	"BLOCKED_EMAIL_DOMAIN": func(kyc *KYCRecord) bool { return hasFlag(kyc, FlagBlockedEmailDomain) },
+	"PINCODE_MISMATCH":     func(kyc *KYCRecord) bool { return hasFlag(kyc, FlagPincodeStateMismatch) },
+	"ADVERSE_MEDIA_MEDIUM": func(kyc *KYCRecord) bool { return hasAdverseMedia(kyc, "MEDIUM") },
+	"ADVERSE_MEDIA_HIGH":   func(kyc *KYCRecord) bool { return hasAdverseMedia(kyc, "HIGH") },
+	"OCR_MISMATCH":         func(kyc *KYCRecord) bool { return hasFlag(kyc, FlagOCRMismatch) },
+	"BLACKLIST_MATCH":      func(kyc *KYCRecord) bool { return kyc.Blacklist != nil },
+	"WATCHLIST_MATCH":      func(kyc *KYCRecord) bool { return kyc.Screening != nil && kyc.Screening.Score >= 85 },
+}
+
+// RiskRuleSet weights risk factors into a score and maps the score to a tier.
+// Every change is stored as a new version so past scores stay explainable.
+type RiskRuleSet struct {
+	Version    int            `json:"version"`
+	Factors    []RiskFactor   `json:"factors"`
+	Thresholds RiskThresholds `json:"thresholds"`
+	UpdatedAt  string         `json:"updatedAt,omitempty" metadata:",optional"`
+	UpdatedBy  string         `json:"updatedBy,omitempty" metadata:",optional"`
+}
+
+// RiskFactor adds Weight to a record's score when the named factor applies
+type RiskFactor struct {
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+}
+
+// RiskThresholds are the minimum scores of the MEDIUM and HIGH tiers
+type RiskThresholds struct {
+	Medium int `json:"medium"`
+	High   int `json:"high"`
+}
+
+// defaultRiskRules is rule set version 0, in force until SetRiskRules is first called
+func defaultRiskRules() *RiskRuleSet {
+	return &RiskRuleSet{
+		Factors: []RiskFactor{
+			{"NON_INDIVIDUAL", 40},
+			{"FOREIGN_ADDRESS", 40},
+			{"DUPLICATE_PHONE", 40},
+			{"BLOCKED_EMAIL_DOMAIN", 40},
+			{"ADVERSE_MEDIA_MEDIUM", 40},
+			{"ADVERSE_MEDIA_HIGH", 100},
+			{"BLACKLIST_MATCH", 100},
+			{"WATCHLIST_MATCH", 100},
+		},
+		Thresholds: RiskThresholds{Medium: 40, High: 100},
+	}
+}
+
+// SetRiskRules stores a new version of the risk rules. Records are rescored
+// against it as they are created or recalculated.
+func (s *SmartContract) SetRiskRules(ctx contractapi.TransactionContextInterface, rulesJSON string) (*RiskRuleSet, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules RiskRuleSet
+	err = json.Unmarshal([]byte(rulesJSON), &rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal risk rules: %v", err)
+	}
+	err = validateRiskRules(&rules)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := loadRiskRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules.Version = current.Version + 1
+	rules.UpdatedBy, err = clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+	rules.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+
+	rulesData, err := json.Marshal(rules)
+	if err != nil {
+		return nil, err
+	}
+	versionKey, err := ctx.GetStub().CreateCompositeKey(riskRuleSetObjectType, []string{fmt.Sprintf("%06d", rules.Version)})
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(versionKey, rulesData)
+	if err != nil {
+		return nil, err
+	}
+	return &rules, ctx.GetStub().PutState(riskRulesKey, rulesData)
+}
+
+// GetRiskRules returns a version of the risk rules, or the rules in force when version is negative
+func (s *SmartContract) GetRiskRules(ctx contractapi.TransactionContextInterface, version int) (*RiskRuleSet, error) {
+	if version < 0 {
+		return loadRiskRules(ctx)
+	}
+	if version == 0 {
+		return defaultRiskRules(), nil
+	}
+
+	versionKey, err := ctx.GetStub().CreateCompositeKey(riskRuleSetObjectType, []string{fmt.Sprintf("%06d", version)})
+	if err != nil {
+		return nil, err
+	}
+	rulesData, err := ctx.GetStub().GetState(versionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if rulesData == nil {
+		return nil, fmt.Errorf("risk rules version %d does not exist", version)
+	}
+
+	var rules RiskRuleSet
+	err = json.Unmarshal(rulesData, &rules)
+	if err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+// loadRiskRules returns the risk rules in force
+func loadRiskRules(ctx contractapi.TransactionContextInterface) (*RiskRuleSet, error) {
+	rulesData, err := ctx.GetStub().GetState(riskRulesKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read risk rules: %v", err)
+	}
+	if rulesData == nil {
+		return defaultRiskRules(), nil
+	}
+
+	var rules RiskRuleSet
+	err = json.Unmarshal(rulesData, &rules)
+	if err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+func validateRiskRules(rules *RiskRuleSet) error {
+	if len(rules.Factors) == 0 {
+		return fmt.Errorf("at least one risk factor is required")
+	}
+	seen := map[string]bool{}
+	for _, factor := range rules.Factors {
+		if _, ok := riskFactors[factor.Name]; !ok {
+			return fmt.Errorf("unknown risk factor %q", factor.Name)
+		}
+		if seen[factor.Name] {
+			return fmt.Errorf("risk factor %s is listed more than once", factor.Name)
+		}
+		seen[factor.Name] = true
+		if factor.Weight < 0 || factor.Weight > maxRiskFactorWeight {
+			return fmt.Errorf("weight of risk factor %s must be between 0 and %d", factor.Name, maxRiskFactorWeight)
+		}
+	}
+	if rules.Thresholds.Medium <= 0 || rules.Thresholds.High < rules.Thresholds.Medium {
+		return fmt.Errorf("thresholds must satisfy 0 < medium <= high")
+	}
+	return nil
+}
+
+// scoreRisk applies a rule set to a record, returning its score and tier
+func scoreRisk(kyc *KYCRecord, rules *RiskRuleSet) (int, string) {
+	score := 0
+	for _, factor := range rules.Factors {
+		if applies, ok := riskFactors[factor.Name]; ok && applies(kyc) {
+			score += factor.Weight
+		}
+	}
+
+	switch {
+	case score >= rules.Thresholds.High:
+		return score, RiskHigh
+	case score >= rules.Thresholds.Medium:
+		return score, RiskMedium
+	default:
+		return score, RiskLow
+	}
+}
+
+// assessRisk scores a record and stores the score, tier and rule version on it
+func assessRisk(kyc *KYCRecord, rules *RiskRuleSet) {
+	kyc.RiskScore, kyc.RiskTier = scoreRisk(kyc, rules)
+	kyc.RiskRuleVersion = rules.Version
+}
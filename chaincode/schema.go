@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Record schemas. Schema 1 records carry no schemaVersion and the earliest
+// of them hold some fields in legacy forms, which unmarshalRecord upgrades on
+// read. Schema 2 records carry schemaVersion 2. Records are stored as is
+// under their record key (see keys.go); while the dualWriteRecords config
+// setting is on, every record write and delete also goes to a copy under
+// recordPrefix+ID, wrapped in a recordDocument, for readers of the prefixed
+// schema.
+const (
+	recordSchema = 2
+	recordPrefix = "RECORD_"
+)
+
+// recordDocument is a record as schema 2 stores it. The record is nested,
+// as in the archive, so rich queries selecting schema 1 records by their
+// top-level fields do not also match the schema 2 copy.
+type recordDocument struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Record        json.RawMessage `json:"record"`
+}
+
+// SchemaBackfillResult summarises one page of a schema 2 backfill
+type SchemaBackfillResult struct {
+	Scanned  int    `json:"scanned"`
+	Written  int    `json:"written"` // records whose schema 2 copy was missing or out of date
+	Bookmark string `json:"bookmark"`
+}
+
+func recordKey(kycID string) string {
+	return recordPrefix + kycID
+}
+
+// unmarshalRecord decodes a stored record of any schema, upgrading a schema
+// 1 record to the current shape in memory. The stored record is upgraded
+// the next time it is written. A document without a status is not a record
+// and is decoded as is, for range scans to skip.
+func unmarshalRecord(data []byte, kyc *KYCRecord) error {
+	err := json.Unmarshal(data, kyc)
+	if err == nil && (kyc.SchemaVersion >= recordSchema || kyc.Status == "") {
+		return nil
+	}
+	upgraded, upgradeErr := upgradeLegacyRecord(data)
+	if upgradeErr != nil {
+		if err != nil {
+			return err
+		}
+		return upgradeErr
+	}
+	*kyc = KYCRecord{}
+	return json.Unmarshal(upgraded, kyc)
+}
+
+// upgradeLegacyRecord rewrites a schema 1 record in the current shape. The
+// first gateway releases submitted the address and the document hashes as
+// JSON-encoded strings, the document hashes as bare hashes, and the
+// creation time as submittedAt; records from before entity types default
+// to individuals, as CreateKYC does.
+func upgradeLegacyRecord(data []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range []string{"address", "documentHashes"} {
+		raw := fields[name]
+		if len(raw) == 0 || raw[0] != '"' {
+			continue
+		}
+		var encoded string
+		err = json.Unmarshal(raw, &encoded)
+		if err != nil {
+			return nil, err
+		}
+		if !json.Valid([]byte(encoded)) {
+			return nil, fmt.Errorf("legacy %s %q is not JSON", name, encoded)
+		}
+		fields[name] = json.RawMessage(encoded)
+	}
+
+	var documents []json.RawMessage
+	if json.Unmarshal(fields["documentHashes"], &documents) == nil && documents != nil {
+		for i, document := range documents {
+			var hash string
+			if json.Unmarshal(document, &hash) != nil {
+				continue
+			}
+			documents[i], err = json.Marshal(DocumentHash{Hash: hash})
+			if err != nil {
+				return nil, err
+			}
+		}
+		fields["documentHashes"], err = json.Marshal(documents)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, ok := fields["createdAt"]; !ok && fields["submittedAt"] != nil {
+		fields["createdAt"] = fields["submittedAt"]
+	}
+	delete(fields, "submittedAt")
+
+	var entityType string
+	if json.Unmarshal(fields["entityType"], &entityType) != nil || entityType == "" {
+		fields["entityType"] = json.RawMessage(`"` + EntityIndividual + `"`)
+	}
+	fields["schemaVersion"] = json.RawMessage(fmt.Sprint(recordSchema))
+	return json.Marshal(fields)
+}
+
+// putRecordState writes a record's JSON under its typed key, moving it off
+// its legacy key, and while dual writes are on under the schema 2 key as
+// well. It keeps the record's lookup indexes in step.
+func putRecordState(ctx contractapi.TransactionContextInterface, kycID string, kycJSON []byte) error {
+	previous, err := noteRecordChange(ctx, kycID, kycJSON)
+	if err != nil {
+		return err
+	}
+	key, err := recordStateKey(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, kycJSON)
+	if err != nil {
+		return err
+	}
+	err = updateLookupIndexes(ctx, kycID, previous, kycJSON)
+	if err != nil {
+		return err
+	}
+	err = deleteLegacyRecord(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	config, err := loadConfig(ctx)
+	if err != nil || !config.DualWriteRecords {
+		return err
+	}
+	_, err = putRecordDocument(ctx, kycID, kycJSON)
+	return err
+}
+
+// deleteRecordState deletes a record under its typed or legacy key, with its
+// lookup index entries and, while dual writes are on, its schema 2 copy
+func deleteRecordState(ctx contractapi.TransactionContextInterface, kycID string) error {
+	previous, err := noteRecordChange(ctx, kycID, nil)
+	if err != nil {
+		return err
+	}
+	key, err := recordStateKey(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().DelState(key)
+	if err != nil {
+		return err
+	}
+	err = updateLookupIndexes(ctx, kycID, previous, nil)
+	if err != nil {
+		return err
+	}
+	err = deleteLegacyRecord(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	config, err := loadConfig(ctx)
+	if err != nil || !config.DualWriteRecords {
+		return err
+	}
+	return ctx.GetStub().DelState(recordKey(kycID))
+}
+
+// putRecordDocument writes a record's schema 2 copy unless it already holds
+// the same record, and reports whether it wrote
+func putRecordDocument(ctx contractapi.TransactionContextInterface, kycID string, kycJSON []byte) (bool, error) {
+	documentJSON, err := json.Marshal(recordDocument{SchemaVersion: recordSchema, Record: kycJSON})
+	if err != nil {
+		return false, err
+	}
+	existing, err := ctx.GetStub().GetState(recordKey(kycID))
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if bytes.Equal(existing, documentJSON) {
+		return false, nil
+	}
+	return true, ctx.GetStub().PutState(recordKey(kycID), documentJSON)
+}
+
+// BackfillRecordSchema writes the schema 2 copy of one page of records
+// written before dual writes were turned on. Run it over every page after
+// turning dualWriteRecords on, and again if dual writes were ever turned off
+// during the migration window, since copies go stale while they are off.
+// Only records under their typed keys are copied, so run MigrateKeys first.
+func (s *SmartContract) BackfillRecordSchema(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*SchemaBackfillResult, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !config.DualWriteRecords {
+		return nil, fmt.Errorf("dualWriteRecords is off; turn it on with SetConfig before backfilling")
+	}
+
+	err = checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	// the copies are writes, which rule out paginated queries; the records
+	// are read by key, so the reads are checked again at commit as a rich
+	// query's would not be
+	result := &SchemaBackfillResult{}
+	next, done, err := scanCompositeKeys(ctx, recordObjectType, bookmark, int(pageSize), func(key string, value []byte) (bool, error) {
+		var kyc KYCRecord
+		err := json.Unmarshal(value, &kyc)
+		if err != nil {
+			return false, err
+		}
+		written, err := putRecordDocument(ctx, kyc.ID, value)
+		if err != nil {
+			return false, fmt.Errorf("failed to write schema %d copy of %s: %v", recordSchema, kyc.ID, err)
+		}
+		result.Scanned++
+		if written {
+			result.Written++
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !done {
+		result.Bookmark = next
+	}
+	return result, nil
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// matchAlgorithm identifies the name matching algorithm recorded with each
+// score; bump it whenever matchScore changes so old scores stay attributable
+const matchAlgorithm = "JW-TOKENSORT-1"
+
+// matchScorePrefix keys the score index. Keys are ordered from the highest
+// score down so a range scan returns the strongest matches first.
+const matchScorePrefix = "MATCHSCORE_"
+
+// ScreeningMatch is the best watchlist match found by the latest screening
+type ScreeningMatch struct {
+	RunID       string `json:"runId"`
+	Score       int    `json:"score"` // 0-100
+	List        string `json:"list"`
+	ListVersion int    `json:"listVersion"`
+	EntryKey    string `json:"entryKey,omitempty" metadata:",optional"` // empty when the list had no entries
+	Algorithm   string `json:"algorithm"`
+	ScreenedAt  string `json:"screenedAt"`
+}
+
+// ScreenKYC scores a record's name against every entry of a watchlist,
+// stores the best match on the record and appends the run to the record's
+// screening history. Entries are matched on their "name" attribute, or on
+// the entry key when the attribute is absent.
+func (s *SmartContract) ScreenKYC(ctx contractapi.TransactionContextInterface, kycID string, listName string) (*ScreeningMatch, error) {
+	err := requireAttribute(ctx, AttrVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := loadList(ctx, listName)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := listEntries(ctx, list.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// iterate in key order so ties resolve to the same entry on every peer
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	name := normalizePersonName(kyc.Name)
+	match := &ScreeningMatch{
+		RunID:       ctx.GetStub().GetTxID(),
+		List:        list.Name,
+		ListVersion: list.Version,
+		Algorithm:   matchAlgorithm,
+		ScreenedAt:  s.txTime(ctx).Format(time.RFC3339),
+	}
+	for _, key := range keys {
+		entryName := entries[key].Attributes["name"]
+		if entryName == "" {
+			entryName = key
+		}
+		score := matchScore(name, normalizePersonName(entryName))
+		if match.EntryKey == "" || score > match.Score {
+			match.Score = score
+			match.EntryKey = key
+		}
+	}
+
+	err = recordScreeningRun(ctx, kyc.ID, *match)
+	if err != nil {
+		return nil, err
+	}
+	err = clearStaleScreening(ctx, list.Name, kyc.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = deleteMatchScoreIndex(ctx, kyc)
+	if err != nil {
+		return nil, err
+	}
+	kyc.Screening = match
+	kyc.UpdatedAt = match.ScreenedAt
+
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return nil, err
+	}
+	err = putRecordState(ctx, kyc.ID, kycJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update KYC record: %v", err)
+	}
+	err = ctx.GetStub().PutState(matchScoreKey(match.Score, kyc.ID), []byte{0x00})
+	if err != nil {
+		return nil, fmt.Errorf("failed to index KYC record: %v", err)
+	}
+
+	return match, nil
+}
+
+// GetRecordsAboveMatchScore returns records whose latest screening scored at
+// least threshold, strongest matches first
+func (s *SmartContract) GetRecordsAboveMatchScore(ctx contractapi.TransactionContextInterface, threshold int, pageSize int32, bookmark string, purposeCode string) (*PaginatedQueryResult, error) {
+	if threshold < 0 || threshold > 100 {
+		return nil, fmt.Errorf("threshold must be between 0 and 100")
+	}
+	purpose, err := newReadPurpose(ctx, "GetRecordsAboveMatchScore", purposeCode)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	budget, err := newResponseBudget(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the rate limit's bucket and the access logs rule out paginated queries
+	result := &PaginatedQueryResult{Records: []*KYCRecord{}}
+	next, done, err := scanKeyRange(ctx, matchScoreKey(100, ""), matchScoreKey(threshold-1, ""), bookmark, int(pageSize), func(key string, value []byte) (bool, error) {
+		kyc, err := s.readKYC(ctx, key[len(matchScoreKey(0, "")):])
+		if err != nil {
+			return false, err
+		}
+		visible, grant, err := purpose.permits(ctx, kyc)
+		if err != nil {
+			return false, err
+		}
+		if visible == nil {
+			return true, nil
+		}
+		fits, err := budget.fits(visible)
+		if err != nil {
+			return false, err
+		}
+		if !fits {
+			result.Truncated = true
+			return false, nil
+		}
+		err = purpose.record(ctx, kyc, grant)
+		if err != nil {
+			return false, err
+		}
+		result.Records = append(result.Records, visible)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !done {
+		result.Bookmark = next
+	}
+
+	result.FetchedRecordsCount = int32(len(result.Records))
+	return result, nil
+}
+
+// matchScoreKey returns the score index key for a record; the score is stored
+// inverted and zero-padded so keys sort from 100 down to 0
+func matchScoreKey(score int, kycID string) string {
+	return fmt.Sprintf("%s%03d_%s", matchScorePrefix, 100-score, kycID)
+}
+
+// deleteMatchScoreIndex removes a record's score index entry, if it has one
+func deleteMatchScoreIndex(ctx contractapi.TransactionContextInterface, kyc *KYCRecord) error {
+	if kyc.Screening == nil {
+		return nil
+	}
+	return ctx.GetStub().DelState(matchScoreKey(kyc.Screening.Score, kyc.ID))
+}
+
+// matchScore rates the similarity of two normalized names from 0 to 100 as
+// the better of their Jaro-Winkler similarity and that of their tokens
+// sorted alphabetically, so reordered names ("sharma rajesh") still match
+func matchScore(a string, b string) int {
+	score := jaroWinkler(a, b)
+	if sorted := jaroWinkler(sortTokens(a), sortTokens(b)); sorted > score {
+		score = sorted
+	}
+	// explicit conversions stop the compiler fusing multiply-adds, which
+	// would let peers on different architectures disagree on a score
+	return int(float64(score*100) + 0.5)
+}
+
+func sortTokens(name string) string {
+	tokens := strings.Fields(name)
+	sort.Strings(tokens)
+	return strings.Join(tokens, " ")
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of two strings in [0, 1]
+func jaroWinkler(a string, b string) float64 {
+	s1, s2 := []rune(a), []rune(b)
+	if len(s1) == 0 || len(s2) == 0 {
+		if len(s1) == len(s2) {
+			return 1
+		}
+		return 0
+	}
+
+	window := len(s1)
+	if len(s2) > window {
+		window = len(s2)
+	}
+	window = window/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	matched1 := make([]bool, len(s1))
+	matched2 := make([]bool, len(s2))
+	matches := 0
+	for i := range s1 {
+		lo, hi := i-window, i+window+1
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > len(s2) {
+			hi = len(s2)
+		}
+		for j := lo; j < hi; j++ {
+			if !matched2[j] && s1[i] == s2[j] {
+				matched1[i], matched2[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions, j := 0, 0
+	for i := range s1 {
+		if !matched1[i] {
+			continue
+		}
+		for !matched2[j] {
+			j++
+		}
+		if s1[i] != s2[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s1)) + m/float64(len(s2)) + (m-float64(transpositions/2))/m) / 3
+
+	prefix := 0
+	for prefix < 4 && prefix < len(s1) && prefix < len(s2) && s1[prefix] == s2[prefix] {
+		prefix++
+	}
+	return jaro + float64(float64(prefix)*0.1*(1-jaro))
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+const (
+	screeningRunObjectType = "screeningRun"
+	openAlertIndex         = "openalert~kycid~runid"
+)
+
+// Screening dispositions
+const (
+	DispositionPendingReview = "PENDING_REVIEW"
+	DispositionFalsePositive = "FALSE_POSITIVE"
+	DispositionTrueMatch     = "TRUE_MATCH"
+)
+
+// ScreeningRun is one screening of a record, kept alongside every earlier run
+type ScreeningRun struct {
+	RunID        string                 `json:"runId"`
+	KYCID        string                 `json:"kycId"`
+	Match        ScreeningMatch         `json:"match"`
+	ScreenedBy   string                 `json:"screenedBy"`
+	Disposition  string                 `json:"disposition,omitempty" metadata:",optional"` // current disposition; empty when the run raised no alert
+	Dispositions []ScreeningDisposition `json:"dispositions"`                               // every disposition in the order recorded
+}
+
+// ScreeningDisposition is an analyst's decision on a screening alert
+type ScreeningDisposition struct {
+	Disposition string `json:"disposition"` // PENDING_REVIEW, FALSE_POSITIVE, TRUE_MATCH
+	DecidedBy   string `json:"decidedBy"`
+	DecidedAt   string `json:"decidedAt"`
+	Remarks     string `json:"remarks,omitempty" metadata:",optional"`
+}
+
+// ScreeningAlertPage holds one page of open screening alerts
+type ScreeningAlertPage struct {
+	Alerts              []*ScreeningRun `json:"alerts"`
+	FetchedRecordsCount int32           `json:"fetchedRecordsCount"`
+	Bookmark            string          `json:"bookmark"`
+	Truncated           bool            `json:"truncated"` // the page was cut short by maxResponseBytes
+}
+
+// SetScreeningDisposition records an analyst's disposition of a screening
+// run's alert. Earlier dispositions are kept, so an alert can be re-disposed
+// (for instance reopened as PENDING_REVIEW) without losing its history.
+func (s *SmartContract) SetScreeningDisposition(ctx contractapi.TransactionContextInterface, kycID string, runID string, disposition string, remarks string) error {
+	err := requireAttribute(ctx, AttrVerifier)
+	if err != nil {
+		return err
+	}
+
+	disposition = strings.ToUpper(disposition)
+	if disposition != DispositionPendingReview && disposition != DispositionFalsePositive && disposition != DispositionTrueMatch {
+		return fmt.Errorf("disposition must be %s, %s or %s", DispositionPendingReview, DispositionFalsePositive, DispositionTrueMatch)
+	}
+
+	run, err := readScreeningRun(ctx, kycID, runID)
+	if err != nil {
+		return err
+	}
+	if len(run.Dispositions) == 0 {
+		return fmt.Errorf("screening run %s of KYC record %s did not raise an alert", runID, kycID)
+	}
+	if disposition == run.Disposition {
+		return fmt.Errorf("screening run %s is already %s", runID, disposition)
+	}
+
+	decidedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	run.Disposition = disposition
+	run.Dispositions = append(run.Dispositions, ScreeningDisposition{
+		Disposition: disposition,
+		DecidedBy:   decidedBy,
+		DecidedAt:   s.txTime(ctx).Format(time.RFC3339),
+		Remarks:     remarks,
+	})
+	err = putScreeningRun(ctx, run)
+	if err != nil {
+		return err
+	}
+
+	if disposition == DispositionPendingReview {
+		return putOpenAlert(ctx, run)
+	}
+	return deleteOpenAlert(ctx, run)
+}
+
+// GetScreeningRuns returns every screening run of a record, oldest first
+func (s *SmartContract) GetScreeningRuns(ctx contractapi.TransactionContextInterface, kycID string) ([]*ScreeningRun, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(screeningRunObjectType, []string{kycID})
+	if err != nil {
+		return nil, err
+	}
+
+	runs := []*ScreeningRun{}
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		var run ScreeningRun
+		err := json.Unmarshal(queryResponse.Value, &run)
+		if err != nil {
+			return err
+		}
+		runs = append(runs, &run)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// run IDs are transaction IDs, so key order is not chronological
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].Match.ScreenedAt < runs[j].Match.ScreenedAt
+	})
+	return runs, nil
+}
+
+// GetOpenScreeningAlerts returns one page of screening runs awaiting review
+func (s *SmartContract) GetOpenScreeningAlerts(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*ScreeningAlertPage, error) {
+	err := checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	resultsIterator, responseMetadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(openAlertIndex, []string{}, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	budget, err := newResponseBudget(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := []*ScreeningRun{}
+	bookmark, truncated := responseMetadata.Bookmark, false
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		_, keyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		run, err := readScreeningRun(ctx, keyParts[0], keyParts[1])
+		if err != nil {
+			return nil, err
+		}
+		fits, err := budget.fits(run)
+		if err != nil {
+			return nil, err
+		}
+		if !fits {
+			bookmark, truncated = queryResponse.Key, true
+			break
+		}
+		alerts = append(alerts, run)
+	}
+
+	return &ScreeningAlertPage{
+		Alerts:              alerts,
+		FetchedRecordsCount: int32(len(alerts)),
+		Bookmark:            bookmark,
+		Truncated:           truncated,
+	}, nil
+}
+
+// recordScreeningRun appends a screening run for a record, opening an alert
+// when the match score reaches the configured threshold
+func recordScreeningRun(ctx contractapi.TransactionContextInterface, kycID string, match ScreeningMatch) error {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	screenedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	run := &ScreeningRun{
+		RunID:        match.RunID,
+		KYCID:        kycID,
+		Match:        match,
+		ScreenedBy:   screenedBy,
+		Dispositions: []ScreeningDisposition{},
+	}
+	if match.EntryKey != "" && match.Score >= config.ScreeningAlertThreshold {
+		run.Disposition = DispositionPendingReview
+		run.Dispositions = append(run.Dispositions, ScreeningDisposition{
+			Disposition: DispositionPendingReview,
+			DecidedBy:   screenedBy,
+			DecidedAt:   match.ScreenedAt,
+			Remarks:     fmt.Sprintf("match score %d reached alert threshold %d", match.Score, config.ScreeningAlertThreshold),
+		})
+		err = putOpenAlert(ctx, run)
+		if err != nil {
+			return err
+		}
+	}
+	return putScreeningRun(ctx, run)
+}
+
+func readScreeningRun(ctx contractapi.TransactionContextInterface, kycID string, runID string) (*ScreeningRun, error) {
+	runKey, err := ctx.GetStub().CreateCompositeKey(screeningRunObjectType, []string{kycID, runID})
+	if err != nil {
+		return nil, err
+	}
+	runJSON, err := ctx.GetStub().GetState(runKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if runJSON == nil {
+		return nil, fmt.Errorf("screening run %s of KYC record %s does not exist", runID, kycID)
+	}
+
+	var run ScreeningRun
+	err = json.Unmarshal(runJSON, &run)
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func putScreeningRun(ctx contractapi.TransactionContextInterface, run *ScreeningRun) error {
+	runKey, err := ctx.GetStub().CreateCompositeKey(screeningRunObjectType, []string{run.KYCID, run.RunID})
+	if err != nil {
+		return err
+	}
+	runJSON, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(runKey, runJSON)
+}
+
+func putOpenAlert(ctx contractapi.TransactionContextInterface, run *ScreeningRun) error {
+	openAlertKey, err := ctx.GetStub().CreateCompositeKey(openAlertIndex, []string{run.KYCID, run.RunID})
+	if err != nil {
+		return err
+	}
+	existing, err := ctx.GetStub().GetState(openAlertKey)
+	if err != nil || existing != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(openAlertKey, []byte{0x00})
+	if err != nil {
+		return err
+	}
+	return incrementCounter(ctx, counterOpenAlerts, 1)
+}
+
+func deleteOpenAlert(ctx contractapi.TransactionContextInterface, run *ScreeningRun) error {
+	openAlertKey, err := ctx.GetStub().CreateCompositeKey(openAlertIndex, []string{run.KYCID, run.RunID})
+	if err != nil {
+		return err
+	}
+	existing, err := ctx.GetStub().GetState(openAlertKey)
+	if err != nil || existing == nil {
+		return err
+	}
+	err = ctx.GetStub().DelState(openAlertKey)
+	if err != nil {
+		return err
+	}
+	return incrementCounter(ctx, counterOpenAlerts, -1)
+}
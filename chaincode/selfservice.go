@@ -0,0 +1,151 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Customer self-service. A customer whose certificate, issued by the
+// organisation that submitted their record, carries the record's userId as
+// its kyc.userId attribute can read the record's status with
+// GetMyKYCStatus. The response includes a proof: the status, level and
+// expiry with the hash of the record as it stands in world state and the
+// transaction of its latest history entry, base64-encoded JSON the
+// customer can hand to a relying party. The relying party checks it with
+// VerifyKYCStatusProof, which holds while the record is unchanged, learning
+// nothing of the record the proof does not state.
+
+// AttrUserID is the certificate attribute holding a customer's user ID, the
+// userId of their records
+const AttrUserID = "kyc.userId"
+
+// MyKYCStatus is what a customer can see of their own record
+type MyKYCStatus struct {
+	KYCID             string `json:"kycId"`
+	Status            string `json:"status"`
+	VerificationLevel string `json:"verificationLevel"`
+	ExpiresAt         string `json:"expiresAt,omitempty" metadata:",optional"`
+	Proof             string `json:"proof"` // base64 JSON KYCStatusProof
+}
+
+// KYCStatusProof states a record's status at the time it was issued, bound
+// to the record's state
+type KYCStatusProof struct {
+	KYCID             string `json:"kycId"`
+	Status            string `json:"status"`
+	VerificationLevel string `json:"verificationLevel"`
+	ExpiresAt         string `json:"expiresAt,omitempty" metadata:",optional"`
+	TxID              string `json:"txId"`      // the transaction of the record's latest history entry
+	StateHash         string `json:"stateHash"` // hex SHA-256 of the record's world state value
+	IssuedAt          string `json:"issuedAt"`
+}
+
+// KYCStatusProofCheck is the outcome of checking a status proof
+type KYCStatusProofCheck struct {
+	Valid  bool            `json:"valid"`
+	Reason string          `json:"reason,omitempty" metadata:",optional"` // why the proof does not hold
+	Proof  *KYCStatusProof `json:"proof,omitempty" metadata:",optional"`  // the proof as decoded
+}
+
+// GetMyKYCStatus returns the status of the caller's own record with a proof
+// of it. Only the customer the record belongs to can call it.
+func (s *SmartContract) GetMyKYCStatus(ctx contractapi.TransactionContextInterface, id string) (*MyKYCStatus, error) {
+	kyc, kycJSON, err := s.readKYCWithState(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	userID, found, err := ctx.GetClientIdentity().GetAttributeValue(AttrUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client attributes: %v", err)
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	if !found || userID == "" || userID != kyc.UserID || (kyc.OwnerMSP != "" && kyc.OwnerMSP != mspID) {
+		return nil, fmt.Errorf("caller is not authorized: KYC record %s does not belong to the caller", id)
+	}
+
+	history, err := s.GetKYCHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	proof := KYCStatusProof{
+		KYCID:             kyc.ID,
+		Status:            kyc.Status,
+		VerificationLevel: kyc.VerificationLevel,
+		ExpiresAt:         kyc.ExpiresAt,
+		StateHash:         recordStateHash(kycJSON),
+		IssuedAt:          s.txTime(ctx).Format(time.RFC3339),
+	}
+	if len(history) > 0 {
+		proof.TxID = history[len(history)-1].TxID
+	}
+	proofJSON, err := json.Marshal(proof)
+	if err != nil {
+		return nil, err
+	}
+	return &MyKYCStatus{
+		KYCID:             kyc.ID,
+		Status:            kyc.Status,
+		VerificationLevel: kyc.VerificationLevel,
+		ExpiresAt:         kyc.ExpiresAt,
+		Proof:             base64.StdEncoding.EncodeToString(proofJSON),
+	}, nil
+}
+
+// VerifyKYCStatusProof checks a status proof from GetMyKYCStatus against the
+// record's current state. The proof holds if the record is unchanged since
+// it was issued and still has the status it states.
+func (s *SmartContract) VerifyKYCStatusProof(ctx contractapi.TransactionContextInterface, proof string) (*KYCStatusProofCheck, error) {
+	proofJSON, err := base64.StdEncoding.DecodeString(proof)
+	if err != nil {
+		return nil, fmt.Errorf("proof must be base64: %v", err)
+	}
+	var decoded KYCStatusProof
+	err = json.Unmarshal(proofJSON, &decoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal proof: %v", err)
+	}
+	check := &KYCStatusProofCheck{Proof: &decoded}
+
+	kyc, kycJSON, err := s.readKYCWithState(ctx, decoded.KYCID)
+	if err != nil {
+		check.Reason = err.Error()
+		return check, nil
+	}
+	switch {
+	case recordStateHash(kycJSON) != decoded.StateHash:
+		check.Reason = fmt.Sprintf("KYC record %s has changed since the proof was issued", kyc.ID)
+	case kyc.Status != decoded.Status || kyc.VerificationLevel != decoded.VerificationLevel || kyc.ExpiresAt != decoded.ExpiresAt:
+		check.Reason = fmt.Sprintf("the proof does not state the status of KYC record %s", kyc.ID)
+	default:
+		check.Valid = true
+	}
+	return check, nil
+}
+
+// readKYCWithState returns a record and its world state value
+func (s *SmartContract) readKYCWithState(ctx contractapi.TransactionContextInterface, id string) (*KYCRecord, []byte, error) {
+	kyc, err := s.readKYC(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	kycJSON, err := getRecordState(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	return kyc, kycJSON, nil
+}
+
+// recordStateHash is the hex SHA-256 of a record's world state value
+func recordStateHash(kycJSON []byte) string {
+	sum := sha256.Sum256(kycJSON)
+	return hex.EncodeToString(sum[:])
+}
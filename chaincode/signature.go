@@ -0,0 +1,214 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// historySignatureTransientKey is the transient field a client passes a
+// detached signature in, made with the key of the certificate it submits
+// with over the JSON of the signed entry's HistorySignaturePayload, keys
+// sorted and without whitespace. The signature is attached to every history
+// entry of the transaction it verifies against, and the transaction fails if
+// there is none.
+//
+// A client whose key is held in an HSM or other key store can name the key
+// it signed with in the historySignatureKeyId transient field, such as a
+// PKCS #11 URI, and the identifier is stored with the signature.
+const (
+	historySignatureTransientKey      = "historySignature"
+	historySignatureKeyIDTransientKey = "historySignatureKeyId"
+)
+
+// HistorySignaturePayload is what a signed history entry's signature covers:
+// the action on the record and the transaction that performed it, all known
+// to the client before it submits
+type HistorySignaturePayload struct {
+	Action string `json:"action"`
+	KYCID  string `json:"kycId"`
+	TxID   string `json:"txId"`
+}
+
+// HistorySignature is a client's detached signature over a history entry,
+// with the certificate it verifies against
+type HistorySignature struct {
+	Signature   string `json:"signature"`                            // base64
+	Certificate string `json:"certificate"`                          // PEM
+	KeyID       string `json:"keyId,omitempty" metadata:",optional"` // the signing key's identifier in the client's key store
+}
+
+// pendingSignature is a transaction's history signature while it runs
+type pendingSignature struct {
+	signature []byte
+	cert      *x509.Certificate
+	keyID     string
+	used      bool // attached to an entry, or none was given
+}
+
+// HistorySignatureVerification reports whether a history entry's signature
+// verifies
+type HistorySignatureVerification struct {
+	KYCID   string `json:"kycId"`
+	TxID    string `json:"txId"`
+	Signed  bool   `json:"signed"`
+	Valid   bool   `json:"valid"`
+	Signer  string `json:"signer,omitempty" metadata:",optional"` // subject of the signing certificate
+	KeyID   string `json:"keyId,omitempty" metadata:",optional"`
+	Problem string `json:"problem,omitempty" metadata:",optional"`
+}
+
+// requireHistorySignatureUsed fails a transaction given a history signature
+// that signed none of its history entries
+func requireHistorySignatureUsed(ctx contractapi.TransactionContextInterface) error {
+	tx, ok := ctx.(*txContext)
+	if !ok || (tx.historySignature != nil && tx.historySignature.used) {
+		return nil
+	}
+	if tx.historySignature == nil {
+		transient, err := ctx.GetStub().GetTransient()
+		if err != nil || len(transient[historySignatureTransientKey]) == 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("the %s transient signature does not verify against any history entry this transaction wrote", historySignatureTransientKey)
+}
+
+// signHistoryEntry attaches the transaction's history signature to an entry
+// it verifies against
+func signHistoryEntry(ctx contractapi.TransactionContextInterface, entry *HistoryEntry) error {
+	tx, ok := ctx.(*txContext)
+	if !ok {
+		return nil
+	}
+	if tx.historySignature == nil {
+		transient, err := ctx.GetStub().GetTransient()
+		if err != nil {
+			return fmt.Errorf("failed to read transient data: %v", err)
+		}
+		tx.historySignature = &pendingSignature{
+			signature: transient[historySignatureTransientKey],
+			keyID:     string(transient[historySignatureKeyIDTransientKey]),
+		}
+		if len(tx.historySignature.signature) > 0 {
+			cert, err := ctx.GetClientIdentity().GetX509Certificate()
+			if err != nil {
+				return fmt.Errorf("failed to get client certificate: %v", err)
+			}
+			tx.historySignature.cert = cert
+		}
+	}
+	pending := tx.historySignature
+	if len(pending.signature) == 0 {
+		pending.used = true
+		return nil
+	}
+
+	payload, err := historySignaturePayload(entry)
+	if err != nil {
+		return err
+	}
+	if verifySignature(pending.cert, payload, pending.signature) != nil {
+		return nil
+	}
+	entry.Signature = &HistorySignature{
+		Signature:   base64.StdEncoding.EncodeToString(pending.signature),
+		Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: pending.cert.Raw})),
+		KeyID:       pending.keyID,
+	}
+	pending.used = true
+	return nil
+}
+
+// VerifyHistorySignature verifies the signature on the history entry a
+// transaction wrote for a record against the certificate stored with it
+func (s *SmartContract) VerifyHistorySignature(ctx contractapi.TransactionContextInterface, kycID string, txID string, sequence int) (*HistorySignatureVerification, error) {
+	entry := &HistoryEntry{KYCID: kycID, TxID: txID, Sequence: sequence}
+	key, err := historyStateKey(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+	entryJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if entryJSON == nil {
+		return nil, fmt.Errorf("transaction %s wrote no history entry %d for KYC record %s", txID, sequence, kycID)
+	}
+	err = json.Unmarshal(entryJSON, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &HistorySignatureVerification{KYCID: kycID, TxID: txID}
+	if entry.Signature == nil {
+		return result, nil
+	}
+	result.Signed = true
+	result.KeyID = entry.Signature.KeyID
+
+	block, _ := pem.Decode([]byte(entry.Signature.Certificate))
+	if block == nil {
+		result.Problem = "the stored certificate is not PEM"
+		return result, nil
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		result.Problem = fmt.Sprintf("the stored certificate does not parse: %v", err)
+		return result, nil
+	}
+	result.Signer = cert.Subject.String()
+	signature, err := base64.StdEncoding.DecodeString(entry.Signature.Signature)
+	if err != nil {
+		result.Problem = "the stored signature is not base64"
+		return result, nil
+	}
+	payload, err := historySignaturePayload(entry)
+	if err != nil {
+		return nil, err
+	}
+	err = verifySignature(cert, payload, signature)
+	if err != nil {
+		result.Problem = err.Error()
+		return result, nil
+	}
+	result.Valid = true
+	return result, nil
+}
+
+func historySignaturePayload(entry *HistoryEntry) ([]byte, error) {
+	return json.Marshal(HistorySignaturePayload{Action: entry.Action, KYCID: entry.KYCID, TxID: entry.TxID})
+}
+
+// verifySignature verifies a signature over payload with a certificate's
+// key: ECDSA (ASN.1) or RSA PKCS #1 v1.5 over its SHA-256 digest, or Ed25519
+func verifySignature(cert *x509.Certificate, payload []byte, signature []byte) error {
+	digest := sha256.Sum256(payload)
+	switch key := cert.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, digest[:], signature) {
+			return fmt.Errorf("the signature does not verify")
+		}
+	case *rsa.PublicKey:
+		err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature)
+		if err != nil {
+			return fmt.Errorf("the signature does not verify")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, payload, signature) {
+			return fmt.Errorf("the signature does not verify")
+		}
+	default:
+		return fmt.Errorf("unsupported certificate key type %T", cert.PublicKey)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// slaDueAt returns the RFC 3339 time hours after the RFC 3339 time from
+func slaDueAt(from string, hours int) string {
+	start, err := time.Parse(time.RFC3339, from)
+	if err != nil {
+		return ""
+	}
+	return start.Add(time.Duration(hours) * time.Hour).UTC().Format(time.RFC3339)
+}
+
+// recordSLABreach registers an exception when a record is decided after its
+// SLA due date. Timestamps are all UTC RFC 3339, so they compare as strings.
+func recordSLABreach(ctx contractapi.TransactionContextInterface, kyc *KYCRecord, decidedBy string) error {
+	if kyc.SLADueAt == "" || kyc.UpdatedAt <= kyc.SLADueAt {
+		return nil
+	}
+
+	details := map[string]interface{}{
+		"status":    kyc.Status,
+		"slaDueAt":  kyc.SLADueAt,
+		"decidedAt": kyc.UpdatedAt,
+		"decidedBy": decidedBy,
+	}
+	due, dueErr := time.Parse(time.RFC3339, kyc.SLADueAt)
+	decided, decidedErr := time.Parse(time.RFC3339, kyc.UpdatedAt)
+	if dueErr == nil && decidedErr == nil {
+		details["hoursLate"] = int(decided.Sub(due).Hours())
+	}
+
+	return recordException(ctx, ExceptionEntry{
+		Type:     ExceptionSLABreach,
+		KYCID:    kyc.ID,
+		RaisedAt: kyc.UpdatedAt,
+		Details:  details,
+	})
+}
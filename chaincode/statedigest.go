@@ -0,0 +1,153 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// State digests. A peer restored from a backup must be shown to hold the
+// same world state as the rest of the network before it is trusted to
+// endorse. ComputeStateDigest pages through every key the contract writes in
+// a fixed order, chaining a SHA-256 over each key and the hash of its value,
+// so peers at the same height return the same digest whatever page size
+// they are asked with. tools/statedigest collects the digest from a peer and
+// compares those of several.
+//
+// The chain starts from 32 zero bytes, and each key extends it to
+// SHA-256(previous || uint32 big-endian key length || key || SHA-256(value)).
+
+// derivedObjectTypes are the composite key spaces ExportAll leaves out
+// because import rebuilds them, but which a state digest covers
+var derivedObjectTypes = []string{
+	phoneIndex,
+	panIndex,
+	emailIndex,
+	statusIndex,
+	pincodeIndex,
+	stateCityIndex,
+	tagIndex,
+	faceHashIndex,
+	documentReviewIndex,
+	openAlertIndex,
+	sponsoredGrantIndex,
+	rateBucketObjectType,
+	telemetryObjectType,
+}
+
+// stateDigestSections are the key spaces ComputeStateDigest pages through in
+// turn: every simple key first, then each composite-key object type
+var stateDigestSections = func() []string {
+	sections := []string{}
+	for _, section := range exportSections {
+		sections = append(sections, section.objectType)
+	}
+	return append(sections, derivedObjectTypes...)
+}()
+
+// StateDigestPage is one page of a state digest
+type StateDigestPage struct {
+	Digest              string `json:"digest"`   // hex chain over every key up to this page's last; the state digest once Bookmark is empty
+	KeyCount            int64  `json:"keyCount"` // keys digested up to this page's last
+	FetchedRecordsCount int32  `json:"fetchedRecordsCount"`
+	Bookmark            string `json:"bookmark"` // empty once every key space has been digested
+}
+
+// ComputeStateDigest digests one page of the world state, continuing the
+// chain held in bookmark. Start with an empty bookmark and pass each page's
+// bookmark to the next call; the page returned with an empty bookmark holds
+// the digest of the whole state. Only administrators and regulators can
+// compute state digests.
+func (s *SmartContract) ComputeStateDigest(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*StateDigestPage, error) {
+	_, err := requireAnyAttribute(ctx, AttrAdmin, AttrRegulator)
+	if err != nil {
+		return nil, err
+	}
+	err = checkPageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	section, keyCount, inner := 0, int64(0), ""
+	digest := make([]byte, sha256.Size)
+	if bookmark != "" {
+		parts := strings.SplitN(bookmark, ":", 4)
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("invalid state digest bookmark %q", bookmark)
+		}
+		section, err = strconv.Atoi(parts[0])
+		if err != nil || section < 0 || section >= len(stateDigestSections) {
+			return nil, fmt.Errorf("invalid state digest bookmark %q", bookmark)
+		}
+		keyCount, err = strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || keyCount < 0 {
+			return nil, fmt.Errorf("invalid state digest bookmark %q", bookmark)
+		}
+		digest, err = hex.DecodeString(parts[2])
+		if err != nil || len(digest) != sha256.Size {
+			return nil, fmt.Errorf("invalid state digest bookmark %q", bookmark)
+		}
+		inner = parts[3]
+	}
+
+	stub := ctx.GetStub()
+	objectType := stateDigestSections[section]
+	var resultsIterator shim.StateQueryIteratorInterface
+	var responseMetadata *peer.QueryResponseMetadata
+	if objectType == "" {
+		resultsIterator, responseMetadata, err = stub.GetStateByRangeWithPagination("", "", pageSize, inner)
+	} else {
+		resultsIterator, responseMetadata, err = stub.GetStateByPartialCompositeKeyWithPagination(objectType, []string{}, pageSize, inner)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	count := int32(0)
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		digest = chainStateDigest(digest, queryResponse.Key, queryResponse.Value)
+		count++
+	}
+	keyCount += int64(count)
+
+	inner = responseMetadata.Bookmark
+	if inner != "" {
+		bookmark = fmt.Sprintf("%d:%d:%x:%s", section, keyCount, digest, inner)
+	} else if section+1 < len(stateDigestSections) {
+		bookmark = fmt.Sprintf("%d:%d:%x:", section+1, keyCount, digest)
+	} else {
+		bookmark = ""
+	}
+	return &StateDigestPage{
+		Digest:              hex.EncodeToString(digest),
+		KeyCount:            keyCount,
+		FetchedRecordsCount: count,
+		Bookmark:            bookmark,
+	}, nil
+}
+
+// chainStateDigest extends a state digest chain with one key and its value
+func chainStateDigest(previous []byte, key string, value []byte) []byte {
+	valueHash := sha256.Sum256(value)
+	var keyLength [4]byte
+	binary.BigEndian.PutUint32(keyLength[:], uint32(len(key)))
+
+	hash := sha256.New()
+	hash.Write(previous)
+	hash.Write(keyLength[:])
+	hash.Write([]byte(key))
+	hash.Write(valueHash[:])
+	return hash.Sum(nil)
+}
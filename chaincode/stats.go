@@ -0,0 +1,65 @@
+package main
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ComplianceStats summarises the KYC records held on the ledger
+type ComplianceStats struct {
+	TotalRecords int            `json:"totalRecords"`
+	ByStatus     map[string]int `json:"byStatus"`
+	Nominee      NomineeStats   `json:"nominee"`
+}
+
+// NomineeStats counts records by nominee completeness
+type NomineeStats struct {
+	Complete int `json:"complete"`
+	Partial  int `json:"partial"`
+	Missing  int `json:"missing"`
+}
+
+// GetComplianceStats returns record counts by status and nominee
+// completeness. It is read from maintained counters, so its cost does not
+// grow with the number of records; archived records and decoys are left out.
+func (s *SmartContract) GetComplianceStats(ctx contractapi.TransactionContextInterface) (*ComplianceStats, error) {
+	stats := &ComplianceStats{ByStatus: map[string]int{}}
+	var err error
+	stats.TotalRecords, err = readRecordCounter(ctx, counterTotal)
+	if err != nil {
+		return nil, err
+	}
+	for status := range recordStatuses {
+		count, err := readRecordCounter(ctx, counterStatusPrefix+status)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			stats.ByStatus[status] = count
+		}
+	}
+
+	for completeness, count := range map[string]*int{
+		"COMPLETE": &stats.Nominee.Complete,
+		"PARTIAL":  &stats.Nominee.Partial,
+		"MISSING":  &stats.Nominee.Missing,
+	} {
+		*count, err = readRecordCounter(ctx, counterNomineePrefix+completeness)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+// readRecordCounter returns the value of a record counter, leaving out decoys
+func readRecordCounter(ctx contractapi.TransactionContextInterface, name string) (int, error) {
+	count, err := readCounter(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	decoys, err := readCounter(ctx, counterDecoyPrefix+name)
+	if err != nil {
+		return 0, err
+	}
+	return count - decoys, nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const tagIndex = "tag~kycid"
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9:_.-]{0,63}$`)
+
+// AddTag labels a KYC record with a tag such as a campaign, branch, migration
+// batch or investigation case. Tags are case-insensitive and stored lower-case.
+// Members of the organisation that owns a record, verifiers and
+// administrators can tag it.
+func (s *SmartContract) AddTag(ctx contractapi.TransactionContextInterface, kycID string, tag string) error {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if !tagPattern.MatchString(tag) {
+		return fmt.Errorf("invalid tag %q", tag)
+	}
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	err = requireTagger(ctx, kyc)
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range kyc.Tags {
+		if existing == tag {
+			return nil
+		}
+	}
+	kyc.Tags = append(kyc.Tags, tag)
+
+	err = putIndexEntry(ctx, tagIndex, kycID, tag)
+	if err != nil {
+		return err
+	}
+
+	return s.saveTagChange(ctx, kyc, "TAG_ADDED", tag)
+}
+
+// RemoveTag removes a tag from a KYC record. Whoever can tag a record can
+// remove its tags.
+func (s *SmartContract) RemoveTag(ctx contractapi.TransactionContextInterface, kycID string, tag string) error {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+
+	kyc, err := s.readKYC(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	err = requireTagger(ctx, kyc)
+	if err != nil {
+		return err
+	}
+
+	var remaining []string
+	for _, existing := range kyc.Tags {
+		if existing != tag {
+			remaining = append(remaining, existing)
+		}
+	}
+	if len(remaining) == len(kyc.Tags) {
+		return fmt.Errorf("KYC record %s is not tagged %q", kycID, tag)
+	}
+	kyc.Tags = remaining
+
+	err = deleteIndexEntry(ctx, tagIndex, kycID, tag)
+	if err != nil {
+		return err
+	}
+
+	return s.saveTagChange(ctx, kyc, "TAG_REMOVED", tag)
+}
+
+// requireTagger fails unless the caller belongs to the organisation that
+// owns a record or holds the verifier or admin attribute
+func requireTagger(ctx contractapi.TransactionContextInterface, kyc *KYCRecord) error {
+	for _, attr := range []string{AttrVerifier, AttrAdmin} {
+		held, err := hasAttribute(ctx, attr)
+		if err != nil {
+			return err
+		}
+		if held {
+			return requireCurrentCertificate(ctx)
+		}
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	if kyc.OwnerMSP != "" && kyc.OwnerMSP != mspID {
+		return fmt.Errorf("KYC record %s is owned by %s", kyc.ID, kyc.OwnerMSP)
+	}
+	return nil
+}
+
+// GetKYCByTag returns a page of KYC records carrying the given tag
+func (s *SmartContract) GetKYCByTag(ctx contractapi.TransactionContextInterface, tag string, pageSize int32, bookmark string, purposeCode string) (*PaginatedQueryResult, error) {
+	purpose, err := newReadPurpose(ctx, "GetKYCByTag", purposeCode)
+	if err != nil {
+		return nil, err
+	}
+	return s.getRecordsByIndex(ctx, purpose, tagIndex, []string{strings.ToLower(strings.TrimSpace(tag))}, pageSize, bookmark)
+}
+
+// saveTagChange stores a record after a tag change and writes the history entry
+func (s *SmartContract) saveTagChange(ctx contractapi.TransactionContextInterface, kyc *KYCRecord, action string, tag string) error {
+	kyc.UpdatedAt = s.txTime(ctx).Format(time.RFC3339)
+
+	kycJSON, err := json.Marshal(kyc)
+	if err != nil {
+		return err
+	}
+
+	err = putRecordState(ctx, kyc.ID, kycJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update KYC record: %v", err)
+	}
+
+	performedBy, err := clientActorID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	historyEntry := HistoryEntry{
+		ID:          fmt.Sprintf("%s-%s-%d", kyc.ID, action, s.txTime(ctx).Unix()),
+		KYCID:       kyc.ID,
+		Action:      action,
+		PerformedBy: performedBy,
+		PerformedAt: kyc.UpdatedAt,
+		TxID:        ctx.GetStub().GetTxID(),
+		Details: map[string]interface{}{
+			"tag": tag,
+		},
+	}
+
+	err = s.createHistoryEntry(ctx, historyEntry)
+	if err != nil {
+		return fmt.Errorf("failed to create history entry: %v", err)
+	}
+
+	return nil
+}
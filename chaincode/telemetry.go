@@ -0,0 +1,283 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Transaction telemetry. Every transaction runs against a stub that meters
+// the distinct world state keys it reads and writes and their sizes. When
+// ContractConfig.TelemetrySampleEvery is set, one transaction in that many,
+// chosen by its transaction ID, stores a TelemetrySample in one of
+// telemetrySlots slots, also chosen by its ID, so the samples form a rolling
+// window over recent transactions; the sample is written without reading
+// its slot, so samples never cause MVCC read conflicts. Read and write set
+// sizes are the same on every endorsing peer, but execution time is not, so
+// a sampled transaction's duration goes to the peer's chaincode log rather
+// than the ledger. Fabric refuses writes in a transaction that has run a
+// paginated query, so such a transaction's sample only goes to the log.
+const (
+	telemetryObjectType = "TELEMETRY"
+	telemetrySlots      = 256
+)
+
+// TelemetrySample is the read and write set size of one transaction. The
+// sizes count keys and values in bytes, and leave out the sample itself.
+type TelemetrySample struct {
+	Function     string `json:"function"`
+	TxID         string `json:"txId"`
+	KeysRead     int    `json:"keysRead"`
+	BytesRead    int    `json:"bytesRead"`
+	KeysWritten  int    `json:"keysWritten"` // deletions included
+	BytesWritten int    `json:"bytesWritten"`
+	RecordedAt   string `json:"recordedAt"`
+}
+
+// FunctionTelemetry summarises the samples of one function
+type FunctionTelemetry struct {
+	Function         string `json:"function"`
+	Samples          int    `json:"samples"`
+	MeanKeysRead     int    `json:"meanKeysRead"`
+	MeanBytesRead    int    `json:"meanBytesRead"`
+	MeanKeysWritten  int    `json:"meanKeysWritten"`
+	MeanBytesWritten int    `json:"meanBytesWritten"`
+	MaxBytesWritten  int    `json:"maxBytesWritten"`
+}
+
+// TelemetryReport summarises the telemetry samples held, by function
+type TelemetryReport struct {
+	Samples   int                 `json:"samples"`
+	Functions []FunctionTelemetry `json:"functions"` // largest mean write set first
+}
+
+// GetTelemetryReport summarises the sampled transactions by function, the
+// functions writing the most on average first. Only administrators can read
+// telemetry.
+func (s *SmartContract) GetTelemetryReport(ctx contractapi.TransactionContextInterface) (*TelemetryReport, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(telemetryObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &TelemetryReport{Functions: []FunctionTelemetry{}}
+	totals := map[string]*FunctionTelemetry{}
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		var sample TelemetrySample
+		err := json.Unmarshal(queryResponse.Value, &sample)
+		if err != nil {
+			return err
+		}
+		total, ok := totals[sample.Function]
+		if !ok {
+			total = &FunctionTelemetry{Function: sample.Function}
+			totals[sample.Function] = total
+		}
+		total.Samples++
+		total.MeanKeysRead += sample.KeysRead
+		total.MeanBytesRead += sample.BytesRead
+		total.MeanKeysWritten += sample.KeysWritten
+		total.MeanBytesWritten += sample.BytesWritten
+		if sample.BytesWritten > total.MaxBytesWritten {
+			total.MaxBytesWritten = sample.BytesWritten
+		}
+		report.Samples++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, total := range totals {
+		total.MeanKeysRead /= total.Samples
+		total.MeanBytesRead /= total.Samples
+		total.MeanKeysWritten /= total.Samples
+		total.MeanBytesWritten /= total.Samples
+		report.Functions = append(report.Functions, *total)
+	}
+	sort.Slice(report.Functions, func(i, j int) bool {
+		a, b := report.Functions[i], report.Functions[j]
+		if a.MeanBytesWritten != b.MeanBytesWritten {
+			return a.MeanBytesWritten > b.MeanBytesWritten
+		}
+		return a.Function < b.Function
+	})
+	return report, nil
+}
+
+// txTrace is what a transaction's metered stub has seen
+type txTrace struct {
+	startedAt time.Time
+	reads     map[string]int // bytes of each key read
+	writes    map[string]int // bytes of each key last written
+	paginated bool           // the transaction has run a paginated query
+}
+
+func (t *txTrace) read(key string, value []byte) {
+	t.reads[key] = len(key) + len(value)
+}
+
+func (t *txTrace) write(key string, value []byte) {
+	t.writes[key] = len(key) + len(value)
+}
+
+// sample returns the sizes of what a transaction has read and written so far
+func (t *txTrace) sample(function string, txID string) TelemetrySample {
+	sample := TelemetrySample{Function: function, TxID: txID, KeysRead: len(t.reads), KeysWritten: len(t.writes)}
+	for _, size := range t.reads {
+		sample.BytesRead += size
+	}
+	for _, size := range t.writes {
+		sample.BytesWritten += size
+	}
+	return sample
+}
+
+// meteredStub passes a transaction's world state calls to the peer,
+// recording the keys read and written in its trace
+type meteredStub struct {
+	shim.ChaincodeStubInterface
+	trace *txTrace
+}
+
+func (s *meteredStub) GetState(key string) ([]byte, error) {
+	value, err := s.ChaincodeStubInterface.GetState(key)
+	if err == nil {
+		s.trace.read(key, value)
+	}
+	return value, err
+}
+
+func (s *meteredStub) PutState(key string, value []byte) error {
+	s.trace.write(key, value)
+	return s.ChaincodeStubInterface.PutState(key, value)
+}
+
+func (s *meteredStub) DelState(key string) error {
+	s.trace.write(key, nil)
+	return s.ChaincodeStubInterface.DelState(key)
+}
+
+func (s *meteredStub) GetStateByRange(startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+	iterator, err := s.ChaincodeStubInterface.GetStateByRange(startKey, endKey)
+	return s.meter(iterator), err
+}
+
+func (s *meteredStub) GetStateByRangeWithPagination(startKey, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	s.trace.paginated = true
+	iterator, metadata, err := s.ChaincodeStubInterface.GetStateByRangeWithPagination(startKey, endKey, pageSize, bookmark)
+	return s.meter(iterator), metadata, err
+}
+
+func (s *meteredStub) GetStateByPartialCompositeKey(objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	iterator, err := s.ChaincodeStubInterface.GetStateByPartialCompositeKey(objectType, keys)
+	return s.meter(iterator), err
+}
+
+func (s *meteredStub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	s.trace.paginated = true
+	iterator, metadata, err := s.ChaincodeStubInterface.GetStateByPartialCompositeKeyWithPagination(objectType, keys, pageSize, bookmark)
+	return s.meter(iterator), metadata, err
+}
+
+func (s *meteredStub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	iterator, err := s.ChaincodeStubInterface.GetQueryResult(query)
+	return s.meter(iterator), err
+}
+
+func (s *meteredStub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	s.trace.paginated = true
+	iterator, metadata, err := s.ChaincodeStubInterface.GetQueryResultWithPagination(query, pageSize, bookmark)
+	return s.meter(iterator), metadata, err
+}
+
+func (s *meteredStub) meter(iterator shim.StateQueryIteratorInterface) shim.StateQueryIteratorInterface {
+	if iterator == nil {
+		return nil
+	}
+	return &meteredIterator{StateQueryIteratorInterface: iterator, trace: s.trace}
+}
+
+// meteredIterator records the keys a query returns as read
+type meteredIterator struct {
+	shim.StateQueryIteratorInterface
+	trace *txTrace
+}
+
+func (it *meteredIterator) Next() (*queryresult.KV, error) {
+	kv, err := it.StateQueryIteratorInterface.Next()
+	if err == nil && kv != nil {
+		it.trace.read(kv.Key, kv.Value)
+	}
+	return kv, err
+}
+
+// startTrace restarts the clock of a transaction's trace once the contract
+// is about to run it
+func startTrace(ctx contractapi.TransactionContextInterface) error {
+	if tx, ok := ctx.(*txContext); ok && tx.trace != nil {
+		tx.trace.startedAt = time.Now()
+	}
+	return nil
+}
+
+// recordTrace stores a transaction's telemetry sample, if it is sampled
+func recordTrace(ctx contractapi.TransactionContextInterface) error {
+	tx, ok := ctx.(*txContext)
+	if !ok || tx.trace == nil {
+		return nil
+	}
+	function, _ := ctx.GetStub().GetFunctionAndParameters()
+	sample := tx.trace.sample(function[strings.LastIndex(function, ":")+1:], ctx.GetStub().GetTxID())
+	elapsed := time.Since(tx.trace.startedAt)
+
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	slot, sampled := telemetrySlot(sample.TxID, config.TelemetrySampleEvery)
+	if !sampled {
+		return nil
+	}
+	log.Printf("telemetry: %s %s took %s, read %d keys (%d bytes), wrote %d keys (%d bytes)",
+		sample.Function, sample.TxID, elapsed, sample.KeysRead, sample.BytesRead, sample.KeysWritten, sample.BytesWritten)
+	if tx.trace.paginated {
+		return nil
+	}
+
+	sample.RecordedAt = txTime(ctx).Format(time.RFC3339)
+	key, err := ctx.GetStub().CreateCompositeKey(telemetryObjectType, []string{fmt.Sprintf("%03d", slot)})
+	if err != nil {
+		return err
+	}
+	sampleJSON, err := json.Marshal(sample)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(key, sampleJSON)
+}
+
+// telemetrySlot reports whether a transaction is sampled when one in every
+// is, and the slot its sample goes in
+func telemetrySlot(txID string, every int) (int, bool) {
+	if every <= 0 {
+		return 0, false
+	}
+	h := fnv.New32a()
+	h.Write([]byte(txID))
+	sum, n := h.Sum32(), uint32(every)
+	return int(sum / n % telemetrySlots), sum%n == 0
+}
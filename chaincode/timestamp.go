@@ -0,0 +1,448 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Timestamp tokens. A deployment that needs externally attested time has an
+// RFC 3161 timestamp authority timestamp the digest of a critical event, and
+// stores the token under "TSTOKEN~<event>~<reference>" with
+// StoreTimestampToken. The digest of an event comes from GetTimestampDigest:
+// the hash of a history entry, as its successor's prevEntryHash holds it, or
+// the content hash of a monthly summary. Tokens must carry the signing
+// certificate, chaining at their time to one of the trustedTsaRoots config
+// setting's certificates.
+const timestampTokenObjectType = "TSTOKEN"
+
+// Events a timestamp token can be stored for
+const (
+	TimestampHistory = "HISTORY" // reference <kyc ID>/<tx ID>/<sequence>
+	TimestampReport  = "REPORT"  // reference the month, YYYY-MM
+)
+
+var (
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	digestAlgorithms = map[string]crypto.Hash{
+		"2.16.840.1.101.3.4.2.1": crypto.SHA256,
+		"2.16.840.1.101.3.4.2.2": crypto.SHA384,
+		"2.16.840.1.101.3.4.2.3": crypto.SHA512,
+	}
+)
+
+// TimestampToken is a timestamp authority's token over the digest of an event
+type TimestampToken struct {
+	Event        string `json:"event"`
+	Reference    string `json:"reference"`
+	Digest       string `json:"digest"`       // hex SHA-256 the token timestamps
+	Token        string `json:"token"`        // base64 DER TimeStampToken
+	GenTime      string `json:"genTime"`      // the time the authority attests
+	SerialNumber string `json:"serialNumber"` // the authority's serial number of the token
+	Policy       string `json:"policy"`
+	TSA          string `json:"tsa"` // subject of the signing certificate
+	StoredAt     string `json:"storedAt"`
+	StoredBy     string `json:"storedBy"`
+}
+
+// TimestampVerification reports whether an event's timestamp token verifies
+// against the event as it stands
+type TimestampVerification struct {
+	Event     string `json:"event"`
+	Reference string `json:"reference"`
+	Valid     bool   `json:"valid"`
+	GenTime   string `json:"genTime,omitempty" metadata:",optional"`
+	TSA       string `json:"tsa,omitempty" metadata:",optional"`
+	Problem   string `json:"problem,omitempty" metadata:",optional"`
+}
+
+// contentInfo, signedData, signerInfo and tstInfo are the parts of a
+// TimeStampToken (RFC 3161, RFC 5652) verification needs
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0"` // [0] EXPLICIT, unwrapped by hand
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo struct {
+		EContentType asn1.ObjectIdentifier
+		EContent     []byte `asn1:"explicit,optional,tag:0"`
+	}
+	Certificates asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs         asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos  []signerInfo  `asn1:"set"`
+}
+
+type signerInfo struct {
+	Version            int
+	SID                asn1.RawValue
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue `asn1:"set"`
+}
+
+type issuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint struct {
+		HashAlgorithm pkix.AlgorithmIdentifier
+		HashedMessage []byte
+	}
+	SerialNumber *big.Int
+	GenTime      time.Time `asn1:"generalized"`
+	Accuracy     struct {
+		Seconds int `asn1:"optional"`
+		Millis  int `asn1:"optional,tag:0"`
+		Micros  int `asn1:"optional,tag:1"`
+	} `asn1:"optional"`
+	Ordering   bool          `asn1:"optional,default:false"`
+	Nonce      *big.Int      `asn1:"optional"`
+	TSA        asn1.RawValue `asn1:"optional,explicit,tag:0"`
+	Extensions asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+// GetTimestampDigest returns the hex SHA-256 digest a timestamp authority
+// should timestamp for an event
+func (s *SmartContract) GetTimestampDigest(ctx contractapi.TransactionContextInterface, event string, reference string) (string, error) {
+	return s.timestampDigest(ctx, event, reference)
+}
+
+// StoreTimestampToken stores an RFC 3161 timestamp token, base64 DER, over
+// the digest of an event. The token must verify; an event has at most one.
+func (s *SmartContract) StoreTimestampToken(ctx contractapi.TransactionContextInterface, event string, reference string, token string) (*TimestampToken, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(timestampTokenObjectType, []string{event, reference})
+	if err != nil {
+		return nil, err
+	}
+	existing, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("a timestamp token is already stored for %s %s", event, reference)
+	}
+
+	digest, err := s.timestampDigest(ctx, event, reference)
+	if err != nil {
+		return nil, err
+	}
+	der, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("token must be base64")
+	}
+	info, cert, err := verifyTimestampToken(ctx, der, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	storedBy, err := clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+	stored := &TimestampToken{
+		Event:        event,
+		Reference:    reference,
+		Digest:       digest,
+		Token:        token,
+		GenTime:      info.GenTime.UTC().Format(time.RFC3339Nano),
+		SerialNumber: info.SerialNumber.String(),
+		Policy:       info.Policy.String(),
+		TSA:          cert.Subject.String(),
+		StoredAt:     s.txTime(ctx).Format(time.RFC3339),
+		StoredBy:     storedBy,
+	}
+	storedJSON, err := json.Marshal(stored)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(key, storedJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store timestamp token: %v", err)
+	}
+	return stored, nil
+}
+
+// VerifyTimestampToken verifies an event's stored timestamp token against
+// the event as it stands and the trusted roots as configured now
+func (s *SmartContract) VerifyTimestampToken(ctx contractapi.TransactionContextInterface, event string, reference string) (*TimestampVerification, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(timestampTokenObjectType, []string{event, reference})
+	if err != nil {
+		return nil, err
+	}
+	storedJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if storedJSON == nil {
+		return nil, fmt.Errorf("no timestamp token is stored for %s %s", event, reference)
+	}
+	var stored TimestampToken
+	err = json.Unmarshal(storedJSON, &stored)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TimestampVerification{Event: event, Reference: reference}
+	digest, err := s.timestampDigest(ctx, event, reference)
+	if err != nil {
+		result.Problem = err.Error()
+		return result, nil
+	}
+	if digest != stored.Digest {
+		result.Problem = fmt.Sprintf("the event now hashes to %s, not the timestamped %s", digest, stored.Digest)
+		return result, nil
+	}
+	der, err := base64.StdEncoding.DecodeString(stored.Token)
+	if err != nil {
+		result.Problem = "the stored token is not base64"
+		return result, nil
+	}
+	info, cert, err := verifyTimestampToken(ctx, der, digest)
+	if err != nil {
+		result.Problem = err.Error()
+		return result, nil
+	}
+	result.Valid = true
+	result.GenTime = info.GenTime.UTC().Format(time.RFC3339Nano)
+	result.TSA = cert.Subject.String()
+	return result, nil
+}
+
+// timestampDigest returns the hex SHA-256 digest of an event
+func (s *SmartContract) timestampDigest(ctx contractapi.TransactionContextInterface, event string, reference string) (string, error) {
+	switch event {
+	case TimestampHistory:
+		// transaction IDs and sequences hold no slash, record IDs may
+		parts := strings.Split(reference, "/")
+		if len(parts) < 3 {
+			return "", fmt.Errorf("history reference must be <kyc ID>/<tx ID>/<sequence>")
+		}
+		sequence, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil {
+			return "", fmt.Errorf("history reference must be <kyc ID>/<tx ID>/<sequence>")
+		}
+		entry := &HistoryEntry{KYCID: strings.Join(parts[:len(parts)-2], "/"), TxID: parts[len(parts)-2], Sequence: sequence}
+		key, err := historyStateKey(ctx, entry)
+		if err != nil {
+			return "", err
+		}
+		entryJSON, err := ctx.GetStub().GetState(key)
+		if err != nil {
+			return "", fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if entryJSON == nil {
+			return "", fmt.Errorf("transaction %s wrote no history entry %d for KYC record %s", entry.TxID, sequence, entry.KYCID)
+		}
+		return historyEntryHash(entryJSON)
+	case TimestampReport:
+		summary, err := s.GetMonthlySummary(ctx, reference)
+		if err != nil {
+			return "", err
+		}
+		return summary.ContentHash, nil
+	}
+	return "", fmt.Errorf("event must be %s or %s", TimestampHistory, TimestampReport)
+}
+
+// verifyTimestampToken checks that a DER TimeStampToken timestamps the hex
+// SHA-256 digest, is signed by the certificate it carries, and that the
+// certificate was a timestamping certificate chaining to a trusted root at
+// the time the token attests
+func verifyTimestampToken(ctx contractapi.TransactionContextInterface, der []byte, digest string) (*tstInfo, *x509.Certificate, error) {
+	var content contentInfo
+	rest, err := asn1.Unmarshal(der, &content)
+	if err != nil || len(rest) > 0 || !content.ContentType.Equal(oidSignedData) {
+		return nil, nil, fmt.Errorf("the token is not a CMS SignedData")
+	}
+	var signed signedData
+	_, err = asn1.Unmarshal(content.Content.Bytes, &signed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("the token is not a CMS SignedData: %v", err)
+	}
+	if !signed.EncapContentInfo.EContentType.Equal(oidTSTInfo) || signed.EncapContentInfo.EContent == nil {
+		return nil, nil, fmt.Errorf("the token does not hold timestamp information")
+	}
+	var info tstInfo
+	_, err = asn1.Unmarshal(signed.EncapContentInfo.EContent, &info)
+	if err != nil {
+		return nil, nil, fmt.Errorf("the token's timestamp information does not parse: %v", err)
+	}
+	want, err := hex.DecodeString(digest)
+	if err != nil {
+		return nil, nil, err
+	}
+	if digestAlgorithms[info.MessageImprint.HashAlgorithm.Algorithm.String()] != crypto.SHA256 || !bytes.Equal(info.MessageImprint.HashedMessage, want) {
+		return nil, nil, fmt.Errorf("the token does not timestamp the SHA-256 digest %s", digest)
+	}
+
+	if len(signed.SignerInfos) != 1 {
+		return nil, nil, fmt.Errorf("the token must have one signer, not %d", len(signed.SignerInfos))
+	}
+	signer := signed.SignerInfos[0]
+	certs, err := x509.ParseCertificates(signed.Certificates.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("the token's certificates do not parse: %v", err)
+	}
+	cert, err := signerCertificate(signer.SID, certs)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = verifySignedAttributes(&signer, cert, signed.EncapContentInfo.EContent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(config.TrustedTSARoots) == 0 {
+		return nil, nil, fmt.Errorf("no timestamp authority is trusted; set trustedTsaRoots with SetConfig")
+	}
+	roots, intermediates := x509.NewCertPool(), x509.NewCertPool()
+	for _, root := range config.TrustedTSARoots {
+		roots.AppendCertsFromPEM([]byte(root))
+	}
+	for _, intermediate := range certs {
+		intermediates.AddCert(intermediate)
+	}
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   info.GenTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("the signing certificate is not trusted: %v", err)
+	}
+	return &info, cert, nil
+}
+
+// signerCertificate finds the certificate a signer identifier names
+func signerCertificate(sid asn1.RawValue, certs []*x509.Certificate) (*x509.Certificate, error) {
+	var issuerSerial issuerAndSerialNumber
+	bySerial := sid.Class == asn1.ClassUniversal && sid.Tag == asn1.TagSequence
+	if bySerial {
+		_, err := asn1.Unmarshal(sid.FullBytes, &issuerSerial)
+		if err != nil {
+			return nil, fmt.Errorf("the token's signer identifier does not parse: %v", err)
+		}
+	}
+	for _, cert := range certs {
+		if bySerial && bytes.Equal(cert.RawIssuer, issuerSerial.Issuer.FullBytes) && cert.SerialNumber.Cmp(issuerSerial.SerialNumber) == 0 {
+			return cert, nil
+		}
+		if !bySerial && sid.Class == asn1.ClassContextSpecific && sid.Tag == 0 && bytes.Equal(cert.SubjectKeyId, sid.Bytes) {
+			return cert, nil
+		}
+	}
+	return nil, fmt.Errorf("the token does not carry its signing certificate")
+}
+
+// verifySignedAttributes checks that a signer's signed attributes cover the
+// content and that its signature over them verifies with cert's key
+func verifySignedAttributes(signer *signerInfo, cert *x509.Certificate, content []byte) error {
+	hash, ok := digestAlgorithms[signer.DigestAlgorithm.Algorithm.String()]
+	if !ok {
+		return fmt.Errorf("unsupported digest algorithm %s", signer.DigestAlgorithm.Algorithm)
+	}
+	if signer.SignedAttrs.FullBytes == nil {
+		return fmt.Errorf("the token has no signed attributes")
+	}
+	// the signature covers the attributes encoded as a SET, not the [0] they
+	// are tagged with in the token
+	signedAttrs := append([]byte{0x31}, signer.SignedAttrs.FullBytes[1:]...)
+	var attributes []attribute
+	_, err := asn1.UnmarshalWithParams(signedAttrs, &attributes, "set")
+	if err != nil {
+		return fmt.Errorf("the token's signed attributes do not parse: %v", err)
+	}
+
+	var contentType asn1.ObjectIdentifier
+	var messageDigest []byte
+	for _, attr := range attributes {
+		switch {
+		case attr.Type.Equal(oidContentType):
+			_, err = asn1.Unmarshal(attr.Values.Bytes, &contentType)
+		case attr.Type.Equal(oidMessageDigest):
+			_, err = asn1.Unmarshal(attr.Values.Bytes, &messageDigest)
+		}
+		if err != nil {
+			return fmt.Errorf("the token's signed attributes do not parse: %v", err)
+		}
+	}
+	if !contentType.Equal(oidTSTInfo) {
+		return fmt.Errorf("the token's signed content type is not timestamp information")
+	}
+	digester := hash.New()
+	digester.Write(content)
+	if !bytes.Equal(messageDigest, digester.Sum(nil)) {
+		return fmt.Errorf("the token's signature does not cover its timestamp information")
+	}
+
+	digester = hash.New()
+	digester.Write(signedAttrs)
+	attrsDigest := digester.Sum(nil)
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		err = rsa.VerifyPKCS1v15(key, hash, attrsDigest, signer.Signature)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, attrsDigest, signer.Signature) {
+			err = fmt.Errorf("invalid signature")
+		}
+	default:
+		return fmt.Errorf("unsupported signing key type %T", cert.PublicKey)
+	}
+	if err != nil {
+		return fmt.Errorf("the token's signature does not verify")
+	}
+	return nil
+}
+
+// validateTSARoots checks that every trusted timestamp authority root is a
+// PEM certificate
+func validateTSARoots(roots []string) error {
+	for i, root := range roots {
+		block, _ := pem.Decode([]byte(root))
+		if block == nil || block.Type != "CERTIFICATE" {
+			return fmt.Errorf("trustedTsaRoots[%d] is not a PEM certificate", i)
+		}
+		_, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("trustedTsaRoots[%d] does not parse: %v", i, err)
+		}
+	}
+	return nil
+}
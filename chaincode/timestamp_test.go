@@ -0,0 +1,219 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	oidSHA256          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidECDSAWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+)
+
+// testGenTime is the time the test authority attests, inside its
+// certificates' validity
+var testGenTime = time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+
+// testAuthority is a timestamp authority: a root and the timestamping
+// certificate it issued
+type testAuthority struct {
+	rootPEM string
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+}
+
+func newTestAuthority(t *testing.T, name string) *testAuthority {
+	t.Helper()
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name + " Root"},
+		NotBefore:             testGenTime.AddDate(-1, 0, 0),
+		NotAfter:              testGenTime.AddDate(5, 0, 0),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, rootTemplate, rootTemplate, &rootKey.PublicKey, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := x509.ParseCertificate(rootDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: name + " TSA"},
+		NotBefore:    testGenTime.AddDate(-1, 0, 0),
+		NotAfter:     testGenTime.AddDate(1, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, root, &key.PublicKey, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: rootDER})
+	return &testAuthority{rootPEM: string(rootPEM), cert: cert, key: key}
+}
+
+// issue returns a DER TimeStampToken over the hex SHA-256 digest
+func (a *testAuthority) issue(t *testing.T, digest string) []byte {
+	t.Helper()
+	hashed, err := hex.DecodeString(digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := struct {
+		Version        int
+		Policy         asn1.ObjectIdentifier
+		MessageImprint struct {
+			HashAlgorithm pkix.AlgorithmIdentifier
+			HashedMessage []byte
+		}
+		SerialNumber *big.Int
+		GenTime      time.Time `asn1:"generalized"`
+	}{Version: 1, Policy: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 1}, SerialNumber: big.NewInt(42), GenTime: testGenTime}
+	info.MessageImprint.HashAlgorithm.Algorithm = oidSHA256
+	info.MessageImprint.HashedMessage = hashed
+	content := mustMarshal(t, info)
+
+	contentDigest := sha256.Sum256(content)
+	signedAttrs := mustMarshalWithParams(t, []attribute{
+		{Type: oidContentType, Values: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: mustMarshal(t, oidTSTInfo)}},
+		{Type: oidMessageDigest, Values: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: mustMarshal(t, contentDigest[:])}},
+	}, "set")
+	attrsDigest := sha256.Sum256(signedAttrs)
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, attrsDigest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer := struct {
+		Version            int
+		SID                asn1.RawValue
+		DigestAlgorithm    pkix.AlgorithmIdentifier
+		SignedAttrs        asn1.RawValue
+		SignatureAlgorithm pkix.AlgorithmIdentifier
+		Signature          []byte
+	}{
+		Version:            1,
+		SID:                asn1.RawValue{FullBytes: mustMarshal(t, issuerAndSerialNumber{Issuer: asn1.RawValue{FullBytes: a.cert.RawIssuer}, SerialNumber: a.cert.SerialNumber})},
+		DigestAlgorithm:    pkix.AlgorithmIdentifier{Algorithm: oidSHA256},
+		SignedAttrs:        asn1.RawValue{FullBytes: append([]byte{0xa0}, signedAttrs[1:]...)}, // [0] IMPLICIT
+		SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidECDSAWithSHA256},
+		Signature:          signature,
+	}
+	signed := struct {
+		Version          int
+		DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+		EncapContentInfo struct {
+			EContentType asn1.ObjectIdentifier
+			EContent     []byte `asn1:"explicit,tag:0"`
+		}
+		Certificates asn1.RawValue
+		SignerInfos  []asn1.RawValue `asn1:"set"`
+	}{
+		Version:          3,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{{Algorithm: oidSHA256}},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: a.cert.Raw},
+		SignerInfos:      []asn1.RawValue{{FullBytes: mustMarshal(t, signer)}},
+	}
+	signed.EncapContentInfo.EContentType = oidTSTInfo
+	signed.EncapContentInfo.EContent = content
+
+	return mustMarshal(t, struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue
+	}{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: mustMarshal(t, signed)},
+	})
+}
+
+func mustMarshal(t *testing.T, value interface{}) []byte {
+	t.Helper()
+	return mustMarshalWithParams(t, value, "")
+}
+
+func mustMarshalWithParams(t *testing.T, value interface{}, params string) []byte {
+	t.Helper()
+	der, err := asn1.MarshalWithParams(value, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func TestVerifyTimestampToken(t *testing.T) {
+	authority := newTestAuthority(t, "Test")
+	other := newTestAuthority(t, "Other")
+	digest := hex.EncodeToString(sha256.New().Sum(nil))
+	otherDigest := strings.Repeat("ab", sha256.Size)
+
+	tests := []struct {
+		name    string
+		roots   []string
+		digest  string // the digest verified against the token, which is over digest
+		problem string // substring of the expected error; empty for a valid token
+	}{
+		{name: "valid", roots: []string{authority.rootPEM}, digest: digest},
+		{name: "valid among several roots", roots: []string{other.rootPEM, authority.rootPEM}, digest: digest},
+		{name: "wrong digest", roots: []string{authority.rootPEM}, digest: otherDigest, problem: "does not timestamp the SHA-256 digest"},
+		{name: "untrusted root", roots: []string{other.rootPEM}, digest: digest, problem: "is not trusted"},
+		{name: "no trusted roots", digest: digest, problem: "no timestamp authority is trusted"},
+	}
+	token := authority.issue(t, digest)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stub := newBenchStub()
+			configJSON, err := json.Marshal(map[string]interface{}{"trustedTsaRoots": test.roots})
+			if err != nil {
+				t.Fatal(err)
+			}
+			stub.state[configKey] = configJSON
+
+			info, cert, err := verifyTimestampToken(newBenchContext(stub), token, test.digest)
+			if test.problem != "" {
+				if err == nil || !strings.Contains(err.Error(), test.problem) {
+					t.Fatalf("got error %v, want one containing %q", err, test.problem)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verifyTimestampToken: %v", err)
+			}
+			if !info.GenTime.Equal(testGenTime) || info.SerialNumber.Int64() != 42 {
+				t.Errorf("got genTime %v and serial %v, want %v and 42", info.GenTime, info.SerialNumber, testGenTime)
+			}
+			if cert.Subject.CommonName != "Test TSA" {
+				t.Errorf("got signer %q, want Test TSA", cert.Subject.CommonName)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+var (
+	panPattern   = regexp.MustCompile(`^[A-Z]{5}[0-9]{4}[A-Z]$`)
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// entityRules describes how records of one entity type are validated
+type entityRules struct {
+	// panHolderType is the fourth character of a PAN issued to this legal form
+	panHolderType     byte
+	requiresDOB       bool
+	requiredDocuments []string
+	validateStructure func(kyc *KYCRecord) error
+}
+
+// entityRuleSet maps each supported entity type to its validation rules
+var entityRuleSet = map[string]entityRules{
+	EntityIndividual: {
+		panHolderType:     'P',
+		requiresDOB:       true,
+		validateStructure: validateIndividualStructure,
+	},
+	EntityCompany: {
+		panHolderType:     'C',
+		requiredDocuments: []string{"PAN", "CERTIFICATE_OF_INCORPORATION", "MOA", "AOA", "BOARD_RESOLUTION"},
+		validateStructure: validateCompanyStructure,
+	},
+	EntityTrust: {
+		panHolderType:     'T',
+		requiredDocuments: []string{"PAN", "REGISTRATION_CERTIFICATE", "TRUST_DEED"},
+		validateStructure: validateTrustStructure,
+	},
+	EntityPartnership: {
+		panHolderType:     'F',
+		requiredDocuments: []string{"PAN", "REGISTRATION_CERTIFICATE", "PARTNERSHIP_DEED"},
+		validateStructure: validatePartnershipStructure,
+	},
+	EntityHUF: {
+		panHolderType:     'H',
+		requiredDocuments: []string{"PAN", "HUF_DECLARATION"},
+		validateStructure: validateHUFStructure,
+	},
+}
+
+// Checks a validation problem is reported under
+const (
+	ValidationCheckSchema     = "schema"
+	ValidationCheckFormat     = "format"
+	ValidationCheckStructure  = "structure"
+	ValidationCheckDocuments  = "documents"
+	ValidationCheckExtensions = "extensions"
+	ValidationCheckEncryption = "encryption"
+	ValidationCheckDuplicate  = "duplicate"
+	ValidationCheckScreening  = "screening"
+	ValidationCheckHooks      = "hooks"
+	ValidationCheckQuota      = "quota"
+)
+
+// ValidationProblem is one reason a submission would be rejected
+type ValidationProblem struct {
+	Check   string `json:"check"`
+	Message string `json:"message"`
+}
+
+// SubmissionValidation is the outcome of a dry-run submission
+type SubmissionValidation struct {
+	Valid    bool                `json:"valid"` // CreateKYC would accept the submission as it stands
+	Problems []ValidationProblem `json:"problems"`
+	Flags    []RecordFlag        `json:"flags"` // raised on the record if it were created now
+}
+
+// validateKYCRecord selects the rule set for the record's entity type and applies
+// the field, structure and document rules in turn
+func validateKYCRecord(kyc *KYCRecord) error {
+	problems := kycRecordProblems(kyc)
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", problems[0].Message)
+	}
+	return nil
+}
+
+// kycRecordProblems applies every rule validateKYCRecord does, returning all
+// the problems found in the order validateKYCRecord reports them
+func kycRecordProblems(kyc *KYCRecord) []ValidationProblem {
+	rules, ok := entityRuleSet[kyc.EntityType]
+	if !ok {
+		return []ValidationProblem{{Check: ValidationCheckSchema, Message: fmt.Sprintf("unsupported entity type %q", kyc.EntityType)}}
+	}
+
+	problems := commonFieldProblems(kyc, rules)
+
+	err := rules.validateStructure(kyc)
+	if err != nil {
+		problems = append(problems, ValidationProblem{Check: ValidationCheckStructure, Message: err.Error()})
+	}
+
+	missing := missingDocuments(kyc.DocumentHashes, rules.requiredDocuments)
+	if len(missing) > 0 {
+		problems = append(problems, ValidationProblem{Check: ValidationCheckDocuments, Message: fmt.Sprintf("missing required documents for %s: %v", kyc.EntityType, missing)})
+	}
+
+	return problems
+}
+
+// commonFieldProblems checks the identity fields shared by every entity type
+func commonFieldProblems(kyc *KYCRecord, rules entityRules) []ValidationProblem {
+	var problems []ValidationProblem
+	problem := func(check string, format string, args ...interface{}) {
+		problems = append(problems, ValidationProblem{Check: check, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if kyc.ID == "" {
+		problem(ValidationCheckSchema, "KYC ID is required")
+	}
+	// a private record's personal details were checked when it was submitted
+	private := kyc.PrivateData != nil
+	if kyc.Name == "" && !private {
+		problem(ValidationCheckSchema, "name is required")
+	}
+
+	if private && kyc.PAN == "" {
+		// held in private data
+	} else if !panPattern.MatchString(kyc.PAN) {
+		problem(ValidationCheckFormat, "invalid PAN format %q", kyc.PAN)
+	} else if kyc.PAN[3] != rules.panHolderType {
+		problem(ValidationCheckFormat, "PAN %s is not issued to a %s holder", kyc.PAN, kyc.EntityType)
+	}
+
+	if kyc.Email != "" && !emailPattern.MatchString(kyc.Email) {
+		problem(ValidationCheckFormat, "invalid email address %q", kyc.Email)
+	}
+
+	if _, ok := countryName(kyc.Address.Country); !ok {
+		problem(ValidationCheckFormat, "country %q is not an ISO 3166-1 alpha-2 code", kyc.Address.Country)
+	}
+
+	if kyc.Address.Pincode != "" && isIndianAddress(kyc.Address) && !pincodePattern.MatchString(kyc.Address.Pincode) {
+		problem(ValidationCheckFormat, "invalid pincode %q", kyc.Address.Pincode)
+	}
+
+	if (rules.requiresDOB && kyc.EncryptedFields["dateOfBirth"] == nil && !private) || kyc.DateOfBirth != "" {
+		_, err := time.Parse("2006-01-02", kyc.DateOfBirth)
+		if err != nil {
+			problem(ValidationCheckFormat, "invalid date of birth %q, expected YYYY-MM-DD", kyc.DateOfBirth)
+		}
+	}
+
+	return problems
+}
+
+// missingDocuments returns the required document types not present in docs
+func missingDocuments(docs []DocumentHash, required []string) []string {
+	present := map[string]bool{}
+	for _, doc := range docs {
+		present[doc.Type] = true
+	}
+
+	var missing []string
+	for _, docType := range required {
+		if !present[docType] {
+			missing = append(missing, docType)
+		}
+	}
+	return missing
+}
+
+// ValidateKYC runs a submission through the checks CreateKYC applies,
+// including the validation hooks configured for creation and the caller's
+// submission quota, without writing anything, and returns every problem
+// found rather than stopping at the first, so front-ends can correct a
+// submission before sending it as a transaction. A submission with no
+// problems can still fail if the ledger changes before it is created. The
+// blacklist is not screened, since a dry run would let a submitter probe it
+// without leaving a record.
+func (s *SmartContract) ValidateKYC(ctx contractapi.TransactionContextInterface, kycData string) (*SubmissionValidation, error) {
+	err := requireAllowedMSP(ctx, AllowlistSubmitter)
+	if err != nil {
+		return nil, err
+	}
+	result := &SubmissionValidation{Problems: []ValidationProblem{}, Flags: []RecordFlag{}}
+	problem := func(check string, err error) {
+		result.Problems = append(result.Problems, ValidationProblem{Check: check, Message: err.Error()})
+	}
+
+	var kyc KYCRecord
+	err = json.Unmarshal([]byte(kycData), &kyc)
+	if err != nil {
+		problem(ValidationCheckSchema, fmt.Errorf("failed to unmarshal KYC data: %v", err))
+		return result, nil
+	}
+
+	err = normalizeSubmission(&kyc)
+	if err != nil {
+		problem(ValidationCheckFormat, err)
+	}
+	result.Problems = append(result.Problems, kycRecordProblems(&kyc)...)
+
+	namespaces := make([]string, 0, len(kyc.Extensions))
+	for namespace := range kyc.Extensions {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		err = s.validateExtensions(ctx, map[string]interface{}{namespace: kyc.Extensions[namespace]})
+		if err != nil {
+			problem(ValidationCheckExtensions, err)
+		}
+	}
+
+	if kyc.ID != "" {
+		exists, err := s.KYCExists(ctx, kyc.ID)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			problem(ValidationCheckDuplicate, fmt.Errorf("KYC record %s already exists", kyc.ID))
+		}
+		archived, err := readArchivedRecord(ctx, kyc.ID)
+		if err != nil {
+			return nil, err
+		}
+		if archived != nil {
+			problem(ValidationCheckDuplicate, fmt.Errorf("KYC record %s is archived; restore it with RestoreKYC", kyc.ID))
+		}
+	}
+
+	kyc.OwnerMSP, err = ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	err = checkEncryptedFields(ctx, &kyc)
+	if err != nil {
+		problem(ValidationCheckEncryption, err)
+	}
+
+	now := s.txTime(ctx).Format(time.RFC3339)
+	err = checkSubmissionQuota(ctx, kyc.OwnerMSP, now)
+	if err != nil {
+		problem(ValidationCheckQuota, err)
+	}
+	kyc.Flags = nil
+	checkPincodeState(&kyc, now)
+	err = screenEmailDomain(ctx, &kyc, now)
+	if err != nil {
+		problem(ValidationCheckScreening, err)
+	}
+	if kyc.Phone != "" {
+		samePhone, err := getIndexedIDs(ctx, phoneIndex, phoneHash(kyc.Phone))
+		if err != nil {
+			return nil, err
+		}
+		if len(samePhone) > 0 {
+			addFlag(&kyc, FlagDuplicatePhone, fmt.Sprintf("phone number already registered on %d other record(s)", len(samePhone)), now)
+		}
+	}
+	result.Flags = append(result.Flags, kyc.Flags...)
+
+	hookResults, err := evaluateValidationHooks(ctx, &kyc, HookOperationCreate)
+	if err != nil {
+		return nil, err
+	}
+	for _, hookResult := range hookResults {
+		if !hookResult.Passed && !hookResult.Advisory {
+			problem(ValidationCheckHooks, fmt.Errorf("validation hook %s: %s", hookResult.Name, hookResult.Message))
+		}
+	}
+
+	result.Valid = len(result.Problems) == 0
+	return result, nil
+}
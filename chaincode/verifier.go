@@ -0,0 +1,328 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Verifier registry. A Fabric client ID is derived from its certificate, so
+// a verifier who re-enrols under a new subject or CA would appear in history
+// and registers as someone new. An administrator registers a verifier under
+// a stable ID with RegisterVerifier, bound to the SHA-256 fingerprint of
+// their certificate, and rebinds the ID with RotateVerifierCertificate when
+// they re-enrol. Registered verifiers are recorded by their verifier ID
+// wherever the contract records who acted, and only their current
+// certificate is accepted: one rotated out is refused, even while it is
+// still valid. Unregistered callers are recorded by client ID as before.
+//
+// Verifiers are stored under "VERIFIER~<verifier ID>" and each certificate
+// ever bound under "VERIFIERCERT~<fingerprint>", so an audit can tell which
+// verifier an old certificate belonged to.
+const (
+	verifierObjectType     = "VERIFIER"
+	verifierCertObjectType = "VERIFIERCERT"
+)
+
+// verifierIDPattern is the form of a verifier ID, kept apart from the base64
+// client IDs unregistered callers are recorded by
+var verifierIDPattern = regexp.MustCompile(`^[a-z][a-z0-9._-]{1,63}$`)
+
+// Verifier is a registered verifier and the certificates bound to them
+type Verifier struct {
+	VerifierID   string                `json:"verifierId"`
+	MSPID        string                `json:"mspId"`
+	Fingerprint  string                `json:"fingerprint"` // of the current certificate
+	Certificates []VerifierCertificate `json:"certificates"`
+	RegisteredBy string                `json:"registeredBy"`
+	RegisteredAt string                `json:"registeredAt"`
+}
+
+// VerifierCertificate is one certificate bound to a verifier
+type VerifierCertificate struct {
+	VerifierID  string `json:"verifierId"`
+	Fingerprint string `json:"fingerprint"` // hex SHA-256 of the certificate's DER
+	Subject     string `json:"subject"`
+	Issuer      string `json:"issuer"`
+	NotAfter    string `json:"notAfter"`
+	BoundBy     string `json:"boundBy"`
+	BoundAt     string `json:"boundAt"`
+	TxID        string `json:"txId"`
+	RotatedAt   string `json:"rotatedAt,omitempty" metadata:",optional"` // when a newer certificate replaced it
+}
+
+// RegisterVerifier registers a verifier of the caller's organisation under
+// verifierID, bound to certData, their PEM enrolment certificate. Only
+// administrators can register verifiers.
+func (s *SmartContract) RegisterVerifier(ctx contractapi.TransactionContextInterface, verifierID string, certData string) (*Verifier, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !verifierIDPattern.MatchString(verifierID) {
+		return nil, fmt.Errorf("verifier ID must be 2-64 lowercase letters, digits, dots, dashes or underscores, starting with a letter")
+	}
+	existing, err := getVerifier(ctx, verifierID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("verifier %s is already registered", verifierID)
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	registeredBy, err := clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	verifier := &Verifier{
+		VerifierID:   verifierID,
+		MSPID:        mspID,
+		Certificates: []VerifierCertificate{},
+		RegisteredBy: registeredBy,
+		RegisteredAt: s.txTime(ctx).Format(time.RFC3339),
+	}
+	err = bindVerifierCertificate(ctx, verifier, certData, registeredBy)
+	if err != nil {
+		return nil, err
+	}
+	return verifier, nil
+}
+
+// RotateVerifierCertificate binds a registered verifier's ID to certData,
+// the PEM certificate they re-enrolled with, in place of their current one.
+// An administrator of the verifier's organisation, or the verifier with
+// their current certificate, can rotate it.
+func (s *SmartContract) RotateVerifierCertificate(ctx contractapi.TransactionContextInterface, verifierID string, certData string) (*Verifier, error) {
+	verifier, err := s.GetVerifier(ctx, verifierID)
+	if err != nil {
+		return nil, err
+	}
+	rotatedBy, err := clientActorID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+	if rotatedBy != verifier.VerifierID {
+		err = requireAdmin(ctx)
+		if err != nil {
+			return nil, err
+		}
+		mspID, err := ctx.GetClientIdentity().GetMSPID()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+		}
+		if mspID != verifier.MSPID {
+			return nil, fmt.Errorf("verifier %s is registered to %s", verifierID, verifier.MSPID)
+		}
+	}
+
+	now := s.txTime(ctx).Format(time.RFC3339)
+	for i := range verifier.Certificates {
+		cert := &verifier.Certificates[i]
+		if cert.Fingerprint == verifier.Fingerprint {
+			cert.RotatedAt = now
+			err = putVerifierCertificate(ctx, cert)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	err = bindVerifierCertificate(ctx, verifier, certData, rotatedBy)
+	if err != nil {
+		return nil, err
+	}
+	return verifier, nil
+}
+
+// GetVerifier returns a registered verifier with every certificate bound to
+// them
+func (s *SmartContract) GetVerifier(ctx contractapi.TransactionContextInterface, verifierID string) (*Verifier, error) {
+	verifier, err := getVerifier(ctx, verifierID)
+	if err != nil {
+		return nil, err
+	}
+	if verifier == nil {
+		return nil, fmt.Errorf("verifier %s is not registered", verifierID)
+	}
+	return verifier, nil
+}
+
+// GetVerifierByCertificate returns the certificate binding of a fingerprint,
+// naming the verifier it belongs or belonged to
+func (s *SmartContract) GetVerifierByCertificate(ctx contractapi.TransactionContextInterface, fingerprint string) (*VerifierCertificate, error) {
+	cert, err := getVerifierCertificate(ctx, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	if cert == nil {
+		return nil, fmt.Errorf("certificate %s is not bound to a verifier", fingerprint)
+	}
+	return cert, nil
+}
+
+// clientActorID returns the identity the contract records the caller by:
+// their verifier ID when their certificate is bound to one, or else their
+// client ID, followed by the operator a gateway caller acts for. A
+// certificate rotated out of a verifier's binding is refused.
+func clientActorID(ctx contractapi.TransactionContextInterface) (string, error) {
+	actor, err := identityActorID(ctx)
+	if err != nil {
+		return "", err
+	}
+	operator, err := transientOperator(ctx)
+	if err != nil || operator == "" {
+		return actor, err
+	}
+	return actor + operatorSeparator + operator, nil
+}
+
+// identityActorID is clientActorID without the operator
+func identityActorID(ctx contractapi.TransactionContextInterface) (string, error) {
+	cert, err := ctx.GetClientIdentity().GetX509Certificate()
+	if err != nil {
+		return "", err
+	}
+	if cert != nil {
+		binding, err := getVerifierCertificate(ctx, certificateFingerprint(cert))
+		if err != nil {
+			return "", err
+		}
+		if binding != nil {
+			if binding.RotatedAt != "" {
+				return "", fmt.Errorf("this certificate of verifier %s was rotated out at %s; use the current one", binding.VerifierID, binding.RotatedAt)
+			}
+			return binding.VerifierID, nil
+		}
+	}
+	return ctx.GetClientIdentity().GetID()
+}
+
+// certificateFingerprint is the hex SHA-256 of a certificate's DER
+func certificateFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// bindVerifierCertificate binds a PEM certificate to a verifier as their
+// current one and stores both
+func bindVerifierCertificate(ctx contractapi.TransactionContextInterface, verifier *Verifier, certData string, boundBy string) error {
+	block, _ := pem.Decode([]byte(certData))
+	if block == nil {
+		return fmt.Errorf("certificate is not PEM encoded")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse certificate: %v", err)
+	}
+	fingerprint := certificateFingerprint(cert)
+	bound, err := getVerifierCertificate(ctx, fingerprint)
+	if err != nil {
+		return err
+	}
+	if bound != nil {
+		return fmt.Errorf("certificate %s is already bound to verifier %s", fingerprint, bound.VerifierID)
+	}
+
+	binding := VerifierCertificate{
+		VerifierID:  verifier.VerifierID,
+		Fingerprint: fingerprint,
+		Subject:     cert.Subject.String(),
+		Issuer:      cert.Issuer.String(),
+		NotAfter:    cert.NotAfter.UTC().Format(time.RFC3339),
+		BoundBy:     boundBy,
+		BoundAt:     txTime(ctx).Format(time.RFC3339),
+		TxID:        ctx.GetStub().GetTxID(),
+	}
+	err = putVerifierCertificate(ctx, &binding)
+	if err != nil {
+		return err
+	}
+	verifier.Fingerprint = fingerprint
+	verifier.Certificates = append(verifier.Certificates, binding)
+
+	key, err := ctx.GetStub().CreateCompositeKey(verifierObjectType, []string{verifier.VerifierID})
+	if err != nil {
+		return err
+	}
+	verifierJSON, err := json.Marshal(verifier)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, verifierJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put verifier: %v", err)
+	}
+	return nil
+}
+
+// getVerifier loads a verifier, returning nil when none is registered
+func getVerifier(ctx contractapi.TransactionContextInterface, verifierID string) (*Verifier, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(verifierObjectType, []string{verifierID})
+	if err != nil {
+		return nil, err
+	}
+	verifierJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if verifierJSON == nil {
+		return nil, nil
+	}
+
+	var verifier Verifier
+	err = json.Unmarshal(verifierJSON, &verifier)
+	if err != nil {
+		return nil, err
+	}
+	return &verifier, nil
+}
+
+// getVerifierCertificate loads the binding of a certificate fingerprint,
+// returning nil when it was never bound
+func getVerifierCertificate(ctx contractapi.TransactionContextInterface, fingerprint string) (*VerifierCertificate, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(verifierCertObjectType, []string{fingerprint})
+	if err != nil {
+		return nil, err
+	}
+	certJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if certJSON == nil {
+		return nil, nil
+	}
+
+	var cert VerifierCertificate
+	err = json.Unmarshal(certJSON, &cert)
+	if err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// putVerifierCertificate stores a certificate binding under its fingerprint
+func putVerifierCertificate(ctx contractapi.TransactionContextInterface, cert *VerifierCertificate) error {
+	key, err := ctx.GetStub().CreateCompositeKey(verifierCertObjectType, []string{cert.Fingerprint})
+	if err != nil {
+		return err
+	}
+	certJSON, err := json.Marshal(cert)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, certJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put verifier certificate: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"runtime/debug"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// contractVersion is the chaincode's semantic version. Bump it with every
+// release, alongside CHAINCODE_VERSION in scripts/deploy-network.sh.
+const contractVersion = "1.0.0"
+
+// buildCommit is the source commit the chaincode was built from, set with
+// -ldflags "-X main.buildCommit=<commit>". Left empty, the VCS revision Go
+// stamps into binaries built from a checkout is used.
+var buildCommit = ""
+
+// VersionInfo identifies the contract a peer runs
+type VersionInfo struct {
+	Version       string `json:"version"`       // semantic version of the chaincode
+	SchemaVersion int    `json:"schemaVersion"` // record schema the chaincode writes
+	ConfigVersion int    `json:"configVersion"` // changes made to the contract configuration
+	PolicyVersion int    `json:"policyVersion"` // consortium policy version in effect
+	BuildCommit   string `json:"buildCommit"`   // "unknown" when the build recorded none
+}
+
+// Health is the answer to Ping
+type Health struct {
+	Status    string `json:"status"` // OK
+	Version   string `json:"version"`
+	CheckedAt string `json:"checkedAt"`
+}
+
+// Ping reports that the chaincode is running and can read its world state,
+// for liveness and readiness probes. Any identity can ping.
+func (s *SmartContract) Ping(ctx contractapi.TransactionContextInterface) (*Health, error) {
+	_, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Health{
+		Status:    "OK",
+		Version:   contractVersion,
+		CheckedAt: s.txTime(ctx).Format(time.RFC3339),
+	}, nil
+}
+
+// GetVersion returns the version of the chaincode and of the schema,
+// configuration and policy it runs with, so deployment automation can
+// confirm every peer runs the expected contract. Any identity can read it.
+func (s *SmartContract) GetVersion(ctx contractapi.TransactionContextInterface) (*VersionInfo, error) {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := currentPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &VersionInfo{
+		Version:       contractVersion,
+		SchemaVersion: recordSchema,
+		ConfigVersion: config.Version,
+		PolicyVersion: policy.Version,
+		BuildCommit:   sourceCommit(),
+	}, nil
+}
+
+// sourceCommit returns buildCommit, or else the VCS revision in the build
+// information
+func sourceCommit() string {
+	if buildCommit != "" {
+		return buildCommit
+	}
+	info, ok := debug.ReadBuildInfo()
+	if ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return setting.Value
+			}
+		}
+	}
+	return "unknown"
+}
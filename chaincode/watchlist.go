@@ -0,0 +1,184 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// Watchlist synchronisation modes
+const (
+	SyncReplace = "REPLACE"
+	SyncDelta   = "DELTA"
+)
+
+// maxSyncEntries bounds the entries written or removed by one SyncWatchlist call
+const maxSyncEntries = 10000
+
+// WatchlistEntry is an entry supplied to SyncWatchlist
+type WatchlistEntry struct {
+	Key        string            `json:"key"`
+	Reason     string            `json:"reason,omitempty" metadata:",optional"`
+	Attributes map[string]string `json:"attributes,omitempty" metadata:",optional"`
+}
+
+// SyncWatchlist brings a named watchlist up to a new source version in one
+// transaction. In REPLACE mode entries becomes the complete list; in DELTA
+// mode entries are upserted and removals deleted. The SHA-256 content hash of
+// the resulting list is stored with it and, when expectedHash is given, must
+// match so a publisher's list can be verified end to end.
+func (s *SmartContract) SyncWatchlist(ctx contractapi.TransactionContextInterface, listName string, version string, mode string, entries []WatchlistEntry, removals []string, expectedHash string) (*ReferenceList, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mode = strings.ToUpper(mode)
+	if mode != SyncReplace && mode != SyncDelta {
+		return nil, fmt.Errorf("sync mode must be %s or %s", SyncReplace, SyncDelta)
+	}
+	if mode == SyncReplace && len(removals) > 0 {
+		return nil, fmt.Errorf("removals are only accepted in %s mode", SyncDelta)
+	}
+	if len(entries)+len(removals) > maxSyncEntries {
+		return nil, fmt.Errorf("at most %d entries can be synchronised per transaction", maxSyncEntries)
+	}
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return nil, fmt.Errorf("list version is required")
+	}
+
+	list, err := loadOrCreateList(ctx, listName, ListWatchlist)
+	if err != nil {
+		return nil, err
+	}
+	if list.Type != ListWatchlist {
+		return nil, fmt.Errorf("list %s is a %s, not a %s", list.Name, list.Type, ListWatchlist)
+	}
+	if list.SourceVersion == version {
+		return nil, fmt.Errorf("list %s is already at version %s", list.Name, version)
+	}
+
+	// reads do not observe this transaction's writes, so the resulting list
+	// is assembled in memory to count and hash it
+	current, err := listEntries(ctx, list.Name)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]WatchlistEntry{}
+	if mode == SyncDelta {
+		for key, entry := range current {
+			result[key] = WatchlistEntry{Key: key, Reason: entry.Reason, Attributes: entry.Attributes}
+		}
+		for _, key := range removals {
+			key = listEntryKey(key)
+			if _, ok := result[key]; !ok {
+				return nil, fmt.Errorf("list %s has no entry %s", list.Name, key)
+			}
+			delete(result, key)
+		}
+	}
+	upserts := map[string]bool{}
+	for _, entry := range entries {
+		key := listEntryKey(entry.Key)
+		if upserts[key] {
+			return nil, fmt.Errorf("duplicate list entry key %q", entry.Key)
+		}
+		upserts[key] = true
+		entry.Key = key
+		entry.Reason = strings.TrimSpace(entry.Reason)
+		result[key] = entry
+	}
+
+	contentHash := watchlistHash(result)
+	if expectedHash != "" && !strings.EqualFold(expectedHash, contentHash) {
+		return nil, fmt.Errorf("list content hash %s does not match expected hash %s", contentHash, expectedHash)
+	}
+
+	err = touchList(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+	for key := range current {
+		if _, ok := result[key]; ok {
+			continue
+		}
+		entryKey, err := ctx.GetStub().CreateCompositeKey(listEntryObjectType, []string{list.Name, key})
+		if err != nil {
+			return nil, err
+		}
+		err = ctx.GetStub().DelState(entryKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for key := range upserts {
+		entry := result[key]
+		_, err = putListEntry(ctx, list, entry.Key, entry.Reason, entry.Attributes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	list.EntryCount = len(result)
+	list.SourceVersion = version
+	list.ContentHash = contentHash
+	return list, saveList(ctx, list)
+}
+
+// listEntries returns every entry of a named list keyed by entry key
+func listEntries(ctx contractapi.TransactionContextInterface, listName string) (map[string]*ListEntry, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(listEntryObjectType, []string{listName})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := map[string]*ListEntry{}
+	err = forEachResult(ctx, resultsIterator, func(queryResponse *queryresult.KV) error {
+		var entry ListEntry
+		err := json.Unmarshal(queryResponse.Value, &entry)
+		if err != nil {
+			return err
+		}
+		entries[entry.Key] = &entry
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// watchlistHash returns the hex SHA-256 digest of a list's content. Each entry
+// contributes one line of tab-separated key, reason and key=value attributes,
+// with entries ordered by key and attributes by name.
+func watchlistHash(entries map[string]WatchlistEntry) string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	digest := sha256.New()
+	for _, key := range keys {
+		entry := entries[key]
+		attrNames := make([]string, 0, len(entry.Attributes))
+		for name := range entry.Attributes {
+			attrNames = append(attrNames, name)
+		}
+		sort.Strings(attrNames)
+
+		fields := []string{entry.Key, entry.Reason}
+		for _, name := range attrNames {
+			fields = append(fields, name+"="+entry.Attributes[name])
+		}
+		digest.Write([]byte(strings.Join(fields, "\t") + "\n"))
+	}
+	return hex.EncodeToString(digest.Sum(nil))
+}
@@ -0,0 +1,154 @@
+// Package anchor writes digests of the eKYC ledger state outside the Fabric
+// network, to a public chain or an external timestamping service, and
+// records the proof it gets back on the ledger with RecordAnchor. Once a
+// digest is anchored, rewriting the ledger that produced it takes rewriting
+// the target as well, so anchors keep their tamper evidence after the
+// network's own orderers and peers are no longer trusted.
+//
+// The digest is the hex SHA-256 of the ExportAll NDJSON, every page in turn,
+// so it does not depend on the page size and anyone with an export of the
+// same state can recompute it. Pages are read one evaluate at a time, so a
+// digest taken while the ledger is being written covers each document as its
+// page read it rather than one point-in-time state.
+package anchor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	exportPageSize = 500
+	maxReplyBytes  = 64 * 1024 // bounds what a target may return
+)
+
+// Ledger evaluates and submits chaincode functions
+type Ledger interface {
+	Evaluate(ctx context.Context, function string, args ...string) ([]byte, error)
+	Submit(ctx context.Context, function string, args ...string) (string, []byte, error)
+}
+
+// Proof is a target's receipt for a digest
+type Proof struct {
+	Proof      string    // a transaction hash, or a base64 timestamp
+	AnchoredAt time.Time // when the target accepted the digest
+}
+
+// Target writes digests outside the network
+type Target interface {
+	Name() string // stored with each anchor, such as opentimestamps or ethereum
+	Anchor(ctx context.Context, digest []byte) (*Proof, error)
+}
+
+// Digest is a digest of the ledger state
+type Digest struct {
+	Sum       []byte
+	Documents int // export lines the digest covers
+}
+
+// Hex returns the digest as RecordAnchor takes it
+func (d *Digest) Hex() string {
+	return hex.EncodeToString(d.Sum)
+}
+
+// Worker anchors the ledger state to one target
+type Worker struct {
+	ledger Ledger
+	target Target
+	last   string // the digest last anchored, not anchored again while unchanged
+}
+
+// NewWorker returns a worker anchoring the state of ledger to target. The
+// ledger identity must be a contract administrator, as ExportAll and
+// RecordAnchor require.
+func NewWorker(ledger Ledger, target Target) *Worker {
+	return &Worker{ledger: ledger, target: target}
+}
+
+// Digest hashes the ledger state, paging through ExportAll. The pages are
+// evaluated rather than submitted: a submitted page logs itself under
+// BULKACCESS, changing the state being digested, so no pass would find the
+// state unchanged since the last.
+func (w *Worker) Digest(ctx context.Context) (*Digest, error) {
+	hash := sha256.New()
+	digest := &Digest{}
+	bookmark := ""
+	for {
+		pageJSON, err := w.ledger.Evaluate(ctx, "ExportAll", strconv.Itoa(exportPageSize), bookmark)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export ledger state: %v", err)
+		}
+		var page struct {
+			Data     string `json:"data"`
+			Bookmark string `json:"bookmark"`
+		}
+		err = json.Unmarshal(pageJSON, &page)
+		if err != nil {
+			return nil, fmt.Errorf("malformed export page: %v", err)
+		}
+
+		hash.Write([]byte(page.Data))
+		digest.Documents += strings.Count(page.Data, "\n")
+		if page.Bookmark == "" {
+			break
+		}
+		if page.Bookmark == bookmark {
+			return nil, fmt.Errorf("export did not advance past bookmark %q", bookmark)
+		}
+		bookmark = page.Bookmark
+	}
+	digest.Sum = hash.Sum(nil)
+	return digest, nil
+}
+
+// Anchor digests the ledger state, writes the digest to the target and
+// records the proof on the ledger, returning the recording transaction's ID.
+// A state unchanged since the worker last anchored it is not anchored again,
+// and the transaction ID is empty.
+func (w *Worker) Anchor(ctx context.Context) (string, error) {
+	digest, err := w.Digest(ctx)
+	if err != nil {
+		return "", err
+	}
+	if digest.Hex() == w.last {
+		return "", nil
+	}
+
+	proof, err := w.target.Anchor(ctx, digest.Sum)
+	if err != nil {
+		return "", fmt.Errorf("failed to anchor %s to %s: %v", digest.Hex(), w.target.Name(), err)
+	}
+	// the digest is out; if recording fails the next pass anchors it again
+	txID, _, err := w.ledger.Submit(ctx, "RecordAnchor", digest.Hex(), strconv.Itoa(digest.Documents), w.target.Name(), proof.Proof, proof.AnchoredAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return "", fmt.Errorf("failed to record anchor of %s: %v", digest.Hex(), err)
+	}
+	w.last = digest.Hex()
+	log.Printf("anchored %s (%d documents) to %s in %s", digest.Hex(), digest.Documents, w.target.Name(), txID)
+	return txID, nil
+}
+
+// Run anchors the ledger state every interval until ctx is done, logging
+// failures
+func (w *Worker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := w.Anchor(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("anchoring failed: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package anchor
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Ethereum writes digests as the data of a zero-value transaction, sent
+// with eth_sendTransaction from an account the node holds the key of. The
+// proof is the transaction hash, returned once the node has accepted the
+// transaction and before it is mined; check its inclusion on the chain.
+type Ethereum struct {
+	Endpoint string // JSON-RPC URL of the node
+	From     string // sending account, unlocked on the node
+	To       string // receiving account; empty sends to From
+	Network  string // stored in the target name, such as mainnet or sepolia
+	Client   *http.Client
+}
+
+// Name implements Target
+func (e *Ethereum) Name() string {
+	if e.Network == "" {
+		return "ethereum"
+	}
+	return "ethereum:" + e.Network
+}
+
+// Anchor implements Target
+func (e *Ethereum) Anchor(ctx context.Context, digest []byte) (*Proof, error) {
+	to := e.To
+	if to == "" {
+		to = e.From
+	}
+	requestJSON, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "eth_sendTransaction",
+		"params": []interface{}{map[string]string{
+			"from":  e.From,
+			"to":    to,
+			"value": "0x0",
+			"data":  "0x" + hex.EncodeToString(digest),
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, e.Endpoint, bytes.NewReader(requestJSON))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	client := e.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxReplyBytes))
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("node responded %s: %s", response.Status, strings.TrimSpace(string(body)))
+	}
+
+	var reply struct {
+		Result string `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	err = json.Unmarshal(body, &reply)
+	if err != nil {
+		return nil, fmt.Errorf("malformed JSON-RPC reply: %v", err)
+	}
+	if reply.Error != nil {
+		return nil, fmt.Errorf("eth_sendTransaction failed: %s (%d)", reply.Error.Message, reply.Error.Code)
+	}
+	if reply.Result == "" {
+		return nil, fmt.Errorf("eth_sendTransaction returned no transaction hash")
+	}
+	return &Proof{Proof: reply.Result, AnchoredAt: time.Now()}, nil
+}
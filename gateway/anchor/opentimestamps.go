@@ -0,0 +1,57 @@
+package anchor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenTimestamps submits digests to an OpenTimestamps calendar, which
+// aggregates them into a Bitcoin transaction. The proof is the calendar's
+// pending timestamp, base64; upgrade it to a complete one with the
+// OpenTimestamps client once the calendar has confirmed it, normally within
+// a few hours.
+type OpenTimestamps struct {
+	Calendar string // calendar URL, such as https://a.pool.opentimestamps.org
+	Client   *http.Client
+}
+
+// Name implements Target
+func (o *OpenTimestamps) Name() string {
+	return "opentimestamps"
+}
+
+// Anchor implements Target
+func (o *OpenTimestamps) Anchor(ctx context.Context, digest []byte) (*Proof, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(o.Calendar, "/")+"/digest", bytes.NewReader(digest))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/vnd.opentimestamps.v1")
+
+	client := o.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxReplyBytes))
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("calendar responded %s: %s", response.Status, strings.TrimSpace(string(body)))
+	}
+	if len(body) == 0 {
+		return nil, fmt.Errorf("calendar returned an empty timestamp")
+	}
+	return &Proof{Proof: base64.StdEncoding.EncodeToString(body), AnchoredAt: time.Now()}, nil
+}
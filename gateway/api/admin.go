@@ -0,0 +1,358 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"ekyc-gateway/auth"
+	"ekyc-gateway/fabric"
+	"ekyc-gateway/readmodel"
+)
+
+// Operator roles, carried in the roles claim of an operator's token. They
+// mirror the chaincode's certificate attributes of the same names.
+const (
+	RoleVerifier = "kyc.verifier"
+	RoleSenior   = "kyc.senior"
+)
+
+const maxAdminBodySize = 16 << 10
+
+// Submitter submits chaincode transactions
+type Submitter interface {
+	Submit(ctx context.Context, function string, args ...string) (string, []byte, error)
+}
+
+// AttestingSubmitter submits chaincode transactions whose history entry the
+// submitting identity signs
+type AttestingSubmitter interface {
+	SubmitAttested(ctx context.Context, attestation fabric.Attestation, function string, args ...string) (string, []byte, error)
+}
+
+// ServeAdmin serves the back-office API behind a review console. Every
+// request needs an operator bearer token; verifiers work their own queue and
+// senior verifiers any queue, the escalated records in the unassigned pool
+// among them:
+//
+//	GET  /api/admin/queue              the caller's pending queue with SLA timers;
+//	                                   ?assignee= (seniors only; empty for unassigned)
+//	GET  /api/admin/kyc/{id}           record, documents, history and SLA timer
+//	POST /api/admin/kyc/{id}/decision  {status: VERIFIED|REJECTED, reason}
+//	POST /api/admin/kyc/{id}/escalate  {reason}
+//	POST /api/admin/kyc/{id}/assign    {assignee} (seniors only)
+//
+// Decisions and escalations are recorded on the ledger as the gateway
+// identity acting for the operator's token subject (see fabric.WithOperator),
+// so dual approval needs two operators. The queue is read from the projector's views; anything
+// checked before a change is read from the ledger.
+func (s *Server) ServeAdmin(submitter Submitter, views *readmodel.Views, operators *auth.Verifier) {
+	s.submitter = submitter
+	s.views = views
+	s.operators = operators
+	s.mux.HandleFunc("/api/admin/queue", s.authenticated(s.handleAdminQueue))
+	s.mux.HandleFunc("/api/admin/kyc/", s.authenticated(s.handleAdminKYC))
+}
+
+// SignDecisions has the back-office API sign the history entry of every
+// decision, escalation and assignment with the key of the identity that
+// submits it, which must then implement AttestingSubmitter, so each entry
+// carries a verifier signature and the identifier of the key that made it.
+func (s *Server) SignDecisions() {
+	s.signDecisions = true
+}
+
+type operatorHandler func(w http.ResponseWriter, r *http.Request, operator *auth.Principal)
+
+// authenticated admits operators holding at least the verifier role
+func (s *Server) authenticated(handle operatorHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		operator, ok := s.authenticateOperator(w, r)
+		if !ok {
+			return
+		}
+		// the chaincode records the gateway identity's transactions as the operator's
+		handle(w, r.WithContext(fabric.WithOperator(r.Context(), operator.Subject)), operator)
+	}
+}
+
+// authenticateOperator returns the operator presenting the request's bearer
+// token if they hold at least the verifier role, and otherwise writes the
+// failure and returns false
+func (s *Server) authenticateOperator(w http.ResponseWriter, r *http.Request) (*auth.Principal, bool) {
+	var operator *auth.Principal
+	err := auth.ErrUnauthenticated
+	if s.operators != nil {
+		operator, err = s.operators.Authenticate(r)
+	}
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="ekyc-admin"`)
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return nil, false
+	}
+	if !operator.HasRole(RoleVerifier) && !operator.HasRole(RoleSenior) {
+		writeError(w, http.StatusForbidden, "a verifier role is required")
+		return nil, false
+	}
+	return operator, true
+}
+
+// slaTimer is the time left to decide a pending record
+type slaTimer struct {
+	DueAt            string `json:"dueAt"`
+	RemainingSeconds int64  `json:"remainingSeconds"` // negative once overdue
+	Breached         bool   `json:"breached"`
+}
+
+// newSLATimer returns the timer of a record due at dueAt, or nil when it has
+// no due date
+func newSLATimer(dueAt string, now time.Time) *slaTimer {
+	due, err := time.Parse(time.RFC3339, dueAt)
+	if err != nil {
+		return nil
+	}
+	remaining := due.Sub(now)
+	return &slaTimer{DueAt: dueAt, RemainingSeconds: int64(remaining / time.Second), Breached: remaining < 0}
+}
+
+type queueEntry struct {
+	*readmodel.Entry
+	SLA *slaTimer `json:"sla,omitempty"`
+}
+
+func (s *Server) handleAdminQueue(w http.ResponseWriter, r *http.Request, operator *auth.Principal) {
+	limit, ok := listLimit(w, r)
+	if !ok {
+		return
+	}
+	assignee := operator.Subject
+	if values, ok := r.URL.Query()["assignee"]; ok {
+		if !operator.HasRole(RoleSenior) && values[0] != operator.Subject {
+			writeError(w, http.StatusForbidden, "only senior verifiers can view other queues")
+			return
+		}
+		assignee = values[0]
+	}
+
+	list, err := s.views.PendingQueue(r.Context(), assignee, limit)
+	if err != nil {
+		writeView(w, list, err)
+		return
+	}
+	now := time.Now()
+	entries := make([]queueEntry, len(list.Entries))
+	for i, entry := range list.Entries {
+		entries[i] = queueEntry{Entry: entry, SLA: newSLATimer(entry.SLADueAt, now)}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"entries": entries, "total": list.Total, "asOf": list.AsOf})
+}
+
+// reviewState is the part of a record access and workflow checks need
+type reviewState struct {
+	Status     string    `json:"status"`
+	AssignedTo string    `json:"assignedTo"`
+	SLADueAt   string    `json:"slaDueAt"`
+	Escalation *struct{} `json:"escalation"`
+	// the first approval, when dual approval awaits a second
+	VerificationApproval *struct{} `json:"verificationApproval"`
+}
+
+func (s *Server) handleAdminKYC(w http.ResponseWriter, r *http.Request, operator *auth.Principal) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/admin/kyc/"), "/")
+	kycID := parts[0]
+	if kycID == "" || len(parts) > 2 {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	action := ""
+	if len(parts) == 2 {
+		action = parts[1]
+	}
+	if (action == "" && r.Method != http.MethodGet) || (action != "" && r.Method != http.MethodPost) {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	recordJSON, err := s.read(r.Context(), "ReadKYC", kycID, purposeReview)
+	if err != nil {
+		writeLedgerError(w, err)
+		return
+	}
+	var state reviewState
+	err = json.Unmarshal(recordJSON, &state)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "malformed chaincode response")
+		return
+	}
+	senior := operator.HasRole(RoleSenior)
+	if !senior && state.AssignedTo != operator.Subject {
+		writeError(w, http.StatusForbidden, "KYC record "+kycID+" is not in your queue")
+		return
+	}
+
+	switch action {
+	case "":
+		s.serveReview(w, r, kycID, recordJSON, &state)
+	case "decision":
+		s.decide(w, r, operator, kycID, &state, senior)
+	case "escalate":
+		s.escalate(w, r, operator, kycID)
+	case "assign":
+		if !senior {
+			writeError(w, http.StatusForbidden, "only senior verifiers can assign records")
+			return
+		}
+		s.assign(w, r, operator, kycID)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) serveReview(w http.ResponseWriter, r *http.Request, kycID string, recordJSON []byte, state *reviewState) {
+	history, err := s.evaluate(r.Context(), "GetKYCHistory", kycID)
+	if err != nil {
+		writeLedgerError(w, err)
+		return
+	}
+	if len(history) == 0 {
+		history = []byte("[]")
+	}
+	review := map[string]interface{}{
+		"record":  json.RawMessage(recordJSON),
+		"history": json.RawMessage(history),
+	}
+	if state.Status == "PENDING" {
+		if timer := newSLATimer(state.SLADueAt, time.Now()); timer != nil {
+			review["sla"] = timer
+		}
+	}
+	writeJSON(w, http.StatusOK, review)
+}
+
+func (s *Server) decide(w http.ResponseWriter, r *http.Request, operator *auth.Principal, kycID string, state *reviewState, senior bool) {
+	var request struct {
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	}
+	if !decodeAdminBody(w, r, &request) {
+		return
+	}
+	request.Reason = strings.TrimSpace(request.Reason)
+	switch {
+	case request.Status != "VERIFIED" && request.Status != "REJECTED":
+		writeError(w, http.StatusBadRequest, "status must be VERIFIED or REJECTED")
+		return
+	case request.Status == "REJECTED" && request.Reason == "":
+		writeError(w, http.StatusBadRequest, "a reason is required to reject a record")
+		return
+	case state.Status != "PENDING":
+		writeError(w, http.StatusConflict, "KYC record "+kycID+" is "+state.Status+"; only PENDING records can be decided")
+		return
+	case state.Escalation != nil && !senior:
+		writeError(w, http.StatusForbidden, "KYC record "+kycID+" is escalated; a senior verifier must decide it")
+		return
+	}
+	historyAction := request.Status
+	if s.signDecisions && request.Status == "VERIFIED" && state.VerificationApproval == nil {
+		// under dual approval the first verification is recorded as an approval
+		policyJSON, err := s.evaluate(r.Context(), "PolicyConfig:GetPolicy")
+		if err != nil {
+			writeLedgerError(w, err)
+			return
+		}
+		var policy struct {
+			DualApproval bool `json:"dualApproval"`
+		}
+		if json.Unmarshal(policyJSON, &policy) != nil {
+			writeError(w, http.StatusBadGateway, "malformed chaincode response")
+			return
+		}
+		if policy.DualApproval {
+			historyAction = "VERIFICATION_APPROVED"
+		}
+	}
+	s.submit(w, r, operator, "decided "+request.Status, kycID, historyAction, "UpdateKYCStatus", kycID, request.Status, operator.Subject, request.Reason)
+}
+
+func (s *Server) escalate(w http.ResponseWriter, r *http.Request, operator *auth.Principal, kycID string) {
+	var request struct {
+		Reason string `json:"reason"`
+	}
+	if !decodeAdminBody(w, r, &request) {
+		return
+	}
+	if strings.TrimSpace(request.Reason) == "" {
+		writeError(w, http.StatusBadRequest, "a reason is required to escalate a record")
+		return
+	}
+	s.submit(w, r, operator, "escalated", kycID, "ESCALATED", "EscalateKYC", kycID, request.Reason, operator.Subject)
+}
+
+func (s *Server) assign(w http.ResponseWriter, r *http.Request, operator *auth.Principal, kycID string) {
+	var request struct {
+		Assignee string `json:"assignee"`
+	}
+	if !decodeAdminBody(w, r, &request) {
+		return
+	}
+	s.submit(w, r, operator, "assigned", kycID, "ASSIGNED", "AssignKYC", kycID, request.Assignee)
+}
+
+// submit runs a workflow transaction for an operator and answers with its
+// transaction ID. historyAction is the action of the history entry it
+// writes, signed when SignDecisions was called.
+func (s *Server) submit(w http.ResponseWriter, r *http.Request, operator *auth.Principal, action string, kycID string, historyAction string, function string, args ...string) {
+	var txID string
+	var err error
+	if attesting, ok := s.submitter.(AttestingSubmitter); ok && s.signDecisions {
+		txID, _, err = attesting.SubmitAttested(r.Context(), fabric.Attestation{Action: historyAction, KYCID: kycID}, function, args...)
+	} else {
+		txID, _, err = s.submitter.Submit(r.Context(), function, args...)
+	}
+	if err != nil {
+		writeSubmitError(w, err)
+		return
+	}
+	log.Printf("operator %s %s KYC record %s in %s", operator.Subject, action, kycID, txID)
+	if s.cache != nil {
+		err = s.cache.Delete(r.Context(), liteCacheKey(kycID))
+		if err != nil {
+			log.Printf("cache delete failed for %s: %v", kycID, err)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"kycId": kycID, "txId": txID})
+}
+
+func decodeAdminBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAdminBodySize)).Decode(v)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return false
+	}
+	return true
+}
+
+// writeSubmitError maps a failed transaction to an HTTP error. Rejections by
+// the chaincode carry its message so the console can show it.
+func writeSubmitError(w http.ResponseWriter, err error) {
+	var commitErr *fabric.CommitError
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "does not exist"):
+		writeError(w, http.StatusNotFound, message)
+	case fabric.IsMVCCConflict(err):
+		writeError(w, http.StatusConflict, "the record changed while the request was processed; reload and retry")
+	case strings.HasPrefix(message, "failed to endorse"):
+		writeError(w, http.StatusUnprocessableEntity, message)
+	case errors.As(err, &commitErr):
+		log.Printf("transaction %s invalidated: %v", commitErr.TxID, err)
+		writeError(w, http.StatusConflict, message)
+	default:
+		log.Printf("chaincode call failed: %v", err)
+		writeError(w, http.StatusBadGateway, "ledger unavailable")
+	}
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"ekyc-gateway/auth"
+	"ekyc-gateway/vault"
+)
+
+const maxDocumentSize = 10 << 20
+
+// ServeDocuments serves document upload and download to operators holding
+// at least the verifier role:
+//
+//	POST /api/documents/{kycId}?id=&type=  the raw file as the body; stores it
+//	                                       in the vault, then records its hash
+//	GET  /api/documents/{kycId}/{docId}    the file the record's hash names
+//
+// Downloads are checked against the hash on the ledger, so a document
+// altered in the vault is refused rather than served.
+func (s *Server) ServeDocuments(documents vault.DocumentVault, submitter Submitter, operators *auth.Verifier) {
+	s.documents = documents
+	s.submitter = submitter
+	s.operators = operators
+	s.mux.HandleFunc("/api/documents/", s.authenticated(s.handleDocuments))
+}
+
+// documentState is the part of a record a download needs
+type documentState struct {
+	DocumentHashes []struct {
+		ID   string `json:"id"`
+		Hash string `json:"hash"`
+	} `json:"documentHashes"`
+}
+
+func (s *Server) handleDocuments(w http.ResponseWriter, r *http.Request, operator *auth.Principal) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/documents/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] != "" && r.Method == http.MethodPost:
+		s.uploadDocument(w, r, operator, parts[0])
+	case len(parts) == 2 && parts[0] != "" && parts[1] != "" && r.Method == http.MethodGet:
+		s.downloadDocument(w, r, parts[0], parts[1])
+	case len(parts) == 1 || len(parts) == 2:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) uploadDocument(w http.ResponseWriter, r *http.Request, operator *auth.Principal, kycID string) {
+	documentID, documentType := r.URL.Query().Get("id"), r.URL.Query().Get("type")
+	if documentID == "" || documentType == "" {
+		writeError(w, http.StatusBadRequest, "id and type are required")
+		return
+	}
+	content, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxDocumentSize))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, "documents are limited to 10 MiB")
+		return
+	}
+	if len(content) == 0 {
+		writeError(w, http.StatusBadRequest, "the document is empty")
+		return
+	}
+
+	document, txID, err := vault.Upload(r.Context(), s.documents, s.submitter, kycID, documentID, documentType, content)
+	if err != nil {
+		var storageErr *vault.StorageError
+		if errors.As(err, &storageErr) {
+			log.Printf("document upload for %s failed: %v", kycID, err)
+			writeError(w, http.StatusBadGateway, "document storage unavailable")
+			return
+		}
+		writeSubmitError(w, err)
+		return
+	}
+	log.Printf("operator %s uploaded document %s of KYC record %s in %s", operator.Subject, documentID, kycID, txID)
+	if s.cache != nil {
+		err = s.cache.Delete(r.Context(), liteCacheKey(kycID))
+		if err != nil {
+			log.Printf("cache delete failed for %s: %v", kycID, err)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"kycId": kycID, "document": document, "txId": txID})
+}
+
+func (s *Server) downloadDocument(w http.ResponseWriter, r *http.Request, kycID string, documentID string) {
+	recordJSON, err := s.read(r.Context(), "ReadKYC", kycID, purposeDocuments)
+	if err != nil {
+		writeLedgerError(w, err)
+		return
+	}
+	var state documentState
+	err = json.Unmarshal(recordJSON, &state)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "malformed chaincode response")
+		return
+	}
+	hash := ""
+	for _, document := range state.DocumentHashes {
+		if document.ID == documentID {
+			hash = document.Hash
+		}
+	}
+	if hash == "" {
+		writeError(w, http.StatusNotFound, "KYC record "+kycID+" has no document "+documentID)
+		return
+	}
+
+	content, err := vault.Get(r.Context(), s.documents, hash)
+	switch {
+	case err == vault.ErrNotFound:
+		writeError(w, http.StatusNotFound, "document "+documentID+" is not in the vault")
+		return
+	case err != nil:
+		log.Printf("document download for %s failed: %v", kycID, err)
+		writeError(w, http.StatusBadGateway, "document storage unavailable")
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+hash+`"`)
+	w.Header().Set("X-Document-Hash", hash)
+	w.Write(content)
+}
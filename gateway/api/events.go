@@ -0,0 +1,210 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"ekyc-gateway/auth"
+	"ekyc-gateway/fabric"
+	"ekyc-gateway/websocket"
+)
+
+const (
+	// subscriberBuffer is how many events a subscriber may fall behind
+	// before it is disconnected
+	subscriberBuffer = 64
+	pingInterval     = 30 * time.Second
+)
+
+// pushedEvent is what a WebSocket subscriber receives for a chaincode event.
+// Only these fields are forwarded: a record subscription is granted to anyone
+// who knows its ID, so the rest of the payload stays on the ledger.
+type pushedEvent struct {
+	Event       string `json:"event"`
+	KYCID       string `json:"kycId,omitempty"`
+	Org         string `json:"org,omitempty"`
+	Status      string `json:"status,omitempty"`
+	BlockNumber uint64 `json:"blockNumber"`
+	TxID        string `json:"txId"`
+}
+
+// eventHub fans chaincode events out to WebSocket subscribers
+type eventHub struct {
+	checkOrigin func(origin string) bool
+
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+// subscriber is one WebSocket client and the events it asked for
+type subscriber struct {
+	kycID string
+	org   string
+	send  chan []byte
+}
+
+func (sub *subscriber) wants(event *pushedEvent) bool {
+	return (sub.kycID == "" || sub.kycID == event.KYCID) && (sub.org == "" || sub.org == event.Org)
+}
+
+// ServeEvents pushes chaincode events to browsers at /ws?kycId=ID or
+// /ws?org=MSP, so status screens update without polling; with both, an event
+// must match both. Events reach subscribers through HandleEvent. A non-empty
+// origins list restricts which pages may connect.
+//
+// Anyone knowing a record ID may follow it, but following an organisation
+// needs the bearer token of one of its partners, or of an administrator, as
+// for webhooks. Browsers cannot set headers on a WebSocket, so the token may
+// instead be passed as the access_token parameter. Without subscribers to
+// check tokens, only records can be followed.
+func (s *Server) ServeEvents(origins []string, subscribers *auth.Verifier) {
+	s.events = &eventHub{subscribers: map[*subscriber]struct{}{}}
+	if subscribers != nil {
+		s.operators = subscribers
+	}
+	if len(origins) > 0 {
+		s.events.checkOrigin = func(origin string) bool {
+			for _, allowed := range origins {
+				if strings.EqualFold(origin, allowed) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	s.mux.HandleFunc("/ws", s.handleWebSocket)
+}
+
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	sub := &subscriber{
+		kycID: r.URL.Query().Get("kycId"),
+		org:   r.URL.Query().Get("org"),
+		send:  make(chan []byte, subscriberBuffer),
+	}
+	if sub.kycID == "" && sub.org == "" {
+		writeError(w, http.StatusBadRequest, "kycId or org is required")
+		return
+	}
+	if sub.org != "" {
+		authenticated := r
+		if token := r.URL.Query().Get("access_token"); token != "" && r.Header.Get("Authorization") == "" {
+			authenticated = r.Clone(r.Context())
+			authenticated.Header.Set("Authorization", "Bearer "+token)
+		}
+		subscriber, ok := s.authenticateSubscriber(w, authenticated)
+		if !ok {
+			return
+		}
+		if !subscriber.HasRole(RoleAdmin) && subscriber.Org != sub.org {
+			writeError(w, http.StatusForbidden, "partners may only subscribe to their own organisation's events")
+			return
+		}
+	}
+
+	conn, err := websocket.Upgrade(w, r, s.events.checkOrigin)
+	if err != nil {
+		log.Printf("websocket upgrade failed: %v", err)
+		return
+	}
+	s.events.add(sub)
+	defer s.events.remove(sub)
+
+	done := make(chan struct{})
+	go func() {
+		conn.Read()
+		close(done)
+	}()
+
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case message, ok := <-sub.send:
+			if !ok {
+				conn.Close(websocket.ClosePolicyViolation, "subscriber fell too far behind")
+				return
+			}
+			err = conn.WriteText(message)
+		case <-ping.C:
+			err = conn.Ping()
+		case <-done:
+			return
+		}
+		if err != nil {
+			conn.Close(websocket.CloseGoingAway, "")
+			return
+		}
+	}
+}
+
+func (h *eventHub) add(sub *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subscribers[sub] = struct{}{}
+}
+
+func (h *eventHub) remove(sub *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[sub]; ok {
+		delete(h.subscribers, sub)
+		close(sub.send)
+	}
+}
+
+// publish queues an event for every subscriber that wants it. It never
+// blocks: a subscriber whose buffer is full is dropped and must reconnect.
+func (h *eventHub) publish(event *pushedEvent) {
+	message, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subscribers {
+		if !sub.wants(event) {
+			continue
+		}
+		select {
+		case sub.send <- message:
+		default:
+			delete(h.subscribers, sub)
+			close(sub.send)
+		}
+	}
+}
+
+// HandleEvent applies a chaincode event to the server: it evicts the cached
+// results the event makes stale, then pushes it to WebSocket subscribers.
+// Use it as the fabric.Listen handler.
+func (s *Server) HandleEvent(ctx context.Context, event *fabric.Event) error {
+	err := s.Invalidate(ctx, event)
+	if err != nil {
+		return err
+	}
+	if s.events == nil {
+		return nil
+	}
+
+	var payload struct {
+		KYCID    string `json:"kycId"`
+		OwnerMSP string `json:"ownerMsp"`
+		Status   string `json:"status"`
+	}
+	json.Unmarshal(event.Payload, &payload)
+	s.events.publish(&pushedEvent{
+		Event:       event.Name,
+		KYCID:       payload.KYCID,
+		Org:         payload.OwnerMSP,
+		Status:      payload.Status,
+		BlockNumber: event.BlockNumber,
+		TxID:        event.TxID,
+	})
+	return nil
+}
@@ -0,0 +1,370 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"ekyc-gateway/auth"
+	"ekyc-gateway/fabric"
+	"ekyc-gateway/graphql"
+)
+
+const (
+	// maxGraphQLReads caps the chaincode evaluations one GraphQL request may
+	// cause, since nested fields such as a page of records with their
+	// histories fan out into one evaluation each
+	maxGraphQLReads = 50
+
+	maxGraphQLBody = 1 << 20
+)
+
+// liteFields are the record fields GetKYCLite returns. A kyc query selecting
+// nothing else is answered from the lite view, so the customer's personal
+// details are not read at all.
+var liteFields = map[string]bool{
+	"id": true, "entityType": true, "status": true, "verificationLevel": true,
+	"riskTier": true, "verifiedAt": true, "expiresAt": true, "updatedAt": true, "__typename": true,
+}
+
+// handleGraphQL serves GraphQL queries posted as JSON or sent as GET
+// parameters. Anonymous callers may only select the lite fields of a kyc
+// query; personal details, histories, status pages and stats need the bearer
+// token of an operator holding at least the verifier role, accepted once the
+// back-office API or document vault is enabled.
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var request graphql.Request
+	switch r.Method {
+	case http.MethodGet:
+		request.Query = r.URL.Query().Get("query")
+		request.OperationName = r.URL.Query().Get("operationName")
+		if variables := r.URL.Query().Get("variables"); variables != "" {
+			err := json.Unmarshal([]byte(variables), &request.Variables)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "variables must be a JSON object")
+				return
+			}
+		}
+	case http.MethodPost:
+		err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGraphQLBody)).Decode(&request)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "request body must be a JSON GraphQL request")
+			return
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if request.Query == "" {
+		writeError(w, http.StatusBadRequest, "query is required")
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), readBudgetKey{}, &readBudget{remaining: maxGraphQLReads})
+	if r.Header.Get("Authorization") != "" {
+		operator, ok := s.authenticateOperator(w, r)
+		if !ok {
+			return
+		}
+		// the chaincode logs the gateway identity's reads as the operator's
+		ctx = context.WithValue(fabric.WithOperator(ctx, operator.Subject), graphQLOperatorKey{}, operator)
+	}
+	response := s.graphql.Execute(ctx, request)
+	status := http.StatusOK
+	if response.Data == nil {
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, response)
+}
+
+type readBudgetKey struct{}
+
+type graphQLOperatorKey struct{}
+
+// requireGraphQLOperator fails a resolver unless the GraphQL request it
+// serves was made by an authenticated operator
+func requireGraphQLOperator(ctx context.Context, selection string) error {
+	if _, ok := ctx.Value(graphQLOperatorKey{}).(*auth.Principal); !ok {
+		return fmt.Errorf("%s needs an operator bearer token", selection)
+	}
+	return nil
+}
+
+// errReadBudget fails the reads of a GraphQL request past maxGraphQLReads
+var errReadBudget = fmt.Errorf("query needs more than %d ledger reads; select fewer nested fields", maxGraphQLReads)
+
+// readBudget counts the chaincode reads left to a GraphQL request
+type readBudget struct {
+	remaining int
+}
+
+// evaluate evaluates a chaincode query, charging it to the read budget of the
+// GraphQL request it serves, if any
+func (s *Server) evaluate(ctx context.Context, function string, args ...string) ([]byte, error) {
+	err := chargeRead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.ledger.Evaluate(ctx, function, args...)
+}
+
+// read submits a record read, so the chaincode commits its access log and
+// counts it against the grant it is made under, charging it to the read
+// budget like evaluate
+func (s *Server) read(ctx context.Context, function string, args ...string) ([]byte, error) {
+	err := chargeRead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, result, err := s.ledger.Submit(ctx, function, args...)
+	return result, err
+}
+
+// chargeRead takes one read from the read budget of the GraphQL request ctx
+// serves, failing once it is spent
+func chargeRead(ctx context.Context) error {
+	if budget, ok := ctx.Value(readBudgetKey{}).(*readBudget); ok {
+		if budget.remaining == 0 {
+			return errReadBudget
+		}
+		budget.remaining--
+	}
+	return nil
+}
+
+// decodeResult decodes the result of a chaincode query. A record
+// that does not exist resolves to null rather than an error.
+func decodeResult(result []byte, err error) (interface{}, error) {
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			return nil, nil
+		}
+		if err == errReadBudget {
+			return nil, err
+		}
+		return nil, ledgerError(err)
+	}
+	var decoded interface{}
+	err = json.Unmarshal(result, &decoded)
+	if err != nil {
+		return nil, fmt.Errorf("malformed chaincode response")
+	}
+	return decoded, nil
+}
+
+// ledgerError hides chaincode failure detail from clients, as writeLedgerError does
+func ledgerError(err error) error {
+	log.Printf("chaincode call failed: %v", err)
+	return fmt.Errorf("ledger unavailable")
+}
+
+// newGraphQLSchema builds the GraphQL schema, resolving against the server's ledger
+func (s *Server) newGraphQLSchema() *graphql.Schema {
+	nonNull := func(t graphql.Type) graphql.Type { return &graphql.NonNull{Of: t} }
+	listOf := func(t graphql.Type) graphql.Type {
+		return &graphql.NonNull{Of: &graphql.List{Of: &graphql.NonNull{Of: t}}}
+	}
+	str := func(name string, description string) *graphql.Field {
+		return &graphql.Field{Name: name, Type: graphql.String, Description: description}
+	}
+
+	jsonScalar := &graphql.Scalar{
+		Name:        "JSON",
+		Description: "An arbitrary JSON value.",
+		Serialize:   func(value interface{}) (interface{}, bool) { return value, true },
+		ParseValue:  func(value interface{}) (interface{}, bool) { return value, true },
+	}
+	status := &graphql.Enum{Name: "KYCStatus", Values: []string{"PENDING", "ON_HOLD", "VERIFIED", "REJECTED", "EXPIRED", "BLOCKED"}}
+
+	address := &graphql.Object{Name: "Address", Fields: []*graphql.Field{
+		str("street", ""), str("city", ""), str("state", ""), str("pincode", ""),
+		str("country", "ISO 3166-1 alpha-2 code"),
+	}}
+	documentHash := &graphql.Object{Name: "DocumentHash", Fields: []*graphql.Field{
+		{Name: "id", Type: nonNull(graphql.ID)},
+		str("type", "PAN, AADHAAR, PASSPORT, etc."), str("hash", ""), str("ipfsHash", ""), str("uploadedAt", ""),
+	}}
+	consent := &graphql.Object{Name: "Consent", Description: "A consent the record owner has given.", Fields: []*graphql.Field{
+		{Name: "purpose", Type: nonNull(graphql.String), Description: "what the consent covers; NOMINEE for the nominee declaration"},
+		str("consentRef", "reference to the owner's signed declaration"), str("consentedAt", ""), str("recordedBy", ""),
+	}}
+	nominee := &graphql.Object{Name: "Nominee", Fields: []*graphql.Field{
+		str("nameHash", ""), str("relationship", "SPOUSE, CHILD, PARENT, SIBLING or OTHER"), str("nomineeKycId", ""),
+		{Name: "consent", Type: consent, Resolve: func(ctx context.Context, p graphql.Params) (interface{}, error) {
+			return nomineeConsent(p.Source), nil
+		}},
+		str("updatedAt", ""),
+	}}
+	historyEntry := &graphql.Object{Name: "HistoryEntry", Description: "An audit trail entry.", Fields: []*graphql.Field{
+		{Name: "id", Type: nonNull(graphql.ID)},
+		{Name: "kycId", Type: nonNull(graphql.ID)},
+		str("action", "CREATED, UPDATED, VERIFIED, REJECTED, RESUBMITTED, ..."),
+		str("performedBy", ""), str("performedAt", ""), str("txId", ""), str("remarks", ""),
+		{Name: "details", Type: jsonScalar},
+	}}
+
+	history := func(ctx context.Context, kycID string) (interface{}, error) {
+		result, err := s.evaluate(ctx, "GetKYCHistory", kycID)
+		entries, err := decodeResult(result, err)
+		if entries == nil && err == nil {
+			return []interface{}{}, nil
+		}
+		return entries, err
+	}
+
+	record := &graphql.Object{Name: "KYCRecord", Description: "A KYC record. Select only the fields you need: a query for status fields alone never reads personal details.", Fields: []*graphql.Field{
+		{Name: "id", Type: nonNull(graphql.ID)},
+		str("userId", ""), str("ownerMsp", "MSP of the organisation that submitted the record"),
+		str("entityType", "INDIVIDUAL, COMPANY, TRUST, PARTNERSHIP or HUF"),
+		str("name", ""), str("email", ""), str("phone", ""), str("pan", ""), str("dateOfBirth", ""),
+		{Name: "address", Type: address},
+		{Name: "status", Type: nonNull(status)},
+		str("verificationLevel", "L1, L2 or L3"),
+		str("createdAt", ""), str("updatedAt", ""), str("verifiedAt", ""), str("verifiedBy", ""), str("remarks", ""),
+		str("riskTier", "LOW, MEDIUM or HIGH"),
+		{Name: "riskScore", Type: graphql.Int},
+		str("slaDueAt", "verification decision due by"),
+		str("assignedTo", "verifier whose review queue holds the record"),
+		str("expiresAt", "re-KYC due by"),
+		{Name: "tags", Type: listOf(graphql.String), Resolve: func(ctx context.Context, p graphql.Params) (interface{}, error) {
+			return listField(p.Source, "tags"), nil
+		}},
+		{Name: "documentHashes", Type: listOf(documentHash), Resolve: func(ctx context.Context, p graphql.Params) (interface{}, error) {
+			return listField(p.Source, "documentHashes"), nil
+		}},
+		{Name: "nominee", Type: nominee},
+		{Name: "consents", Type: listOf(consent), Description: "consents recorded against the record", Resolve: func(ctx context.Context, p graphql.Params) (interface{}, error) {
+			consents := []interface{}{}
+			if nominee, ok := p.Source.(map[string]interface{})["nominee"]; ok {
+				if consent := nomineeConsent(nominee); consent != nil {
+					consents = append(consents, consent)
+				}
+			}
+			return consents, nil
+		}},
+		{Name: "history", Type: listOf(historyEntry), Description: "the record's audit trail; costs one ledger read per record", Resolve: func(ctx context.Context, p graphql.Params) (interface{}, error) {
+			return history(ctx, fmt.Sprint(p.Source.(map[string]interface{})["id"]))
+		}},
+	}}
+
+	page := &graphql.Object{Name: "KYCPage", Fields: []*graphql.Field{
+		{Name: "records", Type: listOf(record)},
+		{Name: "bookmark", Type: graphql.String, Description: "pass as after to fetch the next page; empty on the last page"},
+		{Name: "fetchedRecordsCount", Type: nonNull(graphql.Int)},
+		{Name: "truncated", Type: nonNull(graphql.Boolean), Description: "the page was cut short by the chaincode's response size limit"},
+	}}
+	statusCount := &graphql.Object{Name: "StatusCount", Fields: []*graphql.Field{
+		{Name: "status", Type: nonNull(graphql.String)},
+		{Name: "count", Type: nonNull(graphql.Int)},
+	}}
+	nomineeStats := &graphql.Object{Name: "NomineeStats", Fields: []*graphql.Field{
+		{Name: "complete", Type: nonNull(graphql.Int)},
+		{Name: "partial", Type: nonNull(graphql.Int)},
+		{Name: "missing", Type: nonNull(graphql.Int)},
+	}}
+	stats := &graphql.Object{Name: "ComplianceStats", Fields: []*graphql.Field{
+		{Name: "totalRecords", Type: nonNull(graphql.Int)},
+		{Name: "byStatus", Type: listOf(statusCount), Resolve: func(ctx context.Context, p graphql.Params) (interface{}, error) {
+			counts, _ := p.Source.(map[string]interface{})["byStatus"].(map[string]interface{})
+			statuses := make([]string, 0, len(counts))
+			for status := range counts {
+				statuses = append(statuses, status)
+			}
+			sort.Strings(statuses)
+			byStatus := make([]interface{}, len(statuses))
+			for i, status := range statuses {
+				byStatus[i] = map[string]interface{}{"status": status, "count": counts[status]}
+			}
+			return byStatus, nil
+		}},
+		{Name: "nominee", Type: nonNull(nomineeStats)},
+	}}
+
+	query := &graphql.Object{Name: "Query", Fields: []*graphql.Field{
+		{Name: "kyc", Type: record, Description: "a KYC record by ID, or null if there is none; fields beyond the lite view need an operator token",
+			Args: []*graphql.Argument{{Name: "id", Type: nonNull(graphql.ID)}},
+			Resolve: func(ctx context.Context, p graphql.Params) (interface{}, error) {
+				kycID := p.Args["id"].(string)
+				for _, name := range p.Selected {
+					if !liteFields[name] {
+						err := requireGraphQLOperator(ctx, "selecting "+name)
+						if err != nil {
+							return nil, err
+						}
+						result, err := s.read(ctx, "ReadKYC", kycID, purposeQuery)
+						return decodeResult(result, err)
+					}
+				}
+				result, err := s.evaluateCached(ctx, liteCacheKey(kycID), "GetKYCLite", kycID)
+				return decodeResult(result, err)
+			}},
+		{Name: "kycByStatus", Type: nonNull(page), Description: "a page of records with the given status",
+			Args: []*graphql.Argument{
+				{Name: "status", Type: nonNull(status)},
+				{Name: "first", Type: graphql.Int, Default: 20, Description: "page size, at most 200"},
+				{Name: "after", Type: graphql.String, Default: "", Description: "bookmark of the previous page"},
+			},
+			Resolve: func(ctx context.Context, p graphql.Params) (interface{}, error) {
+				err := requireGraphQLOperator(ctx, "kycByStatus")
+				if err != nil {
+					return nil, err
+				}
+				first, _ := p.Args["first"].(int)
+				if first < 1 || first > 200 {
+					return nil, fmt.Errorf("first must be between 1 and 200")
+				}
+				after, _ := p.Args["after"].(string)
+				result, err := s.read(ctx, "GetKYCByStatusWithPagination", p.Args["status"].(string), fmt.Sprint(first), after, purposeQuery)
+				return decodeResult(result, err)
+			}},
+		{Name: "history", Type: listOf(historyEntry), Description: "the audit trail of a record",
+			Args: []*graphql.Argument{{Name: "kycId", Type: nonNull(graphql.ID)}},
+			Resolve: func(ctx context.Context, p graphql.Params) (interface{}, error) {
+				err := requireGraphQLOperator(ctx, "history")
+				if err != nil {
+					return nil, err
+				}
+				return history(ctx, p.Args["kycId"].(string))
+			}},
+		{Name: "stats", Type: nonNull(stats), Description: "record counts by status and nominee completeness",
+			Resolve: func(ctx context.Context, p graphql.Params) (interface{}, error) {
+				err := requireGraphQLOperator(ctx, "stats")
+				if err != nil {
+					return nil, err
+				}
+				result, err := s.evaluate(ctx, "GetComplianceStats")
+				return decodeResult(result, err)
+			}},
+	}}
+
+	return &graphql.Schema{Query: query, MaxDepth: 16}
+}
+
+// nomineeConsent returns the consent recorded with a nominee, labelled with its purpose
+func nomineeConsent(nominee interface{}) interface{} {
+	fields, ok := nominee.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	consent, ok := fields["consent"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	labelled := map[string]interface{}{"purpose": "NOMINEE"}
+	for key, value := range consent {
+		labelled[key] = value
+	}
+	return labelled
+}
+
+// listField returns a list-valued field of a decoded record, empty when omitted
+func listField(source interface{}, name string) interface{} {
+	if list, ok := source.(map[string]interface{})[name].([]interface{}); ok {
+		return list
+	}
+	return []interface{}{}
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+
+	"ekyc-gateway/fabric"
+)
+
+// recordEvent is the part of a chaincode event payload naming the records it
+// changed: one record, or several for a delegate's AccessRevoked
+type recordEvent struct {
+	KYCID  string   `json:"kycId"`
+	KYCIDs []string `json:"kycIds"`
+}
+
+// Invalidate evicts the cached results a chaincode event makes stale. Events
+// that do not name a record are ignored; entries they leave stale expire with
+// the cache TTL.
+func (s *Server) Invalidate(ctx context.Context, event *fabric.Event) error {
+	if s.cache == nil {
+		return nil
+	}
+
+	var record recordEvent
+	if json.Unmarshal(event.Payload, &record) != nil {
+		return nil
+	}
+	if record.KYCID != "" {
+		record.KYCIDs = append(record.KYCIDs, record.KYCID)
+	}
+	for _, kycID := range record.KYCIDs {
+		err := s.cache.Delete(ctx, liteCacheKey(kycID))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+// Package api is the gateway's REST interface to the eKYC chaincode.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"ekyc-gateway/auth"
+	"ekyc-gateway/cache"
+	"ekyc-gateway/graphql"
+	"ekyc-gateway/readmodel"
+	"ekyc-gateway/vault"
+	"ekyc-gateway/webhook"
+)
+
+// Ledger evaluates chaincode query functions, and submits the record reads
+// the chaincode logs
+type Ledger interface {
+	Evaluate(ctx context.Context, function string, args ...string) ([]byte, error)
+	Submit(ctx context.Context, function string, args ...string) (string, []byte, error)
+}
+
+// Purpose codes the gateway reads records for. The chaincode logs reads of
+// other organisations' records under their purpose, and only lets them
+// through under an access grant for it.
+const (
+	purposeReview    = "KYC_REVIEW"        // an operator's review of a record in their queue
+	purposeDocuments = "DOCUMENT_DOWNLOAD" // an operator's download of a record's document
+	purposeQuery     = "OPERATOR_QUERY"    // an operator's GraphQL query
+)
+
+// Server routes gateway requests to the chaincode
+type Server struct {
+	ledger        Ledger
+	cache         cache.Store // nil when caching is disabled
+	cacheTTL      time.Duration
+	views         *readmodel.Views // nil unless ServeReadModel was called
+	events        *eventHub        // nil unless ServeEvents was called
+	webhooks      *webhook.Store   // nil unless ServeWebhooks was called
+	submitter     Submitter        // nil unless ServeAdmin was called
+	operators     *auth.Verifier
+	documents     vault.DocumentVault // nil unless ServeDocuments was called
+	signDecisions bool
+	graphql       *graphql.Schema
+	mux           *http.ServeMux
+}
+
+// NewServer returns a gateway API. A nil store disables caching.
+func NewServer(ledger Ledger, store cache.Store, cacheTTL time.Duration) *Server {
+	s := &Server{ledger: ledger, cache: store, cacheTTL: cacheTTL, mux: http.NewServeMux()}
+	s.graphql = s.newGraphQLSchema()
+	s.mux.HandleFunc("/api/kyc/", s.handleKYC)
+	s.mux.HandleFunc("/graphql", s.handleGraphQL)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleKYC serves /api/kyc/{id}/lite and /api/kyc/{id}/status
+func (s *Server) handleKYC(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/kyc/"), "/")
+	if len(parts) != 2 || parts[0] == "" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	kycID := parts[0]
+	switch parts[1] {
+	case "lite":
+		s.serveCached(w, r, liteCacheKey(kycID), "GetKYCLite", kycID)
+	case "status":
+		s.serveStatus(w, r, kycID)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// serveStatus answers status lookups from the cached lite record
+func (s *Server) serveStatus(w http.ResponseWriter, r *http.Request, kycID string) {
+	lite, err := s.evaluateCached(r.Context(), liteCacheKey(kycID), "GetKYCLite", kycID)
+	if err != nil {
+		writeLedgerError(w, err)
+		return
+	}
+
+	var status struct {
+		ID                string `json:"id"`
+		Status            string `json:"status"`
+		VerificationLevel string `json:"verificationLevel"`
+		ExpiresAt         string `json:"expiresAt,omitempty"`
+	}
+	err = json.Unmarshal(lite, &status)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "malformed chaincode response")
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) serveCached(w http.ResponseWriter, r *http.Request, key string, function string, args ...string) {
+	result, err := s.evaluateCached(r.Context(), key, function, args...)
+	if err != nil {
+		writeLedgerError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(result)
+}
+
+// evaluateCached returns a cached query result, evaluating the query on a miss.
+// Cache failures fall back to the ledger rather than failing the request.
+func (s *Server) evaluateCached(ctx context.Context, key string, function string, args ...string) ([]byte, error) {
+	if s.cache != nil {
+		cached, ok, err := s.cache.Get(ctx, key)
+		if err != nil {
+			log.Printf("cache read failed for %s: %v", key, err)
+		} else if ok {
+			return cached, nil
+		}
+	}
+
+	result, err := s.evaluate(ctx, function, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cache != nil {
+		err = s.cache.Set(ctx, key, result, s.cacheTTL)
+		if err != nil {
+			log.Printf("cache write failed for %s: %v", key, err)
+		}
+	}
+	return result, nil
+}
+
+func liteCacheKey(kycID string) string {
+	return "kyclite:" + kycID
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// writeLedgerError maps a failed chaincode call to an HTTP error
+func writeLedgerError(w http.ResponseWriter, err error) {
+	if strings.Contains(err.Error(), "does not exist") {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	log.Printf("chaincode call failed: %v", err)
+	writeError(w, http.StatusBadGateway, "ledger unavailable")
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"ekyc-gateway/readmodel"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// ServeReadModel answers list queries from the projector's views instead of
+// the peers: /api/queue?assignee=V (without assignee for unassigned records),
+// /api/expiring?days=N and /api/stats. Responses carry the views' asOf time;
+// see package readmodel for how stale they may be.
+func (s *Server) ServeReadModel(views *readmodel.Views) {
+	s.views = views
+	s.mux.HandleFunc("/api/queue", s.handleQueue)
+	s.mux.HandleFunc("/api/expiring", s.handleExpiring)
+	s.mux.HandleFunc("/api/stats", s.handleStats)
+}
+
+func (s *Server) handleQueue(w http.ResponseWriter, r *http.Request) {
+	limit, ok := listLimit(w, r)
+	if !ok {
+		return
+	}
+	list, err := s.views.PendingQueue(r.Context(), r.URL.Query().Get("assignee"), limit)
+	writeView(w, list, err)
+}
+
+func (s *Server) handleExpiring(w http.ResponseWriter, r *http.Request) {
+	limit, ok := listLimit(w, r)
+	if !ok {
+		return
+	}
+	days := 30
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			writeError(w, http.StatusBadRequest, "days must be a non-negative integer")
+			return
+		}
+		days = parsed
+	}
+	list, err := s.views.ExpiringSoon(r.Context(), time.Duration(days)*24*time.Hour, limit)
+	writeView(w, list, err)
+}
+
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	stats, err := s.views.Stats(r.Context())
+	writeView(w, stats, err)
+}
+
+// listLimit reads the limit query parameter of a list request
+func listLimit(w http.ResponseWriter, r *http.Request) (int, bool) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return 0, false
+	}
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return defaultListLimit, true
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxListLimit {
+		writeError(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxListLimit))
+		return 0, false
+	}
+	return limit, true
+}
+
+// writeView writes a read model result; the views are down when Redis is
+func writeView(w http.ResponseWriter, body interface{}, err error) {
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, "read model unavailable")
+		return
+	}
+	writeJSON(w, http.StatusOK, body)
+}
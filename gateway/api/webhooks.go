@@ -0,0 +1,214 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"ekyc-gateway/auth"
+	"ekyc-gateway/webhook"
+)
+
+const (
+	minWebhookSecret   = 16
+	defaultLogLimit    = 50
+	maxLogLimit        = 1000
+	maxWebhookBodySize = 64 << 10
+)
+
+// Subscriber roles, carried in the roles claim of a partner's or
+// administrator's token
+const (
+	RolePartner = "kyc.partner"
+	RoleAdmin   = "kyc.admin"
+)
+
+// ServeWebhooks manages partner webhook subscriptions:
+//
+//	POST   /api/webhooks                    register {url, secret, events, org}
+//	GET    /api/webhooks                    list subscriptions
+//	GET    /api/webhooks/{id}               one subscription
+//	DELETE /api/webhooks/{id}               unregister
+//	GET    /api/webhooks/{id}/deliveries    recent deliveries, newest first
+//
+// Callers present a bearer token holding the partner or admin role. A
+// partner's token names its MSP in the org claim, and the partner only
+// registers and sees subscriptions to its own organisation's events; a
+// subscription of another organisation is not found. Administrators manage
+// every subscription. Secrets are write-only: responses never include them.
+func (s *Server) ServeWebhooks(store *webhook.Store, subscribers *auth.Verifier) {
+	s.webhooks = store
+	s.operators = subscribers
+	s.mux.HandleFunc("/api/webhooks", s.subscriberAuthenticated(s.handleWebhooks))
+	s.mux.HandleFunc("/api/webhooks/", s.subscriberAuthenticated(s.handleWebhook))
+}
+
+// subscriberAuthenticated admits administrators, and partners whose token
+// names their organisation
+func (s *Server) subscriberAuthenticated(handle operatorHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		subscriber, ok := s.authenticateSubscriber(w, r)
+		if !ok {
+			return
+		}
+		handle(w, r, subscriber)
+	}
+}
+
+// authenticateSubscriber returns the administrator, or partner naming its
+// organisation, presenting the request's bearer token, and otherwise writes
+// the failure and returns false
+func (s *Server) authenticateSubscriber(w http.ResponseWriter, r *http.Request) (*auth.Principal, bool) {
+	var subscriber *auth.Principal
+	err := auth.ErrUnauthenticated
+	if s.operators != nil {
+		subscriber, err = s.operators.Authenticate(r)
+	}
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="ekyc-partners"`)
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return nil, false
+	}
+	if !subscriber.HasRole(RoleAdmin) && (!subscriber.HasRole(RolePartner) || subscriber.Org == "") {
+		writeError(w, http.StatusForbidden, "a partner token naming its org, or the admin role, is required")
+		return nil, false
+	}
+	return subscriber, true
+}
+
+// canManage reports whether a subscriber may see and change a subscription
+func canManage(subscriber *auth.Principal, sub *webhook.Subscription) bool {
+	return subscriber.HasRole(RoleAdmin) || sub.Org == subscriber.Org
+}
+
+func (s *Server) handleWebhooks(w http.ResponseWriter, r *http.Request, subscriber *auth.Principal) {
+	switch r.Method {
+	case http.MethodGet:
+		subs, err := s.webhooks.List(r.Context())
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		visible := []*webhook.Subscription{}
+		for _, sub := range subs {
+			if canManage(subscriber, sub) {
+				sub.Secret = ""
+				visible = append(visible, sub)
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"subscriptions": visible})
+	case http.MethodPost:
+		var sub webhook.Subscription
+		err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxWebhookBodySize)).Decode(&sub)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON body")
+			return
+		}
+		if message := validateSubscription(&sub); message != "" {
+			writeError(w, http.StatusBadRequest, message)
+			return
+		}
+		if !subscriber.HasRole(RoleAdmin) {
+			if sub.Org != "" && sub.Org != subscriber.Org {
+				writeError(w, http.StatusForbidden, "partners may only subscribe to their own organisation's events")
+				return
+			}
+			sub.Org = subscriber.Org
+		}
+		err = s.webhooks.Create(r.Context(), &sub)
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		sub.Secret = ""
+		writeJSON(w, http.StatusCreated, sub)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request, subscriber *auth.Principal) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/webhooks/"), "/")
+	id := parts[0]
+	if id == "" || len(parts) > 2 || (len(parts) == 2 && parts[1] != "deliveries") {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodGet && !(len(parts) == 1 && r.Method == http.MethodDelete) {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	sub, err := s.webhooks.Get(r.Context(), id)
+	if err == nil && !canManage(subscriber, sub) {
+		// other organisations' subscriptions are not acknowledged
+		err = webhook.ErrNotFound
+	}
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	switch {
+	case len(parts) == 2:
+		s.serveDeliveries(w, r, id)
+	case r.Method == http.MethodGet:
+		sub.Secret = ""
+		writeJSON(w, http.StatusOK, sub)
+	default:
+		err := s.webhooks.Delete(r.Context(), id)
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) serveDeliveries(w http.ResponseWriter, r *http.Request, id string) {
+	limit := defaultLogLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxLogLimit {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxLogLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	deliveries, err := s.webhooks.Deliveries(r.Context(), id, limit)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"deliveries": deliveries})
+}
+
+// validateSubscription checks a registration and returns what is wrong with
+// it, or an empty string
+func validateSubscription(sub *webhook.Subscription) string {
+	endpoint, err := url.Parse(sub.URL)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return "url must be an absolute https URL"
+	}
+	if len(sub.Secret) < minWebhookSecret {
+		return "secret must be at least " + strconv.Itoa(minWebhookSecret) + " characters"
+	}
+	for _, event := range sub.Events {
+		if event == "" {
+			return "events must not contain empty names"
+		}
+	}
+	return ""
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	if err == webhook.ErrNotFound {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	log.Printf("webhook store failed: %v", err)
+	writeError(w, http.StatusServiceUnavailable, "webhook store unavailable")
+}
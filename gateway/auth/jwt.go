@@ -0,0 +1,209 @@
+// Package auth authenticates back-office operators and partners by the bearer
+// tokens their identity provider issues. Tokens are JWTs signed with HS256, RS256 or ES256;
+// only the algorithm matching the configured key is accepted, so a token
+// cannot choose how it is checked.
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// leeway absorbs clock skew between the gateway and the identity provider
+const leeway = time.Minute
+
+// ErrUnauthenticated is returned for a missing, malformed, expired or
+// wrongly signed token. It deliberately does not say which.
+var ErrUnauthenticated = errors.New("invalid or missing bearer token")
+
+// Principal is an authenticated operator or partner
+type Principal struct {
+	Subject string   // the operator ID, recorded as the actor on the ledger
+	Roles   []string // from the token's roles claim
+	Org     string   // the MSP of the partner's organisation, from the token's org claim
+}
+
+// HasRole reports whether the operator holds role
+func (p *Principal) HasRole(role string) bool {
+	for _, held := range p.Roles {
+		if held == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Verifier checks bearer tokens
+type Verifier struct {
+	alg      string
+	secret   []byte
+	key      crypto.PublicKey
+	issuer   string // required iss claim, when set
+	audience string // required aud entry, when set
+	now      func() time.Time
+}
+
+// LoadVerifier returns a verifier for tokens signed with the key in path: a
+// PEM public key or certificate for RS256 or ES256 (P-256), or otherwise the
+// HS256 shared secret itself. Empty issuer or audience skip those checks.
+func LoadVerifier(path string, issuer string, audience string) (*Verifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	v := &Verifier{issuer: issuer, audience: audience, now: time.Now}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		v.alg, v.secret = "HS256", []byte(strings.TrimSpace(string(data)))
+		if len(v.secret) < 32 {
+			return nil, fmt.Errorf("HS256 secret in %s is too short; use at least 32 bytes", path)
+		}
+		return v, nil
+	}
+
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate in %s: %v", path, err)
+		}
+		v.key = cert.PublicKey
+	case "PUBLIC KEY":
+		v.key, err = x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key in %s: %v", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unexpected %s in %s; use a public key or certificate", block.Type, path)
+	}
+	switch key := v.key.(type) {
+	case *rsa.PublicKey:
+		v.alg = "RS256"
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("EC key in %s is not P-256", path)
+		}
+		v.alg = "ES256"
+	default:
+		return nil, fmt.Errorf("unsupported key type %T in %s", v.key, path)
+	}
+	return v, nil
+}
+
+// Authenticate returns the operator presenting the request's bearer token
+func (v *Verifier) Authenticate(r *http.Request) (*Principal, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	return v.Verify(strings.TrimSpace(token))
+}
+
+// Verify checks a token's signature and claims
+func (v *Verifier) Verify(token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrUnauthenticated
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if decodeSegment(parts[0], &header) != nil || header.Alg != v.alg {
+		return nil, ErrUnauthenticated
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !v.verifySignature(parts[0]+"."+parts[1], signature) {
+		return nil, ErrUnauthenticated
+	}
+
+	var claims struct {
+		Subject   string          `json:"sub"`
+		Issuer    string          `json:"iss"`
+		Audience  json.RawMessage `json:"aud"`
+		ExpiresAt *float64        `json:"exp"`
+		NotBefore *float64        `json:"nbf"`
+		Roles     []string        `json:"roles"`
+		Org       string          `json:"org"`
+	}
+	if decodeSegment(parts[1], &claims) != nil {
+		return nil, ErrUnauthenticated
+	}
+	now := v.now()
+	if claims.ExpiresAt == nil || now.After(unixTime(*claims.ExpiresAt).Add(leeway)) {
+		return nil, ErrUnauthenticated
+	}
+	if claims.NotBefore != nil && now.Add(leeway).Before(unixTime(*claims.NotBefore)) {
+		return nil, ErrUnauthenticated
+	}
+	if claims.Subject == "" || (v.issuer != "" && claims.Issuer != v.issuer) || (v.audience != "" && !hasAudience(claims.Audience, v.audience)) {
+		return nil, ErrUnauthenticated
+	}
+	return &Principal{Subject: claims.Subject, Roles: claims.Roles, Org: claims.Org}, nil
+}
+
+func (v *Verifier) verifySignature(signed string, signature []byte) bool {
+	digest := sha256.Sum256([]byte(signed))
+	switch v.alg {
+	case "HS256":
+		mac := hmac.New(sha256.New, v.secret)
+		mac.Write([]byte(signed))
+		return hmac.Equal(mac.Sum(nil), signature)
+	case "RS256":
+		return rsa.VerifyPKCS1v15(v.key.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+	case "ES256":
+		// JWS carries the raw 32-byte r and s, not an ASN.1 signature
+		if len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(v.key.(*ecdsa.PublicKey), digest[:], r, s)
+	}
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(int64(seconds), 0)
+}
+
+// hasAudience reports whether an aud claim, a string or an array of strings,
+// names audience
+func hasAudience(claim json.RawMessage, audience string) bool {
+	var single string
+	if json.Unmarshal(claim, &single) == nil {
+		return single == audience
+	}
+	var list []string
+	if json.Unmarshal(claim, &list) == nil {
+		for _, entry := range list {
+			if entry == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+// Package bridge publishes chaincode events to Kafka for banks whose
+// downstream systems consume from Kafka rather than the ledger.
+//
+// Every event is published, keyed by the record it names (or its transaction
+// when it names none) so the events of one record stay ordered on one
+// partition. Delivery is at least once: an event is acknowledged by all
+// in-sync replicas before its position is written to the checkpoint file,
+// and a restarted bridge resumes after that position, republishing anything
+// it had sent but not yet recorded. Consumers deduplicate on the txId field.
+package bridge
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+
+	"ekyc-gateway/fabric"
+	"ekyc-gateway/kafka"
+)
+
+// Topics chooses the Kafka topic of each event
+type Topics struct {
+	Default string            // topic for events with no entry in ByEvent
+	ByEvent map[string]string // event name to topic
+}
+
+func (t Topics) topic(event string) string {
+	if topic, ok := t.ByEvent[event]; ok {
+		return topic
+	}
+	return t.Default
+}
+
+// Value is the published form of a chaincode event
+type Value struct {
+	Event       string          `json:"event"`
+	KYCID       string          `json:"kycId,omitempty"`
+	BlockNumber uint64          `json:"blockNumber"`
+	TxID        string          `json:"txId"`
+	Payload     json.RawMessage `json:"payload,omitempty"` // as emitted; a base64 string if not JSON
+}
+
+// Bridge publishes events and records its progress
+type Bridge struct {
+	producer   *kafka.Producer
+	registry   *Registry // nil publishes plain JSON
+	topics     Topics
+	checkpoint fabric.CheckpointFile
+}
+
+// New returns a bridge. A nil registry publishes values as plain JSON.
+func New(producer *kafka.Producer, registry *Registry, topics Topics, checkpointPath string) *Bridge {
+	return &Bridge{producer: producer, registry: registry, topics: topics, checkpoint: fabric.CheckpointFile(checkpointPath)}
+}
+
+// Checkpoint returns the position after the last event published, or nil
+// before the first
+func (b *Bridge) Checkpoint() (*fabric.Checkpoint, error) {
+	return b.checkpoint.Load()
+}
+
+// Handle publishes an event and then records it as handled. Use it as the
+// fabric.Listen handler.
+func (b *Bridge) Handle(ctx context.Context, event *fabric.Event) error {
+	var named struct {
+		KYCID string `json:"kycId"`
+	}
+	value := Value{Event: event.Name, BlockNumber: event.BlockNumber, TxID: event.TxID}
+	if json.Valid(event.Payload) {
+		value.Payload = event.Payload
+		json.Unmarshal(event.Payload, &named)
+		value.KYCID = named.KYCID
+	} else if len(event.Payload) > 0 {
+		value.Payload, _ = json.Marshal(base64.StdEncoding.EncodeToString(event.Payload))
+	}
+
+	topic := b.topics.topic(event.Name)
+	encoded, err := json.Marshal(&value)
+	if err != nil {
+		return err
+	}
+	if b.registry != nil {
+		encoded, err = b.registry.frame(ctx, topic, encoded)
+		if err != nil {
+			return err
+		}
+	}
+	key := value.KYCID
+	if key == "" {
+		key = event.TxID
+	}
+
+	err = b.producer.Produce(ctx, &kafka.Message{
+		Topic: topic,
+		Key:   []byte(key),
+		Value: encoded,
+		Headers: []kafka.Header{
+			{Key: "ekyc-event", Value: []byte(event.Name)},
+			{Key: "ekyc-tx-id", Value: []byte(event.TxID)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return b.checkpoint.Save(&fabric.Checkpoint{BlockNumber: event.BlockNumber, TxID: event.TxID})
+}
@@ -0,0 +1,106 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// eventSchema is the JSON Schema of the event values the bridge publishes
+const eventSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "ChaincodeEvent",
+  "type": "object",
+  "properties": {
+    "event": {"type": "string"},
+    "kycId": {"type": "string"},
+    "blockNumber": {"type": "integer", "minimum": 0},
+    "txId": {"type": "string"},
+    "payload": {}
+  },
+  "required": ["event", "blockNumber", "txId"]
+}`
+
+// Registry registers the event schema with a Confluent-compatible schema
+// registry and frames values in its wire format: a zero magic byte and the
+// big-endian schema ID ahead of the JSON, so consumers using the registry's
+// JSON Schema deserializer can read them. Credentials may be given in the URL.
+type Registry struct {
+	url    string
+	client *http.Client
+
+	mu  sync.Mutex
+	ids map[string]int32 // subject to schema ID
+}
+
+// NewRegistry returns a client for the registry at baseURL
+func NewRegistry(baseURL string) *Registry {
+	return &Registry{
+		url:    strings.TrimSuffix(baseURL, "/"),
+		client: &http.Client{Timeout: 10 * time.Second},
+		ids:    map[string]int32{},
+	}
+}
+
+// frame prefixes a value published to topic with its schema ID, registering
+// the schema under the topic's value subject the first time
+func (r *Registry) frame(ctx context.Context, topic string, value []byte) ([]byte, error) {
+	id, err := r.schemaID(ctx, topic+"-value")
+	if err != nil {
+		return nil, err
+	}
+	framed := make([]byte, 5, 5+len(value))
+	binary.BigEndian.PutUint32(framed[1:], uint32(id))
+	return append(framed, value...), nil
+}
+
+// schemaID registers the event schema under subject, which returns the
+// existing ID when the registry already has it
+func (r *Registry) schemaID(ctx context.Context, subject string) (int32, error) {
+	r.mu.Lock()
+	id, ok := r.ids[subject]
+	r.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	body, err := json.Marshal(map[string]string{"schemaType": "JSON", "schema": eventSchema})
+	if err != nil {
+		return 0, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url+"/subjects/"+url.PathEscape(subject)+"/versions", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	response, err := r.client.Do(request)
+	if err != nil {
+		return 0, fmt.Errorf("failed to register schema for %s: %v", subject, err)
+	}
+	defer response.Body.Close()
+	responseBody, _ := io.ReadAll(io.LimitReader(response.Body, 64<<10))
+	if response.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("schema registry rejected %s: %s: %s", subject, response.Status, responseBody)
+	}
+
+	var registered struct {
+		ID int32 `json:"id"`
+	}
+	err = json.Unmarshal(responseBody, &registered)
+	if err != nil {
+		return 0, fmt.Errorf("malformed schema registry response: %v", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ids[subject] = registered.ID
+	return registered.ID, nil
+}
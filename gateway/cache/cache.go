@@ -0,0 +1,16 @@
+// Package cache provides the stores behind the gateway's read-through cache
+// of chaincode query results.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Store is a byte cache whose entries expire after a time to live
+type Store interface {
+	// Get returns a cached value; ok is false on a miss
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
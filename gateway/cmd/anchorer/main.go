@@ -0,0 +1,75 @@
+// Command anchorer periodically writes a digest of the eKYC ledger state to
+// a public chain or external timestamping service and records the proof on
+// the ledger with RecordAnchor: an OpenTimestamps calendar, or an Ethereum
+// node sending from an account it holds the key of, as -target selects. The
+// identity must be a contract administrator. See package anchor for what the
+// digest covers.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"ekyc-gateway/anchor"
+	"ekyc-gateway/fabric"
+)
+
+func main() {
+	var (
+		config      fabric.Config
+		mspID       = flag.String("msp", "Org1MSP", "MSP ID of the anchorer identity")
+		certPath    = flag.String("cert", "", "PEM enrolment certificate of the anchorer identity")
+		keyPath     = flag.String("key", "", "PEM private key of the anchorer identity")
+		targetName  = flag.String("target", "opentimestamps", "opentimestamps or ethereum")
+		calendar    = flag.String("calendar", "https://a.pool.opentimestamps.org", "OpenTimestamps calendar URL")
+		ethEndpoint = flag.String("eth-rpc", "", "Ethereum node JSON-RPC URL")
+		ethFrom     = flag.String("eth-from", "", "Ethereum account the node sends anchors from")
+		ethTo       = flag.String("eth-to", "", "Ethereum account anchors are sent to; empty sends to -eth-from")
+		ethNetwork  = flag.String("eth-network", "", "Ethereum network name recorded with each anchor, such as mainnet")
+		interval    = flag.Duration("interval", 24*time.Hour, "how often the ledger state is anchored")
+	)
+	flag.StringVar(&config.Endpoint, "endpoint", "localhost:7051", "gateway peer address")
+	flag.StringVar(&config.TLSCACertPath, "tls-ca", "", "peer TLS CA certificate; empty connects without TLS")
+	flag.StringVar(&config.ServerName, "server-name", "", "TLS server name override")
+	flag.StringVar(&config.Channel, "channel", "ekycChannel", "channel name")
+	flag.StringVar(&config.Chaincode, "chaincode", "ekyc-chaincode", "chaincode name")
+	flag.Parse()
+
+	var target anchor.Target
+	switch *targetName {
+	case "opentimestamps":
+		target = &anchor.OpenTimestamps{Calendar: *calendar}
+	case "ethereum":
+		if *ethEndpoint == "" || *ethFrom == "" {
+			log.Fatal("-eth-rpc and -eth-from are required for Ethereum")
+		}
+		target = &anchor.Ethereum{Endpoint: *ethEndpoint, From: *ethFrom, To: *ethTo, Network: *ethNetwork}
+	default:
+		log.Fatalf("unknown -target %q; use opentimestamps or ethereum", *targetName)
+	}
+
+	identity, err := fabric.LoadIdentity(*mspID, *certPath, *keyPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	client, err := fabric.Dial(config, identity)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	worker := anchor.NewWorker(client, target)
+	log.Printf("anchoring ledger state to %s every %s", target.Name(), *interval)
+	_, err = worker.Anchor(ctx)
+	if err != nil {
+		log.Printf("initial anchoring failed: %v", err)
+	}
+	worker.Run(ctx, *interval)
+}
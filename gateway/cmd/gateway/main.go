@@ -0,0 +1,284 @@
+// Command gateway serves the eKYC REST API in front of a Fabric peer.
+//
+// Relying-party reads (GetKYCLite and status lookups) can be served from a
+// read-through cache held in process (-cache memory) or shared between
+// gateway instances in Redis (-cache redis). Cached entries are evicted when
+// a chaincode event names the record they describe and otherwise expire
+// after -cache-ttl, which bounds staleness if events are delayed.
+//
+// GraphQL queries over records, histories, consents and stats are served at
+// /graphql, resolving only the fields a query selects. Anonymous queries may
+// only select a record's lite fields; anything else needs an operator token,
+// accepted with -admin-jwt-key.
+//
+// With -read-model the verifier queue, expiring-soon and stats endpoints are
+// served from the views the projector command maintains in Redis.
+//
+// With -websocket, browsers subscribe at /ws?kycId= or /ws?org= to chaincode
+// events, pushed as they commit. Organisation subscriptions need
+// -admin-jwt-key and a partner or admin token, as webhook registration does.
+//
+// With -webhooks, partners register endpoints at /api/webhooks and the
+// gateway delivers matching chaincode events to them, signed and retried,
+// resuming after a restart from a checkpoint in Redis. Enable it on one
+// gateway instance per Redis database. Registration needs -admin-jwt-key:
+// partners present a token with the kyc.partner role and their MSP in its
+// org claim, and manage only their own organisation's subscriptions, while
+// kyc.admin tokens manage all of them.
+//
+// With -admin-jwt-key the back-office API behind the review console is served
+// at /api/admin to operators presenting a bearer token from the identity
+// provider; its queues come from the projector's views. The gateway identity
+// needs the kyc.verifier and kyc.senior attributes, since it submits the
+// operators' decisions, escalations and assignments on their behalf. It also
+// needs the kyc.gateway attribute, so that the chaincode records each of
+// them as the operator's: without it every operator acts as the gateway
+// identity, and under dual approval the second approval is refused.
+//
+// The gateway identity's key can be held on an HSM or other PKCS #11 token
+// instead of in the -key file: name the token's library with -pkcs11-module,
+// the token with -pkcs11-token and the key with -pkcs11-key-label, and pass
+// the user PIN in PKCS11_PIN. PKCS #11 needs a binary built with cgo and
+// -tags pkcs11. With -sign-decisions the back-office API signs the history
+// entry of each decision, escalation and assignment with that key, and the
+// chaincode stores the key's PKCS #11 URI with the signature.
+//
+// With -vault, operators upload documents at /api/documents/{kycId} and the
+// gateway stores each file in a document vault before recording its hash
+// with AddDocumentHash, and serves it back checked against that hash:
+// -vault local keeps files under -vault-dir, -vault s3 in -vault-s3-bucket
+// with the credentials in AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN, and -vault ipfs on the node whose RPC API is
+// -vault-ipfs-api. The document routes authenticate operators as /api/admin
+// does, so -vault needs -admin-jwt-key.
+//
+// With -grpc-listen the KYCService defined in proto/ekyc/v1 is served on a
+// second port for integrators that prefer gRPC. It is served only over
+// mutual TLS, so -grpc-listen needs -grpc-tls-cert and -grpc-tls-key for the
+// server and -grpc-client-ca for the CA issuing the integrators' client
+// certificates.
+//
+// The gateway reads records without a purpose code, so it serves its own
+// organisation's records; records other organisations own are refused or
+// left out of query results by the chaincode's purpose limitation.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"ekyc-gateway/api"
+	"ekyc-gateway/auth"
+	"ekyc-gateway/cache"
+	"ekyc-gateway/ekycpb"
+	"ekyc-gateway/fabric"
+	"ekyc-gateway/grpcapi"
+	"ekyc-gateway/readmodel"
+	"ekyc-gateway/redis"
+	"ekyc-gateway/vault"
+	"ekyc-gateway/webhook"
+)
+
+func main() {
+	var (
+		config        fabric.Config
+		mspID         = flag.String("msp", "Org1MSP", "MSP ID of the gateway identity")
+		certPath      = flag.String("cert", "", "PEM enrolment certificate of the gateway identity")
+		keyPath       = flag.String("key", "", "PEM private key of the gateway identity")
+		pkcs11Module  = flag.String("pkcs11-module", "", "PKCS #11 library holding the gateway identity's key, instead of -key")
+		pkcs11Token   = flag.String("pkcs11-token", "", "label of the PKCS #11 token holding the key")
+		pkcs11Key     = flag.String("pkcs11-key-label", "", "label of the private key on the PKCS #11 token")
+		signDecisions = flag.Bool("sign-decisions", false, "sign the history entries of back-office decisions with the gateway identity's key")
+		listen        = flag.String("listen", ":8090", "HTTP listen address")
+		grpcListen    = flag.String("grpc-listen", "", "gRPC listen address; empty disables the gRPC service")
+		grpcCert      = flag.String("grpc-tls-cert", "", "PEM TLS certificate of the gRPC service")
+		grpcKey       = flag.String("grpc-tls-key", "", "PEM private key of the gRPC service's TLS certificate")
+		grpcClientCA  = flag.String("grpc-client-ca", "", "PEM CA certificates issuing gRPC client certificates")
+		cacheMode     = flag.String("cache", "none", "read cache: none, memory or redis")
+		cacheTTL      = flag.Duration("cache-ttl", 30*time.Second, "how long a cached read may be served")
+		cacheSize     = flag.Int("cache-size", 100000, "maximum entries held by the memory cache")
+		websockets    = flag.Bool("websocket", false, "push chaincode events to browsers at /ws")
+		wsOrigins     = flag.String("websocket-origins", "", "comma-separated origins allowed to open /ws; empty allows any")
+		webhooks      = flag.Bool("webhooks", false, "serve webhook registration and deliver events to partner endpoints")
+		readModel     = flag.Bool("read-model", false, "serve list endpoints from the projector's Redis views")
+		adminKey      = flag.String("admin-jwt-key", "", "public key, certificate or HS256 secret file verifying operator and partner tokens; empty disables /api/admin")
+		adminIssuer   = flag.String("admin-jwt-issuer", "", "required iss claim of operator tokens")
+		adminAudience = flag.String("admin-jwt-audience", "", "required aud claim of operator tokens")
+		vaultMode     = flag.String("vault", "none", "document vault: none, local, s3 or ipfs")
+		vaultDir      = flag.String("vault-dir", "", "directory holding documents for -vault local")
+		vaultBucket   = flag.String("vault-s3-bucket", "", "S3 bucket holding documents for -vault s3")
+		vaultRegion   = flag.String("vault-s3-region", os.Getenv("AWS_REGION"), "AWS region of the S3 bucket")
+		vaultEndpoint = flag.String("vault-s3-endpoint", "", "S3-compatible endpoint overriding AWS, addressing the bucket by path")
+		vaultIPFS     = flag.String("vault-ipfs-api", "http://127.0.0.1:5001", "IPFS node RPC API for -vault ipfs")
+		redisAddr     = flag.String("redis-addr", "localhost:6379", "Redis address for -cache redis, -read-model, -webhooks and -admin-jwt-key")
+		redisPassword = flag.String("redis-password", "", "Redis password")
+		redisDB       = flag.Int("redis-db", 0, "Redis database number")
+	)
+	flag.StringVar(&config.Endpoint, "endpoint", "localhost:7051", "gateway peer address")
+	flag.StringVar(&config.TLSCACertPath, "tls-ca", "", "peer TLS CA certificate; empty connects without TLS")
+	flag.StringVar(&config.ServerName, "server-name", "", "TLS server name override")
+	flag.StringVar(&config.Channel, "channel", "ekycChannel", "channel name")
+	flag.StringVar(&config.Chaincode, "chaincode", "ekyc-chaincode", "chaincode name")
+	flag.IntVar(&config.MVCCRetries, "mvcc-retries", 3, "times to retry a transaction invalidated by an MVCC conflict")
+	flag.Parse()
+
+	var identity *fabric.Identity
+	var err error
+	if *pkcs11Module != "" {
+		identity, err = fabric.LoadPKCS11Identity(*mspID, *certPath, fabric.PKCS11Config{
+			Module:     *pkcs11Module,
+			TokenLabel: *pkcs11Token,
+			PIN:        os.Getenv("PKCS11_PIN"),
+			KeyLabel:   *pkcs11Key,
+		})
+	} else {
+		identity, err = fabric.LoadIdentity(*mspID, *certPath, *keyPath)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	client, err := fabric.Dial(config, identity)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	redisClient := redis.NewClient(*redisAddr, *redisPassword, *redisDB, 16)
+
+	var store cache.Store
+	switch *cacheMode {
+	case "none":
+	case "memory":
+		store = cache.NewMemory(*cacheSize)
+	case "redis":
+		store = cache.NewRedis(redisClient)
+	default:
+		log.Fatalf("unknown cache %q; use none, memory or redis", *cacheMode)
+	}
+
+	var documents vault.DocumentVault
+	switch *vaultMode {
+	case "none":
+	case "local":
+		if *vaultDir == "" {
+			log.Fatal("-vault local needs -vault-dir")
+		}
+		documents = &vault.Local{Dir: *vaultDir}
+	case "s3":
+		if *vaultBucket == "" || *vaultRegion == "" {
+			log.Fatal("-vault s3 needs -vault-s3-bucket and -vault-s3-region")
+		}
+		documents = &vault.S3{
+			Bucket:          *vaultBucket,
+			Region:          *vaultRegion,
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			Endpoint:        *vaultEndpoint,
+		}
+	case "ipfs":
+		documents = &vault.IPFS{API: *vaultIPFS}
+	default:
+		log.Fatalf("unknown vault %q; use none, local, s3 or ipfs", *vaultMode)
+	}
+	if documents != nil && *adminKey == "" {
+		log.Fatal("-vault needs -admin-jwt-key to authenticate operators")
+	}
+	if *webhooks && *adminKey == "" {
+		log.Fatal("-webhooks needs -admin-jwt-key to authenticate partners")
+	}
+	if *grpcListen != "" && (*grpcCert == "" || *grpcKey == "" || *grpcClientCA == "") {
+		log.Fatal("-grpc-listen needs -grpc-tls-cert, -grpc-tls-key and -grpc-client-ca to authenticate clients")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	server := api.NewServer(client, store, *cacheTTL)
+	if *readModel {
+		server.ServeReadModel(readmodel.NewViews(redisClient))
+	}
+	var operators *auth.Verifier
+	if *adminKey != "" {
+		operators, err = auth.LoadVerifier(*adminKey, *adminIssuer, *adminAudience)
+		if err != nil {
+			log.Fatal(err)
+		}
+		server.ServeAdmin(client, readmodel.NewViews(redisClient), operators)
+		if documents != nil {
+			server.ServeDocuments(documents, client, operators)
+		}
+		if *signDecisions {
+			server.SignDecisions()
+		}
+	}
+	if *websockets {
+		var origins []string
+		if *wsOrigins != "" {
+			origins = strings.Split(*wsOrigins, ",")
+		}
+		server.ServeEvents(origins, operators)
+	}
+	if *webhooks {
+		hooks := webhook.NewStore(redisClient)
+		server.ServeWebhooks(hooks, operators)
+		dispatcher := webhook.NewDispatcher(hooks, nil)
+		checkpoint, err := dispatcher.Checkpoint(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go fabric.Listen(ctx, client, checkpoint, dispatcher.Handle)
+		go dispatcher.Run(ctx)
+	}
+	if store != nil || *websockets {
+		go fabric.Listen(ctx, client, nil, server.HandleEvent)
+	}
+
+	if *grpcListen != "" {
+		listener, err := net.Listen("tcp", *grpcListen)
+		if err != nil {
+			log.Fatal(err)
+		}
+		options, err := grpcapi.ServerOptions(*grpcCert, *grpcKey, *grpcClientCA)
+		if err != nil {
+			log.Fatal(err)
+		}
+		grpcServer := grpc.NewServer(options...)
+		ekycpb.RegisterKYCServiceServer(grpcServer, grpcapi.NewServer(client))
+		go func() {
+			<-ctx.Done()
+			// event streams never end on their own, so stop rather than drain
+			grpcServer.Stop()
+		}()
+		go func() {
+			log.Printf("gRPC service listening on %s", *grpcListen)
+			err := grpcServer.Serve(listener)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}()
+	}
+
+	httpServer := &http.Server{Addr: *listen, Handler: server}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("gateway listening on %s", *listen)
+	err = httpServer.ListenAndServe()
+	if err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,111 @@
+// Command kafkabridge publishes every eKYC chaincode event to Kafka. Events
+// go to -topic unless -topic-map routes their name elsewhere; with
+// -schema-registry their values are framed for the registry's JSON Schema
+// deserializer. See package bridge for the delivery guarantees.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"ekyc-gateway/bridge"
+	"ekyc-gateway/fabric"
+	"ekyc-gateway/kafka"
+)
+
+func main() {
+	var (
+		config         fabric.Config
+		mspID          = flag.String("msp", "Org1MSP", "MSP ID of the bridge identity")
+		certPath       = flag.String("cert", "", "PEM enrolment certificate of the bridge identity")
+		keyPath        = flag.String("key", "", "PEM private key of the bridge identity")
+		brokers        = flag.String("brokers", "localhost:9092", "comma-separated Kafka bootstrap brokers")
+		kafkaCA        = flag.String("kafka-tls-ca", "", "CA certificate of the brokers; empty connects in plaintext")
+		kafkaCert      = flag.String("kafka-tls-cert", "", "client certificate for brokers that require mutual TLS")
+		kafkaKey       = flag.String("kafka-tls-key", "", "client key for -kafka-tls-cert")
+		topic          = flag.String("topic", "ekyc.events", "topic for events -topic-map does not route")
+		topicMap       = flag.String("topic-map", "", "comma-separated event=topic routes, e.g. KYCVerified=ekyc.verified")
+		registryURL    = flag.String("schema-registry", "", "schema registry URL; empty publishes plain JSON")
+		checkpointPath = flag.String("checkpoint", "kafkabridge.checkpoint", "file recording the last event published")
+	)
+	flag.StringVar(&config.Endpoint, "endpoint", "localhost:7051", "gateway peer address")
+	flag.StringVar(&config.TLSCACertPath, "tls-ca", "", "peer TLS CA certificate; empty connects without TLS")
+	flag.StringVar(&config.ServerName, "server-name", "", "TLS server name override")
+	flag.StringVar(&config.Channel, "channel", "ekycChannel", "channel name")
+	flag.StringVar(&config.Chaincode, "chaincode", "ekyc-chaincode", "chaincode name")
+	flag.Parse()
+
+	topics := bridge.Topics{Default: *topic, ByEvent: map[string]string{}}
+	if *topicMap != "" {
+		for _, route := range strings.Split(*topicMap, ",") {
+			event, target, ok := strings.Cut(route, "=")
+			if !ok || event == "" || target == "" {
+				log.Fatalf("invalid -topic-map entry %q; use event=topic", route)
+			}
+			topics.ByEvent[event] = target
+		}
+	}
+
+	kafkaConfig := kafka.Config{Brokers: strings.Split(*brokers, ","), ClientID: "ekyc-kafkabridge"}
+	if *kafkaCA != "" {
+		kafkaConfig.TLS = loadKafkaTLS(*kafkaCA, *kafkaCert, *kafkaKey)
+	}
+	producer := kafka.NewProducer(kafkaConfig)
+	defer producer.Close()
+
+	var registry *bridge.Registry
+	if *registryURL != "" {
+		registry = bridge.NewRegistry(*registryURL)
+	}
+
+	identity, err := fabric.LoadIdentity(*mspID, *certPath, *keyPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	client, err := fabric.Dial(config, identity)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	b := bridge.New(producer, registry, topics, *checkpointPath)
+	checkpoint, err := b.Checkpoint()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	log.Printf("bridging chaincode events to %s", *brokers)
+	err = fabric.Listen(ctx, client, checkpoint, b.Handle)
+	if err != nil && err != context.Canceled {
+		log.Fatal(err)
+	}
+}
+
+func loadKafkaTLS(caPath string, certPath string, keyPath string) *tls.Config {
+	caPEM, err := os.ReadFile(caPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		log.Fatalf("no certificates found in %s", caPath)
+	}
+	config := &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	if certPath != "" {
+		pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		config.Certificates = []tls.Certificate{pair}
+	}
+	return config
+}
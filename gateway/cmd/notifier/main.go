@@ -0,0 +1,116 @@
+// Command notifier emails and texts customers when their KYC record is
+// verified, rejected or about to expire, honouring the notification
+// preferences stored on the record. Email goes through -smtp-addr; SMS goes
+// through Amazon SNS or Twilio as -sms-provider selects, with credentials
+// from the providers' usual environment variables (AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN; TWILIO_ACCOUNT_SID,
+// TWILIO_AUTH_TOKEN). See package notify for the delivery guarantees.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+
+	"ekyc-gateway/fabric"
+	"ekyc-gateway/notify"
+)
+
+func main() {
+	var (
+		config         fabric.Config
+		mspID          = flag.String("msp", "Org1MSP", "MSP ID of the notifier identity")
+		certPath       = flag.String("cert", "", "PEM enrolment certificate of the notifier identity")
+		keyPath        = flag.String("key", "", "PEM private key of the notifier identity")
+		smtpAddr       = flag.String("smtp-addr", "", "mail relay host:port; empty sends no email")
+		smtpFrom       = flag.String("smtp-from", "", "sender address of notification emails")
+		smtpUser       = flag.String("smtp-user", "", "mail relay username; empty sends without authenticating")
+		smtpPassword   = flag.String("smtp-password", "", "mail relay password")
+		smsProvider    = flag.String("sms-provider", "", "sns or twilio; empty sends no SMS")
+		smsFrom        = flag.String("sms-from", "", "Twilio sending number or messaging service SID, or SNS sender ID")
+		awsRegion      = flag.String("aws-region", os.Getenv("AWS_REGION"), "SNS region")
+		templatesDir   = flag.String("templates", "", "directory of templates overriding the built-in ones")
+		checkpointPath = flag.String("checkpoint", "notifier.checkpoint", "file recording the last event handled")
+	)
+	flag.StringVar(&config.Endpoint, "endpoint", "localhost:7051", "gateway peer address")
+	flag.StringVar(&config.TLSCACertPath, "tls-ca", "", "peer TLS CA certificate; empty connects without TLS")
+	flag.StringVar(&config.ServerName, "server-name", "", "TLS server name override")
+	flag.StringVar(&config.Channel, "channel", "ekycChannel", "channel name")
+	flag.StringVar(&config.Chaincode, "chaincode", "ekyc-chaincode", "chaincode name")
+	flag.Parse()
+
+	providers := map[string]notify.Provider{}
+	if *smtpAddr != "" {
+		if *smtpFrom == "" {
+			log.Fatal("-smtp-from is required with -smtp-addr")
+		}
+		providers[notify.Email] = &notify.SMTP{Addr: *smtpAddr, From: *smtpFrom, Username: *smtpUser, Password: *smtpPassword}
+	}
+	switch *smsProvider {
+	case "":
+	case "sns":
+		if *awsRegion == "" {
+			log.Fatal("-aws-region or AWS_REGION is required for SNS")
+		}
+		providers[notify.SMS] = &notify.SNS{
+			Region:          *awsRegion,
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			SenderID:        *smsFrom,
+		}
+	case "twilio":
+		if *smsFrom == "" {
+			log.Fatal("-sms-from is required for Twilio")
+		}
+		providers[notify.SMS] = &notify.Twilio{
+			AccountSID: os.Getenv("TWILIO_ACCOUNT_SID"),
+			AuthToken:  os.Getenv("TWILIO_AUTH_TOKEN"),
+			From:       *smsFrom,
+		}
+	default:
+		log.Fatalf("unknown -sms-provider %q; use sns or twilio", *smsProvider)
+	}
+	if len(providers) == 0 {
+		log.Fatal("nothing to send with; set -smtp-addr, -sms-provider or both")
+	}
+
+	templates, err := notify.LoadTemplates(*templatesDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	identity, err := fabric.LoadIdentity(*mspID, *certPath, *keyPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	client, err := fabric.Dial(config, identity)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	checkpointFile := fabric.CheckpointFile(*checkpointPath)
+	checkpoint, err := checkpointFile.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	worker := notify.NewWorker(client, templates, providers)
+	log.Printf("sending notifications for chaincode events")
+	err = fabric.Listen(ctx, client, checkpoint, func(ctx context.Context, event *fabric.Event) error {
+		err := worker.Handle(ctx, event)
+		if err != nil {
+			return err
+		}
+		return checkpointFile.Save(&fabric.Checkpoint{BlockNumber: event.BlockNumber, TxID: event.TxID})
+	})
+	if err != nil && err != context.Canceled {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,71 @@
+// Command projector maintains the eKYC read model in Redis: verifier review
+// queues, the expiring-soon list and status counts, kept in step with the
+// ledger from chaincode events and a periodic reconcile. See package
+// readmodel for the consistency contract the views offer.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"ekyc-gateway/fabric"
+	"ekyc-gateway/readmodel"
+	"ekyc-gateway/redis"
+)
+
+func main() {
+	var (
+		config            fabric.Config
+		mspID             = flag.String("msp", "Org1MSP", "MSP ID of the projector identity")
+		certPath          = flag.String("cert", "", "PEM enrolment certificate of the projector identity")
+		keyPath           = flag.String("key", "", "PEM private key of the projector identity")
+		redisAddr         = flag.String("redis-addr", "localhost:6379", "Redis address")
+		redisPassword     = flag.String("redis-password", "", "Redis password")
+		redisDB           = flag.Int("redis-db", 0, "Redis database number")
+		reconcileInterval = flag.Duration("reconcile-interval", 15*time.Minute, "how often the views are reconciled with the ledger; bounds staleness when events are missed")
+	)
+	flag.StringVar(&config.Endpoint, "endpoint", "localhost:7051", "gateway peer address")
+	flag.StringVar(&config.TLSCACertPath, "tls-ca", "", "peer TLS CA certificate; empty connects without TLS")
+	flag.StringVar(&config.ServerName, "server-name", "", "TLS server name override")
+	flag.StringVar(&config.Channel, "channel", "ekycChannel", "channel name")
+	flag.StringVar(&config.Chaincode, "chaincode", "ekyc-chaincode", "chaincode name")
+	flag.Parse()
+
+	identity, err := fabric.LoadIdentity(*mspID, *certPath, *keyPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	client, err := fabric.Dial(config, identity)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	projector := readmodel.NewProjector(client, redis.NewClient(*redisAddr, *redisPassword, *redisDB, 4))
+	checkpoint, err := projector.Checkpoint(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// start listening before the first reconcile so no change falls between them
+	go func() {
+		err := fabric.Listen(ctx, client, checkpoint, projector.Handle)
+		if err != nil && ctx.Err() == nil {
+			log.Fatal(err)
+		}
+	}()
+
+	log.Printf("reconciling read model")
+	err = projector.Reconcile(ctx)
+	if err != nil {
+		log.Printf("initial reconcile failed: %v", err)
+	}
+	projector.Run(ctx, *reconcileInterval)
+}
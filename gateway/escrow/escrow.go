@@ -0,0 +1,162 @@
+// Package escrow splits field-encryption keys into Shamir shares for the
+// custodian organisations a key is escrowed with, and rebuilds a key from
+// the shares they release. The chaincode only ever sees the hash of each
+// share, recorded by EscrowFieldKey, and the shares custodians release to a
+// recovery with ApproveKeyRecovery, each encrypted to the RSA public key the
+// requester named in RequestKeyRecovery, so no single organisation other
+// than the owner can rebuild the key and the owner can once threshold
+// custodians agree.
+//
+// Shares are computed byte-wise over GF(2^8); each share is the x
+// coordinate, 1 to 255, followed by one y coordinate per byte of the key.
+package escrow
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+)
+
+// exp and log are the exponent and logarithm tables of GF(2^8) with the
+// AES polynomial x^8 + x^4 + x^3 + x + 1, generated by 3
+var exp, log [256]byte
+
+func init() {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i] = x
+		log[x] = byte(i)
+		// multiply by the generator: x*3 = x*2 xor x
+		double := x << 1
+		if x&0x80 != 0 {
+			double ^= 0x1b
+		}
+		x ^= double
+	}
+	exp[255] = exp[0]
+}
+
+func mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return exp[(int(log[a])+int(log[b]))%255]
+}
+
+func div(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return exp[(int(log[a])-int(log[b])+255)%255]
+}
+
+// Split splits secret into n shares, any threshold of which rebuild it
+func Split(secret []byte, n, threshold int) ([][]byte, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("secret is empty")
+	}
+	if threshold < 2 || threshold > n || n > 255 {
+		return nil, fmt.Errorf("need 2 <= threshold <= shares <= 255, got threshold %d of %d", threshold, n)
+	}
+
+	shares := make([][]byte, n)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][0] = byte(i + 1)
+	}
+	coefficients := make([]byte, threshold-1)
+	for b, s := range secret {
+		_, err := rand.Read(coefficients)
+		if err != nil {
+			return nil, err
+		}
+		for _, share := range shares {
+			// evaluate s + c1*x + ... + c(t-1)*x^(t-1) by Horner's rule
+			x, y := share[0], byte(0)
+			for c := len(coefficients) - 1; c >= 0; c-- {
+				y = mul(y^coefficients[c], x)
+			}
+			share[b+1] = y ^ s
+		}
+	}
+	return shares, nil
+}
+
+// Combine rebuilds a secret from threshold or more of its shares. Fewer
+// shares, or shares of different secrets, give a wrong secret rather than
+// an error; check each share against its recorded hash first.
+func Combine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, fmt.Errorf("need at least two shares, got %d", len(shares))
+	}
+	size := len(shares[0])
+	seen := map[byte]bool{}
+	for _, share := range shares {
+		if len(share) != size || size < 2 {
+			return nil, fmt.Errorf("shares differ in length")
+		}
+		if share[0] == 0 || seen[share[0]] {
+			return nil, fmt.Errorf("invalid or repeated share %d", share[0])
+		}
+		seen[share[0]] = true
+	}
+
+	secret := make([]byte, size-1)
+	for b := range secret {
+		// Lagrange interpolation at x = 0
+		var s byte
+		for i, share := range shares {
+			basis := byte(1)
+			for j, other := range shares {
+				if i != j {
+					basis = mul(basis, div(other[0], other[0]^share[0]))
+				}
+			}
+			s ^= mul(share[b+1], basis)
+		}
+		secret[b] = s
+	}
+	return secret, nil
+}
+
+// ShareHash is the hex SHA-256 of a share, as EscrowFieldKey records it
+func ShareHash(share []byte) string {
+	sum := sha256.Sum256(share)
+	return hex.EncodeToString(sum[:])
+}
+
+// SealShare encrypts a share to a recovery's PEM recipient key with
+// RSA-OAEP and SHA-256, giving the base64 ApproveKeyRecovery takes
+func SealShare(recipientKey string, share []byte) (string, error) {
+	block, _ := pem.Decode([]byte(recipientKey))
+	if block == nil {
+		return "", fmt.Errorf("recipient key is not PEM")
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("invalid recipient key: %v", err)
+	}
+	public, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return "", fmt.Errorf("recipient key must be an RSA public key")
+	}
+	sealed, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, public, share, nil)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// OpenShare decrypts a share a custodian released with SealShare
+func OpenShare(private *rsa.PrivateKey, encryptedShare string) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encryptedShare)
+	if err != nil {
+		return nil, fmt.Errorf("encrypted share is not base64: %v", err)
+	}
+	return rsa.DecryptOAEP(sha256.New(), rand.Reader, private, sealed, nil)
+}
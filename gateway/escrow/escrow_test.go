@@ -0,0 +1,93 @@
+package escrow
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSplitCombine(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef") // an AES-256 field key
+
+	tests := []struct {
+		name      string
+		n         int
+		threshold int
+		use       []int // indexes of the shares combined
+		rebuilds  bool
+	}{
+		{name: "2 of 3", n: 3, threshold: 2, use: []int{0, 2}, rebuilds: true},
+		{name: "3 of 5", n: 5, threshold: 3, use: []int{4, 1, 3}, rebuilds: true},
+		{name: "every share of 3 of 5", n: 5, threshold: 3, use: []int{0, 1, 2, 3, 4}, rebuilds: true},
+		{name: "255 shares", n: 255, threshold: 2, use: []int{0, 254}, rebuilds: true},
+		{name: "2 below 3 of 5", n: 5, threshold: 3, use: []int{0, 1}},
+		{name: "4 below 5 of 5", n: 5, threshold: 5, use: []int{0, 1, 2, 3}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			shares, err := Split(secret, test.n, test.threshold)
+			if err != nil {
+				t.Fatalf("Split: %v", err)
+			}
+			if len(shares) != test.n {
+				t.Fatalf("got %d shares, want %d", len(shares), test.n)
+			}
+			used := [][]byte{}
+			for _, i := range test.use {
+				used = append(used, shares[i])
+			}
+			rebuilt, err := Combine(used)
+			if err != nil {
+				t.Fatalf("Combine: %v", err)
+			}
+			if bytes.Equal(rebuilt, secret) != test.rebuilds {
+				t.Errorf("rebuilt %x from %d of %d shares with threshold %d, want the secret: %v", rebuilt, len(used), test.n, test.threshold, test.rebuilds)
+			}
+		})
+	}
+}
+
+func TestSplitInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    []byte
+		n         int
+		threshold int
+	}{
+		{name: "empty secret", n: 3, threshold: 2},
+		{name: "threshold of one", secret: []byte("k"), n: 3, threshold: 1},
+		{name: "threshold above shares", secret: []byte("k"), n: 3, threshold: 4},
+		{name: "too many shares", secret: []byte("k"), n: 256, threshold: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Split(test.secret, test.n, test.threshold)
+			if err == nil {
+				t.Errorf("Split(%d of %d) succeeded", test.threshold, test.n)
+			}
+		})
+	}
+}
+
+func TestCombineInvalid(t *testing.T) {
+	shares, err := Split([]byte("0123456789abcdef"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		shares [][]byte
+	}{
+		{name: "one share", shares: shares[:1]},
+		{name: "repeated share", shares: [][]byte{shares[0], shares[0]}},
+		{name: "different lengths", shares: [][]byte{shares[0], shares[1][:5]}},
+		{name: "zero x coordinate", shares: [][]byte{shares[0], append([]byte{0}, shares[1][1:]...)}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Combine(test.shares)
+			if err == nil {
+				t.Error("Combine succeeded")
+			}
+		})
+	}
+}
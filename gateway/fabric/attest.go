@@ -0,0 +1,77 @@
+package fabric
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Attestation names the history entry a transaction writes that its
+// submitter signs: the action the chaincode records and the record it acts on
+type Attestation struct {
+	Action string
+	KYCID  string
+}
+
+// SubmitAttested submits a transaction function as Submit does, signing the
+// history entry it writes with the client identity's key. The signature and
+// the identity's KeyID travel in the historySignature and
+// historySignatureKeyId transient fields, which the chaincode verifies
+// against the submitting certificate and stores with the entry; the
+// transaction fails if no entry it writes matches attestation.
+func (c *Client) SubmitAttested(ctx context.Context, attestation Attestation, function string, args ...string) (string, []byte, error) {
+	return c.submitRetrying(ctx, function, args, func(txID string) (map[string][]byte, error) {
+		// the chaincode's HistorySignaturePayload, keys sorted
+		payload, err := json.Marshal(struct {
+			Action string `json:"action"`
+			KYCID  string `json:"kycId"`
+			TxID   string `json:"txId"`
+		}{attestation.Action, attestation.KYCID, txID})
+		if err != nil {
+			return nil, err
+		}
+		signature, err := c.identity.sign(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign the %s history entry: %v", attestation.Action, err)
+		}
+		transient := map[string][]byte{"historySignature": signature}
+		if c.identity.KeyID != "" {
+			transient["historySignatureKeyId"] = []byte(c.identity.KeyID)
+		}
+		return transient, nil
+	})
+}
+
+// SignMonthlySummary signs a generated monthly summary with the client
+// identity's key and records the signature, with the identity's KeyID, on
+// the ledger. It returns the signing transaction's ID.
+func (c *Client) SignMonthlySummary(ctx context.Context, month string) (string, error) {
+	summaryJSON, err := c.Evaluate(ctx, "GetMonthlySummary", month)
+	if err != nil {
+		return "", err
+	}
+	var summary struct {
+		Month       string `json:"month"`
+		ContentHash string `json:"contentHash"`
+	}
+	err = json.Unmarshal(summaryJSON, &summary)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the summary for %s: %v", month, err)
+	}
+
+	// the chaincode's ReportSignaturePayload, keys sorted
+	payload, err := json.Marshal(struct {
+		ContentHash string `json:"contentHash"`
+		Month       string `json:"month"`
+	}{summary.ContentHash, summary.Month})
+	if err != nil {
+		return "", err
+	}
+	signature, err := c.identity.sign(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign the summary for %s: %v", month, err)
+	}
+	txID, _, err := c.Submit(ctx, "SignMonthlySummary", month, base64.StdEncoding.EncodeToString(signature), c.identity.KeyID)
+	return txID, err
+}
@@ -0,0 +1,51 @@
+package fabric
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CheckpointFile stores a listener's checkpoint in a file, for listeners
+// with no database of their own to keep it in
+type CheckpointFile string
+
+// Load returns the stored checkpoint, or nil when none has been saved
+func (f CheckpointFile) Load() (*Checkpoint, error) {
+	data, err := os.ReadFile(string(f))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var checkpoint Checkpoint
+	err = json.Unmarshal(data, &checkpoint)
+	if err != nil {
+		return nil, fmt.Errorf("malformed checkpoint %s: %v", string(f), err)
+	}
+	return &checkpoint, nil
+}
+
+// Save replaces the file atomically, so a crash leaves either the old
+// checkpoint or the new one
+func (f CheckpointFile) Save(checkpoint *Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(string(f)), ".checkpoint-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), string(f))
+}
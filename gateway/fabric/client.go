@@ -0,0 +1,343 @@
+// Package fabric is a minimal client for the Fabric Gateway service of a
+// peer. It builds and signs proposals and transactions for one chaincode on
+// one channel, which is all the off-chain eKYC services need.
+package fabric
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	mathrand "math/rand"
+	"os"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/gateway"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Config locates a gateway peer and the chaincode to call
+type Config struct {
+	Endpoint      string // host:port of a peer's gateway service
+	TLSCACertPath string // CA certificate of the peer's TLS certificate; empty disables TLS
+	ServerName    string // overrides the TLS server name, for peers addressed by IP
+	Channel       string
+	Chaincode     string
+	MVCCRetries   int // times Submit retries a transaction invalidated by an MVCC conflict
+}
+
+// Client submits and evaluates transactions on one chaincode
+type Client struct {
+	conn      *grpc.ClientConn
+	gateway   gateway.GatewayClient
+	identity  *Identity
+	creator   []byte
+	channel   string
+	chaincode string
+	retries   int
+}
+
+// CommitError reports a transaction that was ordered but failed validation
+type CommitError struct {
+	TxID string
+	Code peer.TxValidationCode
+}
+
+func (e *CommitError) Error() string {
+	return fmt.Sprintf("transaction %s failed to commit with status %s", e.TxID, e.Code)
+}
+
+// IsMVCCConflict reports whether err is a commit failure caused by another
+// transaction changing keys this one read
+func IsMVCCConflict(err error) bool {
+	commitErr, ok := err.(*CommitError)
+	return ok && (commitErr.Code == peer.TxValidationCode_MVCC_READ_CONFLICT || commitErr.Code == peer.TxValidationCode_PHANTOM_READ_CONFLICT)
+}
+
+// Dial connects to a gateway peer
+func Dial(config Config, identity *Identity) (*Client, error) {
+	transport := insecure.NewCredentials()
+	if config.TLSCACertPath != "" {
+		caPEM, err := os.ReadFile(config.TLSCACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS CA certificate: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in %s", config.TLSCACertPath)
+		}
+		transport = credentials.NewClientTLSFromCert(pool, config.ServerName)
+	}
+
+	conn, err := grpc.Dial(config.Endpoint, grpc.WithTransportCredentials(transport))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to gateway: %v", err)
+	}
+
+	creator, err := identity.serialize()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &Client{
+		conn:      conn,
+		gateway:   gateway.NewGatewayClient(conn),
+		identity:  identity,
+		creator:   creator,
+		channel:   config.Channel,
+		chaincode: config.Chaincode,
+		retries:   config.MVCCRetries,
+	}, nil
+}
+
+// Close closes the connection to the gateway
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Evaluate runs a transaction function on one peer without updating the ledger
+func (c *Client) Evaluate(ctx context.Context, function string, args ...string) ([]byte, error) {
+	txID, proposal, err := c.newProposal(function, args, withOperator(ctx, nil))
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.gateway.Evaluate(ctx, &gateway.EvaluateRequest{
+		TransactionId:       txID,
+		ChannelId:           c.channel,
+		ProposedTransaction: proposal,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate %s: %s", function, gatewayError(err))
+	}
+	return response.GetResult().GetPayload(), nil
+}
+
+// Submit endorses a transaction function, sends it for ordering and waits for
+// it to commit. A transaction invalidated by an MVCC conflict is endorsed
+// afresh against the newer state, up to Config.MVCCRetries times, so the
+// chaincode checks its rules again; the last attempt's transaction ID is
+// returned. A transaction that is ordered but invalidated returns a
+// *CommitError alongside its transaction ID.
+func (c *Client) Submit(ctx context.Context, function string, args ...string) (string, []byte, error) {
+	return c.submitRetrying(ctx, function, args, nil)
+}
+
+// transientFunc returns the transient data of a transaction, once its ID is
+// known
+type transientFunc func(txID string) (map[string][]byte, error)
+
+func (c *Client) submitRetrying(ctx context.Context, function string, args []string, transient transientFunc) (string, []byte, error) {
+	for attempt := 0; ; attempt++ {
+		txID, result, err := c.submit(ctx, function, args, transient)
+		if !IsMVCCConflict(err) || attempt >= c.retries {
+			return txID, result, err
+		}
+		select {
+		case <-ctx.Done():
+			return txID, nil, err
+		case <-time.After(retryDelay(attempt)):
+		}
+	}
+}
+
+// retryDelay spreads out the retries of transactions that conflicted with
+// each other, so they do not collide again in the next block
+func retryDelay(attempt int) time.Duration {
+	base := 50 * time.Millisecond << attempt
+	if base > 2*time.Second {
+		base = 2 * time.Second
+	}
+	return base/2 + time.Duration(mathrand.Int63n(int64(base)))
+}
+
+func (c *Client) submit(ctx context.Context, function string, args []string, transient transientFunc) (string, []byte, error) {
+	txID, proposal, err := c.newProposal(function, args, withOperator(ctx, transient))
+	if err != nil {
+		return "", nil, err
+	}
+
+	endorsed, err := c.gateway.Endorse(ctx, &gateway.EndorseRequest{
+		TransactionId:       txID,
+		ChannelId:           c.channel,
+		ProposedTransaction: proposal,
+	})
+	if err != nil {
+		return txID, nil, fmt.Errorf("failed to endorse %s: %s", function, gatewayError(err))
+	}
+	envelope := endorsed.GetPreparedTransaction()
+	result, err := transactionResult(envelope)
+	if err != nil {
+		return txID, nil, err
+	}
+
+	envelope.Signature, err = c.identity.sign(envelope.Payload)
+	if err != nil {
+		return txID, nil, err
+	}
+	_, err = c.gateway.Submit(ctx, &gateway.SubmitRequest{
+		TransactionId:       txID,
+		ChannelId:           c.channel,
+		PreparedTransaction: envelope,
+	})
+	if err != nil {
+		return txID, nil, fmt.Errorf("failed to submit %s: %s", function, gatewayError(err))
+	}
+
+	code, err := c.commitStatus(ctx, txID)
+	if err != nil {
+		return txID, nil, err
+	}
+	if code != peer.TxValidationCode_VALID {
+		return txID, nil, &CommitError{TxID: txID, Code: code}
+	}
+	return txID, result, nil
+}
+
+// gatewayError describes a failed gateway call. The gateway puts the errors
+// of the peers and orderers it called, such as the chaincode's own message,
+// in the status details rather than the message.
+func gatewayError(err error) string {
+	message := err.Error()
+	for _, detail := range status.Convert(err).Details() {
+		if detail, ok := detail.(*gateway.ErrorDetail); ok {
+			message += fmt.Sprintf("; %s (%s): %s", detail.Address, detail.MspId, detail.Message)
+		}
+	}
+	return message
+}
+
+// commitStatus waits for a submitted transaction to commit and returns its validation code
+func (c *Client) commitStatus(ctx context.Context, txID string) (peer.TxValidationCode, error) {
+	request, err := proto.Marshal(&gateway.CommitStatusRequest{
+		TransactionId: txID,
+		ChannelId:     c.channel,
+		Identity:      c.creator,
+	})
+	if err != nil {
+		return 0, err
+	}
+	signature, err := c.identity.sign(request)
+	if err != nil {
+		return 0, err
+	}
+
+	status, err := c.gateway.CommitStatus(ctx, &gateway.SignedCommitStatusRequest{Request: request, Signature: signature})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get commit status of %s: %v", txID, err)
+	}
+	return status.GetResult(), nil
+}
+
+// newProposal builds and signs a proposal to invoke a transaction function,
+// with the transient data transient returns for its transaction ID
+func (c *Client) newProposal(function string, args []string, transient transientFunc) (string, *peer.SignedProposal, error) {
+	nonce := make([]byte, 24)
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return "", nil, err
+	}
+	digest := sha256.Sum256(append(nonce, c.creator...))
+	txID := hex.EncodeToString(digest[:])
+
+	extension, err := proto.Marshal(&peer.ChaincodeHeaderExtension{ChaincodeId: &peer.ChaincodeID{Name: c.chaincode}})
+	if err != nil {
+		return "", nil, err
+	}
+	channelHeader, err := proto.Marshal(&common.ChannelHeader{
+		Type:      int32(common.HeaderType_ENDORSER_TRANSACTION),
+		TxId:      txID,
+		ChannelId: c.channel,
+		Timestamp: timestamppb.Now(),
+		Extension: extension,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	signatureHeader, err := proto.Marshal(&common.SignatureHeader{Creator: c.creator, Nonce: nonce})
+	if err != nil {
+		return "", nil, err
+	}
+	header, err := proto.Marshal(&common.Header{ChannelHeader: channelHeader, SignatureHeader: signatureHeader})
+	if err != nil {
+		return "", nil, err
+	}
+
+	input := [][]byte{[]byte(function)}
+	for _, arg := range args {
+		input = append(input, []byte(arg))
+	}
+	invocation, err := proto.Marshal(&peer.ChaincodeInvocationSpec{ChaincodeSpec: &peer.ChaincodeSpec{
+		Type:        peer.ChaincodeSpec_GOLANG,
+		ChaincodeId: &peer.ChaincodeID{Name: c.chaincode},
+		Input:       &peer.ChaincodeInput{Args: input},
+	}})
+	if err != nil {
+		return "", nil, err
+	}
+	var transientMap map[string][]byte
+	if transient != nil {
+		transientMap, err = transient(txID)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	payload, err := proto.Marshal(&peer.ChaincodeProposalPayload{Input: invocation, TransientMap: transientMap})
+	if err != nil {
+		return "", nil, err
+	}
+
+	proposal, err := proto.Marshal(&peer.Proposal{Header: header, Payload: payload})
+	if err != nil {
+		return "", nil, err
+	}
+	signature, err := c.identity.sign(proposal)
+	if err != nil {
+		return "", nil, err
+	}
+	return txID, &peer.SignedProposal{ProposalBytes: proposal, Signature: signature}, nil
+}
+
+// transactionResult extracts the chaincode response payload from a prepared transaction
+func transactionResult(envelope *common.Envelope) ([]byte, error) {
+	var payload common.Payload
+	err := proto.Unmarshal(envelope.GetPayload(), &payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prepared transaction: %v", err)
+	}
+	var transaction peer.Transaction
+	err = proto.Unmarshal(payload.Data, &transaction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prepared transaction: %v", err)
+	}
+	if len(transaction.Actions) == 0 {
+		return nil, fmt.Errorf("prepared transaction has no actions")
+	}
+
+	var actionPayload peer.ChaincodeActionPayload
+	err = proto.Unmarshal(transaction.Actions[0].Payload, &actionPayload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prepared transaction: %v", err)
+	}
+	var responsePayload peer.ProposalResponsePayload
+	err = proto.Unmarshal(actionPayload.GetAction().GetProposalResponsePayload(), &responsePayload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prepared transaction: %v", err)
+	}
+	var action peer.ChaincodeAction
+	err = proto.Unmarshal(responsePayload.Extension, &action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prepared transaction: %v", err)
+	}
+	return action.GetResponse().GetPayload(), nil
+}
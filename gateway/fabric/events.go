@@ -0,0 +1,88 @@
+package fabric
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/gateway"
+	"github.com/hyperledger/fabric-protos-go/orderer"
+)
+
+// Event is a chaincode event emitted by a committed transaction
+type Event struct {
+	BlockNumber uint64
+	TxID        string
+	Name        string
+	Payload     []byte
+}
+
+// Checkpoint marks the last event a listener has processed. Resuming from a
+// checkpoint replays the rest of its block, skipping events up to and
+// including its transaction.
+type Checkpoint struct {
+	BlockNumber uint64 `json:"blockNumber"`
+	TxID        string `json:"txId"`
+}
+
+// EventStream delivers chaincode events in commit order
+type EventStream struct {
+	stream  gateway.Gateway_ChaincodeEventsClient
+	pending []*Event
+}
+
+// ChaincodeEvents opens a stream of the chaincode's events. A nil checkpoint
+// starts from the next block to commit.
+func (c *Client) ChaincodeEvents(ctx context.Context, checkpoint *Checkpoint) (*EventStream, error) {
+	request := &gateway.ChaincodeEventsRequest{
+		ChannelId:   c.channel,
+		ChaincodeId: c.chaincode,
+		Identity:    c.creator,
+		StartPosition: &orderer.SeekPosition{Type: &orderer.SeekPosition_NextCommit{
+			NextCommit: &orderer.SeekNextCommit{},
+		}},
+	}
+	if checkpoint != nil {
+		request.StartPosition = &orderer.SeekPosition{Type: &orderer.SeekPosition_Specified{
+			Specified: &orderer.SeekSpecified{Number: checkpoint.BlockNumber},
+		}}
+		request.AfterTransactionId = checkpoint.TxID
+	}
+
+	requestBytes, err := proto.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := c.identity.sign(requestBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := c.gateway.ChaincodeEvents(ctx, &gateway.SignedChaincodeEventsRequest{Request: requestBytes, Signature: signature})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open chaincode event stream: %v", err)
+	}
+	return &EventStream{stream: stream}, nil
+}
+
+// Next blocks until the next event arrives or the stream fails
+func (s *EventStream) Next() (*Event, error) {
+	for len(s.pending) == 0 {
+		response, err := s.stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range response.Events {
+			s.pending = append(s.pending, &Event{
+				BlockNumber: response.BlockNumber,
+				TxID:        event.TxId,
+				Name:        event.EventName,
+				Payload:     event.Payload,
+			})
+		}
+	}
+
+	event := s.pending[0]
+	s.pending = s.pending[1:]
+	return event, nil
+}
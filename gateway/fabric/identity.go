@@ -0,0 +1,120 @@
+package fabric
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/msp"
+)
+
+// Identity is an X.509 client identity that signs proposals and transactions
+type Identity struct {
+	MSPID       string
+	Certificate []byte // PEM-encoded enrolment certificate
+	KeyID       string // identifies a key held outside the process, such as a PKCS #11 URI; empty for key files
+	key         crypto.Signer
+}
+
+// PKCS11Config locates an ECDSA private key on a PKCS #11 token, such as an
+// HSM or a smart card
+type PKCS11Config struct {
+	Module     string // path of the token's PKCS #11 library
+	TokenLabel string
+	PIN        string // the token's user PIN
+	KeyLabel   string // CKA_LABEL of the private key
+}
+
+// LoadIdentity reads an enrolment certificate and its PKCS#8 or SEC 1 private key from PEM files
+func LoadIdentity(mspID string, certPath string, keyPath string) (*Identity, error) {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %v", err)
+	}
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %v", err)
+	}
+
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("private key %s is not PEM encoded", keyPath)
+	}
+	var key *ecdsa.PrivateKey
+	if parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		ecKey, ok := parsed.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("private key %s is not an ECDSA key", keyPath)
+		}
+		key = ecKey
+	} else {
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %v", err)
+		}
+	}
+
+	return NewIdentity(mspID, certPEM, key, "")
+}
+
+// NewIdentity returns an identity signing with any ECDSA crypto.Signer
+// whose key is the certificate's, such as one backed by a remote key
+// service. keyID is recorded with the attestations the identity signs.
+func NewIdentity(mspID string, certPEM []byte, signer crypto.Signer, keyID string) (*Identity, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, fmt.Errorf("certificate is not PEM encoded")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %v", err)
+	}
+	public, ok := signer.Public().(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key is not an ECDSA key")
+	}
+	if !public.Equal(cert.PublicKey) {
+		return nil, fmt.Errorf("signing key does not match the certificate of %s", cert.Subject)
+	}
+	return &Identity{MSPID: mspID, Certificate: certPEM, KeyID: keyID, key: signer}, nil
+}
+
+// serialize returns the identity as the peer expects it in signature headers
+func (id *Identity) serialize() ([]byte, error) {
+	return proto.Marshal(&msp.SerializedIdentity{Mspid: id.MSPID, IdBytes: id.Certificate})
+}
+
+// sign returns a low-S ECDSA signature over the SHA-256 digest of message,
+// the only form Fabric accepts
+func (id *Identity) sign(message []byte) ([]byte, error) {
+	digest := sha256.Sum256(message)
+	signature, err := id.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	var rs struct{ R, S *big.Int }
+	_, err = asn1.Unmarshal(signature, &rs)
+	if err != nil {
+		return nil, fmt.Errorf("signer returned a malformed ECDSA signature: %v", err)
+	}
+
+	curve := id.key.Public().(*ecdsa.PublicKey).Curve
+	halfOrder := new(big.Int).Rsh(curveOrder(curve), 1)
+	if rs.S.Cmp(halfOrder) > 0 {
+		rs.S.Sub(curveOrder(curve), rs.S)
+	}
+	return asn1.Marshal(rs)
+}
+
+func curveOrder(curve elliptic.Curve) *big.Int {
+	return curve.Params().N
+}
@@ -0,0 +1,68 @@
+package fabric
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	minListenBackoff = time.Second
+	maxListenBackoff = time.Minute
+)
+
+// Listen passes each chaincode event to handle, in commit order, until ctx is
+// done. When the stream fails or handle returns an error the stream is
+// reopened after a backoff from the last event handled successfully, so every
+// event is handled at least once. A nil checkpoint starts from the next block
+// to commit.
+func Listen(ctx context.Context, client *Client, checkpoint *Checkpoint, handle func(context.Context, *Event) error) error {
+	l := &listener{client: client, checkpoint: checkpoint, handle: handle, backoff: minListenBackoff}
+	for {
+		err := l.stream(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Printf("chaincode event stream interrupted, reconnecting in %s: %v", l.backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(l.backoff):
+		}
+		l.backoff *= 2
+		if l.backoff > maxListenBackoff {
+			l.backoff = maxListenBackoff
+		}
+	}
+}
+
+type listener struct {
+	client     *Client
+	checkpoint *Checkpoint
+	handle     func(context.Context, *Event) error
+	backoff    time.Duration
+}
+
+// stream handles events from one stream until it fails
+func (l *listener) stream(ctx context.Context) error {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := l.client.ChaincodeEvents(streamCtx, l.checkpoint)
+	if err != nil {
+		return err
+	}
+	for {
+		event, err := stream.Next()
+		if err != nil {
+			return err
+		}
+		err = l.handle(ctx, event)
+		if err != nil {
+			return err
+		}
+		l.checkpoint = &Checkpoint{BlockNumber: event.BlockNumber, TxID: event.TxID}
+		l.backoff = minListenBackoff
+	}
+}
@@ -0,0 +1,42 @@
+package fabric
+
+import "context"
+
+// operatorTransientKey is the transient field the chaincode reads the
+// operator a gateway identity acts for from
+const operatorTransientKey = "operator"
+
+type operatorKey struct{}
+
+// WithOperator returns a context under which the client's transactions name
+// operator as the person the gateway acts for. The chaincode records a
+// client identity carrying the kyc.gateway attribute as
+// "<identity>#<operator>", so history and dual approval tell the operators
+// of one gateway identity apart. Identities without the attribute are
+// recorded as themselves whatever the context says.
+func WithOperator(ctx context.Context, operator string) context.Context {
+	return context.WithValue(ctx, operatorKey{}, operator)
+}
+
+// withOperator adds the operator ctx names, if any, to the transient data
+// transient returns
+func withOperator(ctx context.Context, transient transientFunc) transientFunc {
+	operator, _ := ctx.Value(operatorKey{}).(string)
+	if operator == "" {
+		return transient
+	}
+	return func(txID string) (map[string][]byte, error) {
+		transientMap := map[string][]byte{}
+		if transient != nil {
+			inner, err := transient(txID)
+			if err != nil {
+				return nil, err
+			}
+			for key, value := range inner {
+				transientMap[key] = value
+			}
+		}
+		transientMap[operatorTransientKey] = []byte(operator)
+		return transientMap, nil
+	}
+}
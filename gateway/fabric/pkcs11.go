@@ -0,0 +1,303 @@
+//go:build pkcs11 && cgo
+
+package fabric
+
+/*
+#cgo linux LDFLAGS: -ldl
+
+#include <dlfcn.h>
+#include <stdlib.h>
+#include <string.h>
+
+// The few PKCS #11 2.40 types the signer needs, declared here rather than
+// taken from a vendor's headers
+typedef unsigned long CK_ULONG;
+typedef unsigned char CK_BYTE;
+typedef CK_ULONG CK_RV;
+
+typedef struct {
+	CK_BYTE major;
+	CK_BYTE minor;
+} CK_VERSION;
+
+typedef struct {
+	CK_BYTE label[32];
+	CK_BYTE manufacturerID[32];
+	CK_BYTE model[16];
+	CK_BYTE serialNumber[16];
+	CK_ULONG flags;
+	CK_ULONG counts[10];
+	CK_VERSION hardwareVersion;
+	CK_VERSION firmwareVersion;
+	CK_BYTE utcTime[16];
+} CK_TOKEN_INFO;
+
+typedef struct {
+	CK_ULONG type;
+	void *pValue;
+	CK_ULONG ulValueLen;
+} CK_ATTRIBUTE;
+
+typedef struct {
+	CK_ULONG mechanism;
+	void *pParameter;
+	CK_ULONG ulParameterLen;
+} CK_MECHANISM;
+
+typedef struct {
+	void *CreateMutex;
+	void *DestroyMutex;
+	void *LockMutex;
+	void *UnlockMutex;
+	CK_ULONG flags;
+	void *pReserved;
+} CK_C_INITIALIZE_ARGS;
+
+// CK_FUNCTION_LIST up to C_Sign, in the order the standard fixes
+typedef struct {
+	CK_VERSION version;
+	void *fn[44];
+} CK_FUNCTION_LIST;
+
+enum {
+	fnInitialize = 0,
+	fnGetSlotList = 4,
+	fnGetTokenInfo = 6,
+	fnOpenSession = 12,
+	fnLogin = 18,
+	fnFindObjectsInit = 26,
+	fnFindObjects = 27,
+	fnFindObjectsFinal = 28,
+	fnSignInit = 42,
+	fnSign = 43,
+};
+
+#define CKR_OK 0x0UL
+#define CKR_USER_ALREADY_LOGGED_IN 0x100UL
+#define CKR_CRYPTOKI_ALREADY_INITIALIZED 0x191UL
+#define CKF_OS_LOCKING_OK 0x2UL
+#define CKF_SERIAL_SESSION 0x4UL
+#define CKU_USER 1UL
+#define CKA_CLASS 0x0UL
+#define CKA_LABEL 0x3UL
+#define CKO_PRIVATE_KEY 0x3UL
+#define CKM_ECDSA 0x1041UL
+
+static CK_RV p11_open(const char *path, CK_FUNCTION_LIST **list, char **problem) {
+	void *module = dlopen(path, RTLD_NOW | RTLD_LOCAL);
+	if (module == NULL) {
+		*problem = dlerror();
+		return ~0UL;
+	}
+	CK_RV (*getFunctionList)(CK_FUNCTION_LIST **) = dlsym(module, "C_GetFunctionList");
+	if (getFunctionList == NULL) {
+		*problem = dlerror();
+		return ~0UL;
+	}
+	CK_RV rv = getFunctionList(list);
+	if (rv != CKR_OK) {
+		return rv;
+	}
+	CK_C_INITIALIZE_ARGS args;
+	memset(&args, 0, sizeof args);
+	args.flags = CKF_OS_LOCKING_OK;
+	rv = ((CK_RV (*)(void *))(*list)->fn[fnInitialize])(&args);
+	return rv == CKR_CRYPTOKI_ALREADY_INITIALIZED ? CKR_OK : rv;
+}
+
+// p11_find_slot finds the slot holding the token labelled label, padded
+// with spaces to 32 bytes
+static CK_RV p11_find_slot(CK_FUNCTION_LIST *list, const CK_BYTE *label, CK_ULONG *slot, int *found) {
+	CK_ULONG count = 0;
+	CK_RV rv = ((CK_RV (*)(CK_BYTE, CK_ULONG *, CK_ULONG *))list->fn[fnGetSlotList])(1, NULL, &count);
+	if (rv != CKR_OK || count == 0) {
+		return rv;
+	}
+	CK_ULONG *slots = calloc(count, sizeof(CK_ULONG));
+	rv = ((CK_RV (*)(CK_BYTE, CK_ULONG *, CK_ULONG *))list->fn[fnGetSlotList])(1, slots, &count);
+	for (CK_ULONG i = 0; rv == CKR_OK && i < count; i++) {
+		CK_TOKEN_INFO info;
+		rv = ((CK_RV (*)(CK_ULONG, CK_TOKEN_INFO *))list->fn[fnGetTokenInfo])(slots[i], &info);
+		if (rv == CKR_OK && memcmp(info.label, label, 32) == 0) {
+			*slot = slots[i];
+			*found = 1;
+			break;
+		}
+	}
+	free(slots);
+	return rv;
+}
+
+static CK_RV p11_login(CK_FUNCTION_LIST *list, CK_ULONG slot, CK_BYTE *pin, CK_ULONG pinLen, CK_ULONG *session) {
+	CK_RV rv = ((CK_RV (*)(CK_ULONG, CK_ULONG, void *, void *, CK_ULONG *))list->fn[fnOpenSession])(slot, CKF_SERIAL_SESSION, NULL, NULL, session);
+	if (rv != CKR_OK) {
+		return rv;
+	}
+	rv = ((CK_RV (*)(CK_ULONG, CK_ULONG, CK_BYTE *, CK_ULONG))list->fn[fnLogin])(*session, CKU_USER, pin, pinLen);
+	return rv == CKR_USER_ALREADY_LOGGED_IN ? CKR_OK : rv;
+}
+
+static CK_RV p11_find_key(CK_FUNCTION_LIST *list, CK_ULONG session, CK_BYTE *label, CK_ULONG labelLen, CK_ULONG *key, CK_ULONG *found) {
+	CK_ULONG class = CKO_PRIVATE_KEY;
+	CK_ATTRIBUTE template[2] = {
+		{CKA_CLASS, &class, sizeof class},
+		{CKA_LABEL, label, labelLen},
+	};
+	CK_RV rv = ((CK_RV (*)(CK_ULONG, CK_ATTRIBUTE *, CK_ULONG))list->fn[fnFindObjectsInit])(session, template, 2);
+	if (rv != CKR_OK) {
+		return rv;
+	}
+	rv = ((CK_RV (*)(CK_ULONG, CK_ULONG *, CK_ULONG, CK_ULONG *))list->fn[fnFindObjects])(session, key, 1, found);
+	CK_RV final = ((CK_RV (*)(CK_ULONG))list->fn[fnFindObjectsFinal])(session);
+	return rv != CKR_OK ? rv : final;
+}
+
+static CK_RV p11_sign(CK_FUNCTION_LIST *list, CK_ULONG session, CK_ULONG key, CK_BYTE *digest, CK_ULONG digestLen, CK_BYTE *signature, CK_ULONG *signatureLen) {
+	CK_MECHANISM mechanism = {CKM_ECDSA, NULL, 0};
+	CK_RV rv = ((CK_RV (*)(CK_ULONG, CK_MECHANISM *, CK_ULONG))list->fn[fnSignInit])(session, &mechanism, key);
+	if (rv != CKR_OK) {
+		return rv;
+	}
+	return ((CK_RV (*)(CK_ULONG, CK_BYTE *, CK_ULONG, CK_BYTE *, CK_ULONG *))list->fn[fnSign])(session, digest, digestLen, signature, signatureLen);
+}
+*/
+import "C"
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// pkcs11Signer signs with an ECDSA private key that never leaves its token.
+// A PKCS #11 session runs one operation at a time, so signing is serialised.
+type pkcs11Signer struct {
+	mu      sync.Mutex
+	list    *C.CK_FUNCTION_LIST
+	session C.CK_ULONG
+	key     C.CK_ULONG
+	public  *ecdsa.PublicKey
+}
+
+// LoadPKCS11Identity reads an enrolment certificate from a PEM file and
+// signs with its private key on a PKCS #11 token. The identity's KeyID is
+// the key's PKCS #11 URI.
+func LoadPKCS11Identity(mspID string, certPath string, config PKCS11Config) (*Identity, error) {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %v", err)
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, fmt.Errorf("certificate %s is not PEM encoded", certPath)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %v", err)
+	}
+	public, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("certificate %s does not hold an ECDSA key", certPath)
+	}
+	if len(config.TokenLabel) > 32 {
+		return nil, fmt.Errorf("token label %q is longer than 32 bytes", config.TokenLabel)
+	}
+
+	signer := &pkcs11Signer{public: public}
+	module := C.CString(config.Module)
+	defer C.free(unsafe.Pointer(module))
+	var problem *C.char
+	rv := C.p11_open(module, &signer.list, &problem)
+	if problem != nil {
+		return nil, fmt.Errorf("failed to load PKCS #11 module %s: %s", config.Module, C.GoString(problem))
+	}
+	if rv != C.CKR_OK {
+		return nil, pkcs11Error("C_Initialize", rv)
+	}
+
+	label := C.CBytes([]byte(config.TokenLabel + strings.Repeat(" ", 32-len(config.TokenLabel))))
+	defer C.free(label)
+	var slot C.CK_ULONG
+	var found C.int
+	rv = C.p11_find_slot(signer.list, (*C.CK_BYTE)(label), &slot, &found)
+	if rv != C.CKR_OK {
+		return nil, pkcs11Error("C_GetTokenInfo", rv)
+	}
+	if found == 0 {
+		return nil, fmt.Errorf("no PKCS #11 token is labelled %q", config.TokenLabel)
+	}
+
+	pin := C.CBytes([]byte(config.PIN))
+	defer C.free(pin)
+	rv = C.p11_login(signer.list, slot, (*C.CK_BYTE)(pin), C.CK_ULONG(len(config.PIN)), &signer.session)
+	if rv != C.CKR_OK {
+		return nil, pkcs11Error("C_Login", rv)
+	}
+	keyLabel := C.CBytes([]byte(config.KeyLabel))
+	defer C.free(keyLabel)
+	var keys C.CK_ULONG
+	rv = C.p11_find_key(signer.list, signer.session, (*C.CK_BYTE)(keyLabel), C.CK_ULONG(len(config.KeyLabel)), &signer.key, &keys)
+	if rv != C.CKR_OK {
+		return nil, pkcs11Error("C_FindObjects", rv)
+	}
+	if keys == 0 {
+		return nil, fmt.Errorf("token %q holds no private key labelled %q", config.TokenLabel, config.KeyLabel)
+	}
+
+	// a trial signature proves the token's key is the certificate's
+	digest := sha256.Sum256([]byte("ekyc-gateway key check"))
+	signature, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	if !ecdsa.VerifyASN1(public, digest[:], signature) {
+		return nil, fmt.Errorf("key %q on token %q does not match the certificate of %s", config.KeyLabel, config.TokenLabel, cert.Subject)
+	}
+
+	keyID := "pkcs11:token=" + url.PathEscape(config.TokenLabel) + ";object=" + url.PathEscape(config.KeyLabel) + ";type=private"
+	return NewIdentity(mspID, certPEM, signer, keyID)
+}
+
+// Public implements crypto.Signer
+func (s *pkcs11Signer) Public() crypto.PublicKey {
+	return s.public
+}
+
+// Sign implements crypto.Signer, returning an ASN.1 signature of digest as
+// *ecdsa.PrivateKey does rather than the token's raw r and s
+func (s *pkcs11Signer) Sign(_ io.Reader, digest []byte, _ crypto.SignerOpts) ([]byte, error) {
+	size := (s.public.Curve.Params().BitSize + 7) / 8
+	in := C.CBytes(digest)
+	defer C.free(in)
+	out := C.malloc(C.size_t(2 * size))
+	defer C.free(out)
+	outLen := C.CK_ULONG(2 * size)
+
+	s.mu.Lock()
+	rv := C.p11_sign(s.list, s.session, s.key, (*C.CK_BYTE)(in), C.CK_ULONG(len(digest)), (*C.CK_BYTE)(out), &outLen)
+	s.mu.Unlock()
+	if rv != C.CKR_OK {
+		return nil, pkcs11Error("C_Sign", rv)
+	}
+	raw := C.GoBytes(out, C.int(outLen))
+	if len(raw) != 2*size {
+		return nil, fmt.Errorf("token returned a %d-byte ECDSA signature, expected %d", len(raw), 2*size)
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{new(big.Int).SetBytes(raw[:size]), new(big.Int).SetBytes(raw[size:])})
+}
+
+func pkcs11Error(function string, rv C.CK_RV) error {
+	return fmt.Errorf("PKCS #11 %s failed: CKR 0x%x", function, uint64(rv))
+}
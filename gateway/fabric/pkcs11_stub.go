@@ -0,0 +1,12 @@
+//go:build !pkcs11 || !cgo
+
+package fabric
+
+import "fmt"
+
+// LoadPKCS11Identity reads an enrolment certificate from a PEM file and
+// signs with its private key on a PKCS #11 token. PKCS #11 support needs
+// cgo and the pkcs11 build tag; without them it always fails.
+func LoadPKCS11Identity(mspID string, certPath string, config PKCS11Config) (*Identity, error) {
+	return nil, fmt.Errorf("this binary was built without PKCS #11 support; rebuild with cgo and -tags pkcs11")
+}
@@ -0,0 +1,17 @@
+module ekyc-gateway
+
+go 1.21
+
+require (
+	github.com/golang/protobuf v1.5.3
+	github.com/hyperledger/fabric-protos-go v0.3.0
+	google.golang.org/grpc v1.54.0
+	google.golang.org/protobuf v1.30.0
+)
+
+require (
+	golang.org/x/net v0.10.0 // indirect
+	golang.org/x/sys v0.8.0 // indirect
+	golang.org/x/text v0.9.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230525234030-28d5490b6b19 // indirect
+)
@@ -0,0 +1,290 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// executor runs one validated operation
+type executor struct {
+	schema    *Schema
+	doc       *document
+	variables map[string]interface{}
+	errors    []*Error
+}
+
+// path is the response path of a value, innermost element last
+type path struct {
+	parent *path
+	key    interface{} // response key or list index
+}
+
+func (p *path) slice() []interface{} {
+	if p == nil {
+		return nil
+	}
+	return append(p.parent.slice(), p.key)
+}
+
+// fieldGroup is the fields of a selection sharing one response key
+type fieldGroup struct {
+	key    string
+	fields []*field
+}
+
+// orderedMap is a response object, which keeps its keys in selection order
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		keyJSON, _ := json.Marshal(key)
+		buf.Write(keyJSON)
+		buf.WriteByte(':')
+		valueJSON, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(valueJSON)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// executeSelection resolves a selection set on an object, returning nil when
+// a non-null field failed and the object itself must be null
+func (e *executor) executeSelection(ctx context.Context, object *Object, source interface{}, selections []selection, at *path) *orderedMap {
+	result := &orderedMap{values: map[string]interface{}{}}
+	for _, group := range e.collectFields(object, selections, nil, map[string]bool{}) {
+		value, ok := e.executeField(ctx, object, source, group, &path{parent: at, key: group.key})
+		if !ok {
+			return nil
+		}
+		result.keys = append(result.keys, group.key)
+		result.values[group.key] = value
+	}
+	return result
+}
+
+// collectFields groups a selection set's fields by response key, expanding
+// fragments and applying @skip and @include
+func (e *executor) collectFields(object *Object, selections []selection, groups []*fieldGroup, visited map[string]bool) []*fieldGroup {
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *field:
+			if !e.included(sel.directives) {
+				continue
+			}
+			key := sel.responseKey()
+			found := false
+			for _, group := range groups {
+				if group.key == key {
+					group.fields = append(group.fields, sel)
+					found = true
+					break
+				}
+			}
+			if !found {
+				groups = append(groups, &fieldGroup{key: key, fields: []*field{sel}})
+			}
+		case *fragmentSpread:
+			frag := e.doc.fragments[sel.name]
+			if visited[sel.name] || !e.included(sel.directives) || frag.typeCondition != object.Name {
+				continue
+			}
+			visited[sel.name] = true
+			groups = e.collectFields(object, frag.selection, groups, visited)
+		case *inlineFragment:
+			if !e.included(sel.directives) || (sel.typeCondition != "" && sel.typeCondition != object.Name) {
+				continue
+			}
+			groups = e.collectFields(object, sel.selection, groups, visited)
+		}
+	}
+	return groups
+}
+
+// included evaluates @skip(if:) and @include(if:)
+func (e *executor) included(directives []*directive) bool {
+	for _, d := range directives {
+		if d.name != "skip" && d.name != "include" {
+			continue
+		}
+		condition := false
+		for _, arg := range d.arguments {
+			if arg.name == "if" {
+				value, _ := coerceLiteral(arg.value, &NonNull{Of: Boolean}, e.variables)
+				condition, _ = value.(bool)
+			}
+		}
+		if (d.name == "skip") == condition {
+			return false
+		}
+	}
+	return true
+}
+
+// executeField resolves one response key. It reports false when the value is
+// null in a non-null position, so the enclosing object must be null too.
+func (e *executor) executeField(ctx context.Context, object *Object, source interface{}, group *fieldGroup, at *path) (interface{}, bool) {
+	first := group.fields[0]
+	def := e.schema.fieldDef(object, first.name)
+
+	args, err := coerceArguments(def, first.arguments, e.variables)
+	if err != nil {
+		e.fail(first, at, err)
+		return nil, !isNonNull(def.Type)
+	}
+
+	var subselection []selection
+	for _, f := range group.fields {
+		subselection = append(subselection, f.selection...)
+	}
+	params := Params{Source: source, Args: args}
+	if child, ok := namedType(def.Type).(*Object); ok {
+		for _, childGroup := range e.collectFields(child, subselection, nil, map[string]bool{}) {
+			params.Selected = append(params.Selected, childGroup.fields[0].name)
+		}
+	}
+
+	var resolved interface{}
+	if def.Resolve != nil {
+		resolved, err = def.Resolve(ctx, params)
+	} else if fields, ok := source.(map[string]interface{}); ok {
+		resolved = fields[def.Name]
+	}
+	if err != nil {
+		e.fail(first, at, err)
+		return nil, !isNonNull(def.Type)
+	}
+	return e.completeValue(ctx, def.Type, first, subselection, resolved, at)
+}
+
+// completeValue shapes a resolved value to its field type. A null in a
+// non-null position is reported as false and absorbed by the nearest
+// nullable position above it.
+func (e *executor) completeValue(ctx context.Context, t Type, f *field, subselection []selection, resolved interface{}, at *path) (interface{}, bool) {
+	if nonNull, ok := t.(*NonNull); ok {
+		value, ok := e.completeNullable(ctx, nonNull.Of, f, subselection, resolved, at)
+		if !ok {
+			return nil, false
+		}
+		if value == nil {
+			e.fail(f, at, fmt.Errorf("cannot return null for non-null field %s", f.name))
+			return nil, false
+		}
+		return value, true
+	}
+
+	value, ok := e.completeNullable(ctx, t, f, subselection, resolved, at)
+	if !ok {
+		return nil, true
+	}
+	return value, true
+}
+
+// completeNullable completes a value whose type is not non-null; false means
+// a nested non-null position was null
+func (e *executor) completeNullable(ctx context.Context, t Type, f *field, subselection []selection, resolved interface{}, at *path) (interface{}, bool) {
+	if isNil(resolved) {
+		return nil, true
+	}
+
+	switch t := t.(type) {
+	case *List:
+		items := reflect.ValueOf(resolved)
+		if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+			e.fail(f, at, fmt.Errorf("expected a list for field %s", f.name))
+			return nil, false
+		}
+		completed := make([]interface{}, items.Len())
+		for i := range completed {
+			value, ok := e.completeValue(ctx, t.Of, f, subselection, items.Index(i).Interface(), &path{parent: at, key: i})
+			if !ok {
+				return nil, false
+			}
+			completed[i] = value
+		}
+		return completed, true
+	case *Object:
+		result := e.executeSelection(ctx, t, resolved, subselection, at)
+		return result, result != nil
+	case *Scalar:
+		value, ok := t.Serialize(resolved)
+		if !ok {
+			e.fail(f, at, fmt.Errorf("%s cannot represent %v", t.Name, resolved))
+			return nil, false
+		}
+		return value, true
+	case *Enum:
+		name, ok := resolved.(string)
+		if !ok || !t.has(name) {
+			e.fail(f, at, fmt.Errorf("%s cannot represent %v", t.Name, resolved))
+			return nil, false
+		}
+		return name, true
+	}
+	return nil, false
+}
+
+func (e *executor) fail(f *field, at *path, err error) {
+	e.errors = append(e.errors, &Error{Message: err.Error(), Locations: []Location{f.loc}, Path: at.slice()})
+}
+
+// coerceArguments converts a field's argument literals, applying defaults
+func coerceArguments(def *Field, arguments []*argument, variables map[string]interface{}) (map[string]interface{}, error) {
+	args := map[string]interface{}{}
+	for _, argDef := range def.Args {
+		var literal value
+		for _, arg := range arguments {
+			if arg.name == argDef.Name {
+				literal = arg.value
+			}
+		}
+
+		if literal != nil {
+			value, err := coerceLiteral(literal, argDef.Type, variables)
+			if err == nil {
+				args[argDef.Name] = value
+				continue
+			}
+			if err != errAbsent {
+				return nil, fmt.Errorf("argument %s: %v", argDef.Name, err)
+			}
+		}
+		if argDef.Default != nil {
+			args[argDef.Name] = argDef.Default
+		} else if isNonNull(argDef.Type) {
+			return nil, fmt.Errorf("argument %s of type %s is required", argDef.Name, argDef.Type)
+		}
+	}
+	return args, nil
+}
+
+func isNonNull(t Type) bool {
+	_, ok := t.(*NonNull)
+	return ok
+}
+
+// isNil reports nil, including nil pointers, maps and slices in an interface
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
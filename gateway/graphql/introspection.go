@@ -0,0 +1,280 @@
+package graphql
+
+import (
+	"context"
+	"sort"
+)
+
+// fieldDef returns the definition of a field selected on object, including
+// the meta fields every schema offers. Validation has already rejected
+// unknown fields.
+func (s *Schema) fieldDef(object *Object, name string) *Field {
+	switch {
+	case name == "__typename":
+		return &Field{Name: name, Type: &NonNull{Of: String}, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			return object.Name, nil
+		}}
+	case name == "__schema" && object == s.Query:
+		return &Field{Name: name, Type: &NonNull{Of: schemaType}, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			return s, nil
+		}}
+	case name == "__type" && object == s.Query:
+		return &Field{Name: name, Type: typeType, Args: []*Argument{{Name: "name", Type: &NonNull{Of: String}}}, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			return s.types()[p.Args["name"].(string)], nil
+		}}
+	}
+	return object.field(name)
+}
+
+// types returns every named type reachable from the schema, by name
+func (s *Schema) types() map[string]Type {
+	s.typesOnce.Do(func() {
+		s.typeMap = map[string]Type{}
+		for _, t := range []Type{s.Query, schemaType, String, Int, Float, Boolean, ID} {
+			collectTypes(t, s.typeMap)
+		}
+	})
+	return s.typeMap
+}
+
+func collectTypes(t Type, types map[string]Type) {
+	t = namedType(t)
+	name := t.String()
+	if types[name] != nil {
+		return
+	}
+	types[name] = t
+	if object, ok := t.(*Object); ok {
+		for _, f := range object.Fields {
+			collectTypes(f.Type, types)
+			for _, arg := range f.Args {
+				collectTypes(arg.Type, types)
+			}
+		}
+	}
+}
+
+// Introspection types, resolved over *Schema, Type, *Field, *Argument and
+// enumValue sources
+var (
+	typeKindEnum = &Enum{Name: "__TypeKind", Values: []string{"SCALAR", "OBJECT", "INTERFACE", "UNION", "ENUM", "INPUT_OBJECT", "LIST", "NON_NULL"}}
+
+	schemaType     = &Object{Name: "__Schema"}
+	typeType       = &Object{Name: "__Type"}
+	fieldType      = &Object{Name: "__Field"}
+	inputValueType = &Object{Name: "__InputValue"}
+	enumValueType  = &Object{Name: "__EnumValue"}
+	directiveType  = &Object{Name: "__Directive"}
+)
+
+// enumValue is the introspection source of one enum value
+type enumValue struct {
+	name string
+}
+
+// builtinDirective describes a directive the executor understands
+type builtinDirective struct {
+	name        string
+	description string
+	locations   []string
+	args        []*Argument
+}
+
+var builtinDirectives = []*builtinDirective{
+	{name: "skip", description: "Skips this field or fragment when if is true.", locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"}, args: []*Argument{{Name: "if", Type: &NonNull{Of: Boolean}}}},
+	{name: "include", description: "Includes this field or fragment only when if is true.", locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"}, args: []*Argument{{Name: "if", Type: &NonNull{Of: Boolean}}}},
+}
+
+func init() {
+	directiveLocation := &Enum{Name: "__DirectiveLocation", Values: []string{
+		"QUERY", "MUTATION", "SUBSCRIPTION", "FIELD", "FRAGMENT_DEFINITION", "FRAGMENT_SPREAD", "INLINE_FRAGMENT", "VARIABLE_DEFINITION",
+		"SCHEMA", "SCALAR", "OBJECT", "FIELD_DEFINITION", "ARGUMENT_DEFINITION", "INTERFACE", "UNION", "ENUM", "ENUM_VALUE",
+		"INPUT_OBJECT", "INPUT_FIELD_DEFINITION",
+	}}
+	typeList := &NonNull{Of: &List{Of: &NonNull{Of: typeType}}}
+	includeDeprecated := []*Argument{{Name: "includeDeprecated", Type: Boolean, Default: false}}
+
+	schemaType.Fields = []*Field{
+		{Name: "description", Type: String, Resolve: constant(nil)},
+		{Name: "types", Type: typeList, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			types := p.Source.(*Schema).types()
+			names := make([]string, 0, len(types))
+			for name := range types {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			list := make([]Type, len(names))
+			for i, name := range names {
+				list[i] = types[name]
+			}
+			return list, nil
+		}},
+		{Name: "queryType", Type: &NonNull{Of: typeType}, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			return p.Source.(*Schema).Query, nil
+		}},
+		{Name: "mutationType", Type: typeType, Resolve: constant(nil)},
+		{Name: "subscriptionType", Type: typeType, Resolve: constant(nil)},
+		{Name: "directives", Type: &NonNull{Of: &List{Of: &NonNull{Of: directiveType}}}, Resolve: constant(builtinDirectives)},
+	}
+
+	typeType.Fields = []*Field{
+		{Name: "kind", Type: &NonNull{Of: typeKindEnum}, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			switch p.Source.(type) {
+			case *Scalar:
+				return "SCALAR", nil
+			case *Object:
+				return "OBJECT", nil
+			case *Enum:
+				return "ENUM", nil
+			case *List:
+				return "LIST", nil
+			}
+			return "NON_NULL", nil
+		}},
+		{Name: "name", Type: String, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			switch p.Source.(type) {
+			case *List, *NonNull:
+				return nil, nil
+			}
+			return p.Source.(Type).String(), nil
+		}},
+		{Name: "description", Type: String, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			switch t := p.Source.(type) {
+			case *Scalar:
+				return optional(t.Description), nil
+			case *Object:
+				return optional(t.Description), nil
+			case *Enum:
+				return optional(t.Description), nil
+			}
+			return nil, nil
+		}},
+		{Name: "specifiedByURL", Type: String, Resolve: constant(nil)},
+		{Name: "fields", Type: &List{Of: &NonNull{Of: fieldType}}, Args: includeDeprecated, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			object, ok := p.Source.(*Object)
+			if !ok {
+				return nil, nil
+			}
+			fields := []*Field{}
+			for _, f := range object.Fields {
+				if f.DeprecationReason == "" || p.Args["includeDeprecated"] == true {
+					fields = append(fields, f)
+				}
+			}
+			return fields, nil
+		}},
+		{Name: "interfaces", Type: &List{Of: &NonNull{Of: typeType}}, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			if _, ok := p.Source.(*Object); ok {
+				return []Type{}, nil
+			}
+			return nil, nil
+		}},
+		{Name: "possibleTypes", Type: &List{Of: &NonNull{Of: typeType}}, Resolve: constant(nil)},
+		{Name: "enumValues", Type: &List{Of: &NonNull{Of: enumValueType}}, Args: includeDeprecated, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			enum, ok := p.Source.(*Enum)
+			if !ok {
+				return nil, nil
+			}
+			values := make([]enumValue, len(enum.Values))
+			for i, name := range enum.Values {
+				values[i] = enumValue{name: name}
+			}
+			return values, nil
+		}},
+		{Name: "inputFields", Type: &List{Of: &NonNull{Of: inputValueType}}, Resolve: constant(nil)},
+		{Name: "ofType", Type: typeType, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			switch t := p.Source.(type) {
+			case *List:
+				return t.Of, nil
+			case *NonNull:
+				return t.Of, nil
+			}
+			return nil, nil
+		}},
+	}
+
+	fieldType.Fields = []*Field{
+		{Name: "name", Type: &NonNull{Of: String}, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			return p.Source.(*Field).Name, nil
+		}},
+		{Name: "description", Type: String, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			return optional(p.Source.(*Field).Description), nil
+		}},
+		{Name: "args", Type: &NonNull{Of: &List{Of: &NonNull{Of: inputValueType}}}, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			if args := p.Source.(*Field).Args; args != nil {
+				return args, nil
+			}
+			return []*Argument{}, nil
+		}},
+		{Name: "type", Type: &NonNull{Of: typeType}, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			return p.Source.(*Field).Type, nil
+		}},
+		{Name: "isDeprecated", Type: &NonNull{Of: Boolean}, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			return p.Source.(*Field).DeprecationReason != "", nil
+		}},
+		{Name: "deprecationReason", Type: String, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			return optional(p.Source.(*Field).DeprecationReason), nil
+		}},
+	}
+
+	inputValueType.Fields = []*Field{
+		{Name: "name", Type: &NonNull{Of: String}, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			return p.Source.(*Argument).Name, nil
+		}},
+		{Name: "description", Type: String, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			return optional(p.Source.(*Argument).Description), nil
+		}},
+		{Name: "type", Type: &NonNull{Of: typeType}, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			return p.Source.(*Argument).Type, nil
+		}},
+		{Name: "defaultValue", Type: String, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			arg := p.Source.(*Argument)
+			if arg.Default == nil {
+				return nil, nil
+			}
+			return literalString(arg.Default), nil
+		}},
+		{Name: "isDeprecated", Type: &NonNull{Of: Boolean}, Resolve: constant(false)},
+		{Name: "deprecationReason", Type: String, Resolve: constant(nil)},
+	}
+
+	enumValueType.Fields = []*Field{
+		{Name: "name", Type: &NonNull{Of: String}, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			return p.Source.(enumValue).name, nil
+		}},
+		{Name: "description", Type: String, Resolve: constant(nil)},
+		{Name: "isDeprecated", Type: &NonNull{Of: Boolean}, Resolve: constant(false)},
+		{Name: "deprecationReason", Type: String, Resolve: constant(nil)},
+	}
+
+	directiveType.Fields = []*Field{
+		{Name: "name", Type: &NonNull{Of: String}, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			return p.Source.(*builtinDirective).name, nil
+		}},
+		{Name: "description", Type: String, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			return p.Source.(*builtinDirective).description, nil
+		}},
+		{Name: "isRepeatable", Type: &NonNull{Of: Boolean}, Resolve: constant(false)},
+		{Name: "locations", Type: &NonNull{Of: &List{Of: &NonNull{Of: directiveLocation}}}, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			return p.Source.(*builtinDirective).locations, nil
+		}},
+		{Name: "args", Type: &NonNull{Of: &List{Of: &NonNull{Of: inputValueType}}}, Resolve: func(ctx context.Context, p Params) (interface{}, error) {
+			return p.Source.(*builtinDirective).args, nil
+		}},
+	}
+}
+
+// constant returns a resolver producing value
+func constant(value interface{}) ResolveFunc {
+	return func(ctx context.Context, p Params) (interface{}, error) {
+		return value, nil
+	}
+}
+
+// optional returns nil for an empty description
+func optional(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,605 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// document is a parsed GraphQL request document
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+type operation struct {
+	kind      string // query, mutation or subscription
+	name      string
+	variables []*variableDefinition
+	selection []selection
+	loc       Location
+}
+
+type variableDefinition struct {
+	name         string
+	typ          typeRef
+	defaultValue value // nil when there is no default
+	loc          Location
+}
+
+// typeRef is a type named in a variable definition
+type typeRef struct {
+	name    string   // set for named types
+	list    *typeRef // set for list types
+	nonNull bool
+}
+
+func (t typeRef) String() string {
+	s := t.name
+	if t.list != nil {
+		s = "[" + t.list.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+type fragment struct {
+	name          string
+	typeCondition string
+	directives    []*directive
+	selection     []selection
+	loc           Location
+}
+
+// selection is a *field, *fragmentSpread or *inlineFragment
+type selection interface{}
+
+type field struct {
+	alias      string
+	name       string
+	arguments  []*argument
+	directives []*directive
+	selection  []selection
+	loc        Location
+}
+
+// responseKey is the key the field's value is returned under
+func (f *field) responseKey() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+type fragmentSpread struct {
+	name       string
+	directives []*directive
+	loc        Location
+}
+
+type inlineFragment struct {
+	typeCondition string // empty when the fragment has none
+	directives    []*directive
+	selection     []selection
+	loc           Location
+}
+
+type argument struct {
+	name  string
+	value value
+	loc   Location
+}
+
+type directive struct {
+	name      string
+	arguments []*argument
+	loc       Location
+}
+
+// value is a literal in the document: a *variable, *listValue, *objectValue
+// or *scalarValue
+type value interface{}
+
+type variable struct {
+	name string
+}
+
+type listValue struct {
+	items []value
+}
+
+type objectValue struct {
+	fields []*argument
+}
+
+type scalarValue struct {
+	kind  tokenKind // tokenInt, tokenFloat, tokenString or tokenName (booleans, null and enum values)
+	value string
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	loc   Location
+}
+
+// parser is a recursive-descent parser over the executable subset of the
+// GraphQL grammar: operations and fragments. Type system definitions are
+// rejected.
+type parser struct {
+	source string
+	pos    int
+	line   int
+	lineAt int // offset of the current line's first character
+	tok    token
+}
+
+// parse parses a request document
+func parse(source string) (doc *document, err error) {
+	p := &parser{source: source, line: 1}
+	defer func() {
+		if r := recover(); r != nil {
+			syntaxErr, ok := r.(*Error)
+			if !ok {
+				panic(r)
+			}
+			doc, err = nil, syntaxErr
+		}
+	}()
+
+	p.next()
+	doc = &document{fragments: map[string]*fragment{}}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek(tokenPunctuator, "{"):
+			doc.operations = append(doc.operations, &operation{kind: "query", loc: p.tok.loc, selection: p.parseSelectionSet()})
+		case p.peek(tokenName, "query"), p.peek(tokenName, "mutation"), p.peek(tokenName, "subscription"):
+			doc.operations = append(doc.operations, p.parseOperation())
+		case p.peek(tokenName, "fragment"):
+			frag := p.parseFragment()
+			if doc.fragments[frag.name] != nil {
+				return nil, errorAt(frag.loc, "there can be only one fragment named %q", frag.name)
+			}
+			doc.fragments[frag.name] = frag
+		default:
+			p.fail("unexpected %s", p.describe())
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, &Error{Message: "document contains no operation"}
+	}
+	return doc, nil
+}
+
+func (p *parser) parseOperation() *operation {
+	op := &operation{kind: p.tok.value, loc: p.tok.loc}
+	p.next()
+	if p.tok.kind == tokenName {
+		op.name = p.tok.value
+		p.next()
+	}
+	if p.skip("(") {
+		for !p.skip(")") {
+			def := &variableDefinition{loc: p.tok.loc}
+			p.expect("$")
+			def.name = p.expectName()
+			p.expect(":")
+			def.typ = p.parseType()
+			if p.skip("=") {
+				def.defaultValue = p.parseValue(true)
+			}
+			op.variables = append(op.variables, def)
+		}
+	}
+	for p.peek(tokenPunctuator, "@") {
+		p.parseDirective() // operation directives have no meaning here
+	}
+	op.selection = p.parseSelectionSet()
+	return op
+}
+
+func (p *parser) parseFragment() *fragment {
+	frag := &fragment{loc: p.tok.loc}
+	p.next()
+	frag.name = p.expectName()
+	if frag.name == "on" {
+		p.fail("fragment cannot be named \"on\"")
+	}
+	p.expectKeyword("on")
+	frag.typeCondition = p.expectName()
+	frag.directives = p.parseDirectives()
+	frag.selection = p.parseSelectionSet()
+	return frag
+}
+
+func (p *parser) parseType() typeRef {
+	var t typeRef
+	if p.skip("[") {
+		inner := p.parseType()
+		t.list = &inner
+		p.expect("]")
+	} else {
+		t.name = p.expectName()
+	}
+	t.nonNull = p.skip("!")
+	return t
+}
+
+func (p *parser) parseSelectionSet() []selection {
+	p.expect("{")
+	selections := []selection{}
+	for !p.skip("}") {
+		selections = append(selections, p.parseSelection())
+	}
+	if len(selections) == 0 {
+		p.fail("selection set cannot be empty")
+	}
+	return selections
+}
+
+func (p *parser) parseSelection() selection {
+	loc := p.tok.loc
+	if p.skip("...") {
+		if p.peek(tokenName, "on") {
+			p.next()
+			frag := &inlineFragment{typeCondition: p.expectName(), loc: loc}
+			frag.directives = p.parseDirectives()
+			frag.selection = p.parseSelectionSet()
+			return frag
+		}
+		if p.tok.kind == tokenName {
+			return &fragmentSpread{name: p.expectName(), directives: p.parseDirectives(), loc: loc}
+		}
+		frag := &inlineFragment{loc: loc}
+		frag.directives = p.parseDirectives()
+		frag.selection = p.parseSelectionSet()
+		return frag
+	}
+
+	f := &field{name: p.expectName(), loc: loc}
+	if p.skip(":") {
+		f.alias, f.name = f.name, p.expectName()
+	}
+	f.arguments = p.parseArguments(false)
+	f.directives = p.parseDirectives()
+	if p.peek(tokenPunctuator, "{") {
+		f.selection = p.parseSelectionSet()
+	}
+	return f
+}
+
+func (p *parser) parseArguments(constant bool) []*argument {
+	var args []*argument
+	if !p.skip("(") {
+		return nil
+	}
+	for !p.skip(")") {
+		arg := &argument{loc: p.tok.loc, name: p.expectName()}
+		p.expect(":")
+		arg.value = p.parseValue(constant)
+		args = append(args, arg)
+	}
+	return args
+}
+
+func (p *parser) parseDirectives() []*directive {
+	var directives []*directive
+	for p.peek(tokenPunctuator, "@") {
+		directives = append(directives, p.parseDirective())
+	}
+	return directives
+}
+
+func (p *parser) parseDirective() *directive {
+	d := &directive{loc: p.tok.loc}
+	p.expect("@")
+	d.name = p.expectName()
+	d.arguments = p.parseArguments(false)
+	return d
+}
+
+// parseValue parses a literal; constant literals may not contain variables
+func (p *parser) parseValue(constant bool) value {
+	tok := p.tok
+	switch tok.kind {
+	case tokenPunctuator:
+		switch tok.value {
+		case "$":
+			if constant {
+				p.fail("unexpected variable in constant value")
+			}
+			p.next()
+			return &variable{name: p.expectName()}
+		case "[":
+			p.next()
+			list := &listValue{items: []value{}}
+			for !p.skip("]") {
+				list.items = append(list.items, p.parseValue(constant))
+			}
+			return list
+		case "{":
+			p.next()
+			object := &objectValue{}
+			for !p.skip("}") {
+				field := &argument{loc: p.tok.loc, name: p.expectName()}
+				p.expect(":")
+				field.value = p.parseValue(constant)
+				object.fields = append(object.fields, field)
+			}
+			return object
+		}
+	case tokenName, tokenInt, tokenFloat, tokenString:
+		p.next()
+		return &scalarValue{kind: tok.kind, value: tok.value}
+	}
+	p.fail("unexpected %s", p.describe())
+	return nil
+}
+
+func (p *parser) peek(kind tokenKind, value string) bool {
+	return p.tok.kind == kind && p.tok.value == value
+}
+
+// skip consumes the punctuator if it is next
+func (p *parser) skip(punctuator string) bool {
+	if p.peek(tokenPunctuator, punctuator) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(punctuator string) {
+	if !p.skip(punctuator) {
+		p.fail("expected %q, found %s", punctuator, p.describe())
+	}
+}
+
+func (p *parser) expectKeyword(keyword string) {
+	if !p.peek(tokenName, keyword) {
+		p.fail("expected %q, found %s", keyword, p.describe())
+	}
+	p.next()
+}
+
+func (p *parser) expectName() string {
+	if p.tok.kind != tokenName {
+		p.fail("expected name, found %s", p.describe())
+	}
+	name := p.tok.value
+	p.next()
+	return name
+}
+
+func (p *parser) describe() string {
+	switch p.tok.kind {
+	case tokenEOF:
+		return "end of document"
+	case tokenString:
+		return "string " + strconv.Quote(p.tok.value)
+	}
+	return strconv.Quote(p.tok.value)
+}
+
+func (p *parser) fail(format string, args ...interface{}) {
+	panic(errorAt(p.tok.loc, "syntax error: "+format, args...))
+}
+
+// next reads the following token into p.tok
+func (p *parser) next() {
+	p.skipIgnored()
+	loc := Location{Line: p.line, Column: p.pos - p.lineAt + 1}
+	if p.pos >= len(p.source) {
+		p.tok = token{kind: tokenEOF, loc: loc}
+		return
+	}
+
+	c := p.source[p.pos]
+	switch {
+	case strings.HasPrefix(p.source[p.pos:], "..."):
+		p.pos += 3
+		p.tok = token{kind: tokenPunctuator, value: "...", loc: loc}
+	case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+		p.pos++
+		p.tok = token{kind: tokenPunctuator, value: string(c), loc: loc}
+	case c == '_' || isLetter(c):
+		start := p.pos
+		for p.pos < len(p.source) && (p.source[p.pos] == '_' || isLetter(p.source[p.pos]) || isDigit(p.source[p.pos])) {
+			p.pos++
+		}
+		p.tok = token{kind: tokenName, value: p.source[start:p.pos], loc: loc}
+	case c == '-' || isDigit(c):
+		p.tok = p.readNumber(loc)
+	case c == '"':
+		p.tok = token{kind: tokenString, value: p.readString(), loc: loc}
+	default:
+		r, _ := utf8.DecodeRuneInString(p.source[p.pos:])
+		p.tok = token{loc: loc}
+		p.fail("unexpected character %q", r)
+	}
+}
+
+// skipIgnored skips whitespace, commas, comments and a byte order mark
+func (p *parser) skipIgnored() {
+	for p.pos < len(p.source) {
+		switch c := p.source[p.pos]; {
+		case c == '\n':
+			p.pos++
+			p.line++
+			p.lineAt = p.pos
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			p.pos++
+		case c == '#':
+			for p.pos < len(p.source) && p.source[p.pos] != '\n' {
+				p.pos++
+			}
+		case strings.HasPrefix(p.source[p.pos:], "\ufeff"):
+			p.pos += len("\ufeff")
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) readNumber(loc Location) token {
+	start := p.pos
+	kind := tokenInt
+	if p.source[p.pos] == '-' {
+		p.pos++
+	}
+	p.readDigits()
+	if p.pos < len(p.source) && p.source[p.pos] == '.' {
+		kind = tokenFloat
+		p.pos++
+		p.readDigits()
+	}
+	if p.pos < len(p.source) && (p.source[p.pos] == 'e' || p.source[p.pos] == 'E') {
+		kind = tokenFloat
+		p.pos++
+		if p.pos < len(p.source) && (p.source[p.pos] == '+' || p.source[p.pos] == '-') {
+			p.pos++
+		}
+		p.readDigits()
+	}
+	if p.pos < len(p.source) && (p.source[p.pos] == '_' || isLetter(p.source[p.pos]) || p.source[p.pos] == '.') {
+		p.tok = token{loc: loc}
+		p.fail("invalid number %q", p.source[start:p.pos+1])
+	}
+	return token{kind: kind, value: p.source[start:p.pos], loc: loc}
+}
+
+func (p *parser) readDigits() {
+	start := p.pos
+	for p.pos < len(p.source) && isDigit(p.source[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		p.tok = token{loc: Location{Line: p.line, Column: p.pos - p.lineAt + 1}}
+		p.fail("invalid number, expected digit")
+	}
+}
+
+// readString reads a quoted or block string, returning its value
+func (p *parser) readString() string {
+	if strings.HasPrefix(p.source[p.pos:], `"""`) {
+		return p.readBlockString()
+	}
+
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.pos >= len(p.source) || p.source[p.pos] == '\n' {
+			p.fail("unterminated string")
+		}
+		c := p.source[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return b.String()
+		case c == '\\':
+			if p.pos+1 >= len(p.source) {
+				p.fail("unterminated string")
+			}
+			escape := p.source[p.pos+1]
+			p.pos += 2
+			switch escape {
+			case '"', '\\', '/':
+				b.WriteByte(escape)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if p.pos+4 > len(p.source) {
+					p.fail("invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(p.source[p.pos:p.pos+4], 16, 32)
+				if err != nil {
+					p.fail("invalid unicode escape")
+				}
+				b.WriteRune(rune(code))
+				p.pos += 4
+			default:
+				p.fail("invalid escape sequence \\%c", escape)
+			}
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// readBlockString reads a """block string""", removing common indentation
+// and leading and trailing blank lines
+func (p *parser) readBlockString() string {
+	p.pos += 3
+	end := strings.Index(p.source[p.pos:], `"""`)
+	for end > 0 && p.source[p.pos+end-1] == '\\' {
+		next := strings.Index(p.source[p.pos+end+3:], `"""`)
+		if next < 0 {
+			end = -1
+			break
+		}
+		end += 3 + next
+	}
+	if end < 0 {
+		p.fail("unterminated block string")
+	}
+	raw := p.source[p.pos : p.pos+end]
+	p.line += strings.Count(raw, "\n")
+	if last := strings.LastIndexByte(raw, '\n'); last >= 0 {
+		p.lineAt = p.pos + last + 1
+	}
+	p.pos += end + 3
+
+	lines := strings.Split(strings.ReplaceAll(strings.ReplaceAll(raw, `\"""`, `"""`), "\r\n", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && (indent < 0 || len(line)-len(trimmed) < indent) {
+			indent = len(line) - len(trimmed)
+		}
+	}
+	for i := 1; i < len(lines) && indent > 0; i++ {
+		if len(lines[i]) >= indent {
+			lines[i] = lines[i][indent:]
+		} else {
+			lines[i] = strings.TrimLeft(lines[i], " \t")
+		}
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[0], " \t") == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isLetter(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func errorAt(loc Location, format string, args ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{loc}}
+}
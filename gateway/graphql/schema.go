@@ -0,0 +1,237 @@
+// Package graphql is a small GraphQL query engine: a parser for executable
+// documents, a type system of objects, enums, scalars, lists and non-null
+// wrappers, and an executor that resolves exactly the fields a query selects.
+// Queries are the only supported operation; the schema's clients change the
+// ledger through the REST API.
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Type is a *Scalar, *Enum, *Object, *List or *NonNull
+type Type interface {
+	String() string
+}
+
+// Scalar is a leaf type. Serialize converts a resolved value for the response
+// and ParseValue converts a variable or literal argument; either reports
+// false when the value does not belong to the type.
+type Scalar struct {
+	Name        string
+	Description string
+	Serialize   func(value interface{}) (interface{}, bool)
+	ParseValue  func(value interface{}) (interface{}, bool)
+}
+
+func (t *Scalar) String() string { return t.Name }
+
+// Enum is a leaf type whose values are a fixed set of names
+type Enum struct {
+	Name        string
+	Description string
+	Values      []string
+}
+
+func (t *Enum) String() string { return t.Name }
+
+func (t *Enum) has(value string) bool {
+	for _, v := range t.Values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Object is a type with fields. Fields lists them in the order introspection
+// reports them.
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+}
+
+func (t *Object) String() string { return t.Name }
+
+// field returns the named field, or nil
+func (t *Object) field(name string) *Field {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// List wraps a type whose values are lists of Of
+type List struct {
+	Of Type
+}
+
+func (t *List) String() string { return "[" + t.Of.String() + "]" }
+
+// NonNull wraps a type whose values are never null
+type NonNull struct {
+	Of Type
+}
+
+func (t *NonNull) String() string { return t.Of.String() + "!" }
+
+// Field is a field of an object type. A nil Resolve reads the field's name
+// from a map[string]interface{} source, which suits decoded chaincode JSON.
+type Field struct {
+	Name              string
+	Description       string
+	Type              Type
+	Args              []*Argument
+	Resolve           ResolveFunc
+	DeprecationReason string
+}
+
+// Argument is an argument a field accepts. A nil Default leaves the argument
+// out of Params.Args when the query omits it.
+type Argument struct {
+	Name        string
+	Description string
+	Type        Type
+	Default     interface{}
+}
+
+// ResolveFunc produces the value of a field
+type ResolveFunc func(ctx context.Context, p Params) (interface{}, error)
+
+// Params carries what a resolver needs to produce a field's value
+type Params struct {
+	Source interface{}            // the value of the object the field belongs to
+	Args   map[string]interface{} // coerced arguments
+	// Selected names the fields selected on the value being resolved, so a
+	// resolver can fetch no more than the query asks for. It is empty for
+	// leaf fields.
+	Selected []string
+}
+
+// Selects reports whether any of the named fields is selected
+func (p Params) Selects(names ...string) bool {
+	for _, selected := range p.Selected {
+		for _, name := range names {
+			if selected == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Schema is the root of a type system
+type Schema struct {
+	Query *Object
+	// MaxDepth bounds how deeply selections may nest; zero means no limit
+	MaxDepth int
+
+	typesOnce sync.Once
+	typeMap   map[string]Type
+}
+
+// Location is a position within a request document
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error is an error in the GraphQL response format
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Locations) > 0 {
+		return fmt.Sprintf("%s (line %d, column %d)", e.Message, e.Locations[0].Line, e.Locations[0].Column)
+	}
+	return e.Message
+}
+
+// Response is the result of executing a request. Data is absent when the
+// request failed before execution began.
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// Request is a GraphQL request as clients post it
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Execute parses, validates and runs a request against the schema
+func (s *Schema) Execute(ctx context.Context, request Request) *Response {
+	doc, err := parse(request.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{err.(*Error)}}
+	}
+
+	op, err := selectOperation(doc, request.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{err.(*Error)}}
+	}
+	if op.kind != "query" {
+		return &Response{Errors: []*Error{errorAt(op.loc, "%s operations are not supported", op.kind)}}
+	}
+
+	errs := s.validate(doc, op)
+	if len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+
+	variables, errs := s.coerceVariables(op, request.Variables)
+	if len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+
+	e := &executor{schema: s, doc: doc, variables: variables}
+	data := e.executeSelection(ctx, s.Query, nil, op.selection, nil)
+	if data == nil {
+		return &Response{Data: nullData{}, Errors: e.errors}
+	}
+	return &Response{Data: data, Errors: e.errors}
+}
+
+// nullData marshals as the null data of a request whose root field failed
+type nullData struct{}
+
+func (nullData) MarshalJSON() ([]byte, error) { return []byte("null"), nil }
+
+func selectOperation(doc *document, name string) (*operation, error) {
+	if name == "" {
+		if len(doc.operations) > 1 {
+			return nil, &Error{Message: "operationName is required when the document has several operations"}
+		}
+		return doc.operations[0], nil
+	}
+	for _, op := range doc.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("unknown operation %q", name)}
+}
+
+// namedType strips list and non-null wrappers from a type
+func namedType(t Type) Type {
+	for {
+		switch wrapper := t.(type) {
+		case *List:
+			t = wrapper.Of
+		case *NonNull:
+			t = wrapper.Of
+		default:
+			return t
+		}
+	}
+}
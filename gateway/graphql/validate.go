@@ -0,0 +1,275 @@
+package graphql
+
+import (
+	"fmt"
+)
+
+// validator checks an operation against the schema before it runs, so a
+// request with a mistake fails as a whole instead of partially executing
+type validator struct {
+	schema    *Schema
+	doc       *document
+	variables map[string]*variableDefinition
+	used      map[string]bool // fragments spread by the operation
+	errors    []*Error
+}
+
+// validate checks the selected operation and the fragments it uses
+func (s *Schema) validate(doc *document, op *operation) []*Error {
+	v := &validator{schema: s, doc: doc, variables: map[string]*variableDefinition{}, used: map[string]bool{}}
+	for _, def := range op.variables {
+		if v.variables[def.name] != nil {
+			v.errorf(def.loc, "there can be only one variable named $%s", def.name)
+		}
+		v.variables[def.name] = def
+	}
+
+	v.checkSelection(s.Query, op.selection, 1, map[string]bool{})
+
+	for name, frag := range doc.fragments {
+		if !v.used[name] && !v.usedElsewhere(name) {
+			v.errorf(frag.loc, "fragment %q is never used", name)
+		}
+	}
+	return v.errors
+}
+
+// usedElsewhere reports whether another operation in the document spreads
+// the fragment, so it is not reported unused when that operation is not run
+func (v *validator) usedElsewhere(name string) bool {
+	for _, op := range v.doc.operations {
+		if spreads(v.doc, op.selection, name, map[string]bool{}) {
+			return true
+		}
+	}
+	return false
+}
+
+func spreads(doc *document, selections []selection, name string, visited map[string]bool) bool {
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *field:
+			if spreads(doc, sel.selection, name, visited) {
+				return true
+			}
+		case *inlineFragment:
+			if spreads(doc, sel.selection, name, visited) {
+				return true
+			}
+		case *fragmentSpread:
+			if sel.name == name {
+				return true
+			}
+			if frag := doc.fragments[sel.name]; frag != nil && !visited[sel.name] {
+				visited[sel.name] = true
+				if spreads(doc, frag.selection, name, visited) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// checkSelection validates a selection set on object. spreading holds the
+// fragments being expanded, to catch cycles.
+func (v *validator) checkSelection(object *Object, selections []selection, depth int, spreading map[string]bool) {
+	if v.schema.MaxDepth > 0 && depth > v.schema.MaxDepth {
+		if len(selections) > 0 {
+			v.errorf(selectionLoc(selections[0]), "query is nested more than %d levels deep", v.schema.MaxDepth)
+		}
+		return
+	}
+
+	seen := map[string]*field{}
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *field:
+			v.checkDirectives(sel.directives)
+			if other := seen[sel.responseKey()]; other != nil && other.name != sel.name {
+				v.errorf(sel.loc, "fields %q and %q conflict under response key %q; use different aliases", other.name, sel.name, sel.responseKey())
+			}
+			seen[sel.responseKey()] = sel
+			v.checkField(object, sel, depth, spreading)
+		case *fragmentSpread:
+			v.checkDirectives(sel.directives)
+			frag := v.doc.fragments[sel.name]
+			if frag == nil {
+				v.errorf(sel.loc, "unknown fragment %q", sel.name)
+				continue
+			}
+			v.used[sel.name] = true
+			if spreading[sel.name] {
+				v.errorf(sel.loc, "fragment %q spreads itself", sel.name)
+				continue
+			}
+			if !v.checkTypeCondition(object, frag.typeCondition, frag.loc) {
+				continue
+			}
+			v.checkDirectives(frag.directives)
+			spreading[sel.name] = true
+			v.checkSelection(object, frag.selection, depth, spreading)
+			delete(spreading, sel.name)
+		case *inlineFragment:
+			v.checkDirectives(sel.directives)
+			if sel.typeCondition != "" && !v.checkTypeCondition(object, sel.typeCondition, sel.loc) {
+				continue
+			}
+			v.checkSelection(object, sel.selection, depth, spreading)
+		}
+	}
+}
+
+// checkTypeCondition validates a fragment's type condition; with no abstract
+// types in the schema, a fragment applies only to the object it names
+func (v *validator) checkTypeCondition(object *Object, condition string, loc Location) bool {
+	switch v.schema.types()[condition].(type) {
+	case nil:
+		v.errorf(loc, "unknown type %q", condition)
+		return false
+	case *Object:
+		if condition != object.Name {
+			v.errorf(loc, "fragment on %s cannot be spread within %s", condition, object.Name)
+			return false
+		}
+		return true
+	}
+	v.errorf(loc, "fragment cannot condition on %s, which is not an object type", condition)
+	return false
+}
+
+func (v *validator) checkField(object *Object, f *field, depth int, spreading map[string]bool) {
+	def := v.schema.fieldDef(object, f.name)
+	if def == nil {
+		v.errorf(f.loc, "cannot query field %q on type %s", f.name, object.Name)
+		return
+	}
+
+	for i, arg := range f.arguments {
+		for _, other := range f.arguments[:i] {
+			if other.name == arg.name {
+				v.errorf(arg.loc, "there can be only one argument named %q", arg.name)
+			}
+		}
+		var argDef *Argument
+		for _, candidate := range def.Args {
+			if candidate.Name == arg.name {
+				argDef = candidate
+			}
+		}
+		if argDef == nil {
+			v.errorf(arg.loc, "unknown argument %q on field %s.%s", arg.name, object.Name, f.name)
+			continue
+		}
+		v.checkValue(arg.value, argDef.Type, arg.loc, fmt.Sprintf("argument %q", arg.name))
+	}
+	for _, argDef := range def.Args {
+		if _, required := argDef.Type.(*NonNull); !required || argDef.Default != nil {
+			continue
+		}
+		provided := false
+		for _, arg := range f.arguments {
+			provided = provided || arg.name == argDef.Name
+		}
+		if !provided {
+			v.errorf(f.loc, "field %s.%s requires argument %q of type %s", object.Name, f.name, argDef.Name, argDef.Type)
+		}
+	}
+
+	child, composite := namedType(def.Type).(*Object)
+	switch {
+	case composite && len(f.selection) == 0:
+		v.errorf(f.loc, "field %q of type %s must have a selection of subfields", f.name, def.Type)
+	case !composite && len(f.selection) > 0:
+		v.errorf(f.loc, "field %q of type %s must not have a selection of subfields", f.name, def.Type)
+	case composite:
+		v.checkSelection(child, f.selection, depth+1, spreading)
+	}
+}
+
+// checkValue validates a literal against an input type. Variables are
+// checked for definition only; their values are coerced before execution.
+func (v *validator) checkValue(literal value, t Type, loc Location, what string) {
+	v.checkVariables(literal, loc)
+	if containsVariable(literal) {
+		return
+	}
+	_, err := coerceLiteral(literal, t, nil)
+	if err != nil {
+		v.errorf(loc, "%s: %v", what, err)
+	}
+}
+
+func (v *validator) checkVariables(literal value, loc Location) {
+	switch literal := literal.(type) {
+	case *variable:
+		if v.variables[literal.name] == nil {
+			v.errorf(loc, "variable $%s is not defined", literal.name)
+		}
+	case *listValue:
+		for _, item := range literal.items {
+			v.checkVariables(item, loc)
+		}
+	case *objectValue:
+		for _, field := range literal.fields {
+			v.checkVariables(field.value, field.loc)
+		}
+	}
+}
+
+func containsVariable(literal value) bool {
+	switch literal := literal.(type) {
+	case *variable:
+		return true
+	case *listValue:
+		for _, item := range literal.items {
+			if containsVariable(item) {
+				return true
+			}
+		}
+	case *objectValue:
+		for _, field := range literal.fields {
+			if containsVariable(field.value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (v *validator) checkDirectives(directives []*directive) {
+	for _, d := range directives {
+		if d.name != "skip" && d.name != "include" {
+			v.errorf(d.loc, "unknown directive @%s", d.name)
+			continue
+		}
+		found := false
+		for _, arg := range d.arguments {
+			if arg.name != "if" {
+				v.errorf(arg.loc, "unknown argument %q on directive @%s", arg.name, d.name)
+				continue
+			}
+			found = true
+			v.checkValue(arg.value, &NonNull{Of: Boolean}, arg.loc, "argument \"if\"")
+		}
+		if !found {
+			v.errorf(d.loc, "directive @%s requires argument \"if\"", d.name)
+		}
+	}
+}
+
+func (v *validator) errorf(loc Location, format string, args ...interface{}) {
+	v.errors = append(v.errors, errorAt(loc, format, args...))
+}
+
+func selectionLoc(sel selection) Location {
+	switch sel := sel.(type) {
+	case *field:
+		return sel.loc
+	case *fragmentSpread:
+		return sel.loc
+	case *inlineFragment:
+		return sel.loc
+	}
+	return Location{}
+}
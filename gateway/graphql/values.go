@@ -0,0 +1,357 @@
+package graphql
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Built-in scalar types
+var (
+	String = &Scalar{
+		Name:        "String",
+		Description: "UTF-8 text.",
+		Serialize: func(value interface{}) (interface{}, bool) {
+			switch v := value.(type) {
+			case string:
+				return v, true
+			case bool:
+				return strconv.FormatBool(v), true
+			case int, int32, int64, float64:
+				return fmt.Sprint(v), true
+			}
+			return nil, false
+		},
+		ParseValue: func(value interface{}) (interface{}, bool) {
+			v, ok := value.(string)
+			return v, ok
+		},
+	}
+	Int = &Scalar{
+		Name:        "Int",
+		Description: "A signed 32-bit integer.",
+		Serialize:   toInt,
+		ParseValue:  toInt,
+	}
+	Float = &Scalar{
+		Name:        "Float",
+		Description: "A double-precision floating point number.",
+		Serialize:   toFloat,
+		ParseValue:  toFloat,
+	}
+	Boolean = &Scalar{
+		Name:        "Boolean",
+		Description: "true or false.",
+		Serialize: func(value interface{}) (interface{}, bool) {
+			v, ok := value.(bool)
+			return v, ok
+		},
+		ParseValue: func(value interface{}) (interface{}, bool) {
+			v, ok := value.(bool)
+			return v, ok
+		},
+	}
+	ID = &Scalar{
+		Name:        "ID",
+		Description: "A unique identifier, serialized as a string.",
+		Serialize:   toID,
+		ParseValue:  toID,
+	}
+)
+
+func toInt(value interface{}) (interface{}, bool) {
+	var n float64
+	switch v := value.(type) {
+	case int:
+		n = float64(v)
+	case int32:
+		n = float64(v)
+	case int64:
+		n = float64(v)
+	case float64:
+		n = v
+	default:
+		return nil, false
+	}
+	if n != math.Trunc(n) || n < math.MinInt32 || n > math.MaxInt32 {
+		return nil, false
+	}
+	return int(n), true
+}
+
+func toFloat(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, !math.IsInf(v, 0) && !math.IsNaN(v)
+	}
+	return nil, false
+}
+
+func toID(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case int, int32, int64:
+		return fmt.Sprint(v), true
+	case float64:
+		if v == math.Trunc(v) {
+			return strconv.FormatFloat(v, 'f', -1, 64), true
+		}
+	}
+	return nil, false
+}
+
+// enumLiteral is an enum value written in a document
+type enumLiteral string
+
+// coerceVariables checks the request's variables against the operation's
+// definitions, applying defaults
+func (s *Schema) coerceVariables(op *operation, provided map[string]interface{}) (map[string]interface{}, []*Error) {
+	coerced := map[string]interface{}{}
+	var errs []*Error
+	for _, def := range op.variables {
+		t, err := s.inputType(def.typ)
+		if err != nil {
+			errs = append(errs, errorAt(def.loc, "variable $%s: %v", def.name, err))
+			continue
+		}
+
+		raw, present := provided[def.name]
+		if !present {
+			if def.defaultValue != nil {
+				value, err := coerceLiteral(def.defaultValue, t, nil)
+				if err != nil {
+					errs = append(errs, errorAt(def.loc, "variable $%s default: %v", def.name, err))
+				} else {
+					coerced[def.name] = value
+				}
+			} else if _, required := t.(*NonNull); required {
+				errs = append(errs, errorAt(def.loc, "variable $%s of type %s was not provided", def.name, def.typ))
+			}
+			continue
+		}
+
+		value, err := coerceInput(raw, t)
+		if err != nil {
+			errs = append(errs, errorAt(def.loc, "variable $%s: %v", def.name, err))
+			continue
+		}
+		coerced[def.name] = value
+	}
+	return coerced, errs
+}
+
+// inputType resolves a variable's declared type against the schema
+func (s *Schema) inputType(ref typeRef) (Type, error) {
+	var t Type
+	if ref.list != nil {
+		inner, err := s.inputType(*ref.list)
+		if err != nil {
+			return nil, err
+		}
+		t = &List{Of: inner}
+	} else {
+		named := s.types()[ref.name]
+		switch named.(type) {
+		case *Scalar, *Enum:
+			t = named
+		case nil:
+			return nil, fmt.Errorf("unknown type %s", ref.name)
+		default:
+			return nil, fmt.Errorf("%s is not an input type", ref.name)
+		}
+	}
+	if ref.nonNull {
+		t = &NonNull{Of: t}
+	}
+	return t, nil
+}
+
+// coerceInput converts a JSON variable value to an input type
+func coerceInput(value interface{}, t Type) (interface{}, error) {
+	if nonNull, ok := t.(*NonNull); ok {
+		if value == nil {
+			return nil, fmt.Errorf("expected %s, found null", t)
+		}
+		return coerceInput(value, nonNull.Of)
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *List:
+		items, ok := value.([]interface{})
+		if !ok {
+			item, err := coerceInput(value, t.Of)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{item}, nil
+		}
+		coerced := make([]interface{}, len(items))
+		for i, item := range items {
+			var err error
+			coerced[i], err = coerceInput(item, t.Of)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %v", i, err)
+			}
+		}
+		return coerced, nil
+	case *Scalar:
+		if number, ok := value.(int64); ok {
+			value = float64(number)
+		}
+		coerced, ok := t.ParseValue(value)
+		if !ok {
+			return nil, fmt.Errorf("expected %s, found %v", t.Name, value)
+		}
+		return coerced, nil
+	case *Enum:
+		name, ok := value.(string)
+		if !ok || !t.has(name) {
+			return nil, fmt.Errorf("expected %s, found %v", t.Name, value)
+		}
+		return name, nil
+	}
+	return nil, fmt.Errorf("%s is not an input type", t)
+}
+
+// errAbsent reports a literal that is an unset variable, which leaves the
+// argument out as though it had not been written
+var errAbsent = fmt.Errorf("absent")
+
+// coerceLiteral converts a literal in the document to an input type,
+// substituting variables
+func coerceLiteral(literal value, t Type, variables map[string]interface{}) (interface{}, error) {
+	if v, ok := literal.(*variable); ok {
+		value, present := variables[v.name]
+		if !present {
+			return nil, errAbsent
+		}
+		if _, required := t.(*NonNull); required && value == nil {
+			return nil, fmt.Errorf("expected %s, variable $%s is null", t, v.name)
+		}
+		return value, nil
+	}
+
+	if nonNull, ok := t.(*NonNull); ok {
+		if isNullLiteral(literal) {
+			return nil, fmt.Errorf("expected %s, found null", t)
+		}
+		return coerceLiteral(literal, nonNull.Of, variables)
+	}
+	if isNullLiteral(literal) {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *List:
+		list, ok := literal.(*listValue)
+		if !ok {
+			item, err := coerceLiteral(literal, t.Of, variables)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{item}, nil
+		}
+		coerced := []interface{}{}
+		for i, item := range list.items {
+			value, err := coerceLiteral(item, t.Of, variables)
+			if err == errAbsent {
+				value, err = nil, nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %v", i, err)
+			}
+			coerced = append(coerced, value)
+		}
+		return coerced, nil
+	case *Scalar:
+		scalar, ok := literal.(*scalarValue)
+		if ok {
+			value, err := scalarLiteral(scalar)
+			if err != nil {
+				return nil, err
+			}
+			if coerced, ok := t.ParseValue(value); ok {
+				return coerced, nil
+			}
+		}
+		return nil, fmt.Errorf("expected %s, found %s", t.Name, describeLiteral(literal))
+	case *Enum:
+		scalar, ok := literal.(*scalarValue)
+		if ok && scalar.kind == tokenName && t.has(scalar.value) {
+			return scalar.value, nil
+		}
+		return nil, fmt.Errorf("expected %s, found %s", t.Name, describeLiteral(literal))
+	}
+	return nil, fmt.Errorf("%s is not an input type", t)
+}
+
+// scalarLiteral returns the Go value of a scalar literal
+func scalarLiteral(literal *scalarValue) (interface{}, error) {
+	switch literal.kind {
+	case tokenInt:
+		n, err := strconv.ParseInt(literal.value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("integer %s is out of range", literal.value)
+		}
+		return n, nil
+	case tokenFloat:
+		return strconv.ParseFloat(literal.value, 64)
+	case tokenString:
+		return literal.value, nil
+	}
+	switch literal.value {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return enumLiteral(literal.value), nil
+}
+
+func isNullLiteral(literal value) bool {
+	scalar, ok := literal.(*scalarValue)
+	return ok && scalar.kind == tokenName && scalar.value == "null"
+}
+
+func describeLiteral(literal value) string {
+	switch v := literal.(type) {
+	case *scalarValue:
+		if v.kind == tokenString {
+			return strconv.Quote(v.value)
+		}
+		return v.value
+	case *listValue:
+		return "a list"
+	case *objectValue:
+		return "an object"
+	}
+	return "a value"
+}
+
+// literalString writes a default value as a GraphQL literal
+func literalString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = literalString(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprint(value)
+}
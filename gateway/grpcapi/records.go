@@ -0,0 +1,119 @@
+package grpcapi
+
+import "ekyc-gateway/ekycpb"
+
+// ledgerRecord is the part of a chaincode KYC record the service exposes
+type ledgerRecord struct {
+	ID                string         `json:"id"`
+	UserID            string         `json:"userId"`
+	OwnerMSP          string         `json:"ownerMsp,omitempty"`
+	EntityType        string         `json:"entityType"`
+	Name              string         `json:"name"`
+	Email             string         `json:"email"`
+	Phone             string         `json:"phone"`
+	PAN               string         `json:"pan"`
+	DateOfBirth       string         `json:"dateOfBirth"`
+	Address           address        `json:"address"`
+	DocumentHashes    []documentHash `json:"documentHashes"`
+	Status            string         `json:"status,omitempty"`
+	VerificationLevel string         `json:"verificationLevel,omitempty"`
+	CreatedAt         string         `json:"createdAt,omitempty"`
+	UpdatedAt         string         `json:"updatedAt,omitempty"`
+	VerifiedAt        string         `json:"verifiedAt,omitempty"`
+	VerifiedBy        string         `json:"verifiedBy,omitempty"`
+	Remarks           string         `json:"remarks,omitempty"`
+	RiskTier          string         `json:"riskTier,omitempty"`
+	SLADueAt          string         `json:"slaDueAt,omitempty"`
+	AssignedTo        string         `json:"assignedTo,omitempty"`
+	ExpiresAt         string         `json:"expiresAt,omitempty"`
+	Tags              []string       `json:"tags,omitempty"`
+}
+
+type address struct {
+	Street  string `json:"street"`
+	City    string `json:"city"`
+	State   string `json:"state"`
+	Pincode string `json:"pincode"`
+	Country string `json:"country"`
+}
+
+type documentHash struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	Hash       string `json:"hash"`
+	IPFSHash   string `json:"ipfsHash,omitempty"`
+	UploadedAt string `json:"uploadedAt"`
+}
+
+// newRecordFromProto builds the CreateKYC argument; the chaincode fills in
+// the status, timestamps and the fields it derives
+func newRecordFromProto(request *ekycpb.CreateKYCRequest) *ledgerRecord {
+	record := &ledgerRecord{
+		ID:                request.Id,
+		UserID:            request.UserId,
+		EntityType:        request.EntityType,
+		Name:              request.Name,
+		Email:             request.Email,
+		Phone:             request.Phone,
+		PAN:               request.Pan,
+		DateOfBirth:       request.DateOfBirth,
+		DocumentHashes:    []documentHash{},
+		VerificationLevel: request.VerificationLevel,
+	}
+	if a := request.Address; a != nil {
+		record.Address = address{Street: a.Street, City: a.City, State: a.State, Pincode: a.Pincode, Country: a.Country}
+	}
+	for _, d := range request.DocumentHashes {
+		record.DocumentHashes = append(record.DocumentHashes, documentHash{
+			ID:         d.Id,
+			Type:       d.Type,
+			Hash:       d.Hash,
+			IPFSHash:   d.IpfsHash,
+			UploadedAt: d.UploadedAt,
+		})
+	}
+	return record
+}
+
+func (r *ledgerRecord) toProto() *ekycpb.KYCRecord {
+	record := &ekycpb.KYCRecord{
+		Id:          r.ID,
+		UserId:      r.UserID,
+		OwnerMsp:    r.OwnerMSP,
+		EntityType:  r.EntityType,
+		Name:        r.Name,
+		Email:       r.Email,
+		Phone:       r.Phone,
+		Pan:         r.PAN,
+		DateOfBirth: r.DateOfBirth,
+		Address: &ekycpb.Address{
+			Street:  r.Address.Street,
+			City:    r.Address.City,
+			State:   r.Address.State,
+			Pincode: r.Address.Pincode,
+			Country: r.Address.Country,
+		},
+		Status:            r.Status,
+		VerificationLevel: r.VerificationLevel,
+		CreatedAt:         r.CreatedAt,
+		UpdatedAt:         r.UpdatedAt,
+		VerifiedAt:        r.VerifiedAt,
+		VerifiedBy:        r.VerifiedBy,
+		Remarks:           r.Remarks,
+		RiskTier:          r.RiskTier,
+		SlaDueAt:          r.SLADueAt,
+		AssignedTo:        r.AssignedTo,
+		ExpiresAt:         r.ExpiresAt,
+		Tags:              r.Tags,
+	}
+	for _, d := range r.DocumentHashes {
+		record.DocumentHashes = append(record.DocumentHashes, &ekycpb.DocumentHash{
+			Id:         d.ID,
+			Type:       d.Type,
+			Hash:       d.Hash,
+			IpfsHash:   d.IPFSHash,
+			UploadedAt: d.UploadedAt,
+		})
+	}
+	return record
+}
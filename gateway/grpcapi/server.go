@@ -0,0 +1,204 @@
+// Package grpcapi serves the gateway's gRPC interface, the KYCService defined
+// in proto/ekyc/v1, for integrators such as core-banking systems that prefer
+// typed stubs to the REST API. It calls the same chaincode functions.
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"ekyc-gateway/ekycpb"
+	"ekyc-gateway/fabric"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 200
+)
+
+// purposeService is the purpose code the service reads records for; reads
+// of other organisations' records need an access grant for it
+const purposeService = "KYC_SERVICE"
+
+// statuses are the record statuses QueryByStatus accepts
+var statuses = []string{"PENDING", "ON_HOLD", "VERIFIED", "REJECTED", "EXPIRED", "BLOCKED"}
+
+// Ledger evaluates and submits chaincode functions and streams their events
+type Ledger interface {
+	Evaluate(ctx context.Context, function string, args ...string) ([]byte, error)
+	Submit(ctx context.Context, function string, args ...string) (string, []byte, error)
+	ChaincodeEvents(ctx context.Context, checkpoint *fabric.Checkpoint) (*fabric.EventStream, error)
+}
+
+// Server implements ekycpb.KYCServiceServer over the chaincode
+type Server struct {
+	ekycpb.UnimplementedKYCServiceServer
+	ledger Ledger
+}
+
+// NewServer returns a KYCService backed by ledger
+func NewServer(ledger Ledger) *Server {
+	return &Server{ledger: ledger}
+}
+
+// CreateKYC submits CreateKYC and returns the record as committed, with the
+// status, timestamps and risk tier the chaincode assigned
+func (s *Server) CreateKYC(ctx context.Context, request *ekycpb.CreateKYCRequest) (*ekycpb.CreateKYCResponse, error) {
+	if request.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	kycData, err := json.Marshal(newRecordFromProto(request))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode KYC data: %v", err)
+	}
+	txID, _, err := s.ledger.Submit(ctx, "CreateKYC", string(kycData))
+	if err != nil {
+		return nil, ledgerStatus(err)
+	}
+
+	record, err := s.readKYC(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &ekycpb.CreateKYCResponse{TxId: txID, Record: record}, nil
+}
+
+// GetKYC returns a record by ID
+func (s *Server) GetKYC(ctx context.Context, request *ekycpb.GetKYCRequest) (*ekycpb.KYCRecord, error) {
+	if request.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	return s.readKYC(ctx, request.Id)
+}
+
+func (s *Server) readKYC(ctx context.Context, kycID string) (*ekycpb.KYCRecord, error) {
+	// submitted, so the chaincode commits the read's access log
+	_, result, err := s.ledger.Submit(ctx, "ReadKYC", kycID, purposeService)
+	if err != nil {
+		return nil, ledgerStatus(err)
+	}
+	var record ledgerRecord
+	err = json.Unmarshal(result, &record)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "malformed chaincode response")
+	}
+	return record.toProto(), nil
+}
+
+// QueryByStatus returns a page of records with a status, resuming from the
+// bookmark of the previous page
+func (s *Server) QueryByStatus(ctx context.Context, request *ekycpb.QueryByStatusRequest) (*ekycpb.QueryByStatusResponse, error) {
+	if !validStatus(request.Status) {
+		return nil, status.Errorf(codes.InvalidArgument, "status must be one of %s", strings.Join(statuses, ", "))
+	}
+	pageSize := request.PageSize
+	switch {
+	case pageSize < 0 || pageSize > maxPageSize:
+		return nil, status.Errorf(codes.InvalidArgument, "page_size must be between 1 and %d", maxPageSize)
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	}
+
+	_, result, err := s.ledger.Submit(ctx, "GetKYCByStatusWithPagination", request.Status, fmt.Sprint(pageSize), request.Bookmark, purposeService)
+	if err != nil {
+		return nil, ledgerStatus(err)
+	}
+	var page struct {
+		Records   []*ledgerRecord `json:"records"`
+		Bookmark  string          `json:"bookmark"`
+		Truncated bool            `json:"truncated"`
+	}
+	err = json.Unmarshal(result, &page)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "malformed chaincode response")
+	}
+
+	response := &ekycpb.QueryByStatusResponse{Truncated: page.Truncated}
+	for _, record := range page.Records {
+		response.Records = append(response.Records, record.toProto())
+	}
+	// the chaincode returns a bookmark even on the last page
+	if len(page.Records) == int(pageSize) || page.Truncated {
+		response.Bookmark = page.Bookmark
+	}
+	return response, nil
+}
+
+// StreamEvents relays chaincode events in commit order until the client
+// cancels or the peer's stream fails. It does not reconnect: a client resumes
+// by passing the position of the last event it handled.
+func (s *Server) StreamEvents(request *ekycpb.StreamEventsRequest, stream ekycpb.KYCService_StreamEventsServer) error {
+	ctx := stream.Context()
+	var checkpoint *fabric.Checkpoint
+	if request.After != nil {
+		checkpoint = &fabric.Checkpoint{BlockNumber: request.After.BlockNumber, TxID: request.After.TxId}
+	}
+
+	events, err := s.ledger.ChaincodeEvents(ctx, checkpoint)
+	if err != nil {
+		return ledgerStatus(err)
+	}
+	for {
+		event, err := events.Next()
+		if err != nil {
+			if ctx.Err() != nil {
+				return status.FromContextError(ctx.Err()).Err()
+			}
+			log.Printf("chaincode event stream failed: %v", err)
+			return status.Error(codes.Unavailable, "event stream interrupted; resume from the last event received")
+		}
+
+		var named struct {
+			KYCID string `json:"kycId"`
+		}
+		json.Unmarshal(event.Payload, &named)
+		if request.KycId != "" && named.KYCID != request.KycId {
+			continue
+		}
+		err = stream.Send(&ekycpb.Event{
+			BlockNumber: event.BlockNumber,
+			TxId:        event.TxID,
+			Name:        event.Name,
+			Payload:     event.Payload,
+			KycId:       named.KYCID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func validStatus(value string) bool {
+	for _, s := range statuses {
+		if s == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ledgerStatus maps a failed chaincode call to a gRPC status. Rejections by
+// the chaincode carry its message; other failures are logged and reported as
+// unavailable so the caller can retry.
+func ledgerStatus(err error) error {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "does not exist"):
+		return status.Error(codes.NotFound, message)
+	case strings.Contains(message, "already exists"):
+		return status.Error(codes.AlreadyExists, message)
+	case fabric.IsMVCCConflict(err):
+		return status.Error(codes.Aborted, message)
+	case strings.HasPrefix(message, "failed to endorse"):
+		return status.Error(codes.FailedPrecondition, message)
+	}
+	log.Printf("chaincode call failed: %v", err)
+	return status.Error(codes.Unavailable, "ledger unavailable")
+}
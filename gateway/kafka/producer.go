@@ -0,0 +1,356 @@
+// Package kafka is a minimal Kafka producer: it discovers partition leaders
+// from cluster metadata and publishes uncompressed record batches with acks
+// from all in-sync replicas, which is all the event bridge needs. It speaks
+// plaintext or TLS; SASL is not supported.
+package kafka
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Config locates a Kafka cluster
+type Config struct {
+	Brokers  []string    // bootstrap host:port addresses
+	ClientID string      // reported to brokers in every request
+	TLS      *tls.Config // nil connects in plaintext
+	Timeout  time.Duration
+}
+
+// Producer publishes messages. It is safe for concurrent use.
+type Producer struct {
+	config Config
+
+	mu         sync.Mutex
+	brokers    map[int32]string   // node ID to address
+	partitions map[string][]int32 // topic to the leader of each partition
+	conns      map[string]*brokerConn
+	next       map[string]int // round-robin position for unkeyed messages
+}
+
+type brokerConn struct {
+	mu          sync.Mutex
+	conn        net.Conn
+	reader      *bufio.Reader
+	correlation int32
+}
+
+// NewProducer returns a producer for the cluster. Connections are opened on
+// first use.
+func NewProducer(config Config) *Producer {
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.ClientID == "" {
+		config.ClientID = "ekyc-gateway"
+	}
+	return &Producer{
+		config:     config,
+		brokers:    map[int32]string{},
+		partitions: map[string][]int32{},
+		conns:      map[string]*brokerConn{},
+		next:       map[string]int{},
+	}
+}
+
+// Close closes the producer's broker connections
+func (p *Producer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for addr, conn := range p.conns {
+		conn.conn.Close()
+		delete(p.conns, addr)
+	}
+	return nil
+}
+
+// Produce publishes a message and returns once every in-sync replica of its
+// partition has it. Leader changes are retried after refreshing metadata.
+func (p *Producer) Produce(ctx context.Context, message *Message) error {
+	var err error
+	for attempt := 0; attempt < 4; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * 250 * time.Millisecond):
+			}
+			p.forget(message.Topic)
+		}
+
+		var partition int32
+		var leader string
+		partition, leader, err = p.route(ctx, message)
+		if err != nil {
+			if kafkaErr, ok := err.(Error); ok && !kafkaErr.retriable() {
+				return err
+			}
+			continue
+		}
+		err = p.produce(ctx, leader, message, partition)
+		if kafkaErr, ok := err.(Error); err == nil || (ok && !kafkaErr.retriable()) {
+			return err
+		}
+	}
+	return fmt.Errorf("failed to publish to %s: %v", message.Topic, err)
+}
+
+// route picks a message's partition and the address of its leader
+func (p *Producer) route(ctx context.Context, message *Message) (int32, string, error) {
+	p.mu.Lock()
+	leaders, ok := p.partitions[message.Topic]
+	p.mu.Unlock()
+	if !ok {
+		err := p.refresh(ctx, message.Topic)
+		if err != nil {
+			return 0, "", err
+		}
+		p.mu.Lock()
+		leaders = p.partitions[message.Topic]
+		p.mu.Unlock()
+	}
+	if len(leaders) == 0 {
+		return 0, "", ErrUnknownTopicOrPartition
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var partition int
+	if message.Key != nil {
+		partition = partitionFor(message.Key, len(leaders))
+	} else {
+		partition = p.next[message.Topic] % len(leaders)
+		p.next[message.Topic]++
+	}
+	addr, ok := p.brokers[leaders[partition]]
+	if !ok {
+		return 0, "", ErrLeaderNotAvailable
+	}
+	return int32(partition), addr, nil
+}
+
+// forget drops a topic's cached leaders so the next produce refreshes them
+func (p *Producer) forget(topic string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.partitions, topic)
+}
+
+// refresh reads the topic's partition leaders from the first bootstrap
+// broker that answers
+func (p *Producer) refresh(ctx context.Context, topic string) error {
+	request := &encoder{}
+	request.int32(1)
+	request.string(topic)
+	request.bool(false) // never auto-create topics
+
+	var err error
+	for _, addr := range p.config.Brokers {
+		var response []byte
+		response, err = p.request(ctx, addr, apiMetadata, metadataVersion, request.buf)
+		if err != nil {
+			continue
+		}
+		return p.applyMetadata(topic, response)
+	}
+	return fmt.Errorf("failed to read metadata for %s: %v", topic, err)
+}
+
+func (p *Producer) applyMetadata(topic string, response []byte) error {
+	d := &decoder{buf: response}
+	d.int32() // throttle time
+	brokers := map[int32]string{}
+	for n := d.arrayLen(); n > 0; n-- {
+		id := d.int32()
+		host := d.string()
+		port := d.int32()
+		d.string() // rack
+		brokers[id] = net.JoinHostPort(host, strconv.Itoa(int(port)))
+	}
+	d.string() // cluster ID
+	d.int32()  // controller ID
+
+	var leaders []int32
+	var topicErr Error
+	for n := d.arrayLen(); n > 0; n-- {
+		code := Error(d.int16())
+		name := d.string()
+		d.int8() // is internal
+		partitions := d.arrayLen()
+		topicLeaders := make([]int32, partitions)
+		for i := 0; i < partitions; i++ {
+			d.int16() // partition error
+			index := d.int32()
+			leader := d.int32()
+			d.skipInt32Array() // replicas
+			d.skipInt32Array() // in-sync replicas
+			d.skipInt32Array() // offline replicas
+			if index >= 0 && int(index) < partitions {
+				topicLeaders[index] = leader
+			}
+		}
+		if name == topic {
+			leaders, topicErr = topicLeaders, code
+		}
+	}
+	if d.err != nil {
+		return d.err
+	}
+	if topicErr != 0 {
+		return topicErr
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id, addr := range brokers {
+		p.brokers[id] = addr
+	}
+	p.partitions[topic] = leaders
+	return nil
+}
+
+// produce sends one message to its partition's leader
+func (p *Producer) produce(ctx context.Context, leader string, message *Message, partition int32) error {
+	if message.Time == 0 {
+		message.Time = time.Now().UnixMilli()
+	}
+	request := &encoder{}
+	request.nullableString(nil) // transactional ID
+	request.int16(-1)           // acks from all in-sync replicas
+	request.int32(int32(p.config.Timeout.Milliseconds()))
+	request.int32(1)
+	request.string(message.Topic)
+	request.int32(1)
+	request.int32(partition)
+	request.bytes(recordBatch([]*Message{message}))
+
+	response, err := p.request(ctx, leader, apiProduce, produceVersion, request.buf)
+	if err != nil {
+		return err
+	}
+
+	d := &decoder{buf: response}
+	for topics := d.arrayLen(); topics > 0; topics-- {
+		d.string()
+		for partitions := d.arrayLen(); partitions > 0; partitions-- {
+			d.int32() // partition
+			code := Error(d.int16())
+			d.int64() // base offset
+			d.int64() // log append time
+			d.int64() // log start offset
+			if d.err == nil && code != 0 {
+				return code
+			}
+		}
+	}
+	return d.err
+}
+
+// request sends a request to a broker and returns the response body after
+// its correlation ID
+func (p *Producer) request(ctx context.Context, addr string, apiKey int16, version int16, body []byte) ([]byte, error) {
+	conn, err := p.conn(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	deadline := time.Now().Add(p.config.Timeout + 5*time.Second)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.conn.SetDeadline(deadline)
+
+	conn.correlation++
+	clientID := p.config.ClientID
+	header := &encoder{buf: make([]byte, 4, 64+len(body))}
+	header.int16(apiKey)
+	header.int16(version)
+	header.int32(conn.correlation)
+	header.nullableString(&clientID)
+	frame := append(header.buf, body...)
+	binary.BigEndian.PutUint32(frame, uint32(len(frame)-4))
+
+	response, err := conn.roundTrip(frame)
+	if err != nil {
+		p.drop(addr, conn)
+		return nil, err
+	}
+	d := &decoder{buf: response}
+	if d.int32() != conn.correlation || d.err != nil {
+		p.drop(addr, conn)
+		return nil, errors.New("kafka: response out of sequence")
+	}
+	return d.buf, nil
+}
+
+func (c *brokerConn) roundTrip(frame []byte) ([]byte, error) {
+	_, err := c.conn.Write(frame)
+	if err != nil {
+		return nil, err
+	}
+	var size [4]byte
+	_, err = io.ReadFull(c.reader, size[:])
+	if err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > 64<<20 {
+		return nil, fmt.Errorf("kafka: response of %d bytes is too large", n)
+	}
+	response := make([]byte, n)
+	_, err = io.ReadFull(c.reader, response)
+	return response, err
+}
+
+// conn returns the open connection to a broker, dialling it if needed
+func (p *Producer) conn(ctx context.Context, addr string) (*brokerConn, error) {
+	p.mu.Lock()
+	conn, ok := p.conns[addr]
+	p.mu.Unlock()
+	if ok {
+		return conn, nil
+	}
+
+	dialer := &net.Dialer{Timeout: p.config.Timeout}
+	var netConn net.Conn
+	var err error
+	if p.config.TLS != nil {
+		netConn, err = (&tls.Dialer{NetDialer: dialer, Config: p.config.TLS}).DialContext(ctx, "tcp", addr)
+	} else {
+		netConn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("kafka: failed to connect to %s: %v", addr, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if existing, ok := p.conns[addr]; ok {
+		netConn.Close()
+		return existing, nil
+	}
+	conn = &brokerConn{conn: netConn, reader: bufio.NewReader(netConn)}
+	p.conns[addr] = conn
+	return conn, nil
+}
+
+// drop closes a connection a failed request left unusable
+func (p *Producer) drop(addr string, conn *brokerConn) {
+	conn.conn.Close()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conns[addr] == conn {
+		delete(p.conns, addr)
+	}
+}
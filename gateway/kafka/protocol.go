@@ -0,0 +1,300 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// API keys and the versions of them the producer speaks. Both versions
+// predate flexible (tagged-field) encodings and are served by Kafka 2.1 and
+// later, including 4.x.
+const (
+	apiProduce  = 0
+	apiMetadata = 3
+
+	produceVersion  = 7
+	metadataVersion = 5
+)
+
+// Error is an error code returned by a broker
+type Error int16
+
+// Error codes the producer acts on
+const (
+	ErrUnknownTopicOrPartition Error = 3
+	ErrLeaderNotAvailable      Error = 5
+	ErrNotLeaderOrFollower     Error = 6
+	ErrRequestTimedOut         Error = 7
+	ErrMessageTooLarge         Error = 10
+	ErrNotEnoughReplicas       Error = 19
+	ErrNotEnoughReplicasAfter  Error = 20
+	ErrTopicAuthorization      Error = 29
+)
+
+var errorNames = map[Error]string{
+	ErrUnknownTopicOrPartition: "UNKNOWN_TOPIC_OR_PARTITION",
+	ErrLeaderNotAvailable:      "LEADER_NOT_AVAILABLE",
+	ErrNotLeaderOrFollower:     "NOT_LEADER_OR_FOLLOWER",
+	ErrRequestTimedOut:         "REQUEST_TIMED_OUT",
+	ErrMessageTooLarge:         "MESSAGE_TOO_LARGE",
+	ErrNotEnoughReplicas:       "NOT_ENOUGH_REPLICAS",
+	ErrNotEnoughReplicasAfter:  "NOT_ENOUGH_REPLICAS_AFTER_APPEND",
+	ErrTopicAuthorization:      "TOPIC_AUTHORIZATION_FAILED",
+}
+
+func (e Error) Error() string {
+	if name, ok := errorNames[e]; ok {
+		return "kafka: " + name
+	}
+	return fmt.Sprintf("kafka: error code %d", int16(e))
+}
+
+// retriable reports whether a produce may succeed if retried after a
+// metadata refresh
+func (e Error) retriable() bool {
+	switch e {
+	case ErrUnknownTopicOrPartition, ErrLeaderNotAvailable, ErrNotLeaderOrFollower, ErrRequestTimedOut, ErrNotEnoughReplicas, ErrNotEnoughReplicasAfter:
+		return true
+	}
+	return false
+}
+
+// encoder appends Kafka protocol primitives to a buffer
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) int8(v int8)   { e.buf = append(e.buf, byte(v)) }
+func (e *encoder) int16(v int16) { e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(v)) }
+func (e *encoder) int32(v int32) { e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(v)) }
+func (e *encoder) int64(v int64) { e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(v)) }
+func (e *encoder) bool(v bool) {
+	if v {
+		e.int8(1)
+	} else {
+		e.int8(0)
+	}
+}
+
+func (e *encoder) string(v string) {
+	e.int16(int16(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+func (e *encoder) nullableString(v *string) {
+	if v == nil {
+		e.int16(-1)
+		return
+	}
+	e.string(*v)
+}
+
+func (e *encoder) bytes(v []byte) {
+	e.int32(int32(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+func (e *encoder) varint(v int64) {
+	e.buf = binary.AppendVarint(e.buf, v)
+}
+
+// varintBytes writes a record field: a varint length, -1 for null, then the bytes
+func (e *encoder) varintBytes(v []byte) {
+	if v == nil {
+		e.varint(-1)
+		return
+	}
+	e.varint(int64(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+var errShortResponse = errors.New("kafka: truncated response")
+
+// decoder reads Kafka protocol primitives; the first failure sticks
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.buf) < n {
+		d.err = errShortResponse
+		return nil
+	}
+	v := d.buf[:n]
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) int8() int8 {
+	v := d.take(1)
+	if v == nil {
+		return 0
+	}
+	return int8(v[0])
+}
+
+func (d *decoder) int16() int16 {
+	v := d.take(2)
+	if v == nil {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(v))
+}
+
+func (d *decoder) int32() int32 {
+	v := d.take(4)
+	if v == nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(v))
+}
+
+func (d *decoder) int64() int64 {
+	v := d.take(8)
+	if v == nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(v))
+}
+
+func (d *decoder) string() string {
+	n := d.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(d.take(int(n)))
+}
+
+// arrayLen reads an array length, treating null as empty
+func (d *decoder) arrayLen() int {
+	n := d.int32()
+	if n < 0 {
+		return 0
+	}
+	if int(n) > len(d.buf) {
+		// every element takes at least a byte
+		d.err = errShortResponse
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) skipInt32Array() {
+	for n := d.arrayLen(); n > 0; n-- {
+		d.int32()
+	}
+}
+
+// Message is a record to publish
+type Message struct {
+	Topic   string
+	Key     []byte // picks the partition; nil spreads messages round-robin
+	Value   []byte
+	Headers []Header
+	Time    int64 // milliseconds since the Unix epoch
+}
+
+// Header is a record header
+type Header struct {
+	Key   string
+	Value []byte
+}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// recordBatch encodes messages as one uncompressed v2 record batch, the
+// on-disk and wire format since Kafka 0.11
+func recordBatch(messages []*Message) []byte {
+	first, last := messages[0].Time, messages[0].Time
+	for _, m := range messages {
+		if m.Time < first {
+			first = m.Time
+		}
+		if m.Time > last {
+			last = m.Time
+		}
+	}
+
+	body := &encoder{}
+	body.int16(0) // attributes: no compression, create time, not transactional
+	body.int32(int32(len(messages) - 1))
+	body.int64(first)
+	body.int64(last)
+	body.int64(-1) // producer ID: not idempotent
+	body.int16(-1) // producer epoch
+	body.int32(-1) // base sequence
+	body.int32(int32(len(messages)))
+	for i, m := range messages {
+		record := &encoder{}
+		record.int8(0) // attributes
+		record.varint(m.Time - first)
+		record.varint(int64(i))
+		record.varintBytes(m.Key)
+		record.varintBytes(m.Value)
+		record.varint(int64(len(m.Headers)))
+		for _, h := range m.Headers {
+			record.varintBytes([]byte(h.Key))
+			record.varintBytes(h.Value)
+		}
+		body.varint(int64(len(record.buf)))
+		body.buf = append(body.buf, record.buf...)
+	}
+
+	batch := &encoder{}
+	batch.int64(0)                                // base offset, assigned by the broker
+	batch.int32(int32(4 + 1 + 4 + len(body.buf))) // length from the partition leader epoch on
+	batch.int32(-1)                               // partition leader epoch
+	batch.int8(2)                                 // magic
+	batch.int32(int32(crc32.Checksum(body.buf, castagnoli)))
+	batch.buf = append(batch.buf, body.buf...)
+	return batch.buf
+}
+
+// murmur2 is the hash of Kafka's default partitioner, so keyed messages land
+// on the partition a Java producer would choose
+func murmur2(data []byte) int32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+		r           = 24
+	)
+	length := len(data)
+	h := seed ^ uint32(length)
+	for i := 0; i+4 <= length; i += 4 {
+		k := binary.LittleEndian.Uint32(data[i:])
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+
+	tail := data[length&^3:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= m
+	}
+
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return int32(h)
+}
+
+// partitionFor maps a key to one of n partitions as the Java client does
+func partitionFor(key []byte, n int) int {
+	return int(murmur2(key)&0x7fffffff) % n
+}
@@ -0,0 +1,219 @@
+// Package notify tells customers about decisions on their KYC records. The
+// worker turns KYCVerified, KYCRejected and KYCExpiringSoon chaincode events
+// into email and SMS messages rendered from templates, and hands them to a
+// Provider for each channel.
+//
+// Preferences come from the ledger, not the event: the worker reads the
+// record when it handles the event and honours the preferences stored on it,
+// so a customer who opts out is never messaged by a replayed event. For the
+// same reason an event is skipped when the record has since moved on, such
+// as a KYCVerified event for a record that has been rejected again.
+//
+// Delivery is at least once. A provider failure that may clear stops the
+// event stream, which fabric.Listen reopens from the last event handled; the
+// retry skips channels that already succeeded, unless the worker restarted in
+// between. Failures retrying cannot fix, such as a number the SMS provider
+// rejects, are logged and the event is passed over.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"ekyc-gateway/fabric"
+)
+
+// Channels
+const (
+	Email = "email"
+	SMS   = "sms"
+)
+
+// eventStatus is the status a record must still have for each event to be
+// worth telling the customer about
+var eventStatus = map[string]string{
+	"KYCVerified":     "VERIFIED",
+	"KYCRejected":     "REJECTED",
+	"KYCExpiringSoon": "VERIFIED",
+}
+
+// Message is a rendered notification
+type Message struct {
+	Channel string
+	To      string // email address or E.164 phone number
+	Subject string // email only
+	Body    string
+	Event   string
+	KYCID   string
+}
+
+// Provider sends messages on one channel
+type Provider interface {
+	Send(ctx context.Context, message *Message) error
+}
+
+// permanentError marks a provider failure that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as one retrying cannot fix, so the worker logs it and
+// moves on rather than retrying the event
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Ledger submits the chaincode's record reads, which the chaincode logs
+type Ledger interface {
+	Submit(ctx context.Context, function string, args ...string) (string, []byte, error)
+}
+
+// purposeNotification is the purpose code the worker reads records for;
+// reads of other organisations' records need an access grant for it
+const purposeNotification = "CUSTOMER_NOTIFICATION"
+
+// record is the part of a ledger record notifications need
+type record struct {
+	ID            string       `json:"id"`
+	Name          string       `json:"name"`
+	Email         string       `json:"email"`
+	Phone         string       `json:"phone"`
+	Status        string       `json:"status"`
+	Remarks       string       `json:"remarks"`
+	ExpiresAt     string       `json:"expiresAt"`
+	Notifications *preferences `json:"notifications"`
+}
+
+// preferences mirrors the chaincode's NotificationPreferences
+type preferences struct {
+	Email    bool     `json:"email"`
+	SMS      bool     `json:"sms"`
+	Muted    []string `json:"muted"`
+	Language string   `json:"language"`
+}
+
+// defaultPreferences apply to records that have none
+var defaultPreferences = preferences{Email: true}
+
+// Worker renders and sends notifications for chaincode events
+type Worker struct {
+	ledger    Ledger
+	templates *Templates
+	providers map[string]Provider
+
+	mu   sync.Mutex
+	sent map[string]bool // record channels of the event being retried that succeeded
+}
+
+// NewWorker returns a worker sending through the given providers, keyed by
+// channel. Channels without a provider are not notified.
+func NewWorker(ledger Ledger, templates *Templates, providers map[string]Provider) *Worker {
+	return &Worker{ledger: ledger, templates: templates, providers: providers, sent: map[string]bool{}}
+}
+
+// Handle notifies the customers of the records an event names: one for
+// KYCVerified and KYCRejected, and every record an expiry sweep reminded for
+// KYCExpiringSoon. Use it as the fabric.Listen handler.
+func (w *Worker) Handle(ctx context.Context, event *fabric.Event) error {
+	wantStatus, ok := eventStatus[event.Name]
+	if !ok {
+		return nil
+	}
+	var named struct {
+		KYCID  string   `json:"kycId"`
+		KYCIDs []string `json:"kycIds"`
+	}
+	if json.Unmarshal(event.Payload, &named) != nil || (named.KYCID == "" && len(named.KYCIDs) == 0) {
+		log.Printf("skipping %s event in %s: no kycId in payload", event.Name, event.TxID)
+		return nil
+	}
+	kycIDs := named.KYCIDs
+	if named.KYCID != "" {
+		kycIDs = []string{named.KYCID}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, kycID := range kycIDs {
+		err := w.notify(ctx, event, kycID, wantStatus)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the event is handled; later events start with a clean slate
+	w.sent = map[string]bool{}
+	return nil
+}
+
+// notify tells the customer of one record about an event, if the record
+// still has wantStatus. w.mu must be held.
+func (w *Worker) notify(ctx context.Context, event *fabric.Event, kycID string, wantStatus string) error {
+	_, result, err := w.ledger.Submit(ctx, "ReadKYC", kycID, purposeNotification)
+	if err != nil && strings.Contains(err.Error(), "does not exist") {
+		// deleted since; there is no one left to tell
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read KYC record %s: %v", kycID, err)
+	}
+	var kyc record
+	err = json.Unmarshal(result, &kyc)
+	if err != nil {
+		return fmt.Errorf("malformed KYC record %s: %v", kycID, err)
+	}
+	if kyc.Status != wantStatus || (event.Name == "KYCExpiringSoon" && kyc.ExpiresAt == "") {
+		return nil
+	}
+
+	prefs := defaultPreferences
+	if kyc.Notifications != nil {
+		prefs = *kyc.Notifications
+	}
+	for _, muted := range prefs.Muted {
+		if muted == event.Name {
+			return nil
+		}
+	}
+
+	for _, channel := range []string{Email, SMS} {
+		to := kyc.Email
+		enabled := prefs.Email
+		if channel == SMS {
+			to, enabled = kyc.Phone, prefs.SMS
+		}
+		provider := w.providers[channel]
+		sentKey := event.TxID + "/" + kyc.ID + "/" + channel
+		if !enabled || to == "" || provider == nil || w.sent[sentKey] {
+			continue
+		}
+
+		message, err := w.templates.render(event.Name, channel, prefs.Language, &kyc)
+		if err != nil {
+			return err
+		}
+		message.To = to
+		err = provider.Send(ctx, message)
+		if err != nil && !isPermanent(err) {
+			return fmt.Errorf("failed to send %s %s notification for %s: %v", event.Name, channel, kyc.ID, err)
+		}
+		if err != nil {
+			log.Printf("dropping %s %s notification for %s: %v", event.Name, channel, kyc.ID, err)
+		}
+		w.sent[sentKey] = true
+	}
+	return nil
+}
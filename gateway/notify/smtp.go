@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// SMTP sends email through a mail relay. It upgrades the connection with
+// STARTTLS when the relay offers it, and authenticates only over TLS.
+type SMTP struct {
+	Addr     string // relay host:port, usually port 587
+	From     string // sender address, optionally with a display name
+	Username string // empty sends without authenticating
+	Password string
+}
+
+// Send implements Provider
+func (s *SMTP) Send(ctx context.Context, message *Message) error {
+	from, err := mail.ParseAddress(s.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address %q: %v", s.From, err)
+	}
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return Permanent(fmt.Errorf("invalid recipient address: %v", err))
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		host, _, _ := net.SplitHostPort(s.Addr)
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+
+	// smtp.SendMail takes no context, so give up on it rather than wait
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(s.Addr, auth, from.Address, []string{to.Address}, emailBody(from, to, message))
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err = <-done:
+	}
+
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return Permanent(err)
+	}
+	return err
+}
+
+// emailBody formats a plain-text RFC 5322 message
+func emailBody(from *mail.Address, to *mail.Address, message *Message) []byte {
+	var id [12]byte
+	rand.Read(id[:])
+	_, domain, _ := strings.Cut(from.Address, "@")
+
+	var b strings.Builder
+	b.WriteString("From: " + from.String() + "\r\n")
+	b.WriteString("To: " + to.String() + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", message.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("Message-ID: <" + hex.EncodeToString(id[:]) + "@" + domain + ">\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	// smtp's DATA writer converts line endings and escapes leading dots
+	b.WriteString(message.Body + "\n")
+	return []byte(b.String())
+}
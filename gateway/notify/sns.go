@@ -0,0 +1,144 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SNS sends SMS through Amazon SNS, publishing directly to the phone number
+// as a transactional message
+type SNS struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // for temporary credentials
+	SenderID        string // optional alphanumeric sender ID, where the destination allows one
+	Client          *http.Client
+	Endpoint        string // overrides https://sns.<region>.amazonaws.com/
+}
+
+// Send implements Provider
+func (s *SNS) Send(ctx context.Context, message *Message) error {
+	form := url.Values{
+		"Action":      {"Publish"},
+		"Version":     {"2010-03-31"},
+		"PhoneNumber": {message.To},
+		"Message":     {message.Body},
+
+		"MessageAttributes.entry.1.Name":              {"AWS.SNS.SMS.SMSType"},
+		"MessageAttributes.entry.1.Value.DataType":    {"String"},
+		"MessageAttributes.entry.1.Value.StringValue": {"Transactional"},
+	}
+	if s.SenderID != "" {
+		form.Set("MessageAttributes.entry.2.Name", "AWS.SNS.SMS.SenderID")
+		form.Set("MessageAttributes.entry.2.Value.DataType", "String")
+		form.Set("MessageAttributes.entry.2.Value.StringValue", s.SenderID)
+	}
+	body := form.Encode()
+
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = "https://sns." + s.Region + ".amazonaws.com/"
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	if s.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+	signV4(request, []byte(body), s.AccessKeyID, s.SecretAccessKey, s.Region, "sns", time.Now())
+
+	return sendRequest(s.Client, request, "SNS")
+}
+
+// signV4 adds an AWS Signature Version 4 Authorization header to request,
+// signing its host, content type, date and any security token
+func signV4(request *http.Request, body []byte, accessKeyID string, secretAccessKey string, region string, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	request.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": request.URL.Host, "x-amz-date": amzDate}
+	for _, name := range []string{"Content-Type", "X-Amz-Security-Token"} {
+		if value := request.Header.Get(name); value != "" {
+			headers[strings.ToLower(name)] = value
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := request.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		path,
+		request.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// sendRequest sends a provider API request. A rejected request, such as one
+// to an invalid number, is permanent; authorization failures and throttling
+// are retried, since they clear without the message changing.
+func sendRequest(client *http.Client, request *http.Request, provider string) error {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	detail, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	if response.StatusCode >= 200 && response.StatusCode <= 299 {
+		return nil
+	}
+
+	err = fmt.Errorf("%s responded %s: %s", provider, response.Status, strings.TrimSpace(string(detail)))
+	if (response.StatusCode == http.StatusBadRequest || response.StatusCode == http.StatusUnprocessableEntity) && !strings.Contains(string(detail), "Throttl") {
+		return Permanent(err)
+	}
+	return err
+}
@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// defaultTemplates are the built-in English templates, keyed by event and
+// channel. An email template's first line is its subject.
+var defaultTemplates = map[string]string{
+	"KYCVerified.email": `Your KYC verification is complete
+Dear {{.Name}},
+
+Your KYC application {{.KYCID}} has been verified.{{if .ExpiresAt}} It is valid until {{.ExpiresAt}}, when you will be asked to renew it.{{end}}
+
+This is an automated message; please do not reply.`,
+
+	"KYCVerified.sms": `Your KYC application {{.KYCID}} has been verified.`,
+
+	"KYCRejected.email": `Your KYC application needs attention
+Dear {{.Name}},
+
+We could not verify your KYC application {{.KYCID}}.{{if .Remarks}}
+
+Reason: {{.Remarks}}{{end}}
+
+Please contact your bank or submit the application again with the corrected details.
+
+This is an automated message; please do not reply.`,
+
+	"KYCRejected.sms": `We could not verify your KYC application {{.KYCID}}. Please contact your bank.`,
+
+	"KYCExpiringSoon.email": `Your KYC is due for renewal
+Dear {{.Name}},
+
+Your KYC verification {{.KYCID}} expires on {{.ExpiresAt}}. Please renew it before then to keep your accounts active.
+
+This is an automated message; please do not reply.`,
+
+	"KYCExpiringSoon.sms": `Your KYC {{.KYCID}} expires on {{.ExpiresAt}}. Please renew it to keep your accounts active.`,
+}
+
+// templateData is what templates can refer to
+type templateData struct {
+	KYCID     string
+	Name      string
+	Status    string
+	Remarks   string
+	ExpiresAt string // the date part only
+}
+
+// Templates renders notifications for each event, channel and language
+type Templates struct {
+	templates map[string]*template.Template // event.channel[.language]
+}
+
+// LoadTemplates returns the built-in templates overridden and extended by
+// the files in dir, if it is not empty. Files are named
+// <event>.<channel>.tmpl, or <event>.<channel>.<language>.tmpl for a
+// language-specific variant, such as KYCRejected.sms.hi.tmpl.
+func LoadTemplates(dir string) (*Templates, error) {
+	t := &Templates{templates: map[string]*template.Template{}}
+	for name, text := range defaultTemplates {
+		err := t.add(name, text)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if dir == "" {
+		return t, nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".tmpl")
+		parts := strings.Split(name, ".")
+		if len(parts) < 2 || len(parts) > 3 || eventStatus[parts[0]] == "" || (parts[1] != Email && parts[1] != SMS) {
+			return nil, fmt.Errorf("unexpected template %s; name templates <event>.<channel>[.<language>].tmpl", path)
+		}
+		if len(parts) == 3 {
+			name = parts[0] + "." + parts[1] + "." + strings.ToLower(parts[2])
+		}
+		text, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		err = t.add(name, string(text))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+func (t *Templates) add(name string, text string) error {
+	parsed, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid template %s: %v", name, err)
+	}
+	t.templates[name] = parsed
+	return nil
+}
+
+// render renders an event's message on channel, in language when there is a
+// template for it and in the default language otherwise
+func (t *Templates) render(event string, channel string, language string, kyc *record) (*Message, error) {
+	key := event + "." + channel
+	tmpl := t.templates[key]
+	language = strings.ToLower(language)
+	for language != "" {
+		if variant, ok := t.templates[key+"."+language]; ok {
+			tmpl = variant
+			break
+		}
+		// fall back from a regional tag such as hi-IN to hi
+		cut := strings.LastIndex(language, "-")
+		if cut < 0 {
+			break
+		}
+		language = language[:cut]
+	}
+	if tmpl == nil {
+		return nil, fmt.Errorf("no %s template for %s", channel, event)
+	}
+
+	data := templateData{KYCID: kyc.ID, Name: kyc.Name, Status: kyc.Status, Remarks: kyc.Remarks, ExpiresAt: kyc.ExpiresAt}
+	if len(data.ExpiresAt) > 10 {
+		data.ExpiresAt = data.ExpiresAt[:10]
+	}
+	var rendered strings.Builder
+	err := tmpl.Execute(&rendered, &data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s: %v", tmpl.Name(), err)
+	}
+
+	message := &Message{Channel: channel, Event: event, KYCID: kyc.ID, Body: strings.TrimSpace(rendered.String())}
+	if channel == Email {
+		subject, body, _ := strings.Cut(message.Body, "\n")
+		message.Subject = strings.TrimSpace(subject)
+		message.Body = strings.TrimSpace(body)
+	}
+	return message, nil
+}
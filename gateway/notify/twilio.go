@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Twilio sends SMS through Twilio's Messages API
+type Twilio struct {
+	AccountSID string
+	AuthToken  string
+	From       string // sending number, or a messaging service SID (MG...)
+	Client     *http.Client
+	Endpoint   string // overrides https://api.twilio.com
+}
+
+// Send implements Provider
+func (t *Twilio) Send(ctx context.Context, message *Message) error {
+	form := url.Values{"To": {message.To}, "Body": {message.Body}}
+	if strings.HasPrefix(t.From, "MG") {
+		form.Set("MessagingServiceSid", t.From)
+	} else {
+		form.Set("From", t.From)
+	}
+
+	endpoint := t.Endpoint
+	if endpoint == "" {
+		endpoint = "https://api.twilio.com"
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/2010-04-01/Accounts/"+url.PathEscape(t.AccountSID)+"/Messages.json", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.SetBasicAuth(t.AccountSID, t.AuthToken)
+
+	return sendRequest(t.Client, request, "Twilio")
+}
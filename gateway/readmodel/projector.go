@@ -0,0 +1,375 @@
+// Package readmodel maintains denormalized views of the eKYC ledger in Redis
+// so hot list queries (verifier review queues, the expiring-soon list and
+// status counts) are answered without a rich query against the peers.
+//
+// Consistency contract:
+//
+//   - Views hold committed ledger state only. A record is projected from its
+//     current ReadKYC result, never from an event payload, so projecting the
+//     same record twice, or out of order, converges on the latest state.
+//   - The projector handles chaincode events at least once, in commit order,
+//     resuming after a restart from the checkpoint it stores in Redis next to
+//     the views. Events that do not name a record are skipped.
+//   - Each record's snapshot, queue membership, expiry entry and contribution
+//     to the stats change together in one MULTI/EXEC, so a reader never sees a
+//     record counted under one status and queued under another. Views of
+//     different records are not a point-in-time snapshot of the ledger.
+//   - A view of a record lags the ledger by event delivery plus one evaluate,
+//     normally well under a second. Changes that emit no event are picked up
+//     by the next reconcile, so the reconcile interval bounds staleness; the
+//     views report the time they were last known to be complete as AsOf.
+//   - One projector runs per Redis database. It serializes projections, so a
+//     slow reconcile cannot overwrite a newer event-driven update.
+//
+// Views carry no personal data. Anything that drives a decision or a
+// disclosure must read the ledger rather than these views.
+package readmodel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ekyc-gateway/fabric"
+	"ekyc-gateway/redis"
+)
+
+const (
+	keyPrefix     = "ekyc:rm:"
+	recordPrefix  = keyPrefix + "record:"
+	queuePrefix   = keyPrefix + "pending:"
+	queuesKey     = keyPrefix + "queues"
+	expiringKey   = keyPrefix + "expiring"
+	statsKey      = keyPrefix + "stats"
+	checkpointKey = keyPrefix + "checkpoint"
+	asOfKey       = keyPrefix + "asof"
+
+	// unassignedQueue holds pending records no verifier has picked up
+	unassignedQueue = "_unassigned"
+
+	reconcilePageSize = 200
+)
+
+// purposeReadModel is the purpose code the projector reads records for;
+// reads of other organisations' records need an access grant for it
+const purposeReadModel = "READ_MODEL"
+
+// statuses are the record statuses the chaincode assigns
+var statuses = []string{"PENDING", "VERIFIED", "REJECTED", "EXPIRED", "BLOCKED"}
+
+// Ledger submits the chaincode's record reads, which the chaincode logs
+type Ledger interface {
+	Submit(ctx context.Context, function string, args ...string) (string, []byte, error)
+}
+
+// Entry is the projection of one KYC record
+type Entry struct {
+	ID                string `json:"id"`
+	EntityType        string `json:"entityType"`
+	Status            string `json:"status"`
+	VerificationLevel string `json:"verificationLevel"`
+	RiskTier          string `json:"riskTier,omitempty"`
+	AssignedTo        string `json:"assignedTo,omitempty"`
+	SLADueAt          string `json:"slaDueAt,omitempty"`
+	ExpiresAt         string `json:"expiresAt,omitempty"`
+	UpdatedAt         string `json:"updatedAt"`
+}
+
+// queueKey returns the key of the pending queue an entry belongs in
+func (e *Entry) queueKey() string {
+	if e.AssignedTo == "" {
+		return queuePrefix + unassignedQueue
+	}
+	return queuePrefix + e.AssignedTo
+}
+
+// Projector keeps the views in step with the ledger
+type Projector struct {
+	ledger Ledger
+	redis  *redis.Client
+	mu     sync.Mutex
+}
+
+// NewProjector returns a projector reading records from ledger into Redis
+func NewProjector(ledger Ledger, client *redis.Client) *Projector {
+	return &Projector{ledger: ledger, redis: client}
+}
+
+// Checkpoint returns the point to resume the event stream from, or nil when
+// no event has been handled yet
+func (p *Projector) Checkpoint(ctx context.Context) (*fabric.Checkpoint, error) {
+	reply, err := p.redis.Do(ctx, "GET", checkpointKey)
+	if err != nil || reply == nil {
+		return nil, err
+	}
+
+	var checkpoint fabric.Checkpoint
+	err = json.Unmarshal(bulk(reply), &checkpoint)
+	if err != nil {
+		return nil, fmt.Errorf("malformed read model checkpoint: %v", err)
+	}
+	return &checkpoint, nil
+}
+
+// recordEvent is the part of a chaincode event payload naming the records it
+// changed: one, or several for a batch such as KYCBatchChanged
+type recordEvent struct {
+	KYCID  string   `json:"kycId"`
+	KYCIDs []string `json:"kycIds"`
+}
+
+// Handle projects the records a chaincode event names, then advances the
+// stored checkpoint past the event
+func (p *Projector) Handle(ctx context.Context, event *fabric.Event) error {
+	var record recordEvent
+	if json.Unmarshal(event.Payload, &record) == nil {
+		if record.KYCID != "" {
+			record.KYCIDs = append(record.KYCIDs, record.KYCID)
+		}
+		for _, kycID := range record.KYCIDs {
+			err := p.Project(ctx, kycID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	checkpointJSON, err := json.Marshal(fabric.Checkpoint{BlockNumber: event.BlockNumber, TxID: event.TxID})
+	if err != nil {
+		return err
+	}
+	_, err = p.redis.Do(ctx, "SET", checkpointKey, string(checkpointJSON))
+	return err
+}
+
+// Project re-reads a record from the ledger and updates its views, removing
+// them if the record no longer exists
+func (p *Projector) Project(ctx context.Context, kycID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, recordJSON, err := p.ledger.Submit(ctx, "ReadKYC", kycID, purposeReadModel)
+	if err != nil && !strings.Contains(err.Error(), "does not exist") {
+		return fmt.Errorf("failed to read KYC record %s: %v", kycID, err)
+	}
+	var current *Entry
+	if err == nil {
+		current = new(Entry)
+		err = json.Unmarshal(recordJSON, current)
+		if err != nil {
+			return fmt.Errorf("malformed KYC record %s: %v", kycID, err)
+		}
+	}
+	return p.apply(ctx, kycID, current)
+}
+
+// apply moves a record's views from its stored snapshot to current, which is
+// nil when the record has been deleted
+func (p *Projector) apply(ctx context.Context, kycID string, current *Entry) error {
+	previous, err := p.snapshot(ctx, kycID)
+	if err != nil {
+		return err
+	}
+	if previous == nil && current == nil {
+		return nil
+	}
+	if previous != nil && current != nil && *previous == *current {
+		return nil
+	}
+
+	commands := [][]string{}
+	if previous != nil {
+		commands = append(commands,
+			[]string{"HINCRBY", statsKey, previous.Status, "-1"},
+			[]string{"HINCRBY", statsKey, "total", "-1"},
+			[]string{"ZREM", previous.queueKey(), kycID},
+			[]string{"ZREM", expiringKey, kycID},
+		)
+	}
+	if current == nil {
+		commands = append(commands, []string{"DEL", recordPrefix + kycID})
+	} else {
+		snapshotJSON, err := json.Marshal(current)
+		if err != nil {
+			return err
+		}
+		commands = append(commands,
+			[]string{"SET", recordPrefix + kycID, string(snapshotJSON)},
+			[]string{"HINCRBY", statsKey, current.Status, "1"},
+			[]string{"HINCRBY", statsKey, "total", "1"},
+		)
+		switch current.Status {
+		case "PENDING":
+			commands = append(commands,
+				[]string{"ZADD", current.queueKey(), score(current.SLADueAt), kycID},
+				[]string{"SADD", queuesKey, current.queueKey()},
+			)
+		case "VERIFIED":
+			if current.ExpiresAt != "" {
+				commands = append(commands, []string{"ZADD", expiringKey, score(current.ExpiresAt), kycID})
+			}
+		}
+	}
+
+	_, err = p.redis.Tx(ctx, commands...)
+	return err
+}
+
+// snapshot returns the stored projection of a record, or nil if there is none
+func (p *Projector) snapshot(ctx context.Context, kycID string) (*Entry, error) {
+	reply, err := p.redis.Do(ctx, "GET", recordPrefix+kycID)
+	if err != nil || reply == nil {
+		return nil, err
+	}
+
+	var entry Entry
+	err = json.Unmarshal(bulk(reply), &entry)
+	if err != nil {
+		return nil, fmt.Errorf("malformed read model snapshot of %s: %v", kycID, err)
+	}
+	return &entry, nil
+}
+
+// Reconcile brings every view in line with the ledger, paging through the
+// records of each status. Records whose page differs from their snapshot are
+// re-read before being applied, as are snapshots of records no page
+// returned, so changes made while the pass runs are never rolled back. On
+// success the views are marked complete as of the start of the pass.
+func (p *Projector) Reconcile(ctx context.Context) error {
+	started := time.Now().UTC()
+	seen := map[string]bool{}
+
+	for _, status := range statuses {
+		bookmark := ""
+		for {
+			_, pageJSON, err := p.ledger.Submit(ctx, "GetKYCByStatusWithPagination", status, strconv.Itoa(reconcilePageSize), bookmark, purposeReadModel)
+			if err != nil {
+				return fmt.Errorf("failed to list %s records: %v", status, err)
+			}
+			var page struct {
+				Records  []*Entry `json:"records"`
+				Bookmark string   `json:"bookmark"`
+			}
+			err = json.Unmarshal(pageJSON, &page)
+			if err != nil {
+				return fmt.Errorf("malformed %s page: %v", status, err)
+			}
+
+			for _, record := range page.Records {
+				seen[record.ID] = true
+				err = p.reconcileRecord(ctx, record)
+				if err != nil {
+					return err
+				}
+			}
+			if len(page.Records) == 0 || page.Bookmark == "" || page.Bookmark == bookmark {
+				break
+			}
+			bookmark = page.Bookmark
+		}
+	}
+
+	err := p.scanSnapshots(ctx, func(kycID string) error {
+		if seen[kycID] {
+			return nil
+		}
+		return p.Project(ctx, kycID)
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = p.redis.Do(ctx, "SET", asOfKey, started.Format(time.RFC3339))
+	return err
+}
+
+// reconcileRecord applies a record listed by a reconcile page
+func (p *Projector) reconcileRecord(ctx context.Context, record *Entry) error {
+	previous, err := p.snapshot(ctx, record.ID)
+	if err != nil {
+		return err
+	}
+	if previous != nil && *previous == *record {
+		return nil
+	}
+	// the page may predate an event already projected, so read the record afresh
+	return p.Project(ctx, record.ID)
+}
+
+// scanSnapshots calls fn with the ID of every stored record snapshot
+func (p *Projector) scanSnapshots(ctx context.Context, fn func(kycID string) error) error {
+	cursor := "0"
+	for {
+		reply, err := p.redis.Do(ctx, "SCAN", cursor, "MATCH", recordPrefix+"*", "COUNT", "500")
+		if err != nil {
+			return err
+		}
+		parts, ok := reply.([]interface{})
+		if !ok || len(parts) != 2 {
+			return fmt.Errorf("redis: unexpected SCAN reply %v", reply)
+		}
+
+		for _, key := range bulkStrings(parts[1]) {
+			err = fn(strings.TrimPrefix(key, recordPrefix))
+			if err != nil {
+				return err
+			}
+		}
+		cursor = string(bulk(parts[0]))
+		if cursor == "0" {
+			return nil
+		}
+	}
+}
+
+// Run reconciles the views every interval until ctx is done, logging failures
+func (p *Projector) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := p.Reconcile(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("read model reconcile failed: %v", err)
+			}
+		}
+	}
+}
+
+// score orders a view by an RFC3339 timestamp; records without one sort last
+func score(timestamp string) string {
+	at, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return "+inf"
+	}
+	return strconv.FormatInt(at.Unix(), 10)
+}
+
+// bulk returns the bytes of a bulk string reply
+func bulk(reply interface{}) []byte {
+	switch value := reply.(type) {
+	case []byte:
+		return value
+	case string:
+		return []byte(value)
+	}
+	return nil
+}
+
+// strings_ returns the elements of an array reply as strings
+func bulkStrings(reply interface{}) []string {
+	items, _ := reply.([]interface{})
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		values = append(values, string(bulk(item)))
+	}
+	return values
+}
@@ -0,0 +1,143 @@
+package readmodel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ekyc-gateway/redis"
+)
+
+// Views answers list queries from the projected read model
+type Views struct {
+	redis *redis.Client
+}
+
+// NewViews returns the read side of the views a Projector maintains
+func NewViews(client *redis.Client) *Views {
+	return &Views{redis: client}
+}
+
+// List is a page of projected records
+type List struct {
+	Entries []*Entry `json:"entries"`
+	Total   int64    `json:"total"`
+	AsOf    string   `json:"asOf,omitempty"` // views were complete as of this time; empty before the first reconcile
+}
+
+// QueueSize is the number of pending records in one verifier's queue
+type QueueSize struct {
+	Assignee string `json:"assignee"` // empty for records not yet assigned
+	Pending  int64  `json:"pending"`
+}
+
+// Stats counts projected records by status
+type Stats struct {
+	Total    int64            `json:"total"`
+	ByStatus map[string]int64 `json:"byStatus"`
+	Queues   []QueueSize      `json:"queues"`
+	AsOf     string           `json:"asOf,omitempty"`
+}
+
+// PendingQueue returns up to limit pending records assigned to assignee, due
+// soonest first. An empty assignee lists records nobody has picked up.
+func (v *Views) PendingQueue(ctx context.Context, assignee string, limit int) (*List, error) {
+	queue := &Entry{AssignedTo: assignee}
+	return v.list(ctx, queue.queueKey(), "-inf", "+inf", limit)
+}
+
+// ExpiringSoon returns up to limit verified records whose re-KYC falls due
+// within the given window, soonest first. Records already past due are included.
+func (v *Views) ExpiringSoon(ctx context.Context, within time.Duration, limit int) (*List, error) {
+	return v.list(ctx, expiringKey, "-inf", strconv.FormatInt(time.Now().Add(within).Unix(), 10), limit)
+}
+
+// list returns the entries of a view with scores in [min, max]
+func (v *Views) list(ctx context.Context, key string, min string, max string, limit int) (*List, error) {
+	replies, err := v.redis.Tx(ctx,
+		[]string{"ZRANGEBYSCORE", key, min, max, "LIMIT", "0", strconv.Itoa(limit)},
+		[]string{"ZCOUNT", key, min, max},
+		[]string{"GET", asOfKey},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &List{Entries: []*Entry{}, AsOf: string(bulk(replies[2]))}
+	list.Total, _ = replies[1].(int64)
+	ids := bulkStrings(replies[0])
+	if len(ids) == 0 {
+		return list, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = recordPrefix + id
+	}
+	reply, err := v.redis.Do(ctx, append([]string{"MGET"}, keys...)...)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, _ := reply.([]interface{})
+	for i, snapshot := range snapshots {
+		if snapshot == nil {
+			continue // projected away since the range was read
+		}
+		var entry Entry
+		err = json.Unmarshal(bulk(snapshot), &entry)
+		if err != nil {
+			return nil, fmt.Errorf("malformed read model snapshot of %s: %v", ids[i], err)
+		}
+		list.Entries = append(list.Entries, &entry)
+	}
+	return list, nil
+}
+
+// Stats returns record counts by status and the size of every pending queue
+func (v *Views) Stats(ctx context.Context) (*Stats, error) {
+	replies, err := v.redis.Tx(ctx,
+		[]string{"HGETALL", statsKey},
+		[]string{"SMEMBERS", queuesKey},
+		[]string{"GET", asOfKey},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &Stats{ByStatus: map[string]int64{}, Queues: []QueueSize{}, AsOf: string(bulk(replies[2]))}
+	fields := bulkStrings(replies[0])
+	for i := 0; i+1 < len(fields); i += 2 {
+		count, err := strconv.ParseInt(fields[i+1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed read model count %s: %v", fields[i], err)
+		}
+		if fields[i] == "total" {
+			stats.Total = count
+		} else if count != 0 {
+			stats.ByStatus[fields[i]] = count
+		}
+	}
+
+	queues := bulkStrings(replies[1])
+	sort.Strings(queues)
+	for _, queue := range queues {
+		reply, err := v.redis.Do(ctx, "ZCARD", queue)
+		if err != nil {
+			return nil, err
+		}
+		pending, _ := reply.(int64)
+		if pending == 0 {
+			continue
+		}
+		assignee := strings.TrimPrefix(queue, queuePrefix)
+		if assignee == unassignedQueue {
+			assignee = ""
+		}
+		stats.Queues = append(stats.Queues, QueueSize{Assignee: assignee, Pending: pending})
+	}
+	return stats, nil
+}
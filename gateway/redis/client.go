@@ -0,0 +1,216 @@
+// Package redis is a minimal Redis client speaking the subset of RESP the
+// gateway services need over a small pool of connections.
+package redis
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Client runs commands against one Redis server
+type Client struct {
+	addr     string
+	password string
+	db       int
+	timeout  time.Duration
+	pool     chan *redisConn
+}
+
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// Error is an error reply from the server
+type Error string
+
+func (e Error) Error() string { return "redis: " + string(e) }
+
+// NewClient returns a client for the Redis server at addr. Connections are
+// opened on demand and at most poolSize idle ones are kept.
+func NewClient(addr string, password string, db int, poolSize int) *Client {
+	return &Client{addr: addr, password: password, db: db, timeout: 2 * time.Second, pool: make(chan *redisConn, poolSize)}
+}
+
+// Do runs a command and returns its reply: nil, a string for status replies,
+// an int64, a []byte for bulk strings or an []interface{} for arrays
+func (r *Client) Do(ctx context.Context, args ...string) (interface{}, error) {
+	conn, err := r.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.setDeadline(ctx, conn)
+
+	reply, err := conn.command(args...)
+	return reply, r.release(conn, err)
+}
+
+// Tx runs commands atomically in a MULTI/EXEC block and returns their replies
+func (r *Client) Tx(ctx context.Context, commands ...[]string) ([]interface{}, error) {
+	conn, err := r.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.setDeadline(ctx, conn)
+
+	request := encodeCommand(nil, "MULTI")
+	for _, command := range commands {
+		request = encodeCommand(request, command...)
+	}
+	request = encodeCommand(request, "EXEC")
+	_, err = conn.conn.Write(request)
+	if err != nil {
+		return nil, r.release(conn, err)
+	}
+
+	// MULTI and each queued command reply +OK / +QUEUED before EXEC's array
+	var queueErr error
+	for i := 0; i <= len(commands); i++ {
+		_, err = conn.readReply()
+		if _, isReply := err.(Error); isReply && queueErr == nil {
+			queueErr = err
+		} else if err != nil {
+			return nil, r.release(conn, err)
+		}
+	}
+	reply, err := conn.readReply()
+	if err != nil {
+		return nil, r.release(conn, err)
+	}
+	r.put(conn)
+	if queueErr != nil {
+		return nil, queueErr
+	}
+	replies, ok := reply.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("redis: transaction aborted")
+	}
+	return replies, nil
+}
+
+func (r *Client) setDeadline(ctx context.Context, conn *redisConn) {
+	deadline := time.Now().Add(r.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.conn.SetDeadline(deadline)
+}
+
+// release returns a connection to the pool unless err left it unusable
+func (r *Client) release(conn *redisConn, err error) error {
+	if _, isReply := err.(Error); err != nil && !isReply {
+		conn.conn.Close()
+		return err
+	}
+	r.put(conn)
+	return err
+}
+
+// get returns an idle pooled connection or dials a new one
+func (r *Client) get(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn := <-r.pool:
+		return conn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: r.timeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", r.addr)
+	if err != nil {
+		return nil, fmt.Errorf("redis: failed to connect: %v", err)
+	}
+	conn := &redisConn{conn: netConn, reader: bufio.NewReader(netConn)}
+	netConn.SetDeadline(time.Now().Add(r.timeout))
+
+	if r.password != "" {
+		_, err = conn.command("AUTH", r.password)
+		if err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+	if r.db != 0 {
+		_, err = conn.command("SELECT", strconv.Itoa(r.db))
+		if err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// put returns a connection to the pool, closing it if the pool is full
+func (r *Client) put(conn *redisConn) {
+	select {
+	case r.pool <- conn:
+	default:
+		conn.conn.Close()
+	}
+}
+
+// encodeCommand appends a command to buf in RESP form
+func encodeCommand(buf []byte, args ...string) []byte {
+	buf = fmt.Appendf(buf, "*%d\r\n", len(args))
+	for _, arg := range args {
+		buf = fmt.Appendf(buf, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return buf
+}
+
+func (c *redisConn) command(args ...string) (interface{}, error) {
+	_, err := c.conn.Write(encodeCommand(nil, args...))
+	if err != nil {
+		return nil, err
+	}
+	return c.readReply()
+}
+
+func (c *redisConn) readReply() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	body := line[1 : len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, Error(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(body)
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		_, err = io.ReadFull(c.reader, buf)
+		if err != nil {
+			return nil, err
+		}
+		return buf[:size], nil
+	case '*':
+		count, err := strconv.Atoi(body)
+		if err != nil || count < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			items[i], err = c.readReply()
+			if _, isReply := err.(Error); err != nil && !isReply {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unknown reply type %q", line[0])
+}
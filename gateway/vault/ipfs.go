@@ -0,0 +1,146 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// IPFS stores documents on an IPFS node through its Kubo RPC API, as files in
+// the node's mutable file system named by their hash. Files there are
+// pinned against garbage collection, and Put returns the document's CID,
+// which AddDocumentHash records as its ipfsHash. Run the node on a private
+// network, with a swarm key, unless documents are encrypted before upload:
+// on the public network anyone with the CID can fetch them.
+type IPFS struct {
+	API    string // RPC API address, such as http://127.0.0.1:5001
+	Dir    string // directory in the node's file system; /ekyc-documents when empty
+	Client *http.Client
+}
+
+// Name implements DocumentVault
+func (i *IPFS) Name() string {
+	return "ipfs"
+}
+
+func (i *IPFS) path(hash string) string {
+	dir := i.Dir
+	if dir == "" {
+		dir = "/ekyc-documents"
+	}
+	return strings.TrimSuffix(dir, "/") + "/" + hash
+}
+
+// Put implements DocumentVault
+func (i *IPFS) Put(ctx context.Context, hash string, content []byte) (string, error) {
+	err := checkHash(hash)
+	if err != nil {
+		return "", err
+	}
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", hash)
+	if err != nil {
+		return "", err
+	}
+	part.Write(content)
+	form.Close()
+
+	_, err = i.call(ctx, "files/write", url.Values{
+		"arg":         {i.path(hash)},
+		"create":      {"true"},
+		"parents":     {"true"},
+		"truncate":    {"true"},
+		"cid-version": {"1"},
+		"raw-leaves":  {"true"},
+	}, form.FormDataContentType(), &body)
+	if err != nil {
+		return "", err
+	}
+
+	statJSON, err := i.call(ctx, "files/stat", url.Values{"arg": {i.path(hash)}}, "", nil)
+	if err != nil {
+		return "", err
+	}
+	var stat struct {
+		Hash string `json:"Hash"`
+	}
+	err = json.Unmarshal(statJSON, &stat)
+	if err != nil || stat.Hash == "" {
+		return "", fmt.Errorf("malformed IPFS files/stat response")
+	}
+	return stat.Hash, nil
+}
+
+// Get implements DocumentVault
+func (i *IPFS) Get(ctx context.Context, hash string) ([]byte, error) {
+	err := checkHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	return i.call(ctx, "files/read", url.Values{"arg": {i.path(hash)}}, "", nil)
+}
+
+// Delete implements DocumentVault. The node frees the document's blocks at
+// its next garbage collection.
+func (i *IPFS) Delete(ctx context.Context, hash string) error {
+	err := checkHash(hash)
+	if err != nil {
+		return err
+	}
+	_, err = i.call(ctx, "files/rm", url.Values{"arg": {i.path(hash)}}, "", nil)
+	if err == ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// call posts to an RPC endpoint and returns the response body of a success.
+// A missing file is reported as ErrNotFound.
+func (i *IPFS) call(ctx context.Context, endpoint string, query url.Values, contentType string, body io.Reader) ([]byte, error) {
+	api := i.API
+	if api == "" {
+		api = "http://127.0.0.1:5001"
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(api, "/")+"/api/v0/"+endpoint+"?"+query.Encode(), body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	client := i.Client
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	reply, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		var failure struct {
+			Message string `json:"Message"`
+		}
+		if json.Unmarshal(reply, &failure) != nil || failure.Message == "" {
+			failure.Message = strings.TrimSpace(string(reply))
+		}
+		if strings.Contains(failure.Message, "does not exist") {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("IPFS %s responded %s: %s", endpoint, response.Status, failure.Message)
+	}
+	return reply, nil
+}
@@ -0,0 +1,84 @@
+package vault
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// Local stores documents as files under a directory, each at
+// <Dir>/<first two hash characters>/<hash>, readable only by the gateway
+type Local struct {
+	Dir string
+}
+
+// Name implements DocumentVault
+func (l *Local) Name() string {
+	return "local"
+}
+
+func (l *Local) path(hash string) string {
+	return filepath.Join(l.Dir, hash[:2], hash)
+}
+
+// Put implements DocumentVault. The file is written under a temporary name
+// and renamed into place, so a reader never sees part of a document.
+func (l *Local) Put(ctx context.Context, hash string, content []byte) (string, error) {
+	err := checkHash(hash)
+	if err != nil {
+		return "", err
+	}
+	path := l.path(hash)
+	if _, err := os.Stat(path); err == nil {
+		return "", nil
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return "", err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), hash+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	_, err = file.Write(content)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return "", nil
+}
+
+// Get implements DocumentVault
+func (l *Local) Get(ctx context.Context, hash string) ([]byte, error) {
+	err := checkHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(l.path(hash))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return content, err
+}
+
+// Delete implements DocumentVault
+func (l *Local) Delete(ctx context.Context, hash string) error {
+	err := checkHash(hash)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(l.path(hash))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
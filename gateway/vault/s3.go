@@ -0,0 +1,183 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// s3EmptyHash is the hex SHA-256 of an empty payload, signed for GET and
+// DELETE requests
+const s3EmptyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3 stores documents as objects in an Amazon S3 bucket, or any service with
+// its API, encrypted at rest with S3-managed keys. Each object's key is
+// Prefix followed by the document's hash.
+type S3 struct {
+	Bucket          string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // for temporary credentials
+	Prefix          string // such as documents/
+	Endpoint        string // overrides https://<bucket>.s3.<region>.amazonaws.com, addressing the bucket by path
+	Client          *http.Client
+}
+
+// Name implements DocumentVault
+func (s *S3) Name() string {
+	return "s3"
+}
+
+// Put implements DocumentVault
+func (s *S3) Put(ctx context.Context, hash string, content []byte) (string, error) {
+	err := checkHash(hash)
+	if err != nil {
+		return "", err
+	}
+	request, err := s.newRequest(ctx, http.MethodPut, hash, content)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/octet-stream")
+	request.Header.Set("X-Amz-Server-Side-Encryption", "AES256")
+	_, err = s.do(request, content)
+	return "", err
+}
+
+// Get implements DocumentVault
+func (s *S3) Get(ctx context.Context, hash string) ([]byte, error) {
+	err := checkHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	request, err := s.newRequest(ctx, http.MethodGet, hash, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.do(request, nil)
+}
+
+// Delete implements DocumentVault. S3 answers a delete of a missing object
+// as a success.
+func (s *S3) Delete(ctx context.Context, hash string) error {
+	err := checkHash(hash)
+	if err != nil {
+		return err
+	}
+	request, err := s.newRequest(ctx, http.MethodDelete, hash, nil)
+	if err != nil {
+		return err
+	}
+	_, err = s.do(request, nil)
+	return err
+}
+
+func (s *S3) newRequest(ctx context.Context, method string, hash string, content []byte) (*http.Request, error) {
+	url := "https://" + s.Bucket + ".s3." + s.Region + ".amazonaws.com/" + s.Prefix + hash
+	if s.Endpoint != "" {
+		url = strings.TrimSuffix(s.Endpoint, "/") + "/" + s.Bucket + "/" + s.Prefix + hash
+	}
+	var body io.Reader
+	if content != nil {
+		body = bytes.NewReader(content)
+	}
+	return http.NewRequestWithContext(ctx, method, url, body)
+}
+
+// do signs and sends a request, returning the response body of a success
+func (s *S3) do(request *http.Request, content []byte) ([]byte, error) {
+	if s.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+	signS3(request, content, s.AccessKeyID, s.SecretAccessKey, s.Region, time.Now())
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case response.StatusCode == http.StatusNotFound && request.Method == http.MethodDelete:
+		return nil, nil
+	case response.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case response.StatusCode/100 != 2:
+		return nil, fmt.Errorf("S3 responded %s: %s", response.Status, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// signS3 adds an AWS Signature Version 4 Authorization header to an S3
+// request, signing its host, date, payload hash and any content type,
+// encryption or security token headers
+func signS3(request *http.Request, content []byte, accessKeyID string, secretAccessKey string, region string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := s3EmptyHash
+	if content != nil {
+		payloadHash = Hash(content)
+	}
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": request.URL.Host, "x-amz-date": amzDate, "x-amz-content-sha256": payloadHash}
+	for _, name := range []string{"Content-Type", "X-Amz-Server-Side-Encryption", "X-Amz-Security-Token"} {
+		if value := request.Header.Get(name); value != "" {
+			headers[strings.ToLower(name)] = value
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		request.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,124 @@
+// Package vault stores the documents whose hashes KYC records hold. The
+// ledger keeps only a document's SHA-256; the file itself goes to a
+// DocumentVault, keyed by that hash, so anyone holding the record can fetch
+// the document and check it against the ledger. Upload stores a document
+// before recording its hash with AddDocumentHash, so the ledger never names
+// a document the vault does not hold.
+//
+// Documents are content-addressed: storing the same file twice stores it
+// once, and deleting it removes it for every record naming the hash. The
+// gateway does not delete documents a record still names.
+package vault
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"ekyc-gateway/contract"
+)
+
+// ErrNotFound is returned for a document the vault does not hold
+var ErrNotFound = errors.New("document not found in vault")
+
+// StorageError is returned by Upload when the vault fails to store a
+// document, as distinct from the ledger failing to record it
+type StorageError struct {
+	Vault string
+	Err   error
+}
+
+func (e *StorageError) Error() string {
+	return fmt.Sprintf("failed to store document in %s vault: %v", e.Vault, e.Err)
+}
+
+func (e *StorageError) Unwrap() error {
+	return e.Err
+}
+
+// DocumentVault stores documents by the hex SHA-256 of their content
+type DocumentVault interface {
+	Name() string // such as local, s3 or ipfs
+	// Put stores content under its hash and returns where else it can be
+	// found, the IPFS CID for IPFS and empty otherwise
+	Put(ctx context.Context, hash string, content []byte) (string, error)
+	// Get returns the content stored under hash, or ErrNotFound
+	Get(ctx context.Context, hash string) ([]byte, error)
+	// Delete removes the content stored under hash; deleting a document the
+	// vault does not hold succeeds
+	Delete(ctx context.Context, hash string) error
+}
+
+// Submitter submits chaincode transactions
+type Submitter interface {
+	Submit(ctx context.Context, function string, args ...string) (string, []byte, error)
+}
+
+// Hash returns the hex SHA-256 of content, the key it is stored under
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// checkHash rejects anything but a lower-case hex SHA-256, so a hash is
+// always safe to use as a file name or object key
+func checkHash(hash string) error {
+	if len(hash) != sha256.Size*2 {
+		return fmt.Errorf("invalid document hash %q", hash)
+	}
+	for _, c := range hash {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return fmt.Errorf("invalid document hash %q", hash)
+		}
+	}
+	return nil
+}
+
+// Get fetches a document from v and checks it still has hash, so a document
+// altered in storage is never served as the one the ledger names
+func Get(ctx context.Context, v DocumentVault, hash string) ([]byte, error) {
+	err := checkHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	content, err := v.Get(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if Hash(content) != hash {
+		return nil, fmt.Errorf("document %s in %s vault does not match its hash", hash, v.Name())
+	}
+	return content, nil
+}
+
+// Upload stores a document in v and adds it to a record, returning the
+// document as recorded and the transaction that recorded it. A document
+// stored but not recorded, because the transaction failed, stays in the
+// vault; uploading it again records it without storing it twice.
+func Upload(ctx context.Context, v DocumentVault, submitter Submitter, kycID string, documentID string, documentType string, content []byte) (*contract.DocumentHash, string, error) {
+	document := &contract.DocumentHash{
+		ID:         documentID,
+		Type:       documentType,
+		Hash:       Hash(content),
+		UploadedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	location, err := v.Put(ctx, document.Hash, content)
+	if err != nil {
+		return nil, "", &StorageError{Vault: v.Name(), Err: err}
+	}
+	document.IPFSHash = location
+
+	documentJSON, err := json.Marshal(document)
+	if err != nil {
+		return nil, "", err
+	}
+	txID, _, err := submitter.Submit(ctx, "AddDocumentHash", kycID, string(documentJSON))
+	if err != nil {
+		return nil, "", err
+	}
+	return document, txID, nil
+}
@@ -0,0 +1,296 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"ekyc-gateway/fabric"
+)
+
+const (
+	// MaxAttempts is how many times a delivery is tried before it fails
+	MaxAttempts = 10
+
+	firstRetry   = 30 * time.Second
+	maxRetry     = 6 * time.Hour
+	pollInterval = time.Second
+	batchSize    = 32
+	workers      = 8
+
+	attemptTimeout = 10 * time.Second
+	// leaseDuration must outlast an attempt, so a live dispatcher never
+	// retries a delivery it is still sending
+	leaseDuration = 2 * attemptTimeout
+)
+
+// Body is what a subscription's endpoint receives for an event
+type Body struct {
+	ID          string   `json:"id"` // delivery ID, the same on every attempt
+	Event       string   `json:"event"`
+	KYCID       string   `json:"kycId,omitempty"`
+	KYCIDs      []string `json:"kycIds,omitempty"` // records a delegate's AccessRevoked event names
+	Org         string   `json:"org,omitempty"`
+	Status      string   `json:"status,omitempty"`
+	Grantees    []string `json:"grantees,omitempty"` // organisations whose access an AccessRevoked event withdrew
+	BlockNumber uint64   `json:"blockNumber"`
+	TxID        string   `json:"txId"`
+	CreatedAt   string   `json:"createdAt"`
+}
+
+// Dispatcher turns chaincode events into deliveries and attempts them. One
+// dispatcher runs per Redis database.
+type Dispatcher struct {
+	store  *Store
+	client *http.Client
+}
+
+// NewDispatcher returns a dispatcher sending with client. A nil client uses
+// one that does not follow redirects, so an endpoint cannot bounce deliveries
+// to another host.
+func NewDispatcher(store *Store, client *http.Client) *Dispatcher {
+	if client == nil {
+		client = &http.Client{
+			Timeout: attemptTimeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+	return &Dispatcher{store: store, client: client}
+}
+
+// Checkpoint returns the position after the last event handled, or nil
+// before the first
+func (d *Dispatcher) Checkpoint(ctx context.Context) (*fabric.Checkpoint, error) {
+	reply, err := d.store.redis.Do(ctx, "GET", checkpointKey)
+	if err != nil || reply == nil {
+		return nil, err
+	}
+	var checkpoint fabric.Checkpoint
+	err = json.Unmarshal(bulk(reply), &checkpoint)
+	if err != nil {
+		return nil, fmt.Errorf("malformed webhook checkpoint: %v", err)
+	}
+	return &checkpoint, nil
+}
+
+// Handle queues a delivery of the event for every matching subscription and
+// records the event as handled. Use it as the fabric.Listen handler.
+func (d *Dispatcher) Handle(ctx context.Context, event *fabric.Event) error {
+	var payload struct {
+		KYCID    string   `json:"kycId"`
+		KYCIDs   []string `json:"kycIds"`
+		OwnerMSP string   `json:"ownerMsp"`
+		Status   string   `json:"status"`
+		Grantees []string `json:"grantees"`
+	}
+	json.Unmarshal(event.Payload, &payload)
+
+	subs, err := d.store.List(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, sub := range subs {
+		if !sub.matches(event.Name, payload.OwnerMSP, payload.Grantees) {
+			continue
+		}
+		// derived from the subscription and transaction, so a replayed
+		// event maps onto the delivery it already produced
+		hash := sha256.Sum256([]byte(sub.ID + "/" + event.TxID + "/" + event.Name))
+		id := hex.EncodeToString(hash[:16])
+		body, err := json.Marshal(&Body{
+			ID:          id,
+			Event:       event.Name,
+			KYCID:       payload.KYCID,
+			KYCIDs:      payload.KYCIDs,
+			Org:         payload.OwnerMSP,
+			Status:      payload.Status,
+			Grantees:    payload.Grantees,
+			BlockNumber: event.BlockNumber,
+			TxID:        event.TxID,
+			CreatedAt:   now.Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+		err = d.store.enqueue(ctx, &Delivery{
+			ID:             id,
+			SubscriptionID: sub.ID,
+			Event:          event.Name,
+			Body:           body,
+			Status:         Pending,
+			CreatedAt:      now.Format(time.RFC3339),
+			NextAttemptAt:  now.Format(time.RFC3339),
+		}, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	checkpointJSON, err := json.Marshal(fabric.Checkpoint{BlockNumber: event.BlockNumber, TxID: event.TxID})
+	if err != nil {
+		return err
+	}
+	_, err = d.store.redis.Do(ctx, "SET", checkpointKey, string(checkpointJSON))
+	return err
+}
+
+// Run attempts deliveries as they fall due until ctx is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		for ctx.Err() == nil && d.dispatchDue(ctx) == batchSize {
+			// a full batch may mean a backlog; keep going without waiting
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchDue attempts one batch of due deliveries and returns its size
+func (d *Dispatcher) dispatchDue(ctx context.Context) int {
+	now := time.Now()
+	ids, err := d.store.due(ctx, now, batchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("failed to read due webhook deliveries: %v", err)
+		}
+		return 0
+	}
+
+	queue := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range queue {
+				err := d.attempt(ctx, id, now)
+				if err != nil && ctx.Err() == nil {
+					log.Printf("webhook delivery %s: %v", id, err)
+				}
+			}
+		}()
+	}
+	for _, id := range ids {
+		queue <- id
+	}
+	close(queue)
+	wg.Wait()
+	return len(ids)
+}
+
+// attempt sends a delivery once and records the outcome
+func (d *Dispatcher) attempt(ctx context.Context, id string, now time.Time) error {
+	err := d.store.lease(ctx, id, now.Add(leaseDuration))
+	if err != nil {
+		return err
+	}
+	delivery, err := d.store.delivery(ctx, id)
+	if err != nil {
+		return err
+	}
+	if delivery == nil || delivery.Status != Pending {
+		return d.store.drop(ctx, id)
+	}
+	sub, err := d.store.Get(ctx, delivery.SubscriptionID)
+	if err == ErrNotFound {
+		delivery.Status = Failed
+		delivery.LastError = "subscription deleted"
+		delivery.NextAttemptAt = ""
+		return d.store.save(ctx, delivery, time.Time{})
+	}
+	if err != nil {
+		return err
+	}
+
+	delivery.Attempts++
+	delivery.ResponseCode, err = d.send(ctx, sub, delivery)
+	finished := time.Now().UTC()
+	var next time.Time
+	switch {
+	case err == nil:
+		delivery.Status = Delivered
+		delivery.LastError = ""
+		delivery.NextAttemptAt = ""
+		delivery.DeliveredAt = finished.Format(time.RFC3339)
+	case delivery.Attempts >= MaxAttempts:
+		delivery.Status = Failed
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = ""
+	default:
+		delivery.LastError = err.Error()
+		next = finished.Add(backoff(delivery.Attempts))
+		delivery.NextAttemptAt = next.Format(time.RFC3339)
+	}
+	return d.store.save(ctx, delivery, next)
+}
+
+// send POSTs a delivery's body and returns the response status. Any 2xx
+// response accepts the delivery.
+func (d *Dispatcher) send(ctx context.Context, sub *Subscription, delivery *Delivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, attemptTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "ekyc-gateway-webhooks")
+	request.Header.Set("X-EKYC-Delivery", delivery.ID)
+	request.Header.Set("X-EKYC-Event", delivery.Event)
+	request.Header.Set("X-EKYC-Signature", Sign(sub.Secret, timestamp, delivery.Body))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("endpoint responded %s", response.Status)
+	}
+	return response.StatusCode, nil
+}
+
+// Sign returns the X-EKYC-Signature header for a body sent at timestamp (Unix
+// seconds): "t=<timestamp>,v1=<hex HMAC-SHA256 of timestamp.body>". Endpoints
+// recompute the HMAC with their secret, compare it in constant time and
+// reject old timestamps to stop replays.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff returns the wait after a delivery's nth failed attempt
+func backoff(attempts int) time.Duration {
+	wait := firstRetry
+	for i := 1; i < attempts && wait < maxRetry; i++ {
+		wait *= 2
+	}
+	if wait > maxRetry {
+		wait = maxRetry
+	}
+	return wait
+}
@@ -0,0 +1,38 @@
+package webhook
+
+import "testing"
+
+// TestSign checks signatures against HMAC-SHA256 values computed
+// independently of the gateway, over "<timestamp>.<body>"
+func TestSign(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      string
+		want      string
+	}{
+		{
+			name:      "delivery body",
+			secret:    "whsec_0123456789abcdef",
+			timestamp: "1767225600",
+			body:      `{"event":"KYCVerified","kycId":"KYC00000001"}`,
+			want:      "t=1767225600,v1=1b11166ad1ad3d7a4a4e343ad8489893ba8c192885b8c70530481c5099b08c61",
+		},
+		{
+			name:      "empty body",
+			secret:    "another-secret-value",
+			timestamp: "1767225601",
+			body:      "",
+			want:      "t=1767225601,v1=e3933a705beae2fa594a45698390465246fce91c20443615ad59e378542e8031",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Sign(test.secret, test.timestamp, []byte(test.body))
+			if got != test.want {
+				t.Errorf("Sign(%q, %q, %q) = %s, want %s", test.secret, test.timestamp, test.body, got, test.want)
+			}
+		})
+	}
+}
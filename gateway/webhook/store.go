@@ -0,0 +1,312 @@
+// Package webhook pushes KYC status changes to partner endpoints. Partners
+// register a URL, a secret and an event filter; each chaincode event that
+// matches becomes a delivery, POSTed with an HMAC signature and retried with
+// exponential backoff until the endpoint accepts it or the attempts run out.
+// Subscriptions, deliveries and the dispatcher's event checkpoint are kept in
+// Redis.
+//
+// Deliveries are at least once: an endpoint may receive the same delivery
+// more than once and should deduplicate on its X-EKYC-Delivery ID. Deliveries
+// to one endpoint are not ordered. Bodies carry the event name, record ID,
+// org, status and ledger position but no other payload fields, so partners
+// read the record itself through the API they are authorized for.
+// AccessRevoked bodies also name the grantees whose access was withdrawn;
+// a grantee evicts the record and acknowledges with AcknowledgeRevocation.
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"ekyc-gateway/redis"
+)
+
+const (
+	keyPrefix          = "ekyc:wh:"
+	subscriptionPrefix = keyPrefix + "sub:"
+	subscriptionsKey   = keyPrefix + "subs"
+	deliveryPrefix     = keyPrefix + "delivery:"
+	logPrefix          = keyPrefix + "log:"
+	dueKey             = keyPrefix + "due"
+	checkpointKey      = keyPrefix + "checkpoint"
+
+	// logLength is how many recent deliveries each subscription's log keeps
+	logLength = 1000
+	// deliveryRetention is how long a finished delivery stays readable
+	deliveryRetention = 30 * 24 * time.Hour
+)
+
+// ErrNotFound is returned for a subscription that does not exist
+var ErrNotFound = errors.New("webhook subscription not found")
+
+// Subscription is a partner endpoint and the events it receives
+type Subscription struct {
+	ID        string   `json:"id"`
+	URL       string   `json:"url"`
+	Secret    string   `json:"secret,omitempty"`
+	Events    []string `json:"events"`        // event names to deliver; empty for all
+	Org       string   `json:"org,omitempty"` // only records owned by this MSP, and revocations of its access, when set
+	CreatedAt string   `json:"createdAt"`
+}
+
+// matches reports whether an event passes the subscription's filter. An
+// org-filtered subscription also receives the revocations of its
+// organisation's access to other organisations' records.
+func (s *Subscription) matches(name string, org string, grantees []string) bool {
+	if s.Org != "" && s.Org != org {
+		granted := false
+		for _, grantee := range grantees {
+			if grantee == s.Org {
+				granted = true
+			}
+		}
+		if !granted {
+			return false
+		}
+	}
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, event := range s.Events {
+		if event == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery states
+const (
+	Pending   = "PENDING"
+	Delivered = "DELIVERED"
+	Failed    = "FAILED"
+)
+
+// Delivery is one event bound for one subscription and the record of its attempts
+type Delivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscriptionId"`
+	Event          string          `json:"event"`
+	Body           json.RawMessage `json:"body"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseCode   int             `json:"responseCode,omitempty"` // of the last attempt
+	LastError      string          `json:"lastError,omitempty"`
+	CreatedAt      string          `json:"createdAt"`
+	NextAttemptAt  string          `json:"nextAttemptAt,omitempty"`
+	DeliveredAt    string          `json:"deliveredAt,omitempty"`
+}
+
+// Store persists subscriptions and deliveries in Redis
+type Store struct {
+	redis *redis.Client
+}
+
+// NewStore returns a store over client
+func NewStore(client *redis.Client) *Store {
+	return &Store{redis: client}
+}
+
+// Create registers a subscription, assigning its ID and creation time
+func (s *Store) Create(ctx context.Context, sub *Subscription) error {
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	if err != nil {
+		return err
+	}
+	sub.ID = hex.EncodeToString(id)
+	sub.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	if sub.Events == nil {
+		sub.Events = []string{}
+	}
+
+	subJSON, err := json.Marshal(sub)
+	if err != nil {
+		return err
+	}
+	_, err = s.redis.Tx(ctx,
+		[]string{"SET", subscriptionPrefix + sub.ID, string(subJSON)},
+		[]string{"SADD", subscriptionsKey, sub.ID},
+	)
+	return err
+}
+
+// Get returns a subscription, including its secret
+func (s *Store) Get(ctx context.Context, id string) (*Subscription, error) {
+	reply, err := s.redis.Do(ctx, "GET", subscriptionPrefix+id)
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, ErrNotFound
+	}
+	var sub Subscription
+	err = json.Unmarshal(bulk(reply), &sub)
+	if err != nil {
+		return nil, fmt.Errorf("malformed webhook subscription %s: %v", id, err)
+	}
+	return &sub, nil
+}
+
+// List returns every subscription, including their secrets
+func (s *Store) List(ctx context.Context) ([]*Subscription, error) {
+	reply, err := s.redis.Do(ctx, "SMEMBERS", subscriptionsKey)
+	if err != nil {
+		return nil, err
+	}
+	subs := []*Subscription{}
+	for _, id := range bulkStrings(reply) {
+		sub, err := s.Get(ctx, id)
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+// Delete removes a subscription and its delivery log. Deliveries already
+// queued for it are dropped when they fall due.
+func (s *Store) Delete(ctx context.Context, id string) error {
+	replies, err := s.redis.Tx(ctx,
+		[]string{"DEL", subscriptionPrefix + id},
+		[]string{"SREM", subscriptionsKey, id},
+		[]string{"DEL", logPrefix + id},
+	)
+	if err != nil {
+		return err
+	}
+	if removed, _ := replies[0].(int64); removed == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Deliveries returns a subscription's most recent deliveries, newest first
+func (s *Store) Deliveries(ctx context.Context, id string, limit int) ([]*Delivery, error) {
+	reply, err := s.redis.Do(ctx, "LRANGE", logPrefix+id, "0", strconv.Itoa(limit-1))
+	if err != nil {
+		return nil, err
+	}
+	deliveries := []*Delivery{}
+	for _, deliveryID := range bulkStrings(reply) {
+		delivery, err := s.delivery(ctx, deliveryID)
+		if err != nil {
+			return nil, err
+		}
+		if delivery != nil {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+// delivery returns a delivery, or nil once it has expired
+func (s *Store) delivery(ctx context.Context, id string) (*Delivery, error) {
+	reply, err := s.redis.Do(ctx, "GET", deliveryPrefix+id)
+	if err != nil || reply == nil {
+		return nil, err
+	}
+	var delivery Delivery
+	err = json.Unmarshal(bulk(reply), &delivery)
+	if err != nil {
+		return nil, fmt.Errorf("malformed webhook delivery %s: %v", id, err)
+	}
+	return &delivery, nil
+}
+
+// enqueue records a new delivery and schedules its first attempt. Enqueueing
+// the same delivery again, as a replayed event does, leaves the recorded one
+// alone; if it has already finished, the dispatcher drops it when it falls due.
+func (s *Store) enqueue(ctx context.Context, delivery *Delivery, due time.Time) error {
+	deliveryJSON, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+	log := logPrefix + delivery.SubscriptionID
+	_, err = s.redis.Tx(ctx,
+		[]string{"SET", deliveryPrefix + delivery.ID, string(deliveryJSON), "NX"},
+		[]string{"ZADD", dueKey, "NX", unixMillis(due), delivery.ID},
+		[]string{"LREM", log, "0", delivery.ID},
+		[]string{"LPUSH", log, delivery.ID},
+		[]string{"LTRIM", log, "0", strconv.Itoa(logLength - 1)},
+	)
+	return err
+}
+
+// save stores a delivery after an attempt, rescheduling it while it is
+// pending and letting it expire once it is finished
+func (s *Store) save(ctx context.Context, delivery *Delivery, next time.Time) error {
+	deliveryJSON, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+	if delivery.Status == Pending {
+		_, err = s.redis.Tx(ctx,
+			[]string{"SET", deliveryPrefix + delivery.ID, string(deliveryJSON)},
+			[]string{"ZADD", dueKey, unixMillis(next), delivery.ID},
+		)
+		return err
+	}
+	_, err = s.redis.Tx(ctx,
+		[]string{"SET", deliveryPrefix + delivery.ID, string(deliveryJSON), "PX", strconv.FormatInt(deliveryRetention.Milliseconds(), 10)},
+		[]string{"ZREM", dueKey, delivery.ID},
+	)
+	return err
+}
+
+// due returns up to limit deliveries whose next attempt is due
+func (s *Store) due(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	reply, err := s.redis.Do(ctx, "ZRANGEBYSCORE", dueKey, "-inf", unixMillis(now), "LIMIT", "0", strconv.Itoa(limit))
+	if err != nil {
+		return nil, err
+	}
+	return bulkStrings(reply), nil
+}
+
+// lease pushes a due delivery's next attempt back by d, so a dispatcher that
+// dies mid-attempt leaves it to be retried rather than lost
+func (s *Store) lease(ctx context.Context, id string, until time.Time) error {
+	_, err := s.redis.Do(ctx, "ZADD", dueKey, "XX", unixMillis(until), id)
+	return err
+}
+
+// drop removes a delivery that can no longer be attempted from the schedule
+func (s *Store) drop(ctx context.Context, id string) error {
+	_, err := s.redis.Do(ctx, "ZREM", dueKey, id)
+	return err
+}
+
+func unixMillis(t time.Time) string {
+	return strconv.FormatInt(t.UnixMilli(), 10)
+}
+
+// bulk returns the bytes of a bulk string reply
+func bulk(reply interface{}) []byte {
+	switch value := reply.(type) {
+	case []byte:
+		return value
+	case string:
+		return []byte(value)
+	}
+	return nil
+}
+
+// bulkStrings returns the elements of an array reply as strings
+func bulkStrings(reply interface{}) []string {
+	items, _ := reply.([]interface{})
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		values = append(values, string(bulk(item)))
+	}
+	return values
+}
@@ -0,0 +1,293 @@
+// Package websocket is the server side of the WebSocket protocol (RFC 6455),
+// enough to push text messages to browsers: the opening handshake, framing,
+// ping/pong and the closing handshake. Messages clients send are read and
+// discarded.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Close codes
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	ClosePolicyViolation = 1008
+	CloseMessageTooBig   = 1009
+)
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+
+	// maxMessageSize bounds what a client may send; the protocol here only
+	// pushes, so anything larger is misuse
+	maxMessageSize = 4096
+
+	writeTimeout = 10 * time.Second
+	acceptGUID   = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+)
+
+// ErrClosed is returned by writes after the connection has closed
+var ErrClosed = errors.New("websocket: connection closed")
+
+// Conn is an upgraded WebSocket connection. Writes may be called from any
+// goroutine; Read must be called from one.
+type Conn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+
+	mu     sync.Mutex // serializes frames and guards closed
+	closed bool
+}
+
+// Upgrade completes the opening handshake of a WebSocket request.
+// checkOrigin, when not nil, decides whether a browser's Origin may connect.
+// On failure Upgrade has already written an HTTP error response.
+func Upgrade(w http.ResponseWriter, r *http.Request, checkOrigin func(origin string) bool) (*Conn, error) {
+	switch {
+	case r.Method != http.MethodGet:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, fmt.Errorf("websocket: method %s", r.Method)
+	case !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket"):
+		http.Error(w, "expected a WebSocket upgrade", http.StatusBadRequest)
+		return nil, errors.New("websocket: not an upgrade request")
+	case r.Header.Get("Sec-WebSocket-Version") != "13":
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported WebSocket version", http.StatusUpgradeRequired)
+		return nil, errors.New("websocket: unsupported version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		http.Error(w, "invalid Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, errors.New("websocket: invalid key")
+	}
+	if origin := r.Header.Get("Origin"); origin != "" && checkOrigin != nil && !checkOrigin(origin) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return nil, fmt.Errorf("websocket: origin %s not allowed", origin)
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "connection cannot be upgraded", http.StatusInternalServerError)
+		return nil, errors.New("websocket: response does not support hijacking")
+	}
+	netConn, buffered, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	accept := sha1.Sum([]byte(key + acceptGUID))
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(accept[:]) + "\r\n\r\n"
+	netConn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_, err = netConn.Write([]byte(response))
+	if err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	netConn.SetDeadline(time.Time{})
+	return &Conn{conn: netConn, reader: buffered.Reader}, nil
+}
+
+// headerContains reports whether a comma-separated header lists token
+func headerContains(header http.Header, name string, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, item := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(item), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WriteText sends a text message
+func (c *Conn) WriteText(message []byte) error {
+	return c.writeFrame(opText, message)
+}
+
+// Ping sends a ping; the client's pong is consumed by Read
+func (c *Conn) Ping() error {
+	return c.writeFrame(opPing, nil)
+}
+
+// Close sends a close frame with code and reason and closes the connection
+// without waiting for the client's reply
+func (c *Conn) Close(code int, reason string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.writeFrameLocked(opClose, closePayload(code, reason))
+	c.closed = true
+	return c.conn.Close()
+}
+
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+	return c.writeFrameLocked(opcode, payload)
+}
+
+// writeFrameLocked writes one unfragmented, unmasked frame
+func (c *Conn) writeFrameLocked(opcode byte, payload []byte) error {
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | opcode
+	switch n := len(payload); {
+	case n < 126:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_, err := (&net.Buffers{header, payload}).WriteTo(c.conn)
+	return err
+}
+
+func closePayload(code int, reason string) []byte {
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	return append(payload, reason...)
+}
+
+// Read consumes frames from the client, answering pings and the closing
+// handshake, until the connection closes or the client breaks the protocol.
+// It returns nil when the client closed the connection cleanly.
+func (c *Conn) Read() error {
+	messageSize := 0
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			c.mu.Lock()
+			closed := c.closed
+			c.mu.Unlock()
+			if closed {
+				return nil
+			}
+			if err == errTooBig {
+				c.Close(CloseMessageTooBig, "message too big")
+			} else if err != io.EOF {
+				c.Close(CloseProtocolError, "protocol error")
+			}
+			return err
+		}
+
+		switch opcode {
+		case opPing:
+			err = c.writeFrame(opPong, payload)
+			if err != nil {
+				return err
+			}
+		case opPong:
+		case opClose:
+			code := CloseNormal
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+			}
+			c.Close(code, "")
+			return nil
+		default:
+			// data frames are discarded, but a client may not stream an
+			// endless message into them
+			messageSize += len(payload)
+			if messageSize > maxMessageSize {
+				c.Close(CloseMessageTooBig, "message too big")
+				return errTooBig
+			}
+			if fin {
+				messageSize = 0
+			}
+		}
+	}
+}
+
+var errTooBig = errors.New("websocket: message too big")
+
+// readFrame reads and unmasks one frame
+func (c *Conn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	_, err = io.ReadFull(c.reader, header[:])
+	if err != nil {
+		return false, 0, nil, err
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, errors.New("websocket: reserved bits set")
+	}
+	if header[1]&0x80 == 0 {
+		return false, 0, nil, errors.New("websocket: client frame is not masked")
+	}
+	control := opcode >= opClose
+	switch opcode {
+	case opContinuation, opText, opBinary, opClose, opPing, opPong:
+	default:
+		return false, 0, nil, fmt.Errorf("websocket: unknown opcode %d", opcode)
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		_, err = io.ReadFull(c.reader, extended[:])
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		_, err = io.ReadFull(c.reader, extended[:])
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	if err != nil {
+		return false, 0, nil, err
+	}
+	if control && (length > 125 || !fin) {
+		return false, 0, nil, errors.New("websocket: invalid control frame")
+	}
+	if length > maxMessageSize {
+		return false, 0, nil, errTooBig
+	}
+
+	var mask [4]byte
+	_, err = io.ReadFull(c.reader, mask[:])
+	if err != nil {
+		return false, 0, nil, err
+	}
+	payload = make([]byte, length)
+	_, err = io.ReadFull(c.reader, payload)
+	if err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, opcode, payload, nil
+}
@@ -0,0 +1,3 @@
+module ekyc-gen
+
+go 1.21
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"unicode"
+)
+
+// initialisms are the words Go names spell in capitals
+var initialisms = map[string]bool{
+	"DOB": true, "HTTP": true, "ID": true, "IPFS": true, "JSON": true, "KYC": true,
+	"MSP": true, "PAN": true, "SLA": true, "SMS": true, "URL": true,
+}
+
+// goReserved are the identifiers a generated method declares itself, so a
+// parameter of the same name is renamed
+var goReserved = map[string]bool{
+	"c": true, "ctx": true, "err": true, "result": true, "out": true, "txID": true,
+	"context": true, "json": true, "strconv": true,
+}
+
+// exportedName turns a JSON property such as kycIds into a Go field name
+// such as KYCIDs
+func exportedName(property string) string {
+	var name strings.Builder
+	for _, word := range splitWords(property) {
+		upper := strings.ToUpper(word)
+		switch {
+		case initialisms[upper]:
+			name.WriteString(upper)
+		case len(word) > 1 && strings.HasSuffix(word, "s") && initialisms[upper[:len(upper)-1]]:
+			name.WriteString(upper[:len(upper)-1] + "s")
+		default:
+			name.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return name.String()
+}
+
+// splitWords splits a camelCase name into its words
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		if unicode.IsUpper(runes[i]) && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
+
+func goParamName(name string) string {
+	if goReserved[name] {
+		return name + "Arg"
+	}
+	return name
+}
+
+// goType is the Go type of a schema
+func goType(s *Schema) string {
+	switch {
+	case s.Ref != "":
+		return s.RefName()
+	case s.Type == "array":
+		return "[]" + goType(s.Items)
+	case s.Type == "string":
+		return "string"
+	case s.Type == "boolean":
+		return "bool"
+	case s.Type == "integer" && s.Format == "int32":
+		return "int32"
+	case s.Type == "integer":
+		return "int64"
+	case s.Type == "number":
+		return "float64"
+	case s.MapValues() != nil:
+		return "map[string]" + goType(s.MapValues())
+	}
+	return "interface{}"
+}
+
+// goResultType is the type a function returns a schema as: a pointer for
+// structs, so a missing result is nil
+func goResultType(s *Schema) string {
+	if s.Ref != "" {
+		return "*" + goType(s)
+	}
+	return goType(s)
+}
+
+// goArg returns the expression passing a parameter as a string argument,
+// and whether it needs marshaling first
+func goArg(name string, s *Schema) (string, bool) {
+	switch goType(s) {
+	case "string":
+		return name, false
+	case "bool":
+		return "strconv.FormatBool(" + name + ")", false
+	case "int32":
+		return "strconv.FormatInt(int64(" + name + "), 10)", false
+	case "int64":
+		return "strconv.FormatInt(" + name + ", 10)", false
+	case "float64":
+		return "strconv.FormatFloat(" + name + ", 'g', -1, 64)", false
+	}
+	return "string(" + name + "JSON)", true
+}
+
+// generateGo returns a Go client for the contract, in package pkg
+func generateGo(metadata *Metadata, contract *Contract, pkg string) ([]byte, error) {
+	var body bytes.Buffer
+	for _, name := range metadata.SchemaNames(contract) {
+		schema := metadata.Components.Schemas[name]
+		fmt.Fprintf(&body, "// %s mirrors the chaincode's %s\ntype %s struct {\n", name, name, name)
+		for _, property := range schema.PropertyNames() {
+			fieldType := goType(schema.Properties[property])
+			tag := property
+			if !schema.IsRequired(property) {
+				tag += ",omitempty"
+				if schema.Properties[property].Ref != "" {
+					fieldType = "*" + fieldType
+				}
+			}
+			fmt.Fprintf(&body, "%s %s `json:%q`\n", exportedName(property), fieldType, tag)
+		}
+		fmt.Fprintf(&body, "}\n\n")
+	}
+
+	fmt.Fprintf(&body, `// Ledger evaluates and submits chaincode transactions, as *fabric.Client does
+type Ledger interface {
+	Evaluate(ctx context.Context, function string, args ...string) ([]byte, error)
+	Submit(ctx context.Context, function string, args ...string) (string, []byte, error)
+}
+
+// Client calls the contract's functions through a Ledger
+type Client struct {
+	ledger Ledger
+}
+
+// New returns a client for ledger
+func New(ledger Ledger) *Client {
+	return &Client{ledger: ledger}
+}
+
+`)
+	for _, tx := range contract.Transactions {
+		writeGoMethod(&body, contract, tx)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by ekyc-gen from the contract metadata. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "// Package %s is a typed client for the %s contract of the eKYC chaincode.\n", pkg, contract.Name)
+	fmt.Fprintf(&b, "package %s\n\nimport (\n\"context\"\n", pkg)
+	for _, imported := range []string{"encoding/json", "strconv"} {
+		if bytes.Contains(body.Bytes(), []byte(imported[strings.LastIndex(imported, "/")+1:]+".")) {
+			fmt.Fprintf(&b, "%q\n", imported)
+		}
+	}
+	fmt.Fprintf(&b, ")\n\n")
+	b.Write(body.Bytes())
+
+	source, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated Go does not parse: %v", err)
+	}
+	return source, nil
+}
+
+func writeGoMethod(b *bytes.Buffer, contract *Contract, tx *Transaction) {
+	var params, args, marshal []string
+	for _, param := range tx.Parameters {
+		name := goParamName(param.Name)
+		params = append(params, name+" "+goType(param.Schema))
+		arg, marshaled := goArg(name, param.Schema)
+		args = append(args, arg)
+		if marshaled {
+			marshal = append(marshal, name)
+		}
+	}
+
+	// the values returned before the error, zero values first
+	var results, zeros []string
+	if tx.Returns != nil {
+		results = append(results, goResultType(tx.Returns))
+		zeros = append(zeros, goZero(tx.Returns))
+	}
+	if !tx.Evaluate() {
+		results = append(results, "string")
+		zeros = append(zeros, `""`)
+	}
+	results = append(results, "error")
+	returnErr := "return " + strings.Join(append(zeros, "err"), ", ")
+
+	verb := "submits"
+	if tx.Evaluate() {
+		verb = "evaluates"
+	}
+	fmt.Fprintf(b, "// %s %s %s", tx.Name, verb, tx.Name)
+	if !tx.Evaluate() {
+		fmt.Fprintf(b, " and returns its transaction ID")
+	}
+	fmt.Fprintf(b, "\nfunc (c *Client) %s(%s) (%s) {\n", tx.Name, strings.Join(append([]string{"ctx context.Context"}, params...), ", "), strings.Join(results, ", "))
+	for _, name := range marshal {
+		fmt.Fprintf(b, "%sJSON, err := json.Marshal(%s)\nif err != nil {\n%s\n}\n", name, name, returnErr)
+	}
+
+	call := strings.Join(append([]string{"ctx", fmt.Sprintf("%q", contract.FunctionName(tx))}, args...), ", ")
+	resultVar := "_"
+	if tx.Returns != nil {
+		resultVar = "result"
+	}
+	if tx.Evaluate() {
+		assign := ":="
+		if resultVar == "_" && len(marshal) > 0 {
+			assign = "="
+		}
+		fmt.Fprintf(b, "%s, err %s c.ledger.Evaluate(%s)\n", resultVar, assign, call)
+	} else {
+		fmt.Fprintf(b, "txID, %s, err := c.ledger.Submit(%s)\n", resultVar, call)
+	}
+	fmt.Fprintf(b, "if err != nil {\n%s\n}\n", returnErr)
+
+	var done []string
+	if tx.Returns != nil {
+		done = append(done, "out")
+		switch {
+		case goType(tx.Returns) == "string":
+			// contractapi returns strings as they are, not as JSON
+			fmt.Fprintf(b, "out := string(result)\n")
+		case tx.Returns.Ref != "":
+			fmt.Fprintf(b, "if len(result) == 0 {\nreturn nil%s, nil\n}\n", txIDResult(tx))
+			fmt.Fprintf(b, "out := new(%s)\nerr = json.Unmarshal(result, out)\nif err != nil {\n%s\n}\n", goType(tx.Returns), returnErr)
+		default:
+			fmt.Fprintf(b, "var out %s\nif len(result) > 0 {\nerr = json.Unmarshal(result, &out)\nif err != nil {\n%s\n}\n}\n", goType(tx.Returns), returnErr)
+		}
+	}
+	if !tx.Evaluate() {
+		done = append(done, "txID")
+	}
+	fmt.Fprintf(b, "return %s\n}\n\n", strings.Join(append(done, "nil"), ", "))
+}
+
+func txIDResult(tx *Transaction) string {
+	if tx.Evaluate() {
+		return ""
+	}
+	return ", txID"
+}
+
+func goZero(s *Schema) string {
+	switch goType(s) {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	case "int32", "int64", "float64":
+		return "0"
+	}
+	return "nil"
+}
@@ -0,0 +1,90 @@
+// Command gen generates typed Go and TypeScript clients for the eKYC
+// chaincode from its contract metadata, so callers stop hand-writing function
+// names and argument lists. Query functions, tagged evaluate through the
+// chaincode's GetEvaluateTransactions, are evaluated; all others submitted.
+//
+// contractapi names parameters param0, param1 and so on in the metadata;
+// -source recovers their names from the chaincode's Go source.
+//
+// Regenerate the clients after changing the chaincode's functions or types.
+// Refresh metadata.json from a peer running the new chaincode first:
+//
+//	peer chaincode query -C ekycChannel -n ekyc-chaincode \
+//	    -c '{"Args":["org.hyperledger.fabric:GetMetadata"]}' > metadata.json
+//	go generate
+package main
+
+//go:generate go run . -metadata metadata.json -source ../chaincode -go ../gateway/contract/contract.go -ts ../shared/contract.ts
+//go:generate go run . -metadata metadata.json -contract PolicyConfig -source ../chaincode -go ../gateway/contract/policy/policy.go -ts ../shared/policy.ts
+//go:generate go run . -metadata metadata.json -contract ConsentContract -source ../chaincode -go ../gateway/contract/consent/consent.go -ts ../shared/consent.ts
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	var (
+		metadataPath = flag.String("metadata", "metadata.json", "contract metadata returned by GetMetadata")
+		contractName = flag.String("contract", "", "contract to generate; empty for the default contract")
+		sourceDir    = flag.String("source", "", "chaincode source directory to read parameter names from")
+		goPath       = flag.String("go", "", "Go file to write; empty writes none")
+		goPackage    = flag.String("go-package", "", "package of the Go file; empty for its directory name")
+		tsPath       = flag.String("ts", "", "TypeScript file to write; empty writes none")
+	)
+	flag.Parse()
+	if *goPath == "" && *tsPath == "" {
+		log.Fatal("nothing to generate; set -go, -ts or both")
+	}
+
+	metadata, err := loadMetadata(*metadataPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	contract, err := metadata.contract(*contractName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *sourceDir != "" {
+		names, err := readNames(*sourceDir, contract.Name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		unmatched := applyNames(contract, names)
+		if len(unmatched) > 0 {
+			log.Printf("no matching method in %s for %s; refresh the metadata", *sourceDir, strings.Join(unmatched, ", "))
+		}
+	}
+
+	if *goPath != "" {
+		pkg := *goPackage
+		if pkg == "" {
+			absolute, err := filepath.Abs(*goPath)
+			if err != nil {
+				log.Fatal(err)
+			}
+			pkg = filepath.Base(filepath.Dir(absolute))
+		}
+		source, err := generateGo(metadata, contract, pkg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		write(*goPath, source)
+	}
+	if *tsPath != "" {
+		write(*tsPath, generateTypeScript(metadata, contract))
+	}
+}
+
+func write(path string, data []byte) {
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err == nil {
+		err = os.WriteFile(path, data, 0o644)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Metadata is the contract metadata a contractapi chaincode returns from
+// org.hyperledger.fabric:GetMetadata
+type Metadata struct {
+	Contracts  map[string]*Contract `json:"contracts"`
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+}
+
+// Contract is one contract of the chaincode
+type Contract struct {
+	Name         string         `json:"name"`
+	Default      bool           `json:"default"`
+	Transactions []*Transaction `json:"transactions"`
+}
+
+// Transaction is one contract function
+type Transaction struct {
+	Name       string       `json:"name"`
+	Tag        []string     `json:"tag"`
+	Parameters []*Parameter `json:"parameters"`
+	Returns    *Schema      `json:"returns"`
+}
+
+// Parameter is a function parameter. contractapi names them param0, param1
+// and so on; readNames recovers the names in the source.
+type Parameter struct {
+	Name   string  `json:"name"`
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is the JSON schema of a parameter, return value or component
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Items                *Schema            `json:"items"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+}
+
+// Evaluate reports whether the function only reads the ledger
+func (t *Transaction) Evaluate() bool {
+	return len(t.Tag) > 0 && strings.EqualFold(t.Tag[0], "evaluate")
+}
+
+// RefName is the component a schema refers to. Top-level references are
+// "#/components/schemas/X" and those inside components a bare "X".
+func (s *Schema) RefName() string {
+	return s.Ref[strings.LastIndex(s.Ref, "/")+1:]
+}
+
+// MapValues returns the schema of a map's values, or nil when the schema is
+// not a map
+func (s *Schema) MapValues() *Schema {
+	if s.Type != "object" || len(s.AdditionalProperties) == 0 {
+		return nil
+	}
+	var values Schema
+	if json.Unmarshal(s.AdditionalProperties, &values) != nil {
+		// additionalProperties: false, a struct without properties
+		return nil
+	}
+	return &values
+}
+
+// PropertyNames returns the properties in name order
+func (s *Schema) PropertyNames() []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsRequired reports whether property is required
+func (s *Schema) IsRequired(property string) bool {
+	for _, name := range s.Required {
+		if name == property {
+			return true
+		}
+	}
+	return false
+}
+
+// SchemaNames returns the names of the components a contract's functions
+// use, directly or through other components, in order
+func (m *Metadata) SchemaNames(contract *Contract) []string {
+	used := map[string]bool{}
+	var visit func(s *Schema)
+	visit = func(s *Schema) {
+		if s == nil {
+			return
+		}
+		if s.Ref != "" {
+			name := s.RefName()
+			if used[name] {
+				return
+			}
+			used[name] = true
+			visit(m.Components.Schemas[name])
+		}
+		visit(s.Items)
+		visit(s.MapValues())
+		for _, property := range s.Properties {
+			visit(property)
+		}
+	}
+	for _, tx := range contract.Transactions {
+		for _, param := range tx.Parameters {
+			visit(param.Schema)
+		}
+		visit(tx.Returns)
+	}
+
+	names := make([]string, 0, len(used))
+	for name := range used {
+		if m.Components.Schemas[name] != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FunctionName is the name a transaction is invoked by: its own for the
+// default contract, prefixed with the contract's name for the others
+func (c *Contract) FunctionName(tx *Transaction) string {
+	if c.Default {
+		return tx.Name
+	}
+	return c.Name + ":" + tx.Name
+}
+
+// loadMetadata reads the metadata file at path
+func loadMetadata(path string) (*Metadata, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var metadata Metadata
+	err = json.Unmarshal(data, &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata in %s: %v", path, err)
+	}
+	return &metadata, nil
+}
+
+// contract returns the named contract, or the default one when name is empty
+func (m *Metadata) contract(name string) (*Contract, error) {
+	for key, contract := range m.Contracts {
+		if (name == "" && contract.Default) || (name != "" && key == name) {
+			return contract, nil
+		}
+	}
+	if name == "" {
+		return nil, fmt.Errorf("metadata has no default contract; choose one with -contract")
+	}
+	return nil, fmt.Errorf("metadata has no contract %s", name)
+}
+
+// readNames parses the Go package in dir and returns the parameter names of
+// receiver's methods, without the leading transaction context
+func readNames(dir string, receiver string) (map[string][]string, error) {
+	packages, err := parser.ParseDir(token.NewFileSet(), dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	names := map[string][]string{}
+	for _, pkg := range packages {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv == nil || receiverName(fn.Recv.List[0].Type) != receiver {
+					continue
+				}
+				var params []string
+				for _, field := range fn.Type.Params.List {
+					for _, name := range field.Names {
+						params = append(params, name.Name)
+					}
+				}
+				if len(params) > 0 {
+					params = params[1:]
+				}
+				names[fn.Name.Name] = params
+			}
+		}
+	}
+	return names, nil
+}
+
+func receiverName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// applyNames renames the parameters of the contract's functions after the
+// source and returns the functions it could not match. Those keep the
+// metadata's names: the source has no such method, or its parameter count
+// differs because the metadata is stale.
+func applyNames(contract *Contract, names map[string][]string) []string {
+	var unmatched []string
+	for _, tx := range contract.Transactions {
+		params, ok := names[tx.Name]
+		if !ok || len(params) != len(tx.Parameters) {
+			unmatched = append(unmatched, tx.Name)
+			continue
+		}
+		for i, param := range tx.Parameters {
+			param.Name = params[i]
+		}
+	}
+	return unmatched
+}
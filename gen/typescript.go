@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// tsReserved are the words TypeScript does not allow as parameter names
+var tsReserved = map[string]bool{
+	"arguments": true, "await": true, "break": true, "case": true, "catch": true, "class": true,
+	"const": true, "continue": true, "debugger": true, "default": true, "delete": true, "do": true,
+	"else": true, "enum": true, "eval": true, "export": true, "extends": true, "false": true,
+	"finally": true, "for": true, "function": true, "if": true, "implements": true, "import": true,
+	"in": true, "instanceof": true, "interface": true, "let": true, "new": true, "null": true,
+	"package": true, "private": true, "protected": true, "public": true, "return": true,
+	"static": true, "super": true, "switch": true, "this": true, "throw": true, "true": true,
+	"try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true, "yield": true,
+}
+
+// maxLine is the width prettier wraps the rest of the TypeScript at
+const maxLine = 80
+
+// methodName turns a function name such as KYCExists into a TypeScript
+// method name such as kycExists
+func methodName(function string) string {
+	words := splitWords(function)
+	words[0] = strings.ToLower(words[0])
+	return strings.Join(words, "")
+}
+
+func tsParamName(name string) string {
+	if tsReserved[name] {
+		return name + "Arg"
+	}
+	return name
+}
+
+// tsType is the TypeScript type of a schema
+func tsType(s *Schema) string {
+	switch {
+	case s.Ref != "":
+		return s.RefName()
+	case s.Type == "array":
+		items := tsType(s.Items)
+		if strings.ContainsAny(items, " |") {
+			return "Array<" + items + ">"
+		}
+		return items + "[]"
+	case s.Type == "string":
+		return "string"
+	case s.Type == "boolean":
+		return "boolean"
+	case s.Type == "integer", s.Type == "number":
+		return "number"
+	case s.MapValues() != nil:
+		return "Record<string, " + tsType(s.MapValues()) + ">"
+	}
+	return "unknown"
+}
+
+// tsArg returns the expression passing a parameter as a string argument
+func tsArg(name string, s *Schema) string {
+	switch tsType(s) {
+	case "string":
+		return name
+	case "boolean", "number":
+		return "String(" + name + ")"
+	}
+	return "JSON.stringify(" + name + ")"
+}
+
+// writeList writes open, the items separated by commas and then close on one
+// line when they fit in maxLine, and otherwise one item per line as prettier
+// does
+func writeList(b *bytes.Buffer, indent string, open string, items []string, close string) {
+	line := indent + open + strings.Join(items, ", ") + close
+	if len(line) <= maxLine || len(items) == 0 {
+		b.WriteString(line + "\n")
+		return
+	}
+	b.WriteString(indent + open + "\n")
+	for _, item := range items {
+		b.WriteString(indent + "  " + item + ",\n")
+	}
+	b.WriteString(indent + close + "\n")
+}
+
+// generateTypeScript returns a TypeScript client for the contract, wrapping
+// a fabric-network or fabric-gateway Contract
+func generateTypeScript(metadata *Metadata, contract *Contract) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by ekyc-gen from the contract metadata. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "/**\n * Typed client for the %s contract of the eKYC chaincode\n */\n\n", contract.Name)
+
+	for _, name := range metadata.SchemaNames(contract) {
+		schema := metadata.Components.Schemas[name]
+		fmt.Fprintf(&b, "export interface %s {\n", name)
+		for _, property := range schema.PropertyNames() {
+			optional := "?"
+			if schema.IsRequired(property) {
+				optional = ""
+			}
+			fmt.Fprintf(&b, "  %s%s: %s;\n", property, optional, tsType(schema.Properties[property]))
+		}
+		fmt.Fprintf(&b, "}\n\n")
+	}
+
+	fmt.Fprintf(&b, `/**
+ * The transaction functions of a fabric-network or fabric-gateway Contract
+ */
+export interface Transactor {
+  submitTransaction(name: string, ...args: string[]): Promise<Uint8Array>;
+  evaluateTransaction(name: string, ...args: string[]): Promise<Uint8Array>;
+}
+
+const decoder = new TextDecoder();
+
+// contractapi returns strings as they are and everything else as JSON
+function text(result: Uint8Array): string {
+  return decoder.decode(result);
+}
+
+function parse<T>(result: Uint8Array): T {
+  const json = text(result);
+  return json === "" ? null : JSON.parse(json);
+}
+
+export class ContractClient {
+  constructor(private readonly contract: Transactor) {}
+`)
+	for _, tx := range contract.Transactions {
+		writeTSMethod(&b, contract, tx)
+	}
+	fmt.Fprintf(&b, "}\n")
+	return b.Bytes()
+}
+
+func writeTSMethod(b *bytes.Buffer, contract *Contract, tx *Transaction) {
+	var params []string
+	args := []string{fmt.Sprintf("%q", contract.FunctionName(tx))}
+	for _, param := range tx.Parameters {
+		name := tsParamName(param.Name)
+		params = append(params, name+": "+tsType(param.Schema))
+		args = append(args, tsArg(name, param.Schema))
+	}
+
+	resultType := "void"
+	if tx.Returns != nil {
+		resultType = tsType(tx.Returns)
+	}
+	call := "this.contract.submitTransaction("
+	if tx.Evaluate() {
+		call = "this.contract.evaluateTransaction("
+	}
+
+	b.WriteString("\n")
+	writeList(b, "  ", "async "+methodName(tx.Name)+"(", params, "): Promise<"+resultType+"> {")
+	switch {
+	case tx.Returns == nil:
+		writeList(b, "    ", "await "+call, args, ");")
+	case resultType == "string":
+		writeList(b, "    ", "const result = await "+call, args, ");")
+		b.WriteString("    return text(result);\n")
+	default:
+		writeList(b, "    ", "const result = await "+call, args, ");")
+		b.WriteString("    return parse(result);\n")
+	}
+	b.WriteString("  }\n")
+}
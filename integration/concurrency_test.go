@@ -0,0 +1,331 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ekyc-gateway/contract"
+	"ekyc-gateway/fabric"
+)
+
+const writers = 8
+
+// tagConcurrently has writers clients add a distinct tag each to one record
+// at the same time, and returns each tag's error
+func tagConcurrently(t *testing.T, config fabric.Config, kycID string) map[string]error {
+	t.Helper()
+	clients := make([]*contract.Client, writers)
+	for i := range clients {
+		clients[i] = dialConfig(t, config, User)
+	}
+
+	var (
+		mu      sync.Mutex
+		results = map[string]error{}
+		wg      sync.WaitGroup
+		start   = make(chan struct{})
+	)
+	for i, client := range clients {
+		tag := fmt.Sprintf("writer-%d", i)
+		wg.Add(1)
+		go func(client *contract.Client) {
+			defer wg.Done()
+			<-start
+			_, err := client.AddTag(context.Background(), kycID, tag)
+			mu.Lock()
+			results[tag] = err
+			mu.Unlock()
+		}(client)
+	}
+	close(start)
+	wg.Wait()
+	return results
+}
+
+func createRecord(t *testing.T, kyc *contract.Client) string {
+	t.Helper()
+	kycID := NewID()
+	_, err := kyc.CreateKYC(context.Background(), Individual(kycID))
+	if err != nil {
+		t.Fatalf("CreateKYC: %v", err)
+	}
+	return kycID
+}
+
+func sortedTags(record *contract.KYCRecord) []string {
+	tags := append([]string(nil), record.Tags...)
+	sort.Strings(tags)
+	return tags
+}
+
+// TestConflictingUpdatesLoseNothing checks that concurrent read-modify-write
+// transactions on one record either commit or fail with an MVCC conflict,
+// and that the record holds exactly the changes that committed
+func TestConflictingUpdatesLoseNothing(t *testing.T) {
+	config, err := NetworkConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kyc := dialConfig(t, config, User)
+	kycID := createRecord(t, kyc)
+
+	var committed []string
+	conflicts := 0
+	for tag, err := range tagConcurrently(t, config, kycID) {
+		switch {
+		case err == nil:
+			committed = append(committed, tag)
+		case fabric.IsMVCCConflict(err):
+			conflicts++
+		default:
+			t.Fatalf("AddTag %s: %v", tag, err)
+		}
+	}
+	sort.Strings(committed)
+	t.Logf("%d of %d writers committed, %d conflicted", len(committed), writers, conflicts)
+	if len(committed) == 0 {
+		t.Fatal("no writer committed")
+	}
+
+	tags := sortedTags(readKYC(t, kyc, kycID))
+	if strings.Join(tags, ",") != strings.Join(committed, ",") {
+		t.Fatalf("record tags %v, committed %v", tags, committed)
+	}
+}
+
+// TestConflictingUpdatesRetried checks that the client's MVCC retries get
+// every concurrent update onto the record
+func TestConflictingUpdatesRetried(t *testing.T) {
+	config, err := NetworkConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.MVCCRetries = 2 * writers
+	kyc := dialConfig(t, config, User)
+	kycID := createRecord(t, kyc)
+
+	var want []string
+	for tag, err := range tagConcurrently(t, config, kycID) {
+		if err != nil {
+			t.Fatalf("AddTag %s despite retries: %v", tag, err)
+		}
+		want = append(want, tag)
+	}
+	sort.Strings(want)
+
+	record := readKYC(t, kyc, kycID)
+	if tags := sortedTags(record); strings.Join(tags, ",") != strings.Join(want, ",") {
+		t.Fatalf("record tags %v, want %v", tags, want)
+	}
+	for _, tag := range want {
+		page, _, err := kyc.GetKYCByTag(context.Background(), tag, 10, "", "")
+		if err != nil {
+			t.Fatalf("GetKYCByTag %s: %v", tag, err)
+		}
+		if len(page.Records) != 1 || page.Records[0].ID != kycID {
+			t.Fatalf("tag index for %s holds %d records", tag, len(page.Records))
+		}
+	}
+}
+
+// TestConflictingDecisions checks that of two verifiers deciding one record
+// at once, the record ends up with the decision whose transaction committed
+// last, and that a retried decision is judged again against the new state
+func TestConflictingDecisions(t *testing.T) {
+	config, err := NetworkConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	kyc := dialConfig(t, config, User)
+	kycID := createRecord(t, kyc)
+
+	verifiers := []*contract.Client{dialConfig(t, config, Operator), dialConfig(t, config, Operator)}
+	decisions := []string{"VERIFIED", "REJECTED"}
+	errs := make([]error, len(decisions))
+	var wg sync.WaitGroup
+	for i := range decisions {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = verifiers[i].UpdateKYCStatus(ctx, kycID, decisions[i], fmt.Sprintf("verifier-%d", i), "concurrent decision")
+		}(i)
+	}
+	wg.Wait()
+
+	record := readKYC(t, kyc, kycID)
+	history, err := kyc.GetKYCHistory(ctx, kycID)
+	if err != nil {
+		t.Fatalf("GetKYCHistory: %v", err)
+	}
+	for i, err := range errs {
+		if err != nil && !fabric.IsMVCCConflict(err) {
+			t.Fatalf("UpdateKYCStatus %s: %v", decisions[i], err)
+		}
+	}
+	switch {
+	case errs[0] == nil && errs[1] == nil:
+		// serialised into different blocks; the later one wins
+		t.Logf("both decisions committed; record is %s", record.Status)
+	case errs[0] == nil:
+		if record.Status != "VERIFIED" {
+			t.Fatalf("only VERIFIED committed, record is %s", record.Status)
+		}
+	case errs[1] == nil:
+		if record.Status != "REJECTED" {
+			t.Fatalf("only REJECTED committed, record is %s", record.Status)
+		}
+	default:
+		t.Fatal("both decisions conflicted")
+	}
+	decided := 0
+	for _, entry := range history {
+		if entry.Action == "VERIFIED" || entry.Action == "REJECTED" {
+			decided++
+		}
+	}
+	if want := countNil(errs); decided != want {
+		t.Fatalf("history records %d decisions, %d committed", decided, want)
+	}
+}
+
+func countNil(errs []error) int {
+	n := 0
+	for _, err := range errs {
+		if err == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// proxied returns a client that reaches the network through a fault proxy
+func proxied(t *testing.T) (*contract.Client, *FaultProxy) {
+	t.Helper()
+	config, err := NetworkConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy, err := NewFaultProxy(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(proxy.Close)
+	return dialConfig(t, proxy.Config(config), User), proxy
+}
+
+// awaitTag waits for a transaction whose outcome the client never learned
+// to show up on the record
+func awaitTag(t *testing.T, kyc *contract.Client, kycID string, tag string) {
+	t.Helper()
+	deadline := time.Now().Add(15 * time.Second)
+	for {
+		for _, existing := range readKYC(t, kyc, kycID).Tags {
+			if existing == tag {
+				return
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("tag %s never reached %s", tag, kycID)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// TestEndorsementFailure checks that a transaction failing endorsement
+// leaves the record alone and can be sent again
+func TestEndorsementFailure(t *testing.T) {
+	ctx := context.Background()
+	kyc, proxy := proxied(t)
+	kycID := createRecord(t, kyc)
+
+	proxy.Fail(Endorse, false)
+	_, err := kyc.AddTag(ctx, kycID, "endorse-fault")
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to endorse") {
+		t.Fatalf("AddTag through a failing endorsement: got %v", err)
+	}
+	if tags := readKYC(t, kyc, kycID).Tags; len(tags) != 0 {
+		t.Fatalf("record tagged %v after a failed endorsement", tags)
+	}
+	if proxy.Calls(Submit) != 1 {
+		// only CreateKYC reached ordering
+		t.Fatalf("%d transactions were sent for ordering, want 1", proxy.Calls(Submit))
+	}
+
+	_, err = kyc.AddTag(ctx, kycID, "endorse-fault")
+	if err != nil {
+		t.Fatalf("AddTag after the fault cleared: %v", err)
+	}
+	awaitTag(t, kyc, kycID, "endorse-fault")
+}
+
+// TestSubmitFailure checks a transaction the orderer never receives: the
+// client reports the failure and the record is unchanged
+func TestSubmitFailure(t *testing.T) {
+	ctx := context.Background()
+	kyc, proxy := proxied(t)
+	kycID := createRecord(t, kyc)
+
+	proxy.Fail(Submit, false)
+	_, err := kyc.AddTag(ctx, kycID, "submit-fault")
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to submit") {
+		t.Fatalf("AddTag through a failing submit: got %v", err)
+	}
+	// give a stray transaction time to commit before checking
+	time.Sleep(5 * time.Second)
+	if tags := readKYC(t, kyc, kycID).Tags; len(tags) != 0 {
+		t.Fatalf("record tagged %v by a transaction never ordered", tags)
+	}
+}
+
+// TestLostResponses checks transactions whose outcome the client misses
+// after the orderer took them: they commit regardless, and sending them
+// again is harmless
+func TestLostResponses(t *testing.T) {
+	ctx := context.Background()
+	kyc, proxy := proxied(t)
+	kycID := createRecord(t, kyc)
+
+	proxy.Fail(Submit, true)
+	_, err := kyc.AddTag(ctx, kycID, "lost-submit")
+	if err == nil {
+		t.Fatal("AddTag with a lost submit response succeeded")
+	}
+	awaitTag(t, kyc, kycID, "lost-submit")
+
+	proxy.Fail(CommitStatus, true)
+	_, err = kyc.AddTag(ctx, kycID, "lost-status")
+	if err == nil || !strings.Contains(err.Error(), "failed to get commit status") {
+		t.Fatalf("AddTag with a lost commit status: got %v", err)
+	}
+	awaitTag(t, kyc, kycID, "lost-status")
+
+	// the caller cannot tell these apart from failures, so it sends them again
+	for _, tag := range []string{"lost-submit", "lost-status"} {
+		_, err = kyc.AddTag(ctx, kycID, tag)
+		if err != nil {
+			t.Fatalf("resending AddTag %s: %v", tag, err)
+		}
+	}
+	if tags := sortedTags(readKYC(t, kyc, kycID)); strings.Join(tags, ",") != "lost-status,lost-submit" {
+		t.Fatalf("record tags after resending: %v", tags)
+	}
+
+	proxy.Fail(CommitStatus, true)
+	lostID := NewID()
+	_, err = kyc.CreateKYC(ctx, Individual(lostID))
+	if err == nil {
+		t.Fatal("CreateKYC with a lost commit status succeeded")
+	}
+	_, err = kyc.CreateKYC(ctx, Individual(lostID))
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("resending CreateKYC %s: got %v, want an already exists rejection", lostID, err)
+	}
+}
@@ -0,0 +1,80 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestDecoyReadIsCommitted reads a decoy as a user without roles and checks
+// that the read's access log entry and DecoyAccessed event were committed,
+// which only a submitted read does
+func TestDecoyReadIsCommitted(t *testing.T) {
+	ctx := context.Background()
+	kyc, operator := dial(t, User), dial(t, Operator)
+	config, err := NetworkConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := Connect(config, Operator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	decoyID := NewID()
+	_, _, err = operator.PlantDecoy(ctx, Individual(decoyID), "integration")
+	if err != nil {
+		t.Fatalf("PlantDecoy: %v", err)
+	}
+
+	listening, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	events, err := listener.ChaincodeEvents(listening, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, txID, err := kyc.ReadKYC(ctx, decoyID, "")
+	if err != nil {
+		t.Fatalf("ReadKYC of the decoy: %v", err)
+	}
+
+	for {
+		event, err := events.Next()
+		if err != nil {
+			t.Fatalf("no event from the decoy read %s: %v", txID, err)
+		}
+		if event.TxID != txID {
+			continue
+		}
+		if event.Name != "DecoyAccessed" {
+			t.Fatalf("the decoy read emitted %s, want DecoyAccessed", event.Name)
+		}
+		var access struct {
+			KYCIDs   []string `json:"kycIds"`
+			Severity string   `json:"severity"`
+		}
+		err = json.Unmarshal(event.Payload, &access)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(access.KYCIDs) != 1 || access.KYCIDs[0] != decoyID || access.Severity != "HIGH" {
+			t.Fatalf("got DecoyAccessed for %v with severity %s, want %s with HIGH", access.KYCIDs, access.Severity, decoyID)
+		}
+		break
+	}
+
+	entries, err := operator.GetAccessLog(ctx, decoyID)
+	if err != nil {
+		t.Fatalf("GetAccessLog: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.TxID == txID && entry.Function == "ReadKYC" {
+			return
+		}
+	}
+	t.Fatalf("the access log of decoy %s has no entry for the read %s: %+v", decoyID, txID, entries)
+}
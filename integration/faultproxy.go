@@ -0,0 +1,173 @@
+package integration
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/hyperledger/fabric-protos-go/gateway"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"ekyc-gateway/fabric"
+)
+
+// Gateway service calls faults can be injected into
+const (
+	Endorse      = "Endorse"
+	Submit       = "Submit"
+	CommitStatus = "CommitStatus"
+)
+
+// FaultProxy sits between a client and a peer's gateway service and fails
+// the calls it is told to, so tests can break a transaction at each step of
+// its flow
+type FaultProxy struct {
+	gateway.UnimplementedGatewayServer
+	Addr string // plaintext address to dial instead of the peer
+
+	upstream gateway.GatewayClient
+	conn     *grpc.ClientConn
+	server   *grpc.Server
+
+	mu     sync.Mutex
+	faults map[string][]bool // per call, pending faults and whether each forwards first
+	calls  map[string]int
+}
+
+// NewFaultProxy starts a proxy on a local port in front of the peer config
+// names
+func NewFaultProxy(config fabric.Config) (*FaultProxy, error) {
+	transport := insecure.NewCredentials()
+	if config.TLSCACertPath != "" {
+		caPEM, err := os.ReadFile(config.TLSCACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS CA certificate: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in %s", config.TLSCACertPath)
+		}
+		transport = credentials.NewClientTLSFromCert(pool, config.ServerName)
+	}
+	conn, err := grpc.Dial(config.Endpoint, grpc.WithTransportCredentials(transport))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to gateway: %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	p := &FaultProxy{
+		Addr:     listener.Addr().String(),
+		upstream: gateway.NewGatewayClient(conn),
+		conn:     conn,
+		server:   grpc.NewServer(),
+		faults:   map[string][]bool{},
+		calls:    map[string]int{},
+	}
+	gateway.RegisterGatewayServer(p.server, p)
+	go p.server.Serve(listener)
+	return p, nil
+}
+
+// Config returns config pointed at the proxy
+func (p *FaultProxy) Config(config fabric.Config) fabric.Config {
+	config.Endpoint, config.TLSCACertPath, config.ServerName = p.Addr, "", ""
+	return config
+}
+
+// Close stops the proxy
+func (p *FaultProxy) Close() {
+	p.server.Stop()
+	p.conn.Close()
+}
+
+// Fail makes the next call of method fail as unavailable. With forward set
+// the call reaches the peer first, so it takes effect and only its response
+// is lost.
+func (p *FaultProxy) Fail(method string, forward bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.faults[method] = append(p.faults[method], forward)
+}
+
+// Calls returns how many times method has been called
+func (p *FaultProxy) Calls(method string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls[method]
+}
+
+// fault records a call of method and returns whether to fail it and whether
+// to forward it first
+func (p *FaultProxy) fault(method string) (fail bool, forward bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls[method]++
+	pending := p.faults[method]
+	if len(pending) == 0 {
+		return false, true
+	}
+	p.faults[method] = pending[1:]
+	return true, pending[0]
+}
+
+func injected(method string) error {
+	return status.Errorf(codes.Unavailable, "injected fault in %s", method)
+}
+
+// Endorse implements gateway.GatewayServer
+func (p *FaultProxy) Endorse(ctx context.Context, request *gateway.EndorseRequest) (*gateway.EndorseResponse, error) {
+	fail, forward := p.fault(Endorse)
+	var response *gateway.EndorseResponse
+	var err error
+	if forward {
+		response, err = p.upstream.Endorse(ctx, request)
+	}
+	if fail {
+		return nil, injected(Endorse)
+	}
+	return response, err
+}
+
+// Submit implements gateway.GatewayServer
+func (p *FaultProxy) Submit(ctx context.Context, request *gateway.SubmitRequest) (*gateway.SubmitResponse, error) {
+	fail, forward := p.fault(Submit)
+	var response *gateway.SubmitResponse
+	var err error
+	if forward {
+		response, err = p.upstream.Submit(ctx, request)
+	}
+	if fail {
+		return nil, injected(Submit)
+	}
+	return response, err
+}
+
+// CommitStatus implements gateway.GatewayServer
+func (p *FaultProxy) CommitStatus(ctx context.Context, request *gateway.SignedCommitStatusRequest) (*gateway.CommitStatusResponse, error) {
+	fail, forward := p.fault(CommitStatus)
+	var response *gateway.CommitStatusResponse
+	var err error
+	if forward {
+		response, err = p.upstream.CommitStatus(ctx, request)
+	}
+	if fail {
+		return nil, injected(CommitStatus)
+	}
+	return response, err
+}
+
+// Evaluate implements gateway.GatewayServer
+func (p *FaultProxy) Evaluate(ctx context.Context, request *gateway.EvaluateRequest) (*gateway.EvaluateResponse, error) {
+	return p.upstream.Evaluate(ctx, request)
+}
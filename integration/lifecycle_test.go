@@ -0,0 +1,251 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"ekyc-gateway/contract"
+	"ekyc-gateway/fabric"
+)
+
+func dial(t *testing.T, identity string) *contract.Client {
+	t.Helper()
+	config, err := NetworkConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dialConfig(t, config, identity)
+}
+
+func dialConfig(t *testing.T, config fabric.Config, identity string) *contract.Client {
+	t.Helper()
+	client, err := Connect(config, identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return contract.New(client)
+}
+
+func readKYC(t *testing.T, kyc *contract.Client, kycID string) *contract.KYCRecord {
+	t.Helper()
+	record, _, err := kyc.ReadKYC(context.Background(), kycID, "")
+	if err != nil {
+		t.Fatalf("ReadKYC %s: %v", kycID, err)
+	}
+	return record
+}
+
+// TestLifecycle takes one record through creation, the owner's nominee
+// consent, verification, expiry and renewal
+func TestLifecycle(t *testing.T) {
+	ctx := context.Background()
+	kyc, operator := dial(t, User), dial(t, Operator)
+	kycID := NewID()
+
+	_, err := kyc.CreateKYC(ctx, Individual(kycID))
+	if err != nil {
+		t.Fatalf("CreateKYC: %v", err)
+	}
+	record := readKYC(t, kyc, kycID)
+	if record.Status != "PENDING" || record.OwnerMSP != "Org1MSP" || record.SLADueAt == "" {
+		t.Fatalf("created record is %s owned by %s due %q; want PENDING, Org1MSP and an SLA due date", record.Status, record.OwnerMSP, record.SLADueAt)
+	}
+	_, err = kyc.CreateKYC(ctx, Individual(kycID))
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("second CreateKYC of %s: got %v, want an already exists rejection", kycID, err)
+	}
+
+	// consent
+	_, err = kyc.SetNominee(ctx, kycID, `{"nameHash":"4f2a","relationship":"SPOUSE"}`)
+	if err == nil || !strings.Contains(err.Error(), "consent reference is required") {
+		t.Fatalf("SetNominee without consent: got %v, want a consent rejection", err)
+	}
+	_, err = kyc.SetNominee(ctx, kycID, `{"nameHash":"4f2a","relationship":"SPOUSE","consentRef":"DECL-1"}`)
+	if err != nil {
+		t.Fatalf("SetNominee: %v", err)
+	}
+	record = readKYC(t, kyc, kycID)
+	if record.Nominee == nil || record.Nominee.Consent.ConsentRef != "DECL-1" || record.Nominee.Consent.RecordedBy == "" {
+		t.Fatalf("nominee after consent: %+v", record.Nominee)
+	}
+
+	// verify
+	_, err = operator.UpdateKYCStatus(ctx, kycID, "VERIFIED", "integration", "documents checked")
+	if err != nil {
+		t.Fatalf("UpdateKYCStatus VERIFIED: %v", err)
+	}
+	config, err := kyc.GetConfig(ctx)
+	if err != nil {
+		t.Fatalf("GetConfig: %v", err)
+	}
+	record = readKYC(t, kyc, kycID)
+	if record.Status != "VERIFIED" || record.VerifiedBy != "integration" || record.VerificationLevel != "L2" {
+		t.Fatalf("verified record is %s by %q at %s", record.Status, record.VerifiedBy, record.VerificationLevel)
+	}
+	assertExpiry(t, record, config)
+	firstExpiry := record.ExpiresAt
+
+	// expire
+	_, err = operator.UpdateKYCStatus(ctx, kycID, "EXPIRED", "integration", "re-KYC overdue")
+	if err != nil {
+		t.Fatalf("UpdateKYCStatus EXPIRED: %v", err)
+	}
+	record = readKYC(t, kyc, kycID)
+	if record.Status != "EXPIRED" {
+		t.Fatalf("expired record is %s", record.Status)
+	}
+	dashboard, err := kyc.GetComplianceDashboard(ctx)
+	if err != nil {
+		t.Fatalf("GetComplianceDashboard: %v", err)
+	}
+	if dashboard.ByStatus["EXPIRED"] < 1 {
+		t.Fatalf("dashboard counts %d expired records, want at least this one", dashboard.ByStatus["EXPIRED"])
+	}
+
+	// renew
+	_, err = operator.UpdateKYCStatus(ctx, kycID, "VERIFIED", "integration", "re-KYC completed")
+	if err != nil {
+		t.Fatalf("UpdateKYCStatus renewal: %v", err)
+	}
+	record = readKYC(t, kyc, kycID)
+	if record.Status != "VERIFIED" || record.ExpiresAt < firstExpiry {
+		t.Fatalf("renewed record is %s expiring %s, want VERIFIED expiring no earlier than %s", record.Status, record.ExpiresAt, firstExpiry)
+	}
+	assertExpiry(t, record, config)
+
+	history, err := kyc.GetKYCHistory(ctx, kycID)
+	if err != nil {
+		t.Fatalf("GetKYCHistory: %v", err)
+	}
+	actions := map[string]int{}
+	renewed := false
+	for _, entry := range history {
+		if entry.KYCID != kycID || entry.TxID == "" {
+			t.Fatalf("history entry %s is for %s in %q", entry.ID, entry.KYCID, entry.TxID)
+		}
+		actions[entry.Action]++
+		if entry.Action == "VERIFIED" && entry.Details["oldStatus"] == "EXPIRED" {
+			renewed = true
+		}
+	}
+	if actions["CREATED"] != 1 || actions["NOMINEE_UPDATED"] != 1 || actions["UPDATED"] != 1 || !renewed {
+		t.Fatalf("history actions %v, renewal recorded %v", actions, renewed)
+	}
+}
+
+// assertExpiry checks a verified record's re-KYC date against the period the
+// contract configures for its risk tier
+func assertExpiry(t *testing.T, record *contract.KYCRecord, config *contract.ContractConfig) {
+	t.Helper()
+	years := config.RekycYears.High
+	switch record.RiskTier {
+	case "LOW":
+		years = config.RekycYears.Low
+	case "MEDIUM":
+		years = config.RekycYears.Medium
+	}
+	verifiedAt, err := time.Parse(time.RFC3339, record.VerifiedAt)
+	if err != nil {
+		t.Fatalf("verifiedAt %q: %v", record.VerifiedAt, err)
+	}
+	want := verifiedAt.AddDate(int(years), 0, 0).Format(time.RFC3339)
+	if record.ExpiresAt != want {
+		t.Fatalf("%s record verified at %s expires %s, want %s", record.RiskTier, record.VerifiedAt, record.ExpiresAt, want)
+	}
+}
+
+// TestRoleAttributes checks that workflow functions are limited to the
+// certificate attributes the organisation's CA issues
+func TestRoleAttributes(t *testing.T) {
+	ctx := context.Background()
+	user, operator := dial(t, User), dial(t, Operator)
+	kycID := NewID()
+	_, err := user.CreateKYC(ctx, Individual(kycID))
+	if err != nil {
+		t.Fatalf("CreateKYC: %v", err)
+	}
+
+	_, err = user.EscalateKYC(ctx, kycID, "address mismatch", "integration")
+	if err == nil || !strings.Contains(err.Error(), "kyc.verifier attribute required") {
+		t.Fatalf("EscalateKYC without the verifier attribute: got %v", err)
+	}
+	_, err = operator.EscalateKYC(ctx, kycID, "address mismatch", "integration")
+	if err != nil {
+		t.Fatalf("EscalateKYC as operator: %v", err)
+	}
+	record := readKYC(t, user, kycID)
+	if record.Escalation == nil || record.Escalation.Reason != "address mismatch" || record.AssignedTo != "" {
+		t.Fatalf("escalated record: escalation %+v, assigned to %q", record.Escalation, record.AssignedTo)
+	}
+
+	_, err = user.AssignKYC(ctx, kycID, "senior-1")
+	if err == nil || !strings.Contains(err.Error(), "kyc.senior attribute required") {
+		t.Fatalf("AssignKYC without the senior attribute: got %v", err)
+	}
+	_, err = operator.AssignKYC(ctx, kycID, "senior-1")
+	if err != nil {
+		t.Fatalf("AssignKYC as operator: %v", err)
+	}
+	if record = readKYC(t, user, kycID); record.AssignedTo != "senior-1" {
+		t.Fatalf("assigned record is with %q", record.AssignedTo)
+	}
+
+	_, err = user.UpdateKYCStatus(ctx, kycID, "REJECTED", "integration", "not a verifier")
+	if err == nil || !strings.Contains(err.Error(), "kyc.verifier attribute required") {
+		t.Fatalf("UpdateKYCStatus without the verifier attribute: got %v", err)
+	}
+	_, err = user.DeleteKYC(ctx, kycID)
+	if err == nil || !strings.Contains(err.Error(), "kyc.admin attribute required") {
+		t.Fatalf("DeleteKYC without the admin attribute: got %v", err)
+	}
+	_, err = operator.DeleteKYC(ctx, kycID)
+	if err != nil {
+		t.Fatalf("DeleteKYC as operator: %v", err)
+	}
+}
+
+// TestRejectedTransactionsLeaveNoTrace checks that a transaction the
+// chaincode rejects changes neither the record nor its history
+func TestRejectedTransactionsLeaveNoTrace(t *testing.T) {
+	ctx := context.Background()
+	kyc := dial(t, User)
+	kycID := NewID()
+	_, err := kyc.CreateKYC(ctx, Individual(kycID))
+	if err != nil {
+		t.Fatalf("CreateKYC: %v", err)
+	}
+	before, err := json.Marshal(readKYC(t, kyc, kycID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = kyc.SetNominee(ctx, kycID, `{"nameHash":"4f2a","relationship":"COUSIN","consentRef":"DECL-2"}`)
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to endorse") {
+		t.Fatalf("SetNominee with an invalid relationship: got %v, want an endorsement failure", err)
+	}
+	_, _, err = kyc.ReadKYC(ctx, kycID+"-missing", "")
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("ReadKYC of a missing record: got %v", err)
+	}
+
+	after, err := json.Marshal(readKYC(t, kyc, kycID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(before) != string(after) {
+		t.Fatalf("record changed by a rejected transaction:\n%s\n%s", before, after)
+	}
+	history, err := kyc.GetKYCHistory(ctx, kycID)
+	if err != nil {
+		t.Fatalf("GetKYCHistory: %v", err)
+	}
+	if len(history) != 1 || history[0].Action != "CREATED" {
+		t.Fatalf("history after rejections: %+v", history)
+	}
+}
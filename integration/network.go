@@ -0,0 +1,87 @@
+// Package integration runs the chaincode's record lifecycle against a real
+// Fabric network through the gateway's client. The tests carry the
+// integration build tag, so go test ./... skips them; run.sh brings up the
+// fabric-samples test network, deploys the chaincode, enrols an operator and
+// runs them:
+//
+//	FABRIC_SAMPLES=~/fabric-samples ./run.sh
+//
+// To run them against another network, set the variables run.sh exports and
+// run go test -tags integration ./... here.
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"ekyc-gateway/fabric"
+)
+
+// Identities the tests act as. Each is read from EKYC_<identity>_CERT and
+// EKYC_<identity>_KEY.
+const (
+	User     = "USER"     // a client of the organisation, holding no contract roles
+	Operator = "OPERATOR" // holding the kyc.admin, kyc.verifier and kyc.senior attributes
+)
+
+// NetworkConfig returns the network named by the EKYC_ENDPOINT, EKYC_TLS_CA,
+// EKYC_SERVER_NAME, EKYC_CHANNEL and EKYC_CHAINCODE variables
+func NetworkConfig() (fabric.Config, error) {
+	config := fabric.Config{
+		Endpoint:      os.Getenv("EKYC_ENDPOINT"),
+		TLSCACertPath: os.Getenv("EKYC_TLS_CA"),
+		ServerName:    os.Getenv("EKYC_SERVER_NAME"),
+		Channel:       os.Getenv("EKYC_CHANNEL"),
+		Chaincode:     os.Getenv("EKYC_CHAINCODE"),
+	}
+	if config.Endpoint == "" || config.Channel == "" || config.Chaincode == "" {
+		return config, fmt.Errorf("EKYC_ENDPOINT, EKYC_CHANNEL and EKYC_CHAINCODE are required; see run.sh")
+	}
+	return config, nil
+}
+
+// Connect dials the network as identity, of the organisation EKYC_MSP names
+func Connect(config fabric.Config, identity string) (*fabric.Client, error) {
+	certPath, keyPath := os.Getenv("EKYC_"+identity+"_CERT"), os.Getenv("EKYC_"+identity+"_KEY")
+	if certPath == "" || keyPath == "" {
+		return nil, fmt.Errorf("EKYC_%s_CERT and EKYC_%s_KEY are required", identity, identity)
+	}
+
+	id, err := fabric.LoadIdentity(os.Getenv("EKYC_MSP"), certPath, keyPath)
+	if err != nil {
+		return nil, err
+	}
+	return fabric.Dial(config, id)
+}
+
+var sequence atomic.Int64
+
+// NewID returns a KYC ID no earlier run has used
+func NewID() string {
+	return fmt.Sprintf("IT%d-%d", time.Now().Unix(), sequence.Add(1))
+}
+
+// Individual returns the CreateKYC payload of a valid individual record
+func Individual(kycID string) string {
+	n := sequence.Add(1)
+	record, _ := json.Marshal(map[string]interface{}{
+		"id":          kycID,
+		"userId":      "integration-" + kycID,
+		"name":        fmt.Sprintf("Integration Customer %d", n),
+		"email":       fmt.Sprintf("integration%d@example.com", n),
+		"phone":       fmt.Sprintf("98%08d", n%100000000),
+		"pan":         fmt.Sprintf("ITGP%c%04d%c", 'A'+rune(n%26), n%10000, 'A'+rune(n/26%26)),
+		"dateOfBirth": "1985-06-15",
+		"address": map[string]string{
+			"street":  "12 Residency Road",
+			"city":    "Bengaluru",
+			"state":   "Karnataka",
+			"pincode": "560025",
+			"country": "IN",
+		},
+	})
+	return string(record)
+}
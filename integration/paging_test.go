@@ -0,0 +1,124 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+)
+
+// batchPageSize is small enough that the batch jobs below take several
+// pages over the records the test creates
+const batchPageSize = 2
+
+// pageThrough runs one page of a batch job after another, following its
+// bookmark to the end of the records, and returns how many it scanned
+func pageThrough(t *testing.T, job string, page func(bookmark string) (scanned int64, next string, err error)) int64 {
+	t.Helper()
+	total, bookmark := int64(0), ""
+	for pages := 1; ; pages++ {
+		scanned, next, err := page(bookmark)
+		if err != nil {
+			t.Fatalf("%s page %d: %v", job, pages, err)
+		}
+		total += scanned
+		if next == "" {
+			return total
+		}
+		if next == bookmark {
+			t.Fatalf("%s page %d returned its own bookmark %q", job, pages, next)
+		}
+		bookmark = next
+	}
+}
+
+// TestBatchJobsPageAndWrite runs the batch jobs that page through the records
+// and write in the same transaction. Fabric refuses a write after a
+// paginated query, and a paginated query after a write, in one transaction;
+// the in-memory stubs of the unit tests and benchmarks enforce neither, so
+// only a real peer shows whether a job pages in a way Fabric allows.
+func TestBatchJobsPageAndWrite(t *testing.T) {
+	ctx := context.Background()
+	kyc, operator := dial(t, User), dial(t, Operator)
+	created := []string{}
+	for i := 0; i < 2*batchPageSize+1; i++ {
+		created = append(created, createRecord(t, kyc))
+	}
+	verified := created[0]
+	_, err := operator.UpdateKYCStatus(ctx, verified, "VERIFIED", "integration", "documents checked")
+	if err != nil {
+		t.Fatalf("UpdateKYCStatus: %v", err)
+	}
+
+	jobs := []struct {
+		name string
+		page func(bookmark string) (int64, string, error)
+	}{
+		{"RecalculateRiskBatch", func(bookmark string) (int64, string, error) {
+			run, _, err := operator.RecalculateRiskBatch(ctx, batchPageSize, bookmark)
+			if err != nil {
+				return 0, "", err
+			}
+			return run.Scanned, run.Bookmark, nil
+		}},
+		{"BackfillRecordSchema", func(bookmark string) (int64, string, error) {
+			result, _, err := operator.BackfillRecordSchema(ctx, batchPageSize, bookmark)
+			if err != nil {
+				return 0, "", err
+			}
+			return result.Scanned, result.Bookmark, nil
+		}},
+		{"MigrateCountryCodes", func(bookmark string) (int64, string, error) {
+			result, _, err := operator.MigrateCountryCodes(ctx, batchPageSize, bookmark)
+			if err != nil {
+				return 0, "", err
+			}
+			return result.Scanned, result.Bookmark, nil
+		}},
+		{"RebuildCounters", func(bookmark string) (int64, string, error) {
+			result, _, err := operator.RebuildCounters(ctx, batchPageSize, bookmark)
+			if err != nil {
+				return 0, "", err
+			}
+			return result.Scanned, result.Bookmark, nil
+		}},
+	}
+	for _, job := range jobs {
+		t.Run(job.name, func(t *testing.T) {
+			scanned := pageThrough(t, job.name, job.page)
+			if scanned < int64(len(created)) {
+				t.Fatalf("%s scanned %d records; the test alone created %d", job.name, scanned, len(created))
+			}
+		})
+	}
+
+	t.Run("SendExpiryReminders", func(t *testing.T) {
+		reminded := map[string]bool{}
+		pageThrough(t, "SendExpiryReminders", func(bookmark string) (int64, string, error) {
+			result, _, err := operator.SendExpiryReminders(ctx, 20*365, batchPageSize, bookmark)
+			if err != nil {
+				return 0, "", err
+			}
+			for _, kycID := range result.Reminded {
+				reminded[kycID] = true
+			}
+			return result.Scanned, result.Bookmark, nil
+		})
+		if !reminded[verified] {
+			t.Fatalf("the sweep did not remind %s, verified by the test", verified)
+		}
+		// a second sweep has nothing new to remind the record of
+		pageThrough(t, "SendExpiryReminders again", func(bookmark string) (int64, string, error) {
+			result, _, err := operator.SendExpiryReminders(ctx, 20*365, batchPageSize, bookmark)
+			if err != nil {
+				return 0, "", err
+			}
+			for _, kycID := range result.Reminded {
+				if kycID == verified {
+					t.Errorf("the second sweep reminded %s again", verified)
+				}
+			}
+			return result.Scanned, result.Bookmark, nil
+		})
+	})
+}
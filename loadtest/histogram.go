@@ -0,0 +1,103 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencyBounds are the histogram bucket upper bounds, growing by a fifth
+// from one millisecond to two minutes
+var latencyBounds = func() []time.Duration {
+	bounds := []time.Duration{}
+	for bound := float64(time.Millisecond); bound < float64(2*time.Minute); bound *= 1.2 {
+		bounds = append(bounds, time.Duration(bound))
+	}
+	return append(bounds, 2*time.Minute)
+}()
+
+// opStats collects the outcomes and latencies of one transaction type
+type opStats struct {
+	mu        sync.Mutex
+	counts    []int64 // per latencyBounds bucket, with a final overflow bucket
+	max       time.Duration
+	succeeded int64
+	failed    int64
+	conflicts int64 // commit failures caused by MVCC read or phantom conflicts
+	dropped   int64 // scheduled transactions skipped because concurrency was exhausted
+}
+
+func newOpStats() *opStats {
+	return &opStats{counts: make([]int64, len(latencyBounds)+1)}
+}
+
+// record adds a completed transaction
+func (s *opStats) record(latency time.Duration, err error, conflict bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case conflict:
+		s.conflicts++
+	case err != nil:
+		s.failed++
+	default:
+		s.succeeded++
+	}
+	s.counts[sort.Search(len(latencyBounds), func(i int) bool { return latencyBounds[i] >= latency })]++
+	if latency > s.max {
+		s.max = latency
+	}
+}
+
+func (s *opStats) drop() {
+	s.mu.Lock()
+	s.dropped++
+	s.mu.Unlock()
+}
+
+// snapshot is a consistent copy of an opStats
+type snapshot struct {
+	Succeeded, Failed, Conflicts, Dropped int64
+	P50, P90, P99, Max                    time.Duration
+}
+
+func (s *opStats) snapshot() snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return snapshot{
+		Succeeded: s.succeeded,
+		Failed:    s.failed,
+		Conflicts: s.conflicts,
+		Dropped:   s.dropped,
+		P50:       s.quantile(0.50),
+		P90:       s.quantile(0.90),
+		P99:       s.quantile(0.99),
+		Max:       s.max,
+	}
+}
+
+// quantile returns the upper bound of the bucket holding quantile q; callers hold mu
+func (s *opStats) quantile(q float64) time.Duration {
+	total := int64(0)
+	for _, count := range s.counts {
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+
+	rank := int64(q*float64(total-1)) + 1
+	seen := int64(0)
+	for i, count := range s.counts {
+		seen += count
+		if seen >= rank {
+			if i == len(latencyBounds) {
+				return s.max
+			}
+			return latencyBounds[i]
+		}
+	}
+	return s.max
+}
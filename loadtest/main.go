@@ -0,0 +1,304 @@
+// Command loadtest drives CreateKYC and UpdateKYCStatus transactions at fixed
+// rates through a peer's Fabric Gateway service and reports latency
+// percentiles, failures and MVCC conflicts per transaction type.
+//
+// Transactions are scheduled open-loop: each type is started at its rate
+// regardless of how long earlier ones take, up to -concurrency in flight.
+// Transactions that cannot start because the limit is reached are counted as
+// dropped, so a saturated network shows up in the report rather than as a
+// silently lower rate. Updates target the most recently created records
+// (-hot-set), which is where conflicts occur in production.
+//
+// Created records are all alike unless -records names a file written by
+// tools/synth, whose payloads are then submitted in turn under the run's own
+// IDs, so the load exercises every entity type's validation and indexing.
+// Their documents have no malware scan, so updates of records that hold
+// documents are refused and counted as failures.
+// The identity must hold the kyc.verifier attribute for its updates to
+// succeed.
+//
+//	go run . -endpoint localhost:7051 -tls-ca tlsca.pem -cert cert.pem -key key.pem \
+//	    -create-rate 20 -update-rate 10 -duration 2m
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"os/signal"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"ekyc-gateway/fabric"
+)
+
+func main() {
+	var (
+		config      fabric.Config
+		mspID       = flag.String("msp", "Org1MSP", "MSP ID of the submitting identity")
+		certPath    = flag.String("cert", "", "PEM enrolment certificate of the submitting identity")
+		keyPath     = flag.String("key", "", "PEM private key of the submitting identity")
+		createRate  = flag.Float64("create-rate", 10, "CreateKYC transactions started per second")
+		updateRate  = flag.Float64("update-rate", 5, "UpdateKYCStatus transactions started per second")
+		duration    = flag.Duration("duration", time.Minute, "how long to generate load")
+		concurrency = flag.Int("concurrency", 64, "maximum transactions in flight")
+		hotSet      = flag.Int("hot-set", 100, "number of most recently created records that updates target")
+		idPrefix    = flag.String("id-prefix", fmt.Sprintf("LT%d", time.Now().Unix()), "prefix of generated KYC IDs")
+		interval    = flag.Duration("report-interval", 10*time.Second, "how often to print progress")
+		recordsPath = flag.String("records", "", "records generated by tools/synth to create instead of identical ones")
+	)
+	flag.StringVar(&config.Endpoint, "endpoint", "localhost:7051", "gateway peer address")
+	flag.StringVar(&config.TLSCACertPath, "tls-ca", "", "peer TLS CA certificate; empty connects without TLS")
+	flag.StringVar(&config.ServerName, "server-name", "", "TLS server name override")
+	flag.StringVar(&config.Channel, "channel", "ekycChannel", "channel name")
+	flag.StringVar(&config.Chaincode, "chaincode", "ekyc-chaincode", "chaincode name")
+	flag.Parse()
+
+	var records []map[string]interface{}
+	var err error
+	if *recordsPath != "" {
+		records, err = readRecords(*recordsPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	identity, err := fabric.LoadIdentity(*mspID, *certPath, *keyPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	client, err := fabric.Dial(config, identity)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *duration)
+	defer cancel()
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	run := &loadRun{
+		client:  client,
+		records: records,
+		ids:     &recentIDs{limit: *hotSet},
+		slots:   make(chan struct{}, *concurrency),
+		creates: newOpStats(),
+		updates: newOpStats(),
+	}
+
+	started := time.Now()
+	go run.report(ctx, *interval, started)
+
+	var schedulers sync.WaitGroup
+	schedulers.Add(2)
+	go func() {
+		defer schedulers.Done()
+		sequence := 0
+		run.schedule(ctx, *createRate, run.creates, func() func() error {
+			sequence++
+			kycID, n := fmt.Sprintf("%s-%08d", *idPrefix, sequence), sequence
+			return func() error { return run.create(kycID, n) }
+		})
+	}()
+	go func() {
+		defer schedulers.Done()
+		run.schedule(ctx, *updateRate, run.updates, func() func() error {
+			kycID, ok := run.ids.pick()
+			if !ok {
+				return nil
+			}
+			return func() error { return run.update(kycID) }
+		})
+	}()
+	schedulers.Wait()
+	run.inFlight.Wait()
+
+	run.printSummary(time.Since(started))
+}
+
+// loadRun holds the state shared by the transaction schedulers
+type loadRun struct {
+	client   *fabric.Client
+	records  []map[string]interface{} // CreateKYC payloads to cycle through, if any
+	ids      *recentIDs
+	slots    chan struct{}
+	inFlight sync.WaitGroup
+	creates  *opStats
+	updates  *opStats
+}
+
+// schedule starts a transaction rate times a second until ctx is done. next
+// runs on the scheduling goroutine and returns the function that submits the
+// transaction, or nil when there is nothing to submit yet.
+func (r *loadRun) schedule(ctx context.Context, rate float64, stats *opStats, next func() func() error) {
+	if rate <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		submit := next()
+		if submit == nil {
+			continue
+		}
+		select {
+		case r.slots <- struct{}{}:
+		default:
+			stats.drop()
+			continue
+		}
+
+		r.inFlight.Add(1)
+		go func() {
+			defer r.inFlight.Done()
+			defer func() { <-r.slots }()
+
+			startedAt := time.Now()
+			err := submit()
+			stats.record(time.Since(startedAt), err, fabric.IsMVCCConflict(err))
+		}()
+	}
+}
+
+// create submits a CreateKYC transaction for a synthetic individual, or for
+// the next of the loaded records
+func (r *loadRun) create(kycID string, sequence int) error {
+	payload := map[string]interface{}{
+		"id":          kycID,
+		"userId":      "loadtest-" + kycID,
+		"name":        fmt.Sprintf("Load Test Customer %d", sequence),
+		"email":       fmt.Sprintf("loadtest%d@example.com", sequence),
+		"phone":       fmt.Sprintf("9%09d", sequence%1000000000),
+		"pan":         fmt.Sprintf("LDTPT%04dZ", sequence%10000),
+		"dateOfBirth": "1990-01-01",
+		"address": map[string]string{
+			"street":  "1 MG Road",
+			"city":    "Pune",
+			"state":   "Maharashtra",
+			"pincode": "411001",
+			"country": "IN",
+		},
+	}
+	if len(r.records) > 0 {
+		payload = map[string]interface{}{}
+		for field, value := range r.records[(sequence-1)%len(r.records)] {
+			payload[field] = value
+		}
+		payload["id"], payload["userId"] = kycID, "loadtest-"+kycID
+	}
+	record, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.client.Submit(context.Background(), "CreateKYC", string(record))
+	if err == nil {
+		r.ids.add(kycID)
+	}
+	return err
+}
+
+// readRecords reads the CreateKYC payloads from a file of tools/synth records
+func readRecords(path string) ([]map[string]interface{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []map[string]interface{}
+	decoder := json.NewDecoder(f)
+	for decoder.More() {
+		var line struct {
+			Record map[string]interface{} `json:"record"`
+		}
+		err := decoder.Decode(&line)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read records from %s: %v", path, err)
+		}
+		records = append(records, line.Record)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no records in %s", path)
+	}
+	return records, nil
+}
+
+// update submits an UpdateKYCStatus transaction verifying a record
+func (r *loadRun) update(kycID string) error {
+	_, _, err := r.client.Submit(context.Background(), "UpdateKYCStatus", kycID, "VERIFIED", "loadtest", "load test verification")
+	return err
+}
+
+// report prints cumulative progress every interval until ctx is done
+func (r *loadRun) report(ctx context.Context, interval time.Duration, started time.Time) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		creates, updates := r.creates.snapshot(), r.updates.snapshot()
+		log.Printf("%s: create ok=%d conflicts=%d failed=%d p99=%s | update ok=%d conflicts=%d failed=%d p99=%s",
+			time.Since(started).Round(time.Second),
+			creates.Succeeded, creates.Conflicts, creates.Failed, creates.P99,
+			updates.Succeeded, updates.Conflicts, updates.Failed, updates.P99)
+	}
+}
+
+// printSummary writes the final per-transaction table
+func (r *loadRun) printSummary(elapsed time.Duration) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "transaction\tcommitted\tconflicts\tfailed\tdropped\ttps\tp50\tp90\tp99\tmax\t")
+	for _, op := range []struct {
+		name  string
+		stats *opStats
+	}{{"CreateKYC", r.creates}, {"UpdateKYCStatus", r.updates}} {
+		s := op.stats.snapshot()
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%.1f\t%s\t%s\t%s\t%s\t\n",
+			op.name, s.Succeeded, s.Conflicts, s.Failed, s.Dropped,
+			float64(s.Succeeded)/elapsed.Seconds(),
+			s.P50, s.P90, s.P99, s.Max)
+	}
+	w.Flush()
+}
+
+// recentIDs keeps the most recently created record IDs for updates to target
+type recentIDs struct {
+	mu    sync.Mutex
+	limit int
+	ids   []string
+}
+
+func (r *recentIDs) add(kycID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ids = append(r.ids, kycID)
+	if len(r.ids) > r.limit {
+		r.ids = r.ids[len(r.ids)-r.limit:]
+	}
+}
+
+func (r *recentIDs) pick() (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.ids) == 0 {
+		return "", false
+	}
+	return r.ids[rand.Intn(len(r.ids))], true
+}
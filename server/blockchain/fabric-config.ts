@@ -324,7 +324,8 @@ export class HyperledgerFabricService {
 
       console.log(`🔍 Querying KYC from blockchain: ${kycId}`);
 
-      const result = await this.contract.evaluateTransaction("ReadKYC", kycId);
+      // submitted, so the chaincode commits the read's access log
+      const result = await this.contract.submitTransaction("ReadKYC", kycId, "KYC_QUERY");
       const kycData = JSON.parse(result.toString());
 
       console.log("✅ KYC data retrieved from blockchain");
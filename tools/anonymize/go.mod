@@ -0,0 +1,3 @@
+module ekyc-anonymize
+
+go 1.21
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// object is a JSON object that keeps its fields in order, so a rewritten
+// document serialises with its fields where the chaincode wrote them
+type object struct {
+	fields []string
+	values map[string]interface{}
+}
+
+func (o *object) get(field string) (interface{}, bool) {
+	value, ok := o.values[field]
+	return value, ok
+}
+
+func (o *object) str(field string) string {
+	value, _ := o.values[field].(string)
+	return value
+}
+
+func (o *object) set(field string, value interface{}) {
+	if _, ok := o.values[field]; !ok {
+		o.fields = append(o.fields, field)
+	}
+	o.values[field] = value
+}
+
+// decodeJSON parses a document into objects, slices, strings, bools,
+// json.Numbers and nils
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	value, err := decodeValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("trailing data after JSON value")
+	}
+	return value, nil
+}
+
+func decodeValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		o := &object{values: map[string]interface{}{}}
+		for decoder.More() {
+			field, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			o.set(field.(string), value)
+		}
+		_, err := decoder.Token()
+		return o, err
+	case json.Delim('['):
+		array := []interface{}{}
+		for decoder.More() {
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err := decoder.Token()
+		return array, err
+	}
+	return token, nil
+}
+
+// encodeJSON serialises a document decoded by decodeJSON
+func encodeJSON(value interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	err := encodeValue(&b, value)
+	return b.Bytes(), err
+}
+
+func encodeValue(b *bytes.Buffer, value interface{}) error {
+	switch value := value.(type) {
+	case *object:
+		b.WriteByte('{')
+		for i, field := range value.fields {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			name, _ := json.Marshal(field)
+			b.Write(name)
+			b.WriteByte(':')
+			if err := encodeValue(b, value.values[field]); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	case []interface{}:
+		b.WriteByte('[')
+		for i, element := range value {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := encodeValue(b, element); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		b.Write(encoded)
+	}
+	return nil
+}
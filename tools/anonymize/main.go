@@ -0,0 +1,95 @@
+// Command anonymize turns a snapshot of the chaincode's world state into a
+// dataset of the same shape holding no customer's personal data, for seeding
+// development and test networks with realistic records.
+//
+// Names, emails, streets and free text are replaced with made-up values;
+// user and record IDs, document hashes and operator identities with IDs
+// derived from an HMAC of the original; PANs keep their format and holder
+// type, phone numbers their country code and operator prefix, and dates of
+// birth their year and month. The same value always gets the same
+// replacement, so records, their history, indexes, screening runs and
+// blacklist entries still refer to one another, and where the chaincode
+// keys something by a hash of an identifier, such as the phone index and
+// the internal blacklist, the key becomes the hash of the replacement.
+// City, state, pincode and country are kept so address indexes and
+// reporting behave as in production. Watchlist entries are renamed, so a
+// watchlist's content hash no longer matches its publisher's file.
+//
+// Snapshots are written by tools/replay -dump. Anonymize one with
+//
+//	go run . -in state.ndjson -out fixtures.ndjson -key "$ANONYMIZE_KEY"
+//
+// The key lets successive snapshots be anonymized consistently; whoever
+// holds it can test a guessed value against its replacement, so keep it
+// out of the test environments. Without -key a random one is used.
+// A key the tool has no rule for is an error rather than copied through,
+// since it could carry personal data.
+package main
+
+import (
+	"crypto/rand"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+func main() {
+	var (
+		inPath  = flag.String("in", "state.ndjson", "snapshot to anonymize")
+		outPath = flag.String("out", "fixtures.ndjson", "file to write the anonymized snapshot to")
+		key     = flag.String("key", "", "secret the replacements are derived from; random if empty")
+	)
+	flag.Parse()
+
+	secret := []byte(*key)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatal(err)
+		}
+	}
+	count, err := anonymize(*inPath, *outPath, secret)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("anonymized %d keys into %s\n", count, *outPath)
+}
+
+func anonymize(inPath, outPath string, secret []byte) (int, error) {
+	in, err := os.Open(inPath)
+	if err != nil {
+		return 0, err
+	}
+	entries, err := readSnapshot(in)
+	in.Close()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read snapshot %s: %v", inPath, err)
+	}
+
+	r := &rewriter{p: newPseudonyms(secret), listTypes: map[string]string{}}
+	err = r.learn(entries)
+	if err != nil {
+		return 0, err
+	}
+	rewritten := make([]entry, len(entries))
+	for i, e := range entries {
+		rewritten[i], err = r.rewrite(e)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	out, err := os.Create(outPath)
+	if err != nil {
+		return 0, err
+	}
+	err = writeSnapshot(out, rewritten)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to write %s: %v", outPath, err)
+	}
+	return len(rewritten), nil
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	givenNames = []string{
+		"Aarav", "Aditi", "Amit", "Ananya", "Arjun", "Deepa", "Divya", "Farhan", "Gaurav", "Ishaan",
+		"Kavya", "Kiran", "Lakshmi", "Manish", "Meera", "Neha", "Nikhil", "Pooja", "Pranav", "Priya",
+		"Rahul", "Ritu", "Rohan", "Sanjay", "Shreya", "Sneha", "Suresh", "Tanvi", "Varun", "Zoya",
+	}
+	surnames = []string{
+		"Agarwal", "Bhat", "Chopra", "Das", "Desai", "Ghosh", "Gupta", "Iyer", "Jain", "Joshi",
+		"Kapoor", "Khan", "Kulkarni", "Menon", "Mishra", "Nair", "Pandey", "Patel", "Pillai", "Rao",
+		"Reddy", "Saxena", "Shah", "Sharma", "Singh", "Sinha", "Srinivasan", "Thomas", "Varma", "Yadav",
+	}
+	companyWords = []string{
+		"Apex", "Banyan", "Cedar", "Crescent", "Delta", "Everest", "Falcon", "Ganga", "Harbour", "Indus",
+		"Jasmine", "Kaveri", "Lotus", "Meridian", "Neem", "Orchid", "Peacock", "Quartz", "Saffron", "Summit",
+	}
+	streetNames = []string{
+		"Ashoka", "Brigade", "Church", "Gandhi", "Hill", "Lake", "Link", "Market", "Nehru", "Park",
+		"Residency", "Station", "Temple", "Tilak", "Victoria",
+	}
+	streetKinds = []string{"Road", "Street", "Lane", "Marg", "Nagar", "Cross"}
+
+	// legal forms kept at the end of a pseudonymous entity name
+	legalForms = []string{"Private Limited", "Pvt Ltd", "Limited", "Ltd", "LLP", "Trust", "HUF", "& Co", "Associates"}
+
+	panPattern = regexp.MustCompile(`^[A-Z]{5}[0-9]{4}[A-Z]$`)
+	hexPattern = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+)
+
+// pseudonyms derives a replacement for each identifying value from an HMAC of
+// it, so every occurrence of a value gets the same replacement and the data
+// stays consistent across records, indexes and history. Hashes of values are
+// replaced by the same hash of the value's replacement when the snapshot
+// holds the value itself.
+type pseudonyms struct {
+	key    []byte
+	hashes map[string]string // SHA-256 of an identifying value to that of its replacement
+	names  map[string]string // normalized name to its replacement, normalized
+}
+
+func newPseudonyms(key []byte) *pseudonyms {
+	return &pseudonyms{key: key, hashes: map[string]string{}, names: map[string]string{}}
+}
+
+// sum returns n pseudorandom bytes for a value of a kind
+func (p *pseudonyms) sum(kind, value string, n int) []byte {
+	var out []byte
+	for block := 0; len(out) < n; block++ {
+		mac := hmac.New(sha256.New, p.key)
+		fmt.Fprintf(mac, "%s\x00%d\x00%s", kind, block, value)
+		out = mac.Sum(out)
+	}
+	return out[:n]
+}
+
+// pick chooses one of options for a value, words selecting independent
+// choices for the same value
+func (p *pseudonyms) pick(kind, value string, word int, options []string) string {
+	b := p.sum(kind, value, 8*(word+1))
+	return options[binary.BigEndian.Uint64(b[8*word:])%uint64(len(options))]
+}
+
+func (p *pseudonyms) hexString(kind, value string, n int) string {
+	return hex.EncodeToString(p.sum(kind, value, (n+1)/2))[:n]
+}
+
+// KYCID returns the replacement of a record ID
+func (p *pseudonyms) KYCID(id string) string {
+	if id == "" {
+		return ""
+	}
+	return "KYC" + strings.ToUpper(p.hexString("kyc", id, 12))
+}
+
+// UserID returns the replacement of a customer's user ID
+func (p *pseudonyms) UserID(id string) string {
+	if id == "" {
+		return ""
+	}
+	return "user-" + p.hexString("user", id, 12)
+}
+
+// Actor returns the replacement of an operator identity or name
+func (p *pseudonyms) Actor(actor string) string {
+	if actor == "" {
+		return ""
+	}
+	return "actor-" + p.hexString("actor", actor, 10)
+}
+
+// PersonName returns a realistic name for a person
+func (p *pseudonyms) PersonName(name string) string {
+	if name == "" {
+		return ""
+	}
+	key := normalizePersonName(name)
+	return p.pick("given", key, 0, givenNames) + " " + p.pick("surname", key, 0, surnames)
+}
+
+// EntityName returns a realistic name for a company, trust, partnership or
+// HUF, keeping the original's legal form
+func (p *pseudonyms) EntityName(name string) string {
+	if name == "" {
+		return ""
+	}
+	key := normalizePersonName(name)
+	replacement := p.pick("entity", key, 0, companyWords) + " " + p.pick("entity", key, 1, companyWords)
+	for _, form := range legalForms {
+		if strings.HasSuffix(strings.ToLower(name), " "+strings.ToLower(form)) {
+			return replacement + " " + form
+		}
+	}
+	return replacement
+}
+
+// Email returns an example.com address
+func (p *pseudonyms) Email(email string) string {
+	if email == "" {
+		return ""
+	}
+	key := strings.ToLower(strings.TrimSpace(email))
+	local := strings.ToLower(p.pick("email", key, 0, givenNames) + "." + p.pick("email", key, 1, surnames))
+	return fmt.Sprintf("%s%d@example.com", local, p.sum("email", key, 1)[0]%100)
+}
+
+// Phone replaces the last seven digits of a number, keeping its country code
+// and operator prefix so it still validates
+func (p *pseudonyms) Phone(phone string) string {
+	digits := p.sum("phone", phone, 7)
+	out := []rune(phone)
+	replaced := 0
+	for i := len(out) - 1; i >= 0 && replaced < 7; i-- {
+		if unicode.IsDigit(out[i]) {
+			out[i] = rune('0' + digits[replaced]%10)
+			replaced++
+		}
+	}
+	return string(out)
+}
+
+// PAN returns a PAN of the same holder type
+func (p *pseudonyms) PAN(pan string) string {
+	if !panPattern.MatchString(pan) {
+		return p.Scramble(pan)
+	}
+	b := p.sum("pan", pan, 9)
+	letter := func(i int) byte { return 'A' + b[i]%26 }
+	digit := func(i int) byte { return '0' + b[i]%10 }
+	return string([]byte{letter(0), letter(1), letter(2), pan[3], letter(3), digit(4), digit(5), digit(6), digit(7), letter(8)})
+}
+
+// DateOfBirth moves a date within its month, so ages change by no more than
+// a few weeks
+func (p *pseudonyms) DateOfBirth(date string) string {
+	parsed, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return p.Scramble(date)
+	}
+	day := 1 + int(p.sum("dob", date, 1)[0])%28
+	return time.Date(parsed.Year(), parsed.Month(), day, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+}
+
+// Street returns a realistic street address line
+func (p *pseudonyms) Street(street string) string {
+	if street == "" {
+		return ""
+	}
+	number := 1 + binary.BigEndian.Uint16(p.sum("street", street, 2))%200
+	return fmt.Sprintf("%d %s %s", number, p.pick("streetname", street, 0, streetNames), p.pick("streetkind", street, 0, streetKinds))
+}
+
+// Hash replaces a hex digest. A digest of a value the snapshot holds becomes
+// the same digest of the value's replacement, so lookups by hash still find
+// the record.
+func (p *pseudonyms) Hash(digest string) string {
+	if replacement, ok := p.hashes[strings.ToLower(digest)]; ok {
+		return replacement
+	}
+	if !hexPattern.MatchString(digest) {
+		return p.Scramble(digest)
+	}
+	replacement := p.hexString("hash", strings.ToLower(digest), len(digest))
+	if strings.ToUpper(digest) == digest {
+		replacement = strings.ToUpper(replacement)
+	}
+	return replacement
+}
+
+// NormalizedName replaces a name normalized with normalizePersonName
+func (p *pseudonyms) NormalizedName(name string) string {
+	if replacement, ok := p.names[name]; ok {
+		return replacement
+	}
+	return normalizePersonName(p.PersonName(name))
+}
+
+// Scramble replaces each letter with a letter of the same case and each
+// digit with a digit, keeping every other character, for values with a
+// format but no known meaning
+func (p *pseudonyms) Scramble(value string) string {
+	b := p.sum("scramble", value, len(value))
+	out := []rune(value)
+	for i, r := range out {
+		switch {
+		case r >= 'a' && r <= 'z':
+			out[i] = rune('a' + b[i]%26)
+		case r >= 'A' && r <= 'Z':
+			out[i] = rune('A' + b[i]%26)
+		case r >= '0' && r <= '9':
+			out[i] = rune('0' + b[i]%10)
+		case unicode.IsLetter(r):
+			out[i] = rune('a' + b[i]%26)
+		}
+	}
+	return string(out)
+}
+
+// Text replaces free text, which can hold anything
+func (p *pseudonyms) Text(text string) string {
+	if text == "" {
+		return ""
+	}
+	return "redacted"
+}
+
+// learnRecord registers the hashed and normalized forms of a record's
+// identifiers, which the chaincode keys indexes and blacklist entries by
+func (p *pseudonyms) learnRecord(pan, phone, dateOfBirth, name string, individual bool) {
+	if pan != "" {
+		p.hashes[identifierHash(strings.ToUpper(pan))] = identifierHash(strings.ToUpper(p.PAN(pan)))
+	}
+	if phone != "" {
+		p.hashes[identifierHash(phone)] = identifierHash(p.Phone(phone))
+	}
+	if dateOfBirth != "" {
+		p.hashes[identifierHash(dateOfBirth)] = identifierHash(p.DateOfBirth(dateOfBirth))
+	}
+	if name != "" && individual {
+		p.names[normalizePersonName(name)] = normalizePersonName(p.PersonName(name))
+	}
+}
+
+// identifierHash and normalizePersonName match the chaincode's
+func identifierHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizePersonName(name string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsSpace(r) {
+			return unicode.ToLower(r)
+		}
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, name)
+	return strings.Join(strings.Fields(cleaned), " ")
+}
@@ -0,0 +1,451 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	entityIndividual  = "INDIVIDUAL"
+	internalBlacklist = "INTERNAL_BLACKLIST"
+	watchlistType     = "WATCHLIST"
+)
+
+// fields naming the operator who performed an action
+var actorFields = map[string]bool{
+	"performedBy": true, "verifiedBy": true, "requestedBy": true, "approvedBy": true,
+	"decidedBy": true, "escalatedBy": true, "screenedBy": true, "analyst": true,
+	"recordedBy": true, "runBy": true, "updatedBy": true, "generatedBy": true,
+	"assignedTo": true, "previousAssignee": true, "assignee": true, "archivedBy": true,
+	"storedBy": true, "accessor": true, "grantedBy": true, "revokedBy": true,
+	"acknowledgedBy": true, "sponsoredBy": true, "plantedBy": true,
+	"registeredBy": true, "retiredBy": true, "escrowedBy": true, "signedBy": true, "verifierId": true, "boundBy": true,
+	"publishedBy": true, "placedBy": true, "operatorReference": true,
+}
+
+// rewriter anonymizes the documents of a snapshot
+type rewriter struct {
+	p         *pseudonyms
+	listTypes map[string]string // reference list name to its type
+}
+
+// learn collects what rewriting needs to know about the whole snapshot
+// before any entry is rewritten: the hashed identifiers of every record and
+// the type of every reference list
+func (r *rewriter) learn(entries []entry) error {
+	for _, e := range entries {
+		if e.Value == nil {
+			continue
+		}
+		value, err := decodeJSON(e.Value)
+		if err != nil {
+			return fmt.Errorf("%q: %v", e.Key, err)
+		}
+		doc, ok := value.(*object)
+		if !ok {
+			continue
+		}
+		if nested, ok := doc.values["record"].(*object); ok && (strings.HasPrefix(e.Key, "ARCHIVE_") || strings.HasPrefix(e.Key, "RECORD_")) {
+			r.learnRecord(nested)
+		}
+		objectType, attributes := splitCompositeKey(e.Key)
+		if isRecord(e.Key, doc) || objectType == "KYC" {
+			r.learnRecord(doc)
+		}
+		if objectType == "refList" && len(attributes) == 1 {
+			r.listTypes[attributes[0]] = doc.str("type")
+		}
+	}
+	return nil
+}
+
+func (r *rewriter) learnRecord(doc *object) {
+	r.p.learnRecord(doc.str("pan"), doc.str("phone"), doc.str("dateOfBirth"), doc.str("name"), doc.str("entityType") == entityIndividual)
+	if details, ok := doc.values["entityDetails"].(*object); ok {
+		for _, party := range relatedParties(details) {
+			r.p.learnRecord(party.str("pan"), "", "", "", false)
+		}
+	}
+}
+
+func relatedParties(details *object) []*object {
+	var parties []*object
+	for _, field := range []string{"directors", "trustees", "partners", "coparceners", "settlor", "karta"} {
+		switch value := details.values[field].(type) {
+		case *object:
+			parties = append(parties, value)
+		case []interface{}:
+			for _, element := range value {
+				if party, ok := element.(*object); ok {
+					parties = append(parties, party)
+				}
+			}
+		}
+	}
+	return parties
+}
+
+// isRecord reports whether a document is a KYC record stored under its own
+// ID, as the chaincode did before typed keys
+func isRecord(key string, doc *object) bool {
+	_, hasStatus := doc.get("status")
+	_, hasEntityType := doc.get("entityType")
+	return doc.str("id") == key && hasStatus && hasEntityType
+}
+
+// rewrite returns the anonymized form of an entry
+func (r *rewriter) rewrite(e entry) (entry, error) {
+	if objectType, attributes := splitCompositeKey(e.Key); objectType != "" {
+		return r.compositeEntry(e, objectType, attributes)
+	}
+	switch {
+	case strings.HasPrefix(e.Key, "COUNTER_"):
+		return e, nil
+	case strings.HasPrefix(e.Key, "MATCHSCORE_"):
+		// MATCHSCORE_<inverted score>_<kyc ID>
+		parts := strings.SplitN(e.Key, "_", 3)
+		if len(parts) != 3 {
+			return e, fmt.Errorf("malformed match score key %q", e.Key)
+		}
+		e.Key = parts[0] + "_" + parts[1] + "_" + r.p.KYCID(parts[2])
+		return e, nil
+	case e.Key == "CONFIG" || e.Key == "RISKRULES" || e.Key == "VALIDATIONHOOKS" || strings.HasPrefix(e.Key, "EXTSCHEMA_"):
+		return r.document(e, r.actors)
+	}
+
+	if e.Value == nil {
+		return e, fmt.Errorf("no rule for non-JSON key %q", e.Key)
+	}
+	value, err := decodeJSON(e.Value)
+	if err != nil {
+		return e, fmt.Errorf("%q: %v", e.Key, err)
+	}
+	doc, ok := value.(*object)
+	if !ok {
+		return e, fmt.Errorf("no rule for key %q", e.Key)
+	}
+	switch {
+	case isRecord(e.Key, doc):
+		r.record(doc)
+		e.Key = doc.str("id")
+	case strings.HasPrefix(e.Key, "ARCHIVE_"):
+		archived, ok := doc.values["record"].(*object)
+		if !ok {
+			return e, fmt.Errorf("archived record %q holds no record", e.Key)
+		}
+		r.record(archived)
+		doc.set("archivedBy", r.p.Actor(doc.str("archivedBy")))
+		e.Key = "ARCHIVE_" + archived.str("id")
+	case strings.HasPrefix(e.Key, "RECORD_"):
+		record, ok := doc.values["record"].(*object)
+		if !ok {
+			return e, fmt.Errorf("schema 2 record %q holds no record", e.Key)
+		}
+		r.record(record)
+		e.Key = "RECORD_" + record.str("id")
+	case strings.HasPrefix(e.Key, "HISTORY_"):
+		r.history(doc)
+		e.Key = "HISTORY_" + doc.str("id")
+	case strings.HasPrefix(e.Key, "EXCEPTIONTYPE_") || strings.HasPrefix(e.Key, "EXCEPTION_"):
+		r.walk(doc)
+		suffix := fmt.Sprintf("%s_%s_%s", doc.str("raisedAt"), doc.str("id"), doc.str("kycId"))
+		if strings.HasPrefix(e.Key, "EXCEPTIONTYPE_") {
+			e.Key = "EXCEPTIONTYPE_" + doc.str("type") + "_" + suffix
+		} else {
+			e.Key = "EXCEPTION_" + suffix
+		}
+	default:
+		return e, fmt.Errorf("no rule for key %q; add one to rewrite.go", e.Key)
+	}
+	return r.encode(e, doc)
+}
+
+// history rewrites a history entry, whose ID starts with its record's ID
+// when the chaincode derived it from that
+func (r *rewriter) history(doc *object) {
+	kycID, id := doc.str("kycId"), doc.str("id")
+	r.walk(doc)
+	if strings.HasPrefix(id, kycID+"-") {
+		doc.set("id", doc.str("kycId")+strings.TrimPrefix(id, kycID))
+	} else {
+		doc.set("id", r.p.Scramble(id))
+	}
+	// a signature's certificate names the signer, and no pseudonym can sign
+	if _, signed := doc.get("signature"); signed {
+		doc.set("signature", nil)
+	}
+}
+
+// record rewrites a KYC record, whose name is a person's or an entity's by
+// its entity type
+func (r *rewriter) record(doc *object) {
+	entityType, name := doc.str("entityType"), doc.str("name")
+	r.walk(doc)
+	if entityType != entityIndividual {
+		doc.set("name", r.p.EntityName(name))
+	}
+	if documents, ok := doc.values["documentHashes"].([]interface{}); ok {
+		for _, element := range documents {
+			if document, ok := element.(*object); ok && document.str("id") != "" {
+				document.set("id", r.p.Scramble(document.str("id")))
+			}
+		}
+	}
+	doc.set("id", r.p.KYCID(doc.str("id")))
+}
+
+// compositeEntry rewrites an entry under a composite key, whose attributes
+// carry the identifiers of the document or record it indexes
+func (r *rewriter) compositeEntry(e entry, objectType string, attributes []string) (entry, error) {
+	last := len(attributes) - 1
+	switch objectType {
+	case "tag~kycid", "pincode~kycid", "state~city~kycid", "sponsor~delegate~kycid", "docreview~kycid", "status~kycid":
+		attributes[last] = r.p.KYCID(attributes[last])
+	case "phone~kycid", "pan~kycid", "email~kycid", "faceHash~kycid":
+		attributes[0], attributes[last] = r.p.Hash(attributes[0]), r.p.KYCID(attributes[last])
+	case "openalert~kycid~runid", "screeningRun", "KYC", "HIST", "HISTHEAD", "PHOTO", "CONSENT", "ACCESS", "GRANT", "REVOCATION", "REVOCATIONACK", "DECOY":
+		attributes[0] = r.p.KYCID(attributes[0])
+	case "stalescreening~list~kycid":
+		attributes[last] = r.p.KYCID(attributes[last])
+	case "refListEntry":
+		attributes[last] = r.listKey(attributes[0], attributes[last])
+	case "TSTOKEN":
+		attributes[1] = r.timestampReference(attributes[0], attributes[1])
+	case "reportESign":
+		attributes[last] = r.p.Scramble(attributes[last])
+	case "RATE":
+		attributes[last] = r.p.Actor(attributes[last])
+	case "VERIFIER":
+		attributes[0] = r.p.Actor(attributes[0])
+	case "VERIFIERCERT":
+		attributes[0] = r.p.Hash(attributes[0])
+	case "refList", "monthlySummary", "riskRecalculation", "riskRuleSet", "ANCHOR", "SPONSORSHIP", "BULKACCESS", "FIELDKEY", "ESCROW", "RECOVERY", "REPORTSIG", "POLICY", "TELEMETRY":
+	default:
+		return e, fmt.Errorf("no rule for composite key type %q; add one to rewrite.go", objectType)
+	}
+	e.Key = compositeKey(objectType, attributes)
+
+	switch objectType {
+	case "refList":
+		return r.document(e, func(doc *object) {
+			r.actors(doc)
+			if hash := doc.str("contentHash"); hash != "" {
+				doc.set("contentHash", r.p.Hash(hash))
+			}
+		})
+	case "refListEntry":
+		return r.document(e, func(doc *object) {
+			list := doc.str("list")
+			key := doc.str("key")
+			r.walk(doc)
+			doc.set("key", r.listKey(list, key))
+		})
+	case "monthlySummary", "riskRuleSet", "ANCHOR", "SPONSORSHIP", "POLICY":
+		return r.document(e, r.actors)
+	case "BULKACCESS":
+		return r.document(e, func(doc *object) {
+			r.actors(doc)
+			if bookmark := doc.str("bookmark"); bookmark != "" {
+				doc.set("bookmark", r.p.Scramble(bookmark)) // may hold a record key
+			}
+		})
+	case "REPORTSIG":
+		return r.document(e, func(doc *object) {
+			r.actors(doc)
+			// the certificate names the signer, and no pseudonym can sign
+			doc.set("signature", nil)
+			doc.set("certificate", nil)
+		})
+	case "riskRecalculation", "screeningRun", "stalescreening~list~kycid", "HISTHEAD", "reportESign", "PHOTO", "CONSENT", "ACCESS", "GRANT", "REVOCATION", "REVOCATIONACK", "DECOY", "FIELDKEY", "ESCROW", "RECOVERY", "VERIFIER", "VERIFIERCERT":
+		return r.document(e, r.walk)
+	case "KYC":
+		return r.document(e, r.record)
+	case "HIST":
+		return r.document(e, r.history)
+	case "TSTOKEN":
+		return r.document(e, func(doc *object) {
+			r.actors(doc)
+			doc.set("reference", r.timestampReference(doc.str("event"), doc.str("reference")))
+		})
+	}
+	return e, nil
+}
+
+// document applies edit to an entry's JSON document
+func (r *rewriter) document(e entry, edit func(*object)) (entry, error) {
+	if e.Value == nil {
+		return e, nil
+	}
+	value, err := decodeJSON(e.Value)
+	if err != nil {
+		return e, fmt.Errorf("%q: %v", e.Key, err)
+	}
+	doc, ok := value.(*object)
+	if !ok {
+		return e, nil
+	}
+	edit(doc)
+	return r.encode(e, doc)
+}
+
+func (r *rewriter) encode(e entry, doc *object) (entry, error) {
+	value, err := encodeJSON(doc)
+	if err != nil {
+		return e, fmt.Errorf("%q: %v", e.Key, err)
+	}
+	e.Value = value
+	return e, nil
+}
+
+// actors replaces the operator identities anywhere in a document that
+// otherwise holds no personal data
+func (r *rewriter) actors(doc *object) {
+	for _, field := range doc.fields {
+		switch value := doc.values[field].(type) {
+		case string:
+			if actorFields[field] {
+				doc.values[field] = r.p.Actor(value)
+			}
+		case *object:
+			r.actors(value)
+		case []interface{}:
+			for _, element := range value {
+				if o, ok := element.(*object); ok {
+					r.actors(o)
+				}
+			}
+		}
+	}
+}
+
+// walk replaces every personal or identifying field of a document, by field
+// name wherever it appears
+func (r *rewriter) walk(doc *object) {
+	if list, ok := doc.values["list"].(string); ok {
+		if key, ok := doc.values["entryKey"].(string); ok && key != "" {
+			doc.values["entryKey"] = r.listKey(list, key)
+		}
+	}
+	for _, field := range doc.fields {
+		doc.values[field] = r.field(field, doc.values[field])
+	}
+}
+
+func (r *rewriter) field(field string, value interface{}) interface{} {
+	switch value := value.(type) {
+	case *object:
+		if field == "extensions" || field == "attributes" || field == "hashes" {
+			return r.scrambleAll(value)
+		}
+		r.walk(value)
+		return value
+	case []interface{}:
+		for i, element := range value {
+			value[i] = r.field(field, element)
+		}
+		return value
+	case string:
+		if actorFields[field] {
+			return r.p.Actor(value)
+		}
+		switch field {
+		case "kycId", "nomineeKycId", "kycIds":
+			return r.p.KYCID(value)
+		case "userId", "piiPrincipalId":
+			return r.p.UserID(value)
+		case "name":
+			return r.p.PersonName(value)
+		case "legalName":
+			return r.p.EntityName(value)
+		case "email":
+			return r.p.Email(value)
+		case "phone":
+			return r.p.Phone(value)
+		case "pan":
+			return r.p.PAN(value)
+		case "dateOfBirth":
+			return r.p.DateOfBirth(value)
+		case "street":
+			return r.p.Street(value)
+		case "hash", "sourceHash", "summaryHash", "nameHash", "documentHash", "extractedFieldsHash", "previousHash",
+			"selfieHash", "docPhotoHash", "faceHash", "photoHash", "fingerprint":
+			return r.p.Hash(value)
+		case "ipfsHash", "registrationNumber", "consentRef", "signerRef", "esignTxnId", "documentId", "photoIpfsRef",
+			"ciphertext", "keyRef", "encryptedShare", "subject", "issuer":
+			return r.p.Scramble(value)
+		case "remarks", "reason", "justification":
+			return r.p.Text(value)
+		}
+	}
+	return value
+}
+
+// scrambleAll scrambles every string in a document whose fields are defined
+// outside the chaincode, such as extension data and list entry attributes,
+// or named after other fields, such as the salted hashes of private records
+func (r *rewriter) scrambleAll(value interface{}) interface{} {
+	switch value := value.(type) {
+	case *object:
+		for _, field := range value.fields {
+			value.values[field] = r.scrambleAll(value.values[field])
+		}
+	case []interface{}:
+		for i, element := range value {
+			value[i] = r.scrambleAll(element)
+		}
+	case string:
+		return r.p.Scramble(value)
+	}
+	return value
+}
+
+// listKey rewrites the key of a reference list entry. Internal blacklist
+// keys are built from a record's hashed PAN or normalized name and hashed
+// date of birth and follow the record; watchlist keys are names.
+func (r *rewriter) listKey(list, key string) string {
+	switch {
+	case list == internalBlacklist && strings.HasPrefix(key, "pan:"):
+		return "pan:" + r.p.Hash(strings.TrimPrefix(key, "pan:"))
+	case list == internalBlacklist && strings.HasPrefix(key, "namedob:"):
+		name, dobHash, _ := strings.Cut(strings.TrimPrefix(key, "namedob:"), "|")
+		return "namedob:" + r.p.NormalizedName(name) + "|" + r.p.Hash(dobHash)
+	case list == "EMAIL_DOMAIN_BLOCKLIST", list == "HOLD_REASONS":
+		return key
+	case r.listTypes[list] == watchlistType:
+		return strings.ToLower(r.p.PersonName(key))
+	}
+	return r.p.Scramble(key)
+}
+
+// timestampReference rewrites the reference of a timestamp token. History
+// references are <kyc ID>/<tx ID>/<sequence> and follow the record; report
+// references are months.
+func (r *rewriter) timestampReference(event, reference string) string {
+	cut := strings.LastIndex(reference, "/")
+	if event != "HISTORY" || cut < 0 {
+		return reference
+	}
+	cut = strings.LastIndex(reference[:cut], "/")
+	if cut < 0 {
+		return reference
+	}
+	return r.p.KYCID(reference[:cut]) + reference[cut:]
+}
+
+// splitCompositeKey returns the object type and attributes of a composite
+// key, or an empty object type for a simple key
+func splitCompositeKey(key string) (string, []string) {
+	if !strings.HasPrefix(key, "\x00") || !strings.HasSuffix(key, "\x00") || len(key) < 2 {
+		return "", nil
+	}
+	parts := strings.Split(key[1:len(key)-1], "\x00")
+	return parts[0], parts[1:]
+}
+
+func compositeKey(objectType string, attributes []string) string {
+	key := "\x00" + objectType + "\x00"
+	for _, attribute := range attributes {
+		key += attribute + "\x00"
+	}
+	return key
+}
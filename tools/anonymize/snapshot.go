@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// entry is one line of a state snapshot: a world state key with its value,
+// given as JSON when the value is a JSON document and base64 otherwise
+type entry struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value,omitempty"`
+	Bytes []byte          `json:"bytes,omitempty"`
+}
+
+// readSnapshot reads a snapshot of newline-separated entries
+func readSnapshot(r io.Reader) ([]entry, error) {
+	var entries []entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e entry
+		err := json.Unmarshal(scanner.Bytes(), &e)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// writeSnapshot writes entries sorted by key, one per line
+func writeSnapshot(w io.Writer, entries []entry) error {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	out := bufio.NewWriter(w)
+	for i, e := range entries {
+		if i > 0 && e.Key == entries[i-1].Key {
+			return fmt.Errorf("two entries map to key %q", e.Key)
+		}
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		out.Write(line)
+		out.WriteByte('\n')
+	}
+	return out.Flush()
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// transaction is a chaincode invocation as the ledger recorded it
+type transaction struct {
+	Block    uint64
+	Index    int
+	TxID     string
+	Channel  string
+	Valid    bool
+	Input    *peer.ChaincodeInput
+	Proposal *peer.SignedProposal
+	Response *peer.Response
+	Event    *peer.ChaincodeEvent
+	Writes   []*kvrwset.KVWrite
+}
+
+// Function returns the name the transaction invoked
+func (tx *transaction) Function() string {
+	if len(tx.Input.Args) == 0 {
+		return ""
+	}
+	return string(tx.Input.Args[0])
+}
+
+// readBlocks reads the blocks peer channel fetch wrote to dir, in block
+// order. They must be consecutive, so that no transaction's writes are
+// missing from the replayed state.
+func readBlocks(dir string) ([]*common.Block, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var blocks []*common.Block
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		block := &common.Block{}
+		if err := proto.Unmarshal(data, block); err != nil || block.Header == nil {
+			return nil, fmt.Errorf("%s is not a block: %v", entry.Name(), err)
+		}
+		blocks = append(blocks, block)
+	}
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("no blocks in %s", dir)
+	}
+
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].Header.Number < blocks[j].Header.Number })
+	for i := 1; i < len(blocks); i++ {
+		if want := blocks[i-1].Header.Number + 1; blocks[i].Header.Number != want {
+			return nil, fmt.Errorf("block %d is missing from %s", want, dir)
+		}
+	}
+	return blocks, nil
+}
+
+// transactions returns the block's invocations of the namespace chaincode
+func transactions(block *common.Block, namespace string) ([]*transaction, error) {
+	var filter []byte
+	if metadata := block.GetMetadata().GetMetadata(); len(metadata) > int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		filter = metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER]
+	}
+
+	var txs []*transaction
+	for i, data := range block.GetData().GetData() {
+		tx, err := parseTransaction(data, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("block %d transaction %d: %v", block.Header.Number, i, err)
+		}
+		if tx == nil {
+			continue
+		}
+		tx.Block, tx.Index = block.Header.Number, i
+		tx.Valid = i < len(filter) && peer.TxValidationCode(filter[i]) == peer.TxValidationCode_VALID
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// parseTransaction decodes an envelope, returning nil for one that is not
+// an endorser transaction invoking the namespace chaincode
+func parseTransaction(data []byte, namespace string) (*transaction, error) {
+	envelope := &common.Envelope{}
+	if err := proto.Unmarshal(data, envelope); err != nil {
+		return nil, err
+	}
+	payload := &common.Payload{}
+	if err := proto.Unmarshal(envelope.Payload, payload); err != nil {
+		return nil, err
+	}
+	channelHeader := &common.ChannelHeader{}
+	if err := proto.Unmarshal(payload.GetHeader().GetChannelHeader(), channelHeader); err != nil {
+		return nil, err
+	}
+	if channelHeader.Type != int32(common.HeaderType_ENDORSER_TRANSACTION) {
+		return nil, nil
+	}
+
+	body := &peer.Transaction{}
+	if err := proto.Unmarshal(payload.Data, body); err != nil {
+		return nil, err
+	}
+	if len(body.Actions) != 1 {
+		return nil, fmt.Errorf("%d actions, want 1", len(body.Actions))
+	}
+	actionPayload := &peer.ChaincodeActionPayload{}
+	if err := proto.Unmarshal(body.Actions[0].Payload, actionPayload); err != nil {
+		return nil, err
+	}
+	proposalPayload := &peer.ChaincodeProposalPayload{}
+	if err := proto.Unmarshal(actionPayload.ChaincodeProposalPayload, proposalPayload); err != nil {
+		return nil, err
+	}
+	invocation := &peer.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(proposalPayload.Input, invocation); err != nil {
+		return nil, err
+	}
+	if invocation.GetChaincodeSpec().GetChaincodeId().GetName() != namespace {
+		return nil, nil
+	}
+
+	responsePayload := &peer.ProposalResponsePayload{}
+	if err := proto.Unmarshal(actionPayload.GetAction().GetProposalResponsePayload(), responsePayload); err != nil {
+		return nil, err
+	}
+	action := &peer.ChaincodeAction{}
+	if err := proto.Unmarshal(responsePayload.Extension, action); err != nil {
+		return nil, err
+	}
+	results := &rwset.TxReadWriteSet{}
+	if err := proto.Unmarshal(action.Results, results); err != nil {
+		return nil, err
+	}
+
+	tx := &transaction{
+		TxID:     channelHeader.TxId,
+		Channel:  channelHeader.ChannelId,
+		Input:    invocation.ChaincodeSpec.Input,
+		Response: action.Response,
+	}
+	if tx.Input == nil {
+		tx.Input = &peer.ChaincodeInput{}
+	}
+	for _, set := range results.NsRwset {
+		if set.Namespace != namespace {
+			continue
+		}
+		kv := &kvrwset.KVRWSet{}
+		if err := proto.Unmarshal(set.Rwset, kv); err != nil {
+			return nil, err
+		}
+		tx.Writes = kv.Writes
+	}
+	if len(action.Events) > 0 {
+		tx.Event = &peer.ChaincodeEvent{}
+		if err := proto.Unmarshal(action.Events, tx.Event); err != nil {
+			return nil, err
+		}
+	}
+
+	// The transaction carries the proposal's header and payload, less its
+	// transient data, which is all the shim reads from a proposal
+	header, err := proto.Marshal(payload.Header)
+	if err != nil {
+		return nil, err
+	}
+	proposal, err := proto.Marshal(&peer.Proposal{Header: header, Payload: actionPayload.ChaincodeProposalPayload})
+	if err != nil {
+		return nil, err
+	}
+	tx.Proposal = &peer.SignedProposal{ProposalBytes: proposal}
+	return tx, nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+)
+
+// changeSetHeader is the first line of a change set: the blocks it covers
+// and their header hashes. PreviousHash is the hash of the block before
+// FromBlock, which the change set covering that block lists last, so a chain
+// of nightly change sets can be checked for gaps and tampering.
+type changeSetHeader struct {
+	FromBlock    uint64   `json:"fromBlock"`
+	ToBlock      uint64   `json:"toBlock"`
+	PreviousHash string   `json:"previousHash"`
+	BlockHashes  []string `json:"blockHashes"` // from FromBlock to ToBlock
+}
+
+// exportChanges writes the namespace's keys written by the valid
+// transactions of blocks from through to, each with its value after block
+// to, as a change set. A negative to stands for the last block in blocksDir.
+// The change set is a header line followed by one snapshot line per key,
+// a deleted key marked as deleted. Applying change sets in block order to the
+// snapshot of the block before the first gives the snapshot after the last.
+func exportChanges(blocksDir, namespace, path string, from, to int64) error {
+	blocks, err := readBlocks(blocksDir)
+	if err != nil {
+		return err
+	}
+	first, last := int64(blocks[0].Header.Number), int64(blocks[len(blocks)-1].Header.Number)
+	if to < 0 {
+		to = last
+	}
+	if from > to || from < first || to > last {
+		return fmt.Errorf("blocks %d-%d are not all in %s, which holds blocks %d-%d", from, to, blocksDir, first, last)
+	}
+	blocks = blocks[from-first : to-first+1]
+
+	header := changeSetHeader{FromBlock: uint64(from), ToBlock: uint64(to), PreviousHash: hex.EncodeToString(blocks[0].Header.PreviousHash)}
+	changes := map[string][]byte{} // the last write of each key, nil for a delete
+	for i, block := range blocks {
+		err = verifyBlock(block)
+		if err != nil {
+			return err
+		}
+		if i > 0 && !bytes.Equal(block.Header.PreviousHash, blockHash(blocks[i-1].Header)) {
+			return fmt.Errorf("block %d does not follow block %d: its previous hash does not match", block.Header.Number, block.Header.Number-1)
+		}
+		header.BlockHashes = append(header.BlockHashes, hex.EncodeToString(blockHash(block.Header)))
+
+		txs, err := transactions(block, namespace)
+		if err != nil {
+			return err
+		}
+		for _, tx := range txs {
+			if !tx.Valid {
+				continue
+			}
+			for _, write := range tx.Writes {
+				changes[write.Key] = nil
+				if !write.IsDelete && len(write.Value) > 0 {
+					changes[write.Key] = write.Value
+				}
+			}
+		}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = writeChanges(f, &header, changes)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write change set %s: %v", path, err)
+	}
+	fmt.Printf("wrote %d changed keys from blocks %d-%d to %s\n", len(changes), from, to, path)
+	return nil
+}
+
+func writeChanges(out io.Writer, header *changeSetHeader, changes map[string][]byte) error {
+	b := bufio.NewWriter(out)
+	line, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	b.Write(line)
+	b.WriteByte('\n')
+
+	keys := make([]string, 0, len(changes))
+	for key := range changes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		line, err := json.Marshal(newSnapshotEntry(key, changes[key]))
+		if err != nil {
+			return err
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	return b.Flush()
+}
+
+// blockHash returns the hash of a block header, which the next block
+// records as its previous hash
+func blockHash(header *common.BlockHeader) []byte {
+	encoded, err := asn1.Marshal(struct {
+		Number       *big.Int
+		PreviousHash []byte
+		DataHash     []byte
+	}{new(big.Int).SetUint64(header.Number), header.PreviousHash, header.DataHash})
+	if err != nil {
+		panic(err) // a struct of integers and byte strings always encodes
+	}
+	hash := sha256.Sum256(encoded)
+	return hash[:]
+}
+
+// verifyBlock checks a block's transactions against the data hash in its header
+func verifyBlock(block *common.Block) error {
+	hash := sha256.Sum256(bytes.Join(block.GetData().GetData(), nil))
+	if !bytes.Equal(hash[:], block.Header.DataHash) {
+		return fmt.Errorf("block %d does not match its data hash", block.Header.Number)
+	}
+	return nil
+}
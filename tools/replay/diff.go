@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+)
+
+// differences lists how a replayed transaction's outcome differs from the
+// ledger's record of it
+func differences(tx *transaction, replayed *outcome) []string {
+	if replayed.Response.Status >= errorThreshold {
+		return []string{fmt.Sprintf("replay failed with status %d: %s", replayed.Response.Status, replayed.Response.Message)}
+	}
+
+	var diffs []string
+	if status := tx.Response.GetStatus(); status != replayed.Response.Status {
+		diffs = append(diffs, fmt.Sprintf("response status %d on the ledger, %d replayed", status, replayed.Response.Status))
+	}
+	diffs = append(diffs, valueDiffs("response", tx.Response.GetPayload(), replayed.Response.Payload)...)
+
+	if name := tx.Event.GetEventName(); name != replayed.Event.GetEventName() {
+		diffs = append(diffs, fmt.Sprintf("event %q on the ledger, %q replayed", name, replayed.Event.GetEventName()))
+	} else {
+		diffs = append(diffs, valueDiffs("event "+name, tx.Event.GetPayload(), replayed.Event.GetPayload())...)
+	}
+
+	return append(diffs, writeDiffs(tx.Writes, replayed.Writes)...)
+}
+
+func writeDiffs(ledger, replayed []*kvrwset.KVWrite) []string {
+	byKey := func(writes []*kvrwset.KVWrite) map[string]*kvrwset.KVWrite {
+		m := map[string]*kvrwset.KVWrite{}
+		for _, write := range writes {
+			m[write.Key] = write
+		}
+		return m
+	}
+	recorded, produced := byKey(ledger), byKey(replayed)
+	keys := []string{}
+	for key := range recorded {
+		keys = append(keys, key)
+	}
+	for key := range produced {
+		if recorded[key] == nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var diffs []string
+	for _, key := range keys {
+		a, b := recorded[key], produced[key]
+		name := strconv.Quote(key)
+		switch {
+		case b == nil:
+			diffs = append(diffs, name+" written on the ledger only")
+		case a == nil:
+			diffs = append(diffs, name+" written by the replay only")
+		case a.IsDelete && !b.IsDelete:
+			diffs = append(diffs, name+" deleted on the ledger, written by the replay")
+		case !a.IsDelete && b.IsDelete:
+			diffs = append(diffs, name+" written on the ledger, deleted by the replay")
+		default:
+			diffs = append(diffs, valueDiffs(name, a.Value, b.Value)...)
+		}
+	}
+	return diffs
+}
+
+// valueDiffs describes how two values differ: field by field when both are
+// JSON, and as a whole otherwise
+func valueDiffs(name string, ledger, replayed []byte) []string {
+	if bytes.Equal(ledger, replayed) {
+		return nil
+	}
+	var a, b interface{}
+	if json.Unmarshal(ledger, &a) != nil || json.Unmarshal(replayed, &b) != nil {
+		return []string{fmt.Sprintf("%s: %q on the ledger, %q replayed", name, clip(ledger), clip(replayed))}
+	}
+	var diffs []string
+	jsonDiffs(name, "", a, b, &diffs)
+	if len(diffs) == 0 {
+		// the same JSON encoded differently still splits the write set
+		diffs = append(diffs, name+" encoded differently")
+	}
+	return diffs
+}
+
+func jsonDiffs(name, path string, a, b interface{}, diffs *[]string) {
+	aObject, aok := a.(map[string]interface{})
+	bObject, bok := b.(map[string]interface{})
+	if aok && bok {
+		fields := []string{}
+		for field := range aObject {
+			fields = append(fields, field)
+		}
+		for field := range bObject {
+			if _, ok := aObject[field]; !ok {
+				fields = append(fields, field)
+			}
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			fieldPath := field
+			if path != "" {
+				fieldPath = path + "." + field
+			}
+			aValue, aHas := aObject[field]
+			bValue, bHas := bObject[field]
+			switch {
+			case !bHas:
+				*diffs = append(*diffs, fmt.Sprintf("%s %s on the ledger only", name, fieldPath))
+			case !aHas:
+				*diffs = append(*diffs, fmt.Sprintf("%s %s replayed only", name, fieldPath))
+			default:
+				jsonDiffs(name, fieldPath, aValue, bValue, diffs)
+			}
+		}
+		return
+	}
+
+	aArray, aok := a.([]interface{})
+	bArray, bok := b.([]interface{})
+	if aok && bok && len(aArray) == len(bArray) {
+		for i := range aArray {
+			jsonDiffs(name, fmt.Sprintf("%s[%d]", path, i), aArray[i], bArray[i], diffs)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		if path != "" {
+			name += " " + path
+		}
+		aJSON, _ := json.Marshal(a)
+		bJSON, _ := json.Marshal(b)
+		*diffs = append(*diffs, fmt.Sprintf("%s: %s on the ledger, %s replayed", name, clip(aJSON), clip(bJSON)))
+	}
+}
+
+// clip shortens a value for display
+func clip(value []byte) string {
+	const limit = 80
+	if len(value) > limit {
+		return string(value[:limit]) + "..."
+	}
+	return string(value)
+}
@@ -0,0 +1,192 @@
+// Command replay re-executes the transactions on a channel's ledger against
+// the current chaincode and reports each one whose write set, response or
+// event comes out differently from the ledger's record. A difference means
+// the chaincode is not deterministic, for instance because it reads the
+// clock, so endorsing peers can disagree and split state; or that a change
+// to the chaincode would treat the recorded transactions differently.
+//
+// The chaincode is built from -source and runs as its own process,
+// connected to a simulated peer rather than a real one. The simulator
+// rebuilds the world state from the recorded writes of the valid
+// transactions in block order, so each transaction runs against the state it
+// was validated on. Invalid transactions are skipped. Rich query results are
+// not validated by Fabric, so a transaction could have seen other results
+// when it was endorsed; transient data never reaches the ledger and private
+// data is not supported.
+//
+// Fetch the channel's blocks, from the genesis block on, and replay them:
+//
+//	height=$(peer channel getinfo -c ekycChannel | sed 's/.*"height":\([0-9]*\).*/\1/')
+//	for n in $(seq 0 $((height - 1))); do peer channel fetch $n blocks/$n.block -c ekycChannel; done
+//	go run . -blocks blocks
+//
+// The exit status is 1 when any transaction diverged. With -dump the rebuilt
+// world state is also written out as a snapshot, one JSON line per key, which
+// tools/anonymize turns into test fixtures.
+//
+// With -changes no chaincode is run: the keys the valid transactions of
+// blocks -from through -to wrote are written out as a change set, the
+// snapshot lines of their values after block -to headed by the hashes of the
+// blocks covered, so nightly archives need only the blocks committed since
+// the last one:
+//
+//	go run . -blocks blocks -changes changes-1200-1350.ndjson -from 1200 -to 1350
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+)
+
+func main() {
+	var (
+		blocksDir = flag.String("blocks", "blocks", "directory of blocks written by peer channel fetch")
+		sourceDir = flag.String("source", "../../chaincode", "chaincode source directory to build and replay")
+		namespace = flag.String("chaincode", "ekyc-chaincode", "chaincode name")
+		dumpPath  = flag.String("dump", "", "file to write the world state snapshot to after the last block")
+		changes   = flag.String("changes", "", "file to write the change set of blocks -from to -to to, instead of replaying")
+		from      = flag.Int64("from", 0, "first block of the change set")
+		to        = flag.Int64("to", -1, "last block of the change set; -1 for the last block fetched")
+	)
+	flag.Parse()
+
+	if *changes != "" {
+		err := exportChanges(*blocksDir, *namespace, *changes, *from, *to)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	diverged, err := replay(*blocksDir, *sourceDir, *namespace, *dumpPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if diverged {
+		os.Exit(1)
+	}
+}
+
+// functionCounts is how many of one function's transactions were replayed
+// and how many of them diverged
+type functionCounts struct {
+	replayed, diverged int
+}
+
+func replay(blocksDir, sourceDir, namespace, dumpPath string) (bool, error) {
+	blocks, err := readBlocks(blocksDir)
+	if err != nil {
+		return false, err
+	}
+	binary, err := build(sourceDir)
+	if err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(filepath.Dir(binary))
+
+	state := newWorldState()
+	sim, err := startSimulator(binary, namespace, state)
+	if err != nil {
+		return false, err
+	}
+	defer sim.Close()
+
+	counts := map[string]*functionCounts{}
+	replayed, diverged, invalid := 0, 0, 0
+	for _, block := range blocks {
+		txs, err := transactions(block, namespace)
+		if err != nil {
+			return false, err
+		}
+		for _, tx := range txs {
+			if !tx.Valid {
+				invalid++
+				continue
+			}
+			result, err := sim.execute(tx)
+			if err != nil {
+				return false, fmt.Errorf("block %d transaction %d: %v", tx.Block, tx.Index, err)
+			}
+			state.apply(tx.Writes)
+
+			function := counts[tx.Function()]
+			if function == nil {
+				function = &functionCounts{}
+				counts[tx.Function()] = function
+			}
+			replayed++
+			function.replayed++
+			diffs := differences(tx, result)
+			if len(diffs) == 0 {
+				continue
+			}
+			diverged++
+			function.diverged++
+			fmt.Printf("block %d tx %d %s %s\n", tx.Block, tx.Index, tx.Function(), tx.TxID)
+			for _, diff := range diffs {
+				fmt.Printf("\t%s\n", diff)
+			}
+		}
+	}
+
+	first, last := blocks[0].Header.Number, blocks[len(blocks)-1].Header.Number
+	fmt.Printf("\nreplayed %d transactions from blocks %d-%d: %d diverged, %d invalid skipped\n", replayed, first, last, diverged, invalid)
+	if replayed > 0 {
+		functions := make([]string, 0, len(counts))
+		for function := range counts {
+			functions = append(functions, function)
+		}
+		sort.Strings(functions)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "FUNCTION\tREPLAYED\tDIVERGED")
+		for _, function := range functions {
+			fmt.Fprintf(w, "%s\t%d\t%d\n", function, counts[function].replayed, counts[function].diverged)
+		}
+		w.Flush()
+	}
+	if dumpPath != "" {
+		err = dump(state, dumpPath)
+		if err != nil {
+			return false, err
+		}
+	}
+	return diverged > 0, nil
+}
+
+func dump(state *worldState, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = state.dump(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write snapshot %s: %v", path, err)
+	}
+	return nil
+}
+
+// build compiles the chaincode into a temporary directory
+func build(sourceDir string) (string, error) {
+	dir, err := os.MkdirTemp("", "replay")
+	if err != nil {
+		return "", err
+	}
+	binary := filepath.Join(dir, "chaincode")
+	cmd := exec.Command("go", "build", "-o", binary, ".")
+	cmd.Dir = sourceDir
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	if err := cmd.Run(); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to build chaincode in %s: %v", sourceDir, err)
+	}
+	return binary, nil
+}
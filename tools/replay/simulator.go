@@ -0,0 +1,330 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"google.golang.org/grpc"
+)
+
+// Response statuses as the shim defines them
+const (
+	statusError    = 500
+	errorThreshold = 400
+)
+
+// simulator plays the peer to a chaincode process. The chaincode connects to
+// it as it would to its peer, and the simulator executes transactions on the
+// world state and records their writes as the peer's transaction simulator
+// does.
+type simulator struct {
+	namespace  string
+	state      *worldState
+	server     *grpc.Server
+	registered chan peer.ChaincodeSupport_RegisterServer
+	stream     peer.ChaincodeSupport_RegisterServer
+	done       chan struct{}
+	process    *exec.Cmd
+	exited     chan error
+}
+
+// startSimulator runs the chaincode binary and waits for it to register
+func startSimulator(binary string, namespace string, state *worldState) (*simulator, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &simulator{
+		namespace:  namespace,
+		state:      state,
+		server:     grpc.NewServer(),
+		registered: make(chan peer.ChaincodeSupport_RegisterServer, 1),
+		done:       make(chan struct{}),
+		exited:     make(chan error, 1),
+	}
+	peer.RegisterChaincodeSupportServer(s.server, s)
+	go s.server.Serve(listener)
+
+	s.process = exec.Command(binary, "-peer.address", listener.Addr().String())
+	s.process.Env = append(os.Environ(), "CORE_CHAINCODE_ID_NAME="+namespace+":replay", "CORE_PEER_TLS_ENABLED=false")
+	s.process.Stdout, s.process.Stderr = os.Stderr, os.Stderr
+	if err := s.process.Start(); err != nil {
+		s.server.Stop()
+		return nil, fmt.Errorf("failed to start chaincode: %v", err)
+	}
+	go func() { s.exited <- s.process.Wait() }()
+
+	select {
+	case s.stream = <-s.registered:
+		return s, nil
+	case err := <-s.exited:
+		s.server.Stop()
+		return nil, fmt.Errorf("chaincode exited before registering: %v", err)
+	case <-time.After(30 * time.Second):
+		s.Close()
+		return nil, fmt.Errorf("chaincode did not register within 30s")
+	}
+}
+
+// Register implements peer.ChaincodeSupportServer for the chaincode process
+// the simulator started
+func (s *simulator) Register(stream peer.ChaincodeSupport_RegisterServer) error {
+	msg, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if msg.Type != peer.ChaincodeMessage_REGISTER {
+		return fmt.Errorf("expected %s, received %s", peer.ChaincodeMessage_REGISTER, msg.Type)
+	}
+	for _, reply := range []peer.ChaincodeMessage_Type{peer.ChaincodeMessage_REGISTERED, peer.ChaincodeMessage_READY} {
+		if err := stream.Send(&peer.ChaincodeMessage{Type: reply}); err != nil {
+			return err
+		}
+	}
+	s.registered <- stream
+	<-s.done
+	return nil
+}
+
+// Close stops the chaincode
+func (s *simulator) Close() {
+	close(s.done)
+	s.server.Stop()
+	s.process.Process.Kill()
+	<-s.exited
+}
+
+// outcome is what executing a transaction produced
+type outcome struct {
+	Response *peer.Response
+	Event    *peer.ChaincodeEvent
+	Writes   []*kvrwset.KVWrite
+}
+
+// execute runs a transaction on the current state, leaving the state as it
+// was
+func (s *simulator) execute(tx *transaction) (*outcome, error) {
+	input, err := proto.Marshal(tx.Input)
+	if err != nil {
+		return nil, err
+	}
+	msgType := peer.ChaincodeMessage_TRANSACTION
+	if tx.Input.IsInit {
+		msgType = peer.ChaincodeMessage_INIT
+	}
+	err = s.stream.Send(&peer.ChaincodeMessage{
+		Type:      msgType,
+		Payload:   input,
+		Txid:      tx.TxID,
+		ChannelId: tx.Channel,
+		Proposal:  tx.Proposal,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send transaction to chaincode: %v", err)
+	}
+
+	sim := &txSimulator{namespace: s.namespace, state: s.state, writes: map[string]*kvrwset.KVWrite{}}
+	for {
+		msg, err := s.stream.Recv()
+		if err != nil {
+			return nil, fmt.Errorf("chaincode stream failed: %v", err)
+		}
+		switch msg.Type {
+		case peer.ChaincodeMessage_COMPLETED:
+			response := &peer.Response{}
+			if err := proto.Unmarshal(msg.Payload, response); err != nil {
+				return nil, err
+			}
+			return sim.outcome(response, msg.ChaincodeEvent), nil
+		case peer.ChaincodeMessage_ERROR:
+			return sim.outcome(&peer.Response{Status: statusError, Message: string(msg.Payload)}, nil), nil
+		case peer.ChaincodeMessage_KEEPALIVE:
+			continue
+		}
+
+		reply := sim.handle(msg)
+		reply.Txid, reply.ChannelId = msg.Txid, msg.ChannelId
+		if err := s.stream.Send(reply); err != nil {
+			return nil, fmt.Errorf("failed to reply to chaincode: %v", err)
+		}
+	}
+}
+
+// txSimulator serves one transaction's calls on the state with a peer's
+// semantics: reads see the committed state rather than the transaction's
+// own writes, and a transaction may not both write and run a paginated
+// query
+type txSimulator struct {
+	namespace string
+	state     *worldState
+	writes    map[string]*kvrwset.KVWrite
+	paginated bool
+	queries   int
+}
+
+func (t *txSimulator) outcome(response *peer.Response, event *peer.ChaincodeEvent) *outcome {
+	result := &outcome{Response: response, Event: event}
+	for _, write := range t.writes {
+		result.Writes = append(result.Writes, write)
+	}
+	sort.Slice(result.Writes, func(i, j int) bool { return result.Writes[i].Key < result.Writes[j].Key })
+	return result
+}
+
+func (t *txSimulator) handle(msg *peer.ChaincodeMessage) *peer.ChaincodeMessage {
+	payload, err := t.call(msg)
+	if err != nil {
+		return &peer.ChaincodeMessage{Type: peer.ChaincodeMessage_ERROR, Payload: []byte(err.Error())}
+	}
+	return &peer.ChaincodeMessage{Type: peer.ChaincodeMessage_RESPONSE, Payload: payload}
+}
+
+func (t *txSimulator) call(msg *peer.ChaincodeMessage) ([]byte, error) {
+	switch msg.Type {
+	case peer.ChaincodeMessage_GET_STATE:
+		request := &peer.GetState{}
+		if err := decode(msg.Payload, request, request.GetCollection); err != nil {
+			return nil, err
+		}
+		return t.state.get(request.Key), nil
+
+	case peer.ChaincodeMessage_PUT_STATE:
+		request := &peer.PutState{}
+		if err := decode(msg.Payload, request, request.GetCollection); err != nil {
+			return nil, err
+		}
+		return nil, t.write(request.Key, request.Value)
+
+	case peer.ChaincodeMessage_DEL_STATE:
+		request := &peer.DelState{}
+		if err := decode(msg.Payload, request, request.GetCollection); err != nil {
+			return nil, err
+		}
+		return nil, t.write(request.Key, nil)
+
+	case peer.ChaincodeMessage_GET_STATE_BY_RANGE:
+		request := &peer.GetStateByRange{}
+		if err := decode(msg.Payload, request, request.GetCollection); err != nil {
+			return nil, err
+		}
+		page, err := t.page(request.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		start := request.StartKey
+		if page != nil && page.Bookmark != "" {
+			start = page.Bookmark
+		}
+		keys := t.state.keyRange(start, request.EndKey)
+		// a range query's bookmark is the key the next page starts at
+		bookmark := ""
+		if page != nil && page.PageSize > 0 && len(keys) > int(page.PageSize) {
+			bookmark = keys[page.PageSize]
+			keys = keys[:page.PageSize]
+		}
+		return t.results(keys, page, bookmark)
+
+	case peer.ChaincodeMessage_GET_QUERY_RESULT:
+		request := &peer.GetQueryResult{}
+		if err := decode(msg.Payload, request, request.GetCollection); err != nil {
+			return nil, err
+		}
+		page, err := t.page(request.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		keys, err := t.state.query(request.Query)
+		if err != nil {
+			return nil, err
+		}
+		// CouchDB's bookmarks are opaque; the replay's is the last key
+		// returned
+		bookmark := ""
+		if page != nil {
+			from := sort.Search(len(keys), func(i int) bool { return keys[i] > page.Bookmark })
+			keys = keys[from:]
+			if page.PageSize > 0 && len(keys) > int(page.PageSize) {
+				keys = keys[:page.PageSize]
+			}
+			bookmark = page.Bookmark
+			if len(keys) > 0 {
+				bookmark = keys[len(keys)-1]
+			}
+		}
+		return t.results(keys, page, bookmark)
+
+	case peer.ChaincodeMessage_QUERY_STATE_CLOSE:
+		request := &peer.QueryStateClose{}
+		if err := proto.Unmarshal(msg.Payload, request); err != nil {
+			return nil, err
+		}
+		return proto.Marshal(&peer.QueryResponse{Id: request.Id})
+	}
+	return nil, fmt.Errorf("%s is not supported by the replay", msg.Type)
+}
+
+// decode unmarshals a request, rejecting requests for private data, which
+// the ledger's blocks only hold hashes of
+func decode(payload []byte, request proto.Message, collection func() string) error {
+	if err := proto.Unmarshal(payload, request); err != nil {
+		return err
+	}
+	if collection() != "" {
+		return fmt.Errorf("private data collection %s is not supported by the replay", collection())
+	}
+	return nil
+}
+
+func (t *txSimulator) write(key string, value []byte) error {
+	if t.paginated {
+		return fmt.Errorf("cannot write %s after a paginated query; paginated queries are only allowed in read-only transactions", key)
+	}
+	t.writes[key] = &kvrwset.KVWrite{Key: key, IsDelete: len(value) == 0, Value: value}
+	return nil
+}
+
+// page decodes a query's pagination, returning nil for an unpaginated query
+func (t *txSimulator) page(metadata []byte) (*peer.QueryMetadata, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	if len(t.writes) > 0 {
+		return nil, fmt.Errorf("cannot run a paginated query after writing; paginated queries are only allowed in read-only transactions")
+	}
+	page := &peer.QueryMetadata{}
+	if err := proto.Unmarshal(metadata, page); err != nil {
+		return nil, err
+	}
+	t.paginated = true
+	return page, nil
+}
+
+// results returns all of a query's results in one response
+func (t *txSimulator) results(keys []string, page *peer.QueryMetadata, bookmark string) ([]byte, error) {
+	t.queries++
+	response := &peer.QueryResponse{Id: strconv.Itoa(t.queries)}
+	for _, key := range keys {
+		kv, err := proto.Marshal(&queryresult.KV{Namespace: t.namespace, Key: key, Value: t.state.get(key)})
+		if err != nil {
+			return nil, err
+		}
+		response.Results = append(response.Results, &peer.QueryResultBytes{ResultBytes: kv})
+	}
+	if page != nil {
+		metadata, err := proto.Marshal(&peer.QueryResponseMetadata{FetchedRecordsCount: int32(len(keys)), Bookmark: bookmark})
+		if err != nil {
+			return nil, err
+		}
+		response.Metadata = metadata
+	}
+	return proto.Marshal(response)
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+)
+
+// worldState is the chaincode's namespace of the world state, rebuilt from
+// the write sets of the valid transactions replayed so far
+type worldState struct {
+	values map[string][]byte
+	keys   []string                          // sorted, for range queries
+	docs   map[string]map[string]interface{} // values that are JSON objects, for rich queries
+}
+
+func newWorldState() *worldState {
+	return &worldState{values: map[string][]byte{}, docs: map[string]map[string]interface{}{}}
+}
+
+func (w *worldState) get(key string) []byte {
+	return w.values[key]
+}
+
+// apply commits a transaction's writes
+func (w *worldState) apply(writes []*kvrwset.KVWrite) {
+	for _, write := range writes {
+		i := sort.SearchStrings(w.keys, write.Key)
+		exists := i < len(w.keys) && w.keys[i] == write.Key
+		delete(w.docs, write.Key)
+
+		if write.IsDelete || len(write.Value) == 0 {
+			if exists {
+				w.keys = append(w.keys[:i], w.keys[i+1:]...)
+			}
+			delete(w.values, write.Key)
+			continue
+		}
+		if !exists {
+			w.keys = append(w.keys, "")
+			copy(w.keys[i+1:], w.keys[i:])
+			w.keys[i] = write.Key
+		}
+		w.values[write.Key] = write.Value
+		var doc map[string]interface{}
+		if json.Unmarshal(write.Value, &doc) == nil {
+			w.docs[write.Key] = doc
+		}
+	}
+}
+
+// snapshotEntry is one line of a snapshot. A value the chaincode wrote as
+// JSON is given as JSON, any other value as base64 bytes; change sets mark a
+// deleted key as deleted.
+type snapshotEntry struct {
+	Key     string          `json:"key"`
+	Value   json.RawMessage `json:"value,omitempty"`
+	Bytes   []byte          `json:"bytes,omitempty"`
+	Deleted bool            `json:"deleted,omitempty"`
+}
+
+// newSnapshotEntry returns the entry of a key, a nil value standing for a
+// deleted key
+func newSnapshotEntry(key string, value []byte) *snapshotEntry {
+	e := &snapshotEntry{Key: key}
+	var compact bytes.Buffer
+	switch {
+	case value == nil:
+		e.Deleted = true
+	case json.Compact(&compact, value) == nil && bytes.Equal(compact.Bytes(), value):
+		e.Value = value
+	default:
+		e.Bytes = value
+	}
+	return e
+}
+
+// dump writes the state as a snapshot, one key per line in key order
+func (w *worldState) dump(out io.Writer) error {
+	b := bufio.NewWriter(out)
+	for _, key := range w.keys {
+		line, err := json.Marshal(newSnapshotEntry(key, w.values[key]))
+		if err != nil {
+			return err
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	return b.Flush()
+}
+
+// keyRange returns the keys from start up to but excluding end, an empty end
+// leaving the range open
+func (w *worldState) keyRange(start, end string) []string {
+	from := sort.SearchStrings(w.keys, start)
+	to := len(w.keys)
+	if end != "" {
+		to = sort.SearchStrings(w.keys, end)
+	}
+	if to < from {
+		return nil
+	}
+	return w.keys[from:to]
+}
+
+// query returns the keys of the JSON documents a CouchDB query selects, in
+// key order. It supports the selector operators the contract uses and fails
+// on any other part of the query language, so a replay never silently
+// selects different documents than CouchDB would.
+func (w *worldState) query(query string) ([]string, error) {
+	var parsed map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(query), &parsed); err != nil {
+		return nil, fmt.Errorf("invalid query: %v", err)
+	}
+	var selector map[string]interface{}
+	for field, value := range parsed {
+		switch field {
+		case "selector":
+			if err := json.Unmarshal(value, &selector); err != nil {
+				return nil, fmt.Errorf("invalid selector: %v", err)
+			}
+		case "use_index":
+		default:
+			return nil, fmt.Errorf("query field %s is not supported by the replay", field)
+		}
+	}
+	if selector == nil {
+		return nil, fmt.Errorf("query has no selector")
+	}
+
+	var keys []string
+	for key, doc := range w.docs {
+		match, err := matches(doc, selector)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func matches(doc map[string]interface{}, selector map[string]interface{}) (bool, error) {
+	for field, condition := range selector {
+		if strings.HasPrefix(field, "$") {
+			return false, fmt.Errorf("selector operator %s is not supported by the replay", field)
+		}
+		value, present := lookup(doc, field)
+
+		operators, ok := condition.(map[string]interface{})
+		if !ok || !isOperators(operators) {
+			if !present || !reflect.DeepEqual(value, condition) {
+				return false, nil
+			}
+			continue
+		}
+		for operator, operand := range operators {
+			match, err := apply(operator, value, present, operand)
+			if err != nil || !match {
+				return false, err
+			}
+		}
+	}
+	return true, nil
+}
+
+// lookup resolves a field name, which may be a dotted path into nested
+// objects
+func lookup(doc map[string]interface{}, field string) (interface{}, bool) {
+	var value interface{} = doc
+	for _, part := range strings.Split(field, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value, ok = object[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func isOperators(condition map[string]interface{}) bool {
+	for key := range condition {
+		if !strings.HasPrefix(key, "$") {
+			return false
+		}
+	}
+	return len(condition) > 0
+}
+
+func apply(operator string, value interface{}, present bool, operand interface{}) (bool, error) {
+	switch operator {
+	case "$exists":
+		exists, ok := operand.(bool)
+		if !ok {
+			return false, fmt.Errorf("$exists takes a boolean")
+		}
+		return present == exists, nil
+	case "$eq":
+		return present && reflect.DeepEqual(value, operand), nil
+	case "$ne":
+		return present && !reflect.DeepEqual(value, operand), nil
+	case "$in", "$nin":
+		options, ok := operand.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s takes an array", operator)
+		}
+		found := false
+		for _, option := range options {
+			if reflect.DeepEqual(value, option) {
+				found = true
+			}
+		}
+		return present && found == (operator == "$in"), nil
+	case "$gt", "$gte", "$lt", "$lte":
+		if !present {
+			return false, nil
+		}
+		order, comparable := compare(value, operand)
+		if !comparable {
+			return false, nil
+		}
+		switch operator {
+		case "$gt":
+			return order > 0, nil
+		case "$gte":
+			return order >= 0, nil
+		case "$lt":
+			return order < 0, nil
+		}
+		return order <= 0, nil
+	}
+	return false, fmt.Errorf("selector operator %s is not supported by the replay", operator)
+}
+
+// compare orders two strings or two numbers. Strings are compared bytewise,
+// which agrees with CouchDB's Unicode collation for the ASCII timestamps and
+// codes the contract compares. Values of other or mixed types are reported as
+// not comparable, rather than following CouchDB's collation across types.
+func compare(a, b interface{}) (int, bool) {
+	switch a := a.(type) {
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b), true
+		}
+	case float64:
+		if b, ok := b.(float64); ok {
+			switch {
+			case a < b:
+				return -1, true
+			case a > b:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	return 0, false
+}
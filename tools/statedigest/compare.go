@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+)
+
+// compareReports reads the reports at paths, writes a table of them to out
+// and reports whether they all hold the same digest. The first report is
+// the reference the others are marked against.
+func compareReports(out io.Writer, paths []string) (bool, error) {
+	reports := make([]*report, len(paths))
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return false, err
+		}
+		reports[i] = &report{}
+		err = json.Unmarshal(data, reports[i])
+		if err != nil {
+			return false, fmt.Errorf("%s: %v", path, err)
+		}
+		if reports[i].Digest == "" {
+			return false, fmt.Errorf("%s holds no state digest", path)
+		}
+	}
+
+	match := true
+	table := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(table, "REPORT\tENDPOINT\tMSP\tKEYS\tCOMPUTED AT\tDIGEST\tVERDICT")
+	for i, r := range reports {
+		verdict := "matches"
+		switch {
+		case i == 0:
+			verdict = "reference"
+		case r.Digest != reports[0].Digest:
+			verdict = "DIFFERS"
+			match = false
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n", paths[i], r.Endpoint, r.MSPID, r.KeyCount, r.ComputedAt, r.Digest, verdict)
+	}
+	table.Flush()
+	if match {
+		fmt.Fprintf(out, "all %d reports hold the same state digest\n", len(reports))
+	} else {
+		fmt.Fprintf(out, "state digests differ; compare again at a common height before treating a peer as diverged\n")
+	}
+	return match, nil
+}
@@ -0,0 +1,134 @@
+// Command statedigest proves that peers hold the same world state, for
+// instance after one was restored from a backup. It pages through the
+// chaincode's ComputeStateDigest on one peer and writes the digest of the
+// whole state, with the number of keys it covers, as a JSON report:
+//
+//	go run . -endpoint peer0.org1:7051 -tls-ca tlsca.pem -cert cert.pem -key key.pem -out org1.json
+//
+// The identity needs the kyc.admin or kyc.regulator attribute. Each
+// organisation collects a report from its own peers, and the reports are
+// compared with -compare, which exits with status 1 unless every report
+// holds the same digest:
+//
+//	go run . -compare org1.json org2.json restored.json
+//
+// The gateway evaluates on the peer it is connected to unless another peer
+// of its organisation is at a greater height. Digests only agree between
+// peers at the same height, so collect the reports while no transactions
+// are committing, and collect them again before taking a mismatch for
+// diverged state.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"ekyc-gateway/fabric"
+)
+
+// report is one peer's state digest
+type report struct {
+	Endpoint   string `json:"endpoint"`
+	MSPID      string `json:"mspId"`
+	Digest     string `json:"digest"`
+	KeyCount   int64  `json:"keyCount"`
+	Pages      int    `json:"pages"`
+	ComputedAt string `json:"computedAt"`
+}
+
+func main() {
+	var (
+		config   fabric.Config
+		compare  = flag.Bool("compare", false, "compare the reports named as arguments instead of collecting one")
+		outPath  = flag.String("out", "-", "file to write the report to; - writes to standard output")
+		pageSize = flag.Int("page-size", 1000, "keys digested per ComputeStateDigest call")
+		mspID    = flag.String("msp", "Org1MSP", "MSP ID of the identity")
+		certPath = flag.String("cert", "", "PEM enrolment certificate of the identity")
+		keyPath  = flag.String("key", "", "PEM private key of the identity")
+	)
+	flag.StringVar(&config.Endpoint, "endpoint", "localhost:7051", "gateway peer address")
+	flag.StringVar(&config.TLSCACertPath, "tls-ca", "", "peer TLS CA certificate; empty connects without TLS")
+	flag.StringVar(&config.ServerName, "server-name", "", "TLS server name override")
+	flag.StringVar(&config.Channel, "channel", "ekycChannel", "channel name")
+	flag.StringVar(&config.Chaincode, "chaincode", "ekyc-chaincode", "chaincode name")
+	flag.Parse()
+
+	if *compare {
+		if flag.NArg() < 2 {
+			log.Fatal("-compare needs at least two reports")
+		}
+		match, err := compareReports(os.Stdout, flag.Args())
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !match {
+			os.Exit(1)
+		}
+		return
+	}
+
+	identity, err := fabric.LoadIdentity(*mspID, *certPath, *keyPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	client, err := fabric.Dial(config, identity)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	r, err := collect(context.Background(), client, int32(*pageSize))
+	if err != nil {
+		log.Fatal(err)
+	}
+	r.Endpoint, r.MSPID = config.Endpoint, *mspID
+
+	reportJSON, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	reportJSON = append(reportJSON, '\n')
+	if *outPath == "-" {
+		os.Stdout.Write(reportJSON)
+		return
+	}
+	err = os.WriteFile(*outPath, reportJSON, 0o644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// collect pages through ComputeStateDigest to the digest of the whole state
+func collect(ctx context.Context, client *fabric.Client, pageSize int32) (*report, error) {
+	var page struct {
+		Digest   string `json:"digest"`
+		KeyCount int64  `json:"keyCount"`
+		Bookmark string `json:"bookmark"`
+	}
+	r := &report{}
+	bookmark := ""
+	for {
+		result, err := client.Evaluate(ctx, "ComputeStateDigest", strconv.Itoa(int(pageSize)), bookmark)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(result, &page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse state digest page %d: %v", r.Pages+1, err)
+		}
+		r.Pages++
+		if page.Bookmark == "" {
+			break
+		}
+		bookmark = page.Bookmark
+	}
+	r.Digest, r.KeyCount = page.Digest, page.KeyCount
+	r.ComputedAt = time.Now().UTC().Format(time.RFC3339)
+	return r, nil
+}
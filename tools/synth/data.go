@@ -0,0 +1,94 @@
+package main
+
+// Word lists the generator draws from. Names are common across India's
+// regions; cities are listed with their state and the first three digits of
+// their PIN codes, so every generated address passes the chaincode's pincode
+// and state checks.
+
+var givenNames = []string{
+	"Aarav", "Aditi", "Aditya", "Akash", "Amit", "Ananya", "Anil", "Anjali", "Arjun", "Asha",
+	"Deepa", "Deepak", "Divya", "Farhan", "Fatima", "Gaurav", "Geeta", "Harish", "Ishaan", "Jaya",
+	"Karan", "Kavya", "Kiran", "Lakshmi", "Mahesh", "Manish", "Meera", "Mohan", "Nandini", "Neha",
+	"Nikhil", "Pooja", "Pranav", "Priya", "Rahul", "Rajesh", "Ramesh", "Ritu", "Rohan", "Sakshi",
+	"Sanjay", "Shreya", "Simran", "Sneha", "Suresh", "Tanvi", "Usha", "Varun", "Vikram", "Zoya",
+}
+
+var surnames = []string{
+	"Agarwal", "Banerjee", "Bhat", "Chatterjee", "Chopra", "Das", "Desai", "Dutta", "Ghosh", "Gupta",
+	"Hegde", "Iyer", "Jain", "Joshi", "Kapoor", "Khan", "Kulkarni", "Kumar", "Mehta", "Menon",
+	"Mishra", "Mukherjee", "Nair", "Pandey", "Patel", "Pillai", "Rao", "Reddy", "Saxena", "Sethi",
+	"Shah", "Sharma", "Singh", "Sinha", "Srinivasan", "Thakur", "Thomas", "Tiwari", "Varma", "Yadav",
+}
+
+var businessWords = []string{
+	"Apex", "Banyan", "Cedar", "Crescent", "Deccan", "Everest", "Falcon", "Ganga", "Harbour", "Indus",
+	"Jasmine", "Kaveri", "Lotus", "Meridian", "Narmada", "Orchid", "Peacock", "Quartz", "Saffron", "Summit",
+	"Sunrise", "Trident", "Unity", "Vistara", "Zenith",
+}
+
+var businessLines = []string{
+	"Agro", "Builders", "Exports", "Foods", "Infotech", "Logistics", "Pharma", "Solutions", "Textiles", "Traders",
+}
+
+var streetNames = []string{
+	"Ashoka", "Brigade", "Church", "Gandhi", "Hill", "Lake", "Link", "Mahatma Gandhi", "Market", "Nehru",
+	"Park", "Residency", "Station", "Subhash", "Temple", "Tilak", "Victoria",
+}
+
+var streetKinds = []string{"Road", "Street", "Lane", "Marg", "Nagar", "Cross Road", "Main Road"}
+
+type city struct {
+	name, state, stateCode, pinPrefix string
+}
+
+var cities = []city{
+	{"Mumbai", "Maharashtra", "MH", "400"},
+	{"Pune", "Maharashtra", "MH", "411"},
+	{"Nagpur", "Maharashtra", "MH", "440"},
+	{"Delhi", "Delhi", "DL", "110"},
+	{"Gurugram", "Haryana", "HR", "122"},
+	{"Bengaluru", "Karnataka", "KA", "560"},
+	{"Mysuru", "Karnataka", "KA", "570"},
+	{"Chennai", "Tamil Nadu", "TN", "600"},
+	{"Coimbatore", "Tamil Nadu", "TN", "641"},
+	{"Hyderabad", "Telangana", "TG", "500"},
+	{"Visakhapatnam", "Andhra Pradesh", "AP", "530"},
+	{"Kolkata", "West Bengal", "WB", "700"},
+	{"Ahmedabad", "Gujarat", "GJ", "380"},
+	{"Surat", "Gujarat", "GJ", "395"},
+	{"Jaipur", "Rajasthan", "RJ", "302"},
+	{"Lucknow", "Uttar Pradesh", "UP", "226"},
+	{"Kanpur", "Uttar Pradesh", "UP", "208"},
+	{"Bhopal", "Madhya Pradesh", "MP", "462"},
+	{"Indore", "Madhya Pradesh", "MP", "452"},
+	{"Patna", "Bihar", "BR", "800"},
+	{"Kochi", "Kerala", "KL", "682"},
+	{"Thiruvananthapuram", "Kerala", "KL", "695"},
+	{"Chandigarh", "Chandigarh", "CH", "160"},
+	{"Ludhiana", "Punjab", "PB", "141"},
+	{"Guwahati", "Assam", "AS", "781"},
+	{"Bhubaneswar", "Odisha", "OD", "751"},
+}
+
+// emailDomains are reserved for documentation, so no generated address can
+// reach a real mailbox
+var emailDomains = []string{"example.com", "example.in", "example.org", "example.net"}
+
+// requiredDocuments are the document types the chaincode requires of each
+// entity type, with the individual's usual identity documents
+var requiredDocuments = map[string][]string{
+	"INDIVIDUAL":  {"PAN", "AADHAAR"},
+	"COMPANY":     {"PAN", "CERTIFICATE_OF_INCORPORATION", "MOA", "AOA", "BOARD_RESOLUTION"},
+	"TRUST":       {"PAN", "REGISTRATION_CERTIFICATE", "TRUST_DEED"},
+	"PARTNERSHIP": {"PAN", "REGISTRATION_CERTIFICATE", "PARTNERSHIP_DEED"},
+	"HUF":         {"PAN", "HUF_DECLARATION"},
+}
+
+// panHolderTypes are the fourth character of a PAN issued to each entity type
+var panHolderTypes = map[string]byte{
+	"INDIVIDUAL":  'P',
+	"COMPANY":     'C',
+	"TRUST":       'T',
+	"PARTNERSHIP": 'F',
+	"HUF":         'H',
+}
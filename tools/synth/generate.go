@@ -0,0 +1,280 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// weights is a weighted choice between names, such as statuses or entity
+// types
+type weights struct {
+	names  []string
+	totals []int // running total of the weights, in names order
+}
+
+// parseWeights reads a list such as "VERIFIED=60,PENDING=30,REJECTED=10",
+// accepting only the names in allowed
+func parseWeights(spec string, allowed []string) (*weights, error) {
+	ok := map[string]bool{}
+	for _, name := range allowed {
+		ok[name] = true
+	}
+	w := &weights{}
+	total := 0
+	for _, part := range strings.Split(spec, ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		weight, err := strconv.Atoi(strings.TrimSpace(value))
+		if !found || err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight %q, expected NAME=number", part)
+		}
+		if !ok[name] {
+			return nil, fmt.Errorf("unknown %q, expected one of %s", name, strings.Join(allowed, ", "))
+		}
+		if weight == 0 {
+			continue
+		}
+		total += weight
+		w.names = append(w.names, name)
+		w.totals = append(w.totals, total)
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("%q gives every choice a weight of zero", spec)
+	}
+	return w, nil
+}
+
+func (w *weights) pick(r *rand.Rand) string {
+	n := r.Intn(w.totals[len(w.totals)-1])
+	return w.names[sort.SearchInts(w.totals, n+1)]
+}
+
+// generator produces synthetic records. The same seed produces the same
+// records, so a load test or demo can be rerun against a fresh network with
+// identical data.
+type generator struct {
+	r        *rand.Rand
+	idPrefix string
+	statuses *weights
+	entities *weights
+	now      time.Time
+	sequence int
+}
+
+// synthetic is one generated record: the CreateKYC payload and the status
+// the record should be taken to once created
+type synthetic struct {
+	Status string                 `json:"status"`
+	Record map[string]interface{} `json:"record"`
+}
+
+func (g *generator) next() synthetic {
+	g.sequence++
+	entityType := g.entities.pick(g.r)
+	record := map[string]interface{}{
+		"id":         fmt.Sprintf("%s%08d", g.idPrefix, g.sequence),
+		"userId":     fmt.Sprintf("user-%s-%08d", strings.ToLower(g.idPrefix), g.sequence),
+		"entityType": entityType,
+		"address":    g.address(),
+	}
+
+	given, surname := g.personName()
+	switch entityType {
+	case "INDIVIDUAL":
+		record["name"] = given + " " + surname
+		record["email"] = g.email(given, surname)
+		record["phone"] = g.phone()
+		record["pan"] = g.pan('P', surname[0])
+		record["dateOfBirth"] = g.dateOfBirth()
+	default:
+		name := g.entityName(entityType, given, surname)
+		record["name"] = name
+		record["email"] = g.businessEmail(name)
+		record["phone"] = g.phone()
+		record["pan"] = g.pan(panHolderTypes[entityType], name[0])
+		record["entityDetails"] = g.entityDetails(entityType, name, given, surname, record["address"].(map[string]string)["state"])
+	}
+	record["documentHashes"] = g.documents(requiredDocuments[entityType])
+	return synthetic{Status: g.statuses.pick(g.r), Record: record}
+}
+
+func (g *generator) choose(options []string) string {
+	return options[g.r.Intn(len(options))]
+}
+
+func (g *generator) personName() (string, string) {
+	return g.choose(givenNames), g.choose(surnames)
+}
+
+func (g *generator) digits(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('0' + g.r.Intn(10))
+	}
+	return string(b)
+}
+
+func (g *generator) letters(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('A' + g.r.Intn(26))
+	}
+	return string(b)
+}
+
+// pan returns a PAN in the issued format: three letters, the holder type,
+// the initial of the holder's surname or name, four digits and a letter
+func (g *generator) pan(holderType byte, initial byte) string {
+	return g.letters(3) + string(holderType) + string(initial) + g.digits(4) + g.letters(1)
+}
+
+// phone returns an Indian mobile number in E.164 form
+func (g *generator) phone() string {
+	return "+91" + strconv.Itoa(6+g.r.Intn(4)) + g.digits(9)
+}
+
+func (g *generator) email(given, surname string) string {
+	local := strings.ToLower(given + "." + surname)
+	if g.r.Intn(2) == 0 {
+		local += strconv.Itoa(g.r.Intn(1000))
+	}
+	return local + "@" + g.choose(emailDomains)
+}
+
+func (g *generator) businessEmail(name string) string {
+	word := strings.ToLower(strings.Fields(name)[0])
+	return fmt.Sprintf("accounts@%s%d.%s", word, g.r.Intn(1000), g.choose(emailDomains))
+}
+
+// dateOfBirth returns the birth date of an adult aged 18 to 80
+func (g *generator) dateOfBirth() string {
+	days := 18*365 + g.r.Intn(62*365)
+	return g.now.AddDate(0, 0, -days).Format("2006-01-02")
+}
+
+func (g *generator) address() map[string]string {
+	c := cities[g.r.Intn(len(cities))]
+	return map[string]string{
+		"street":  fmt.Sprintf("%d %s %s", 1+g.r.Intn(300), g.choose(streetNames), g.choose(streetKinds)),
+		"city":    c.name,
+		"state":   c.state,
+		"pincode": c.pinPrefix + g.digits(3),
+		"country": "IN",
+	}
+}
+
+func (g *generator) entityName(entityType, given, surname string) string {
+	trade := g.choose(businessWords) + " " + g.choose(businessLines)
+	switch entityType {
+	case "COMPANY":
+		if g.r.Intn(2) == 0 {
+			return trade + " Private Limited"
+		}
+		return trade + " Limited"
+	case "TRUST":
+		return g.choose(businessWords) + " " + g.choose([]string{"Charitable", "Education", "Family", "Welfare"}) + " Trust"
+	case "PARTNERSHIP":
+		return trade + " & Co"
+	}
+	return given + " " + surname + " HUF"
+}
+
+func (g *generator) party(designation string) map[string]string {
+	given, surname := g.personName()
+	party := map[string]string{"name": given + " " + surname, "pan": g.pan('P', surname[0])}
+	if designation != "" {
+		party["designation"] = designation
+	}
+	return party
+}
+
+func (g *generator) parties(min, max int, designation string) []map[string]string {
+	parties := make([]map[string]string, min+g.r.Intn(max-min+1))
+	for i := range parties {
+		parties[i] = g.party(designation)
+	}
+	return parties
+}
+
+// entityDetails returns the legal details and related parties the chaincode
+// requires of each non-individual entity type
+func (g *generator) entityDetails(entityType, name, given, surname, state string) map[string]interface{} {
+	formed := g.now.AddDate(-1-g.r.Intn(40), 0, -g.r.Intn(365))
+	details := map[string]interface{}{
+		"legalName":       name,
+		"dateOfFormation": formed.Format("2006-01-02"),
+	}
+	stateCode := "MH"
+	for _, c := range cities {
+		if c.state == state {
+			stateCode = c.stateCode
+			break
+		}
+	}
+	switch entityType {
+	case "COMPANY":
+		// corporate identity number: listing, industry code, state, year, company class and number
+		class := "PTC"
+		if strings.HasSuffix(name, " Limited") && !strings.HasSuffix(name, "Private Limited") {
+			class = "PLC"
+		}
+		details["registrationNumber"] = fmt.Sprintf("U%s%s%d%s%s", g.digits(5), stateCode, formed.Year(), class, g.digits(6))
+		details["directors"] = g.parties(2, 4, "DIRECTOR")
+	case "TRUST":
+		details["registrationNumber"] = fmt.Sprintf("E-%s/%d/%s", g.digits(5), formed.Year(), stateCode)
+		details["trustees"] = g.parties(1, 3, "TRUSTEE")
+		details["settlor"] = g.party("SETTLOR")
+	case "PARTNERSHIP":
+		details["registrationNumber"] = fmt.Sprintf("RF/%s/%d/%s", stateCode, formed.Year(), g.digits(5))
+		details["partners"] = g.parties(2, 4, "PARTNER")
+	case "HUF":
+		details["registrationNumber"] = fmt.Sprintf("HUF/%d/%s", formed.Year(), g.digits(6))
+		details["karta"] = map[string]string{"name": given + " " + surname, "pan": g.pan('P', surname[0]), "designation": "KARTA"}
+		coparceners := g.parties(1, 3, "COPARCENER")
+		for _, coparcener := range coparceners {
+			// coparceners share the family name
+			coparcener["name"] = g.choose(givenNames) + " " + surname
+			coparcener["pan"] = g.pan('P', surname[0])
+		}
+		details["coparceners"] = coparceners
+	}
+	return details
+}
+
+// documents returns hashes of made-up documents of the given types, some
+// with a content identifier as if pinned to IPFS
+func (g *generator) documents(types []string) []map[string]string {
+	documents := make([]map[string]string, len(types))
+	for i, docType := range types {
+		content := make([]byte, 32)
+		g.r.Read(content)
+		sum := sha256.Sum256(content)
+		document := map[string]string{
+			"id":         fmt.Sprintf("DOC-%s-%d", docType, g.sequence),
+			"type":       docType,
+			"hash":       hex.EncodeToString(sum[:]),
+			"uploadedAt": g.now.Add(-time.Duration(g.r.Intn(72*60)) * time.Minute).UTC().Format(time.RFC3339),
+		}
+		if g.r.Intn(2) == 0 {
+			document["ipfsHash"] = "Qm" + g.base58(44)
+		}
+		documents[i] = document
+	}
+	return documents
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func (g *generator) base58(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = base58Alphabet[g.r.Intn(len(base58Alphabet))]
+	}
+	return string(b)
+}
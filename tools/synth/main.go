@@ -0,0 +1,153 @@
+// Command synth generates realistic-looking KYC records for load tests and
+// demos: valid PANs of each holder type, Indian mobile numbers, addresses
+// whose pincode and state agree, the documents each entity type requires and
+// a weighted mix of entity types and statuses. Records are invented, not
+// drawn from any real data; emails use the reserved example domains.
+//
+// By default the records are written as JSON lines, each holding the
+// CreateKYC payload and the status the record should reach:
+//
+//	go run . -count 10000 -out records.ndjson
+//
+// loadtest -records uses the payloads as its CreateKYC load.
+// With -submit the records are instead created on a network through its
+// Fabric Gateway and taken to their status with UpdateKYCStatus, after a
+// clean RecordDocumentScan of each document of those to be verified, for
+// which the identity must hold the kyc.verifier attribute:
+//
+//	go run . -count 500 -submit -endpoint localhost:7051 -tls-ca tlsca.pem -cert cert.pem -key key.pem
+//
+// The default risk rules score some records HIGH, which cannot be verified
+// without an approved override, so those stay PENDING; the summary counts
+// the records that reached each status. The same -seed and -as-of produce
+// the same records.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"ekyc-gateway/fabric"
+)
+
+var entityTypes = []string{"INDIVIDUAL", "COMPANY", "TRUST", "PARTNERSHIP", "HUF"}
+
+func main() {
+	var (
+		config      fabric.Config
+		count       = flag.Int("count", 1000, "number of records to generate")
+		seed        = flag.Int64("seed", 1, "random seed")
+		asOf        = flag.String("as-of", time.Now().UTC().Format("2006-01-02"), "date ages and document upload times are relative to")
+		idPrefix    = flag.String("id-prefix", "SYN", "prefix of generated KYC IDs")
+		statusMix   = flag.String("statuses", "VERIFIED=55,PENDING=25,REJECTED=10,EXPIRED=10", "weighted mix of target statuses")
+		entityMix   = flag.String("entities", "INDIVIDUAL=90,COMPANY=5,PARTNERSHIP=2,TRUST=2,HUF=1", "weighted mix of entity types")
+		outPath     = flag.String("out", "-", "file to write records to; - writes to standard output")
+		submit      = flag.Bool("submit", false, "create the records on a network instead of writing them")
+		mspID       = flag.String("msp", "Org1MSP", "MSP ID of the submitting identity")
+		certPath    = flag.String("cert", "", "PEM enrolment certificate of the submitting identity")
+		keyPath     = flag.String("key", "", "PEM private key of the submitting identity")
+		verifiedBy  = flag.String("verified-by", "synth", "verifier recorded on the status updates")
+		concurrency = flag.Int("concurrency", 16, "records submitted at a time")
+	)
+	flag.StringVar(&config.Endpoint, "endpoint", "localhost:7051", "gateway peer address")
+	flag.StringVar(&config.TLSCACertPath, "tls-ca", "", "peer TLS CA certificate; empty connects without TLS")
+	flag.StringVar(&config.ServerName, "server-name", "", "TLS server name override")
+	flag.StringVar(&config.Channel, "channel", "ekycChannel", "channel name")
+	flag.StringVar(&config.Chaincode, "chaincode", "ekyc-chaincode", "chaincode name")
+	flag.Parse()
+
+	statusWeights, err := parseWeights(*statusMix, statuses)
+	if err != nil {
+		log.Fatalf("-statuses: %v", err)
+	}
+	entityWeights, err := parseWeights(*entityMix, entityTypes)
+	if err != nil {
+		log.Fatalf("-entities: %v", err)
+	}
+	now, err := time.Parse("2006-01-02", *asOf)
+	if err != nil {
+		log.Fatalf("-as-of: %v", err)
+	}
+	g := &generator{
+		r:        rand.New(rand.NewSource(*seed)),
+		idPrefix: strings.ToUpper(*idPrefix),
+		statuses: statusWeights,
+		entities: entityWeights,
+		now:      now,
+	}
+
+	if *submit {
+		identity, err := fabric.LoadIdentity(*mspID, *certPath, *keyPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		client, err := fabric.Dial(config, identity)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer client.Close()
+
+		records := make(chan synthetic)
+		go func() {
+			for i := 0; i < *count; i++ {
+				records <- g.next()
+			}
+			close(records)
+		}()
+		s := &seeder{client: client, verifiedBy: *verifiedBy}
+		s.seed(records, *concurrency)
+		s.printSummary(*count)
+		return
+	}
+
+	out := io.Writer(os.Stdout)
+	if *outPath != "-" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		out = f
+	}
+	err = write(out, g, *count)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// write writes count generated records as JSON lines
+func write(out io.Writer, g *generator, count int) error {
+	w := bufio.NewWriter(out)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	for i := 0; i < count; i++ {
+		err := encoder.Encode(g.next())
+		if err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// printSummary reports how many records reached each status and which
+// transactions failed
+func (s *seeder) printSummary(count int) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "generated\t%d\n", count)
+	for _, status := range statuses {
+		fmt.Fprintf(w, "%s\t%d\n", status, s.reached[status])
+	}
+	for transaction, failed := range s.failed {
+		fmt.Fprintf(w, "failed %s\t%d\n", transaction, failed)
+	}
+	w.Flush()
+}
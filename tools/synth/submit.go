@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+
+	"ekyc-gateway/fabric"
+)
+
+// transitions are the UpdateKYCStatus calls that take a new record from
+// PENDING to each status
+var transitions = map[string][]string{
+	"PENDING":  nil,
+	"VERIFIED": {"VERIFIED"},
+	"REJECTED": {"REJECTED"},
+	"EXPIRED":  {"VERIFIED", "EXPIRED"},
+}
+
+// statuses are the statuses a generated record can be taken to
+var statuses = []string{"PENDING", "VERIFIED", "REJECTED", "EXPIRED"}
+
+// seeder submits generated records to a network
+type seeder struct {
+	client     *fabric.Client
+	verifiedBy string
+
+	mu       sync.Mutex
+	reached  map[string]int
+	failed   map[string]int // by the transaction that failed
+	reported int
+}
+
+// seed creates each record and takes it to its status, concurrency records
+// at a time
+func (s *seeder) seed(records <-chan synthetic, concurrency int) {
+	s.reached, s.failed = map[string]int{}, map[string]int{}
+	var workers sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for record := range records {
+				s.submit(record)
+			}
+		}()
+	}
+	workers.Wait()
+}
+
+func (s *seeder) submit(record synthetic) {
+	ctx := context.Background()
+	payload, err := json.Marshal(record.Record)
+	if err != nil {
+		s.fail("CreateKYC", err)
+		return
+	}
+	_, _, err = s.client.Submit(ctx, "CreateKYC", string(payload))
+	if err != nil {
+		s.fail("CreateKYC", err)
+		return
+	}
+
+	kycID := record.Record["id"].(string)
+	status := "PENDING"
+	for _, next := range transitions[record.Status] {
+		if next == "VERIFIED" && !s.scan(ctx, kycID, record) {
+			break
+		}
+		_, _, err = s.client.Submit(ctx, "UpdateKYCStatus", kycID, next, s.verifiedBy, "synthetic data")
+		if err != nil {
+			// HIGH risk records cannot be verified without an override, so
+			// some records stop short of their status
+			s.fail("UpdateKYCStatus "+next, fmt.Errorf("%s: %v", kycID, err))
+			break
+		}
+		status = next
+	}
+	s.mu.Lock()
+	s.reached[status]++
+	s.mu.Unlock()
+}
+
+// scan records a clean malware scan of each of a record's documents, which
+// verification needs
+func (s *seeder) scan(ctx context.Context, kycID string, record synthetic) bool {
+	documents, _ := record.Record["documentHashes"].([]map[string]string)
+	for _, document := range documents {
+		_, _, err := s.client.Submit(ctx, "RecordDocumentScan", kycID, document["id"], document["hash"], "synth", "0", "CLEAN", "")
+		if err != nil {
+			s.fail("RecordDocumentScan", fmt.Errorf("%s: %v", kycID, err))
+			return false
+		}
+	}
+	return true
+}
+
+// fail counts a failed transaction, logging the first few errors
+func (s *seeder) fail(transaction string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed[transaction]++
+	if s.reported < 10 {
+		s.reported++
+		log.Printf("%s failed: %v", transaction, err)
+	}
+}